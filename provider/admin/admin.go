@@ -0,0 +1,303 @@
+// Package admin provides HTTP handlers that let operators inspect the provider
+// system of a service: the registered providers with their config schema, the
+// provider active per tenant and category, and live health probes.
+//
+// Each service mounts the tenant routes on a route group protected by its own
+// authentication; they only return the providers of the request's tenant.
+// The routes that list all tenants are mounted separately, behind the
+// operator token:
+//
+//	providerAdmin := admin.NewHandler(resolver, func(c *gin.Context) (string, bool) {
+//	    return middleware.GetTenantID(c).String(), true
+//	})
+//	providerAdmin.RegisterRoutes(api.Group("/admin"))
+//	providerAdmin.RegisterOperatorRoutes(router.Group("/operator", admin.RequireOperator(cfg.OperatorToken)))
+//
+// Secret config values are never returned.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gondolia/gondolia/provider"
+)
+
+// DefaultProbeTimeout bounds each health probe.
+const DefaultProbeTimeout = 5 * time.Second
+
+// Health probe statuses.
+const (
+	StatusUp          = "up"
+	StatusDown        = "down"
+	StatusUnsupported = "unsupported" // The provider interface has no health probe
+	StatusUnavailable = "unavailable" // The provider could not be built
+)
+
+// HealthChecker is implemented by providers that support a live health probe,
+// such as search.SearchProvider.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// TenantFunc returns the ID of the tenant a request is made for.
+type TenantFunc func(c *gin.Context) (string, bool)
+
+// Handler serves the provider admin endpoints.
+type Handler struct {
+	resolver     *provider.Resolver
+	tenantID     TenantFunc
+	probeTimeout time.Duration
+}
+
+// NewHandler creates a new provider admin handler.
+func NewHandler(resolver *provider.Resolver, tenantID TenantFunc) *Handler {
+	return &Handler{
+		resolver:     resolver,
+		tenantID:     tenantID,
+		probeTimeout: DefaultProbeTimeout,
+	}
+}
+
+// RegisterRoutes registers the admin endpoints of the request's tenant on rg.
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	providers := rg.Group("/providers")
+	{
+		providers.GET("", h.ListProviders)
+		providers.GET("/active", h.ActiveProviders)
+		providers.GET("/health", h.Health)
+	}
+}
+
+// RegisterOperatorRoutes registers the endpoints across all tenants on rg,
+// which must only be reachable by operators (see RequireOperator).
+func (h *Handler) RegisterOperatorRoutes(rg *gin.RouterGroup) {
+	rg.GET("/providers/tenants", h.TenantProviders)
+}
+
+// OperatorTokenHeader carries the operator token of a request.
+const OperatorTokenHeader = "X-Operator-Token"
+
+// RequireOperator rejects requests that do not carry the operator token in
+// the X-Operator-Token header. With an empty token every request is rejected.
+func RequireOperator(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := c.GetHeader(OperatorTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": gin.H{"code": "UNAUTHORIZED", "message": "operator authentication required"},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ProviderInfo describes a registered provider.
+type ProviderInfo struct {
+	Category    string        `json:"category"`
+	Name        string        `json:"name"`
+	DisplayName string        `json:"display_name"`
+	Version     string        `json:"version"`
+	Description string        `json:"description"`
+	ConfigSpec  []ConfigField `json:"config_spec"`
+}
+
+// ConfigField describes one field of a provider's config schema.
+type ConfigField struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Default     any    `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TenantProviders lists the providers active for one tenant.
+type TenantProviders struct {
+	TenantID  string                    `json:"tenant_id"`
+	Providers []provider.ActiveProvider `json:"providers"`
+	Error     string                    `json:"error,omitempty"`
+}
+
+// ProbeResult is the outcome of a health probe.
+type ProbeResult struct {
+	Category  string `json:"category"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ListProviders handles GET /providers
+func (h *Handler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": Catalog()})
+}
+
+// ActiveProviders handles GET /providers/active
+func (h *Handler) ActiveProviders(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": "TENANT_REQUIRED", "message": "tenant is required"},
+		})
+		return
+	}
+
+	active, err := h.resolver.Active(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": "INTERNAL_ERROR", "message": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": TenantProviders{TenantID: tenantID, Providers: active}})
+}
+
+// TenantProviders handles GET /providers/tenants (operator only)
+// It lists the active providers of every tenant this service has served so far.
+func (h *Handler) TenantProviders(c *gin.Context) {
+	tenants := h.resolver.Tenants()
+	result := make([]TenantProviders, 0, len(tenants))
+	for _, tenantID := range tenants {
+		entry := TenantProviders{TenantID: tenantID}
+		active, err := h.resolver.Active(c.Request.Context(), tenantID)
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Providers = active
+		}
+		result = append(result, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Health handles GET /providers/health
+// It probes every provider active for the current tenant. The response is 503
+// if any probe fails.
+func (h *Handler) Health(c *gin.Context) {
+	tenantID, ok := h.tenantID(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{"code": "TENANT_REQUIRED", "message": "tenant is required"},
+		})
+		return
+	}
+
+	results, err := h.Probe(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{"code": "INTERNAL_ERROR", "message": err.Error()},
+		})
+		return
+	}
+
+	status := http.StatusOK
+	for _, r := range results {
+		if r.Status == StatusDown || r.Status == StatusUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, gin.H{"data": results})
+}
+
+// Probe runs the health probes of all providers active for a tenant in parallel.
+func (h *Handler) Probe(ctx context.Context, tenantID string) ([]ProbeResult, error) {
+	active, err := h.resolver.Active(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = provider.WithTenant(ctx, tenantID)
+	results := make([]ProbeResult, len(active))
+
+	var wg sync.WaitGroup
+	for i, a := range active {
+		wg.Add(1)
+		go func(i int, a provider.ActiveProvider) {
+			defer wg.Done()
+			results[i] = h.probe(ctx, tenantID, a)
+		}(i, a)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func (h *Handler) probe(ctx context.Context, tenantID string, a provider.ActiveProvider) ProbeResult {
+	result := ProbeResult{Category: a.Category, Name: a.Name}
+
+	instance, err := h.resolver.Instance(ctx, tenantID, a.Category)
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+		return result
+	}
+
+	checker, ok := instance.(HealthChecker)
+	if !ok {
+		result.Status = StatusUnsupported
+		return result
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, h.probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = checker.Health(probeCtx)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = StatusUp
+	return result
+}
+
+// Catalog returns all registered providers sorted by category and name.
+// Defaults of secret fields are masked.
+func Catalog() []ProviderInfo {
+	var result []ProviderInfo
+	for category, providers := range provider.ListAll() {
+		for _, m := range providers {
+			info := ProviderInfo{
+				Category:    category,
+				Name:        m.Name,
+				DisplayName: m.DisplayName,
+				Version:     m.Version,
+				Description: m.Description,
+				ConfigSpec:  make([]ConfigField, 0, len(m.ConfigSpec)),
+			}
+			for _, f := range m.ConfigSpec {
+				field := ConfigField{
+					Key:         f.Key,
+					Type:        f.Type,
+					Required:    f.Required,
+					Default:     f.Default,
+					Description: f.Description,
+				}
+				if f.Type == provider.FieldTypeSecret && f.Default != nil {
+					field.Default = provider.RedactConfig(m.ConfigSpec, map[string]any{f.Key: f.Default})[f.Key]
+				}
+				info.ConfigSpec = append(info.ConfigSpec, field)
+			}
+			result = append(result, info)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category < result[j].Category
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+type downSearch struct {
+	search.SearchProvider
+}
+
+func (downSearch) Health(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func init() {
+	provider.Register[search.SearchProvider]("test-admin", "down",
+		provider.Metadata{Name: "down", ConfigSpec: []provider.ConfigField{
+			{Key: "url", Type: "string", Required: true},
+			{Key: "token", Type: "secret", Required: true},
+		}},
+		func(config map[string]any) (search.SearchProvider, error) {
+			return downSearch{}, nil
+		},
+	)
+}
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	resolver := provider.NewResolver(nil)
+	resolver.SetDefault("test-admin", provider.Selection{
+		Name:   "down",
+		Config: map[string]any{"url": "http://search:9200", "token": "top-secret"},
+	})
+
+	router := gin.New()
+	h := NewHandler(resolver, func(c *gin.Context) (string, bool) {
+		return "tenant-1", true
+	})
+	h.RegisterRoutes(router.Group("/admin"))
+	h.RegisterOperatorRoutes(router.Group("/operator", RequireOperator("operator-token")))
+	return router
+}
+
+func TestActiveProviders_MasksSecrets(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/providers/active", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "top-secret") {
+		t.Fatalf("response leaks secret: %s", rec.Body.String())
+	}
+
+	var resp struct {
+		Data TenantProviders `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Data.Providers) != 1 || resp.Data.Providers[0].Source != "default" {
+		t.Fatalf("providers = %+v, want one default provider", resp.Data.Providers)
+	}
+	if url := resp.Data.Providers[0].Config["url"]; url != "http://search:9200" {
+		t.Errorf("url = %v, want unmasked value", url)
+	}
+}
+
+func TestHealth_ReportsDownProviders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/providers/health", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+
+	var resp struct {
+		Data []ProbeResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Status != StatusDown {
+		t.Fatalf("probes = %+v, want one down probe", resp.Data)
+	}
+}
+
+func TestCatalog_ListsRegisteredProviders(t *testing.T) {
+	for _, info := range Catalog() {
+		if info.Category == "test-admin" && info.Name == "down" {
+			if len(info.ConfigSpec) != 2 {
+				t.Errorf("ConfigSpec = %+v, want 2 fields", info.ConfigSpec)
+			}
+			return
+		}
+	}
+	t.Fatal("test-admin.down not listed")
+}
+
+func TestTenantProviders_RequiresOperator(t *testing.T) {
+	router := newTestRouter()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/providers/tenants", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("tenant route status = %d, want 404", rec.Code)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest(http.MethodGet, "/operator/providers/tenants", nil)
+		req.Header.Set(OperatorTokenHeader, token)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/operator/providers/tenants", nil)
+	req.Header.Set(OperatorTokenHeader, "operator-token")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -1,7 +1,12 @@
 // Package auth defines the interface for SSO/Identity Provider integrations.
 package auth
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/gondolia/gondolia/provider"
+)
 
 // AuthProvider abstracts SSO/Identity Providers (Azure AD, Keycloak, Auth0, etc.).
 type AuthProvider interface {
@@ -11,7 +16,9 @@ type AuthProvider interface {
 	// HandleCallback processes the SSO callback and returns user information.
 	HandleCallback(ctx context.Context, code string, state string) (*SSOUser, error)
 
-	// ValidateToken validates an SSO token (for API-based flows).
+	// ValidateToken validates an SSO token (for API-based flows). A token
+	// that is malformed, expired or not signed by the issuer returns an
+	// error wrapping ErrInvalidToken.
 	ValidateToken(ctx context.Context, token string) (*SSOUser, error)
 
 	// GetUserInfo retrieves user information from the provider.
@@ -21,6 +28,9 @@ type AuthProvider interface {
 	Metadata() Metadata
 }
 
+// ErrInvalidToken is returned for tokens the identity provider does not accept.
+var ErrInvalidToken = fmt.Errorf("invalid token: %w", provider.ErrInvalidArgument)
+
 // SSOUser represents a user from an SSO provider.
 type SSOUser struct {
 	ExternalID string
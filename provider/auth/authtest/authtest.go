@@ -0,0 +1,120 @@
+// Package authtest is a conformance suite for auth.AuthProvider
+// implementations. It checks login URLs, token rejection, error types and
+// context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    authtest.Run(t, func(t *testing.T) auth.AuthProvider {
+//	        return newTestProvider(t)
+//	    }, authtest.Options{})
+//	}
+package authtest
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// redirectURL is the callback URL used for login URLs. Its query must survive.
+const redirectURL = "https://shop.example.com/auth/callback?tenant=conformance"
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that accept every token, such as noop.
+	// The token rejection check is skipped.
+	Stateless bool
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) auth.AuthProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("AuthURL", s.testAuthURL)
+	t.Run("InvalidToken", s.testInvalidToken)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	meta := s.newProvider(t).Metadata()
+	if meta.Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+	if meta.Protocol == "" {
+		t.Error("Metadata().Protocol is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+
+	_, err := p.GetAuthURL(ctx, "state", redirectURL)
+	providertest.ExpectCanceled(t, "GetAuthURL", err)
+	_, err = p.HandleCallback(ctx, "code", "state")
+	providertest.ExpectCanceled(t, "HandleCallback", err)
+	_, err = p.ValidateToken(ctx, "token")
+	providertest.ExpectCanceled(t, "ValidateToken", err)
+	_, err = p.GetUserInfo(ctx, "token")
+	providertest.ExpectCanceled(t, "GetUserInfo", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.GetAuthURL(ctx, "", redirectURL)
+	providertest.ExpectError(t, "GetAuthURL with empty state", err, invalid)
+	_, err = p.HandleCallback(ctx, "", "state")
+	providertest.ExpectError(t, "HandleCallback with empty code", err, invalid)
+	_, err = p.ValidateToken(ctx, "")
+	providertest.ExpectError(t, "ValidateToken with empty token", err, invalid)
+	_, err = p.GetUserInfo(ctx, "")
+	providertest.ExpectError(t, "GetUserInfo with empty token", err, invalid)
+}
+
+func (s *suite) testAuthURL(t *testing.T) {
+	p := s.newProvider(t)
+	state := providertest.UniqueName("state-")
+
+	raw, err := p.GetAuthURL(context.Background(), state, redirectURL)
+	if err != nil {
+		t.Fatalf("GetAuthURL() error = %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("GetAuthURL() = %q, not a URL: %v", raw, err)
+	}
+	if !u.IsAbs() {
+		t.Errorf("GetAuthURL() = %q, want an absolute URL", raw)
+	}
+	if got := u.Query().Get("state"); got != state {
+		t.Errorf("GetAuthURL() state = %q, want %q", got, state)
+	}
+}
+
+func (s *suite) testInvalidToken(t *testing.T) {
+	if s.opts.Stateless {
+		t.Skip("provider is stateless")
+	}
+	p := s.newProvider(t)
+
+	_, err := p.ValidateToken(context.Background(), "conformance.invalid.token")
+	providertest.ExpectError(t, "ValidateToken with garbage token", err, auth.ErrInvalidToken)
+}
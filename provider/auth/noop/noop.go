@@ -3,6 +3,8 @@ package noop
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/auth"
@@ -31,10 +33,32 @@ func NewProvider(config map[string]any) (auth.AuthProvider, error) {
 }
 
 func (p *Provider) GetAuthURL(ctx context.Context, state string, redirectURL string) (string, error) {
-	return redirectURL + "?code=noop-code&state=" + state, nil
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if state == "" {
+		return "", fmt.Errorf("noop: state is required: %w", provider.ErrInvalidArgument)
+	}
+
+	// The noop provider skips the login page and redirects straight back
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return "", fmt.Errorf("noop: invalid redirect URL: %w", provider.ErrInvalidArgument)
+	}
+	q := u.Query()
+	q.Set("code", "noop-code")
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
 func (p *Provider) HandleCallback(ctx context.Context, code string, state string) (*auth.SSOUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("noop: code is required: %w", provider.ErrInvalidArgument)
+	}
 	return &auth.SSOUser{
 		ExternalID: "noop-user-001",
 		Email:      "user@example.com",
@@ -47,6 +71,12 @@ func (p *Provider) HandleCallback(ctx context.Context, code string, state string
 }
 
 func (p *Provider) ValidateToken(ctx context.Context, token string) (*auth.SSOUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("noop: token is required: %w", auth.ErrInvalidToken)
+	}
 	return &auth.SSOUser{
 		ExternalID: "noop-user-001",
 		Email:      "user@example.com",
@@ -59,6 +89,12 @@ func (p *Provider) ValidateToken(ctx context.Context, token string) (*auth.SSOUs
 }
 
 func (p *Provider) GetUserInfo(ctx context.Context, accessToken string) (*auth.SSOUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		return nil, fmt.Errorf("noop: access token is required: %w", auth.ErrInvalidToken)
+	}
 	return &auth.SSOUser{
 		ExternalID: "noop-user-001",
 		Email:      "user@example.com",
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/auth/authtest"
+)
+
+func TestConformance(t *testing.T) {
+	authtest.Run(t, func(t *testing.T) auth.AuthProvider {
+		return &Provider{}
+	}, authtest.Options{Stateless: true})
+}
@@ -0,0 +1,412 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config field types understood by ValidateConfig.
+const (
+	FieldTypeString   = "string"
+	FieldTypeSecret   = "secret"
+	FieldTypeInt      = "int"
+	FieldTypeFloat    = "float"
+	FieldTypeBool     = "bool"
+	FieldTypeDuration = "duration"
+	FieldTypeArray    = "array"
+	FieldTypeMap      = "map"
+)
+
+// redactedValue replaces secret values in logs, errors and API output.
+const redactedValue = "********"
+
+// FieldError describes a single invalid configuration field.
+type FieldError struct {
+	Key    string
+	Reason string
+}
+
+// ConfigError is returned when a provider configuration does not match its ConfigSpec.
+// It reports every problem at once so operators can fix the config in a single pass.
+// Values of secret fields are never included.
+type ConfigError struct {
+	Missing []string     // Required keys without a value or default
+	Unknown []string     // Keys not declared in the ConfigSpec
+	Invalid []FieldError // Keys whose value could not be coerced
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing required fields: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unknown) > 0 {
+		parts = append(parts, "unknown fields: "+strings.Join(e.Unknown, ", "))
+	}
+	for _, fe := range e.Invalid {
+		parts = append(parts, fmt.Sprintf("field %q: %s", fe.Key, fe.Reason))
+	}
+	return "invalid provider config: " + strings.Join(parts, "; ")
+}
+
+func (e *ConfigError) empty() bool {
+	return len(e.Missing) == 0 && len(e.Unknown) == 0 && len(e.Invalid) == 0
+}
+
+// ValidateConfig checks a raw configuration map against a ConfigSpec.
+// It applies defaults, coerces values to the declared field types and rejects
+// unknown keys. The returned map contains only declared keys with normalized
+// values; the input map is not modified. All problems are reported together
+// in a single *ConfigError.
+func ValidateConfig(spec []ConfigField, raw map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(spec))
+	cfgErr := &ConfigError{}
+
+	declared := make(map[string]ConfigField, len(spec))
+	for _, f := range spec {
+		declared[f.Key] = f
+	}
+
+	for key := range raw {
+		if _, ok := declared[key]; !ok {
+			cfgErr.Unknown = append(cfgErr.Unknown, key)
+		}
+	}
+
+	for _, field := range spec {
+		value, present := raw[field.Key]
+		if !present || isEmptyValue(value) {
+			if field.Default != nil {
+				value, present = field.Default, true
+			} else {
+				present = false
+			}
+		}
+
+		if !present {
+			if field.Required {
+				cfgErr.Missing = append(cfgErr.Missing, field.Key)
+			}
+			continue
+		}
+
+		coerced, err := coerceValue(field, value)
+		if err != nil {
+			cfgErr.Invalid = append(cfgErr.Invalid, FieldError{Key: field.Key, Reason: err.Error()})
+			continue
+		}
+		result[field.Key] = coerced
+	}
+
+	if !cfgErr.empty() {
+		sort.Strings(cfgErr.Unknown)
+		return nil, cfgErr
+	}
+	return result, nil
+}
+
+// RedactConfig returns a copy of config with the values of all secret fields masked.
+// Keys that are not declared in the spec are masked as well, since their
+// sensitivity is unknown. Use it before logging or exposing a configuration.
+func RedactConfig(spec []ConfigField, config map[string]any) map[string]any {
+	types := make(map[string]string, len(spec))
+	for _, f := range spec {
+		types[f.Key] = f.Type
+	}
+
+	result := make(map[string]any, len(config))
+	for key, value := range config {
+		fieldType, known := types[key]
+		if !known || fieldType == FieldTypeSecret {
+			if isEmptyValue(value) {
+				result[key] = ""
+			} else {
+				result[key] = redactedValue
+			}
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// Bind copies a validated configuration map into the struct pointed to by target.
+// Struct fields are matched by their `config:"key"` tag; untagged fields are ignored.
+// Supported field kinds are string, bool, all int/uint/float kinds, time.Duration,
+// []string, map[string]string and map[string]any.
+func Bind(config map[string]any, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a pointer to a struct, got %T", target)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		key := sf.Tag.Get("config")
+		if key == "" || key == "-" || !sf.IsExported() {
+			continue
+		}
+		value, ok := config[key]
+		if !ok || value == nil {
+			continue
+		}
+		if err := assignValue(rv.Field(i), value); err != nil {
+			return fmt.Errorf("bind %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// coerceValue converts value to the representation declared by field.Type.
+// Error messages only include the offending value for non-secret fields.
+func coerceValue(field ConfigField, value any) (any, error) {
+	describe := func() string {
+		if field.Type == FieldTypeSecret {
+			return fmt.Sprintf("%T", value)
+		}
+		return fmt.Sprintf("%T %v", value, value)
+	}
+
+	switch field.Type {
+	case FieldTypeString, FieldTypeSecret, "":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case fmt.Stringer:
+			return v.String(), nil
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+			return fmt.Sprint(v), nil
+		}
+		return nil, fmt.Errorf("expected string, got %s", describe())
+
+	case FieldTypeInt:
+		if n, ok := toInt(value); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("expected int, got %s", describe())
+
+	case FieldTypeFloat:
+		if f, ok := toFloat(value); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected float, got %s", describe())
+
+	case FieldTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("expected bool, got %s", describe())
+
+	case FieldTypeDuration:
+		switch v := value.(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			if d, err := time.ParseDuration(strings.TrimSpace(v)); err == nil {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("expected duration (e.g. \"30s\"), got %s", describe())
+
+	case FieldTypeArray:
+		switch v := value.(type) {
+		case []string:
+			return append([]string(nil), v...), nil
+		case []any:
+			out := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected array of strings, got element %T", item)
+				}
+				out = append(out, s)
+			}
+			return out, nil
+		case string:
+			var out []string
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					out = append(out, s)
+				}
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("expected array, got %s", describe())
+
+	case FieldTypeMap:
+		switch v := value.(type) {
+		case map[string]any:
+			out := make(map[string]any, len(v))
+			for k, item := range v {
+				out[k] = item
+			}
+			return out, nil
+		case map[string]string:
+			out := make(map[string]any, len(v))
+			for k, item := range v {
+				out[k] = item
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("expected map, got %s", describe())
+	}
+
+	return nil, fmt.Errorf("unsupported field type %q", field.Type)
+}
+
+func assignValue(dst reflect.Value, value any) error {
+	if dst.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, ok := value.(time.Duration)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to time.Duration", value)
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to string", value)
+		}
+		dst.SetString(s)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to bool", value)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt(value)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %s", value, dst.Type())
+		}
+		dst.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toInt(value)
+		if !ok || n < 0 {
+			return fmt.Errorf("cannot assign %T to %s", value, dst.Type())
+		}
+		dst.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to %s", value, dst.Type())
+		}
+		dst.SetFloat(f)
+	case reflect.Slice:
+		src := reflect.ValueOf(value)
+		if !src.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("cannot assign %T to %s", value, dst.Type())
+		}
+		dst.Set(src)
+	case reflect.Map:
+		switch m := value.(type) {
+		case map[string]any:
+			if dst.Type() == reflect.TypeOf(map[string]any{}) {
+				dst.Set(reflect.ValueOf(m))
+				return nil
+			}
+			if dst.Type() == reflect.TypeOf(map[string]string{}) {
+				out := make(map[string]string, len(m))
+				for k, v := range m {
+					out[k] = fmt.Sprint(v)
+				}
+				dst.Set(reflect.ValueOf(out))
+				return nil
+			}
+		}
+		return fmt.Errorf("cannot assign %T to %s", value, dst.Type())
+	default:
+		return fmt.Errorf("unsupported field kind %s", dst.Kind())
+	}
+	return nil
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint:
+		return int(v), true
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float32:
+		if v == float32(int(v)) {
+			return int(v), true
+		}
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), true
+		}
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, true
+		}
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	default:
+		if n, ok := toInt(value); ok {
+			return float64(n), true
+		}
+	}
+	return 0, false
+}
+
+func isEmptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return s == ""
+	}
+	return false
+}
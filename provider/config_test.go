@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = []ConfigField{
+	{Key: "host", Type: "string", Required: true},
+	{Key: "api_key", Type: "secret", Required: true},
+	{Key: "port", Type: "int", Default: 7700},
+	{Key: "tls", Type: "bool"},
+	{Key: "timeout", Type: "duration", Default: "5s"},
+	{Key: "addresses", Type: "array"},
+}
+
+func TestValidateConfig_AppliesDefaultsAndCoerces(t *testing.T) {
+	cfg, err := ValidateConfig(testSpec, map[string]any{
+		"host":      "http://localhost",
+		"api_key":   "s3cr3t",
+		"tls":       "true",
+		"addresses": []any{"a:1", "b:2"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	if cfg["port"] != 7700 {
+		t.Errorf("port = %v, want 7700", cfg["port"])
+	}
+	if cfg["tls"] != true {
+		t.Errorf("tls = %v, want true", cfg["tls"])
+	}
+	if cfg["timeout"] != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", cfg["timeout"])
+	}
+	if addrs, ok := cfg["addresses"].([]string); !ok || len(addrs) != 2 {
+		t.Errorf("addresses = %#v, want []string of length 2", cfg["addresses"])
+	}
+}
+
+func TestValidateConfig_ReportsAllProblems(t *testing.T) {
+	_, err := ValidateConfig(testSpec, map[string]any{
+		"api_key": []any{"leaked-secret"},
+		"port":    "not-a-number",
+		"extra":   "x",
+	})
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected *ConfigError, got %v", err)
+	}
+	if len(cfgErr.Missing) != 1 || cfgErr.Missing[0] != "host" {
+		t.Errorf("Missing = %v, want [host]", cfgErr.Missing)
+	}
+	if len(cfgErr.Unknown) != 1 || cfgErr.Unknown[0] != "extra" {
+		t.Errorf("Unknown = %v, want [extra]", cfgErr.Unknown)
+	}
+	if len(cfgErr.Invalid) != 2 {
+		t.Errorf("Invalid = %v, want 2 entries", cfgErr.Invalid)
+	}
+	if strings.Contains(err.Error(), "leaked-secret") {
+		t.Errorf("error leaks secret value: %s", err.Error())
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	redacted := RedactConfig(testSpec, map[string]any{
+		"host":    "http://localhost",
+		"api_key": "s3cr3t",
+		"token":   "undeclared",
+	})
+
+	if redacted["host"] != "http://localhost" {
+		t.Errorf("host = %v, want unchanged", redacted["host"])
+	}
+	if redacted["api_key"] != redactedValue {
+		t.Errorf("api_key = %v, want redacted", redacted["api_key"])
+	}
+	if redacted["token"] != redactedValue {
+		t.Errorf("token = %v, want redacted", redacted["token"])
+	}
+}
+
+func TestBind(t *testing.T) {
+	type typedConfig struct {
+		Host      string        `config:"host"`
+		APIKey    string        `config:"api_key"`
+		Port      int           `config:"port"`
+		TLS       bool          `config:"tls"`
+		Timeout   time.Duration `config:"timeout"`
+		Addresses []string      `config:"addresses"`
+	}
+
+	cfg, err := ValidateConfig(testSpec, map[string]any{
+		"host":      "http://localhost",
+		"api_key":   "s3cr3t",
+		"addresses": "a:1, b:2",
+	})
+	if err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	var typed typedConfig
+	if err := Bind(cfg, &typed); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	if typed.Host != "http://localhost" || typed.APIKey != "s3cr3t" {
+		t.Errorf("Host/APIKey not bound: %+v", typed)
+	}
+	if typed.Port != 7700 || typed.Timeout != 5*time.Second {
+		t.Errorf("defaults not bound: %+v", typed)
+	}
+	if len(typed.Addresses) != 2 || typed.Addresses[1] != "b:2" {
+		t.Errorf("Addresses = %v, want [a:1 b:2]", typed.Addresses)
+	}
+}
+
+func TestGet_ValidatesBeforeFactory(t *testing.T) {
+	called := false
+	Register[string]("test-validate", "demo",
+		Metadata{Name: "demo", Category: "test-validate", ConfigSpec: testSpec},
+		func(config map[string]any) (string, error) {
+			called = true
+			return "ok", nil
+		},
+	)
+
+	factory, err := Get[string]("test-validate", "demo")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if _, err := factory(map[string]any{}); err == nil {
+		t.Fatal("expected validation error for empty config")
+	}
+	if called {
+		t.Error("factory must not be called with an invalid config")
+	}
+}
@@ -0,0 +1,383 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretFilePrefix marks a config value that is read from a file, e.g.
+// "file:/run/secrets/sap-client-secret". Relative paths are resolved against
+// the directory of the config file. Trailing newlines are removed.
+const SecretFilePrefix = "file:"
+
+// FileConfig is the content of a YAML provider configuration file. It declares
+// the default provider per category and the providers of individual tenants:
+//
+//	defaults:
+//	  search:
+//	    name: ${SEARCH_PROVIDER:-opensearch}
+//	    config:
+//	      addresses: ["${OPENSEARCH_URL}"]
+//	      username: admin
+//	      password: file:/run/secrets/opensearch-password
+//	tenants:
+//	  7c9e6679-7425-40de-944b-e07fc1f90ae7:
+//	    erp:
+//	      name: sap
+//	      config:
+//	        base_url: https://sap.example.com
+//	        client_secret: file:secrets/sap-client-secret
+//	      resilience:
+//	        timeout: 5s
+//
+// String values may reference environment variables as ${NAME} or
+// ${NAME:-default}; a variable that is unset and has no default is an error.
+// Values starting with SecretFilePrefix are replaced by the content of the file.
+//
+// Selections are validated against the ConfigSpec of their provider. Categories
+// without any registered provider are not validated, so one file can serve
+// services that use different categories.
+type FileConfig struct {
+	Defaults map[string]Selection            `yaml:"defaults"`
+	Tenants  map[string]map[string]Selection `yaml:"tenants"` // tenant ID -> category -> selection
+}
+
+// LoadConfigFile reads, interpolates and validates a provider configuration file.
+// Every problem is reported, each prefixed with its location in the file.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read provider config: %w", err)
+	}
+	cfg, err := parseConfigFile(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("provider config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfigFile parses provider configuration from YAML like LoadConfigFile.
+// Relative secret file paths are resolved against the working directory.
+func ParseConfigFile(data []byte) (*FileConfig, error) {
+	cfg, err := parseConfigFile(data, ".")
+	if err != nil {
+		return nil, fmt.Errorf("provider config: %w", err)
+	}
+	return cfg, nil
+}
+
+func parseConfigFile(data []byte, dir string) (*FileConfig, error) {
+	var cfg FileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	r := &configResolver{dir: dir}
+	for category, sel := range cfg.Defaults {
+		cfg.Defaults[category] = r.selection("defaults."+category, category, sel)
+	}
+	for tenantID, selections := range cfg.Tenants {
+		for category, sel := range selections {
+			selections[category] = r.selection("tenants."+tenantID+"."+category, category, sel)
+		}
+	}
+	if len(r.errs) > 0 {
+		sort.Slice(r.errs, func(i, j int) bool { return r.errs[i].Error() < r.errs[j].Error() })
+		return nil, errors.Join(r.errs...)
+	}
+	return &cfg, nil
+}
+
+// envReference matches ${NAME} and ${NAME:-default}.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// configResolver interpolates and validates the selections of a config file,
+// collecting all errors.
+type configResolver struct {
+	dir  string
+	errs []error
+}
+
+func (r *configResolver) selection(path, category string, sel Selection) Selection {
+	name, _ := r.value(path+".name", sel.Name).(string)
+	sel.Name = name
+	if config, ok := r.value(path+".config", sel.Config).(map[string]any); ok {
+		sel.Config = config
+	}
+	if policy, ok := r.value(path+".resilience", sel.Resilience).(map[string]any); ok {
+		sel.Resilience = policy
+	}
+
+	if sel.Name == "" {
+		r.errs = append(r.errs, fmt.Errorf("%s: provider name is required", path))
+		return sel
+	}
+	if len(List(category)) == 0 {
+		// The file may be shared by services; categories this service
+		// does not use have no registered providers to validate against
+		return sel
+	}
+	meta, ok := GetMetadata(category, sel.Name)
+	if !ok {
+		r.errs = append(r.errs, fmt.Errorf("%s: unknown provider: %s.%s", path, category, sel.Name))
+		return sel
+	}
+	if _, err := ValidateConfig(meta.ConfigSpec, sel.Config); err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: %w", path, err))
+	}
+	return sel
+}
+
+// value interpolates all strings in v, descending into maps and slices.
+func (r *configResolver) value(path string, v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.string(path, v)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = r.value(path+"."+key, item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = r.value(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return result
+	}
+	return v
+}
+
+func (r *configResolver) string(path, s string) string {
+	s = envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envReference.FindStringSubmatch(ref)
+		value, set := os.LookupEnv(m[1])
+		switch {
+		case strings.Contains(ref, ":-") && value == "":
+			// Like the shell, the default also replaces an empty value
+			return m[2]
+		case !set:
+			r.errs = append(r.errs, fmt.Errorf("%s: environment variable %s is not set", path, m[1]))
+		}
+		return value
+	})
+
+	file, ok := strings.CutPrefix(s, SecretFilePrefix)
+	if !ok {
+		return s
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(r.dir, file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: read secret: %w", path, err))
+		return ""
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
+// ConfigFile serves the selections of a provider configuration file to a
+// Resolver and applies changes to the file without restarting the service.
+//
+// Typical use:
+//
+//	file, err := provider.OpenConfigFile(path)
+//	...
+//	resolver := provider.NewResolver(file.Lookup(tenantConfigLookup))
+//	file.Apply(resolver)
+//	go file.Watch(ctx, resolver, 10*time.Second, logReload)
+type ConfigFile struct {
+	path string
+
+	mu      sync.RWMutex
+	current *FileConfig
+}
+
+// OpenConfigFile loads a provider configuration file.
+func OpenConfigFile(path string) (*ConfigFile, error) {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigFile{path: path, current: cfg}, nil
+}
+
+// Config returns the configuration currently in effect.
+func (f *ConfigFile) Config() *FileConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.current
+}
+
+// Apply sets the file's defaults on r, replacing defaults set before for the
+// same categories.
+func (f *ConfigFile) Apply(r *Resolver) {
+	for category, sel := range f.Config().Defaults {
+		r.SetDefault(category, sel)
+	}
+}
+
+// Lookup returns a TenantConfigLookup that adds the file's tenant selections to
+// the tenant configs returned by next, which may be nil. Selections in the
+// tenant's own config take precedence over the file.
+func (f *ConfigFile) Lookup(next TenantConfigLookup) TenantConfigLookup {
+	return func(ctx context.Context, tenantID string) (map[string]any, error) {
+		var tenantConfig map[string]any
+		if next != nil {
+			var err error
+			if tenantConfig, err = next(ctx, tenantID); err != nil {
+				return nil, err
+			}
+		}
+
+		fromFile := f.Config().Tenants[tenantID]
+		if len(fromFile) == 0 {
+			return tenantConfig, nil
+		}
+		own, err := ParseSelections(tenantConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		selections := make(map[string]any, len(fromFile)+len(own))
+		for category, sel := range fromFile {
+			selections[category] = sel
+		}
+		for category, sel := range own {
+			selections[category] = sel
+		}
+		merged := maps.Clone(tenantConfig)
+		if merged == nil {
+			merged = make(map[string]any)
+		}
+		merged[TenantConfigKey] = selections
+		return merged, nil
+	}
+}
+
+// Reload reads the file again and applies the differences to r. Only the
+// changed categories are touched: their defaults are replaced or removed and
+// the affected tenants are invalidated, so the resolver rebuilds exactly the
+// instances whose selection changed. Secret files are read again as well, so
+// rotated secrets are picked up.
+//
+// If the file is invalid, the current configuration stays in effect and the
+// error is returned. The returned changes name the modified selections, e.g.
+// "defaults.search" or "tenants.<id>.erp".
+func (f *ConfigFile) Reload(r *Resolver) ([]string, error) {
+	next, err := LoadConfigFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	prev := f.current
+	f.current = next
+	f.mu.Unlock()
+
+	var changes []string
+	for _, category := range changedSelections(prev.Defaults, next.Defaults) {
+		if sel, ok := next.Defaults[category]; ok {
+			r.SetDefault(category, sel)
+		} else {
+			r.RemoveDefault(category)
+		}
+		changes = append(changes, "defaults."+category)
+	}
+
+	tenants := make(map[string]bool)
+	for tenantID := range prev.Tenants {
+		tenants[tenantID] = true
+	}
+	for tenantID := range next.Tenants {
+		tenants[tenantID] = true
+	}
+	for tenantID := range tenants {
+		categories := changedSelections(prev.Tenants[tenantID], next.Tenants[tenantID])
+		if len(categories) == 0 {
+			continue
+		}
+		r.Invalidate(tenantID)
+		for _, category := range categories {
+			changes = append(changes, "tenants."+tenantID+"."+category)
+		}
+	}
+
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// Watch reloads the file every interval until ctx is done. onReload, if not
+// nil, is called with the changes of each reload that changed something and
+// with each new reload error.
+func (f *ConfigFile) Watch(ctx context.Context, r *Resolver, interval time.Duration, onReload func(changes []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changes, err := f.Reload(r)
+		switch {
+		case err != nil:
+			// Report a broken file once, not on every tick
+			if err.Error() != lastErr && onReload != nil {
+				onReload(nil, err)
+			}
+			lastErr = err.Error()
+		case len(changes) > 0:
+			lastErr = ""
+			if onReload != nil {
+				onReload(changes, nil)
+			}
+		default:
+			lastErr = ""
+		}
+	}
+}
+
+// changedSelections returns the categories that were added, removed or modified.
+func changedSelections(prev, next map[string]Selection) []string {
+	var changed []string
+	for category, sel := range next {
+		old, ok := prev[category]
+		if !ok || !sameSelection(old, sel) {
+			changed = append(changed, category)
+		}
+	}
+	for category := range prev {
+		if _, ok := next[category]; !ok {
+			changed = append(changed, category)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func sameSelection(a, b Selection) bool {
+	fa, errA := selectionFingerprint(a)
+	fb, errB := selectionFingerprint(b)
+	return errA == nil && errB == nil && fa == fb
+}
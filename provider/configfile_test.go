@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// configProvider is built by the "test-config" provider and records its config.
+type configProvider struct {
+	value string
+	token string
+}
+
+var (
+	configBuildsMu sync.Mutex
+	configBuilds   = map[string]int{}
+)
+
+func init() {
+	Register[*configProvider]("test-config", "static",
+		Metadata{Name: "static", Category: "test-config", ConfigSpec: []ConfigField{
+			{Key: "value", Type: "string", Required: true},
+			{Key: "token", Type: "secret"},
+			{Key: "port", Type: "int", Default: 8080},
+		}},
+		func(config map[string]any) (*configProvider, error) {
+			p := &configProvider{value: config["value"].(string)}
+			p.token, _ = config["token"].(string)
+			configBuildsMu.Lock()
+			configBuilds[p.value]++
+			configBuildsMu.Unlock()
+			return p, nil
+		},
+	)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigFile_InterpolatesEnvAndSecrets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "token"), "s3cret\n")
+	writeFile(t, filepath.Join(dir, "providers.yaml"), `
+defaults:
+  test-config:
+    name: ${TEST_CONFIG_PROVIDER:-static}
+    config:
+      value: https://${TEST_CONFIG_HOST}/api
+      token: file:token
+      port: 9200
+tenants:
+  t1:
+    test-config:
+      name: static
+      config:
+        value: ${TEST_CONFIG_EMPTY:-fallback}
+`)
+	t.Setenv("TEST_CONFIG_HOST", "erp.example.com")
+	t.Setenv("TEST_CONFIG_EMPTY", "")
+
+	cfg, err := LoadConfigFile(filepath.Join(dir, "providers.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile() error = %v", err)
+	}
+
+	def := cfg.Defaults["test-config"]
+	if def.Name != "static" {
+		t.Errorf("name = %q, want static", def.Name)
+	}
+	if got := def.Config["value"]; got != "https://erp.example.com/api" {
+		t.Errorf("value = %v, want interpolated URL", got)
+	}
+	if got := def.Config["token"]; got != "s3cret" {
+		t.Errorf("token = %q, want secret file content without newline", got)
+	}
+	if got := cfg.Tenants["t1"]["test-config"].Config["value"]; got != "fallback" {
+		t.Errorf("tenant value = %v, want default for empty variable", got)
+	}
+}
+
+func TestLoadConfigFile_ReportsAllProblems(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "providers.yaml")
+	writeFile(t, path, `
+defaults:
+  test-config:
+    name: static
+    config:
+      port: not-a-number
+      unexpected: true
+  unused-category:
+    name: anything
+tenants:
+  t1:
+    test-config:
+      name: missing
+  t2:
+    test-config:
+      name: static
+      config:
+        value: ${TEST_CONFIG_UNSET}
+        token: file:does-not-exist
+`)
+
+	_, err := LoadConfigFile(path)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"defaults.test-config: invalid provider config: missing required fields: value",
+		"unknown fields: unexpected",
+		`field "port"`,
+		"tenants.t1.test-config: unknown provider: test-config.missing",
+		"tenants.t2.test-config.config.value: environment variable TEST_CONFIG_UNSET is not set",
+		"tenants.t2.test-config.config.token: read secret",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error does not mention %q:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "unused-category") {
+		t.Errorf("categories without registered providers must not be validated:\n%s", msg)
+	}
+}
+
+func TestLoadConfigFile_RejectsUnknownKeys(t *testing.T) {
+	if _, err := ParseConfigFile([]byte("default:\n  test-config:\n    name: static\n")); err == nil {
+		t.Fatal("expected error for misspelled top-level key")
+	}
+}
+
+func TestConfigFile_ReloadRebuildsOnlyChangedInstances(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "providers.yaml")
+	writeFile(t, filepath.Join(dir, "token"), "v1")
+	writeFile(t, path, `
+defaults:
+  test-config:
+    name: static
+    config: {value: reload-default}
+tenants:
+  t1:
+    test-config:
+      name: static
+      config: {value: reload-t1, token: "file:token"}
+  t2:
+    test-config:
+      name: static
+      config: {value: reload-t2}
+`)
+
+	file, err := OpenConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewResolver(file.Lookup(nil))
+	file.Apply(r)
+	ctx := context.Background()
+
+	resolve := func(tenantID string) *configProvider {
+		t.Helper()
+		p, err := Resolve[*configProvider](ctx, r, tenantID, "test-config")
+		if err != nil {
+			t.Fatalf("Resolve(%s) error = %v", tenantID, err)
+		}
+		return p
+	}
+	t1, t2, t3 := resolve("t1"), resolve("t2"), resolve("t3")
+	if t1.token != "v1" || t2.value != "reload-t2" || t3.value != "reload-default" {
+		t.Fatalf("unexpected instances: %+v %+v %+v", t1, t2, t3)
+	}
+
+	// Unchanged file: nothing to do
+	if changes, err := file.Reload(r); err != nil || len(changes) != 0 {
+		t.Fatalf("Reload() = %v, %v; want no changes", changes, err)
+	}
+
+	// Rotating t1's secret rebuilds only t1
+	writeFile(t, filepath.Join(dir, "token"), "v2")
+	changes, err := file.Reload(r)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0] != "tenants.t1.test-config" {
+		t.Fatalf("changes = %v, want only tenants.t1.test-config", changes)
+	}
+	if got := resolve("t1"); got == t1 || got.token != "v2" {
+		t.Errorf("t1 was not rebuilt with the rotated secret")
+	}
+	if resolve("t2") != t2 || resolve("t3") != t3 {
+		t.Error("unchanged tenants were rebuilt")
+	}
+
+	// An invalid file keeps the current configuration
+	writeFile(t, path, "defaults:\n  test-config:\n    name: missing\n")
+	if _, err := file.Reload(r); err == nil {
+		t.Fatal("Reload() accepted an invalid file")
+	}
+	if resolve("t3") != t3 {
+		t.Error("invalid file changed the default instance")
+	}
+
+	// Removing t2 from the file moves it to the default
+	writeFile(t, path, `
+defaults:
+  test-config:
+    name: static
+    config: {value: reload-default}
+`)
+	if _, err := file.Reload(r); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if resolve("t2") != t3 {
+		t.Error("t2 does not use the default after its selection was removed")
+	}
+
+	configBuildsMu.Lock()
+	defer configBuildsMu.Unlock()
+	if configBuilds["reload-t2"] != 1 || configBuilds["reload-default"] != 1 {
+		t.Errorf("builds = %v, want one build each for t2 and the default", configBuilds)
+	}
+}
+
+func TestConfigFile_TenantConfigTakesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "providers.yaml")
+	writeFile(t, path, `
+tenants:
+  t1:
+    test-config:
+      name: static
+      config: {value: precedence-file}
+`)
+	file, err := OpenConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := file.Lookup(func(ctx context.Context, tenantID string) (map[string]any, error) {
+		return map[string]any{TenantConfigKey: map[string]any{
+			"test-config": map[string]any{"name": "static", "config": map[string]any{"value": "precedence-tenant"}},
+		}}, nil
+	})
+
+	p, err := Resolve[*configProvider](context.Background(), NewResolver(lookup), "t1", "test-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.value != "precedence-tenant" {
+		t.Errorf("value = %q, want the tenant's own selection", p.value)
+	}
+}
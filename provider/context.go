@@ -0,0 +1,18 @@
+package provider
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the ID of the tenant a provider call is made for.
+// Decorators such as tracing read it to attribute calls to tenants, including calls
+// to instances shared by several tenants.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID stored by WithTenant, or "" if none is set.
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
@@ -5,10 +5,12 @@ import "context"
 
 // CRMProvider abstracts CRM systems (MS Dynamics, Salesforce, etc.).
 type CRMProvider interface {
-	// SyncContact synchronizes a contact with the CRM.
+	// SyncContact synchronizes a contact with the CRM. Contacts are matched
+	// by email, so syncing the same contact again updates it.
 	SyncContact(ctx context.Context, contact Contact) (*SyncResult, error)
 
-	// GetAccount retrieves company data from the CRM.
+	// GetAccount retrieves company data from the CRM. An unknown account
+	// returns an error wrapping provider.ErrNotFound.
 	GetAccount(ctx context.Context, accountID string) (*Account, error)
 
 	// ListAccounts retrieves a list of companies.
@@ -38,6 +40,8 @@ type Account struct {
 }
 
 // AccountFilter contains filter criteria for listing accounts.
+// Limit 0 means the provider default; an offset past the last account
+// returns an empty list.
 type AccountFilter struct {
 	Query  string
 	Limit  int
@@ -0,0 +1,161 @@
+// Package crmtest is a conformance suite for crm.CRMProvider implementations.
+// It checks account pagination, repeated contact syncs, error types and
+// context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    crmtest.Run(t, func(t *testing.T) crm.CRMProvider {
+//	        return newSandboxProvider(t)
+//	    }, crmtest.Options{})
+//	}
+//
+// The contact sync check writes one contact with a conformance.invalid email
+// address to the CRM.
+package crmtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/crm"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that hold no data, such as noop.
+	// Not-found and pagination checks are skipped.
+	Stateless bool
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) crm.CRMProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("NotFound", s.stateful(s.testNotFound))
+	t.Run("Pagination", s.stateful(s.testPagination))
+	t.Run("RepeatedSync", s.testRepeatedSync)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) stateful(test func(t *testing.T)) func(t *testing.T) {
+	return func(t *testing.T) {
+		if s.opts.Stateless {
+			t.Skip("provider is stateless")
+		}
+		test(t)
+	}
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+
+	_, err := p.SyncContact(ctx, crm.Contact{Email: "canceled@conformance.invalid"})
+	providertest.ExpectCanceled(t, "SyncContact", err)
+	_, err = p.GetAccount(ctx, "1")
+	providertest.ExpectCanceled(t, "GetAccount", err)
+	_, err = p.ListAccounts(ctx, crm.AccountFilter{})
+	providertest.ExpectCanceled(t, "ListAccounts", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.SyncContact(ctx, crm.Contact{FirstName: "No", LastName: "Email"})
+	providertest.ExpectError(t, "SyncContact without email", err, invalid)
+	_, err = p.GetAccount(ctx, "")
+	providertest.ExpectError(t, "GetAccount with empty ID", err, invalid)
+	_, err = p.ListAccounts(ctx, crm.AccountFilter{Limit: -1})
+	providertest.ExpectError(t, "ListAccounts with negative limit", err, invalid)
+	_, err = p.ListAccounts(ctx, crm.AccountFilter{Offset: -1})
+	providertest.ExpectError(t, "ListAccounts with negative offset", err, invalid)
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	p := s.newProvider(t)
+
+	_, err := p.GetAccount(context.Background(), providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "GetAccount of unknown account", err, provider.ErrNotFound)
+}
+
+func (s *suite) testPagination(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	page := func(offset int) []crm.Account {
+		t.Helper()
+		accounts, err := p.ListAccounts(ctx, crm.AccountFilter{Limit: 1, Offset: offset})
+		if err != nil {
+			t.Fatalf("ListAccounts(offset %d) error = %v", offset, err)
+		}
+		if len(accounts) > 1 {
+			t.Errorf("ListAccounts(limit 1) returned %d accounts", len(accounts))
+		}
+		return accounts
+	}
+
+	first, second := page(0), page(1)
+	if len(first) == 0 || len(second) == 0 {
+		t.Fatal("pagination needs at least two accounts")
+	}
+	if first[0].ExternalID == second[0].ExternalID {
+		t.Errorf("offsets 0 and 1 both returned account %s", first[0].ExternalID)
+	}
+	if beyond := page(100000); len(beyond) != 0 {
+		t.Errorf("ListAccounts beyond the last page returned %d accounts", len(beyond))
+	}
+
+	account, err := p.GetAccount(ctx, first[0].ExternalID)
+	if err != nil {
+		t.Fatalf("GetAccount(%s) error = %v", first[0].ExternalID, err)
+	}
+	if account.ExternalID != first[0].ExternalID {
+		t.Errorf("GetAccount(%s) returned %s", first[0].ExternalID, account.ExternalID)
+	}
+}
+
+func (s *suite) testRepeatedSync(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	contact := crm.Contact{
+		Email:     providertest.UniqueName("contact-") + "@conformance.invalid",
+		FirstName: "Conformance",
+		LastName:  "Test",
+	}
+
+	first, err := p.SyncContact(ctx, contact)
+	if err != nil {
+		t.Fatalf("SyncContact() error = %v", err)
+	}
+	second, err := p.SyncContact(ctx, contact)
+	if err != nil {
+		t.Fatalf("SyncContact() again error = %v", err)
+	}
+	if second.Action == "created" {
+		t.Error("syncing the same contact twice created it twice")
+	}
+	if first.ExternalID != second.ExternalID {
+		t.Errorf("repeated sync returned ExternalID %q, first sync %q", second.ExternalID, first.ExternalID)
+	}
+}
@@ -3,6 +3,7 @@ package noop
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/crm"
@@ -31,6 +32,12 @@ func NewProvider(config map[string]any) (crm.CRMProvider, error) {
 }
 
 func (p *Provider) SyncContact(ctx context.Context, contact crm.Contact) (*crm.SyncResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if contact.Email == "" {
+		return nil, fmt.Errorf("noop: contact email is required: %w", provider.ErrInvalidArgument)
+	}
 	return &crm.SyncResult{
 		ExternalID: "noop-contact-001",
 		Action:     "unchanged",
@@ -38,6 +45,12 @@ func (p *Provider) SyncContact(ctx context.Context, contact crm.Contact) (*crm.S
 }
 
 func (p *Provider) GetAccount(ctx context.Context, accountID string) (*crm.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if accountID == "" {
+		return nil, fmt.Errorf("noop: account ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return &crm.Account{
 		ExternalID:    accountID,
 		Name:          "Demo Company",
@@ -47,6 +60,12 @@ func (p *Provider) GetAccount(ctx context.Context, accountID string) (*crm.Accou
 }
 
 func (p *Provider) ListAccounts(ctx context.Context, filter crm.AccountFilter) ([]crm.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("noop: limit and offset must not be negative: %w", provider.ErrInvalidArgument)
+	}
 	return []crm.Account{}, nil
 }
 
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/crm"
+	"github.com/gondolia/gondolia/provider/crm/crmtest"
+)
+
+func TestConformance(t *testing.T) {
+	crmtest.Run(t, func(t *testing.T) crm.CRMProvider {
+		return &Provider{}
+	}, crmtest.Options{Stateless: true})
+}
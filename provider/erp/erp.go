@@ -147,6 +147,9 @@ type Address struct {
 }
 
 // ReportFilter contains parameters for historical data queries.
+// Reports are returned newest first. A Limit of 0 selects the provider's
+// default page size. DateFrom and DateTo are inclusive; zero dates leave
+// the range open.
 type ReportFilter struct {
 	CustomerID string
 	DateFrom   time.Time
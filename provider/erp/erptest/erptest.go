@@ -0,0 +1,259 @@
+// Package erptest is a conformance suite for erp.ERPProvider implementations.
+// It checks argument validation, error types, context cancellation and the
+// pagination of the report methods.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    erptest.Run(t, func(t *testing.T) erp.ERPProvider {
+//	        return newTestProvider(t)
+//	    }, erptest.Options{CustomerID: "1000042", SKUs: []string{"4711"}})
+//	}
+//
+// The suite never calls CreateOrder with a valid request, so it does not
+// create data in the ERP system.
+package erptest
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that hold no data and answer every lookup
+	// with placeholder values, such as noop. Not-found checks are skipped.
+	Stateless bool
+
+	// CustomerID is a customer with at least three orders, shipments and
+	// invoices. The report pagination checks are skipped if it is empty.
+	CustomerID string
+
+	// SKUs are products known to the ERP. The availability check is skipped if empty.
+	SKUs []string
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) erp.ERPProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("NotFound", s.testNotFound)
+	t.Run("Availability", s.testAvailability)
+	t.Run("ReportPagination", s.testReportPagination)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+	item := erp.OrderItem{SKU: "SKU-1", Quantity: 1, Unit: "PC"}
+
+	_, err := p.CreateOrder(ctx, erp.CreateOrderRequest{Order: erp.Order{Items: []erp.OrderItem{item}}})
+	providertest.ExpectCanceled(t, "CreateOrder", err)
+	_, err = p.SimulateOrder(ctx, erp.SimulateOrderRequest{Items: []erp.SimulateItem{{SKU: "SKU-1", Quantity: 1}}})
+	providertest.ExpectCanceled(t, "SimulateOrder", err)
+	_, err = p.GetOrderStatus(ctx, "1")
+	providertest.ExpectCanceled(t, "GetOrderStatus", err)
+	_, err = p.GetProductAvailability(ctx, []string{"SKU-1"})
+	providertest.ExpectCanceled(t, "GetProductAvailability", err)
+	_, err = p.GetTierPrices(ctx, erp.TierPriceRequest{SKUs: []string{"SKU-1"}})
+	providertest.ExpectCanceled(t, "GetTierPrices", err)
+	_, err = p.SyncCompany(ctx, "1")
+	providertest.ExpectCanceled(t, "SyncCompany", err)
+	_, err = p.GetCompanyAddresses(ctx, "1")
+	providertest.ExpectCanceled(t, "GetCompanyAddresses", err)
+	_, err = p.GetOrderHistory(ctx, erp.ReportFilter{})
+	providertest.ExpectCanceled(t, "GetOrderHistory", err)
+	_, err = p.GetShipmentHistory(ctx, erp.ReportFilter{})
+	providertest.ExpectCanceled(t, "GetShipmentHistory", err)
+	_, err = p.GetInvoiceHistory(ctx, erp.ReportFilter{})
+	providertest.ExpectCanceled(t, "GetInvoiceHistory", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.CreateOrder(ctx, erp.CreateOrderRequest{})
+	providertest.ExpectError(t, "CreateOrder without items", err, invalid)
+	_, err = p.SimulateOrder(ctx, erp.SimulateOrderRequest{})
+	providertest.ExpectError(t, "SimulateOrder without items", err, invalid)
+	_, err = p.SimulateOrder(ctx, erp.SimulateOrderRequest{Items: []erp.SimulateItem{{SKU: "SKU-1", Quantity: 0}}})
+	providertest.ExpectError(t, "SimulateOrder with zero quantity", err, invalid)
+	_, err = p.GetOrderStatus(ctx, "")
+	providertest.ExpectError(t, "GetOrderStatus with empty ID", err, invalid)
+	_, err = p.SyncCompany(ctx, "")
+	providertest.ExpectError(t, "SyncCompany with empty ID", err, invalid)
+	_, err = p.GetCompanyAddresses(ctx, "")
+	providertest.ExpectError(t, "GetCompanyAddresses with empty ID", err, invalid)
+
+	now := time.Now()
+	filters := map[string]erp.ReportFilter{
+		"negative limit":      {Limit: -1},
+		"negative offset":     {Offset: -1},
+		"inverted date range": {DateFrom: now, DateTo: now.Add(-time.Hour)},
+	}
+	for name, f := range filters {
+		_, err = p.GetOrderHistory(ctx, f)
+		providertest.ExpectError(t, "GetOrderHistory with "+name, err, invalid)
+		_, err = p.GetShipmentHistory(ctx, f)
+		providertest.ExpectError(t, "GetShipmentHistory with "+name, err, invalid)
+		_, err = p.GetInvoiceHistory(ctx, f)
+		providertest.ExpectError(t, "GetInvoiceHistory with "+name, err, invalid)
+	}
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	if s.opts.Stateless {
+		t.Skip("provider is stateless")
+	}
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	_, err := p.GetOrderStatus(ctx, providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "GetOrderStatus of unknown order", err, provider.ErrNotFound)
+	_, err = p.SyncCompany(ctx, providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "SyncCompany of unknown customer", err, provider.ErrNotFound)
+}
+
+func (s *suite) testAvailability(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	stocks, err := p.GetProductAvailability(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetProductAvailability(nil) error = %v", err)
+	}
+	if len(stocks) != 0 {
+		t.Errorf("GetProductAvailability(nil) returned %d entries, want 0", len(stocks))
+	}
+
+	if len(s.opts.SKUs) == 0 {
+		t.Skip("no SKUs configured")
+	}
+	stocks, err = p.GetProductAvailability(ctx, s.opts.SKUs)
+	if err != nil {
+		t.Fatalf("GetProductAvailability() error = %v", err)
+	}
+	for _, stock := range stocks {
+		if !slices.Contains(s.opts.SKUs, stock.SKU) {
+			t.Errorf("GetProductAvailability() returned unrequested SKU %q", stock.SKU)
+		}
+	}
+}
+
+// report is the part of a report entry the pagination checks need.
+type report struct {
+	key  string
+	date time.Time
+}
+
+func (s *suite) testReportPagination(t *testing.T) {
+	if s.opts.CustomerID == "" {
+		t.Skip("no CustomerID configured")
+	}
+	p := s.newProvider(t)
+
+	methods := map[string]func(context.Context, erp.ReportFilter) ([]report, error){
+		"GetOrderHistory": func(ctx context.Context, f erp.ReportFilter) ([]report, error) {
+			list, err := p.GetOrderHistory(ctx, f)
+			var result []report
+			for _, r := range list {
+				result = append(result, report{r.ERPOrderNumber, r.OrderDate})
+			}
+			return result, err
+		},
+		"GetShipmentHistory": func(ctx context.Context, f erp.ReportFilter) ([]report, error) {
+			list, err := p.GetShipmentHistory(ctx, f)
+			var result []report
+			for _, r := range list {
+				result = append(result, report{r.ShipmentID, r.ShipDate})
+			}
+			return result, err
+		},
+		"GetInvoiceHistory": func(ctx context.Context, f erp.ReportFilter) ([]report, error) {
+			list, err := p.GetInvoiceHistory(ctx, f)
+			var result []report
+			for _, r := range list {
+				result = append(result, report{r.InvoiceNumber, r.InvoiceDate})
+			}
+			return result, err
+		},
+	}
+
+	for name, fetch := range methods {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			page := func(offset int) []report {
+				t.Helper()
+				result, err := fetch(ctx, erp.ReportFilter{CustomerID: s.opts.CustomerID, Limit: 2, Offset: offset})
+				if err != nil {
+					t.Fatalf("%s(offset %d) error = %v", name, offset, err)
+				}
+				if len(result) > 2 {
+					t.Errorf("%s(limit 2) returned %d entries", name, len(result))
+				}
+				return result
+			}
+
+			first, second := page(0), page(2)
+			if len(first) == 0 || len(second) == 0 {
+				t.Fatalf("%s: customer %s needs at least three entries", name, s.opts.CustomerID)
+			}
+			entries := append(first, second...)
+			for i := 1; i < len(entries); i++ {
+				if entries[i].key == entries[i-1].key {
+					t.Errorf("%s: entry %s returned twice", name, entries[i].key)
+				}
+				if entries[i].date.After(entries[i-1].date) {
+					t.Errorf("%s: entries not ordered newest first", name)
+				}
+			}
+			if beyond := page(100000); len(beyond) != 0 {
+				t.Errorf("%s beyond the last page returned %d entries", name, len(beyond))
+			}
+
+			// A date range around the newest entry includes it and nothing outside
+			day := first[0].date
+			inRange, err := fetch(ctx, erp.ReportFilter{
+				CustomerID: s.opts.CustomerID,
+				DateFrom:   day.Add(-time.Second),
+				DateTo:     day.Add(time.Second),
+			})
+			if err != nil {
+				t.Fatalf("%s(date range) error = %v", name, err)
+			}
+			if len(inRange) == 0 {
+				t.Errorf("%s(date range) did not return the entry of %s", name, day)
+			}
+			for _, r := range inRange {
+				if r.date.Before(day.Add(-time.Second)) || r.date.After(day.Add(time.Second)) {
+					t.Errorf("%s(date range) returned entry dated %s", name, r.date)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,820 @@
+// Package idoc provides an ERP provider for SAP systems that exchange IDocs as
+// XML files, e.g. over a file share or an SFTP server mounted as storage.
+//
+// Orders are written as ORDERS05 IDocs below the outbound prefix of a storage
+// provider. The provider polls the inbound prefix for the IDocs SAP sends
+// back and moves every file to the processed prefix, or to the error prefix if
+// it cannot be read:
+//
+//	ORDRSP  order response: confirms or rejects orders and assigns the SAP order number
+//	DESADV  shipping notification: shipments, shipped quantities of order items
+//	INVOIC  invoice
+//
+// They back GetOrderStatus and the order, shipment and invoice reports. On
+// Start the provider reads the outbound and processed files again, so the
+// storage is the only state.
+//
+// Orders are identified by their reference (Order.ExternalID or a generated
+// one), which is sent as the document number and returned by CreateOrder, and
+// by the SAP order number once the order response has arrived.
+//
+// IDoc files carry no synchronous answers: SimulateOrder, the availability and
+// price lookups and the customer master data return provider.ErrUnsupported.
+package idoc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+func init() {
+	provider.RegisterTyped[erp.ERPProvider, Config]("erp", "idoc",
+		provider.Metadata{
+			Name:        "idoc",
+			DisplayName: "SAP IDoc Files",
+			Category:    "erp",
+			Version:     "1.0.0",
+			Description: "Exchanges ORDERS05, ORDRSP, DESADV and INVOIC IDocs with SAP as XML files through a storage provider",
+			ConfigSpec:  configSpec,
+		},
+		func(cfg Config) (erp.ERPProvider, error) {
+			return Open(cfg)
+		},
+	)
+}
+
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{Key: "storage", Type: "string", Required: true, Description: "Name of the storage provider holding the IDoc files"},
+	{Key: "storage_config", Type: "map", Description: "Configuration of the storage provider"},
+	{Key: "outbound_prefix", Type: "string", Default: "idoc/out/", Description: "Prefix the ORDERS05 files are written to"},
+	{Key: "inbound_prefix", Type: "string", Default: "idoc/in/", Description: "Prefix polled for ORDRSP, DESADV and INVOIC files"},
+	{Key: "processed_prefix", Type: "string", Default: "idoc/processed/", Description: "Prefix inbound files are moved to after processing"},
+	{Key: "error_prefix", Type: "string", Default: "idoc/error/", Description: "Prefix inbound files that cannot be read are moved to"},
+	{Key: "poll_interval", Type: "duration", Default: "1m", Description: "Interval of polling the inbound prefix; 0 disables polling"},
+	{Key: "sender_port", Type: "string", Default: "GONDOLIA", Description: "Sender port of the control record (SNDPOR)"},
+	{Key: "sender_partner", Type: "string", Default: "GONDOLIA", Description: "Sender partner number of the control record (SNDPRN)"},
+	{Key: "receiver_port", Type: "string", Description: "Receiver port of the control record (RCVPOR), e.g. SAPPRD"},
+	{Key: "receiver_partner", Type: "string", Description: "Receiver partner number of the control record (RCVPRN)"},
+	{Key: "partner_type", Type: "string", Default: "LS", Description: "Partner type of sender and receiver (SNDPRT, RCVPRT)"},
+}
+
+// Config holds the provider configuration.
+type Config struct {
+	Storage         string            `config:"storage"`
+	StorageConfig   map[string]string `config:"storage_config"`
+	OutboundPrefix  string            `config:"outbound_prefix"`
+	InboundPrefix   string            `config:"inbound_prefix"`
+	ProcessedPrefix string            `config:"processed_prefix"`
+	ErrorPrefix     string            `config:"error_prefix"`
+	PollInterval    time.Duration     `config:"poll_interval"`
+	SenderPort      string            `config:"sender_port"`
+	SenderPartner   string            `config:"sender_partner"`
+	ReceiverPort    string            `config:"receiver_port"`
+	ReceiverPartner string            `config:"receiver_partner"`
+	PartnerType     string            `config:"partner_type"`
+}
+
+// Order statuses.
+const (
+	StatusSent       = "sent" // Waiting for the order response
+	StatusConfirmed  = "confirmed"
+	StatusProcessing = "processing" // Partially shipped
+	StatusShipped    = "shipped"
+	StatusRejected   = "rejected"
+)
+
+// defaultReportLimit is the page size of reports without a limit.
+const defaultReportLimit = 100
+
+// listPageSize is the page size of listing the storage.
+const listPageSize = 1000
+
+// Provider is the IDoc ERP provider.
+type Provider struct {
+	cfg     Config
+	store   storage.StorageProvider
+	owned   bool // The store was created by Open and is started and closed with the provider
+	parties partyInfo
+	now     func() time.Time
+
+	pollMu sync.Mutex // Serializes polls
+
+	mu        sync.Mutex
+	lastDoc   int64
+	seq       int
+	seen      map[string]bool // DOCNUMs of applied IDocs
+	orders    []*order
+	byNumber  map[string]*order // By reference and SAP order number
+	shipments []shipmentRecord
+	invoices  []invoiceRecord
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type order struct {
+	seq int
+	orderRecord
+	confirmed bool
+}
+
+// NewProvider creates the provider from a raw config map.
+func NewProvider(config map[string]any) (erp.ERPProvider, error) {
+	normalized, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := provider.Bind(normalized, &cfg); err != nil {
+		return nil, err
+	}
+	return Open(cfg)
+}
+
+// Open creates the provider with the storage provider named in cfg.
+func Open(cfg Config) (*Provider, error) {
+	factory, err := provider.Get[storage.StorageProvider]("storage", cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("idoc: %w", err)
+	}
+	storageConfig := make(map[string]any, len(cfg.StorageConfig))
+	for k, v := range cfg.StorageConfig {
+		storageConfig[k] = v
+	}
+	store, err := factory(storageConfig)
+	if err != nil {
+		return nil, fmt.Errorf("idoc: storage %s: %w", cfg.Storage, err)
+	}
+	p := New(cfg, store)
+	p.owned = true
+	return p, nil
+}
+
+// New creates the provider on top of store. The caller starts and closes store.
+func New(cfg Config, store storage.StorageProvider) *Provider {
+	return &Provider{
+		cfg:   cfg,
+		store: store,
+		parties: partyInfo{
+			SenderPort:      cfg.SenderPort,
+			SenderPartner:   cfg.SenderPartner,
+			ReceiverPort:    cfg.ReceiverPort,
+			ReceiverPartner: cfg.ReceiverPartner,
+			PartnerType:     cfg.PartnerType,
+		},
+		now:      time.Now,
+		seen:     make(map[string]bool),
+		byNumber: make(map[string]*order),
+	}
+}
+
+// SetClock replaces the clock used for document numbers and creation times.
+func (p *Provider) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// --- Lifecycle ---
+
+// Start reads the sent orders and processed IDocs, polls the inbound prefix
+// once and starts polling it in the background.
+func (p *Provider) Start(ctx context.Context) error {
+	if p.owned {
+		if err := provider.Start(ctx, p.store); err != nil {
+			return fmt.Errorf("idoc: start storage: %w", err)
+		}
+	}
+	for _, prefix := range []string{p.cfg.OutboundPrefix, p.cfg.ProcessedPrefix} {
+		if err := p.load(ctx, prefix); err != nil {
+			return err
+		}
+	}
+	// Unreadable files are moved to the error prefix and need not delay the start
+	p.Poll(ctx)
+
+	if p.cfg.PollInterval > 0 {
+		p.stop, p.done = make(chan struct{}), make(chan struct{})
+		go p.pollLoop(p.cfg.PollInterval)
+	}
+	return nil
+}
+
+// Close stops polling and closes the storage provider created by Open.
+func (p *Provider) Close(ctx context.Context) error {
+	if p.stop != nil {
+		close(p.stop)
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if p.owned {
+		return provider.Close(ctx, p.store)
+	}
+	return nil
+}
+
+func (p *Provider) pollLoop(interval time.Duration) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			// Unreadable files end up below the error prefix; storage
+			// errors are retried with the next tick
+			p.Poll(ctx)
+		}
+	}
+}
+
+// load applies the IDocs of the files below prefix.
+func (p *Provider) load(ctx context.Context, prefix string) error {
+	files, err := p.list(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		r, err := p.read(ctx, f.Path)
+		if err != nil {
+			return fmt.Errorf("idoc: %s: %w", f.Path, err)
+		}
+		p.apply(r)
+	}
+	return nil
+}
+
+// Poll processes the files below the inbound prefix in path order. Files that
+// cannot be read are moved to the error prefix and reported in the returned
+// error; the others are applied and moved to the processed prefix.
+func (p *Provider) Poll(ctx context.Context) error {
+	p.pollMu.Lock()
+	defer p.pollMu.Unlock()
+
+	files, err := p.list(ctx, p.cfg.InboundPrefix)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range files {
+		r, readErr := p.read(ctx, f.Path)
+		if readErr != nil && ctx.Err() != nil {
+			return readErr
+		}
+		target := p.cfg.ProcessedPrefix
+		if readErr != nil {
+			target = p.cfg.ErrorPrefix
+			errs = append(errs, fmt.Errorf("idoc: %s: %w", f.Path, readErr))
+		}
+		// Move before applying, so that a failed move does not apply the file twice
+		if err := p.move(ctx, f.Path, target+strings.TrimPrefix(f.Path, p.cfg.InboundPrefix)); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if readErr == nil {
+			p.apply(r)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Provider) list(ctx context.Context, prefix string) ([]storage.FileInfo, error) {
+	var files []storage.FileInfo
+	opts := storage.ListOptions{MaxKeys: listPageSize}
+	for {
+		page, err := p.store.List(ctx, prefix, opts)
+		if err != nil {
+			return nil, fmt.Errorf("idoc: list %s: %w", prefix, err)
+		}
+		for _, f := range page {
+			if strings.EqualFold(path.Ext(f.Path), ".xml") {
+				files = append(files, f)
+			}
+		}
+		if len(page) < listPageSize {
+			return files, nil
+		}
+		opts.Cursor = page[len(page)-1].Path
+	}
+}
+
+func (p *Provider) download(ctx context.Context, name string) ([]byte, error) {
+	body, _, err := p.store.Download(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (p *Provider) read(ctx context.Context, name string) (*records, error) {
+	data, err := p.download(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return parse(data)
+}
+
+func (p *Provider) move(ctx context.Context, from, to string) error {
+	data, err := p.download(ctx, from)
+	if err != nil {
+		return fmt.Errorf("idoc: move %s: %w", from, err)
+	}
+	if _, err := p.store.Upload(ctx, to, bytes.NewReader(data), storage.UploadOptions{ContentType: "application/xml"}); err != nil {
+		return fmt.Errorf("idoc: move %s: %w", from, err)
+	}
+	if err := p.store.Delete(ctx, from); err != nil {
+		return fmt.Errorf("idoc: move %s: %w", from, err)
+	}
+	return nil
+}
+
+// apply adds the records of a file that were not applied before.
+func (p *Provider) apply(r *records) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, o := range r.Orders {
+		if p.first(o.DocNum) {
+			p.seq++
+			added := &order{seq: p.seq, orderRecord: o}
+			p.orders = append(p.orders, added)
+			p.byNumber[o.Reference] = added
+		}
+	}
+	for _, resp := range r.Responses {
+		if p.first(resp.DocNum) {
+			p.confirm(resp)
+		}
+	}
+	for _, s := range r.Shipments {
+		if p.first(s.DocNum) {
+			p.shipments = append(p.shipments, s)
+		}
+	}
+	for _, i := range r.Invoices {
+		if p.first(i.DocNum) {
+			p.invoices = append(p.invoices, i)
+		}
+	}
+}
+
+// first reports whether the IDoc was not applied before and marks it applied.
+func (p *Provider) first(docNum string) bool {
+	if docNum != "" && p.seen[docNum] {
+		return false
+	}
+	p.seen[docNum] = true
+	return true
+}
+
+// confirm applies an order response to the order it answers. Responses to
+// orders that were not sent by the provider, e.g. entered in SAP, add the order.
+func (p *Provider) confirm(resp orderRecord) {
+	o := p.byNumber[resp.OrderNumber]
+	if o == nil {
+		o = p.byNumber[resp.CustomerPO]
+	}
+	if o == nil {
+		// SAP may keep the customer's own PO number instead of the reference
+		for _, candidate := range p.orders {
+			if !candidate.confirmed && candidate.CustomerPO == resp.CustomerPO && resp.CustomerPO != "" &&
+				(resp.CustomerID == "" || candidate.CustomerID == resp.CustomerID) {
+				o = candidate
+				break
+			}
+		}
+	}
+	if o == nil {
+		p.seq++
+		o = &order{seq: p.seq, orderRecord: resp}
+		p.orders = append(p.orders, o)
+	}
+
+	o.confirmed = true
+	o.OrderNumber = resp.OrderNumber
+	o.Rejected = resp.Rejected
+	if resp.Currency != "" {
+		o.Currency = resp.Currency
+	}
+	if resp.Total != 0 {
+		o.Total = resp.Total
+	}
+	if len(resp.Items) > 0 {
+		o.Items = resp.Items
+	}
+	p.byNumber[resp.OrderNumber] = o
+}
+
+// --- Orders ---
+
+// CreateOrder writes the order as ORDERS05 IDoc. The order is confirmed
+// asynchronously by the order response.
+func (p *Provider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Order.Items) == 0 {
+		return nil, fmt.Errorf("idoc: order has no items: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range req.Order.Items {
+		if item.SKU == "" {
+			return nil, fmt.Errorf("idoc: item without SKU: %w", provider.ErrInvalidArgument)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("idoc: item %s has quantity %v: %w", item.SKU, item.Quantity, provider.ErrInvalidArgument)
+		}
+	}
+	if req.Customer.ERPCustomerID == "" && req.Customer.SoldToParty == "" {
+		return nil, fmt.Errorf("idoc: order has no sold-to party: %w", provider.ErrInvalidArgument)
+	}
+
+	docNum, created := p.nextDocNum()
+	reference := req.Order.ExternalID
+	if reference == "" {
+		reference = "GD" + docNum[len(docNum)-14:]
+	}
+	p.mu.Lock()
+	_, exists := p.byNumber[reference]
+	p.mu.Unlock()
+	if exists {
+		return nil, fmt.Errorf("idoc: order %s was already sent: %w", reference, provider.ErrInvalidArgument)
+	}
+
+	data, err := encode(buildOrder(req, docNum, reference, created, p.parties))
+	if err != nil {
+		return nil, fmt.Errorf("idoc: encode order: %w", err)
+	}
+	name := p.cfg.OutboundPrefix + "ORDERS05_" + docNum + ".xml"
+	if _, err := p.store.Upload(ctx, name, bytes.NewReader(data), storage.UploadOptions{ContentType: "application/xml"}); err != nil {
+		return nil, fmt.Errorf("idoc: write %s: %w", name, err)
+	}
+	r, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("idoc: %s: %w", name, err)
+	}
+	p.apply(r)
+
+	result := &erp.CreateOrderResult{
+		ERPOrderNumber: reference,
+		Messages: []erp.Message{{
+			Type:    "info",
+			Code:    "IDOC_SENT",
+			Message: fmt.Sprintf("order sent as IDoc %s; awaiting the order response", docNum),
+		}},
+	}
+	for i, item := range req.Order.Items {
+		res := erp.OrderItemResult{SKU: item.SKU, ItemNumber: itemNumber(i)}
+		if item.RequestedPrice != nil {
+			res.ConfirmedPrice = *item.RequestedPrice
+		}
+		result.Items = append(result.Items, res)
+	}
+	return result, nil
+}
+
+// nextDocNum returns a new 16-digit IDoc number and the creation time. The
+// numbers are the creation times in microseconds, so they stay unique across
+// restarts.
+func (p *Provider) nextDocNum() (string, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now().UTC()
+	n := now.UnixMicro()
+	if n <= p.lastDoc {
+		n = p.lastDoc + 1
+	}
+	p.lastDoc = n
+	return fmt.Sprintf("%016d", n), now.Truncate(time.Second)
+}
+
+// SimulateOrder is not supported: IDocs are asynchronous.
+func (p *Provider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("idoc: order has no items: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range req.Items {
+		if item.SKU == "" || item.Quantity <= 0 {
+			return nil, fmt.Errorf("idoc: item %q has quantity %v: %w", item.SKU, item.Quantity, provider.ErrInvalidArgument)
+		}
+	}
+	return nil, fmt.Errorf("idoc: order simulation: %w", provider.ErrUnsupported)
+}
+
+// GetOrderStatus returns the status of an order by reference or SAP order number.
+func (p *Provider) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("idoc: order ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.byNumber[orderID]
+	if !ok {
+		return nil, fmt.Errorf("idoc: order %s: %w", orderID, provider.ErrNotFound)
+	}
+	return p.status(o), nil
+}
+
+// status derives the status of an order from its order response and shipments.
+func (p *Provider) status(o *order) *erp.OrderStatus {
+	result := &erp.OrderStatus{ERPOrderNumber: o.Reference}
+	if o.OrderNumber != "" {
+		result.ERPOrderNumber = o.OrderNumber
+	}
+
+	var ordered, shipped float64
+	for _, item := range o.Items {
+		s := erp.OrderItemStatus{ItemNumber: item.Number, SKU: item.SKU, Status: StatusSent}
+		if o.confirmed {
+			s.Status = StatusConfirmed
+		}
+		if o.OrderNumber != "" {
+			s.ShippedQty = p.shippedQty(o.OrderNumber, item)
+		}
+		switch {
+		case item.Rejected || o.Rejected:
+			s.Status = StatusRejected
+		case s.ShippedQty >= item.Quantity:
+			s.Status = StatusShipped
+		case s.ShippedQty > 0:
+			s.Status = StatusProcessing
+		}
+		if s.Status != StatusRejected {
+			ordered += item.Quantity
+			shipped += s.ShippedQty
+		}
+		result.Items = append(result.Items, s)
+	}
+
+	switch {
+	case o.Rejected || (o.confirmed && ordered == 0):
+		result.Status = StatusRejected
+	case shipped > 0 && shipped >= ordered:
+		result.Status = StatusShipped
+	case shipped > 0:
+		result.Status = StatusProcessing
+	case o.confirmed:
+		result.Status = StatusConfirmed
+	default:
+		result.Status = StatusSent
+	}
+	return result
+}
+
+// shippedQty sums the delivered quantities of an order item.
+func (p *Provider) shippedQty(orderNumber string, item itemRecord) float64 {
+	var qty float64
+	for _, s := range p.shipments {
+		for _, line := range s.Items {
+			if line.OrderNumber != orderNumber {
+				continue
+			}
+			if line.OrderItem != "" && sameItem(line.OrderItem, item.Number) || line.OrderItem == "" && line.SKU == item.SKU {
+				qty += line.Quantity
+			}
+		}
+	}
+	return qty
+}
+
+// sameItem compares item numbers, which SAP pads with zeros to six digits.
+func sameItem(a, b string) bool {
+	return strings.TrimLeft(a, "0") == strings.TrimLeft(b, "0")
+}
+
+// --- Synchronous lookups ---
+
+// GetProductAvailability is not supported; it returns an empty result for no SKUs.
+func (p *Provider) GetProductAvailability(ctx context.Context, skus []string) ([]erp.ProductStock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(skus) == 0 {
+		return []erp.ProductStock{}, nil
+	}
+	return nil, fmt.Errorf("idoc: availability: %w", provider.ErrUnsupported)
+}
+
+// GetTierPrices is not supported.
+func (p *Provider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("idoc: tier prices: %w", provider.ErrUnsupported)
+}
+
+// SyncCompany is not supported. The error also matches provider.ErrNotFound,
+// as no customer is known to the provider.
+func (p *Provider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("idoc: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
+	return nil, fmt.Errorf("idoc: customer %s: %w (%w)", erpCustomerID, provider.ErrUnsupported, provider.ErrNotFound)
+}
+
+// GetCompanyAddresses is not supported. The error also matches
+// provider.ErrNotFound, as no customer is known to the provider.
+func (p *Provider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("idoc: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
+	return nil, fmt.Errorf("idoc: addresses of customer %s: %w (%w)", erpCustomerID, provider.ErrUnsupported, provider.ErrNotFound)
+}
+
+// --- Reports ---
+
+// entry is a report entry with the fields used for filtering and ordering.
+type entry[T any] struct {
+	customerID string
+	date       time.Time
+	key        string
+	report     T
+}
+
+// page filters entries by customer and date, orders them newest first and
+// applies the limit and offset of f.
+func page[T any](entries []entry[T], f erp.ReportFilter) []T {
+	var matched []entry[T]
+	for _, e := range entries {
+		if f.CustomerID != "" && e.customerID != f.CustomerID {
+			continue
+		}
+		if (!f.DateFrom.IsZero() && e.date.Before(f.DateFrom)) || (!f.DateTo.IsZero() && e.date.After(f.DateTo)) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].date.Equal(matched[j].date) {
+			return matched[i].date.After(matched[j].date)
+		}
+		return matched[i].key > matched[j].key
+	})
+
+	limit := f.Limit
+	if limit == 0 {
+		limit = defaultReportLimit
+	}
+	result := []T{}
+	for i := f.Offset; i < len(matched) && len(result) < limit; i++ {
+		result = append(result, matched[i].report)
+	}
+	return result
+}
+
+func (p *Provider) report(ctx context.Context, f erp.ReportFilter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("idoc: %w", err)
+	}
+	return nil
+}
+
+// customerOf returns the customer of an SAP order.
+func (p *Provider) customerOf(orderNumber string) string {
+	if o, ok := p.byNumber[orderNumber]; ok {
+		return o.CustomerID
+	}
+	return ""
+}
+
+// GetOrderHistory returns the sent orders and the orders with an order response.
+func (p *Provider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	if err := p.report(ctx, req); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]entry[erp.OrderReport], len(p.orders))
+	for i, o := range p.orders {
+		status := p.status(o)
+		entries[i] = entry[erp.OrderReport]{
+			customerID: o.CustomerID,
+			date:       o.Date,
+			key:        fmt.Sprintf("%012d", o.seq),
+			report: erp.OrderReport{
+				ERPOrderNumber: status.ERPOrderNumber,
+				OrderDate:      o.Date,
+				CustomerPO:     o.CustomerPO,
+				TotalAmount:    o.Total,
+				Currency:       o.Currency,
+				Status:         status.Status,
+			},
+		}
+	}
+	return page(entries, req), nil
+}
+
+// GetShipmentHistory returns the shipments of the shipping notifications.
+func (p *Provider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) ([]erp.ShipmentReport, error) {
+	if err := p.report(ctx, req); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]entry[erp.ShipmentReport], len(p.shipments))
+	for i, s := range p.shipments {
+		customerID := s.CustomerID
+		if customerID == "" {
+			customerID = p.customerOf(s.OrderNumber)
+		}
+		entries[i] = entry[erp.ShipmentReport]{
+			customerID: customerID,
+			date:       s.Date,
+			key:        s.ShipmentID,
+			report: erp.ShipmentReport{
+				ShipmentID:     s.ShipmentID,
+				OrderNumber:    s.OrderNumber,
+				ShipDate:       s.Date,
+				TrackingNumber: s.Tracking,
+				Carrier:        s.Carrier,
+			},
+		}
+	}
+	return page(entries, req), nil
+}
+
+// GetInvoiceHistory returns the received invoices.
+func (p *Provider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	if err := p.report(ctx, req); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := make([]entry[erp.InvoiceReport], len(p.invoices))
+	for i, inv := range p.invoices {
+		customerID := inv.CustomerID
+		if customerID == "" {
+			customerID = p.customerOf(inv.OrderNumber)
+		}
+		entries[i] = entry[erp.InvoiceReport]{
+			customerID: customerID,
+			date:       inv.Date,
+			key:        inv.InvoiceNumber,
+			report: erp.InvoiceReport{
+				InvoiceNumber: inv.InvoiceNumber,
+				OrderNumber:   inv.OrderNumber,
+				InvoiceDate:   inv.Date,
+				DueDate:       inv.DueDate,
+				Amount:        inv.Amount,
+				Currency:      inv.Currency,
+				Status:        "open",
+			},
+		}
+	}
+	return page(entries, req), nil
+}
+
+// Metadata returns provider information.
+func (p *Provider) Metadata() erp.Metadata {
+	return erp.Metadata{
+		Name:         "idoc",
+		Version:      "1.0.0",
+		Protocol:     "idoc",
+		Capabilities: []string{"orders", "order_status", "reports"},
+	}
+}
@@ -0,0 +1,343 @@
+package idoc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/erp/erptest"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden compares got with the golden file, or rewrites it with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file:\n%s", name, got)
+	}
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// memStorage is an in-memory storage provider.
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{files: make(map[string][]byte)}
+}
+
+func (m *memStorage) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (*storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = data
+	return &storage.FileInfo{Path: path, Size: int64(len(data))}, nil
+}
+
+func (m *memStorage) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", path, provider.ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(data)), &storage.FileInfo{Path: path, Size: int64(len(data))}, nil
+}
+
+func (m *memStorage) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, path)
+	return nil
+}
+
+func (m *memStorage) Exists(ctx context.Context, path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[path]
+	return ok, nil
+}
+
+func (m *memStorage) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return "mem://" + path, nil
+}
+
+func (m *memStorage) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var paths []string
+	for path := range m.files {
+		if strings.HasPrefix(path, prefix) && path > opts.Cursor {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	if opts.MaxKeys > 0 && len(paths) > opts.MaxKeys {
+		paths = paths[:opts.MaxKeys]
+	}
+	files := make([]storage.FileInfo, len(paths))
+	for i, path := range paths {
+		files[i] = storage.FileInfo{Path: path, Size: int64(len(m.files[path]))}
+	}
+	return files, nil
+}
+
+func (m *memStorage) Metadata() storage.Metadata {
+	return storage.Metadata{Name: "memory"}
+}
+
+func (m *memStorage) paths(prefix string) []string {
+	files, _ := m.List(context.Background(), prefix, storage.ListOptions{})
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+var testConfig = Config{
+	OutboundPrefix:  "out/",
+	InboundPrefix:   "in/",
+	ProcessedPrefix: "processed/",
+	ErrorPrefix:     "error/",
+	SenderPort:      "GONDOLIA",
+	SenderPartner:   "GONDOLIA",
+	ReceiverPort:    "SAPPRD",
+	ReceiverPartner: "PRDCLNT100",
+	PartnerType:     "LS",
+}
+
+// startProvider starts a provider on store and closes it when the test ends.
+func startProvider(t *testing.T, store storage.StorageProvider) *Provider {
+	t.Helper()
+	p := New(testConfig, store)
+	p.SetClock(func() time.Time { return time.Date(2024, 5, 2, 14, 30, 0, 0, time.UTC) })
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close(context.Background()) })
+	return p
+}
+
+func (m *memStorage) put(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if _, err := m.Upload(context.Background(), path, bytes.NewReader(data), storage.UploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testOrder() erp.CreateOrderRequest {
+	price := 11.9
+	return erp.CreateOrderRequest{
+		TenantConfig: erp.TenantConfig{SalesOrg: "1010", DistChannel: "10", Division: "00", Currency: "CHF"},
+		Order: erp.Order{
+			ExternalID: "GD-ORDER-42",
+			CustomerPO: "PO-77",
+			Notes:      "Please deliver to gate 3\nCall before delivery",
+			Items: []erp.OrderItem{
+				{SKU: "4711", Quantity: 10, Unit: "PCE", Plant: "1000"},
+				{SKU: "4712", Quantity: 2.5, Unit: "MTR", RequestedPrice: &price},
+			},
+		},
+		Customer: erp.Customer{ERPCustomerID: "1000042", BillToParty: "1000044", PayerParty: "1000045"},
+		ShipTo:   erp.Address{Name: "Muster AG Baustelle", Street: "Seestrasse 12", PostalCode: "8002", City: "Zürich", Country: "CH"},
+	}
+}
+
+func TestGenerateOrders05(t *testing.T) {
+	doc := buildOrder(testOrder(), "0000000000000042", "GD-ORDER-42", time.Date(2024, 5, 2, 14, 30, 0, 0, time.UTC), partyInfo{
+		SenderPort: "GONDOLIA", SenderPartner: "GONDOLIA", ReceiverPort: "SAPPRD", ReceiverPartner: "PRDCLNT100", PartnerType: "LS",
+	})
+	data, err := encode(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "orders05.xml", data)
+}
+
+func TestParse(t *testing.T) {
+	for _, name := range []string{"orders05", "ordrsp", "desadv", "invoic"} {
+		t.Run(name, func(t *testing.T) {
+			r, err := parse(readTestdata(t, name+".xml"))
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			data, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, name+".json", append(data, '\n'))
+		})
+	}
+}
+
+func TestParse_RejectsUnknownMessageTypes(t *testing.T) {
+	data := bytes.ReplaceAll(readTestdata(t, "invoic.xml"), []byte("<MESTYP>INVOIC</MESTYP>"), []byte("<MESTYP>MATMAS</MESTYP>"))
+	if _, err := parse(data); err == nil || !strings.Contains(err.Error(), "MATMAS") {
+		t.Errorf("parse() error = %v, want unsupported message type", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	erptest.Run(t, func(t *testing.T) erp.ERPProvider {
+		store := newMemStorage()
+		for _, name := range []string{"ordrsp", "desadv", "invoic"} {
+			store.put(t, "in/"+name+".xml", readTestdata(t, name+".xml"))
+		}
+		return startProvider(t, store)
+	}, erptest.Options{CustomerID: "1000042"})
+}
+
+// response returns an ORDRSP IDoc confirming the order with reference.
+func response(docNum, reference, orderNumber string) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ORDERS05><IDOC BEGIN="1">
+<EDI_DC40 SEGMENT="1"><DOCNUM>` + docNum + `</DOCNUM><IDOCTYP>ORDERS05</IDOCTYP><MESTYP>ORDRSP</MESTYP><CREDAT>20240502</CREDAT><CRETIM>150000</CRETIM></EDI_DC40>
+<E1EDK01 SEGMENT="1"><CURCY>CHF</CURCY><BELNR>` + orderNumber + `</BELNR></E1EDK01>
+<E1EDKA1 SEGMENT="1"><PARVW>AG</PARVW><PARTN>1000042</PARTN></E1EDKA1>
+<E1EDK02 SEGMENT="1"><QUALF>001</QUALF><BELNR>` + reference + `</BELNR></E1EDK02>
+<E1EDP01 SEGMENT="1"><POSEX>000010</POSEX><MENGE>10</MENGE><NETWR>125</NETWR><E1EDP19 SEGMENT="1"><QUALF>002</QUALF><IDTNR>4711</IDTNR></E1EDP19></E1EDP01>
+<E1EDP01 SEGMENT="1"><POSEX>000020</POSEX><MENGE>2.5</MENGE><NETWR>29.75</NETWR><E1EDP19 SEGMENT="1"><QUALF>002</QUALF><IDTNR>4712</IDTNR></E1EDP19></E1EDP01>
+</IDOC></ORDERS05>`)
+}
+
+// delivery returns a DESADV IDoc delivering qty of the first item of an order.
+func delivery(docNum, shipmentID, orderNumber, qty string) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<DELVRY03><IDOC BEGIN="1">
+<EDI_DC40 SEGMENT="1"><DOCNUM>` + docNum + `</DOCNUM><IDOCTYP>DELVRY03</IDOCTYP><MESTYP>DESADV</MESTYP><CREDAT>20240506</CREDAT><CRETIM>170000</CRETIM></EDI_DC40>
+<E1EDL20 SEGMENT="1"><VBELN>` + shipmentID + `</VBELN>
+<E1EDL24 SEGMENT="1"><POSNR>000010</POSNR><MATNR>4711</MATNR><LFIMG>` + qty + `</LFIMG><VGBEL>` + orderNumber + `</VGBEL><VGPOS>000010</VGPOS></E1EDL24>
+</E1EDL20></IDOC></DELVRY03>`)
+}
+
+func TestOrderFlow(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStorage()
+	p := startProvider(t, store)
+
+	result, err := p.CreateOrder(ctx, testOrder())
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	if result.ERPOrderNumber != "GD-ORDER-42" || len(result.Items) != 2 || result.Items[1].ItemNumber != "000020" {
+		t.Errorf("CreateOrder() = %+v", result)
+	}
+	if out := store.paths("out/"); len(out) != 1 || !strings.HasPrefix(out[0], "out/ORDERS05_") {
+		t.Fatalf("outbound files = %v, want one ORDERS05 file", out)
+	}
+	if _, err := p.CreateOrder(ctx, testOrder()); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("CreateOrder() of a sent order error = %v, want ErrInvalidArgument", err)
+	}
+
+	wantStatus := func(id, want string, shipped float64) {
+		t.Helper()
+		status, err := p.GetOrderStatus(ctx, id)
+		if err != nil {
+			t.Fatalf("GetOrderStatus(%s) error = %v", id, err)
+		}
+		if status.Status != want || status.Items[0].ShippedQty != shipped {
+			t.Errorf("GetOrderStatus(%s) = %s with %v shipped, want %s with %v", id, status.Status, status.Items[0].ShippedQty, want, shipped)
+		}
+	}
+	wantStatus("GD-ORDER-42", StatusSent, 0)
+
+	store.put(t, "in/0001_ordrsp.xml", response("0000000000000101", "GD-ORDER-42", "0000012399"))
+	store.put(t, "in/0002_desadv.xml", delivery("0000000000000102", "80001299", "0000012399", "4"))
+	store.put(t, "in/0003_broken.xml", []byte("<ORDERS05><IDOC>"))
+	err = p.Poll(ctx)
+	if err == nil || !strings.Contains(err.Error(), "0003_broken.xml") {
+		t.Errorf("Poll() error = %v, want the broken file", err)
+	}
+	wantStatus("GD-ORDER-42", StatusProcessing, 4)
+	wantStatus("0000012399", StatusProcessing, 4)
+
+	if in := store.paths("in/"); len(in) != 0 {
+		t.Errorf("inbound files after Poll = %v, want none", in)
+	}
+	if processed := store.paths("processed/"); len(processed) != 2 {
+		t.Errorf("processed files = %v, want 2", processed)
+	}
+	if failed := store.paths("error/"); len(failed) != 1 || failed[0] != "error/0003_broken.xml" {
+		t.Errorf("error files = %v, want the broken file", failed)
+	}
+
+	store.put(t, "in/0004_desadv.xml", delivery("0000000000000103", "80001300", "0000012399", "6"))
+	if err := p.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	// The second item has not been delivered yet
+	wantStatus("0000012399", StatusProcessing, 10)
+
+	// A restarted provider reads its state back from the storage
+	restarted := startProvider(t, store)
+	status, err := restarted.GetOrderStatus(ctx, "GD-ORDER-42")
+	if err != nil || status.ERPOrderNumber != "0000012399" || status.Items[0].Status != StatusShipped || status.Items[1].Status != StatusConfirmed {
+		t.Errorf("GetOrderStatus() after restart = %+v, %v", status, err)
+	}
+	shipments, err := restarted.GetShipmentHistory(ctx, erp.ReportFilter{CustomerID: "1000042"})
+	if err != nil || len(shipments) != 2 {
+		t.Errorf("GetShipmentHistory() after restart = %+v, %v; want 2 shipments", shipments, err)
+	}
+}
+
+func TestSynchronousLookupsAreUnsupported(t *testing.T) {
+	p := startProvider(t, newMemStorage())
+	ctx := context.Background()
+
+	_, err := p.SimulateOrder(ctx, erp.SimulateOrderRequest{Items: []erp.SimulateItem{{SKU: "4711", Quantity: 1}}})
+	if !errors.Is(err, provider.ErrUnsupported) {
+		t.Errorf("SimulateOrder() error = %v, want ErrUnsupported", err)
+	}
+	_, err = p.SyncCompany(ctx, "1000042")
+	if !errors.Is(err, provider.ErrUnsupported) || !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("SyncCompany() error = %v, want ErrUnsupported and ErrNotFound", err)
+	}
+}
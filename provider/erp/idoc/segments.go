@@ -0,0 +1,665 @@
+package idoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// Message types of the exchanged IDocs.
+const (
+	messageOrders   = "ORDERS" // Outbound order, basic type ORDERS05
+	messageResponse = "ORDRSP" // Order response, basic type ORDERS05
+	messageDelivery = "DESADV" // Shipping notification, basic type DELVRY03
+	messageInvoice  = "INVOIC" // Invoice, basic type INVOIC02
+)
+
+// Qualifiers and codes of the segments used.
+const (
+	orgSalesOrg    = "008" // E1EDK14: sales organization
+	orgDistChannel = "007" // E1EDK14: distribution channel
+	orgDivision    = "006" // E1EDK14: division
+
+	dateDocument = "012" // E1EDK03: document date
+
+	refCustomerPO = "001" // E1EDK02: customer purchase order
+	refOrder      = "002" // E1EDK02: sales order (in INVOIC)
+	refInvoice    = "009" // E1EDK02: invoice
+
+	sumTotal      = "002" // E1EDS01: total value of an order
+	sumInvoiceNet = "010" // E1EDS01: total of the invoice items
+	sumInvoice    = "011" // E1EDS01: final amount of an invoice
+
+	materialNumber = "002" // E1EDP19: material number of the supplier
+
+	partnerSoldTo  = "AG"
+	partnerShipTo  = "WE"
+	partnerBillTo  = "RE"
+	partnerPayer   = "RG"
+	partnerCarrier = "SP" // E1ADRM1: forwarding agent
+
+	timeGoodsIssue = "006" // E1EDT13: goods issue
+
+	actionRejected = "003" // E1EDK01/E1EDP01: deletion or rejection
+
+	dateLayout = "20060102"
+	timeLayout = "150405"
+)
+
+// --- XML structure ---
+//
+// The types cover the segments and fields of ORDERS05, DELVRY03 and INVOIC02
+// the provider reads or writes; all other segments are ignored when parsing.
+
+// file is an IDoc XML file, which may hold several IDocs.
+type file struct {
+	XMLName xml.Name
+	IDocs   []document `xml:"IDOC"`
+}
+
+// seg carries the SEGMENT attribute of every segment.
+type seg struct {
+	Segment string `xml:"SEGMENT,attr"`
+}
+
+type document struct {
+	Begin    string    `xml:"BEGIN,attr"`
+	Control  control   `xml:"EDI_DC40"`
+	Header   *e1edk01  `xml:"E1EDK01"`
+	Orgs     []e1edk14 `xml:"E1EDK14"`
+	Dates    []e1edk03 `xml:"E1EDK03"`
+	Partners []e1edka1 `xml:"E1EDKA1"`
+	Refs     []e1edk02 `xml:"E1EDK02"`
+	Terms    []e1edk18 `xml:"E1EDK18"`
+	Texts    []e1edkt1 `xml:"E1EDKT1"`
+	Items    []e1edp01 `xml:"E1EDP01"`
+	Sums     []e1eds01 `xml:"E1EDS01"`
+	Delivery *e1edl20  `xml:"E1EDL20"`
+}
+
+// control is the control record.
+type control struct {
+	seg
+	TabNam  string `xml:"TABNAM"`
+	DocNum  string `xml:"DOCNUM"`
+	Direct  string `xml:"DIRECT"`
+	IDocTyp string `xml:"IDOCTYP"`
+	MesTyp  string `xml:"MESTYP"`
+	SndPor  string `xml:"SNDPOR"`
+	SndPrt  string `xml:"SNDPRT"`
+	SndPrn  string `xml:"SNDPRN"`
+	RcvPor  string `xml:"RCVPOR"`
+	RcvPrt  string `xml:"RCVPRT"`
+	RcvPrn  string `xml:"RCVPRN"`
+	CreDat  string `xml:"CREDAT"`
+	CreTim  string `xml:"CRETIM"`
+}
+
+type e1edk01 struct {
+	seg
+	Action string `xml:"ACTION,omitempty"`
+	Curcy  string `xml:"CURCY,omitempty"`
+	Belnr  string `xml:"BELNR,omitempty"`
+}
+
+type e1edk14 struct {
+	seg
+	Qualf string `xml:"QUALF"`
+	OrgID string `xml:"ORGID"`
+}
+
+type e1edk03 struct {
+	seg
+	Iddat string `xml:"IDDAT"`
+	Datum string `xml:"DATUM"`
+}
+
+type e1edka1 struct {
+	seg
+	Parvw string `xml:"PARVW"`
+	Partn string `xml:"PARTN,omitempty"`
+	Name1 string `xml:"NAME1,omitempty"`
+	Stras string `xml:"STRAS,omitempty"`
+	Ort01 string `xml:"ORT01,omitempty"`
+	Pstlz string `xml:"PSTLZ,omitempty"`
+	Land1 string `xml:"LAND1,omitempty"`
+	Regio string `xml:"REGIO,omitempty"`
+}
+
+type e1edk02 struct {
+	seg
+	Qualf string `xml:"QUALF"`
+	Belnr string `xml:"BELNR"`
+	Datum string `xml:"DATUM,omitempty"`
+}
+
+type e1edk18 struct {
+	seg
+	Qualf string `xml:"QUALF"`
+	Tage  string `xml:"TAGE,omitempty"`
+}
+
+type e1edkt1 struct {
+	seg
+	Tdid  string    `xml:"TDID"`
+	Lines []e1edkt2 `xml:"E1EDKT2"`
+}
+
+type e1edkt2 struct {
+	seg
+	Tdline string `xml:"TDLINE"`
+}
+
+type e1edp01 struct {
+	seg
+	Posex     string    `xml:"POSEX"`
+	Action    string    `xml:"ACTION,omitempty"`
+	Menge     string    `xml:"MENGE"`
+	Menee     string    `xml:"MENEE,omitempty"`
+	Vprei     string    `xml:"VPREI,omitempty"`
+	Netwr     string    `xml:"NETWR,omitempty"`
+	Werks     string    `xml:"WERKS,omitempty"`
+	Schedules []e1edp20 `xml:"E1EDP20"`
+	Objects   []e1edp19 `xml:"E1EDP19"`
+}
+
+type e1edp20 struct {
+	seg
+	Wmeng string `xml:"WMENG"`
+	Edatu string `xml:"EDATU,omitempty"`
+}
+
+type e1edp19 struct {
+	seg
+	Qualf string `xml:"QUALF"`
+	Idtnr string `xml:"IDTNR"`
+}
+
+type e1eds01 struct {
+	seg
+	Sumid string `xml:"SUMID"`
+	Summe string `xml:"SUMME"`
+	Sunit string `xml:"SUNIT,omitempty"`
+}
+
+type e1edl20 struct {
+	seg
+	Vbeln    string    `xml:"VBELN"`
+	Bolnr    string    `xml:"BOLNR"`
+	Partners []e1adrm1 `xml:"E1ADRM1"`
+	Times    []e1edt13 `xml:"E1EDT13"`
+	Items    []e1edl24 `xml:"E1EDL24"`
+}
+
+type e1adrm1 struct {
+	seg
+	PartnerQ  string `xml:"PARTNER_Q"`
+	PartnerID string `xml:"PARTNER_ID"`
+	Name1     string `xml:"NAME1"`
+}
+
+type e1edt13 struct {
+	seg
+	Qualf string `xml:"QUALF"`
+	Ntanf string `xml:"NTANF"`
+	Isdd  string `xml:"ISDD"`
+}
+
+type e1edl24 struct {
+	seg
+	Posnr string `xml:"POSNR"`
+	Matnr string `xml:"MATNR"`
+	Lfimg string `xml:"LFIMG"`
+	Vrkme string `xml:"VRKME"`
+	Vgbel string `xml:"VGBEL"`
+	Vgpos string `xml:"VGPOS"`
+}
+
+// --- Records ---
+
+// orderRecord is an order sent with ORDERS or answered with ORDRSP.
+type orderRecord struct {
+	DocNum      string       `json:"docnum"`
+	Reference   string       `json:"reference,omitempty"`    // E1EDK01-BELNR of ORDERS
+	OrderNumber string       `json:"order_number,omitempty"` // E1EDK01-BELNR of ORDRSP
+	CustomerID  string       `json:"customer_id,omitempty"`
+	CustomerPO  string       `json:"customer_po,omitempty"`
+	Date        time.Time    `json:"date"`
+	Currency    string       `json:"currency,omitempty"`
+	Total       float64      `json:"total,omitempty"`
+	Rejected    bool         `json:"rejected,omitempty"`
+	Items       []itemRecord `json:"items"`
+}
+
+type itemRecord struct {
+	Number       string    `json:"number"`
+	SKU          string    `json:"sku"`
+	Quantity     float64   `json:"quantity"`
+	Unit         string    `json:"unit,omitempty"`
+	Price        float64   `json:"price,omitempty"`
+	ConfirmedQty float64   `json:"confirmed_qty,omitempty"`
+	DeliveryDate time.Time `json:"delivery_date,omitzero"`
+	Rejected     bool      `json:"rejected,omitempty"`
+}
+
+// shipmentRecord is a delivery reported with DESADV.
+type shipmentRecord struct {
+	DocNum      string               `json:"docnum"`
+	ShipmentID  string               `json:"shipment_id"`
+	OrderNumber string               `json:"order_number"`
+	CustomerID  string               `json:"customer_id,omitempty"`
+	Date        time.Time            `json:"date"`
+	Tracking    string               `json:"tracking,omitempty"`
+	Carrier     string               `json:"carrier,omitempty"`
+	Items       []shipmentItemRecord `json:"items"`
+}
+
+type shipmentItemRecord struct {
+	OrderNumber string  `json:"order_number"`
+	OrderItem   string  `json:"order_item,omitempty"`
+	SKU         string  `json:"sku"`
+	Quantity    float64 `json:"quantity"`
+}
+
+// invoiceRecord is an invoice reported with INVOIC.
+type invoiceRecord struct {
+	DocNum        string    `json:"docnum"`
+	InvoiceNumber string    `json:"invoice_number"`
+	OrderNumber   string    `json:"order_number,omitempty"`
+	CustomerID    string    `json:"customer_id,omitempty"`
+	Date          time.Time `json:"date"`
+	DueDate       time.Time `json:"due_date,omitzero"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency,omitempty"`
+}
+
+// records are the records of an IDoc file.
+type records struct {
+	Orders    []orderRecord    `json:"orders,omitempty"`
+	Responses []orderRecord    `json:"responses,omitempty"`
+	Shipments []shipmentRecord `json:"shipments,omitempty"`
+	Invoices  []invoiceRecord  `json:"invoices,omitempty"`
+}
+
+// --- Generation ---
+
+// partyInfo is the control record data of the sender and receiver.
+type partyInfo struct {
+	SenderPort, SenderPartner     string
+	ReceiverPort, ReceiverPartner string
+	PartnerType                   string
+}
+
+// buildOrder returns the ORDERS05 IDoc of an order.
+func buildOrder(req erp.CreateOrderRequest, docNum, reference string, created time.Time, parties partyInfo) document {
+	created = created.UTC()
+	doc := document{
+		Begin: "1",
+		Control: control{
+			seg:     seg{"1"},
+			TabNam:  "EDI_DC40",
+			DocNum:  docNum,
+			Direct:  "2",
+			IDocTyp: "ORDERS05",
+			MesTyp:  messageOrders,
+			SndPor:  parties.SenderPort,
+			SndPrt:  parties.PartnerType,
+			SndPrn:  parties.SenderPartner,
+			RcvPor:  parties.ReceiverPort,
+			RcvPrt:  parties.PartnerType,
+			RcvPrn:  parties.ReceiverPartner,
+			CreDat:  created.Format(dateLayout),
+			CreTim:  created.Format(timeLayout),
+		},
+		Header: &e1edk01{seg: seg{"1"}, Curcy: req.TenantConfig.Currency, Belnr: reference},
+	}
+
+	tc := req.TenantConfig
+	for _, org := range []struct{ qualf, id string }{
+		{orgSalesOrg, tc.SalesOrg},
+		{orgDistChannel, tc.DistChannel},
+		{orgDivision, tc.Division},
+	} {
+		if org.id != "" {
+			doc.Orgs = append(doc.Orgs, e1edk14{seg: seg{"1"}, Qualf: org.qualf, OrgID: org.id})
+		}
+	}
+	doc.Dates = append(doc.Dates, e1edk03{seg: seg{"1"}, Iddat: dateDocument, Datum: created.Format(dateLayout)})
+
+	c := req.Customer
+	soldTo := c.SoldToParty
+	if soldTo == "" {
+		soldTo = c.ERPCustomerID
+	}
+	shipTo := e1edka1{seg: seg{"1"}, Parvw: partnerShipTo, Partn: c.ShipToParty}
+	if shipTo.Partn == "" {
+		// Ship to an address the ERP does not know
+		shipTo.Partn = req.ShipTo.ID
+		shipTo.Name1 = req.ShipTo.Name
+		shipTo.Stras = req.ShipTo.Street
+		shipTo.Ort01 = req.ShipTo.City
+		shipTo.Pstlz = req.ShipTo.PostalCode
+		shipTo.Land1 = req.ShipTo.Country
+		shipTo.Regio = req.ShipTo.Region
+	}
+	billTo := c.BillToParty
+	if billTo == "" {
+		billTo = req.BillTo.ID
+	}
+	for _, partner := range []e1edka1{
+		{seg: seg{"1"}, Parvw: partnerSoldTo, Partn: soldTo},
+		shipTo,
+		{seg: seg{"1"}, Parvw: partnerBillTo, Partn: billTo},
+		{seg: seg{"1"}, Parvw: partnerPayer, Partn: c.PayerParty},
+	} {
+		if partner != (e1edka1{seg: seg{"1"}, Parvw: partner.Parvw}) {
+			doc.Partners = append(doc.Partners, partner)
+		}
+	}
+
+	po := req.Order.CustomerPO
+	if po == "" {
+		po = reference
+	}
+	doc.Refs = append(doc.Refs, e1edk02{seg: seg{"1"}, Qualf: refCustomerPO, Belnr: po, Datum: created.Format(dateLayout)})
+
+	if notes := strings.TrimSpace(req.Order.Notes); notes != "" {
+		text := e1edkt1{seg: seg{"1"}, Tdid: "0001"}
+		for _, line := range strings.Split(notes, "\n") {
+			text.Lines = append(text.Lines, e1edkt2{seg: seg{"1"}, Tdline: strings.TrimSpace(line)})
+		}
+		doc.Texts = append(doc.Texts, text)
+	}
+
+	for i, item := range req.Order.Items {
+		line := e1edp01{
+			seg:   seg{"1"},
+			Posex: itemNumber(i),
+			Menge: formatDecimal(item.Quantity),
+			Menee: item.Unit,
+			Werks: item.Plant,
+			Objects: []e1edp19{
+				{seg: seg{"1"}, Qualf: materialNumber, Idtnr: item.SKU},
+			},
+		}
+		if item.RequestedPrice != nil {
+			line.Vprei = formatDecimal(*item.RequestedPrice)
+		}
+		doc.Items = append(doc.Items, line)
+	}
+	return doc
+}
+
+// itemNumber returns the number of the i-th order item: 000010, 000020, ...
+func itemNumber(i int) string {
+	return fmt.Sprintf("%06d", (i+1)*10)
+}
+
+func formatDecimal(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// encode returns the XML file of doc.
+func encode(doc document) ([]byte, error) {
+	f := file{XMLName: xml.Name{Local: doc.Control.IDocTyp}, IDocs: []document{doc}}
+	data, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// --- Parsing ---
+
+// parse reads the records of an IDoc XML file. Files with IDocs of other
+// message types are rejected.
+func parse(data []byte) (*records, error) {
+	var f file
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// SAP writes files with encoding="UTF-8" or "utf-8" only; other charsets are read as is
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid IDoc XML: %w", err)
+	}
+	if len(f.IDocs) == 0 {
+		return nil, fmt.Errorf("file contains no IDoc")
+	}
+
+	r := &records{}
+	for _, doc := range f.IDocs {
+		var err error
+		switch doc.Control.MesTyp {
+		case messageOrders:
+			var o orderRecord
+			if o, err = doc.order(); err == nil {
+				r.Orders = append(r.Orders, o)
+			}
+		case messageResponse:
+			var o orderRecord
+			if o, err = doc.order(); err == nil {
+				r.Responses = append(r.Responses, o)
+			}
+		case messageDelivery:
+			var s shipmentRecord
+			if s, err = doc.shipment(); err == nil {
+				r.Shipments = append(r.Shipments, s)
+			}
+		case messageInvoice:
+			var i invoiceRecord
+			if i, err = doc.invoice(); err == nil {
+				r.Invoices = append(r.Invoices, i)
+			}
+		default:
+			err = fmt.Errorf("unsupported message type %q", doc.Control.MesTyp)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("IDoc %s: %w", doc.Control.DocNum, err)
+		}
+	}
+	return r, nil
+}
+
+// created returns the creation time of the control record.
+func (d *document) created() time.Time {
+	t, err := time.Parse(dateLayout+timeLayout, d.Control.CreDat+d.Control.CreTim)
+	if err != nil {
+		t, _ = time.Parse(dateLayout, d.Control.CreDat)
+	}
+	return t
+}
+
+func (d *document) ref(qualf string) *e1edk02 {
+	for i := range d.Refs {
+		if d.Refs[i].Qualf == qualf {
+			return &d.Refs[i]
+		}
+	}
+	return nil
+}
+
+func (d *document) partner(parvw string) string {
+	for _, p := range d.Partners {
+		if p.Parvw == parvw {
+			return p.Partn
+		}
+	}
+	return ""
+}
+
+func (d *document) sum(sumid string) (float64, bool) {
+	for _, s := range d.Sums {
+		if s.Sumid == sumid {
+			return parseDecimal(s.Summe), true
+		}
+	}
+	return 0, false
+}
+
+func (d *document) order() (orderRecord, error) {
+	if d.Header == nil || d.Header.Belnr == "" {
+		return orderRecord{}, fmt.Errorf("missing document number (E1EDK01-BELNR)")
+	}
+	o := orderRecord{
+		DocNum:     d.Control.DocNum,
+		CustomerID: d.partner(partnerSoldTo),
+		Date:       d.created(),
+		Currency:   d.Header.Curcy,
+		Rejected:   d.Header.Action == actionRejected,
+	}
+	if d.Control.MesTyp == messageOrders {
+		o.Reference = d.Header.Belnr
+	} else {
+		o.OrderNumber = d.Header.Belnr
+	}
+	if ref := d.ref(refCustomerPO); ref != nil {
+		o.CustomerPO = ref.Belnr
+	}
+	for _, date := range d.Dates {
+		if date.Iddat == dateDocument {
+			if t, err := time.Parse(dateLayout, date.Datum); err == nil && d.Control.MesTyp != messageOrders {
+				o.Date = t
+			}
+		}
+	}
+
+	var total float64
+	for _, line := range d.Items {
+		item := itemRecord{
+			Number:   line.Posex,
+			Quantity: parseDecimal(line.Menge),
+			Unit:     line.Menee,
+			Price:    parseDecimal(line.Vprei),
+			Rejected: line.Action == actionRejected,
+		}
+		for _, obj := range line.Objects {
+			if obj.Qualf == materialNumber || item.SKU == "" {
+				item.SKU = obj.Idtnr
+			}
+		}
+		for _, schedule := range line.Schedules {
+			item.ConfirmedQty += parseDecimal(schedule.Wmeng)
+			if t, err := time.Parse(dateLayout, schedule.Edatu); err == nil && t.After(item.DeliveryDate) {
+				item.DeliveryDate = t
+			}
+		}
+		if item.SKU == "" {
+			return orderRecord{}, fmt.Errorf("item %s without material (E1EDP19)", line.Posex)
+		}
+		if line.Netwr != "" {
+			total += parseDecimal(line.Netwr)
+		} else {
+			total += item.Price * item.Quantity
+		}
+		o.Items = append(o.Items, item)
+	}
+	o.Total = total
+	if sum, ok := d.sum(sumTotal); ok {
+		o.Total = sum
+	}
+	return o, nil
+}
+
+func (d *document) shipment() (shipmentRecord, error) {
+	l := d.Delivery
+	if l == nil || l.Vbeln == "" {
+		return shipmentRecord{}, fmt.Errorf("missing delivery (E1EDL20-VBELN)")
+	}
+	s := shipmentRecord{
+		DocNum:     d.Control.DocNum,
+		ShipmentID: l.Vbeln,
+		Tracking:   l.Bolnr,
+		Date:       d.created().Truncate(24 * time.Hour),
+	}
+	for _, p := range l.Partners {
+		switch p.PartnerQ {
+		case partnerSoldTo:
+			s.CustomerID = p.PartnerID
+		case partnerCarrier:
+			s.Carrier = p.Name1
+			if s.Carrier == "" {
+				s.Carrier = p.PartnerID
+			}
+		}
+	}
+	for _, t := range l.Times {
+		if t.Qualf != timeGoodsIssue {
+			continue
+		}
+		// The actual goods issue date, or the planned one until it is posted
+		for _, date := range []string{t.Isdd, t.Ntanf} {
+			if parsed, err := time.Parse(dateLayout, date); err == nil {
+				s.Date = parsed
+				break
+			}
+		}
+	}
+	for _, line := range l.Items {
+		s.Items = append(s.Items, shipmentItemRecord{
+			OrderNumber: line.Vgbel,
+			OrderItem:   line.Vgpos,
+			SKU:         line.Matnr,
+			Quantity:    parseDecimal(line.Lfimg),
+		})
+		if s.OrderNumber == "" {
+			s.OrderNumber = line.Vgbel
+		}
+	}
+	return s, nil
+}
+
+func (d *document) invoice() (invoiceRecord, error) {
+	i := invoiceRecord{
+		DocNum:     d.Control.DocNum,
+		CustomerID: d.partner(partnerSoldTo),
+		Date:       d.created().Truncate(24 * time.Hour),
+	}
+	if d.Header != nil {
+		i.InvoiceNumber = d.Header.Belnr
+		i.Currency = d.Header.Curcy
+	}
+	if i.CustomerID == "" {
+		i.CustomerID = d.partner(partnerBillTo)
+	}
+	if ref := d.ref(refInvoice); ref != nil {
+		if i.InvoiceNumber == "" {
+			i.InvoiceNumber = ref.Belnr
+		}
+		if t, err := time.Parse(dateLayout, ref.Datum); err == nil {
+			i.Date = t
+		}
+	}
+	if i.InvoiceNumber == "" {
+		return invoiceRecord{}, fmt.Errorf("missing invoice number (E1EDK01-BELNR)")
+	}
+	if ref := d.ref(refOrder); ref != nil {
+		i.OrderNumber = ref.Belnr
+	}
+	for _, term := range d.Terms {
+		if days, err := strconv.Atoi(strings.TrimSpace(term.Tage)); err == nil && term.Qualf == "001" {
+			i.DueDate = i.Date.AddDate(0, 0, days)
+		}
+	}
+	if amount, ok := d.sum(sumInvoice); ok {
+		i.Amount = amount
+	} else {
+		i.Amount, _ = d.sum(sumInvoiceNet)
+	}
+	return i, nil
+}
+
+// parseDecimal reads IDoc numbers, which may be padded and carry a trailing minus sign.
+func parseDecimal(s string) float64 {
+	s = strings.TrimSpace(s)
+	negative := strings.HasSuffix(s, "-")
+	f, _ := strconv.ParseFloat(strings.TrimSuffix(s, "-"), 64)
+	if negative {
+		return -f
+	}
+	return f
+}
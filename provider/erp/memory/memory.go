@@ -0,0 +1,929 @@
+// Package memory provides a stateful in-memory ERP provider that simulates an
+// ERP system for development and end-to-end tests.
+//
+// Every tenant (see provider.WithTenant) gets its own copy of the seed data:
+// orders created with CreateOrder decrement stock and appear in the order,
+// shipment and invoice reports, and their status progresses when the tests
+// call Advance:
+//
+//	confirmed -> processing -> shipped -> delivered
+//
+// Shipping an order creates its shipment and invoice. Orders that would exceed
+// the customer's credit limit are created with status credit_hold and must be
+// released with Advance. Orders can be cancelled until they are shipped.
+//
+// The seed is a YAML (or JSON) file:
+//
+//	products:
+//	  - {sku: "4711", stock: 120, unit: PC, plant_code: "1000", lead_time_days: 3}
+//	tier_prices:
+//	  - {sku: "4711", min_qty: 1, price: 12.50, currency: CHF}
+//	  - {sku: "4711", min_qty: 100, price: 9.80, currency: CHF}
+//	  - {customer_id: "1000042", sku: "4711", min_qty: 1, price: 8.90, currency: CHF}
+//	companies:
+//	  - id: "1000042"
+//	    name: Muster AG
+//	    credit_limit: 50000
+//	    addresses:
+//	      - {id: "1", street: Bahnhofstrasse 1, postal_code: "8001", city: Zürich, country: CH}
+//
+// Data is lost on restart.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+func init() {
+	provider.RegisterTyped[erp.ERPProvider, Config]("erp", "memory",
+		provider.Metadata{
+			Name:        "memory",
+			DisplayName: "In-Memory ERP Simulator",
+			Category:    "erp",
+			Version:     "1.0.0",
+			Description: "A stateful ERP simulator for development and testing; data is lost on restart",
+			ConfigSpec:  configSpec,
+		},
+		func(cfg Config) (erp.ERPProvider, error) {
+			return Open(cfg)
+		},
+	)
+}
+
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{Key: "seed", Type: "string", Description: "Path of a YAML or JSON file with products, tier prices and companies"},
+	{Key: "currency", Type: "string", Default: "CHF", Description: "Currency of orders that do not specify one"},
+	{Key: "default_stock", Type: "float", Default: 0.0, Description: "Stock of products that are not in the seed"},
+	{Key: "lead_time_days", Type: "int", Default: 14, Description: "Lead time of products out of stock without their own lead time"},
+}
+
+// Config holds the simulator configuration.
+type Config struct {
+	Seed         string  `config:"seed"`
+	Currency     string  `config:"currency"`
+	DefaultStock float64 `config:"default_stock"`
+	LeadTimeDays int     `config:"lead_time_days"`
+}
+
+// Order statuses.
+const (
+	StatusCreditHold = "credit_hold"
+	StatusConfirmed  = "confirmed"
+	StatusProcessing = "processing"
+	StatusShipped    = "shipped"
+	StatusDelivered  = "delivered"
+	StatusCancelled  = "cancelled"
+)
+
+// nextStatus is the status each status advances to.
+var nextStatus = map[string]string{
+	StatusCreditHold: StatusConfirmed,
+	StatusConfirmed:  StatusProcessing,
+	StatusProcessing: StatusShipped,
+	StatusShipped:    StatusDelivered,
+}
+
+// defaultReportLimit is the page size of reports without a limit.
+const defaultReportLimit = 100
+
+// Seed is the initial data of every tenant.
+type Seed struct {
+	Products   []Product   `yaml:"products"`
+	TierPrices []TierPrice `yaml:"tier_prices"`
+	Companies  []Company   `yaml:"companies"`
+}
+
+// Product is the stock of a product.
+type Product struct {
+	SKU          string  `yaml:"sku"`
+	Stock        float64 `yaml:"stock"`
+	Unit         string  `yaml:"unit"`
+	PlantCode    string  `yaml:"plant_code"`
+	PlantName    string  `yaml:"plant_name"`
+	LeadTimeDays int     `yaml:"lead_time_days"` // Lead time when out of stock
+}
+
+// TierPrice is a quantity-based price. Prices without a customer are list
+// prices; customer prices replace the list prices of their SKU.
+type TierPrice struct {
+	CustomerID string    `yaml:"customer_id"`
+	SKU        string    `yaml:"sku"`
+	MinQty     float64   `yaml:"min_qty"`
+	Price      float64   `yaml:"price"`
+	Currency   string    `yaml:"currency"`
+	ValidFrom  time.Time `yaml:"valid_from"`
+	ValidTo    time.Time `yaml:"valid_to"` // Zero for open-ended prices
+}
+
+// Company is the master data of a customer. A zero CreditLimit means unlimited.
+type Company struct {
+	ID           string            `yaml:"id"`
+	Name         string            `yaml:"name"`
+	TaxID        string            `yaml:"tax_id"`
+	PaymentTerms string            `yaml:"payment_terms"`
+	Currency     string            `yaml:"currency"`
+	CreditLimit  float64           `yaml:"credit_limit"`
+	Attributes   map[string]string `yaml:"attributes"`
+	Addresses    []Address         `yaml:"addresses"`
+}
+
+// Address is a company address.
+type Address struct {
+	ID         string `yaml:"id"`
+	Name       string `yaml:"name"`
+	Street     string `yaml:"street"`
+	PostalCode string `yaml:"postal_code"`
+	City       string `yaml:"city"`
+	Country    string `yaml:"country"`
+	Region     string `yaml:"region"`
+}
+
+// LoadSeed reads a seed file.
+func LoadSeed(path string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("memory: read seed: %w", err)
+	}
+	var seed Seed
+	if err := yaml.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("memory: seed %s: %w", path, err)
+	}
+	return &seed, nil
+}
+
+// Provider is an in-memory ERP simulator. It is safe for concurrent use.
+type Provider struct {
+	cfg  Config
+	seed Seed
+	now  func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+// tenant is the state of one tenant.
+type tenant struct {
+	seq       int
+	products  map[string]*Product
+	companies map[string]Company
+	orders    map[string]*order
+	shipments []shipment
+	invoices  []invoice
+}
+
+type order struct {
+	seq        int
+	number     string
+	customerID string
+	customerPO string
+	created    time.Time
+	status     string
+	currency   string
+	items      []orderItem
+	total      float64
+}
+
+type orderItem struct {
+	number  string
+	sku     string
+	qty     float64
+	unit    string
+	price   float64
+	shipped float64
+}
+
+type shipment struct {
+	customerID string
+	seq        int
+	report     erp.ShipmentReport
+}
+
+type invoice struct {
+	customerID string
+	seq        int
+	report     erp.InvoiceReport
+}
+
+// NewProvider creates a new ERP simulator from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
+func NewProvider(config map[string]any) (erp.ERPProvider, error) {
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("memory: %w", err)
+	}
+	var cfg Config
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("memory: %w", err)
+	}
+	return Open(cfg)
+}
+
+// Open creates a new ERP simulator, loading the seed file of cfg if set.
+func Open(cfg Config) (*Provider, error) {
+	seed := &Seed{}
+	if cfg.Seed != "" {
+		var err error
+		if seed, err = LoadSeed(cfg.Seed); err != nil {
+			return nil, err
+		}
+	}
+	return New(cfg, *seed), nil
+}
+
+// New creates a new ERP simulator whose tenants start with seed.
+func New(cfg Config, seed Seed) *Provider {
+	if cfg.Currency == "" {
+		cfg.Currency = "CHF"
+	}
+	if cfg.LeadTimeDays == 0 {
+		cfg.LeadTimeDays = 14
+	}
+	return &Provider{
+		cfg:     cfg,
+		seed:    seed,
+		now:     time.Now,
+		tenants: make(map[string]*tenant),
+	}
+}
+
+// SetClock replaces the clock used for order, shipment and invoice dates.
+func (p *Provider) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+// tenant returns the state of a tenant, seeding it on first use. The caller holds p.mu.
+func (p *Provider) tenant(tenantID string) *tenant {
+	if t, ok := p.tenants[tenantID]; ok {
+		return t
+	}
+	t := &tenant{
+		products:  make(map[string]*Product, len(p.seed.Products)),
+		companies: make(map[string]Company, len(p.seed.Companies)),
+		orders:    make(map[string]*order),
+	}
+	for _, product := range p.seed.Products {
+		product := product
+		t.products[product.SKU] = &product
+	}
+	for _, company := range p.seed.Companies {
+		t.companies[company.ID] = company
+	}
+	p.tenants[tenantID] = t
+	return t
+}
+
+func (p *Provider) state(ctx context.Context) *tenant {
+	return p.tenant(provider.TenantFromContext(ctx))
+}
+
+// product returns the stock of sku, adding products that are not in the seed
+// with the default stock.
+func (p *Provider) product(t *tenant, sku string) *Product {
+	if product, ok := t.products[sku]; ok {
+		return product
+	}
+	product := &Product{SKU: sku, Stock: p.cfg.DefaultStock, Unit: "PC"}
+	t.products[sku] = product
+	return product
+}
+
+func (p *Provider) leadTime(product *Product, qty float64) int {
+	switch {
+	case product.Stock >= qty:
+		return 0
+	case product.LeadTimeDays > 0:
+		return product.LeadTimeDays
+	}
+	return p.cfg.LeadTimeDays
+}
+
+// tierPrices returns the prices of sku valid for the customer at the given
+// time, ordered by minimum quantity.
+func (p *Provider) tierPrices(customerID, sku, currency string, at time.Time) []TierPrice {
+	var list, own []TierPrice
+	for _, tp := range p.seed.TierPrices {
+		if tp.SKU != sku || (currency != "" && tp.Currency != currency) {
+			continue
+		}
+		if at.Before(tp.ValidFrom) || (!tp.ValidTo.IsZero() && at.After(tp.ValidTo)) {
+			continue
+		}
+		switch tp.CustomerID {
+		case "":
+			list = append(list, tp)
+		case customerID:
+			own = append(own, tp)
+		}
+	}
+	if len(own) > 0 {
+		list = own
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].MinQty < list[j].MinQty })
+	return list
+}
+
+// unitPrice returns the price of the highest tier that qty reaches.
+func (p *Provider) unitPrice(customerID, sku, currency string, qty float64, at time.Time) float64 {
+	var price float64
+	for _, tp := range p.tierPrices(customerID, sku, currency, at) {
+		if qty >= tp.MinQty {
+			price = tp.Price
+		}
+	}
+	return price
+}
+
+func (p *Provider) currency(cfg erp.TenantConfig) string {
+	if cfg.Currency != "" {
+		return cfg.Currency
+	}
+	return p.cfg.Currency
+}
+
+// exposure is the total of the customer's orders that are neither cancelled
+// nor delivered; delivered orders are assumed to be paid.
+func (t *tenant) exposure(customerID string) float64 {
+	var total float64
+	for _, o := range t.orders {
+		if o.customerID == customerID && o.status != StatusCancelled && o.status != StatusDelivered && o.status != StatusCreditHold {
+			total += o.total
+		}
+	}
+	return total
+}
+
+func (t *tenant) exceedsCreditLimit(customerID string, amount float64) bool {
+	company, ok := t.companies[customerID]
+	return ok && company.CreditLimit > 0 && t.exposure(customerID)+amount > company.CreditLimit
+}
+
+func creditMessage(customerID string) erp.Message {
+	return erp.Message{Type: "warning", Code: "CREDIT_LIMIT", Message: fmt.Sprintf("credit limit of customer %s exceeded", customerID)}
+}
+
+func validateItems[T any](items []T, qty func(T) (string, float64)) error {
+	if len(items) == 0 {
+		return fmt.Errorf("memory: order has no items: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range items {
+		sku, q := qty(item)
+		if sku == "" {
+			return fmt.Errorf("memory: item without SKU: %w", provider.ErrInvalidArgument)
+		}
+		if q <= 0 {
+			return fmt.Errorf("memory: item %s has quantity %v: %w", sku, q, provider.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+func (p *Provider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateItems(req.Order.Items, func(i erp.OrderItem) (string, float64) { return i.SKU, i.Quantity }); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.state(ctx)
+	now := p.now()
+	t.seq++
+	o := &order{
+		seq:        t.seq,
+		number:     fmt.Sprintf("MEM-%08d", t.seq),
+		customerID: req.Customer.ERPCustomerID,
+		customerPO: req.Order.CustomerPO,
+		created:    now,
+		status:     StatusConfirmed,
+		currency:   p.currency(req.TenantConfig),
+	}
+	result := &erp.CreateOrderResult{ERPOrderNumber: o.number}
+	for i, item := range req.Order.Items {
+		price := p.unitPrice(o.customerID, item.SKU, o.currency, item.Quantity, now)
+		if item.RequestedPrice != nil {
+			price = *item.RequestedPrice
+		}
+		product := p.product(t, item.SKU)
+		unit := item.Unit
+		if unit == "" {
+			unit = product.Unit
+		}
+		o.items = append(o.items, orderItem{
+			number: fmt.Sprintf("%06d", (i+1)*10),
+			sku:    item.SKU,
+			qty:    item.Quantity,
+			unit:   unit,
+			price:  price,
+		})
+		o.total += price * item.Quantity
+		result.Items = append(result.Items, erp.OrderItemResult{
+			SKU:            item.SKU,
+			ItemNumber:     o.items[i].number,
+			ConfirmedQty:   item.Quantity,
+			ConfirmedPrice: price,
+		})
+	}
+
+	if t.exceedsCreditLimit(o.customerID, o.total) {
+		o.status = StatusCreditHold
+		result.Messages = append(result.Messages, creditMessage(o.customerID))
+	}
+	// Stock is reserved on creation and may go negative (backorder)
+	for _, item := range o.items {
+		p.product(t, item.sku).Stock -= item.qty
+	}
+	t.orders[o.number] = o
+
+	result.Messages = append(result.Messages, erp.Message{
+		Type:    "success",
+		Message: fmt.Sprintf("order %s created", o.number),
+	})
+	return result, nil
+}
+
+func (p *Provider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := validateItems(req.Items, func(i erp.SimulateItem) (string, float64) { return i.SKU, i.Quantity }); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.state(ctx)
+	now := p.now()
+	customerID := req.Customer.ERPCustomerID
+	currency := p.currency(req.TenantConfig)
+	start := now
+	if req.DesiredDate != nil && req.DesiredDate.After(now) {
+		start = *req.DesiredDate
+	}
+
+	result := &erp.SimulateOrderResult{Totals: erp.Totals{Currency: currency}}
+	schedule := make(map[int]*erp.DeliverySchedule)
+	for _, item := range req.Items {
+		product := p.product(t, item.SKU)
+		price := p.unitPrice(customerID, item.SKU, currency, item.Quantity, now)
+		unit := item.Unit
+		if unit == "" {
+			unit = product.Unit
+		}
+		leadTime := p.leadTime(product, item.Quantity)
+		result.Items = append(result.Items, erp.SimulatedItem{
+			SKU:          item.SKU,
+			Quantity:     item.Quantity,
+			Unit:         unit,
+			UnitPrice:    price,
+			TotalPrice:   price * item.Quantity,
+			Available:    leadTime == 0,
+			LeadTimeDays: leadTime,
+		})
+		result.Totals.Subtotal += price * item.Quantity
+
+		delivery, ok := schedule[leadTime]
+		if !ok {
+			delivery = &erp.DeliverySchedule{Date: start.AddDate(0, 0, leadTime)}
+			schedule[leadTime] = delivery
+		}
+		delivery.Quantity += item.Quantity
+		delivery.Items = append(delivery.Items, item.SKU)
+	}
+	result.Totals.Total = result.Totals.Subtotal
+
+	for _, delivery := range schedule {
+		result.Schedule = append(result.Schedule, *delivery)
+	}
+	sort.Slice(result.Schedule, func(i, j int) bool { return result.Schedule[i].Date.Before(result.Schedule[j].Date) })
+
+	if t.exceedsCreditLimit(customerID, result.Totals.Total) {
+		result.Messages = append(result.Messages, creditMessage(customerID))
+	}
+	return result, nil
+}
+
+func (p *Provider) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("memory: order ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o, ok := p.state(ctx).orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("memory: order %s: %w", orderID, provider.ErrNotFound)
+	}
+	return o.orderStatus(), nil
+}
+
+func (o *order) orderStatus() *erp.OrderStatus {
+	status := &erp.OrderStatus{ERPOrderNumber: o.number, Status: o.status}
+	for _, item := range o.items {
+		status.Items = append(status.Items, erp.OrderItemStatus{
+			ItemNumber: item.number,
+			SKU:        item.sku,
+			Status:     o.status,
+			ShippedQty: item.shipped,
+		})
+	}
+	return status
+}
+
+func (p *Provider) GetProductAvailability(ctx context.Context, skus []string) ([]erp.ProductStock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := p.state(ctx)
+	stocks := make([]erp.ProductStock, 0, len(skus))
+	for _, sku := range skus {
+		product := p.product(t, sku)
+		stock := erp.ProductStock{
+			SKU:       sku,
+			PlantCode: product.PlantCode,
+			PlantName: product.PlantName,
+			Quantity:  max(product.Stock, 0),
+			Unit:      product.Unit,
+		}
+		if stock.Quantity == 0 {
+			stock.LeadTimeDays = p.leadTime(product, 1)
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, nil
+}
+
+func (p *Provider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	skus := req.SKUs
+	if len(skus) == 0 {
+		seen := make(map[string]bool)
+		for _, tp := range p.seed.TierPrices {
+			if !seen[tp.SKU] {
+				seen[tp.SKU] = true
+				skus = append(skus, tp.SKU)
+			}
+		}
+		sort.Strings(skus)
+	}
+
+	now := p.now()
+	prices := []erp.TierPrice{}
+	for _, sku := range skus {
+		for _, tp := range p.tierPrices(req.CustomerID, sku, req.Currency, now) {
+			prices = append(prices, erp.TierPrice{
+				SKU:       tp.SKU,
+				MinQty:    tp.MinQty,
+				Price:     tp.Price,
+				Currency:  tp.Currency,
+				ValidFrom: tp.ValidFrom,
+				ValidTo:   tp.ValidTo,
+			})
+		}
+	}
+	return prices, nil
+}
+
+func (p *Provider) company(ctx context.Context, erpCustomerID string) (Company, error) {
+	if err := ctx.Err(); err != nil {
+		return Company{}, err
+	}
+	if erpCustomerID == "" {
+		return Company{}, fmt.Errorf("memory: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	company, ok := p.state(ctx).companies[erpCustomerID]
+	if !ok {
+		return Company{}, fmt.Errorf("memory: customer %s: %w", erpCustomerID, provider.ErrNotFound)
+	}
+	return company, nil
+}
+
+func (p *Provider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	company, err := p.company(ctx, erpCustomerID)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]string, len(company.Attributes))
+	for k, v := range company.Attributes {
+		attributes[k] = v
+	}
+	currency := company.Currency
+	if currency == "" {
+		currency = p.cfg.Currency
+	}
+	return &erp.CompanyData{
+		ERPCustomerID: company.ID,
+		Name:          company.Name,
+		TaxID:         company.TaxID,
+		PaymentTerms:  company.PaymentTerms,
+		Currency:      currency,
+		CreditLimit:   company.CreditLimit,
+		Attributes:    attributes,
+	}, nil
+}
+
+func (p *Provider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	company, err := p.company(ctx, erpCustomerID)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]erp.Address, len(company.Addresses))
+	for i, a := range company.Addresses {
+		addresses[i] = erp.Address{
+			ID:         a.ID,
+			Name:       a.Name,
+			Street:     a.Street,
+			PostalCode: a.PostalCode,
+			City:       a.City,
+			Country:    a.Country,
+			Region:     a.Region,
+		}
+	}
+	return addresses, nil
+}
+
+// entry is a report entry with the fields used for filtering and ordering.
+type entry[T any] struct {
+	customerID string
+	date       time.Time
+	seq        int
+	report     T
+}
+
+// page filters entries by customer and date, orders them newest first and
+// applies the limit and offset of f.
+func page[T any](entries []entry[T], f erp.ReportFilter) []T {
+	var matched []entry[T]
+	for _, e := range entries {
+		if f.CustomerID != "" && e.customerID != f.CustomerID {
+			continue
+		}
+		if (!f.DateFrom.IsZero() && e.date.Before(f.DateFrom)) || (!f.DateTo.IsZero() && e.date.After(f.DateTo)) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].date.Equal(matched[j].date) {
+			return matched[i].date.After(matched[j].date)
+		}
+		return matched[i].seq > matched[j].seq
+	})
+
+	limit := f.Limit
+	if limit == 0 {
+		limit = defaultReportLimit
+	}
+	result := []T{}
+	for i := f.Offset; i < len(matched) && len(result) < limit; i++ {
+		result = append(result, matched[i].report)
+	}
+	return result
+}
+
+func (p *Provider) report(ctx context.Context, f erp.ReportFilter) (*tenant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("memory: %w", err)
+	}
+	return p.state(ctx), nil
+}
+
+func (p *Provider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, err := p.report(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry[erp.OrderReport], 0, len(t.orders))
+	for _, o := range t.orders {
+		entries = append(entries, entry[erp.OrderReport]{o.customerID, o.created, o.seq, erp.OrderReport{
+			ERPOrderNumber: o.number,
+			OrderDate:      o.created,
+			CustomerPO:     o.customerPO,
+			TotalAmount:    o.total,
+			Currency:       o.currency,
+			Status:         o.status,
+		}})
+	}
+	return page(entries, req), nil
+}
+
+func (p *Provider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) ([]erp.ShipmentReport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, err := p.report(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry[erp.ShipmentReport], len(t.shipments))
+	for i, s := range t.shipments {
+		entries[i] = entry[erp.ShipmentReport]{s.customerID, s.report.ShipDate, s.seq, s.report}
+	}
+	return page(entries, req), nil
+}
+
+func (p *Provider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, err := p.report(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry[erp.InvoiceReport], len(t.invoices))
+	for i, inv := range t.invoices {
+		entries[i] = entry[erp.InvoiceReport]{inv.customerID, inv.report.InvoiceDate, inv.seq, inv.report}
+	}
+	return page(entries, req), nil
+}
+
+func (p *Provider) Metadata() erp.Metadata {
+	return erp.Metadata{
+		Name:         "memory",
+		Version:      "1.0.0",
+		Protocol:     "none",
+		Capabilities: []string{"orders", "simulation", "availability", "pricing", "companies", "reports"},
+	}
+}
+
+// --- Test controls ---
+
+// order returns an order of a tenant. The caller holds p.mu.
+func (p *Provider) order(tenantID, orderNumber string) (*tenant, *order, error) {
+	t := p.tenant(tenantID)
+	o, ok := t.orders[orderNumber]
+	if !ok {
+		return nil, nil, fmt.Errorf("memory: order %s: %w", orderNumber, provider.ErrNotFound)
+	}
+	return t, o, nil
+}
+
+// Advance moves an order of the tenant to its next status and returns it.
+// Shipping creates the shipment and invoice of the order. Delivered and
+// cancelled orders cannot advance.
+func (p *Provider) Advance(tenantID, orderNumber string) (*erp.OrderStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, o, err := p.order(tenantID, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	next, ok := nextStatus[o.status]
+	if !ok {
+		return nil, fmt.Errorf("memory: order %s is %s: %w", orderNumber, o.status, provider.ErrInvalidArgument)
+	}
+	o.status = next
+	if next == StatusShipped {
+		p.ship(t, o)
+	}
+	return o.orderStatus(), nil
+}
+
+// AdvanceTo advances an order of the tenant until it reaches status.
+func (p *Provider) AdvanceTo(tenantID, orderNumber, status string) (*erp.OrderStatus, error) {
+	for {
+		current, err := p.orderStatus(tenantID, orderNumber)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status == status {
+			return current, nil
+		}
+		if _, err := p.Advance(tenantID, orderNumber); err != nil {
+			return nil, fmt.Errorf("memory: order %s cannot reach %s: %w", orderNumber, status, err)
+		}
+	}
+}
+
+func (p *Provider) orderStatus(tenantID, orderNumber string) (*erp.OrderStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, o, err := p.order(tenantID, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	return o.orderStatus(), nil
+}
+
+// ship creates the shipment and invoice of an order. The caller holds p.mu.
+func (p *Provider) ship(t *tenant, o *order) {
+	now := p.now()
+	for i := range o.items {
+		o.items[i].shipped = o.items[i].qty
+	}
+	t.shipments = append(t.shipments, shipment{customerID: o.customerID, seq: o.seq, report: erp.ShipmentReport{
+		ShipmentID:     fmt.Sprintf("DLV-%08d", o.seq),
+		OrderNumber:    o.number,
+		ShipDate:       now,
+		TrackingNumber: fmt.Sprintf("MEMTRACK%08d", o.seq),
+		Carrier:        "memory",
+	}})
+
+	dueDays := 30
+	if company, ok := t.companies[o.customerID]; ok {
+		if days, ok := paymentTermDays(company.PaymentTerms); ok {
+			dueDays = days
+		}
+	}
+	t.invoices = append(t.invoices, invoice{customerID: o.customerID, seq: o.seq, report: erp.InvoiceReport{
+		InvoiceNumber: fmt.Sprintf("INV-%08d", o.seq),
+		OrderNumber:   o.number,
+		InvoiceDate:   now,
+		DueDate:       now.AddDate(0, 0, dueDays),
+		Amount:        o.total,
+		Currency:      o.currency,
+		Status:        "open",
+	}})
+}
+
+// paymentTermDays reads payment terms such as "NET30" or "30".
+func paymentTermDays(terms string) (int, bool) {
+	var days int
+	if _, err := fmt.Sscanf(terms, "NET%d", &days); err == nil {
+		return days, true
+	}
+	if _, err := fmt.Sscanf(terms, "%d", &days); err == nil {
+		return days, true
+	}
+	return 0, false
+}
+
+// Cancel cancels an order of the tenant that has not been shipped and
+// returns its stock.
+func (p *Provider) Cancel(tenantID, orderNumber string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, o, err := p.order(tenantID, orderNumber)
+	if err != nil {
+		return err
+	}
+	switch o.status {
+	case StatusShipped, StatusDelivered, StatusCancelled:
+		return fmt.Errorf("memory: order %s is %s: %w", orderNumber, o.status, provider.ErrInvalidArgument)
+	}
+	o.status = StatusCancelled
+	for _, item := range o.items {
+		p.product(t, item.sku).Stock += item.qty
+	}
+	return nil
+}
+
+// SetStock sets the stock of a product of the tenant.
+func (p *Provider) SetStock(tenantID, sku string, quantity float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.product(p.tenant(tenantID), sku).Stock = quantity
+}
+
+// AddCompany adds or replaces a company of the tenant.
+func (p *Provider) AddCompany(tenantID string, company Company) error {
+	if company.ID == "" {
+		return fmt.Errorf("memory: company ID is required: %w", provider.ErrInvalidArgument)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tenant(tenantID).companies[company.ID] = company
+	return nil
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/erp/erptest"
+)
+
+const testSeed = `
+products:
+  - {sku: "4711", stock: 10, unit: PC, plant_code: "1000"}
+  - {sku: "4712", stock: 0, unit: PC, lead_time_days: 5}
+tier_prices:
+  - {sku: "4711", min_qty: 1, price: 12.5, currency: CHF}
+  - {sku: "4711", min_qty: 5, price: 10, currency: CHF}
+  - {customer_id: "1000042", sku: "4712", min_qty: 1, price: 3, currency: CHF}
+companies:
+  - id: "1000042"
+    name: Muster AG
+    payment_terms: NET10
+    credit_limit: 100
+    addresses:
+      - {id: "1", street: Bahnhofstrasse 1, postal_code: "8001", city: Zürich, country: CH}
+`
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	if err := os.WriteFile(path, []byte(testSeed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProvider(map[string]any{"seed": path})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return p.(*Provider)
+}
+
+func orderRequest(customerID string, items ...erp.OrderItem) erp.CreateOrderRequest {
+	return erp.CreateOrderRequest{
+		Order:    erp.Order{Items: items},
+		Customer: erp.Customer{ERPCustomerID: customerID},
+	}
+}
+
+func TestConformance(t *testing.T) {
+	erptest.Run(t, func(t *testing.T) erp.ERPProvider {
+		p := newTestProvider(t)
+		ctx := context.Background()
+		for i := 0; i < 3; i++ {
+			result, err := p.CreateOrder(ctx, orderRequest("2000001", erp.OrderItem{SKU: "4711", Quantity: 1}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := p.AdvanceTo("", result.ERPOrderNumber, StatusShipped); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return p
+	}, erptest.Options{CustomerID: "2000001", SKUs: []string{"4711", "4712"}})
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := provider.WithTenant(context.Background(), "t1")
+
+	result, err := p.CreateOrder(ctx, orderRequest("1000042", erp.OrderItem{SKU: "4711", Quantity: 6}))
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	if got := result.Items[0].ConfirmedPrice; got != 10 {
+		t.Errorf("ConfirmedPrice = %v, want tier price 10", got)
+	}
+
+	stocks, err := p.GetProductAvailability(ctx, []string{"4711"})
+	if err != nil || stocks[0].Quantity != 4 {
+		t.Fatalf("GetProductAvailability() = %+v, %v; want 4 left", stocks, err)
+	}
+	other, _ := p.GetProductAvailability(context.Background(), []string{"4711"})
+	if other[0].Quantity != 10 {
+		t.Errorf("stock of another tenant = %v, want the seeded 10", other[0].Quantity)
+	}
+
+	number := result.ERPOrderNumber
+	for _, want := range []string{StatusProcessing, StatusShipped, StatusDelivered} {
+		status, err := p.Advance("t1", number)
+		if err != nil || status.Status != want {
+			t.Fatalf("Advance() = %+v, %v; want %s", status, err, want)
+		}
+	}
+	if _, err := p.Advance("t1", number); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("Advance() of delivered order = %v, want ErrInvalidArgument", err)
+	}
+
+	status, err := p.GetOrderStatus(ctx, number)
+	if err != nil || status.Items[0].ShippedQty != 6 {
+		t.Errorf("GetOrderStatus() = %+v, %v; want shipped quantity 6", status, err)
+	}
+	invoices, err := p.GetInvoiceHistory(ctx, erp.ReportFilter{CustomerID: "1000042"})
+	if err != nil || len(invoices) != 1 || invoices[0].Amount != 60 {
+		t.Fatalf("GetInvoiceHistory() = %+v, %v; want one invoice of 60", invoices, err)
+	}
+	if days := invoices[0].DueDate.Sub(invoices[0].InvoiceDate).Hours() / 24; days != 10 {
+		t.Errorf("invoice due after %v days, want NET10", days)
+	}
+	orders, _ := p.GetOrderHistory(context.Background(), erp.ReportFilter{})
+	if len(orders) != 0 {
+		t.Errorf("order history of another tenant has %d orders", len(orders))
+	}
+}
+
+func TestCreditLimitAndCancel(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := context.Background()
+
+	// 4 x 12.5 + 20 x 3 = 110 exceeds the credit limit of 100
+	result, err := p.CreateOrder(ctx, orderRequest("1000042", erp.OrderItem{SKU: "4711", Quantity: 4}, erp.OrderItem{SKU: "4712", Quantity: 20}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _ := p.GetOrderStatus(ctx, result.ERPOrderNumber)
+	if status.Status != StatusCreditHold {
+		t.Fatalf("status = %s, want %s", status.Status, StatusCreditHold)
+	}
+
+	sim, err := p.SimulateOrder(ctx, erp.SimulateOrderRequest{Items: []erp.SimulateItem{{SKU: "4712", Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Items[0].Available || sim.Items[0].LeadTimeDays != 5 {
+		t.Errorf("simulated item = %+v, want unavailable with lead time 5", sim.Items[0])
+	}
+
+	if err := p.Cancel("", result.ERPOrderNumber); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	stocks, _ := p.GetProductAvailability(ctx, []string{"4711", "4712"})
+	if stocks[0].Quantity != 10 || stocks[1].Quantity != 0 {
+		t.Errorf("stocks after cancel = %+v, want seeded stock", stocks)
+	}
+	if err := p.Cancel("", result.ERPOrderNumber); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("second Cancel() = %v, want ErrInvalidArgument", err)
+	}
+}
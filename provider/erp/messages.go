@@ -0,0 +1,29 @@
+package erp
+
+import "errors"
+
+// MessageCarrier is implemented by provider errors that carry the ERP's
+// messages, e.g. the error details of a rejected order.
+type MessageCarrier interface {
+	ERPMessages() []Message
+}
+
+// ErrorMessages returns the ERP messages carried by err or any error it
+// wraps, or nil if there are none.
+func ErrorMessages(err error) []Message {
+	var carrier MessageCarrier
+	if errors.As(err, &carrier) {
+		return carrier.ERPMessages()
+	}
+	return nil
+}
+
+// HasErrors reports whether messages contains an error message.
+func HasErrors(messages []Message) bool {
+	for _, m := range messages {
+		if m.Type == "error" {
+			return true
+		}
+	}
+	return false
+}
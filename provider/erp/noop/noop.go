@@ -4,6 +4,7 @@ package noop
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/erp"
@@ -32,6 +33,12 @@ func NewProvider(config map[string]any) (erp.ERPProvider, error) {
 }
 
 func (p *Provider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Order.Items) == 0 {
+		return nil, fmt.Errorf("noop: order has no items: %w", provider.ErrInvalidArgument)
+	}
 	return &erp.CreateOrderResult{
 		ERPOrderNumber: "NOOP-00000001",
 		Items:          []erp.OrderItemResult{},
@@ -40,6 +47,17 @@ func (p *Provider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest)
 }
 
 func (p *Provider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("noop: order has no items: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("noop: quantity of %s must be positive: %w", item.SKU, provider.ErrInvalidArgument)
+		}
+	}
 	items := make([]erp.SimulatedItem, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = erp.SimulatedItem{
@@ -68,6 +86,12 @@ func (p *Provider) SimulateOrder(ctx context.Context, req erp.SimulateOrderReque
 }
 
 func (p *Provider) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("noop: order ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return &erp.OrderStatus{
 		ERPOrderNumber: orderID,
 		Status:         "unknown",
@@ -76,6 +100,9 @@ func (p *Provider) GetOrderStatus(ctx context.Context, orderID string) (*erp.Ord
 }
 
 func (p *Provider) GetProductAvailability(ctx context.Context, skus []string) ([]erp.ProductStock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	stocks := make([]erp.ProductStock, len(skus))
 	for i, sku := range skus {
 		stocks[i] = erp.ProductStock{
@@ -91,10 +118,19 @@ func (p *Provider) GetProductAvailability(ctx context.Context, skus []string) ([
 }
 
 func (p *Provider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return []erp.TierPrice{}, nil
 }
 
 func (p *Provider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("noop: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return &erp.CompanyData{
 		ERPCustomerID: erpCustomerID,
 		Name:          "Demo Company",
@@ -107,18 +143,42 @@ func (p *Provider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.
 }
 
 func (p *Provider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("noop: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return []erp.Address{}, nil
 }
 
 func (p *Provider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("noop: %w", err)
+	}
 	return []erp.OrderReport{}, nil
 }
 
 func (p *Provider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) ([]erp.ShipmentReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("noop: %w", err)
+	}
 	return []erp.ShipmentReport{}, nil
 }
 
 func (p *Provider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("noop: %w", err)
+	}
 	return []erp.InvoiceReport{}, nil
 }
 
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/erp/erptest"
+)
+
+func TestConformance(t *testing.T) {
+	erptest.Run(t, func(t *testing.T) erp.ERPProvider {
+		return &Provider{}
+	}, erptest.Options{Stateless: true, SKUs: []string{"SKU-1", "SKU-2"}})
+}
@@ -0,0 +1,394 @@
+package odata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// Error is an error response of the OData service. Messages holds the error
+// and its details as ERP messages.
+type Error struct {
+	StatusCode int
+	Code       string
+	Messages   []erp.Message
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("odata: HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if len(e.Messages) > 0 {
+		msg += ": " + e.Messages[0].Message
+	}
+	return msg
+}
+
+// ERPMessages implements erp.MessageCarrier.
+func (e *Error) ERPMessages() []erp.Message {
+	return e.Messages
+}
+
+// Unwrap classifies the error: missing entities are provider.ErrNotFound and
+// rejected requests provider.ErrInvalidArgument. Other errors, such as server
+// errors, may be retried.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return provider.ErrNotFound
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return provider.ErrInvalidArgument
+	}
+	return nil
+}
+
+// odataError is the OData v4 JSON error format.
+type odataError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Target  string `json:"target"`
+		Details []struct {
+			Code     string `json:"code"`
+			Message  string `json:"message"`
+			Target   string `json:"target"`
+			Severity any    `json:"@Common.numericSeverity"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func parseError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var body odataError
+	if err := json.Unmarshal(data, &body); err != nil || body.Error.Message == "" {
+		if text := strings.TrimSpace(string(data)); text != "" && len(text) < 500 {
+			e.Messages = append(e.Messages, erp.Message{Type: "error", Message: text})
+		} else {
+			e.Messages = append(e.Messages, erp.Message{Type: "error", Message: http.StatusText(resp.StatusCode)})
+		}
+		return e
+	}
+	e.Code = body.Error.Code
+	e.Messages = append(e.Messages, erp.Message{Type: "error", Code: body.Error.Code, Message: withTarget(body.Error.Message, body.Error.Target)})
+	for _, d := range body.Error.Details {
+		// The severity is a number, but some systems send it as a string
+		msgType := "error"
+		if d.Severity != nil {
+			msgType = severity(fmt.Sprint(d.Severity))
+		}
+		e.Messages = append(e.Messages, erp.Message{Type: msgType, Code: d.Code, Message: withTarget(d.Message, d.Target)})
+	}
+	return e
+}
+
+func withTarget(message, target string) string {
+	if target == "" {
+		return message
+	}
+	return target + ": " + message
+}
+
+// severity translates the numeric severities of SAP messages.
+func severity(s string) string {
+	switch strings.ToLower(s) {
+	case "1", "success":
+		return "success"
+	case "2", "info":
+		return "info"
+	case "3", "warning":
+		return "warning"
+	}
+	return "error"
+}
+
+// sapMessage is an entry of the sap-messages response header.
+type sapMessage struct {
+	Code            string `json:"code"`
+	Message         string `json:"message"`
+	Target          string `json:"target"`
+	NumericSeverity int    `json:"numericSeverity"`
+}
+
+// headerMessages returns the messages an SAP system reports for a successful
+// request in the sap-messages header.
+func headerMessages(h http.Header) []erp.Message {
+	raw := h.Get("sap-messages")
+	if raw == "" {
+		return nil
+	}
+	var list []sapMessage
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil
+	}
+	messages := make([]erp.Message, len(list))
+	for i, m := range list {
+		messages[i] = erp.Message{Type: severity(fmt.Sprint(m.NumericSeverity)), Code: m.Code, Message: withTarget(m.Message, m.Target)}
+	}
+	return messages
+}
+
+// client sends OData requests, authenticating with OAuth2 client credentials
+// or basic auth and handling the CSRF tokens SAP systems require for changes.
+type client struct {
+	baseURL string
+	http    *http.Client
+	cfg     Config
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	csrfToken   string
+}
+
+func newClient(cfg Config) (*client, error) {
+	// The CSRF token is bound to the session cookie
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/") + "/",
+		http:    &http.Client{Jar: jar, Timeout: cfg.Timeout},
+		cfg:     cfg,
+	}, nil
+}
+
+// tokenResponse is the response of an OAuth2 token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// accessToken returns a cached OAuth2 access token, requesting a new one with
+// the client credentials grant when it is about to expire.
+func (c *client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if c.cfg.Scope != "" {
+		form.Set("scope", c.cfg.Scope)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("odata: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("odata: token request: HTTP %d", resp.StatusCode)
+	}
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("odata: token request: invalid response")
+	}
+
+	expiresIn := time.Duration(token.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 5 * time.Minute
+	}
+	c.token = token.AccessToken
+	// Renew shortly before the token expires
+	c.tokenExpiry = time.Now().Add(expiresIn - min(expiresIn/10, 30*time.Second))
+	return c.token, nil
+}
+
+func (c *client) authorize(ctx context.Context, req *http.Request) error {
+	switch {
+	case c.cfg.TokenURL != "":
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+	return nil
+}
+
+func (c *client) dropToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// fetchCSRFToken requests a CSRF token from the service root.
+func (c *client) fetchCSRFToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-CSRF-Token", "Fetch")
+	if err := c.authorize(ctx, req); err != nil {
+		return "", err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("odata: fetch CSRF token: %w", err)
+	}
+	resp.Body.Close()
+	token := resp.Header.Get("X-CSRF-Token")
+	if token == "" {
+		return "", fmt.Errorf("odata: fetch CSRF token: HTTP %d without token", resp.StatusCode)
+	}
+
+	c.mu.Lock()
+	c.csrfToken = token
+	c.mu.Unlock()
+	return token, nil
+}
+
+func (c *client) cachedCSRFToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.csrfToken
+}
+
+// do sends a request to path below the service root and decodes the JSON
+// response into out, if not nil. It returns the response headers.
+//
+// Expired access tokens and CSRF tokens are renewed and the request is
+// repeated once.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("odata: encode request: %w", err)
+		}
+	}
+	target := c.baseURL + path
+	if len(query) > 0 {
+		// OData expects %20 rather than + for spaces
+		target += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	modifying := method != http.MethodGet && method != http.MethodHead
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json;IEEE754Compatible=true")
+		req.Header.Set("OData-Version", "4.0")
+		req.Header.Set("OData-MaxVersion", "4.0")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json;IEEE754Compatible=true")
+		}
+		if err := c.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		if modifying && c.cfg.CSRF {
+			token := c.cachedCSRFToken()
+			if token == "" {
+				if token, err = c.fetchCSRFToken(ctx); err != nil {
+					return nil, err
+				}
+			}
+			req.Header.Set("X-CSRF-Token", token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("odata: %s %s: %w", method, path, err)
+		}
+
+		if attempt == 0 && c.retryable(resp) {
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			err := parseError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+		if out != nil && resp.StatusCode != http.StatusNoContent {
+			dec := json.NewDecoder(resp.Body)
+			dec.UseNumber()
+			if err := dec.Decode(out); err != nil {
+				return nil, fmt.Errorf("odata: decode response: %w", err)
+			}
+		}
+		return resp.Header, nil
+	}
+}
+
+// retryable drops stale tokens and reports whether the request should be repeated.
+func (c *client) retryable(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusForbidden && strings.EqualFold(resp.Header.Get("X-CSRF-Token"), "Required"):
+		c.mu.Lock()
+		c.csrfToken = ""
+		c.mu.Unlock()
+		return true
+	case resp.StatusCode == http.StatusUnauthorized && c.cfg.TokenURL != "":
+		c.dropToken()
+		return true
+	}
+	return false
+}
+
+// collection is an OData collection response.
+type collection struct {
+	Value []map[string]any `json:"value"`
+}
+
+// list reads the entities of set matching query.
+func (c *client) list(ctx context.Context, set string, query url.Values) ([]entity, error) {
+	var result collection
+	if _, err := c.do(ctx, http.MethodGet, set, query, nil, &result); err != nil {
+		return nil, err
+	}
+	entities := make([]entity, len(result.Value))
+	for i, v := range result.Value {
+		entities[i] = v
+	}
+	return entities, nil
+}
+
+// get reads a single entity.
+func (c *client) get(ctx context.Context, path string, query url.Values) (entity, error) {
+	var result map[string]any
+	if _, err := c.do(ctx, http.MethodGet, path, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// post creates an entity and returns the created entity and the messages of
+// the response.
+func (c *client) post(ctx context.Context, set string, body any) (entity, []erp.Message, error) {
+	var result map[string]any
+	header, err := c.do(ctx, http.MethodPost, set, nil, body, &result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, headerMessages(header), nil
+}
@@ -0,0 +1,301 @@
+package odata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultEntitySets are the entity sets of SAP S/4HANA Cloud APIs, keyed by
+// the logical name used in the entity_sets config.
+var defaultEntitySets = map[string]string{
+	"orders":       "SalesOrder",
+	"simulations":  "SalesOrderSimulation",
+	"availability": "ProductAvailability",
+	"tier_prices":  "PricingConditionRecord",
+	"customers":    "Customer",
+	"addresses":    "CustomerAddress",
+	"shipments":    "OutboundDelivery",
+	"invoices":     "BillingDocument",
+}
+
+// defaultProperties are the property names of SAP S/4HANA Cloud APIs, keyed by
+// "<entity>.<field>" as used in the properties config. Empty names are not sent.
+var defaultProperties = map[string]string{
+	// Orders and simulations; items and partners are deep-inserted
+	"order.number":         "SalesOrder",
+	"order.external_id":    "", // Not sent unless mapped
+	"order.customer_po":    "PurchaseOrderByCustomer",
+	"order.sales_org":      "SalesOrganization",
+	"order.dist_channel":   "DistributionChannel",
+	"order.division":       "OrganizationDivision",
+	"order.sold_to":        "SoldToParty",
+	"order.currency":       "TransactionCurrency",
+	"order.status":         "OverallSDProcessStatus",
+	"order.date":           "CreationDate",
+	"order.total":          "TotalNetAmount",
+	"order.tax":            "TotalTaxAmount",
+	"order.requested_date": "RequestedDeliveryDate",
+	"order.notes":          "", // Not sent unless mapped
+	"order.items":          "_Item",
+	"order.partners":       "_Partner",
+
+	"item.number":          "SalesOrderItem",
+	"item.sku":             "Material",
+	"item.quantity":        "RequestedQuantity",
+	"item.unit":            "RequestedQuantityUnit",
+	"item.plant":           "ProductionPlant",
+	"item.requested_price": "NetPriceAmount",
+	"item.confirmed_qty":   "ConfdDelivQtyInOrderQtyUnit",
+	"item.net_amount":      "NetAmount",
+	"item.net_price":       "NetPriceAmount",
+	"item.status":          "SDProcessStatus",
+	"item.shipped_qty":     "DeliveredQuantity",
+	"item.available":       "IsAvailable",
+	"item.lead_time_days":  "LeadTimeInDays",
+	"item.delivery_date":   "ConfirmedDeliveryDate",
+
+	"partner.function": "PartnerFunction",
+	"partner.customer": "Customer",
+
+	"availability.sku":            "Material",
+	"availability.plant":          "Plant",
+	"availability.plant_name":     "PlantName",
+	"availability.quantity":       "AvailableQuantity",
+	"availability.unit":           "BaseUnit",
+	"availability.lead_time_days": "LeadTimeInDays",
+
+	"tier_price.customer":   "Customer",
+	"tier_price.sku":        "Material",
+	"tier_price.min_qty":    "ConditionScaleQuantity",
+	"tier_price.price":      "ConditionRateValue",
+	"tier_price.currency":   "ConditionCurrency",
+	"tier_price.valid_from": "ConditionValidityStartDate",
+	"tier_price.valid_to":   "ConditionValidityEndDate",
+
+	"customer.id":            "Customer",
+	"customer.name":          "CustomerName",
+	"customer.tax_id":        "TaxNumber1",
+	"customer.payment_terms": "PaymentTerms",
+	"customer.currency":      "Currency",
+	"customer.credit_limit":  "CreditLimitAmount",
+
+	"address.customer":    "Customer",
+	"address.id":          "AddressID",
+	"address.name":        "FullName",
+	"address.street":      "StreetName",
+	"address.postal_code": "PostalCode",
+	"address.city":        "CityName",
+	"address.country":     "Country",
+	"address.region":      "Region",
+
+	"shipment.id":       "DeliveryDocument",
+	"shipment.order":    "ReferenceSDDocument",
+	"shipment.sold_to":  "SoldToParty",
+	"shipment.date":     "ActualGoodsMovementDate",
+	"shipment.tracking": "TrackingNumber",
+	"shipment.carrier":  "ShippingCarrier",
+
+	"invoice.number":   "BillingDocument",
+	"invoice.order":    "SalesDocument",
+	"invoice.sold_to":  "SoldToParty",
+	"invoice.date":     "BillingDocumentDate",
+	"invoice.due_date": "NetDueDate",
+	"invoice.amount":   "TotalNetAmount",
+	"invoice.currency": "TransactionCurrency",
+	"invoice.status":   "OverallBillingStatus",
+}
+
+// defaultPartnerFunctions are the partner function codes of the Customer
+// partner roles.
+var defaultPartnerFunctions = map[string]string{
+	"sold_to": "SP",
+	"ship_to": "SH",
+	"bill_to": "BP",
+	"payer":   "PY",
+}
+
+// defaultStatuses translate the overall processing status of SAP orders.
+// Codes without a translation are returned unchanged.
+var defaultStatuses = map[string]string{
+	"A": "confirmed",
+	"B": "processing",
+	"C": "delivered",
+}
+
+// mapping resolves logical names to the names of the ERP system.
+type mapping struct {
+	entitySets       map[string]string
+	properties       map[string]string
+	partnerFunctions map[string]string
+	statuses         map[string]string
+}
+
+// newMapping merges overrides into the defaults. Unknown keys are rejected so
+// that typos do not silently fall back to the defaults.
+func newMapping(entitySets, properties, partnerFunctions, statuses map[string]string) (*mapping, error) {
+	m := &mapping{statuses: merge(defaultStatuses, statuses)}
+	var errs []string
+	var err error
+	if m.entitySets, err = override("entity_sets", defaultEntitySets, entitySets); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if m.properties, err = override("properties", defaultProperties, properties); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if m.partnerFunctions, err = override("partner_functions", defaultPartnerFunctions, partnerFunctions); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return m, nil
+}
+
+func merge(defaults, overrides map[string]string) map[string]string {
+	result := make(map[string]string, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		result[k] = v
+	}
+	for k, v := range overrides {
+		result[k] = v
+	}
+	return result
+}
+
+func override(name string, defaults, overrides map[string]string) (map[string]string, error) {
+	var unknown []string
+	for k := range overrides {
+		if _, ok := defaults[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown %s keys: %s", name, strings.Join(unknown, ", "))
+	}
+	return merge(defaults, overrides), nil
+}
+
+func (m *mapping) set(name string) string  { return m.entitySets[name] }
+func (m *mapping) prop(name string) string { return m.properties[name] }
+
+func (m *mapping) status(code string) string {
+	if status, ok := m.statuses[code]; ok {
+		return status
+	}
+	return code
+}
+
+// --- Literals ---
+
+// quote returns an OData string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// keyPath returns the path of the entity with a single string key, e.g. Customer('42').
+func keyPath(set, key string) string {
+	return set + "(" + quote(key) + ")"
+}
+
+// anyOf returns a filter matching any of the values, e.g. (Material eq 'a' or Material eq 'b').
+func anyOf(prop string, values []string) string {
+	terms := make([]string, len(values))
+	for i, v := range values {
+		terms[i] = prop + " eq " + quote(v)
+	}
+	return "(" + strings.Join(terms, " or ") + ")"
+}
+
+// dateFilter returns the filter terms of an inclusive date range on prop.
+// With Edm.Date properties the bounds are rounded inwards to whole days, so
+// that the filter is exact for values at midnight.
+func dateFilter(prop string, from, to time.Time, dateTime bool) []string {
+	var terms []string
+	if !from.IsZero() {
+		if dateTime {
+			terms = append(terms, prop+" ge "+from.UTC().Format(time.RFC3339))
+		} else {
+			day := from.UTC().Truncate(24 * time.Hour)
+			if day.Before(from) {
+				day = day.AddDate(0, 0, 1)
+			}
+			terms = append(terms, prop+" ge "+day.Format(time.DateOnly))
+		}
+	}
+	if !to.IsZero() {
+		if dateTime {
+			terms = append(terms, prop+" le "+to.UTC().Format(time.RFC3339))
+		} else {
+			terms = append(terms, prop+" le "+to.UTC().Format(time.DateOnly))
+		}
+	}
+	return terms
+}
+
+// --- Entities ---
+
+// entity is a decoded OData entity.
+type entity map[string]any
+
+func (e entity) string(prop string) string {
+	switch v := e[prop].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// float reads numbers and decimals, which OData serializes as strings with
+// IEEE754Compatible=true.
+func (e entity) float(prop string) float64 {
+	switch v := e[prop].(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func (e entity) int(prop string) int {
+	return int(e.float(prop))
+}
+
+func (e entity) bool(prop string) (value, ok bool) {
+	v, ok := e[prop].(bool)
+	return v, ok
+}
+
+// time reads Edm.Date and Edm.DateTimeOffset values.
+func (e entity) time(prop string) time.Time {
+	s := e.string(prop)
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (e entity) entities(prop string) []entity {
+	list, _ := e[prop].([]any)
+	result := make([]entity, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			result = append(result, entity(m))
+		}
+	}
+	return result
+}
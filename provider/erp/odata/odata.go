@@ -0,0 +1,638 @@
+// Package odata provides an ERP provider for systems with an OData v4 API, such
+// as SAP S/4HANA and Microsoft Dynamics 365.
+//
+// Orders and simulations are created with deep inserts into configurable entity
+// sets; the other methods read entity sets with $filter, $orderby, $top and
+// $skip. The defaults follow the SAP S/4HANA Cloud APIs. Other systems are
+// mapped through the entity_sets, properties and partner_functions configs,
+// which override single entries of the defaults:
+//
+//	erp:
+//	  name: odata
+//	  config:
+//	    base_url: https://erp.example.com/odata/sales/
+//	    token_url: https://login.example.com/oauth2/token
+//	    client_id: gondolia
+//	    client_secret: file:/run/secrets/erp-client-secret
+//	    entity_sets: {orders: SalesOrderHeaders}
+//	    properties: {order.number: SalesOrderNumber, order.sold_to: OrderingCustomerAccountNumber}
+//
+// TenantConfig SalesOrg, DistChannel and Division are sent as order properties,
+// and the Customer partner roles as order partners with their partner function.
+// Error responses are returned as *Error, which carries the OData error and its
+// details as erp.Message entries.
+package odata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+func init() {
+	provider.RegisterTyped[erp.ERPProvider, Config]("erp", "odata",
+		provider.Metadata{
+			Name:        "odata",
+			DisplayName: "OData v4 ERP (SAP S/4HANA, Dynamics 365)",
+			Category:    "erp",
+			Version:     "1.0.0",
+			Description: "ERP integration over OData v4 with configurable entity sets",
+			ConfigSpec:  configSpec,
+		},
+		New,
+	)
+}
+
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{Key: "base_url", Type: "string", Required: true, Description: "Service root URL"},
+	{Key: "token_url", Type: "string", Description: "OAuth2 token endpoint for the client credentials grant"},
+	{Key: "client_id", Type: "string", Description: "OAuth2 client ID"},
+	{Key: "client_secret", Type: "secret", Description: "OAuth2 client secret"},
+	{Key: "scope", Type: "string", Description: "OAuth2 scope"},
+	{Key: "username", Type: "string", Description: "User for basic auth, if no token_url is set"},
+	{Key: "password", Type: "secret", Description: "Password for basic auth"},
+	{Key: "csrf", Type: "bool", Default: true, Description: "Fetch an X-CSRF-Token before changing requests (SAP)"},
+	{Key: "timeout", Type: "duration", Default: "30s", Description: "HTTP request timeout"},
+	{Key: "datetime_filters", Type: "bool", Default: false, Description: "Filter report dates as Edm.DateTimeOffset instead of Edm.Date"},
+	{Key: "entity_sets", Type: "map", Description: "Entity set names by logical name (orders, simulations, availability, tier_prices, customers, addresses, shipments, invoices)"},
+	{Key: "properties", Type: "map", Description: "Property names by <entity>.<field>, e.g. order.number"},
+	{Key: "partner_functions", Type: "map", Description: "Partner function codes of sold_to, ship_to, bill_to and payer"},
+	{Key: "statuses", Type: "map", Description: "Order statuses by ERP status code"},
+}
+
+// Config holds the OData provider configuration.
+type Config struct {
+	BaseURL         string            `config:"base_url"`
+	TokenURL        string            `config:"token_url"`
+	ClientID        string            `config:"client_id"`
+	ClientSecret    string            `config:"client_secret"`
+	Scope           string            `config:"scope"`
+	Username        string            `config:"username"`
+	Password        string            `config:"password"`
+	CSRF            bool              `config:"csrf"`
+	Timeout         time.Duration     `config:"timeout"`
+	DateTimeFilters bool              `config:"datetime_filters"`
+	EntitySets      map[string]string `config:"entity_sets"`
+	Properties      map[string]string `config:"properties"`
+	PartnerFuncs    map[string]string `config:"partner_functions"`
+	Statuses        map[string]string `config:"statuses"`
+}
+
+// defaultReportLimit is the page size of reports without a limit.
+const defaultReportLimit = 100
+
+// Provider is an OData v4 ERP provider.
+type Provider struct {
+	client *client
+	m      *mapping
+	cfg    Config
+}
+
+// NewProvider creates a new OData ERP provider from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
+func NewProvider(config map[string]any) (erp.ERPProvider, error) {
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("odata: %w", err)
+	}
+	var cfg Config
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("odata: %w", err)
+	}
+	return New(cfg)
+}
+
+// New creates a new OData ERP provider from a typed configuration.
+func New(cfg Config) (erp.ERPProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("odata: base_url is required")
+	}
+	if _, err := url.ParseRequestURI(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("odata: invalid base_url: %w", err)
+	}
+	if cfg.TokenURL != "" && (cfg.ClientID == "" || cfg.ClientSecret == "") {
+		return nil, fmt.Errorf("odata: token_url requires client_id and client_secret")
+	}
+	m, err := newMapping(cfg.EntitySets, cfg.Properties, cfg.PartnerFuncs, cfg.Statuses)
+	if err != nil {
+		return nil, fmt.Errorf("odata: %w", err)
+	}
+	c, err := newClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("odata: %w", err)
+	}
+	return &Provider{client: c, m: m, cfg: cfg}, nil
+}
+
+// Start checks that the service is reachable and the credentials are accepted
+// by reading the service document.
+func (p *Provider) Start(ctx context.Context) error {
+	_, err := p.client.do(ctx, http.MethodGet, "", nil, nil, nil)
+	return err
+}
+
+// decimal formats a number as Edm.Decimal, which is a string with IEEE754Compatible=true.
+func decimal(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// setIf sets prop to value unless the value is empty or the property is not mapped.
+func setIf(e map[string]any, prop, value string) {
+	if prop != "" && value != "" {
+		e[prop] = value
+	}
+}
+
+// orderItem is an item of an order or simulation request.
+type orderItem struct {
+	sku      string
+	quantity float64
+	unit     string
+	plant    string
+	price    *float64
+}
+
+func validateItems(items []orderItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("odata: order has no items: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range items {
+		if item.sku == "" {
+			return fmt.Errorf("odata: item without SKU: %w", provider.ErrInvalidArgument)
+		}
+		if item.quantity <= 0 {
+			return fmt.Errorf("odata: item %s has quantity %v: %w", item.sku, item.quantity, provider.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// orderPayload builds the deep insert body of an order or simulation.
+func (p *Provider) orderPayload(cfg erp.TenantConfig, customer erp.Customer, shipTo, billTo erp.Address, items []orderItem) map[string]any {
+	m := p.m
+	body := make(map[string]any)
+	setIf(body, m.prop("order.sales_org"), cfg.SalesOrg)
+	setIf(body, m.prop("order.dist_channel"), cfg.DistChannel)
+	setIf(body, m.prop("order.division"), cfg.Division)
+	setIf(body, m.prop("order.currency"), cfg.Currency)
+
+	soldTo := firstNonEmpty(customer.SoldToParty, customer.ERPCustomerID)
+	setIf(body, m.prop("order.sold_to"), soldTo)
+
+	var partners []map[string]any
+	for _, role := range []struct{ name, party string }{
+		{"sold_to", soldTo},
+		{"ship_to", firstNonEmpty(customer.ShipToParty, shipTo.ID)},
+		{"bill_to", firstNonEmpty(customer.BillToParty, billTo.ID)},
+		{"payer", customer.PayerParty},
+	} {
+		if role.party == "" {
+			continue
+		}
+		partners = append(partners, map[string]any{
+			m.prop("partner.function"): m.partnerFunctions[role.name],
+			m.prop("partner.customer"): role.party,
+		})
+	}
+	if len(partners) > 0 {
+		body[m.prop("order.partners")] = partners
+	}
+
+	lines := make([]map[string]any, len(items))
+	for i, item := range items {
+		line := map[string]any{
+			m.prop("item.number"):   strconv.Itoa((i + 1) * 10),
+			m.prop("item.sku"):      item.sku,
+			m.prop("item.quantity"): decimal(item.quantity),
+		}
+		setIf(line, m.prop("item.unit"), item.unit)
+		setIf(line, m.prop("item.plant"), item.plant)
+		if item.price != nil {
+			line[m.prop("item.requested_price")] = decimal(*item.price)
+		}
+		lines[i] = line
+	}
+	body[m.prop("order.items")] = lines
+	return body
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// unitPrice returns the net price of an item, derived from the net amount if
+// the system does not report it.
+func (p *Provider) unitPrice(item entity, quantity float64) float64 {
+	if price := item.float(p.m.prop("item.net_price")); price != 0 {
+		return price
+	}
+	if quantity != 0 {
+		return item.float(p.m.prop("item.net_amount")) / quantity
+	}
+	return 0
+}
+
+func (p *Provider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items := make([]orderItem, len(req.Order.Items))
+	for i, item := range req.Order.Items {
+		items[i] = orderItem{sku: item.SKU, quantity: item.Quantity, unit: item.Unit, plant: item.Plant, price: item.RequestedPrice}
+	}
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+
+	m := p.m
+	body := p.orderPayload(req.TenantConfig, req.Customer, req.ShipTo, req.BillTo, items)
+	setIf(body, m.prop("order.customer_po"), req.Order.CustomerPO)
+	setIf(body, m.prop("order.external_id"), req.Order.ExternalID)
+	setIf(body, m.prop("order.notes"), req.Order.Notes)
+
+	created, messages, err := p.client.post(ctx, m.set("orders"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &erp.CreateOrderResult{
+		ERPOrderNumber: created.string(m.prop("order.number")),
+		Messages:       messages,
+	}
+	for _, item := range created.entities(m.prop("order.items")) {
+		qty := item.float(m.prop("item.confirmed_qty"))
+		if qty == 0 {
+			qty = item.float(m.prop("item.quantity"))
+		}
+		result.Items = append(result.Items, erp.OrderItemResult{
+			SKU:            item.string(m.prop("item.sku")),
+			ItemNumber:     item.string(m.prop("item.number")),
+			ConfirmedQty:   qty,
+			ConfirmedPrice: p.unitPrice(item, item.float(m.prop("item.quantity"))),
+		})
+	}
+	if len(result.Messages) == 0 {
+		result.Messages = []erp.Message{{Type: "success", Message: fmt.Sprintf("order %s created", result.ERPOrderNumber)}}
+	}
+	return result, nil
+}
+
+func (p *Provider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items := make([]orderItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = orderItem{sku: item.SKU, quantity: item.Quantity, unit: item.Unit}
+	}
+	if err := validateItems(items); err != nil {
+		return nil, err
+	}
+
+	m := p.m
+	body := p.orderPayload(req.TenantConfig, req.Customer, req.ShipTo, erp.Address{}, items)
+	if req.DesiredDate != nil {
+		body[m.prop("order.requested_date")] = req.DesiredDate.UTC().Format(time.DateOnly)
+	}
+
+	simulated, messages, err := p.client.post(ctx, m.set("simulations"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal := simulated.float(m.prop("order.total"))
+	tax := simulated.float(m.prop("order.tax"))
+	currency := firstNonEmpty(simulated.string(m.prop("order.currency")), req.TenantConfig.Currency)
+	result := &erp.SimulateOrderResult{Messages: messages}
+
+	schedule := make(map[time.Time]*erp.DeliverySchedule)
+	var itemsTotal float64
+	for _, item := range simulated.entities(m.prop("order.items")) {
+		qty := item.float(m.prop("item.quantity"))
+		confirmed := item.float(m.prop("item.confirmed_qty"))
+		available, ok := item.bool(m.prop("item.available"))
+		if !ok {
+			available = confirmed >= qty
+		}
+		total := item.float(m.prop("item.net_amount"))
+		sku := item.string(m.prop("item.sku"))
+		result.Items = append(result.Items, erp.SimulatedItem{
+			SKU:          sku,
+			Quantity:     qty,
+			Unit:         item.string(m.prop("item.unit")),
+			UnitPrice:    p.unitPrice(item, qty),
+			TotalPrice:   total,
+			Available:    available,
+			LeadTimeDays: item.int(m.prop("item.lead_time_days")),
+		})
+		itemsTotal += total
+
+		if date := item.time(m.prop("item.delivery_date")); !date.IsZero() {
+			delivery, ok := schedule[date]
+			if !ok {
+				delivery = &erp.DeliverySchedule{Date: date}
+				schedule[date] = delivery
+			}
+			delivery.Quantity += qty
+			delivery.Items = append(delivery.Items, sku)
+		}
+	}
+	for _, delivery := range schedule {
+		result.Schedule = append(result.Schedule, *delivery)
+	}
+	sort.Slice(result.Schedule, func(i, j int) bool { return result.Schedule[i].Date.Before(result.Schedule[j].Date) })
+
+	if subtotal == 0 {
+		subtotal = itemsTotal
+	}
+	result.Totals = erp.Totals{Subtotal: subtotal, Tax: tax, Total: subtotal + tax, Currency: currency}
+	return result, nil
+}
+
+func (p *Provider) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if orderID == "" {
+		return nil, fmt.Errorf("odata: order ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	m := p.m
+	order, err := p.client.get(ctx, keyPath(m.set("orders"), orderID), url.Values{"$expand": {m.prop("order.items")}})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &erp.OrderStatus{
+		ERPOrderNumber: order.string(m.prop("order.number")),
+		Status:         m.status(order.string(m.prop("order.status"))),
+	}
+	for _, item := range order.entities(m.prop("order.items")) {
+		status.Items = append(status.Items, erp.OrderItemStatus{
+			ItemNumber: item.string(m.prop("item.number")),
+			SKU:        item.string(m.prop("item.sku")),
+			Status:     m.status(item.string(m.prop("item.status"))),
+			ShippedQty: item.float(m.prop("item.shipped_qty")),
+		})
+	}
+	return status, nil
+}
+
+func (p *Provider) GetProductAvailability(ctx context.Context, skus []string) ([]erp.ProductStock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(skus) == 0 {
+		return []erp.ProductStock{}, nil
+	}
+
+	m := p.m
+	rows, err := p.client.list(ctx, m.set("availability"), url.Values{"$filter": {anyOf(m.prop("availability.sku"), skus)}})
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		requested[sku] = true
+	}
+	stocks := make([]erp.ProductStock, 0, len(rows))
+	for _, row := range rows {
+		sku := row.string(m.prop("availability.sku"))
+		if !requested[sku] {
+			continue
+		}
+		stocks = append(stocks, erp.ProductStock{
+			SKU:          sku,
+			PlantCode:    row.string(m.prop("availability.plant")),
+			PlantName:    row.string(m.prop("availability.plant_name")),
+			Quantity:     row.float(m.prop("availability.quantity")),
+			Unit:         row.string(m.prop("availability.unit")),
+			LeadTimeDays: row.int(m.prop("availability.lead_time_days")),
+		})
+	}
+	return stocks, nil
+}
+
+func (p *Provider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m := p.m
+	var filter []string
+	if req.CustomerID != "" {
+		filter = append(filter, m.prop("tier_price.customer")+" eq "+quote(req.CustomerID))
+	}
+	if len(req.SKUs) > 0 {
+		filter = append(filter, anyOf(m.prop("tier_price.sku"), req.SKUs))
+	}
+	if req.Currency != "" {
+		filter = append(filter, m.prop("tier_price.currency")+" eq "+quote(req.Currency))
+	}
+	query := url.Values{"$orderby": {m.prop("tier_price.sku") + "," + m.prop("tier_price.min_qty")}}
+	if len(filter) > 0 {
+		query.Set("$filter", strings.Join(filter, " and "))
+	}
+
+	rows, err := p.client.list(ctx, m.set("tier_prices"), query)
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]erp.TierPrice, len(rows))
+	for i, row := range rows {
+		prices[i] = erp.TierPrice{
+			SKU:       row.string(m.prop("tier_price.sku")),
+			MinQty:    row.float(m.prop("tier_price.min_qty")),
+			Price:     row.float(m.prop("tier_price.price")),
+			Currency:  row.string(m.prop("tier_price.currency")),
+			ValidFrom: row.time(m.prop("tier_price.valid_from")),
+			ValidTo:   row.time(m.prop("tier_price.valid_to")),
+		}
+	}
+	return prices, nil
+}
+
+func (p *Provider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("odata: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	m := p.m
+	customer, err := p.client.get(ctx, keyPath(m.set("customers"), erpCustomerID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make(map[string]bool)
+	for _, field := range []string{"id", "name", "tax_id", "payment_terms", "currency", "credit_limit"} {
+		mapped[m.prop("customer."+field)] = true
+	}
+	// Unmapped string properties are kept as attributes
+	attributes := make(map[string]string)
+	for prop, value := range customer {
+		if s, ok := value.(string); ok && !mapped[prop] && !strings.HasPrefix(prop, "@") && s != "" {
+			attributes[prop] = s
+		}
+	}
+
+	return &erp.CompanyData{
+		ERPCustomerID: firstNonEmpty(customer.string(m.prop("customer.id")), erpCustomerID),
+		Name:          customer.string(m.prop("customer.name")),
+		TaxID:         customer.string(m.prop("customer.tax_id")),
+		PaymentTerms:  customer.string(m.prop("customer.payment_terms")),
+		Currency:      customer.string(m.prop("customer.currency")),
+		CreditLimit:   customer.float(m.prop("customer.credit_limit")),
+		Attributes:    attributes,
+	}, nil
+}
+
+func (p *Provider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if erpCustomerID == "" {
+		return nil, fmt.Errorf("odata: customer ID is required: %w", provider.ErrInvalidArgument)
+	}
+
+	m := p.m
+	rows, err := p.client.list(ctx, m.set("addresses"), url.Values{
+		"$filter": {m.prop("address.customer") + " eq " + quote(erpCustomerID)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]erp.Address, len(rows))
+	for i, row := range rows {
+		addresses[i] = erp.Address{
+			ID:         row.string(m.prop("address.id")),
+			Name:       row.string(m.prop("address.name")),
+			Street:     row.string(m.prop("address.street")),
+			PostalCode: row.string(m.prop("address.postal_code")),
+			City:       row.string(m.prop("address.city")),
+			Country:    row.string(m.prop("address.country")),
+			Region:     row.string(m.prop("address.region")),
+		}
+	}
+	return addresses, nil
+}
+
+// report reads a page of the entity set of a report, newest first.
+func (p *Provider) report(ctx context.Context, set, kind, key string, f erp.ReportFilter) ([]entity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("odata: %w", err)
+	}
+
+	m := p.m
+	dateProp := m.prop(kind + ".date")
+	var filter []string
+	if f.CustomerID != "" {
+		filter = append(filter, m.prop(kind+".sold_to")+" eq "+quote(f.CustomerID))
+	}
+	filter = append(filter, dateFilter(dateProp, f.DateFrom, f.DateTo, p.cfg.DateTimeFilters)...)
+
+	limit := f.Limit
+	if limit == 0 {
+		limit = defaultReportLimit
+	}
+	query := url.Values{
+		"$orderby": {dateProp + " desc," + m.prop(kind+"."+key) + " desc"},
+		"$top":     {strconv.Itoa(limit)},
+	}
+	if f.Offset > 0 {
+		query.Set("$skip", strconv.Itoa(f.Offset))
+	}
+	if len(filter) > 0 {
+		query.Set("$filter", strings.Join(filter, " and "))
+	}
+	return p.client.list(ctx, m.set(set), query)
+}
+
+func (p *Provider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	m := p.m
+	rows, err := p.report(ctx, "orders", "order", "number", req)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]erp.OrderReport, len(rows))
+	for i, row := range rows {
+		orders[i] = erp.OrderReport{
+			ERPOrderNumber: row.string(m.prop("order.number")),
+			OrderDate:      row.time(m.prop("order.date")),
+			CustomerPO:     row.string(m.prop("order.customer_po")),
+			TotalAmount:    row.float(m.prop("order.total")),
+			Currency:       row.string(m.prop("order.currency")),
+			Status:         m.status(row.string(m.prop("order.status"))),
+		}
+	}
+	return orders, nil
+}
+
+func (p *Provider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) ([]erp.ShipmentReport, error) {
+	m := p.m
+	rows, err := p.report(ctx, "shipments", "shipment", "id", req)
+	if err != nil {
+		return nil, err
+	}
+	shipments := make([]erp.ShipmentReport, len(rows))
+	for i, row := range rows {
+		shipments[i] = erp.ShipmentReport{
+			ShipmentID:     row.string(m.prop("shipment.id")),
+			OrderNumber:    row.string(m.prop("shipment.order")),
+			ShipDate:       row.time(m.prop("shipment.date")),
+			TrackingNumber: row.string(m.prop("shipment.tracking")),
+			Carrier:        row.string(m.prop("shipment.carrier")),
+		}
+	}
+	return shipments, nil
+}
+
+func (p *Provider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	m := p.m
+	rows, err := p.report(ctx, "invoices", "invoice", "number", req)
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]erp.InvoiceReport, len(rows))
+	for i, row := range rows {
+		invoices[i] = erp.InvoiceReport{
+			InvoiceNumber: row.string(m.prop("invoice.number")),
+			OrderNumber:   row.string(m.prop("invoice.order")),
+			InvoiceDate:   row.time(m.prop("invoice.date")),
+			DueDate:       row.time(m.prop("invoice.due_date")),
+			Amount:        row.float(m.prop("invoice.amount")),
+			Currency:      row.string(m.prop("invoice.currency")),
+			Status:        row.string(m.prop("invoice.status")),
+		}
+	}
+	return invoices, nil
+}
+
+func (p *Provider) Metadata() erp.Metadata {
+	return erp.Metadata{
+		Name:         "odata",
+		Version:      "1.0.0",
+		Protocol:     "odata",
+		Capabilities: []string{"orders", "simulation", "availability", "pricing", "companies", "reports"},
+	}
+}
@@ -0,0 +1,403 @@
+package odata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/erp/erptest"
+)
+
+// standIn is an OData v4 service with the default SAP entity sets, an OAuth2
+// token endpoint and CSRF protection.
+type standIn struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	sets        map[string][]map[string]any
+	csrfToken   string
+	tokens      int
+	csrfFetches int
+	lastOrder   map[string]any
+}
+
+func newStandIn(t *testing.T) *standIn {
+	s := &standIn{csrfToken: "csrf-1", sets: map[string][]map[string]any{
+		"Customer": {
+			{"Customer": "1000042", "CustomerName": "Muster AG", "TaxNumber1": "CHE-123.456.789", "PaymentTerms": "NT30", "Currency": "CHF", "CreditLimitAmount": "50000", "Industry": "Construction"},
+		},
+		"CustomerAddress": {
+			{"Customer": "1000042", "AddressID": "1", "StreetName": "Bahnhofstrasse 1", "PostalCode": "8001", "CityName": "Zürich", "Country": "CH"},
+		},
+		"ProductAvailability": {
+			{"Material": "4711", "Plant": "1000", "AvailableQuantity": "120", "BaseUnit": "PC"},
+			{"Material": "4712", "Plant": "1000", "AvailableQuantity": "0", "BaseUnit": "PC", "LeadTimeInDays": 5},
+		},
+		"PricingConditionRecord": {
+			{"Customer": "1000042", "Material": "4711", "ConditionScaleQuantity": "100", "ConditionRateValue": "9.8", "ConditionCurrency": "CHF", "ConditionValidityStartDate": "2024-01-01"},
+			{"Customer": "1000042", "Material": "4711", "ConditionScaleQuantity": "1", "ConditionRateValue": "12.5", "ConditionCurrency": "CHF", "ConditionValidityStartDate": "2024-01-01"},
+		},
+	}}
+	for i, date := range []string{"2024-01-10", "2024-02-10", "2024-03-10"} {
+		n := strconv.Itoa(i + 1)
+		s.sets["SalesOrder"] = append(s.sets["SalesOrder"], map[string]any{
+			"SalesOrder": "100" + n, "SoldToParty": "1000042", "CreationDate": date, "TotalNetAmount": "100", "TransactionCurrency": "CHF", "OverallSDProcessStatus": "C",
+			"_Item": []any{map[string]any{"SalesOrderItem": "10", "Material": "4711", "SDProcessStatus": "C", "DeliveredQuantity": "8"}},
+		})
+		s.sets["OutboundDelivery"] = append(s.sets["OutboundDelivery"], map[string]any{
+			"DeliveryDocument": "800" + n, "ReferenceSDDocument": "100" + n, "SoldToParty": "1000042", "ActualGoodsMovementDate": date,
+		})
+		s.sets["BillingDocument"] = append(s.sets["BillingDocument"], map[string]any{
+			"BillingDocument": "900" + n, "SalesDocument": "100" + n, "SoldToParty": "1000042", "BillingDocumentDate": date, "TotalNetAmount": "100",
+		})
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *standIn) config() map[string]any {
+	return map[string]any{
+		"base_url":      s.URL + "/odata/",
+		"token_url":     s.URL + "/token",
+		"client_id":     "gondolia",
+		"client_secret": "secret",
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string, details ...map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": code, "message": message, "details": details}})
+}
+
+var keyPattern = regexp.MustCompile(`^(\w+)\('([^']*)'\)$`)
+
+func (s *standIn) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "gondolia" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.tokens++
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token-" + strconv.Itoa(s.tokens), "expires_in": 3600})
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/odata/")
+	switch r.Method {
+	case http.MethodHead:
+		if r.Header.Get("X-CSRF-Token") == "Fetch" {
+			s.csrfFetches++
+			w.Header().Set("X-CSRF-Token", s.csrfToken)
+		}
+		return
+	case http.MethodPost:
+		if r.Header.Get("X-CSRF-Token") != s.csrfToken {
+			w.Header().Set("X-CSRF-Token", "Required")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		s.post(w, path, body)
+		return
+	}
+
+	if path == "" {
+		json.NewEncoder(w).Encode(map[string]any{"value": []any{}})
+		return
+	}
+	if m := keyPattern.FindStringSubmatch(path); m != nil {
+		for _, e := range s.sets[m[1]] {
+			if e[m[1]] == m[2] {
+				json.NewEncoder(w).Encode(e)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s %s does not exist", m[1], m[2]))
+		return
+	}
+	rows, ok := s.sets[path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown entity set "+path)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"value": query(rows, r.URL.Query())})
+}
+
+func (s *standIn) post(w http.ResponseWriter, set string, body map[string]any) {
+	if body["SoldToParty"] == "BLOCKED" {
+		writeError(w, http.StatusBadRequest, "V1/112", "Customer BLOCKED is blocked for sales",
+			map[string]any{"code": "V1/555", "message": "Check the credit limit", "target": "SoldToParty", "@Common.numericSeverity": 3})
+		return
+	}
+
+	created := map[string]any{"TotalNetAmount": "0", "TransactionCurrency": "CHF"}
+	var total float64
+	var items []any
+	for _, raw := range body["_Item"].([]any) {
+		item := raw.(map[string]any)
+		qty, _ := strconv.ParseFloat(item["RequestedQuantity"].(string), 64)
+		items = append(items, map[string]any{
+			"SalesOrderItem":              item["SalesOrderItem"],
+			"Material":                    item["Material"],
+			"RequestedQuantity":           item["RequestedQuantity"],
+			"RequestedQuantityUnit":       "PC",
+			"ConfdDelivQtyInOrderQtyUnit": item["RequestedQuantity"],
+			"NetAmount":                   strconv.FormatFloat(qty*12.5, 'f', -1, 64),
+			"ConfirmedDeliveryDate":       "2024-05-02",
+		})
+		total += qty * 12.5
+	}
+	created["_Item"] = items
+	created["TotalNetAmount"] = strconv.FormatFloat(total, 'f', -1, 64)
+
+	if set == "SalesOrder" {
+		s.lastOrder = body
+		created["SalesOrder"] = strconv.Itoa(5000 + len(s.sets["SalesOrder"]))
+		w.Header().Set("sap-messages", `[{"code":"V1/311","message":"Standard Order has been saved","numericSeverity":1}]`)
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+var termPattern = regexp.MustCompile(`^(\w+) (eq|ge|le) (?:'([^']*)'|(\S+))$`)
+
+// query applies the $filter, $orderby, $skip and $top subset the provider uses.
+func query(rows []map[string]any, q map[string][]string) []map[string]any {
+	var result []map[string]any
+	for _, row := range rows {
+		if matches(row, first(q["$filter"])) {
+			result = append(result, row)
+		}
+	}
+	if orderBy := first(q["$orderby"]); orderBy != "" {
+		keys := strings.Split(orderBy, ",")
+		sort.SliceStable(result, func(i, j int) bool {
+			for _, key := range keys {
+				prop, desc, _ := strings.Cut(key, " ")
+				a, b := fmt.Sprint(result[i][prop]), fmt.Sprint(result[j][prop])
+				if a != b {
+					return (a < b) != (desc == "desc")
+				}
+			}
+			return false
+		})
+	}
+	skip, _ := strconv.Atoi(first(q["$skip"]))
+	result = result[min(skip, len(result)):]
+	if top, err := strconv.Atoi(first(q["$top"])); err == nil && top < len(result) {
+		result = result[:top]
+	}
+	return result
+}
+
+func matches(row map[string]any, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	for _, clause := range strings.Split(filter, " and ") {
+		ok := false
+		for _, term := range strings.Split(strings.Trim(clause, "()"), " or ") {
+			m := termPattern.FindStringSubmatch(term)
+			value := m[3] + m[4]
+			actual := fmt.Sprint(row[m[1]])
+			switch m[2] {
+			case "eq":
+				ok = ok || actual == value
+			case "ge":
+				ok = ok || actual >= value
+			case "le":
+				ok = ok || actual <= value
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func newTestProvider(t *testing.T, s *standIn) *Provider {
+	t.Helper()
+	p, err := NewProvider(s.config())
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return p.(*Provider)
+}
+
+func TestConformance(t *testing.T) {
+	s := newStandIn(t)
+	erptest.Run(t, func(t *testing.T) erp.ERPProvider {
+		return newTestProvider(t, s)
+	}, erptest.Options{CustomerID: "1000042", SKUs: []string{"4711", "4712"}})
+}
+
+func TestCreateOrder_MapsTenantConfigAndPartners(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	result, err := p.CreateOrder(ctx, erp.CreateOrderRequest{
+		TenantConfig: erp.TenantConfig{SalesOrg: "1010", DistChannel: "10", Division: "00", Currency: "CHF"},
+		Order:        erp.Order{CustomerPO: "PO-77", Items: []erp.OrderItem{{SKU: "4711", Quantity: 4, Unit: "PC", Plant: "1000"}}},
+		Customer:     erp.Customer{ERPCustomerID: "1000042", ShipToParty: "1000043", BillToParty: "1000044", PayerParty: "1000045"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	if result.ERPOrderNumber == "" || len(result.Items) != 1 || result.Items[0].ConfirmedPrice != 12.5 || result.Items[0].ConfirmedQty != 4 {
+		t.Errorf("result = %+v, want confirmed item at 12.5", result)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Type != "success" || result.Messages[0].Code != "V1/311" {
+		t.Errorf("Messages = %+v, want the sap-messages entry", result.Messages)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for prop, want := range map[string]string{
+		"SalesOrganization":       "1010",
+		"DistributionChannel":     "10",
+		"OrganizationDivision":    "00",
+		"SoldToParty":             "1000042",
+		"PurchaseOrderByCustomer": "PO-77",
+	} {
+		if got := s.lastOrder[prop]; got != want {
+			t.Errorf("%s = %v, want %s", prop, got, want)
+		}
+	}
+	partners := map[string]string{}
+	for _, raw := range s.lastOrder["_Partner"].([]any) {
+		partner := raw.(map[string]any)
+		partners[partner["PartnerFunction"].(string)] = partner["Customer"].(string)
+	}
+	want := map[string]string{"SP": "1000042", "SH": "1000043", "BP": "1000044", "PY": "1000045"}
+	if fmt.Sprint(partners) != fmt.Sprint(want) {
+		t.Errorf("partners = %v, want %v", partners, want)
+	}
+	if s.tokens != 1 || s.csrfFetches != 1 {
+		t.Errorf("tokens = %d, CSRF fetches = %d; want 1 each", s.tokens, s.csrfFetches)
+	}
+}
+
+func TestCSRFTokenIsRenewed(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+	order := erp.CreateOrderRequest{
+		Order:    erp.Order{Items: []erp.OrderItem{{SKU: "4711", Quantity: 1}}},
+		Customer: erp.Customer{ERPCustomerID: "1000042"},
+	}
+
+	if _, err := p.CreateOrder(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.csrfToken = "csrf-2"
+	s.mu.Unlock()
+	if _, err := p.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("CreateOrder() after token expiry error = %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.csrfFetches != 2 {
+		t.Errorf("CSRF fetches = %d, want 2", s.csrfFetches)
+	}
+}
+
+func TestErrorsBecomeMessages(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+
+	_, err := p.CreateOrder(context.Background(), erp.CreateOrderRequest{
+		Order:    erp.Order{Items: []erp.OrderItem{{SKU: "4711", Quantity: 1}}},
+		Customer: erp.Customer{ERPCustomerID: "BLOCKED"},
+	})
+	if !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Fatalf("error = %v, want ErrInvalidArgument", err)
+	}
+	var odataErr *Error
+	if !errors.As(err, &odataErr) {
+		t.Fatalf("error = %T, want *Error", err)
+	}
+	want := []erp.Message{
+		{Type: "error", Code: "V1/112", Message: "Customer BLOCKED is blocked for sales"},
+		{Type: "warning", Code: "V1/555", Message: "SoldToParty: Check the credit limit"},
+	}
+	if fmt.Sprint(odataErr.Messages) != fmt.Sprint(want) {
+		t.Errorf("Messages = %+v, want %+v", odataErr.Messages, want)
+	}
+	if got := erp.ErrorMessages(fmt.Errorf("dispatch: %w", err)); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ErrorMessages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSimulateAndRead(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+
+	sim, err := p.SimulateOrder(ctx, erp.SimulateOrderRequest{Items: []erp.SimulateItem{{SKU: "4711", Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("SimulateOrder() error = %v", err)
+	}
+	if sim.Totals.Total != 25 || !sim.Items[0].Available || len(sim.Schedule) != 1 {
+		t.Errorf("SimulateOrder() = %+v, want total 25, available, one delivery", sim)
+	}
+
+	prices, err := p.GetTierPrices(ctx, erp.TierPriceRequest{CustomerID: "1000042", SKUs: []string{"4711"}})
+	if err != nil || len(prices) != 2 || prices[0].MinQty != 1 || prices[1].Price != 9.8 {
+		t.Errorf("GetTierPrices() = %+v, %v; want two tiers ordered by quantity", prices, err)
+	}
+
+	company, err := p.SyncCompany(ctx, "1000042")
+	if err != nil || company.CreditLimit != 50000 || company.Attributes["Industry"] != "Construction" {
+		t.Errorf("SyncCompany() = %+v, %v", company, err)
+	}
+
+	status, err := p.GetOrderStatus(ctx, "1001")
+	if err != nil || status.Status != "delivered" || status.Items[0].ShippedQty != 8 {
+		t.Errorf("GetOrderStatus() = %+v, %v; want delivered with shipped items", status, err)
+	}
+}
+
+func TestNew_RejectsUnknownMappingKeys(t *testing.T) {
+	_, err := NewProvider(map[string]any{
+		"base_url":   "https://erp.example.com/odata/",
+		"properties": map[string]any{"order.numbr": "SalesOrderNumber"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "order.numbr") {
+		t.Errorf("error = %v, want unknown key order.numbr", err)
+	}
+}
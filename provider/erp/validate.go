@@ -0,0 +1,18 @@
+package erp
+
+import (
+	"fmt"
+
+	"github.com/gondolia/gondolia/provider"
+)
+
+// Validate checks the filter's pagination and date range.
+func (f ReportFilter) Validate() error {
+	if f.Limit < 0 || f.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	if !f.DateFrom.IsZero() && !f.DateTo.IsZero() && f.DateTo.Before(f.DateFrom) {
+		return fmt.Errorf("date range ends before it starts: %w", provider.ErrInvalidArgument)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package provider
+
+import "errors"
+
+// Errors shared by all provider categories. Implementations wrap them so that
+// callers can classify a failure without knowing the backend:
+//
+//	if errors.Is(err, provider.ErrNotFound) { ... }
+//
+// Category packages may define more specific errors that wrap these, such as
+// search.ErrIndexNotFound. The conformance suites (e.g. package searchtest)
+// check that providers return them.
+var (
+	// ErrNotFound is returned when the requested record does not exist in the backend.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidArgument is returned when a request is rejected before or by
+	// the backend because it is malformed, e.g. an empty ID or a negative limit.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrUnsupported is returned for operations or options the provider does
+	// not support, e.g. a notification channel it cannot deliver to.
+	ErrUnsupported = errors.New("unsupported")
+)
@@ -11,10 +11,12 @@ type FulfillmentProvider interface {
 	// CreateShipment creates a shipment order.
 	CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error)
 
-	// GetShipmentStatus retrieves shipment status.
+	// GetShipmentStatus retrieves shipment status. An unknown shipment
+	// returns an error wrapping provider.ErrNotFound.
 	GetShipmentStatus(ctx context.Context, shipmentID string) (*ShipmentStatus, error)
 
-	// CancelShipment cancels a shipment order.
+	// CancelShipment cancels a shipment order. Cancelling a shipment that
+	// is already cancelled is not an error.
 	CancelShipment(ctx context.Context, shipmentID string) error
 
 	// GetTrackingURL returns the tracking URL.
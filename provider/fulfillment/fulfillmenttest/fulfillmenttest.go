@@ -0,0 +1,153 @@
+// Package fulfillmenttest is a conformance suite for
+// fulfillment.FulfillmentProvider implementations. It checks shipping
+// calculation, idempotent cancellation, error types and context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    fulfillmenttest.Run(t, func(t *testing.T) fulfillment.FulfillmentProvider {
+//	        return newSandboxProvider(t)
+//	    }, fulfillmenttest.Options{Shipment: &sandboxShipment})
+//	}
+//
+// Shipments are only created if Options.Shipment is set, and are cancelled again.
+package fulfillmenttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/fulfillment"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that track no shipments, such as noop.
+	// Not-found checks are skipped.
+	Stateless bool
+
+	// Shipment is a request the carrier accepts, typically in a sandbox.
+	// The cancellation check is skipped if nil.
+	Shipment *fulfillment.ShipmentRequest
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) fulfillment.FulfillmentProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("NotFound", s.testNotFound)
+	t.Run("IdempotentCancel", s.testIdempotentCancel)
+	t.Run("CalculateShipping", s.testCalculateShipping)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+// parcel is a small package every carrier accepts.
+var parcel = fulfillment.Package{WeightGrams: 1000, LengthCm: 30, WidthCm: 20, HeightCm: 10}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+
+	_, err := p.CreateShipment(ctx, fulfillment.ShipmentRequest{OrderID: "1", Packages: []fulfillment.Package{parcel}})
+	providertest.ExpectCanceled(t, "CreateShipment", err)
+	_, err = p.GetShipmentStatus(ctx, "1")
+	providertest.ExpectCanceled(t, "GetShipmentStatus", err)
+	providertest.ExpectCanceled(t, "CancelShipment", p.CancelShipment(ctx, "1"))
+	_, err = p.GetTrackingURL(ctx, "1")
+	providertest.ExpectCanceled(t, "GetTrackingURL", err)
+	_, err = p.CalculateShipping(ctx, fulfillment.ShippingCalcRequest{Packages: []fulfillment.Package{parcel}})
+	providertest.ExpectCanceled(t, "CalculateShipping", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.CreateShipment(ctx, fulfillment.ShipmentRequest{OrderID: "1"})
+	providertest.ExpectError(t, "CreateShipment without packages", err, invalid)
+	_, err = p.GetShipmentStatus(ctx, "")
+	providertest.ExpectError(t, "GetShipmentStatus with empty ID", err, invalid)
+	providertest.ExpectError(t, "CancelShipment with empty ID", p.CancelShipment(ctx, ""), invalid)
+	_, err = p.GetTrackingURL(ctx, "")
+	providertest.ExpectError(t, "GetTrackingURL with empty tracking number", err, invalid)
+	_, err = p.CalculateShipping(ctx, fulfillment.ShippingCalcRequest{})
+	providertest.ExpectError(t, "CalculateShipping without packages", err, invalid)
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	if s.opts.Stateless {
+		t.Skip("provider is stateless")
+	}
+	p := s.newProvider(t)
+
+	_, err := p.GetShipmentStatus(context.Background(), providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "GetShipmentStatus of unknown shipment", err, provider.ErrNotFound)
+}
+
+func (s *suite) testIdempotentCancel(t *testing.T) {
+	if s.opts.Shipment == nil {
+		t.Skip("no Shipment configured")
+	}
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	result, err := p.CreateShipment(ctx, *s.opts.Shipment)
+	if err != nil {
+		t.Fatalf("CreateShipment() error = %v", err)
+	}
+	if result.ShipmentID == "" {
+		t.Fatal("CreateShipment() returned no ShipmentID")
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.CancelShipment(ctx, result.ShipmentID); err != nil {
+			t.Errorf("CancelShipment() #%d error = %v", i+1, err)
+		}
+	}
+}
+
+func (s *suite) testCalculateShipping(t *testing.T) {
+	p := s.newProvider(t)
+	req := fulfillment.ShippingCalcRequest{
+		From:     fulfillment.Address{Name: "Sender", Street: "Bahnhofstrasse 1", PostalCode: "8001", City: "Zürich", Country: "CH"},
+		To:       fulfillment.Address{Name: "Recipient", Street: "Marktgasse 1", PostalCode: "3011", City: "Bern", Country: "CH"},
+		Packages: []fulfillment.Package{parcel},
+	}
+	if s.opts.Shipment != nil {
+		req.From, req.To = s.opts.Shipment.From, s.opts.Shipment.To
+	}
+
+	options, err := p.CalculateShipping(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CalculateShipping() error = %v", err)
+	}
+	for _, option := range options {
+		if option.Service == "" {
+			t.Errorf("shipping option %+v has no Service", option)
+		}
+		if option.Currency == "" {
+			t.Errorf("shipping option %s has no Currency", option.Service)
+		}
+		if option.Price < 0 {
+			t.Errorf("shipping option %s has negative price %d", option.Service, option.Price)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package noop
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/gondolia/gondolia/provider"
@@ -32,6 +33,12 @@ func NewProvider(config map[string]any) (fulfillment.FulfillmentProvider, error)
 }
 
 func (p *Provider) CreateShipment(ctx context.Context, req fulfillment.ShipmentRequest) (*fulfillment.ShipmentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Packages) == 0 {
+		return nil, fmt.Errorf("noop: shipment has no packages: %w", provider.ErrInvalidArgument)
+	}
 	estimatedDate := time.Now().Add(7 * 24 * time.Hour)
 	return &fulfillment.ShipmentResult{
 		ShipmentID:     "noop-shipment-001",
@@ -42,6 +49,12 @@ func (p *Provider) CreateShipment(ctx context.Context, req fulfillment.ShipmentR
 }
 
 func (p *Provider) GetShipmentStatus(ctx context.Context, shipmentID string) (*fulfillment.ShipmentStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if shipmentID == "" {
+		return nil, fmt.Errorf("noop: shipment ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return &fulfillment.ShipmentStatus{
 		ShipmentID:     shipmentID,
 		Status:         "created",
@@ -51,14 +64,32 @@ func (p *Provider) GetShipmentStatus(ctx context.Context, shipmentID string) (*f
 }
 
 func (p *Provider) CancelShipment(ctx context.Context, shipmentID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if shipmentID == "" {
+		return fmt.Errorf("noop: shipment ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return nil
 }
 
 func (p *Provider) GetTrackingURL(ctx context.Context, trackingNumber string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if trackingNumber == "" {
+		return "", fmt.Errorf("noop: tracking number is required: %w", provider.ErrInvalidArgument)
+	}
 	return "https://example.com/track/" + trackingNumber, nil
 }
 
 func (p *Provider) CalculateShipping(ctx context.Context, req fulfillment.ShippingCalcRequest) ([]fulfillment.ShippingOption, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(req.Packages) == 0 {
+		return nil, fmt.Errorf("noop: no packages to ship: %w", provider.ErrInvalidArgument)
+	}
 	return []fulfillment.ShippingOption{
 		{
 			Service:       "standard",
@@ -0,0 +1,20 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/fulfillment"
+	"github.com/gondolia/gondolia/provider/fulfillment/fulfillmenttest"
+)
+
+func TestConformance(t *testing.T) {
+	fulfillmenttest.Run(t, func(t *testing.T) fulfillment.FulfillmentProvider {
+		return &Provider{}
+	}, fulfillmenttest.Options{
+		Stateless: true,
+		Shipment: &fulfillment.ShipmentRequest{
+			OrderID:  "ORD-1",
+			Packages: []fulfillment.Package{{WeightGrams: 500}},
+		},
+	})
+}
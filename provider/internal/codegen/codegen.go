@@ -0,0 +1,215 @@
+// Package codegen holds the parts shared by the generators that wrap the
+// provider interfaces (provider/tracing/gen and provider/replay/gen): the list
+// of interfaces, parsing them into methods with fully qualified types, and
+// writing formatted Go files.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModulePath is the import path of the provider packages.
+const ModulePath = "github.com/gondolia/gondolia/provider"
+
+// Target describes one provider interface to wrap.
+type Target struct {
+	Category  string // Package name and registry category, e.g. "erp"
+	Interface string // Interface name, e.g. "ERPProvider"
+	Wrapper   string // Exported wrap function suffix, e.g. "ERP"
+}
+
+// Targets lists all provider interfaces.
+var Targets = []Target{
+	{"auth", "AuthProvider", "Auth"},
+	{"crm", "CRMProvider", "CRM"},
+	{"erp", "ERPProvider", "ERP"},
+	{"fulfillment", "FulfillmentProvider", "Fulfillment"},
+	{"notification", "NotificationProvider", "Notification"},
+	{"payment", "PaymentProvider", "Payment"},
+	{"pim", "PIMProvider", "PIM"},
+	{"search", "SearchProvider", "Search"},
+	{"storage", "StorageProvider", "Storage"},
+	{"tax", "TaxProvider", "Tax"},
+}
+
+// Param is a parameter or result of an interface method.
+type Param struct {
+	Name string
+	Type string
+}
+
+// Method is an interface method with fully qualified types.
+type Method struct {
+	Name    string
+	Params  []Param
+	Results []Param
+	Imports map[string]bool
+}
+
+// Contextual reports whether the method takes a context first and returns an
+// error last, i.e. whether it is a call to the external system.
+func (m Method) Contextual() bool {
+	return len(m.Params) > 0 && m.Params[0].Type == "context.Context" &&
+		len(m.Results) > 0 && m.Results[len(m.Results)-1].Type == "error"
+}
+
+// ParseInterface parses the interface of t from the provider package in dir
+// (e.g. "../erp") into its methods. Unnamed parameters are named a0, a1, ...
+// and unnamed results r0, r1, ...
+func ParseInterface(dir string, t Target) ([]Method, error) {
+	path := filepath.Join(dir, t.Category+".go")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map import names to paths so qualified types can be re-imported.
+	importPaths := make(map[string]string)
+	for _, imp := range file.Imports {
+		p := strings.Trim(imp.Path.Value, `"`)
+		name := filepath.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		importPaths[name] = p
+	}
+
+	var iface *ast.InterfaceType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == t.Interface {
+			iface, _ = ts.Type.(*ast.InterfaceType)
+			return false
+		}
+		return iface == nil
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("%s: interface %s not found", path, t.Interface)
+	}
+
+	var methods []Method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", t.Interface)
+		}
+		m := Method{Name: field.Names[0].Name, Imports: make(map[string]bool)}
+		q := qualifier{pkg: t.Category, imports: importPaths, used: m.Imports}
+		m.Params = q.fields(fn.Params, "a")
+		if fn.Results != nil {
+			m.Results = q.fields(fn.Results, "r")
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// qualifier renders type expressions as seen from outside the provider package.
+type qualifier struct {
+	pkg     string
+	imports map[string]string
+	used    map[string]bool
+}
+
+func (q qualifier) fields(list *ast.FieldList, prefix string) []Param {
+	var params []Param
+	for _, f := range list.List {
+		typ := q.expr(f.Type)
+		if len(f.Names) == 0 {
+			params = append(params, Param{Name: fmt.Sprintf("%s%d", prefix, len(params)), Type: typ})
+			continue
+		}
+		for _, n := range f.Names {
+			params = append(params, Param{Name: n.Name, Type: typ})
+		}
+	}
+	return params
+}
+
+func (q qualifier) expr(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		if ast.IsExported(x.Name) {
+			q.used[ModulePath+"/"+q.pkg] = true
+			return q.pkg + "." + x.Name
+		}
+		return x.Name
+	case *ast.SelectorExpr:
+		pkg := x.X.(*ast.Ident).Name
+		q.used[q.imports[pkg]] = true
+		return pkg + "." + x.Sel.Name
+	case *ast.StarExpr:
+		return "*" + q.expr(x.X)
+	case *ast.ArrayType:
+		return "[]" + q.expr(x.Elt)
+	case *ast.MapType:
+		return "map[" + q.expr(x.Key) + "]" + q.expr(x.Value)
+	case *ast.Ellipsis:
+		return "..." + q.expr(x.Elt)
+	case *ast.InterfaceType:
+		return "any"
+	}
+	log.Fatalf("unsupported type expression %T", e)
+	return ""
+}
+
+// ResultList renders result types as they appear in a signature.
+func ResultList(types []string) string {
+	switch len(types) {
+	case 0:
+		return ""
+	case 1:
+		return types[0]
+	}
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
+// WriteImports writes an import block with standard library imports first.
+func WriteImports(b *bytes.Buffer, imports map[string]bool) {
+	var std, local []string
+	for p := range imports {
+		if strings.HasPrefix(p, "github.com/") {
+			local = append(local, p)
+		} else {
+			std = append(std, p)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(local)
+
+	b.WriteString("import (\n")
+	for _, p := range std {
+		fmt.Fprintf(b, "\t%q\n", p)
+	}
+	if len(std) > 0 && len(local) > 0 {
+		b.WriteString("\n")
+	}
+	for _, p := range local {
+		fmt.Fprintf(b, "\t%q\n", p)
+	}
+	b.WriteString(")\n\n")
+}
+
+// Format formats generated source and exits on syntax errors.
+func Format(src []byte) []byte {
+	out, err := format.Source(src)
+	if err != nil {
+		log.Fatalf("format generated code: %v\n%s", err, src)
+	}
+	return out
+}
+
+// Write writes a generated file.
+func Write(name string, data []byte) error {
+	return os.WriteFile(name, data, 0o644)
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Starter is implemented by providers that prepare connections or caches before
+// serving. The Resolver starts every instance it builds before handing it out.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Closer is implemented by providers that hold resources such as connection pools
+// or buffered writes. The Resolver closes an instance when it is replaced after a
+// configuration change and when the resolver itself is closed. Close should flush
+// pending work and release connections; it is called at most once.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// DefaultCloseTimeout bounds how long the Resolver waits for an instance that is
+// closed in the background after being replaced.
+const DefaultCloseTimeout = 30 * time.Second
+
+// ErrResolverClosed is returned when resolving a provider after Resolver.Close.
+var ErrResolverClosed = errors.New("provider resolver is closed")
+
+// ErrNotReady is returned by Resolver.Ready before Resolver.Start has succeeded.
+var ErrNotReady = errors.New("providers are not started yet")
+
+// Start starts p if it implements Starter and does nothing otherwise.
+// It is meant for providers created directly through a factory from Get.
+func Start(ctx context.Context, p any) error {
+	if s, ok := p.(Starter); ok {
+		return s.Start(ctx)
+	}
+	return nil
+}
+
+// Close closes p if it implements Closer and does nothing otherwise.
+// It is meant for providers created directly through a factory from Get.
+func Close(ctx context.Context, p any) error {
+	if c, ok := p.(Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
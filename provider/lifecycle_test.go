@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// lifecycleProvider records Start and Close calls.
+type lifecycleProvider struct {
+	value    string
+	mu       sync.Mutex
+	starts   int
+	closes   int
+	startErr error
+}
+
+func (p *lifecycleProvider) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.starts++
+	return p.startErr
+}
+
+func (p *lifecycleProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closes++
+	return nil
+}
+
+func (p *lifecycleProvider) counts() (starts, closes int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.starts, p.closes
+}
+
+// lifecycleDecorator wraps instances and records when the wrapper is closed.
+type lifecycleDecorator struct {
+	inner  *lifecycleProvider
+	closed *[]string
+}
+
+func (d *lifecycleDecorator) Close(ctx context.Context) error {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	*d.closed = append(*d.closed, "decorator:"+d.inner.value)
+	return nil
+}
+
+var (
+	lifecycleMu    sync.Mutex
+	lifecycleBuilt []*lifecycleProvider
+	lifecycleFail  = map[string]bool{}
+)
+
+func init() {
+	Register[*lifecycleProvider]("test-lifecycle", "recorder",
+		Metadata{Name: "recorder", Category: "test-lifecycle", ConfigSpec: []ConfigField{
+			{Key: "value", Type: "string", Required: true},
+		}},
+		func(config map[string]any) (*lifecycleProvider, error) {
+			lifecycleMu.Lock()
+			defer lifecycleMu.Unlock()
+			p := &lifecycleProvider{value: config["value"].(string)}
+			if lifecycleFail[p.value] {
+				p.startErr = errors.New("connection refused")
+			}
+			lifecycleBuilt = append(lifecycleBuilt, p)
+			return p, nil
+		},
+	)
+}
+
+func built(value string) []*lifecycleProvider {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	var result []*lifecycleProvider
+	for _, p := range lifecycleBuilt {
+		if p.value == value {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func lifecycleSelection(value string) map[string]any {
+	return map[string]any{TenantConfigKey: map[string]any{
+		"test-lifecycle": map[string]any{"name": "recorder", "config": map[string]any{"value": value}},
+	}}
+}
+
+func TestResolver_StartWarmsDefaultsBeforeReady(t *testing.T) {
+	r := NewResolver(nil)
+	r.SetDefault("test-lifecycle", Selection{Name: "recorder", Config: map[string]any{"value": "warm"}})
+	ctx := context.Background()
+
+	if err := r.Ready(); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("Ready() before Start = %v, want ErrNotReady", err)
+	}
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := r.Ready(); err != nil {
+		t.Fatalf("Ready() after Start = %v", err)
+	}
+
+	instances := built("warm")
+	if len(instances) != 1 {
+		t.Fatalf("built %d instances, want 1", len(instances))
+	}
+	if starts, _ := instances[0].counts(); starts != 1 {
+		t.Errorf("starts = %d, want 1", starts)
+	}
+
+	// Resolving afterwards reuses the warm instance
+	got, err := Resolve[*lifecycleProvider](ctx, r, "t1", "test-lifecycle")
+	if err != nil || got != instances[0] {
+		t.Fatalf("Resolve() = %p, %v; want the warm instance", got, err)
+	}
+	if err := r.Start(ctx); err != nil || len(built("warm")) != 1 {
+		t.Errorf("second Start() rebuilt the default: %v", err)
+	}
+}
+
+func TestResolver_StartFailureKeepsNotReady(t *testing.T) {
+	lifecycleMu.Lock()
+	lifecycleFail["unreachable"] = true
+	lifecycleMu.Unlock()
+
+	r := NewResolver(nil)
+	r.SetDefault("test-lifecycle", Selection{Name: "recorder", Config: map[string]any{"value": "unreachable"}})
+
+	if err := r.Start(context.Background()); err == nil {
+		t.Fatal("Start() succeeded for a provider that fails to start")
+	}
+	if err := r.Ready(); err == nil {
+		t.Fatal("Ready() = nil after failed Start")
+	}
+	instances := built("unreachable")
+	if _, closes := instances[0].counts(); closes != 1 {
+		t.Errorf("failed instance closed %d times, want 1", closes)
+	}
+}
+
+func TestResolver_SharesIdenticalSelectionsAndClosesReplaced(t *testing.T) {
+	configs := map[string]map[string]any{
+		"t1": lifecycleSelection("shared"),
+		"t2": lifecycleSelection("shared"),
+	}
+	var mu sync.Mutex
+	r := NewResolver(func(ctx context.Context, tenantID string) (map[string]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return configs[tenantID], nil
+	})
+	ctx := context.Background()
+
+	p1, err := Resolve[*lifecycleProvider](ctx, r, "t1", "test-lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := Resolve[*lifecycleProvider](ctx, r, "t2", "test-lifecycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p1 != p2 {
+		t.Fatal("tenants with identical selections got different instances")
+	}
+
+	// t1 moves away: the shared instance stays open for t2
+	mu.Lock()
+	configs["t1"] = lifecycleSelection("own")
+	mu.Unlock()
+	r.Invalidate("t1")
+	if _, err := Resolve[*lifecycleProvider](ctx, r, "t1", "test-lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if starts, closes := p1.counts(); starts != 1 || closes != 1 {
+		t.Errorf("shared instance started %d and closed %d times, want 1 and 1", starts, closes)
+	}
+	if _, closes := built("own")[0].counts(); closes != 1 {
+		t.Errorf("tenant instance closed %d times, want 1", closes)
+	}
+	if _, err := Resolve[*lifecycleProvider](ctx, r, "t2", "test-lifecycle"); !errors.Is(err, ErrResolverClosed) {
+		t.Errorf("Resolve() after Close = %v, want ErrResolverClosed", err)
+	}
+	if err := r.Ready(); !errors.Is(err, ErrResolverClosed) {
+		t.Errorf("Ready() after Close = %v, want ErrResolverClosed", err)
+	}
+}
+
+func TestResolver_ClosesReplacedInstanceAndDecorators(t *testing.T) {
+	configs := map[string]map[string]any{"t1": lifecycleSelection("before")}
+	var mu sync.Mutex
+	r := NewResolver(func(ctx context.Context, tenantID string) (map[string]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return configs[tenantID], nil
+	})
+	var closed []string
+	r.Use(func(category string, sel Selection, inst any) (any, error) {
+		return &lifecycleDecorator{inner: inst.(*lifecycleProvider), closed: &closed}, nil
+	})
+	ctx := context.Background()
+
+	if _, err := r.Instance(ctx, "t1", "test-lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	configs["t1"] = lifecycleSelection("after")
+	mu.Unlock()
+	r.Invalidate("t1")
+	if _, err := r.Instance(ctx, "t1", "test-lifecycle"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close waits for the replaced instance that is closed in the background
+	if err := r.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, closes := built("before")[0].counts(); closes != 1 {
+		t.Errorf("replaced instance closed %d times, want 1", closes)
+	}
+	if len(closed) != 2 {
+		t.Fatalf("decorators closed = %v, want both", closed)
+	}
+}
@@ -3,6 +3,8 @@ package noop
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/notification"
@@ -31,6 +33,12 @@ func NewProvider(config map[string]any) (notification.NotificationProvider, erro
 }
 
 func (p *Provider) Send(ctx context.Context, msg notification.Message) (*notification.SendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.validate(msg); err != nil {
+		return nil, err
+	}
 	return &notification.SendResult{
 		MessageID: "noop-msg-001",
 		Status:    "sent",
@@ -39,8 +47,15 @@ func (p *Provider) Send(ctx context.Context, msg notification.Message) (*notific
 }
 
 func (p *Provider) SendBatch(ctx context.Context, msgs []notification.Message) ([]notification.SendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	results := make([]notification.SendResult, len(msgs))
-	for i := range msgs {
+	for i, msg := range msgs {
+		if err := p.validate(msg); err != nil {
+			results[i] = notification.SendResult{Status: "failed", Error: err.Error()}
+			continue
+		}
 		results[i] = notification.SendResult{
 			MessageID: "noop-msg-001",
 			Status:    "sent",
@@ -50,6 +65,17 @@ func (p *Provider) SendBatch(ctx context.Context, msgs []notification.Message) (
 	return results, nil
 }
 
+// validate rejects messages a real provider could not send either.
+func (p *Provider) validate(msg notification.Message) error {
+	if !slices.Contains(p.Channels(), msg.Channel) {
+		return fmt.Errorf("noop: channel %q: %w", msg.Channel, provider.ErrUnsupported)
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("noop: message has no recipients: %w", provider.ErrInvalidArgument)
+	}
+	return nil
+}
+
 func (p *Provider) Channels() []string {
 	return []string{"email", "sms", "push"}
 }
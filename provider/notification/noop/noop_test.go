@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/notification"
+	"github.com/gondolia/gondolia/provider/notification/notificationtest"
+)
+
+func TestConformance(t *testing.T) {
+	notificationtest.Run(t, func(t *testing.T) notification.NotificationProvider {
+		return &Provider{}
+	}, notificationtest.Options{Recipients: map[string]string{"email": "qa@example.com", "sms": "+41790000000"}})
+}
@@ -5,10 +5,14 @@ import "context"
 
 // NotificationProvider abstracts notification channels (Email, SMS, Push, etc.).
 type NotificationProvider interface {
-	// Send sends a notification.
+	// Send sends a notification. Messages without recipients fail with
+	// provider.ErrInvalidArgument, messages for a channel the provider does not
+	// serve with provider.ErrUnsupported.
 	Send(ctx context.Context, msg Message) (*SendResult, error)
 
-	// SendBatch sends multiple notifications.
+	// SendBatch sends multiple notifications. It returns one result per message,
+	// in order; a message that cannot be sent is reported in its result with
+	// status "failed" and does not fail the batch.
 	SendBatch(ctx context.Context, msgs []Message) ([]SendResult, error)
 
 	// Channels returns the supported channels.
@@ -0,0 +1,156 @@
+// Package notificationtest is a conformance suite for
+// notification.NotificationProvider implementations. It checks message
+// validation, error types, batch results and context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    notificationtest.Run(t, func(t *testing.T) notification.NotificationProvider {
+//	        return newSandboxProvider(t)
+//	    }, notificationtest.Options{Recipients: map[string]string{"email": "qa@example.com"}})
+//	}
+//
+// Messages are only delivered to the configured recipients.
+package notificationtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/notification"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Recipients maps a channel to the address test messages are sent to.
+	// Channels without a recipient are only checked with invalid messages.
+	Recipients map[string]string
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) notification.NotificationProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidMessages", s.testInvalidMessages)
+	t.Run("Send", s.testSend)
+	t.Run("SendBatch", s.testSendBatch)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+// message returns a test message for channel, addressed to to if not empty.
+func message(channel, to string) notification.Message {
+	msg := notification.Message{
+		Channel:   channel,
+		Subject:   "Conformance test",
+		Body:      "<p>Conformance test message</p>",
+		BodyPlain: "Conformance test message",
+	}
+	if to != "" {
+		msg.To = []notification.Recipient{{Address: to, Name: "Conformance"}}
+	}
+	return msg
+}
+
+// anyChannel returns a channel the provider serves.
+func anyChannel(t *testing.T, p notification.NotificationProvider) string {
+	t.Helper()
+	channels := p.Channels()
+	if len(channels) == 0 {
+		t.Fatal("Channels() is empty")
+	}
+	return channels[0]
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	p := s.newProvider(t)
+	if p.Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+	if len(p.Channels()) == 0 {
+		t.Error("Channels() is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+	msg := message(anyChannel(t, p), "conformance@example.com")
+
+	_, err := p.Send(ctx, msg)
+	providertest.ExpectCanceled(t, "Send", err)
+	_, err = p.SendBatch(ctx, []notification.Message{msg})
+	providertest.ExpectCanceled(t, "SendBatch", err)
+}
+
+func (s *suite) testInvalidMessages(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	_, err := p.Send(ctx, message(anyChannel(t, p), ""))
+	providertest.ExpectError(t, "Send without recipients", err, provider.ErrInvalidArgument)
+	_, err = p.Send(ctx, message("conformance-carrier-pigeon", "conformance@example.com"))
+	providertest.ExpectError(t, "Send to unknown channel", err, provider.ErrUnsupported)
+}
+
+func (s *suite) testSend(t *testing.T) {
+	if len(s.opts.Recipients) == 0 {
+		t.Skip("no recipients configured")
+	}
+	p := s.newProvider(t)
+
+	for channel, to := range s.opts.Recipients {
+		result, err := p.Send(context.Background(), message(channel, to))
+		if err != nil {
+			t.Errorf("Send(%s) error = %v", channel, err)
+			continue
+		}
+		if result.Status != "sent" && result.Status != "queued" {
+			t.Errorf("Send(%s) status = %q, want sent or queued", channel, result.Status)
+		}
+		if result.MessageID == "" {
+			t.Errorf("Send(%s) returned no message ID", channel)
+		}
+	}
+}
+
+func (s *suite) testSendBatch(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	results, err := p.SendBatch(ctx, nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("SendBatch(nil) = %d results, %v; want none", len(results), err)
+	}
+
+	// An invalid message is reported in its result and does not fail the batch
+	msgs := []notification.Message{message(anyChannel(t, p), "")}
+	for channel, to := range s.opts.Recipients {
+		msgs = append(msgs, message(channel, to))
+	}
+	results, err = p.SendBatch(ctx, msgs)
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if len(results) != len(msgs) {
+		t.Fatalf("SendBatch() returned %d results for %d messages", len(results), len(msgs))
+	}
+	if results[0].Status != "failed" || results[0].Error == "" {
+		t.Errorf("result of invalid message = %+v, want status failed with error", results[0])
+	}
+	for i, result := range results[1:] {
+		if result.Status != "sent" && result.Status != "queued" {
+			t.Errorf("result %d status = %q (%s), want sent or queued", i+1, result.Status, result.Error)
+		}
+	}
+}
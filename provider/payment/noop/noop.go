@@ -3,6 +3,7 @@ package noop
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/payment"
@@ -31,6 +32,12 @@ func NewProvider(config map[string]any) (payment.PaymentProvider, error) {
 }
 
 func (p *Provider) Initialize(ctx context.Context, req payment.InitializeRequest) (*payment.PaymentSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Amount.Value <= 0 {
+		return nil, fmt.Errorf("noop: amount must be positive: %w", provider.ErrInvalidArgument)
+	}
 	return &payment.PaymentSession{
 		SessionID:   "noop-session-001",
 		RedirectURL: req.ReturnURL,
@@ -40,6 +47,12 @@ func (p *Provider) Initialize(ctx context.Context, req payment.InitializeRequest
 }
 
 func (p *Provider) Authorize(ctx context.Context, sessionID string) (*payment.AuthorizationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if sessionID == "" {
+		return nil, fmt.Errorf("noop: session ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return &payment.AuthorizationResult{
 		TransactionID:  "noop-transaction-001",
 		Status:         "authorized",
@@ -52,6 +65,15 @@ func (p *Provider) Authorize(ctx context.Context, sessionID string) (*payment.Au
 }
 
 func (p *Provider) Capture(ctx context.Context, transactionID string, amount *payment.Amount) (*payment.CaptureResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if transactionID == "" {
+		return nil, fmt.Errorf("noop: transaction ID is required: %w", provider.ErrInvalidArgument)
+	}
+	if amount != nil && amount.Value <= 0 {
+		return nil, fmt.Errorf("noop: amount must be positive: %w", provider.ErrInvalidArgument)
+	}
 	return &payment.CaptureResult{
 		TransactionID: transactionID,
 		Status:        "captured",
@@ -60,10 +82,25 @@ func (p *Provider) Capture(ctx context.Context, transactionID string, amount *pa
 }
 
 func (p *Provider) Cancel(ctx context.Context, transactionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if transactionID == "" {
+		return fmt.Errorf("noop: transaction ID is required: %w", provider.ErrInvalidArgument)
+	}
 	return nil
 }
 
 func (p *Provider) Refund(ctx context.Context, transactionID string, amount payment.Amount) (*payment.RefundResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if transactionID == "" {
+		return nil, fmt.Errorf("noop: transaction ID is required: %w", provider.ErrInvalidArgument)
+	}
+	if amount.Value <= 0 {
+		return nil, fmt.Errorf("noop: amount must be positive: %w", provider.ErrInvalidArgument)
+	}
 	return &payment.RefundResult{
 		RefundID: "noop-refund-001",
 		Status:   "refunded",
@@ -72,6 +109,12 @@ func (p *Provider) Refund(ctx context.Context, transactionID string, amount paym
 }
 
 func (p *Provider) HandleWebhook(ctx context.Context, payload []byte, headers map[string]string) (*payment.WebhookEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("noop: webhook payload is empty: %w", provider.ErrInvalidArgument)
+	}
 	return &payment.WebhookEvent{
 		Type:          "payment.authorized",
 		TransactionID: "noop-transaction-001",
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/payment"
+	"github.com/gondolia/gondolia/provider/payment/paymenttest"
+)
+
+func TestConformance(t *testing.T) {
+	paymenttest.Run(t, func(t *testing.T) payment.PaymentProvider {
+		return &Provider{}
+	}, paymenttest.Options{Stateless: true})
+}
@@ -0,0 +1,113 @@
+// Package paymenttest is a conformance suite for payment.PaymentProvider
+// implementations. It checks argument validation, error types and context
+// cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories,
+// against the payment service provider's sandbox:
+//
+//	func TestConformance(t *testing.T) {
+//	    paymenttest.Run(t, func(t *testing.T) payment.PaymentProvider {
+//	        return newSandboxProvider(t)
+//	    }, paymenttest.Options{})
+//	}
+//
+// Authorizing a payment needs a customer interaction, so the suite does not
+// move money and only uses IDs that do not exist.
+package paymenttest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/payment"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that hold no data and answer every call
+	// with placeholder values, such as noop. Not-found checks are skipped.
+	Stateless bool
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) payment.PaymentProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("NotFound", s.testNotFound)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+	amount := payment.Amount{Value: 1000, Currency: "CHF"}
+
+	_, err := p.Initialize(ctx, payment.InitializeRequest{OrderID: "1", Amount: amount, Currency: "CHF", ReturnURL: "https://example.com/return"})
+	providertest.ExpectCanceled(t, "Initialize", err)
+	_, err = p.Authorize(ctx, "1")
+	providertest.ExpectCanceled(t, "Authorize", err)
+	_, err = p.Capture(ctx, "1", nil)
+	providertest.ExpectCanceled(t, "Capture", err)
+	providertest.ExpectCanceled(t, "Cancel", p.Cancel(ctx, "1"))
+	_, err = p.Refund(ctx, "1", amount)
+	providertest.ExpectCanceled(t, "Refund", err)
+	_, err = p.HandleWebhook(ctx, []byte(`{}`), map[string]string{})
+	providertest.ExpectCanceled(t, "HandleWebhook", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.Initialize(ctx, payment.InitializeRequest{OrderID: "1", Amount: payment.Amount{Value: 0, Currency: "CHF"}, Currency: "CHF"})
+	providertest.ExpectError(t, "Initialize with zero amount", err, invalid)
+	_, err = p.Authorize(ctx, "")
+	providertest.ExpectError(t, "Authorize with empty session", err, invalid)
+	_, err = p.Capture(ctx, "", nil)
+	providertest.ExpectError(t, "Capture with empty transaction", err, invalid)
+	_, err = p.Capture(ctx, "1", &payment.Amount{Value: -1, Currency: "CHF"})
+	providertest.ExpectError(t, "Capture with negative amount", err, invalid)
+	providertest.ExpectError(t, "Cancel with empty transaction", p.Cancel(ctx, ""), invalid)
+	_, err = p.Refund(ctx, "", payment.Amount{Value: 100, Currency: "CHF"})
+	providertest.ExpectError(t, "Refund with empty transaction", err, invalid)
+	_, err = p.Refund(ctx, "1", payment.Amount{Value: 0, Currency: "CHF"})
+	providertest.ExpectError(t, "Refund with zero amount", err, invalid)
+	_, err = p.HandleWebhook(ctx, nil, nil)
+	providertest.ExpectError(t, "HandleWebhook with empty payload", err, invalid)
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	if s.opts.Stateless {
+		t.Skip("provider is stateless")
+	}
+	p := s.newProvider(t)
+	ctx := context.Background()
+	missing := providertest.UniqueName("conformance-missing-")
+
+	_, err := p.Authorize(ctx, missing)
+	providertest.ExpectError(t, "Authorize of unknown session", err, provider.ErrNotFound)
+	_, err = p.Capture(ctx, missing, nil)
+	providertest.ExpectError(t, "Capture of unknown transaction", err, provider.ErrNotFound)
+	providertest.ExpectError(t, "Cancel of unknown transaction", p.Cancel(ctx, missing), provider.ErrNotFound)
+	_, err = p.Refund(ctx, missing, payment.Amount{Value: 100, Currency: "CHF"})
+	providertest.ExpectError(t, "Refund of unknown transaction", err, provider.ErrNotFound)
+}
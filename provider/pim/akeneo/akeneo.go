@@ -0,0 +1,571 @@
+// Package akeneo provides a PIM provider for the Akeneo PIM REST API.
+//
+// The provider authenticates with the OAuth2 password grant of an Akeneo
+// connection and refreshes the access token before it expires. Products and
+// product models are read with search_after pagination, so large catalogs can
+// be walked without deep offsets; categories, attributes and family variants
+// are read in full.
+//
+//	pim:
+//	  name: akeneo
+//	  config:
+//	    base_url: https://pim.example.com
+//	    client_id: 1_abc
+//	    client_secret: file:/run/secrets/akeneo-secret
+//	    username: gondolia_1234
+//	    password: file:/run/secrets/akeneo-password
+//	    channel: ecommerce
+//
+// DownloadAsset downloads media files by their code, e.g. the data of an
+// image attribute value. Error responses are returned as *Error.
+package akeneo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+)
+
+func init() {
+	provider.RegisterTyped[pim.PIMProvider, Config]("pim", "akeneo",
+		provider.Metadata{
+			Name:        "akeneo",
+			DisplayName: "Akeneo PIM",
+			Category:    "pim",
+			Version:     "1.0.0",
+			Description: "Products, categories, attributes and media files from the Akeneo REST API",
+			ConfigSpec:  configSpec,
+		},
+		New,
+	)
+}
+
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{Key: "base_url", Type: "string", Required: true, Description: "Akeneo URL, e.g. https://pim.example.com"},
+	{Key: "client_id", Type: "string", Required: true, Description: "Client ID of the Akeneo connection"},
+	{Key: "client_secret", Type: "secret", Required: true, Description: "Secret of the Akeneo connection"},
+	{Key: "username", Type: "string", Required: true, Description: "API user of the connection"},
+	{Key: "password", Type: "secret", Required: true, Description: "Password of the API user"},
+	{Key: "channel", Type: "string", Description: "Channel (scope) to read product values of; all if empty"},
+	{Key: "locales", Type: "array", Description: "Locales to read product values of, e.g. [de_CH, fr_CH]; all if empty"},
+	{Key: "timeout", Type: "duration", Default: "30s", Description: "HTTP request timeout"},
+}
+
+// Config holds the Akeneo provider configuration.
+type Config struct {
+	BaseURL      string        `config:"base_url"`
+	ClientID     string        `config:"client_id"`
+	ClientSecret string        `config:"client_secret"`
+	Username     string        `config:"username"`
+	Password     string        `config:"password"`
+	Channel      string        `config:"channel"`
+	Locales      []string      `config:"locales"`
+	Timeout      time.Duration `config:"timeout"`
+}
+
+const (
+	defaultPageSize = 100 // Page size of product filters without a limit
+	maxPageSize     = 100 // Largest page the API returns
+)
+
+// cursorPrefix marks cursors issued by this provider.
+const cursorPrefix = "akeneo:"
+
+// Provider is an Akeneo PIM provider.
+type Provider struct {
+	client *client
+	cfg    Config
+}
+
+// NewProvider creates a new Akeneo provider from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
+func NewProvider(config map[string]any) (pim.PIMProvider, error) {
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("akeneo: %w", err)
+	}
+	var cfg Config
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("akeneo: %w", err)
+	}
+	return New(cfg)
+}
+
+// New creates a new Akeneo provider from a typed configuration.
+func New(cfg Config) (pim.PIMProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("akeneo: base_url is required")
+	}
+	if _, err := url.ParseRequestURI(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("akeneo: invalid base_url: %w", err)
+	}
+	if cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.Username == "" || cfg.Password == "" {
+		return nil, fmt.Errorf("akeneo: client_id, client_secret, username and password are required")
+	}
+	return &Provider{client: newClient(cfg), cfg: cfg}, nil
+}
+
+// Start checks that the credentials are accepted by requesting a token.
+func (p *Provider) Start(ctx context.Context) error {
+	_, err := p.client.accessToken(ctx)
+	return err
+}
+
+// product is a product of the Akeneo API.
+type product struct {
+	Identifier string                     `json:"identifier"`
+	Parent     *string                    `json:"parent"`
+	Family     string                     `json:"family"`
+	Categories []string                   `json:"categories"`
+	Enabled    bool                       `json:"enabled"`
+	Values     map[string][]attributeData `json:"values"`
+	Created    string                     `json:"created"`
+	Updated    string                     `json:"updated"`
+}
+
+type attributeData struct {
+	Locale *string `json:"locale"`
+	Scope  *string `json:"scope"`
+	Data   any     `json:"data"`
+}
+
+func (p *product) toPIM() pim.Product {
+	converted := pim.Product{
+		Identifier: p.Identifier,
+		Family:     p.Family,
+		Categories: orEmpty(p.Categories),
+		Enabled:    p.Enabled,
+		Values:     toValues(p.Values),
+		Created:    parseTime(p.Created),
+		Updated:    parseTime(p.Updated),
+	}
+	if p.Parent != nil {
+		converted.Parent = *p.Parent
+	}
+	return converted
+}
+
+// productModel is a product model of the Akeneo API.
+type productModel struct {
+	Code          string                     `json:"code"`
+	Parent        *string                    `json:"parent"`
+	Family        string                     `json:"family"`
+	FamilyVariant string                     `json:"family_variant"`
+	Categories    []string                   `json:"categories"`
+	Values        map[string][]attributeData `json:"values"`
+	Created       string                     `json:"created"`
+	Updated       string                     `json:"updated"`
+}
+
+func (m *productModel) toPIM() pim.ProductModel {
+	converted := pim.ProductModel{
+		Code:          m.Code,
+		Family:        m.Family,
+		FamilyVariant: m.FamilyVariant,
+		Categories:    orEmpty(m.Categories),
+		Values:        toValues(m.Values),
+		Created:       parseTime(m.Created),
+		Updated:       parseTime(m.Updated),
+	}
+	if m.Parent != nil {
+		converted.Parent = *m.Parent
+	}
+	return converted
+}
+
+func toValues(data map[string][]attributeData) map[string][]pim.AttributeValue {
+	values := make(map[string][]pim.AttributeValue, len(data))
+	for code, list := range data {
+		for _, v := range list {
+			value := pim.AttributeValue{Data: normalize(v.Data)}
+			if v.Locale != nil {
+				value.Locale = *v.Locale
+			}
+			if v.Scope != nil {
+				value.Scope = *v.Scope
+			}
+			values[code] = append(values[code], value)
+		}
+	}
+	return values
+}
+
+func orEmpty(codes []string) []string {
+	if codes == nil {
+		return []string{}
+	}
+	return codes
+}
+
+// normalize converts the json.Numbers of a decoded value: integers to int64
+// and other numbers to float64. Akeneo sends decimals of number and metric
+// attributes as strings, which are kept.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+	}
+	return v
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// FetchProducts reads a page of products with search_after pagination. The
+// cursor holds the search_after value of the next page.
+func (p *Provider) FetchProducts(ctx context.Context, filter pim.ProductFilter) (*pim.ProductPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query, err := p.listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var result page[product]
+	if err := p.client.getJSON(ctx, "/api/rest/v1/products", query, &result); err != nil {
+		return nil, err
+	}
+
+	products := make([]pim.Product, len(result.Embedded.Items))
+	for i := range result.Embedded.Items {
+		products[i] = result.Embedded.Items[i].toPIM()
+	}
+	next, err := nextCursor(result.Links.Next.Href, len(products))
+	if err != nil {
+		return nil, err
+	}
+	return &pim.ProductPage{Products: products, NextCursor: next}, nil
+}
+
+// listQuery builds the query of a product or product model list.
+func (p *Provider) listQuery(filter pim.ProductFilter) (url.Values, error) {
+	if filter.Limit < 0 {
+		return nil, fmt.Errorf("akeneo: limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	query := url.Values{
+		"pagination_type": {"search_after"},
+		"limit":           {strconv.Itoa(limit)},
+	}
+	if filter.Cursor != "" {
+		searchAfter, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("search_after", searchAfter)
+	}
+
+	search := make(map[string][]map[string]any)
+	if filter.UpdatedSince != nil {
+		search["updated"] = []map[string]any{{"operator": ">", "value": filter.UpdatedSince.UTC().Format(time.DateTime)}}
+	}
+	if len(filter.Families) > 0 {
+		search["family"] = []map[string]any{{"operator": "IN", "value": filter.Families}}
+	}
+	if len(filter.Categories) > 0 {
+		search["categories"] = []map[string]any{{"operator": "IN", "value": filter.Categories}}
+	}
+	if len(search) > 0 {
+		var encoded strings.Builder
+		enc := json.NewEncoder(&encoded)
+		enc.SetEscapeHTML(false) // Keep the > operator readable in logged URLs
+		if err := enc.Encode(search); err != nil {
+			return nil, err
+		}
+		query.Set("search", strings.TrimSpace(encoded.String()))
+	}
+	p.setValueFilters(query)
+	return query, nil
+}
+
+// nextCursor returns the cursor of the page a next link points to.
+func nextCursor(href string, items int) (string, error) {
+	if href == "" || items == 0 {
+		return "", nil
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("akeneo: invalid next link: %w", err)
+	}
+	if searchAfter := u.Query().Get("search_after"); searchAfter != "" {
+		return encodeCursor(searchAfter), nil
+	}
+	return "", nil
+}
+
+// FetchProduct reads a single product by identifier.
+func (p *Provider) FetchProduct(ctx context.Context, identifier string) (*pim.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if identifier == "" {
+		return nil, fmt.Errorf("akeneo: identifier is required: %w", provider.ErrInvalidArgument)
+	}
+
+	query := url.Values{}
+	p.setValueFilters(query)
+
+	var result product
+	if err := p.client.getJSON(ctx, "/api/rest/v1/products/"+url.PathEscape(identifier), query, &result); err != nil {
+		return nil, err
+	}
+	converted := result.toPIM()
+	return &converted, nil
+}
+
+// FetchProductModels reads a page of root and sub product models with
+// search_after pagination.
+func (p *Provider) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (*pim.ProductModelPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query, err := p.listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var result page[productModel]
+	if err := p.client.getJSON(ctx, "/api/rest/v1/product-models", query, &result); err != nil {
+		return nil, err
+	}
+
+	models := make([]pim.ProductModel, len(result.Embedded.Items))
+	for i := range result.Embedded.Items {
+		models[i] = result.Embedded.Items[i].toPIM()
+	}
+	next, err := nextCursor(result.Links.Next.Href, len(models))
+	if err != nil {
+		return nil, err
+	}
+	return &pim.ProductModelPage{Models: models, NextCursor: next}, nil
+}
+
+// FetchProductModel reads a single product model by code.
+func (p *Provider) FetchProductModel(ctx context.Context, code string) (*pim.ProductModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("akeneo: code is required: %w", provider.ErrInvalidArgument)
+	}
+
+	query := url.Values{}
+	p.setValueFilters(query)
+
+	var result productModel
+	if err := p.client.getJSON(ctx, "/api/rest/v1/product-models/"+url.PathEscape(code), query, &result); err != nil {
+		return nil, err
+	}
+	converted := result.toPIM()
+	return &converted, nil
+}
+
+// FetchFamilyVariants reads the family variants of every family.
+func (p *Provider) FetchFamilyVariants(ctx context.Context) ([]pim.FamilyVariant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	families, err := listAll[struct {
+		Code string `json:"code"`
+	}](ctx, p.client, "/api/rest/v1/families")
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []pim.FamilyVariant
+	for _, family := range families {
+		items, err := listAll[struct {
+			Code   string            `json:"code"`
+			Labels map[string]string `json:"labels"`
+			Sets   []struct {
+				Level      int      `json:"level"`
+				Axes       []string `json:"axes"`
+				Attributes []string `json:"attributes"`
+			} `json:"variant_attribute_sets"`
+		}](ctx, p.client, "/api/rest/v1/families/"+url.PathEscape(family.Code)+"/variants")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			sort.Slice(item.Sets, func(i, j int) bool { return item.Sets[i].Level < item.Sets[j].Level })
+			variant := pim.FamilyVariant{Code: item.Code, Family: family.Code, Labels: item.Labels}
+			for _, set := range item.Sets {
+				variant.Levels = append(variant.Levels, pim.VariantLevel{Axes: set.Axes, Attributes: set.Attributes})
+			}
+			variants = append(variants, variant)
+		}
+	}
+	return variants, nil
+}
+
+// setValueFilters limits product values to the configured channel and locales.
+func (p *Provider) setValueFilters(query url.Values) {
+	if p.cfg.Channel != "" {
+		query.Set("scope", p.cfg.Channel)
+	}
+	if len(p.cfg.Locales) > 0 {
+		query.Set("locales", strings.Join(p.cfg.Locales, ","))
+	}
+}
+
+// FetchCategories reads all categories of all category trees.
+func (p *Provider) FetchCategories(ctx context.Context) ([]pim.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := listAll[struct {
+		Code   string            `json:"code"`
+		Parent *string           `json:"parent"`
+		Labels map[string]string `json:"labels"`
+	}](ctx, p.client, "/api/rest/v1/categories")
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]pim.Category, len(items))
+	for i, item := range items {
+		categories[i] = pim.Category{Code: item.Code, Labels: item.Labels}
+		if item.Parent != nil {
+			categories[i].Parent = *item.Parent
+		}
+	}
+	return categories, nil
+}
+
+// attributeTypes maps Akeneo attribute types to the generic PIM types.
+var attributeTypes = map[string]string{
+	"pim_catalog_identifier":          "identifier",
+	"pim_catalog_text":                "text",
+	"pim_catalog_textarea":            "textarea",
+	"pim_catalog_number":              "number",
+	"pim_catalog_metric":              "metric",
+	"pim_catalog_price_collection":    "price",
+	"pim_catalog_boolean":             "boolean",
+	"pim_catalog_date":                "date",
+	"pim_catalog_simpleselect":        "select",
+	"pim_catalog_multiselect":         "multiselect",
+	"pim_catalog_image":               "media",
+	"pim_catalog_file":                "media",
+	"pim_catalog_asset_collection":    "asset_collection",
+	"pim_reference_data_simpleselect": "select",
+	"pim_reference_data_multiselect":  "multiselect",
+}
+
+// FetchAttributes reads all attribute definitions.
+func (p *Provider) FetchAttributes(ctx context.Context) ([]pim.Attribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := listAll[struct {
+		Code        string            `json:"code"`
+		Type        string            `json:"type"`
+		Group       string            `json:"group"`
+		Localizable bool              `json:"localizable"`
+		Scopable    bool              `json:"scopable"`
+		Labels      map[string]string `json:"labels"`
+	}](ctx, p.client, "/api/rest/v1/attributes")
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]pim.Attribute, len(items))
+	for i, item := range items {
+		attrType, ok := attributeTypes[item.Type]
+		if !ok {
+			attrType = strings.TrimPrefix(item.Type, "pim_catalog_")
+		}
+		attributes[i] = pim.Attribute{
+			Code:        item.Code,
+			Type:        attrType,
+			Group:       item.Group,
+			Localizable: item.Localizable,
+			Scopable:    item.Scopable,
+			Labels:      item.Labels,
+		}
+	}
+	return attributes, nil
+}
+
+// DownloadAsset downloads a media file by its code, as found in the values
+// of image and file attributes.
+func (p *Provider) DownloadAsset(ctx context.Context, assetCode string) (io.ReadCloser, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	if assetCode == "" {
+		return nil, "", fmt.Errorf("akeneo: asset code is required: %w", provider.ErrInvalidArgument)
+	}
+
+	// Media file codes are paths like 8/d/3/a/8d3a..._image.jpg
+	segments := strings.Split(assetCode, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	resp, err := p.client.get(ctx, "/api/rest/v1/media-files/"+strings.Join(segments, "/")+"/download", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return resp.Body, contentType, nil
+}
+
+func (p *Provider) Metadata() pim.Metadata {
+	return pim.Metadata{
+		Name:    "akeneo",
+		Version: "1.0.0",
+	}
+}
+
+func encodeCursor(searchAfter string) string {
+	return cursorPrefix + base64.RawURLEncoding.EncodeToString([]byte(searchAfter))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	encoded, ok := strings.CutPrefix(cursor, cursorPrefix)
+	if !ok {
+		return "", fmt.Errorf("akeneo: unknown cursor: %w", provider.ErrInvalidArgument)
+	}
+	searchAfter, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(searchAfter) == 0 {
+		return "", fmt.Errorf("akeneo: invalid cursor: %w", provider.ErrInvalidArgument)
+	}
+	return string(searchAfter), nil
+}
@@ -0,0 +1,472 @@
+package akeneo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/pim/pimtest"
+)
+
+const mediaCode = "8/d/3/a/8d3a_drill.jpg"
+
+// standIn is an Akeneo API with the OAuth2 token endpoint, search_after
+// product pagination, product models, family variants, categories,
+// attributes and media files.
+type standIn struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	products []map[string]any
+	models   []map[string]any
+	grants   []string
+	issued   int
+	revoked  map[string]bool
+	queries  []url.Values
+	linkBase string // base of the category page links, the stand-in itself if empty
+}
+
+func newStandIn(t *testing.T) *standIn {
+	s := &standIn{revoked: make(map[string]bool)}
+	for i, family := range []string{"drills", "drills", "saws", "saws", "drills"} {
+		n := strconv.Itoa(i + 1)
+		s.products = append(s.products, map[string]any{
+			"identifier": "SKU-" + n,
+			"family":     family,
+			"categories": []string{"tools", family},
+			"enabled":    true,
+			"values": map[string]any{
+				"name":   []any{map[string]any{"locale": "de_CH", "scope": nil, "data": "Produkt " + n}},
+				"weight": []any{map[string]any{"locale": nil, "scope": nil, "data": map[string]any{"amount": "1.5000", "unit": "KILOGRAM"}}},
+				"stock":  []any{map[string]any{"locale": nil, "scope": "ecommerce", "data": i * 10}},
+			},
+			"created": "2024-01-0" + n + "T10:00:00+00:00",
+			"updated": "2024-02-0" + n + "T10:00:00+00:00",
+		})
+	}
+	s.products[0]["parent"] = "drill_red"
+	s.models = []map[string]any{
+		{"code": "drill", "parent": nil, "family": "drills", "family_variant": "drills_color_voltage", "categories": []string{"drills"},
+			"values":  map[string]any{"name": []any{map[string]any{"locale": "de_CH", "scope": nil, "data": "Bohrer"}}},
+			"created": "2024-01-01T09:00:00+00:00", "updated": "2024-02-01T09:00:00+00:00"},
+		{"code": "drill_red", "parent": "drill", "family": "drills", "family_variant": "drills_color_voltage", "categories": []string{"drills"},
+			"values":  map[string]any{"color": []any{map[string]any{"locale": nil, "scope": nil, "data": "red"}}},
+			"created": "2024-01-01T09:00:00+00:00", "updated": "2024-02-01T09:00:00+00:00"},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *standIn) config() map[string]any {
+	return map[string]any{
+		"base_url":      s.URL,
+		"client_id":     "1_gondolia",
+		"client_secret": "secret",
+		"username":      "gondolia_api",
+		"password":      "password",
+		"channel":       "ecommerce",
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"code": status, "message": message})
+}
+
+func (s *standIn) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/oauth/v1/token" {
+		s.token(w, r)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !strings.HasPrefix(token, "access-") || s.revoked[token] {
+		writeError(w, http.StatusUnauthorized, "The access token provided is invalid.")
+		return
+	}
+
+	switch path := strings.TrimPrefix(r.URL.Path, "/api/rest/v1/"); {
+	case path == "products":
+		s.queries = append(s.queries, r.URL.Query())
+		s.listProducts(w, r)
+	case strings.HasPrefix(path, "products/"):
+		for _, p := range s.products {
+			if p["identifier"] == strings.TrimPrefix(path, "products/") {
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Resource not found.")
+	case path == "product-models":
+		s.listModels(w, r)
+	case strings.HasPrefix(path, "product-models/"):
+		for _, m := range s.models {
+			if m["code"] == strings.TrimPrefix(path, "product-models/") {
+				json.NewEncoder(w).Encode(m)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Resource not found.")
+	case path == "families":
+		json.NewEncoder(w).Encode(map[string]any{"_links": map[string]any{}, "_embedded": map[string]any{"items": []any{
+			map[string]any{"code": "drills"}, map[string]any{"code": "saws"},
+		}}})
+	case path == "families/drills/variants":
+		json.NewEncoder(w).Encode(map[string]any{"_links": map[string]any{}, "_embedded": map[string]any{"items": []any{
+			map[string]any{"code": "drills_color_voltage", "labels": map[string]string{"de_CH": "Farbe und Spannung"}, "variant_attribute_sets": []any{
+				map[string]any{"level": 2, "axes": []string{"voltage"}, "attributes": []string{"sku", "voltage", "weight"}},
+				map[string]any{"level": 1, "axes": []string{"color"}, "attributes": []string{"color"}},
+			}},
+		}}})
+	case path == "families/saws/variants":
+		json.NewEncoder(w).Encode(map[string]any{"_links": map[string]any{}, "_embedded": map[string]any{"items": []any{}}})
+	case path == "categories":
+		s.listCategories(w, r)
+	case path == "attributes":
+		json.NewEncoder(w).Encode(map[string]any{"_links": map[string]any{}, "_embedded": map[string]any{"items": []any{
+			map[string]any{"code": "sku", "type": "pim_catalog_identifier", "group": "general"},
+			map[string]any{"code": "name", "type": "pim_catalog_text", "group": "general", "localizable": true, "labels": map[string]string{"de_CH": "Name"}},
+			map[string]any{"code": "weight", "type": "pim_catalog_metric", "group": "technical"},
+			map[string]any{"code": "image", "type": "pim_catalog_image", "group": "media"},
+		}}})
+	case path == "media-files/"+mediaCode+"/download":
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("\xff\xd8\xff\xe0 drill"))
+	default:
+		writeError(w, http.StatusNotFound, "Resource not found.")
+	}
+}
+
+func (s *standIn) token(w http.ResponseWriter, r *http.Request) {
+	var grant map[string]string
+	json.NewDecoder(r.Body).Decode(&grant)
+	if id, secret, ok := r.BasicAuth(); !ok || id != "1_gondolia" || secret != "secret" {
+		writeError(w, http.StatusUnprocessableEntity, "Parameter \"client_id\" is missing or does not match any client")
+		return
+	}
+	switch grant["grant_type"] {
+	case "password":
+		if grant["username"] != "gondolia_api" || grant["password"] != "password" {
+			writeError(w, http.StatusUnprocessableEntity, "No user found for the given username and password.")
+			return
+		}
+	case "refresh_token":
+		if !strings.HasPrefix(grant["refresh_token"], "refresh-") || s.revoked[grant["refresh_token"]] {
+			writeError(w, http.StatusUnprocessableEntity, "Refresh token is invalid or has expired.")
+			return
+		}
+	default:
+		writeError(w, http.StatusUnprocessableEntity, "Unsupported grant type")
+		return
+	}
+	s.grants = append(s.grants, grant["grant_type"])
+	s.issued++
+	n := strconv.Itoa(s.issued)
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-" + n, "refresh_token": "refresh-" + n, "expires_in": 3600, "token_type": "bearer",
+	})
+}
+
+// listProducts applies the search filters and search_after pagination by identifier.
+func (s *standIn) listProducts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("pagination_type") != "search_after" {
+		writeError(w, http.StatusBadRequest, "Pagination type is not supported.")
+		return
+	}
+	var search map[string][]struct {
+		Operator string `json:"operator"`
+		Value    any    `json:"value"`
+	}
+	if raw := q.Get("search"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &search); err != nil {
+			writeError(w, http.StatusBadRequest, "Search query parameter should be valid JSON.")
+			return
+		}
+	}
+
+	var matched []map[string]any
+	for _, p := range s.products {
+		ok := p["identifier"].(string) > q.Get("search_after")
+		for field, conditions := range search {
+			for _, c := range conditions {
+				switch field {
+				case "updated":
+					since, err := time.Parse(time.DateTime, c.Value.(string))
+					if err != nil {
+						writeError(w, http.StatusUnprocessableEntity, "Property \"updated\" expects a datetime")
+						return
+					}
+					updated, _ := time.Parse(time.RFC3339, p["updated"].(string))
+					ok = ok && updated.After(since)
+				case "family":
+					ok = ok && slices.Contains(toStrings(c.Value), p["family"].(string))
+				case "categories":
+					ok = ok && slices.ContainsFunc(toStrings(c.Value), func(code string) bool {
+						return slices.Contains(p["categories"].([]string), code)
+					})
+				}
+			}
+		}
+		if ok {
+			matched = append(matched, p)
+		}
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	links := map[string]any{}
+	if len(matched) > limit {
+		matched = matched[:limit]
+		next := r.URL.Query()
+		next.Set("search_after", matched[limit-1]["identifier"].(string))
+		links["next"] = map[string]any{"href": s.URL + r.URL.Path + "?" + next.Encode()}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"_links": links, "_embedded": map[string]any{"items": matched}})
+}
+
+// listModels serves the product models with search_after pagination by code.
+func (s *standIn) listModels(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var matched []map[string]any
+	for _, m := range s.models {
+		if m["code"].(string) > q.Get("search_after") {
+			matched = append(matched, m)
+		}
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	links := map[string]any{}
+	if len(matched) > limit {
+		matched = matched[:limit]
+		next := r.URL.Query()
+		next.Set("search_after", matched[limit-1]["code"].(string))
+		links["next"] = map[string]any{"href": s.URL + r.URL.Path + "?" + next.Encode()}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"_links": links, "_embedded": map[string]any{"items": matched}})
+}
+
+// listCategories serves the categories in pages of two, following page links.
+func (s *standIn) listCategories(w http.ResponseWriter, r *http.Request) {
+	categories := []any{
+		map[string]any{"code": "master", "parent": nil, "labels": map[string]string{"de_CH": "Katalog"}},
+		map[string]any{"code": "tools", "parent": "master", "labels": map[string]string{"de_CH": "Werkzeuge"}},
+		map[string]any{"code": "drills", "parent": "tools"},
+		map[string]any{"code": "saws", "parent": "tools"},
+		map[string]any{"code": "accessories", "parent": "master"},
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+	start, end := (page-1)*2, min(page*2, len(categories))
+	links := map[string]any{}
+	if end < len(categories) {
+		base := s.URL
+		if s.linkBase != "" {
+			base = s.linkBase
+		}
+		links["next"] = map[string]any{"href": base + r.URL.Path + "?page=" + strconv.Itoa(page+1) + "&limit=2"}
+	}
+	json.NewEncoder(w).Encode(map[string]any{"_links": links, "_embedded": map[string]any{"items": categories[start:end]}})
+}
+
+func toStrings(v any) []string {
+	var result []string
+	for _, s := range v.([]any) {
+		result = append(result, s.(string))
+	}
+	return result
+}
+
+func newTestProvider(t *testing.T, s *standIn) *Provider {
+	t.Helper()
+	p, err := NewProvider(s.config())
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return p.(*Provider)
+}
+
+func TestConformance(t *testing.T) {
+	s := newStandIn(t)
+	pimtest.Run(t, func(t *testing.T) pim.PIMProvider {
+		return newTestProvider(t, s)
+	}, pimtest.Options{AssetCode: mediaCode})
+}
+
+func TestFetchProducts_MapsValuesAndFilters(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+
+	since := time.Date(2024, 2, 3, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	page, err := p.FetchProducts(ctx, pim.ProductFilter{UpdatedSince: &since, Families: []string{"drills"}})
+	if err != nil {
+		t.Fatalf("FetchProducts() error = %v", err)
+	}
+	if len(page.Products) != 1 || page.Products[0].Identifier != "SKU-5" || page.NextCursor != "" {
+		t.Fatalf("FetchProducts() = %+v, want SKU-5 only", page)
+	}
+
+	s.mu.Lock()
+	query := s.queries[len(s.queries)-1]
+	s.mu.Unlock()
+	if want := `{"family":[{"operator":"IN","value":["drills"]}],"updated":[{"operator":">","value":"2024-02-03 11:00:00"}]}`; query.Get("search") != want {
+		t.Errorf("search = %s, want %s", query.Get("search"), want)
+	}
+	if query.Get("scope") != "ecommerce" {
+		t.Errorf("scope = %q, want the configured channel", query.Get("scope"))
+	}
+
+	product := page.Products[0]
+	if !product.Updated.Equal(time.Date(2024, 2, 5, 10, 0, 0, 0, time.UTC)) || product.Created.IsZero() {
+		t.Errorf("Created, Updated = %v, %v", product.Created, product.Updated)
+	}
+	if name := product.Values["name"][0]; name.Locale != "de_CH" || name.Scope != "" || name.Data != "Produkt 5" {
+		t.Errorf("name = %+v", name)
+	}
+	if stock := product.Values["stock"][0]; stock.Scope != "ecommerce" || stock.Data != int64(40) {
+		t.Errorf("stock = %+v, want 40 in ecommerce", stock)
+	}
+	if weight := product.Values["weight"][0].Data.(map[string]any); weight["amount"] != "1.5000" {
+		t.Errorf("weight = %v", weight)
+	}
+}
+
+func TestFetchCategoriesAndAttributes(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+
+	categories, err := p.FetchCategories(ctx)
+	if err != nil {
+		t.Fatalf("FetchCategories() error = %v", err)
+	}
+	if len(categories) != 5 || categories[0].Parent != "" || categories[1].Parent != "master" || categories[1].Labels["de_CH"] != "Werkzeuge" {
+		t.Errorf("FetchCategories() = %+v, want all pages with parents", categories)
+	}
+
+	attributes, err := p.FetchAttributes(ctx)
+	if err != nil {
+		t.Fatalf("FetchAttributes() error = %v", err)
+	}
+	var types []string
+	for _, a := range attributes {
+		types = append(types, a.Type)
+	}
+	if !slices.Equal(types, []string{"identifier", "text", "metric", "media"}) || !attributes[1].Localizable {
+		t.Errorf("FetchAttributes() = %+v", attributes)
+	}
+}
+
+func TestFetchProductModelsAndFamilyVariants(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+
+	page, err := p.FetchProductModels(ctx, pim.ProductFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("FetchProductModels() error = %v", err)
+	}
+	if len(page.Models) != 1 || page.Models[0].Code != "drill" || page.Models[0].Parent != "" || page.NextCursor == "" {
+		t.Fatalf("FetchProductModels() = %+v, want the root model and a cursor", page)
+	}
+	root := page.Models[0]
+	if root.FamilyVariant != "drills_color_voltage" || root.Values["name"][0].Data != "Bohrer" || root.Updated.IsZero() {
+		t.Errorf("root model = %+v", root)
+	}
+	page, err = p.FetchProductModels(ctx, pim.ProductFilter{Limit: 1, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("FetchProductModels() error = %v", err)
+	}
+	if len(page.Models) != 1 || page.Models[0].Code != "drill_red" || page.Models[0].Parent != "drill" || page.NextCursor != "" {
+		t.Fatalf("FetchProductModels() second page = %+v, want the sub-model", page)
+	}
+
+	model, err := p.FetchProductModel(ctx, "drill_red")
+	if err != nil || model.Values["color"][0].Data != "red" {
+		t.Errorf("FetchProductModel() = %+v, %v", model, err)
+	}
+	product, err := p.FetchProduct(ctx, "SKU-1")
+	if err != nil || product.Parent != "drill_red" {
+		t.Errorf("FetchProduct() = %+v, %v, want parent drill_red", product, err)
+	}
+
+	variants, err := p.FetchFamilyVariants(ctx)
+	if err != nil {
+		t.Fatalf("FetchFamilyVariants() error = %v", err)
+	}
+	if len(variants) != 1 || variants[0].Family != "drills" || len(variants[0].Levels) != 2 {
+		t.Fatalf("FetchFamilyVariants() = %+v, want one variant with two levels", variants)
+	}
+	if levels := variants[0].Levels; !slices.Equal(levels[0].Axes, []string{"color"}) || !slices.Equal(levels[1].Axes, []string{"voltage"}) {
+		t.Errorf("Levels = %+v, want color before voltage", levels)
+	}
+}
+
+func TestAccessToken_IsRefreshed(t *testing.T) {
+	s := newStandIn(t)
+	p := newTestProvider(t, s)
+	ctx := context.Background()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	// An expired token is renewed with the refresh token
+	p.client.expireToken()
+	if _, err := p.FetchProduct(ctx, "SKU-1"); err != nil {
+		t.Fatalf("FetchProduct() error = %v", err)
+	}
+
+	// A revoked token is refreshed on the 401; a revoked refresh token
+	// falls back to the password grant
+	s.mu.Lock()
+	s.revoked["access-2"], s.revoked["refresh-2"] = true, true
+	s.mu.Unlock()
+	if _, err := p.FetchProduct(ctx, "SKU-1"); err != nil {
+		t.Fatalf("FetchProduct() error = %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if want := []string{"password", "refresh_token", "password"}; !slices.Equal(s.grants, want) {
+		t.Errorf("grants = %v, want %v", s.grants, want)
+	}
+}
+
+func TestFetchCategories_RejectsForeignLinks(t *testing.T) {
+	var foreignRequests atomic.Int32
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests.Add(1)
+		w.Write([]byte(`{"_links":{},"_embedded":{"items":[]}}`))
+	}))
+	defer foreign.Close()
+
+	s := newStandIn(t)
+	s.linkBase = foreign.URL
+	p := newTestProvider(t, s)
+
+	if _, err := p.FetchCategories(context.Background()); err == nil {
+		t.Fatal("FetchCategories() error = nil, want an error for a link to another host")
+	}
+	if n := foreignRequests.Load(); n != 0 {
+		t.Errorf("foreign host received %d requests, want none", n)
+	}
+}
+
+func TestNew_RequiresCredentials(t *testing.T) {
+	if _, err := NewProvider(map[string]any{"base_url": "https://pim.example.com"}); err == nil {
+		t.Error("NewProvider() without credentials succeeded")
+	}
+}
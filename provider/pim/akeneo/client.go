@@ -0,0 +1,267 @@
+package akeneo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+)
+
+// Error is an error response of the Akeneo API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("akeneo: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("akeneo: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap classifies the error: missing resources are provider.ErrNotFound
+// and rejected requests provider.ErrInvalidArgument. Other errors, such as
+// server errors and rate limits, may be retried.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return provider.ErrNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return provider.ErrInvalidArgument
+	}
+	return nil
+}
+
+func parseError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		e.Message = body.Message
+	} else if text := strings.TrimSpace(string(data)); text != "" && len(text) < 500 {
+		e.Message = text
+	}
+	return e
+}
+
+// client sends requests to the Akeneo REST API, authenticating with the
+// OAuth2 password grant and refreshing the access token before it expires.
+type client struct {
+	baseURL string
+	http    *http.Client
+	cfg     Config
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	tokenExpiry  time.Time
+}
+
+func newClient(cfg Config) *client {
+	return &client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		http:    &http.Client{Timeout: cfg.Timeout},
+		cfg:     cfg,
+	}
+}
+
+// tokenResponse is the response of the Akeneo token endpoint.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// accessToken returns a cached access token. An expiring token is renewed
+// with the refresh token, falling back to the password grant if the refresh
+// token is rejected.
+func (c *client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	if c.refreshToken != "" {
+		err := c.requestToken(ctx, map[string]string{"grant_type": "refresh_token", "refresh_token": c.refreshToken})
+		if err == nil {
+			return c.token, nil
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+		c.refreshToken = ""
+	}
+
+	err := c.requestToken(ctx, map[string]string{
+		"grant_type": "password",
+		"username":   c.cfg.Username,
+		"password":   c.cfg.Password,
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.token, nil
+}
+
+// requestToken requests a token with the grant; the caller holds c.mu.
+func (c *client) requestToken(ctx context.Context, grant map[string]string) error {
+	payload, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/oauth/v1/token", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.cfg.ClientID, c.cfg.ClientSecret)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("akeneo: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("akeneo: token request (%s): %w", grant["grant_type"], parseError(resp))
+	}
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return fmt.Errorf("akeneo: token request: invalid response")
+	}
+
+	expiresIn := time.Duration(token.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	c.token = token.AccessToken
+	c.refreshToken = token.RefreshToken
+	// Renew shortly before the token expires
+	c.tokenExpiry = time.Now().Add(expiresIn - min(expiresIn/10, time.Minute))
+	return nil
+}
+
+// expireToken forces a refresh before the next request.
+func (c *client) expireToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenExpiry = time.Time{}
+}
+
+// get sends a GET request to target, a path below the base URL or an
+// absolute URL of a _links entry. Absolute URLs must point to the scheme and
+// host of the base URL so the access token is never sent elsewhere. A
+// rejected token is refreshed and the request repeated once. The caller
+// closes the response body.
+func (c *client) get(ctx context.Context, target string, query url.Values) (*http.Response, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		if err := c.checkLink(target); err != nil {
+			return nil, err
+		}
+	} else {
+		target = c.baseURL + target
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("akeneo: GET %s: %w", req.URL.Path, err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			c.expireToken()
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			defer resp.Body.Close()
+			return nil, parseError(resp)
+		}
+		return resp, nil
+	}
+}
+
+// checkLink rejects a link that does not point to the scheme and host of the
+// base URL.
+func (c *client) checkLink(link string) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("akeneo: invalid link: %w", err)
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("akeneo: invalid base URL: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+		return fmt.Errorf("akeneo: link %s://%s%s is outside %s", u.Scheme, u.Host, u.Path, c.baseURL)
+	}
+	return nil
+}
+
+// getJSON sends a GET request and decodes the JSON response into out.
+func (c *client) getJSON(ctx context.Context, target string, query url.Values, out any) error {
+	resp, err := c.get(ctx, target, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("akeneo: decode response: %w", err)
+	}
+	return nil
+}
+
+// page is a page of a paginated Akeneo list.
+type page[T any] struct {
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+	Embedded struct {
+		Items []T `json:"items"`
+	} `json:"_embedded"`
+}
+
+// listAll reads all pages of a list, following the next links.
+func listAll[T any](ctx context.Context, c *client, path string) ([]T, error) {
+	var items []T
+	target, query := path, url.Values{"limit": {"100"}}
+	for target != "" {
+		var p page[T]
+		if err := c.getJSON(ctx, target, query, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p.Embedded.Items...)
+		target, query = p.Links.Next.Href, nil
+	}
+	return items, nil
+}
@@ -0,0 +1,456 @@
+// Package feed provides a PIM provider for suppliers that deliver product
+// data as flat files instead of running a PIM.
+//
+// Products, categories and attributes are read from CSV or NDJSON files in a
+// local directory or below a prefix of a storage provider:
+//
+//	pim:
+//	  name: feed
+//	  config:
+//	    storage: {name: s3, config: {bucket: supplier-feeds}}
+//	    prefix: acme/
+//	    products: products.csv
+//	    columns: {product.identifier: Artikelnummer, product.updated: Geaendert, product.name-de_CH: Bezeichnung}
+//
+// CSV product feeds have the columns identifier, family, categories, enabled,
+// created and updated; all other columns are attribute values named
+// <code>[-<locale>][-<scope>] as in Akeneo exports, e.g. name-de_CH. Category
+// feeds have the columns code, parent and label-<locale>, attribute feeds
+// code, type, group, localizable, scopable and label-<locale>. The columns
+// config maps <feed>.<field> to the column header of a feed that names it
+// differently. NDJSON feeds hold one object per line in the format of the
+// Akeneo REST API.
+//
+// Rows without an updated timestamp count as updated when the provider first
+// reads the file version that contains them. Files are read again when they
+// change; missing category and attribute files are empty. DownloadAsset reads
+// the files below the assets directory. Feeds have no product models, so all
+// products are standalone.
+package feed
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+func init() {
+	provider.RegisterTyped[pim.PIMProvider, Config]("pim", "feed",
+		provider.Metadata{
+			Name:        "feed",
+			DisplayName: "CSV/NDJSON Feed",
+			Category:    "pim",
+			Version:     "1.0.0",
+			Description: "Products, categories and attributes from CSV or NDJSON files in a directory or object storage",
+			ConfigSpec:  configSpec,
+		},
+		New,
+	)
+}
+
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{Key: "path", Type: "string", Description: "Local directory of the feed files"},
+	{Key: "storage", Type: "map", Description: "Storage provider of the feed files as {name, config}, instead of path"},
+	{Key: "prefix", Type: "string", Description: "Path prefix of the feed files in the storage, e.g. acme/"},
+	{Key: "products", Type: "string", Default: "products.csv", Description: "Product feed file"},
+	{Key: "categories", Type: "string", Default: "categories.csv", Description: "Category feed file"},
+	{Key: "attributes", Type: "string", Default: "attributes.csv", Description: "Attribute feed file"},
+	{Key: "assets", Type: "string", Default: "assets", Description: "Directory of the asset files"},
+	{Key: "format", Type: "string", Description: "csv or ndjson; by default from the file extension (.ndjson, .jsonl)"},
+	{Key: "delimiter", Type: "string", Default: ",", Description: "CSV field delimiter"},
+	{Key: "list_separator", Type: "string", Default: ",", Description: "Separator of multiple values in a CSV cell, e.g. categories"},
+	{Key: "columns", Type: "map", Description: "CSV column headers by <feed>.<field>, e.g. product.identifier: Artikelnummer"},
+}
+
+// Config holds the feed provider configuration.
+type Config struct {
+	Path          string            `config:"path"`
+	Storage       map[string]any    `config:"storage"`
+	Prefix        string            `config:"prefix"`
+	Products      string            `config:"products"`
+	Categories    string            `config:"categories"`
+	Attributes    string            `config:"attributes"`
+	Assets        string            `config:"assets"`
+	Format        string            `config:"format"`
+	Delimiter     string            `config:"delimiter"`
+	ListSeparator string            `config:"list_separator"`
+	Columns       map[string]string `config:"columns"`
+}
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+const (
+	defaultPageSize = 100  // Page size of product filters without a limit
+	maxPageSize     = 1000 // Largest page returned
+)
+
+// cursorPrefix marks cursors issued by this provider.
+const cursorPrefix = "feed:"
+
+// Provider is a PIM provider reading feed files.
+type Provider struct {
+	cfg     Config
+	source  source
+	storage storage.StorageProvider // Storage built from the config, started and closed with the provider
+	now     func() time.Time
+
+	mu    sync.Mutex
+	files map[string]*loadedFile
+}
+
+// loadedFile is a parsed feed file and the version it was parsed from.
+type loadedFile struct {
+	version string
+	value   any
+}
+
+// NewProvider creates a new feed provider from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
+func NewProvider(config map[string]any) (pim.PIMProvider, error) {
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	var cfg Config
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	return New(cfg)
+}
+
+// New creates a new feed provider from a typed configuration. The storage
+// provider is built through the registry.
+func New(cfg Config) (pim.PIMProvider, error) {
+	if (cfg.Path == "") == (cfg.Storage == nil) {
+		return nil, fmt.Errorf("feed: exactly one of path and storage is required")
+	}
+	if cfg.Path != "" {
+		return newProvider(cfg, dirSource{dir: cfg.Path}, nil)
+	}
+
+	name, _ := cfg.Storage["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("feed: storage name is required")
+	}
+	factory, err := provider.Get[storage.StorageProvider]("storage", name)
+	if err != nil {
+		return nil, fmt.Errorf("feed: %w", err)
+	}
+	storageConfig, _ := cfg.Storage["config"].(map[string]any)
+	store, err := factory(storageConfig)
+	if err != nil {
+		return nil, fmt.Errorf("feed: storage %s: %w", name, err)
+	}
+	return newProvider(cfg, storageSource{storage: store, prefix: cfg.Prefix}, store)
+}
+
+// NewWithStorage creates a feed provider that reads the files below
+// cfg.Prefix of an existing storage provider; cfg.Path and cfg.Storage are ignored.
+func NewWithStorage(store storage.StorageProvider, cfg Config) (pim.PIMProvider, error) {
+	return newProvider(cfg, storageSource{storage: store, prefix: cfg.Prefix}, nil)
+}
+
+func newProvider(cfg Config, src source, owned storage.StorageProvider) (pim.PIMProvider, error) {
+	if cfg.Products == "" {
+		cfg.Products = "products.csv"
+	}
+	if cfg.Categories == "" {
+		cfg.Categories = "categories.csv"
+	}
+	if cfg.Attributes == "" {
+		cfg.Attributes = "attributes.csv"
+	}
+	if cfg.Assets == "" {
+		cfg.Assets = "assets"
+	}
+	if cfg.Delimiter == "" {
+		cfg.Delimiter = ","
+	}
+	if cfg.ListSeparator == "" {
+		cfg.ListSeparator = ","
+	}
+	if utf8.RuneCountInString(cfg.Delimiter) != 1 {
+		return nil, fmt.Errorf("feed: delimiter must be a single character")
+	}
+	if cfg.Format != "" && cfg.Format != formatCSV && cfg.Format != formatNDJSON {
+		return nil, fmt.Errorf("feed: unknown format %q", cfg.Format)
+	}
+	for key := range cfg.Columns {
+		feed, field, ok := strings.Cut(key, ".")
+		if !ok || field == "" || (feed != "product" && feed != "category" && feed != "attribute") {
+			return nil, fmt.Errorf("feed: invalid column mapping %q, want <product|category|attribute>.<field>", key)
+		}
+	}
+	return &Provider{cfg: cfg, source: src, storage: owned, now: time.Now, files: make(map[string]*loadedFile)}, nil
+}
+
+// Start starts the storage provider built from the config.
+func (p *Provider) Start(ctx context.Context) error {
+	if p.storage == nil {
+		return nil
+	}
+	return provider.Start(ctx, p.storage)
+}
+
+// Close closes the storage provider built from the config.
+func (p *Provider) Close(ctx context.Context) error {
+	if p.storage == nil {
+		return nil
+	}
+	return provider.Close(ctx, p.storage)
+}
+
+// format returns the format of a feed file.
+func (p *Provider) format(name string) string {
+	if p.cfg.Format != "" {
+		return p.cfg.Format
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	}
+	return formatCSV
+}
+
+func (p *Provider) parser() *parser {
+	columns := make(map[string]map[string]string)
+	for key, header := range p.cfg.Columns {
+		feed, field, _ := strings.Cut(key, ".")
+		if columns[feed] == nil {
+			columns[feed] = make(map[string]string)
+		}
+		columns[feed][header] = field
+	}
+	delimiter, _ := utf8.DecodeRuneInString(p.cfg.Delimiter)
+	return &parser{delimiter: delimiter, listSeparator: p.cfg.ListSeparator, columns: columns, loaded: p.now()}
+}
+
+// load returns the parsed content of a feed file, parsing it again if the
+// file changed since it was last read. Loads are serialized so that a
+// changed file is parsed once.
+func load[T any](ctx context.Context, p *Provider, name string, parse func(*parser, io.Reader, string) (T, error)) (T, error) {
+	var zero T
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	version, err := p.source.version(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	if f := p.files[name]; f != nil && f.version == version {
+		return f.value.(T), nil
+	}
+
+	rc, _, err := p.source.open(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	defer rc.Close()
+	value, err := parse(p.parser(), rc, p.format(name))
+	if err != nil {
+		return zero, fmt.Errorf("feed: %s: %w", name, err)
+	}
+	p.files[name] = &loadedFile{version: version, value: value}
+	return value, nil
+}
+
+// FetchProducts returns a page of products ordered by identifier. The cursor
+// holds the identifier of the last product of the previous page, so pages
+// stay consistent when the file changes between them.
+func (p *Provider) FetchProducts(ctx context.Context, filter pim.ProductFilter) (*pim.ProductPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 {
+		return nil, fmt.Errorf("feed: limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	var after string
+	if filter.Cursor != "" {
+		var err error
+		if after, err = decodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	products, err := load(ctx, p, p.cfg.Products, (*parser).products)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []pim.Product
+	for _, product := range products {
+		if filter.UpdatedSince != nil && !product.Updated.After(*filter.UpdatedSince) {
+			continue
+		}
+		if len(filter.Families) > 0 && !slices.Contains(filter.Families, product.Family) {
+			continue
+		}
+		if len(filter.Categories) > 0 && !slices.ContainsFunc(filter.Categories, func(code string) bool {
+			return slices.Contains(product.Categories, code)
+		}) {
+			continue
+		}
+		matched = append(matched, product)
+	}
+
+	start := 0
+	if after != "" {
+		start = sort.Search(len(matched), func(i int) bool { return matched[i].Identifier > after })
+	}
+	end := min(start+limit, len(matched))
+
+	page := &pim.ProductPage{Products: matched[start:end], TotalCount: len(matched)}
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(matched[end-1].Identifier)
+	}
+	return page, nil
+}
+
+// FetchProduct returns a single product by identifier.
+func (p *Provider) FetchProduct(ctx context.Context, identifier string) (*pim.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if identifier == "" {
+		return nil, fmt.Errorf("feed: identifier is required: %w", provider.ErrInvalidArgument)
+	}
+
+	products, err := load(ctx, p, p.cfg.Products, (*parser).products)
+	if err != nil {
+		return nil, err
+	}
+	i := sort.Search(len(products), func(i int) bool { return products[i].Identifier >= identifier })
+	if i == len(products) || products[i].Identifier != identifier {
+		return nil, fmt.Errorf("feed: product %s: %w", identifier, provider.ErrNotFound)
+	}
+	product := products[i]
+	return &product, nil
+}
+
+// FetchProductModels returns no models; feeds only hold products.
+func (p *Provider) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (*pim.ProductModelPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 {
+		return nil, fmt.Errorf("feed: limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	if filter.Cursor != "" {
+		if _, err := decodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return &pim.ProductModelPage{Models: []pim.ProductModel{}}, nil
+}
+
+// FetchProductModel reports every model as not found.
+func (p *Provider) FetchProductModel(ctx context.Context, code string) (*pim.ProductModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("feed: code is required: %w", provider.ErrInvalidArgument)
+	}
+	return nil, fmt.Errorf("feed: product model %s: %w", code, provider.ErrNotFound)
+}
+
+// FetchFamilyVariants returns no family variants.
+func (p *Provider) FetchFamilyVariants(ctx context.Context) ([]pim.FamilyVariant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []pim.FamilyVariant{}, nil
+}
+
+// FetchCategories returns the categories of the category feed.
+func (p *Provider) FetchCategories(ctx context.Context) ([]pim.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	categories, err := load(ctx, p, p.cfg.Categories, (*parser).categories)
+	if err != nil && !errors.Is(err, provider.ErrNotFound) {
+		return nil, err
+	}
+	return slices.Clone(categories), nil
+}
+
+// FetchAttributes returns the attributes of the attribute feed.
+func (p *Provider) FetchAttributes(ctx context.Context) ([]pim.Attribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	attributes, err := load(ctx, p, p.cfg.Attributes, (*parser).attributes)
+	if err != nil && !errors.Is(err, provider.ErrNotFound) {
+		return nil, err
+	}
+	return slices.Clone(attributes), nil
+}
+
+// DownloadAsset reads a file of the assets directory by its relative path.
+func (p *Provider) DownloadAsset(ctx context.Context, assetCode string) (io.ReadCloser, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	if assetCode == "" {
+		return nil, "", fmt.Errorf("feed: asset code is required: %w", provider.ErrInvalidArgument)
+	}
+	name := path.Clean(path.Join(p.cfg.Assets, assetCode))
+	if !strings.HasPrefix(name, path.Clean(p.cfg.Assets)+"/") {
+		return nil, "", fmt.Errorf("feed: asset code %q is outside the assets directory: %w", assetCode, provider.ErrInvalidArgument)
+	}
+
+	rc, contentType, err := p.source.open(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return rc, contentType, nil
+}
+
+func (p *Provider) Metadata() pim.Metadata {
+	return pim.Metadata{
+		Name:    "feed",
+		Version: "1.0.0",
+	}
+}
+
+func encodeCursor(identifier string) string {
+	return cursorPrefix + base64.RawURLEncoding.EncodeToString([]byte(identifier))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	encoded, ok := strings.CutPrefix(cursor, cursorPrefix)
+	if !ok {
+		return "", fmt.Errorf("feed: unknown cursor: %w", provider.ErrInvalidArgument)
+	}
+	identifier, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(identifier) == 0 {
+		return "", fmt.Errorf("feed: invalid cursor: %w", provider.ErrInvalidArgument)
+	}
+	return string(identifier), nil
+}
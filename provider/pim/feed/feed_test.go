@@ -0,0 +1,222 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/pim/pimtest"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+const productsCSV = `identifier,family,categories,enabled,updated,name-de_CH,name-fr_CH,description-de_CH-ecommerce
+SKU-1,drills,"tools,drills",1,2024-02-01T10:00:00Z,Bohrer,Perceuse,
+SKU-2,drills,"tools,drills",1,2024-02-02 10:00:00,Akkubohrer,,Mit Akku
+SKU-3,saws,"tools,saws",0,2024-02-03,Säge,Scie,
+`
+
+const productsNDJSON = `{"identifier":"SKU-1","family":"drills","categories":["tools"],"values":{"weight":[{"locale":null,"scope":null,"data":{"amount":"1.5","unit":"KILOGRAM"}}]},"updated":"2024-02-01T10:00:00Z"}
+
+{"identifier":"SKU-2","family":"drills","categories":["tools"],"values":{"stock":[{"locale":null,"scope":"ecommerce","data":12}]},"updated":"2024-02-02T10:00:00Z"}
+{"identifier":"SKU-3","family":"saws","categories":["saws"],"enabled":false,"updated":"2024-02-03T10:00:00Z"}
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestProvider(t *testing.T, config map[string]any) *Provider {
+	t.Helper()
+	p, err := NewProvider(config)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return p.(*Provider)
+}
+
+// memStorage keeps files in memory.
+type memStorage struct {
+	storage.StorageProvider
+	files map[string]string
+}
+
+func (s *memStorage) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return nil, nil, provider.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), &storage.FileInfo{Path: path, Size: int64(len(content))}, nil
+}
+
+func (s *memStorage) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	var files []storage.FileInfo
+	for path, content := range s.files {
+		if strings.HasPrefix(path, prefix) {
+			files = append(files, storage.FileInfo{Path: path, Size: int64(len(content)), ETag: `"` + content + `"`})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files[:min(len(files), opts.MaxKeys)], nil
+}
+
+func TestConformance(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"products.csv": productsCSV, "assets/sku-1/front.png": "\x89PNG drill"})
+	pimtest.Run(t, func(t *testing.T) pim.PIMProvider {
+		return newTestProvider(t, map[string]any{"path": dir})
+	}, pimtest.Options{AssetCode: "sku-1/front.png"})
+}
+
+func TestConformance_NDJSONInStorage(t *testing.T) {
+	store := &memStorage{files: map[string]string{
+		"acme/products.ndjson": productsNDJSON,
+		"acme/assets/manual":   "%PDF manual",
+	}}
+	pimtest.Run(t, func(t *testing.T) pim.PIMProvider {
+		p, err := NewWithStorage(store, Config{Prefix: "acme/", Products: "products.ndjson"})
+		if err != nil {
+			t.Fatalf("NewWithStorage() error = %v", err)
+		}
+		return p
+	}, pimtest.Options{AssetCode: "manual"})
+}
+
+func TestFetchProducts_MapsColumnsAndValues(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"artikel.csv":    "Artikelnummer;Warengruppe;Bezeichnung;farbe\nA-1;Bohrer|Werkzeug;Bohrer;rot\nA-2;;Säge;\n",
+		"categories.csv": "code;parent;label-de_CH;label-fr_CH\nwerkzeug;;Werkzeug;Outils\nbohrer;werkzeug;Bohrer;\n",
+		"attributes.csv": "code;type;group;localizable;scopable;label-de_CH\nname;pim_catalog_text;general;true;false;Name\nfarbe;select;general;;;Farbe\n",
+	})
+	p := newTestProvider(t, map[string]any{
+		"path":           dir,
+		"products":       "artikel.csv",
+		"delimiter":      ";",
+		"list_separator": "|",
+		"columns": map[string]any{
+			"product.identifier": "Artikelnummer",
+			"product.categories": "Warengruppe",
+			"product.name-de_CH": "Bezeichnung",
+		},
+	})
+	loaded := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return loaded }
+	ctx := context.Background()
+
+	page, err := p.FetchProducts(ctx, pim.ProductFilter{})
+	if err != nil {
+		t.Fatalf("FetchProducts() error = %v", err)
+	}
+	if len(page.Products) != 2 || page.TotalCount != 2 {
+		t.Fatalf("FetchProducts() = %+v, want A-1 and A-2", page)
+	}
+	product := page.Products[0]
+	if product.Identifier != "A-1" || len(product.Categories) != 2 || product.Categories[0] != "Bohrer" || !product.Enabled {
+		t.Errorf("product = %+v", product)
+	}
+	if name := product.Values["name"]; len(name) != 1 || name[0].Locale != "de_CH" || name[0].Data != "Bohrer" {
+		t.Errorf("name = %+v, want Bohrer in de_CH", name)
+	}
+	if color := product.Values["farbe"]; len(color) != 1 || color[0].Locale != "" || color[0].Data != "rot" {
+		t.Errorf("farbe = %+v, want rot", color)
+	}
+	if _, ok := page.Products[1].Values["farbe"]; ok {
+		t.Error("empty cells became values")
+	}
+	if !product.Updated.Equal(loaded) {
+		t.Errorf("Updated = %v, want the load time %v for rows without a timestamp", product.Updated, loaded)
+	}
+
+	categories, err := p.FetchCategories(ctx)
+	if err != nil {
+		t.Fatalf("FetchCategories() error = %v", err)
+	}
+	if len(categories) != 2 || categories[1].Parent != "werkzeug" || categories[0].Labels["fr_CH"] != "Outils" {
+		t.Errorf("FetchCategories() = %+v", categories)
+	}
+
+	attributes, err := p.FetchAttributes(ctx)
+	if err != nil {
+		t.Fatalf("FetchAttributes() error = %v", err)
+	}
+	if len(attributes) != 2 || attributes[0].Type != "text" || !attributes[0].Localizable || attributes[1].Labels["de_CH"] != "Farbe" {
+		t.Errorf("FetchAttributes() = %+v", attributes)
+	}
+}
+
+func TestFetchProducts_ReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"products.csv": productsCSV})
+	p := newTestProvider(t, map[string]any{"path": dir})
+	ctx := context.Background()
+
+	since := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	page, err := p.FetchProducts(ctx, pim.ProductFilter{UpdatedSince: &since})
+	if err != nil {
+		t.Fatalf("FetchProducts() error = %v", err)
+	}
+	if len(page.Products) != 2 || page.Products[0].Identifier != "SKU-2" {
+		t.Errorf("UpdatedSince = %+v, want SKU-2 and SKU-3", page.Products)
+	}
+
+	// A changed row and a new row without a timestamp are picked up
+	changed := strings.Replace(productsCSV, "SKU-1,drills,\"tools,drills\",1,2024-02-01T10:00:00Z", "SKU-1,drills,\"tools,drills\",1,2024-03-01T10:00:00Z", 1) +
+		"SKU-4,saws,saws,1,,Stichsäge,,\n"
+	writeFiles(t, dir, map[string]string{"products.csv": changed})
+	since = time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	page, err = p.FetchProducts(ctx, pim.ProductFilter{UpdatedSince: &since})
+	if err != nil {
+		t.Fatalf("FetchProducts() error = %v", err)
+	}
+	var identifiers []string
+	for _, product := range page.Products {
+		identifiers = append(identifiers, product.Identifier)
+	}
+	if strings.Join(identifiers, ",") != "SKU-1,SKU-4" {
+		t.Errorf("UpdatedSince after change = %v, want SKU-1 and SKU-4", identifiers)
+	}
+}
+
+func TestFeed_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"products.csv": "identifier,updated\nSKU-1,yesterday\n"})
+	p := newTestProvider(t, map[string]any{"path": dir})
+	ctx := context.Background()
+
+	if _, err := p.FetchProducts(ctx, pim.ProductFilter{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("FetchProducts() error = %v, want the line of the invalid timestamp", err)
+	}
+	if categories, err := p.FetchCategories(ctx); err != nil || len(categories) != 0 {
+		t.Errorf("FetchCategories() = %v, %v, want none for a missing feed", categories, err)
+	}
+	if _, _, err := p.DownloadAsset(ctx, "../products.csv"); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("DownloadAsset() outside the assets directory error = %v, want ErrInvalidArgument", err)
+	}
+
+	for _, config := range []map[string]any{
+		{},
+		{"path": dir, "storage": map[string]any{"name": "noop"}},
+		{"path": dir, "columns": map[string]any{"identifier": "SKU"}},
+	} {
+		if _, err := NewProvider(config); err == nil {
+			t.Errorf("NewProvider(%v) succeeded", config)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/pim"
+)
+
+// Standard columns of the product feed; all other columns are attribute values.
+var productFields = map[string]bool{
+	"identifier": true,
+	"family":     true,
+	"categories": true,
+	"enabled":    true,
+	"created":    true,
+	"updated":    true,
+}
+
+// localePattern matches locale codes such as de_CH, which tell the locale of
+// a value column apart from its scope.
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}_[A-Z]{2}$`)
+
+// timeLayouts are the accepted formats of row timestamps.
+var timeLayouts = []string{time.RFC3339, time.DateTime, "2006-01-02T15:04:05", time.DateOnly}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// parser reads feed files in CSV or NDJSON.
+type parser struct {
+	delimiter     rune
+	listSeparator string
+	columns       map[string]map[string]string // feed -> column header -> field
+	loaded        time.Time                    // Timestamp of rows without one
+}
+
+// csvRows reads a CSV file as rows keyed by field, with the column mapping
+// of feed applied. Errors of fn are annotated with the line number.
+func (p *parser) csvRows(r io.Reader, feed string, fn func(row map[string]string) error) error {
+	reader := csv.NewReader(r)
+	reader.Comma = p.delimiter
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fields := make([]string, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		if field, ok := p.columns[feed][h]; ok {
+			h = field
+		}
+		fields[i] = h
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		row := make(map[string]string, len(fields))
+		for i, value := range record {
+			if i < len(fields) && fields[i] != "" {
+				row[fields[i]] = strings.TrimSpace(value)
+			}
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// ndjsonRows decodes each non-empty line of r into a new T.
+func ndjsonRows[T any](r io.Reader, fn func(row *T) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		row := new(T)
+		if err := dec.Decode(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// products parses a product feed. Products are sorted by identifier; of rows
+// with the same identifier, the last one wins.
+func (p *parser) products(r io.Reader, format string) ([]pim.Product, error) {
+	byIdentifier := make(map[string]pim.Product)
+	add := func(product pim.Product) error {
+		if product.Identifier == "" {
+			return errors.New("identifier is empty")
+		}
+		if product.Categories == nil {
+			product.Categories = []string{}
+		}
+		if product.Updated.IsZero() {
+			product.Updated = p.loaded
+		}
+		if product.Created.IsZero() {
+			product.Created = product.Updated
+		}
+		byIdentifier[product.Identifier] = product
+		return nil
+	}
+
+	var err error
+	if format == formatNDJSON {
+		err = ndjsonRows(r, func(row *productRow) error {
+			product, err := row.toPIM()
+			if err != nil {
+				return err
+			}
+			return add(product)
+		})
+	} else {
+		err = p.csvRows(r, "product", func(row map[string]string) error {
+			product, err := p.csvProduct(row)
+			if err != nil {
+				return err
+			}
+			return add(product)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]pim.Product, 0, len(byIdentifier))
+	for _, product := range byIdentifier {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Identifier < products[j].Identifier })
+	return products, nil
+}
+
+func (p *parser) csvProduct(row map[string]string) (pim.Product, error) {
+	product := pim.Product{
+		Identifier: row["identifier"],
+		Family:     row["family"],
+		Enabled:    true,
+		Values:     make(map[string][]pim.AttributeValue),
+	}
+	if categories := row["categories"]; categories != "" {
+		for _, code := range strings.Split(categories, p.listSeparator) {
+			if code = strings.TrimSpace(code); code != "" {
+				product.Categories = append(product.Categories, code)
+			}
+		}
+	}
+	if enabled := row["enabled"]; enabled != "" {
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return product, fmt.Errorf("invalid enabled %q", enabled)
+		}
+		product.Enabled = b
+	}
+	for _, field := range []string{"created", "updated"} {
+		if row[field] == "" {
+			continue
+		}
+		t, err := parseTimestamp(row[field])
+		if err != nil {
+			return product, fmt.Errorf("%s: %w", field, err)
+		}
+		if field == "created" {
+			product.Created = t
+		} else {
+			product.Updated = t
+		}
+	}
+
+	for column, data := range row {
+		if productFields[column] || data == "" {
+			continue
+		}
+		code, value := valueColumn(column)
+		value.Data = data
+		product.Values[code] = append(product.Values[code], value)
+	}
+	for _, values := range product.Values {
+		sort.Slice(values, func(i, j int) bool {
+			return values[i].Locale+"-"+values[i].Scope < values[j].Locale+"-"+values[j].Scope
+		})
+	}
+	return product, nil
+}
+
+// valueColumn splits a value column such as name-de_CH-ecommerce into the
+// attribute code, locale and scope, following the Akeneo export format.
+func valueColumn(column string) (string, pim.AttributeValue) {
+	parts := strings.Split(column, "-")
+	var value pim.AttributeValue
+	for _, part := range parts[1:] {
+		if value.Locale == "" && value.Scope == "" && localePattern.MatchString(part) {
+			value.Locale = part
+		} else {
+			value.Scope = part
+		}
+	}
+	return parts[0], value
+}
+
+// labels collects label-<locale> columns.
+func labels(row map[string]string) map[string]string {
+	result := make(map[string]string)
+	for column, value := range row {
+		if locale, ok := strings.CutPrefix(column, "label-"); ok && value != "" {
+			result[locale] = value
+		}
+	}
+	return result
+}
+
+// productRow is a product line of an NDJSON feed, in the format of the Akeneo API.
+type productRow struct {
+	Identifier string   `json:"identifier"`
+	Family     string   `json:"family"`
+	Categories []string `json:"categories"`
+	Enabled    *bool    `json:"enabled"`
+	Values     map[string][]struct {
+		Locale *string `json:"locale"`
+		Scope  *string `json:"scope"`
+		Data   any     `json:"data"`
+	} `json:"values"`
+	Created string `json:"created"`
+	Updated string `json:"updated"`
+}
+
+func (row *productRow) toPIM() (pim.Product, error) {
+	product := pim.Product{
+		Identifier: row.Identifier,
+		Family:     row.Family,
+		Categories: row.Categories,
+		Enabled:    row.Enabled == nil || *row.Enabled,
+		Values:     make(map[string][]pim.AttributeValue, len(row.Values)),
+	}
+	for code, list := range row.Values {
+		for _, v := range list {
+			value := pim.AttributeValue{Data: normalize(v.Data)}
+			if v.Locale != nil {
+				value.Locale = *v.Locale
+			}
+			if v.Scope != nil {
+				value.Scope = *v.Scope
+			}
+			product.Values[code] = append(product.Values[code], value)
+		}
+	}
+	var err error
+	if row.Created != "" {
+		if product.Created, err = parseTimestamp(row.Created); err != nil {
+			return product, fmt.Errorf("created: %w", err)
+		}
+	}
+	if row.Updated != "" {
+		if product.Updated, err = parseTimestamp(row.Updated); err != nil {
+			return product, fmt.Errorf("updated: %w", err)
+		}
+	}
+	return product, nil
+}
+
+// normalize converts the json.Numbers of a decoded value: integers to int64
+// and other numbers to float64.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+	}
+	return v
+}
+
+// categories parses a category feed with the columns code, parent and
+// label-<locale>.
+func (p *parser) categories(r io.Reader, format string) ([]pim.Category, error) {
+	var categories []pim.Category
+	add := func(category pim.Category) error {
+		if category.Code == "" {
+			return errors.New("code is empty")
+		}
+		categories = append(categories, category)
+		return nil
+	}
+
+	if format == formatNDJSON {
+		err := ndjsonRows(r, func(row *pim.Category) error { return add(*row) })
+		return categories, err
+	}
+	err := p.csvRows(r, "category", func(row map[string]string) error {
+		return add(pim.Category{Code: row["code"], Parent: row["parent"], Labels: labels(row)})
+	})
+	return categories, err
+}
+
+// attributeRow is an attribute line of an NDJSON feed.
+type attributeRow struct {
+	Code        string            `json:"code"`
+	Type        string            `json:"type"`
+	Group       string            `json:"group"`
+	Localizable bool              `json:"localizable"`
+	Scopable    bool              `json:"scopable"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// attributes parses an attribute feed with the columns code, type, group,
+// localizable, scopable and label-<locale>.
+func (p *parser) attributes(r io.Reader, format string) ([]pim.Attribute, error) {
+	var attributes []pim.Attribute
+	add := func(row attributeRow) error {
+		if row.Code == "" {
+			return errors.New("code is empty")
+		}
+		attributes = append(attributes, pim.Attribute{
+			Code:        row.Code,
+			Type:        strings.TrimPrefix(row.Type, "pim_catalog_"),
+			Group:       row.Group,
+			Localizable: row.Localizable,
+			Scopable:    row.Scopable,
+			Labels:      row.Labels,
+		})
+		return nil
+	}
+
+	if format == formatNDJSON {
+		err := ndjsonRows(r, func(row *attributeRow) error { return add(*row) })
+		return attributes, err
+	}
+	err := p.csvRows(r, "attribute", func(row map[string]string) error {
+		attr := attributeRow{Code: row["code"], Type: row["type"], Group: row["group"], Labels: labels(row)}
+		for field, target := range map[string]*bool{"localizable": &attr.Localizable, "scopable": &attr.Scopable} {
+			if row[field] == "" {
+				continue
+			}
+			b, err := strconv.ParseBool(row[field])
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", field, row[field])
+			}
+			*target = b
+		}
+		return add(attr)
+	})
+	return attributes, err
+}
@@ -0,0 +1,97 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// source reads feed files by their path relative to the feed root.
+type source interface {
+	// version identifies the current content of a file, e.g. its modification
+	// time. Missing files return provider.ErrNotFound.
+	version(ctx context.Context, name string) (string, error)
+
+	// open opens a file and returns its content type.
+	open(ctx context.Context, name string) (io.ReadCloser, string, error)
+}
+
+// dirSource reads files from a local directory.
+type dirSource struct {
+	dir string
+}
+
+func (s dirSource) version(ctx context.Context, name string) (string, error) {
+	info, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return "", fileError(name, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("feed: %s is a directory: %w", name, provider.ErrNotFound)
+	}
+	return info.ModTime().UTC().String() + "/" + strconv.FormatInt(info.Size(), 10), nil
+}
+
+func (s dirSource) open(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	if _, err := s.version(ctx, name); err != nil {
+		return nil, "", err
+	}
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, "", fileError(name, err)
+	}
+	return f, mime.TypeByExtension(path.Ext(name)), nil
+}
+
+func fileError(name string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("feed: %s does not exist: %w", name, provider.ErrNotFound)
+	}
+	return fmt.Errorf("feed: %w", err)
+}
+
+// storageSource reads files from a storage provider below a prefix.
+type storageSource struct {
+	storage storage.StorageProvider
+	prefix  string
+}
+
+func (s storageSource) version(ctx context.Context, name string) (string, error) {
+	key := s.prefix + name
+	files, err := s.storage.List(ctx, key, storage.ListOptions{MaxKeys: 1})
+	if err != nil {
+		return "", fmt.Errorf("feed: list %s: %w", key, err)
+	}
+	if len(files) == 0 || files[0].Path != key {
+		return "", fmt.Errorf("feed: %s does not exist: %w", key, provider.ErrNotFound)
+	}
+	if files[0].ETag != "" {
+		return files[0].ETag, nil
+	}
+	return files[0].LastModified.UTC().String() + "/" + strconv.FormatInt(files[0].Size, 10), nil
+}
+
+func (s storageSource) open(ctx context.Context, name string) (io.ReadCloser, string, error) {
+	rc, info, err := s.storage.Download(ctx, s.prefix+name)
+	if err != nil {
+		return nil, "", fmt.Errorf("feed: download %s: %w", s.prefix+name, err)
+	}
+	contentType := ""
+	if info != nil {
+		contentType = info.ContentType
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	return rc, contentType, nil
+}
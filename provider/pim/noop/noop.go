@@ -3,7 +3,9 @@ package noop
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/pim"
@@ -32,6 +34,16 @@ func NewProvider(config map[string]any) (pim.PIMProvider, error) {
 }
 
 func (p *Provider) FetchProducts(ctx context.Context, filter pim.ProductFilter) (*pim.ProductPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 {
+		return nil, fmt.Errorf("noop: limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	// The provider never issues cursors
+	if filter.Cursor != "" {
+		return nil, fmt.Errorf("noop: unknown cursor: %w", provider.ErrInvalidArgument)
+	}
 	return &pim.ProductPage{
 		Products:   []pim.Product{},
 		NextCursor: "",
@@ -40,6 +52,12 @@ func (p *Provider) FetchProducts(ctx context.Context, filter pim.ProductFilter)
 }
 
 func (p *Provider) FetchProduct(ctx context.Context, identifier string) (*pim.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if identifier == "" {
+		return nil, fmt.Errorf("noop: identifier is required: %w", provider.ErrInvalidArgument)
+	}
 	return &pim.Product{
 		Identifier: identifier,
 		Family:     "",
@@ -49,16 +67,66 @@ func (p *Provider) FetchProduct(ctx context.Context, identifier string) (*pim.Pr
 	}, nil
 }
 
+func (p *Provider) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (*pim.ProductModelPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit < 0 {
+		return nil, fmt.Errorf("noop: limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	if filter.Cursor != "" {
+		return nil, fmt.Errorf("noop: unknown cursor: %w", provider.ErrInvalidArgument)
+	}
+	return &pim.ProductModelPage{
+		Models:     []pim.ProductModel{},
+		NextCursor: "",
+		TotalCount: 0,
+	}, nil
+}
+
+func (p *Provider) FetchProductModel(ctx context.Context, code string) (*pim.ProductModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("noop: code is required: %w", provider.ErrInvalidArgument)
+	}
+	return &pim.ProductModel{
+		Code:       code,
+		Categories: []string{},
+		Values:     make(map[string][]pim.AttributeValue),
+	}, nil
+}
+
+func (p *Provider) FetchFamilyVariants(ctx context.Context) ([]pim.FamilyVariant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []pim.FamilyVariant{}, nil
+}
+
 func (p *Provider) FetchCategories(ctx context.Context) ([]pim.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return []pim.Category{}, nil
 }
 
 func (p *Provider) FetchAttributes(ctx context.Context) ([]pim.Attribute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return []pim.Attribute{}, nil
 }
 
 func (p *Provider) DownloadAsset(ctx context.Context, assetCode string) (io.ReadCloser, string, error) {
-	return io.NopCloser(nil), "", nil
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	if assetCode == "" {
+		return nil, "", fmt.Errorf("noop: asset code is required: %w", provider.ErrInvalidArgument)
+	}
+	return io.NopCloser(strings.NewReader("")), "application/octet-stream", nil
 }
 
 func (p *Provider) Metadata() pim.Metadata {
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/pim/pimtest"
+)
+
+func TestConformance(t *testing.T) {
+	pimtest.Run(t, func(t *testing.T) pim.PIMProvider {
+		return &Provider{}
+	}, pimtest.Options{Stateless: true})
+}
@@ -17,6 +17,17 @@ type PIMProvider interface {
 	// FetchProduct retrieves a single product by identifier.
 	FetchProduct(ctx context.Context, identifier string) (*Product, error)
 
+	// --- Product models ---
+
+	// FetchProductModels retrieves product models with cursor-based pagination.
+	FetchProductModels(ctx context.Context, filter ProductFilter) (*ProductModelPage, error)
+
+	// FetchProductModel retrieves a single product model by code.
+	FetchProductModel(ctx context.Context, code string) (*ProductModel, error)
+
+	// FetchFamilyVariants retrieves the family variants of all families.
+	FetchFamilyVariants(ctx context.Context) ([]FamilyVariant, error)
+
 	// --- Categories ---
 
 	// FetchCategories retrieves all categories.
@@ -39,24 +50,30 @@ type PIMProvider interface {
 }
 
 // ProductFilter contains filter criteria for fetching products.
+// Families and Categories match products in any of the listed codes.
+// A Limit of 0 selects the provider's default page size.
 type ProductFilter struct {
 	UpdatedSince *time.Time
 	Families     []string
 	Categories   []string
-	Cursor       string // For pagination
+	Cursor       string // For pagination; opaque, taken from ProductPage.NextCursor
 	Limit        int
 }
 
 // ProductPage represents a page of products.
+// An empty NextCursor marks the last page.
 type ProductPage struct {
 	Products   []Product
 	NextCursor string
-	TotalCount int
+	TotalCount int // 0 if the provider cannot count the matching products
 }
 
 // Product represents a product from the PIM system.
+// Products of a product model are its variants; their values include
+// the values inherited from their models.
 type Product struct {
 	Identifier string
+	Parent     string // Code of the product model; empty for standalone products
 	Family     string
 	Categories []string
 	Enabled    bool
@@ -65,6 +82,43 @@ type Product struct {
 	Updated    time.Time
 }
 
+// ProductModelPage represents a page of product models.
+// An empty NextCursor marks the last page.
+type ProductModelPage struct {
+	Models     []ProductModel
+	NextCursor string
+	TotalCount int // 0 if the provider cannot count the matching models
+}
+
+// ProductModel groups the variants of a product. A root model has no
+// parent; sub-models group the variants of one value of the first level
+// axes of their root model.
+type ProductModel struct {
+	Code          string
+	Parent        string // Code of the parent model; empty for root models
+	Family        string
+	FamilyVariant string
+	Categories    []string
+	Values        map[string][]AttributeValue // Includes the values of the parent model
+	Created       time.Time
+	Updated       time.Time
+}
+
+// FamilyVariant defines the variant levels of the products of a family.
+type FamilyVariant struct {
+	Code   string
+	Family string
+	Labels map[string]string
+	Levels []VariantLevel // Level 1 first
+}
+
+// VariantLevel lists the axes that tell the models or products of a level
+// apart, and the attributes maintained on that level.
+type VariantLevel struct {
+	Axes       []string
+	Attributes []string
+}
+
 // AttributeValue represents a localized/scoped attribute value.
 type AttributeValue struct {
 	Locale string
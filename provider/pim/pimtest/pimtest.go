@@ -0,0 +1,260 @@
+// Package pimtest is a conformance suite for pim.PIMProvider implementations.
+// It checks cursor pagination, product filters, error types and context
+// cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    pimtest.Run(t, func(t *testing.T) pim.PIMProvider {
+//	        return newTestProvider(t)
+//	    }, pimtest.Options{AssetCode: "product_image_1"})
+//	}
+//
+// The data-dependent checks need a catalog with at least three products.
+package pimtest
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/providertest"
+)
+
+// maxPages bounds the pagination walk so a provider that never ends its cursor fails instead of hanging.
+const maxPages = 10000
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that hold no data and answer every lookup
+	// with placeholder values, such as noop. Data-dependent checks are skipped.
+	Stateless bool
+
+	// AssetCode is an asset that exists in the PIM. The download check is skipped if empty.
+	AssetCode string
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) pim.PIMProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("NotFound", s.stateful(s.testNotFound))
+	t.Run("Pagination", s.stateful(s.testPagination))
+	t.Run("Filters", s.stateful(s.testFilters))
+	t.Run("DownloadAsset", s.testDownloadAsset)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) stateful(test func(t *testing.T)) func(t *testing.T) {
+	return func(t *testing.T) {
+		if s.opts.Stateless {
+			t.Skip("provider is stateless")
+		}
+		test(t)
+	}
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+
+	_, err := p.FetchProducts(ctx, pim.ProductFilter{})
+	providertest.ExpectCanceled(t, "FetchProducts", err)
+	_, err = p.FetchProduct(ctx, "1")
+	providertest.ExpectCanceled(t, "FetchProduct", err)
+	_, err = p.FetchProductModels(ctx, pim.ProductFilter{})
+	providertest.ExpectCanceled(t, "FetchProductModels", err)
+	_, err = p.FetchProductModel(ctx, "1")
+	providertest.ExpectCanceled(t, "FetchProductModel", err)
+	_, err = p.FetchFamilyVariants(ctx)
+	providertest.ExpectCanceled(t, "FetchFamilyVariants", err)
+	_, err = p.FetchCategories(ctx)
+	providertest.ExpectCanceled(t, "FetchCategories", err)
+	_, err = p.FetchAttributes(ctx)
+	providertest.ExpectCanceled(t, "FetchAttributes", err)
+	rc, _, err := p.DownloadAsset(ctx, "1")
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectCanceled(t, "DownloadAsset", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.FetchProducts(ctx, pim.ProductFilter{Limit: -1})
+	providertest.ExpectError(t, "FetchProducts with negative limit", err, invalid)
+	_, err = p.FetchProducts(ctx, pim.ProductFilter{Cursor: "conformance-invalid-cursor"})
+	providertest.ExpectError(t, "FetchProducts with foreign cursor", err, invalid)
+	_, err = p.FetchProduct(ctx, "")
+	providertest.ExpectError(t, "FetchProduct with empty identifier", err, invalid)
+	_, err = p.FetchProductModels(ctx, pim.ProductFilter{Limit: -1})
+	providertest.ExpectError(t, "FetchProductModels with negative limit", err, invalid)
+	_, err = p.FetchProductModels(ctx, pim.ProductFilter{Cursor: "conformance-invalid-cursor"})
+	providertest.ExpectError(t, "FetchProductModels with foreign cursor", err, invalid)
+	_, err = p.FetchProductModel(ctx, "")
+	providertest.ExpectError(t, "FetchProductModel with empty code", err, invalid)
+	rc, _, err := p.DownloadAsset(ctx, "")
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectError(t, "DownloadAsset with empty code", err, invalid)
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	_, err := p.FetchProduct(ctx, providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "FetchProduct of unknown product", err, provider.ErrNotFound)
+	_, err = p.FetchProductModel(ctx, providertest.UniqueName("conformance-missing-"))
+	providertest.ExpectError(t, "FetchProductModel of unknown model", err, provider.ErrNotFound)
+	rc, _, err := p.DownloadAsset(ctx, providertest.UniqueName("conformance-missing-"))
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectError(t, "DownloadAsset of unknown asset", err, provider.ErrNotFound)
+}
+
+// fetchAll walks all pages of filter and returns the products.
+func fetchAll(t *testing.T, p pim.PIMProvider, filter pim.ProductFilter) []pim.Product {
+	t.Helper()
+	var products []pim.Product
+	seen := make(map[string]bool)
+	for page := 0; ; page++ {
+		if page == maxPages {
+			t.Fatalf("FetchProducts did not reach the last page after %d pages", maxPages)
+		}
+		result, err := p.FetchProducts(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("FetchProducts(page %d) error = %v", page+1, err)
+		}
+		if filter.Limit > 0 && len(result.Products) > filter.Limit {
+			t.Errorf("page %d has %d products, limit is %d", page+1, len(result.Products), filter.Limit)
+		}
+		for _, product := range result.Products {
+			if seen[product.Identifier] {
+				t.Errorf("product %s returned twice", product.Identifier)
+			}
+			seen[product.Identifier] = true
+			products = append(products, product)
+		}
+		if result.NextCursor == "" {
+			if result.TotalCount > 0 && result.TotalCount != len(products) {
+				t.Errorf("TotalCount = %d, but pages contained %d products", result.TotalCount, len(products))
+			}
+			return products
+		}
+		if result.NextCursor == filter.Cursor {
+			t.Fatalf("NextCursor %q did not advance", result.NextCursor)
+		}
+		filter.Cursor = result.NextCursor
+	}
+}
+
+func (s *suite) testPagination(t *testing.T) {
+	p := s.newProvider(t)
+
+	products := fetchAll(t, p, pim.ProductFilter{Limit: 2})
+	if len(products) < 3 {
+		t.Fatalf("pagination needs at least 3 products, got %d", len(products))
+	}
+
+	product, err := p.FetchProduct(context.Background(), products[0].Identifier)
+	if err != nil {
+		t.Fatalf("FetchProduct(%s) error = %v", products[0].Identifier, err)
+	}
+	if product.Identifier != products[0].Identifier {
+		t.Errorf("FetchProduct(%s) returned %s", products[0].Identifier, product.Identifier)
+	}
+}
+
+func (s *suite) testFilters(t *testing.T) {
+	p := s.newProvider(t)
+	products := fetchAll(t, p, pim.ProductFilter{Limit: 100})
+
+	future := time.Now().Add(24 * time.Hour)
+	if updated := fetchAll(t, p, pim.ProductFilter{UpdatedSince: &future}); len(updated) != 0 {
+		t.Errorf("UpdatedSince in the future returned %d products", len(updated))
+	}
+
+	var family, category string
+	for _, product := range products {
+		if family == "" {
+			family = product.Family
+		}
+		if category == "" && len(product.Categories) > 0 {
+			category = product.Categories[0]
+		}
+	}
+
+	if family != "" {
+		filtered := fetchAll(t, p, pim.ProductFilter{Families: []string{family}})
+		if len(filtered) == 0 {
+			t.Errorf("Families [%s] returned no products", family)
+		}
+		for _, product := range filtered {
+			if product.Family != family {
+				t.Errorf("Families [%s] returned %s of family %q", family, product.Identifier, product.Family)
+			}
+		}
+	}
+
+	if category != "" {
+		filtered := fetchAll(t, p, pim.ProductFilter{Categories: []string{category}})
+		if len(filtered) == 0 {
+			t.Errorf("Categories [%s] returned no products", category)
+		}
+		for _, product := range filtered {
+			if !slices.Contains(product.Categories, category) {
+				t.Errorf("Categories [%s] returned %s in %v", category, product.Identifier, product.Categories)
+			}
+		}
+	}
+}
+
+func (s *suite) testDownloadAsset(t *testing.T) {
+	if s.opts.AssetCode == "" {
+		t.Skip("no AssetCode configured")
+	}
+	p := s.newProvider(t)
+
+	rc, contentType, err := p.DownloadAsset(context.Background(), s.opts.AssetCode)
+	if err != nil {
+		t.Fatalf("DownloadAsset(%s) error = %v", s.opts.AssetCode, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading asset: %v", err)
+	}
+	if len(data) == 0 {
+		t.Error("asset is empty")
+	}
+	if contentType == "" {
+		t.Error("content type is empty")
+	}
+}
@@ -22,7 +22,7 @@ type Metadata struct {
 // ConfigField describes a configuration field required by the provider.
 type ConfigField struct {
 	Key         string // Field name
-	Type        string // "string", "int", "bool", "secret"
+	Type        string // "string", "secret", "int", "float", "bool", "duration", "array", "map"
 	Required    bool   // Is this field required?
 	Default     any    // Default value (if any)
 	Description string // Human-readable description
@@ -31,11 +31,16 @@ type ConfigField struct {
 // ProviderFactory creates a provider instance from configuration.
 type ProviderFactory[T any] func(config map[string]any) (T, error)
 
+// TypedFactory creates a provider instance from a typed configuration struct.
+// The struct fields are bound from the validated config via `config:"key"` tags.
+type TypedFactory[T any, C any] func(config C) (T, error)
+
 // --- Global Registry ---
 
 var (
-	registry = make(map[string]map[string]any)      // category -> name -> factory
-	metadata = make(map[string]map[string]Metadata) // category -> name -> metadata
+	registry = make(map[string]map[string]any)                  // category -> name -> factory
+	untyped  = make(map[string]map[string]ProviderFactory[any]) // category -> name -> factory returning any
+	metadata = make(map[string]map[string]Metadata)             // category -> name -> metadata
 	mu       sync.RWMutex
 )
 
@@ -56,6 +61,7 @@ func Register[T any](category, name string, meta Metadata, factory ProviderFacto
 
 	if registry[category] == nil {
 		registry[category] = make(map[string]any)
+		untyped[category] = make(map[string]ProviderFactory[any])
 		metadata[category] = make(map[string]Metadata)
 	}
 
@@ -65,11 +71,47 @@ func Register[T any](category, name string, meta Metadata, factory ProviderFacto
 	}
 
 	registry[category][name] = factory
+	untyped[category][name] = func(config map[string]any) (any, error) {
+		return factory(config)
+	}
 	metadata[category][name] = meta
 }
 
+// RegisterTyped registers a provider whose factory receives a typed configuration struct
+// instead of a raw map. The config is validated against meta.ConfigSpec and then bound
+// into C (see Bind), so the factory no longer needs its own type assertions.
+//
+// Example:
+//
+//	type Config struct {
+//	    Host   string `config:"host"`
+//	    APIKey string `config:"api_key"`
+//	}
+//
+//	func init() {
+//	    provider.RegisterTyped[search.SearchProvider, Config]("search", "meilisearch", meta, New)
+//	}
+func RegisterTyped[T any, C any](category, name string, meta Metadata, factory TypedFactory[T, C]) {
+	Register[T](category, name, meta, func(config map[string]any) (T, error) {
+		var typed C
+		if err := Bind(config, &typed); err != nil {
+			var zero T
+			return zero, fmt.Errorf("provider %s.%s: %w", category, name, err)
+		}
+		return factory(typed)
+	})
+}
+
 // Get retrieves a provider factory from the registry.
 //
+// The returned factory validates its config against the provider's ConfigSpec before
+// calling the registered factory: defaults are applied, values are coerced to the
+// declared types, unknown keys are rejected and all missing required fields are
+// reported in a single *ConfigError.
+//
+// Unlike instances from a Resolver, providers created this way are not managed:
+// callers start and close them with Start and Close (see Starter and Closer).
+//
 // Example:
 //
 //	factory, err := provider.Get[payment.PaymentProvider]("payment", "saferpay")
@@ -96,7 +138,49 @@ func Get[T any](category, name string) (ProviderFactory[T], error) {
 		return nil, fmt.Errorf("provider %s.%s has wrong type", category, name)
 	}
 
-	return f, nil
+	spec := metadata[category][name].ConfigSpec
+	return func(config map[string]any) (T, error) {
+		validated, err := ValidateConfig(spec, config)
+		if err != nil {
+			var zero T
+			return zero, fmt.Errorf("provider %s.%s: %w", category, name, err)
+		}
+		return f(validated)
+	}, nil
+}
+
+// GetAny retrieves a provider factory from the registry without knowing its interface type.
+// It is used by code that handles providers of all categories, such as the Resolver.
+// Like Get, the returned factory validates its config before building the provider.
+func GetAny(category, name string) (ProviderFactory[any], error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if _, ok := untyped[category]; !ok {
+		return nil, fmt.Errorf("unknown provider category: %s", category)
+	}
+	f, ok := untyped[category][name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider: %s.%s", category, name)
+	}
+
+	spec := metadata[category][name].ConfigSpec
+	return func(config map[string]any) (any, error) {
+		validated, err := ValidateConfig(spec, config)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s.%s: %w", category, name, err)
+		}
+		return f(validated)
+	}, nil
+}
+
+// GetMetadata returns the metadata of a registered provider.
+func GetMetadata(category, name string) (Metadata, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	m, ok := metadata[category][name]
+	return m, ok
 }
 
 // List returns all registered providers in a category.
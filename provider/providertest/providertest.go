@@ -0,0 +1,45 @@
+// Package providertest contains helpers shared by the provider conformance
+// suites (searchtest, erptest, ...). Provider authors normally use the suite
+// of their category instead of this package.
+package providertest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// CanceledContext returns a context that is already canceled.
+func CanceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// ExpectCanceled reports an error unless err wraps context.Canceled.
+func ExpectCanceled(t testing.TB, method string, err error) {
+	t.Helper()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("%s with canceled context: error = %v, want context.Canceled", method, err)
+	}
+}
+
+// ExpectError reports an error unless err wraps target.
+func ExpectError(t testing.TB, method string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: error = %v, want %v", method, err, target)
+	}
+}
+
+// UniqueName returns prefix followed by a random suffix, for indexes, paths
+// and other resources a suite creates in a shared backend.
+func UniqueName(prefix string) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return prefix + hex.EncodeToString(b)
+}
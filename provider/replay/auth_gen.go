@@ -0,0 +1,95 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/auth"
+)
+
+// authRecorder records calls to auth.AuthProvider.
+type authRecorder struct {
+	next auth.AuthProvider
+	rec  *Recorder
+}
+
+// RecordAuth wraps auth.AuthProvider so that every call is recorded to rec.
+func RecordAuth(p auth.AuthProvider, rec *Recorder) auth.AuthProvider {
+	return &authRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *authRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *authRecorder) GetAuthURL(ctx context.Context, state string, redirectURL string) (r0 string, err error) {
+	r0, err = p.next.GetAuthURL(ctx, state, redirectURL)
+	p.rec.record("GetAuthURL", []any{state, redirectURL}, []any{r0}, err)
+	return
+}
+
+func (p *authRecorder) HandleCallback(ctx context.Context, code string, state string) (r0 *auth.SSOUser, err error) {
+	r0, err = p.next.HandleCallback(ctx, code, state)
+	p.rec.record("HandleCallback", []any{code, state}, []any{r0}, err)
+	return
+}
+
+func (p *authRecorder) ValidateToken(ctx context.Context, token string) (r0 *auth.SSOUser, err error) {
+	r0, err = p.next.ValidateToken(ctx, token)
+	p.rec.record("ValidateToken", []any{token}, []any{r0}, err)
+	return
+}
+
+func (p *authRecorder) GetUserInfo(ctx context.Context, accessToken string) (r0 *auth.SSOUser, err error) {
+	r0, err = p.next.GetUserInfo(ctx, accessToken)
+	p.rec.record("GetUserInfo", []any{accessToken}, []any{r0}, err)
+	return
+}
+
+func (p *authRecorder) Metadata() (r0 auth.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// authReplayer serves recorded calls to auth.AuthProvider.
+type authReplayer struct {
+	player *Player
+}
+
+// ReplayAuth returns a auth.AuthProvider that answers every call from player.
+func ReplayAuth(player *Player) auth.AuthProvider {
+	return &authReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *authReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *authReplayer) GetAuthURL(ctx context.Context, state string, redirectURL string) (r0 string, err error) {
+	err = p.player.play(ctx, "GetAuthURL", []any{state, redirectURL}, &r0)
+	return
+}
+
+func (p *authReplayer) HandleCallback(ctx context.Context, code string, state string) (r0 *auth.SSOUser, err error) {
+	err = p.player.play(ctx, "HandleCallback", []any{code, state}, &r0)
+	return
+}
+
+func (p *authReplayer) ValidateToken(ctx context.Context, token string) (r0 *auth.SSOUser, err error) {
+	err = p.player.play(ctx, "ValidateToken", []any{token}, &r0)
+	return
+}
+
+func (p *authReplayer) GetUserInfo(ctx context.Context, accessToken string) (r0 *auth.SSOUser, err error) {
+	err = p.player.play(ctx, "GetUserInfo", []any{accessToken}, &r0)
+	return
+}
+
+func (p *authReplayer) Metadata() (r0 auth.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,84 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/crm"
+)
+
+// crmRecorder records calls to crm.CRMProvider.
+type crmRecorder struct {
+	next crm.CRMProvider
+	rec  *Recorder
+}
+
+// RecordCRM wraps crm.CRMProvider so that every call is recorded to rec.
+func RecordCRM(p crm.CRMProvider, rec *Recorder) crm.CRMProvider {
+	return &crmRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *crmRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *crmRecorder) SyncContact(ctx context.Context, contact crm.Contact) (r0 *crm.SyncResult, err error) {
+	r0, err = p.next.SyncContact(ctx, contact)
+	p.rec.record("SyncContact", []any{contact}, []any{r0}, err)
+	return
+}
+
+func (p *crmRecorder) GetAccount(ctx context.Context, accountID string) (r0 *crm.Account, err error) {
+	r0, err = p.next.GetAccount(ctx, accountID)
+	p.rec.record("GetAccount", []any{accountID}, []any{r0}, err)
+	return
+}
+
+func (p *crmRecorder) ListAccounts(ctx context.Context, filter crm.AccountFilter) (r0 []crm.Account, err error) {
+	r0, err = p.next.ListAccounts(ctx, filter)
+	p.rec.record("ListAccounts", []any{filter}, []any{r0}, err)
+	return
+}
+
+func (p *crmRecorder) Metadata() (r0 crm.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// crmReplayer serves recorded calls to crm.CRMProvider.
+type crmReplayer struct {
+	player *Player
+}
+
+// ReplayCRM returns a crm.CRMProvider that answers every call from player.
+func ReplayCRM(player *Player) crm.CRMProvider {
+	return &crmReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *crmReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *crmReplayer) SyncContact(ctx context.Context, contact crm.Contact) (r0 *crm.SyncResult, err error) {
+	err = p.player.play(ctx, "SyncContact", []any{contact}, &r0)
+	return
+}
+
+func (p *crmReplayer) GetAccount(ctx context.Context, accountID string) (r0 *crm.Account, err error) {
+	err = p.player.play(ctx, "GetAccount", []any{accountID}, &r0)
+	return
+}
+
+func (p *crmReplayer) ListAccounts(ctx context.Context, filter crm.AccountFilter) (r0 []crm.Account, err error) {
+	err = p.player.play(ctx, "ListAccounts", []any{filter}, &r0)
+	return
+}
+
+func (p *crmReplayer) Metadata() (r0 crm.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,161 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// erpRecorder records calls to erp.ERPProvider.
+type erpRecorder struct {
+	next erp.ERPProvider
+	rec  *Recorder
+}
+
+// RecordERP wraps erp.ERPProvider so that every call is recorded to rec.
+func RecordERP(p erp.ERPProvider, rec *Recorder) erp.ERPProvider {
+	return &erpRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *erpRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *erpRecorder) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (r0 *erp.CreateOrderResult, err error) {
+	r0, err = p.next.CreateOrder(ctx, req)
+	p.rec.record("CreateOrder", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (r0 *erp.SimulateOrderResult, err error) {
+	r0, err = p.next.SimulateOrder(ctx, req)
+	p.rec.record("SimulateOrder", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetOrderStatus(ctx context.Context, orderID string) (r0 *erp.OrderStatus, err error) {
+	r0, err = p.next.GetOrderStatus(ctx, orderID)
+	p.rec.record("GetOrderStatus", []any{orderID}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetProductAvailability(ctx context.Context, skus []string) (r0 []erp.ProductStock, err error) {
+	r0, err = p.next.GetProductAvailability(ctx, skus)
+	p.rec.record("GetProductAvailability", []any{skus}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) (r0 []erp.TierPrice, err error) {
+	r0, err = p.next.GetTierPrices(ctx, req)
+	p.rec.record("GetTierPrices", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) SyncCompany(ctx context.Context, erpCustomerID string) (r0 *erp.CompanyData, err error) {
+	r0, err = p.next.SyncCompany(ctx, erpCustomerID)
+	p.rec.record("SyncCompany", []any{erpCustomerID}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetCompanyAddresses(ctx context.Context, erpCustomerID string) (r0 []erp.Address, err error) {
+	r0, err = p.next.GetCompanyAddresses(ctx, erpCustomerID)
+	p.rec.record("GetCompanyAddresses", []any{erpCustomerID}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetOrderHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.OrderReport, err error) {
+	r0, err = p.next.GetOrderHistory(ctx, req)
+	p.rec.record("GetOrderHistory", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.ShipmentReport, err error) {
+	r0, err = p.next.GetShipmentHistory(ctx, req)
+	p.rec.record("GetShipmentHistory", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.InvoiceReport, err error) {
+	r0, err = p.next.GetInvoiceHistory(ctx, req)
+	p.rec.record("GetInvoiceHistory", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *erpRecorder) Metadata() (r0 erp.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// erpReplayer serves recorded calls to erp.ERPProvider.
+type erpReplayer struct {
+	player *Player
+}
+
+// ReplayERP returns a erp.ERPProvider that answers every call from player.
+func ReplayERP(player *Player) erp.ERPProvider {
+	return &erpReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *erpReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *erpReplayer) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (r0 *erp.CreateOrderResult, err error) {
+	err = p.player.play(ctx, "CreateOrder", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (r0 *erp.SimulateOrderResult, err error) {
+	err = p.player.play(ctx, "SimulateOrder", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetOrderStatus(ctx context.Context, orderID string) (r0 *erp.OrderStatus, err error) {
+	err = p.player.play(ctx, "GetOrderStatus", []any{orderID}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetProductAvailability(ctx context.Context, skus []string) (r0 []erp.ProductStock, err error) {
+	err = p.player.play(ctx, "GetProductAvailability", []any{skus}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) (r0 []erp.TierPrice, err error) {
+	err = p.player.play(ctx, "GetTierPrices", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) SyncCompany(ctx context.Context, erpCustomerID string) (r0 *erp.CompanyData, err error) {
+	err = p.player.play(ctx, "SyncCompany", []any{erpCustomerID}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetCompanyAddresses(ctx context.Context, erpCustomerID string) (r0 []erp.Address, err error) {
+	err = p.player.play(ctx, "GetCompanyAddresses", []any{erpCustomerID}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetOrderHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.OrderReport, err error) {
+	err = p.player.play(ctx, "GetOrderHistory", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.ShipmentReport, err error) {
+	err = p.player.play(ctx, "GetShipmentHistory", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.InvoiceReport, err error) {
+	err = p.player.play(ctx, "GetInvoiceHistory", []any{req}, &r0)
+	return
+}
+
+func (p *erpReplayer) Metadata() (r0 erp.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,106 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/fulfillment"
+)
+
+// fulfillmentRecorder records calls to fulfillment.FulfillmentProvider.
+type fulfillmentRecorder struct {
+	next fulfillment.FulfillmentProvider
+	rec  *Recorder
+}
+
+// RecordFulfillment wraps fulfillment.FulfillmentProvider so that every call is recorded to rec.
+func RecordFulfillment(p fulfillment.FulfillmentProvider, rec *Recorder) fulfillment.FulfillmentProvider {
+	return &fulfillmentRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *fulfillmentRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *fulfillmentRecorder) CreateShipment(ctx context.Context, req fulfillment.ShipmentRequest) (r0 *fulfillment.ShipmentResult, err error) {
+	r0, err = p.next.CreateShipment(ctx, req)
+	p.rec.record("CreateShipment", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *fulfillmentRecorder) GetShipmentStatus(ctx context.Context, shipmentID string) (r0 *fulfillment.ShipmentStatus, err error) {
+	r0, err = p.next.GetShipmentStatus(ctx, shipmentID)
+	p.rec.record("GetShipmentStatus", []any{shipmentID}, []any{r0}, err)
+	return
+}
+
+func (p *fulfillmentRecorder) CancelShipment(ctx context.Context, shipmentID string) (err error) {
+	err = p.next.CancelShipment(ctx, shipmentID)
+	p.rec.record("CancelShipment", []any{shipmentID}, []any{}, err)
+	return
+}
+
+func (p *fulfillmentRecorder) GetTrackingURL(ctx context.Context, trackingNumber string) (r0 string, err error) {
+	r0, err = p.next.GetTrackingURL(ctx, trackingNumber)
+	p.rec.record("GetTrackingURL", []any{trackingNumber}, []any{r0}, err)
+	return
+}
+
+func (p *fulfillmentRecorder) CalculateShipping(ctx context.Context, req fulfillment.ShippingCalcRequest) (r0 []fulfillment.ShippingOption, err error) {
+	r0, err = p.next.CalculateShipping(ctx, req)
+	p.rec.record("CalculateShipping", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *fulfillmentRecorder) Metadata() (r0 fulfillment.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// fulfillmentReplayer serves recorded calls to fulfillment.FulfillmentProvider.
+type fulfillmentReplayer struct {
+	player *Player
+}
+
+// ReplayFulfillment returns a fulfillment.FulfillmentProvider that answers every call from player.
+func ReplayFulfillment(player *Player) fulfillment.FulfillmentProvider {
+	return &fulfillmentReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *fulfillmentReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *fulfillmentReplayer) CreateShipment(ctx context.Context, req fulfillment.ShipmentRequest) (r0 *fulfillment.ShipmentResult, err error) {
+	err = p.player.play(ctx, "CreateShipment", []any{req}, &r0)
+	return
+}
+
+func (p *fulfillmentReplayer) GetShipmentStatus(ctx context.Context, shipmentID string) (r0 *fulfillment.ShipmentStatus, err error) {
+	err = p.player.play(ctx, "GetShipmentStatus", []any{shipmentID}, &r0)
+	return
+}
+
+func (p *fulfillmentReplayer) CancelShipment(ctx context.Context, shipmentID string) (err error) {
+	err = p.player.play(ctx, "CancelShipment", []any{shipmentID})
+	return
+}
+
+func (p *fulfillmentReplayer) GetTrackingURL(ctx context.Context, trackingNumber string) (r0 string, err error) {
+	err = p.player.play(ctx, "GetTrackingURL", []any{trackingNumber}, &r0)
+	return
+}
+
+func (p *fulfillmentReplayer) CalculateShipping(ctx context.Context, req fulfillment.ShippingCalcRequest) (r0 []fulfillment.ShippingOption, err error) {
+	err = p.player.play(ctx, "CalculateShipping", []any{req}, &r0)
+	return
+}
+
+func (p *fulfillmentReplayer) Metadata() (r0 fulfillment.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
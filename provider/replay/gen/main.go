@@ -0,0 +1,246 @@
+// Command gen generates the recording and replaying wrappers in package
+// provider/replay from the provider interface definitions.
+//
+// Usage (from provider/replay):
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gondolia/gondolia/provider/internal/codegen"
+)
+
+const header = "// Code generated by provider/replay/gen. DO NOT EDIT.\n\n"
+
+func main() {
+	for _, t := range codegen.Targets {
+		methods, err := codegen.ParseInterface("../"+t.Category, t)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := codegen.Write(t.Category+"_gen.go", renderWrappers(t, methods)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := codegen.Write("wrap_gen.go", renderWrap()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Streams are recorded as their content: io.Reader arguments are read before
+// the call and io.ReadCloser results are buffered after it.
+const (
+	readerType     = "io.Reader"
+	readCloserType = "io.ReadCloser"
+)
+
+func renderWrappers(t codegen.Target, methods []codegen.Method) []byte {
+	imports := map[string]bool{
+		codegen.ModulePath + "/" + t.Category: true,
+	}
+	for _, m := range methods {
+		for p := range m.Imports {
+			imports[p] = true
+		}
+		for _, r := range m.Results {
+			if r.Type == readCloserType {
+				imports["bytes"] = true
+			}
+		}
+	}
+
+	recorder := t.Category + "Recorder"
+	replayer := t.Category + "Replayer"
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("package replay\n\n")
+	codegen.WriteImports(&b, imports)
+
+	fmt.Fprintf(&b, "// %s records calls to %s.%s.\n", recorder, t.Category, t.Interface)
+	fmt.Fprintf(&b, "type %s struct {\n\tnext %s.%s\n\trec  *Recorder\n}\n\n", recorder, t.Category, t.Interface)
+	fmt.Fprintf(&b, "// Record%s wraps %s.%s so that every call is recorded to rec.\n", t.Wrapper, t.Category, t.Interface)
+	fmt.Fprintf(&b, "func Record%s(p %s.%s, rec *Recorder) %s.%s {\n", t.Wrapper, t.Category, t.Interface, t.Category, t.Interface)
+	fmt.Fprintf(&b, "\treturn &%s{next: p, rec: rec}\n}\n\n", recorder)
+	fmt.Fprintf(&b, "// Close saves the recording, see Recorder.Close.\n")
+	fmt.Fprintf(&b, "func (p *%s) Close(ctx context.Context) error {\n\treturn p.rec.Close(ctx)\n}\n", recorder)
+	for _, m := range methods {
+		b.WriteString("\n")
+		renderRecorderMethod(&b, recorder, m)
+	}
+
+	fmt.Fprintf(&b, "\n// %s serves recorded calls to %s.%s.\n", replayer, t.Category, t.Interface)
+	fmt.Fprintf(&b, "type %s struct {\n\tplayer *Player\n}\n\n", replayer)
+	fmt.Fprintf(&b, "// Replay%s returns a %s.%s that answers every call from player.\n", t.Wrapper, t.Category, t.Interface)
+	fmt.Fprintf(&b, "func Replay%s(player *Player) %s.%s {\n", t.Wrapper, t.Category, t.Interface)
+	fmt.Fprintf(&b, "\treturn &%s{player: player}\n}\n\n", replayer)
+	fmt.Fprintf(&b, "// Close reports recorded calls that were not replayed, see Player.Close.\n")
+	fmt.Fprintf(&b, "func (p *%s) Close(ctx context.Context) error {\n\treturn p.player.Close(ctx)\n}\n", replayer)
+	for _, m := range methods {
+		b.WriteString("\n")
+		renderReplayerMethod(&b, replayer, m)
+	}
+
+	return codegen.Format(b.Bytes())
+}
+
+// signature renders the method signature with named results r0, r1, ... and err.
+func signature(typeName string, m codegen.Method) string {
+	var params, results []string
+	for _, p := range m.Params {
+		params = append(params, p.Name+" "+p.Type)
+	}
+	for i, r := range m.Results {
+		results = append(results, resultName(m, i)+" "+r.Type)
+	}
+	return fmt.Sprintf("func (p *%s) %s(%s) (%s) {\n", typeName, m.Name, strings.Join(params, ", "), strings.Join(results, ", "))
+}
+
+func resultName(m codegen.Method, i int) string {
+	if m.Contextual() && i == len(m.Results)-1 {
+		return "err"
+	}
+	return fmt.Sprintf("r%d", i)
+}
+
+// recordedArgs reads stream arguments and returns the values to record,
+// skipping the context.
+func recordedArgs(b *bytes.Buffer, m codegen.Method) []string {
+	var args []string
+	for i, p := range m.Params[1:] {
+		if p.Type != readerType {
+			args = append(args, p.Name)
+			continue
+		}
+		data := fmt.Sprintf("data%d", i)
+		fmt.Fprintf(b, "\t%s, err := io.ReadAll(%s)\n\tif err != nil {\n\t\treturn\n\t}\n", data, p.Name)
+		args = append(args, data)
+	}
+	return args
+}
+
+func renderRecorderMethod(b *bytes.Buffer, typeName string, m codegen.Method) {
+	b.WriteString(signature(typeName, m))
+
+	var names, args []string
+	for i := range m.Results {
+		names = append(names, resultName(m, i))
+	}
+	for _, p := range m.Params {
+		args = append(args, p.Name)
+	}
+
+	if !m.Contextual() {
+		fmt.Fprintf(b, "\t%s = p.next.%s(%s)\n", strings.Join(names, ", "), m.Name, strings.Join(args, ", "))
+		fmt.Fprintf(b, "\tp.rec.recordStatic(%q, %s)\n\treturn\n}\n", m.Name, strings.Join(names, ", "))
+		return
+	}
+
+	recorded := recordedArgs(b, m)
+	for i, p := range m.Params[1:] {
+		if p.Type == readerType {
+			fmt.Fprintf(b, "\t%s = bytes.NewReader(data%d)\n", p.Name, i)
+		}
+	}
+	fmt.Fprintf(b, "\t%s = p.next.%s(%s)\n", strings.Join(names, ", "), m.Name, strings.Join(args, ", "))
+
+	results := names[:len(names)-1]
+	for i, r := range m.Results[:len(m.Results)-1] {
+		if r.Type == readCloserType {
+			body := fmt.Sprintf("body%d", i)
+			fmt.Fprintf(b, "\tvar %s []byte\n\t%s, %s, err = buffer(%s, err)\n", body, names[i], body, names[i])
+			results[i] = body
+		}
+	}
+	fmt.Fprintf(b, "\tp.rec.record(%q, []any{%s}, []any{%s}, err)\n\treturn\n}\n",
+		m.Name, strings.Join(recorded, ", "), strings.Join(results, ", "))
+}
+
+func renderReplayerMethod(b *bytes.Buffer, typeName string, m codegen.Method) {
+	b.WriteString(signature(typeName, m))
+
+	var targets []string
+	for i := range m.Results {
+		targets = append(targets, "&"+resultName(m, i))
+	}
+
+	if !m.Contextual() {
+		fmt.Fprintf(b, "\tp.player.playStatic(%q, %s)\n\treturn\n}\n", m.Name, strings.Join(targets, ", "))
+		return
+	}
+
+	recorded := recordedArgs(b, m)
+	targets = targets[:len(targets)-1]
+	var streams []int
+	for i, r := range m.Results[:len(m.Results)-1] {
+		if r.Type == readCloserType {
+			fmt.Fprintf(b, "\tvar body%d []byte\n", i)
+			targets[i] = fmt.Sprintf("&body%d", i)
+			streams = append(streams, i)
+		}
+	}
+	ctxName := m.Params[0].Name
+	fmt.Fprintf(b, "\terr = p.player.play(%s, %q, []any{%s}, %s)\n", ctxName, m.Name, strings.Join(recorded, ", "), strings.Join(targets, ", "))
+	for _, i := range streams {
+		fmt.Fprintf(b, "\tif err == nil {\n\t\tr%d = io.NopCloser(bytes.NewReader(body%d))\n\t}\n", i, i)
+	}
+	b.WriteString("\treturn\n}\n")
+}
+
+func renderWrap() []byte {
+	imports := map[string]bool{
+		"fmt":              true,
+		codegen.ModulePath: true,
+	}
+	for _, t := range codegen.Targets {
+		imports[codegen.ModulePath+"/"+t.Category] = true
+	}
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("package replay\n\n")
+	codegen.WriteImports(&b, imports)
+
+	b.WriteString("// Record wraps a provider of the given category so that its calls are recorded to rec.\n")
+	b.WriteString("// Values that do not implement the category's interface are returned unchanged.\n")
+	b.WriteString("func Record(category string, instance any, rec *Recorder) any {\n\tswitch category {\n")
+	for _, t := range codegen.Targets {
+		fmt.Fprintf(&b, "\tcase %q:\n", t.Category)
+		fmt.Fprintf(&b, "\t\tif p, ok := instance.(%s.%s); ok {\n", t.Category, t.Interface)
+		fmt.Fprintf(&b, "\t\t\treturn Record%s(p, rec)\n\t\t}\n", t.Wrapper)
+	}
+	b.WriteString("\t}\n\treturn instance\n}\n\n")
+
+	b.WriteString("// Replay returns the replay provider of the given category, serving calls from player.\n")
+	b.WriteString("func Replay(category string, player *Player) (any, error) {\n\tswitch category {\n")
+	for _, t := range codegen.Targets {
+		fmt.Fprintf(&b, "\tcase %q:\n\t\treturn Replay%s(player), nil\n", t.Category, t.Wrapper)
+	}
+	b.WriteString("\t}\n\treturn nil, fmt.Errorf(\"replay: unknown provider category %s\", category)\n}\n\n")
+
+	b.WriteString("// categories lists the categories the replay provider can be registered in.\n")
+	b.WriteString("var categories = []string{\n")
+	for _, t := range codegen.Targets {
+		fmt.Fprintf(&b, "\t%q,\n", t.Category)
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// register registers the replay provider in category and reports whether the category is known.\n")
+	b.WriteString("func register(category string) bool {\n\tswitch category {\n")
+	for _, t := range codegen.Targets {
+		fmt.Fprintf(&b, "\tcase %q:\n", t.Category)
+		fmt.Fprintf(&b, "\t\tprovider.Register[%s.%s](%q, Name, metadata(%q),\n", t.Category, t.Interface, t.Category, t.Category)
+		fmt.Fprintf(&b, "\t\t\tfunc(config map[string]any) (%s.%s, error) {\n", t.Category, t.Interface)
+		fmt.Fprintf(&b, "\t\t\t\tplayer, err := newPlayer(%q, config)\n", t.Category)
+		b.WriteString("\t\t\t\tif err != nil {\n\t\t\t\t\treturn nil, err\n\t\t\t\t}\n")
+		fmt.Fprintf(&b, "\t\t\t\treturn Replay%s(player), nil\n\t\t\t},\n\t\t)\n\t\treturn true\n", t.Wrapper)
+	}
+	b.WriteString("\t}\n\treturn false\n}\n")
+
+	return codegen.Format(b.Bytes())
+}
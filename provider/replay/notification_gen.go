@@ -0,0 +1,84 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/notification"
+)
+
+// notificationRecorder records calls to notification.NotificationProvider.
+type notificationRecorder struct {
+	next notification.NotificationProvider
+	rec  *Recorder
+}
+
+// RecordNotification wraps notification.NotificationProvider so that every call is recorded to rec.
+func RecordNotification(p notification.NotificationProvider, rec *Recorder) notification.NotificationProvider {
+	return &notificationRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *notificationRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *notificationRecorder) Send(ctx context.Context, msg notification.Message) (r0 *notification.SendResult, err error) {
+	r0, err = p.next.Send(ctx, msg)
+	p.rec.record("Send", []any{msg}, []any{r0}, err)
+	return
+}
+
+func (p *notificationRecorder) SendBatch(ctx context.Context, msgs []notification.Message) (r0 []notification.SendResult, err error) {
+	r0, err = p.next.SendBatch(ctx, msgs)
+	p.rec.record("SendBatch", []any{msgs}, []any{r0}, err)
+	return
+}
+
+func (p *notificationRecorder) Channels() (r0 []string) {
+	r0 = p.next.Channels()
+	p.rec.recordStatic("Channels", r0)
+	return
+}
+
+func (p *notificationRecorder) Metadata() (r0 notification.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// notificationReplayer serves recorded calls to notification.NotificationProvider.
+type notificationReplayer struct {
+	player *Player
+}
+
+// ReplayNotification returns a notification.NotificationProvider that answers every call from player.
+func ReplayNotification(player *Player) notification.NotificationProvider {
+	return &notificationReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *notificationReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *notificationReplayer) Send(ctx context.Context, msg notification.Message) (r0 *notification.SendResult, err error) {
+	err = p.player.play(ctx, "Send", []any{msg}, &r0)
+	return
+}
+
+func (p *notificationReplayer) SendBatch(ctx context.Context, msgs []notification.Message) (r0 []notification.SendResult, err error) {
+	err = p.player.play(ctx, "SendBatch", []any{msgs}, &r0)
+	return
+}
+
+func (p *notificationReplayer) Channels() (r0 []string) {
+	p.player.playStatic("Channels", &r0)
+	return
+}
+
+func (p *notificationReplayer) Metadata() (r0 notification.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,117 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/payment"
+)
+
+// paymentRecorder records calls to payment.PaymentProvider.
+type paymentRecorder struct {
+	next payment.PaymentProvider
+	rec  *Recorder
+}
+
+// RecordPayment wraps payment.PaymentProvider so that every call is recorded to rec.
+func RecordPayment(p payment.PaymentProvider, rec *Recorder) payment.PaymentProvider {
+	return &paymentRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *paymentRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *paymentRecorder) Initialize(ctx context.Context, req payment.InitializeRequest) (r0 *payment.PaymentSession, err error) {
+	r0, err = p.next.Initialize(ctx, req)
+	p.rec.record("Initialize", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *paymentRecorder) Authorize(ctx context.Context, sessionID string) (r0 *payment.AuthorizationResult, err error) {
+	r0, err = p.next.Authorize(ctx, sessionID)
+	p.rec.record("Authorize", []any{sessionID}, []any{r0}, err)
+	return
+}
+
+func (p *paymentRecorder) Capture(ctx context.Context, transactionID string, amount *payment.Amount) (r0 *payment.CaptureResult, err error) {
+	r0, err = p.next.Capture(ctx, transactionID, amount)
+	p.rec.record("Capture", []any{transactionID, amount}, []any{r0}, err)
+	return
+}
+
+func (p *paymentRecorder) Cancel(ctx context.Context, transactionID string) (err error) {
+	err = p.next.Cancel(ctx, transactionID)
+	p.rec.record("Cancel", []any{transactionID}, []any{}, err)
+	return
+}
+
+func (p *paymentRecorder) Refund(ctx context.Context, transactionID string, amount payment.Amount) (r0 *payment.RefundResult, err error) {
+	r0, err = p.next.Refund(ctx, transactionID, amount)
+	p.rec.record("Refund", []any{transactionID, amount}, []any{r0}, err)
+	return
+}
+
+func (p *paymentRecorder) HandleWebhook(ctx context.Context, payload []byte, headers map[string]string) (r0 *payment.WebhookEvent, err error) {
+	r0, err = p.next.HandleWebhook(ctx, payload, headers)
+	p.rec.record("HandleWebhook", []any{payload, headers}, []any{r0}, err)
+	return
+}
+
+func (p *paymentRecorder) Metadata() (r0 payment.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// paymentReplayer serves recorded calls to payment.PaymentProvider.
+type paymentReplayer struct {
+	player *Player
+}
+
+// ReplayPayment returns a payment.PaymentProvider that answers every call from player.
+func ReplayPayment(player *Player) payment.PaymentProvider {
+	return &paymentReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *paymentReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *paymentReplayer) Initialize(ctx context.Context, req payment.InitializeRequest) (r0 *payment.PaymentSession, err error) {
+	err = p.player.play(ctx, "Initialize", []any{req}, &r0)
+	return
+}
+
+func (p *paymentReplayer) Authorize(ctx context.Context, sessionID string) (r0 *payment.AuthorizationResult, err error) {
+	err = p.player.play(ctx, "Authorize", []any{sessionID}, &r0)
+	return
+}
+
+func (p *paymentReplayer) Capture(ctx context.Context, transactionID string, amount *payment.Amount) (r0 *payment.CaptureResult, err error) {
+	err = p.player.play(ctx, "Capture", []any{transactionID, amount}, &r0)
+	return
+}
+
+func (p *paymentReplayer) Cancel(ctx context.Context, transactionID string) (err error) {
+	err = p.player.play(ctx, "Cancel", []any{transactionID})
+	return
+}
+
+func (p *paymentReplayer) Refund(ctx context.Context, transactionID string, amount payment.Amount) (r0 *payment.RefundResult, err error) {
+	err = p.player.play(ctx, "Refund", []any{transactionID, amount}, &r0)
+	return
+}
+
+func (p *paymentReplayer) HandleWebhook(ctx context.Context, payload []byte, headers map[string]string) (r0 *payment.WebhookEvent, err error) {
+	err = p.player.play(ctx, "HandleWebhook", []any{payload, headers}, &r0)
+	return
+}
+
+func (p *paymentReplayer) Metadata() (r0 payment.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,147 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gondolia/gondolia/provider/pim"
+)
+
+// pimRecorder records calls to pim.PIMProvider.
+type pimRecorder struct {
+	next pim.PIMProvider
+	rec  *Recorder
+}
+
+// RecordPIM wraps pim.PIMProvider so that every call is recorded to rec.
+func RecordPIM(p pim.PIMProvider, rec *Recorder) pim.PIMProvider {
+	return &pimRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *pimRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *pimRecorder) FetchProducts(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductPage, err error) {
+	r0, err = p.next.FetchProducts(ctx, filter)
+	p.rec.record("FetchProducts", []any{filter}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchProduct(ctx context.Context, identifier string) (r0 *pim.Product, err error) {
+	r0, err = p.next.FetchProduct(ctx, identifier)
+	p.rec.record("FetchProduct", []any{identifier}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductModelPage, err error) {
+	r0, err = p.next.FetchProductModels(ctx, filter)
+	p.rec.record("FetchProductModels", []any{filter}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchProductModel(ctx context.Context, code string) (r0 *pim.ProductModel, err error) {
+	r0, err = p.next.FetchProductModel(ctx, code)
+	p.rec.record("FetchProductModel", []any{code}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchFamilyVariants(ctx context.Context) (r0 []pim.FamilyVariant, err error) {
+	r0, err = p.next.FetchFamilyVariants(ctx)
+	p.rec.record("FetchFamilyVariants", []any{}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchCategories(ctx context.Context) (r0 []pim.Category, err error) {
+	r0, err = p.next.FetchCategories(ctx)
+	p.rec.record("FetchCategories", []any{}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) FetchAttributes(ctx context.Context) (r0 []pim.Attribute, err error) {
+	r0, err = p.next.FetchAttributes(ctx)
+	p.rec.record("FetchAttributes", []any{}, []any{r0}, err)
+	return
+}
+
+func (p *pimRecorder) DownloadAsset(ctx context.Context, assetCode string) (r0 io.ReadCloser, r1 string, err error) {
+	r0, r1, err = p.next.DownloadAsset(ctx, assetCode)
+	var body0 []byte
+	r0, body0, err = buffer(r0, err)
+	p.rec.record("DownloadAsset", []any{assetCode}, []any{body0, r1}, err)
+	return
+}
+
+func (p *pimRecorder) Metadata() (r0 pim.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// pimReplayer serves recorded calls to pim.PIMProvider.
+type pimReplayer struct {
+	player *Player
+}
+
+// ReplayPIM returns a pim.PIMProvider that answers every call from player.
+func ReplayPIM(player *Player) pim.PIMProvider {
+	return &pimReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *pimReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *pimReplayer) FetchProducts(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductPage, err error) {
+	err = p.player.play(ctx, "FetchProducts", []any{filter}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchProduct(ctx context.Context, identifier string) (r0 *pim.Product, err error) {
+	err = p.player.play(ctx, "FetchProduct", []any{identifier}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductModelPage, err error) {
+	err = p.player.play(ctx, "FetchProductModels", []any{filter}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchProductModel(ctx context.Context, code string) (r0 *pim.ProductModel, err error) {
+	err = p.player.play(ctx, "FetchProductModel", []any{code}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchFamilyVariants(ctx context.Context) (r0 []pim.FamilyVariant, err error) {
+	err = p.player.play(ctx, "FetchFamilyVariants", []any{}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchCategories(ctx context.Context) (r0 []pim.Category, err error) {
+	err = p.player.play(ctx, "FetchCategories", []any{}, &r0)
+	return
+}
+
+func (p *pimReplayer) FetchAttributes(ctx context.Context) (r0 []pim.Attribute, err error) {
+	err = p.player.play(ctx, "FetchAttributes", []any{}, &r0)
+	return
+}
+
+func (p *pimReplayer) DownloadAsset(ctx context.Context, assetCode string) (r0 io.ReadCloser, r1 string, err error) {
+	var body0 []byte
+	err = p.player.play(ctx, "DownloadAsset", []any{assetCode}, &body0, &r1)
+	if err == nil {
+		r0 = io.NopCloser(bytes.NewReader(body0))
+	}
+	return
+}
+
+func (p *pimReplayer) Metadata() (r0 pim.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
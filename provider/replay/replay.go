@@ -0,0 +1,577 @@
+// Package replay records provider calls to JSON fixtures and serves them back.
+//
+// A recording captures every call to a provider with its arguments, results and
+// error. Record a session once against a real system, e.g. an SAP or Akeneo
+// sandbox, and run the same flows offline against the replay provider:
+//
+//	resolver.Use(replay.Decorator("testdata/fixtures"))
+//	// ... run catalog sync, checkout, ...
+//	resolver.Close(ctx) // writes testdata/fixtures/erp-sap.json, ...
+//
+// The replay provider is registered under the name "replay" by Register:
+//
+//	replay.Register("erp", "pim")
+//
+//	defaults:
+//	  erp:
+//	    name: replay
+//	    config:
+//	      fixture: testdata/fixtures/erp-sap.json
+//	      strict: true
+//
+// A call is answered by the first recorded call of the same method with equal
+// arguments. A call without such a recording fails with a *MismatchError that
+// lists the differences to the closest recorded call. Without strict, recorded
+// calls may be replayed any number of times; with strict, each recorded call is
+// served once and Close reports the calls that were never replayed.
+//
+// Fixtures contain the data exchanged with the external system. Do not record
+// against production systems or commit fixtures containing credentials.
+//
+// The wrappers are generated from the provider interfaces; run go generate
+// after changing an interface.
+package replay
+
+//go:generate go run ./gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+// Name is the registry name of the replay provider.
+const Name = "replay"
+
+var (
+	registerMu sync.Mutex
+	registered = make(map[string]bool)
+)
+
+// Register registers the replay provider in the given categories, or in every
+// category if none are given. Categories that are already registered are
+// skipped; an unknown category panics.
+//
+// Unlike other providers, replay does not register itself on import: services
+// would otherwise gain every category, and provider config files validate all
+// categories that have registered providers.
+func Register(categories ...string) {
+	if len(categories) == 0 {
+		categories = allCategories()
+	}
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	for _, category := range categories {
+		if registered[category] {
+			continue
+		}
+		if !register(category) {
+			panic(fmt.Sprintf("replay: unknown provider category %s", category))
+		}
+		registered[category] = true
+	}
+}
+
+func allCategories() []string {
+	return append([]string(nil), categories...)
+}
+
+// Fixture is a recorded provider session.
+type Fixture struct {
+	Category   string    `json:"category"`
+	Provider   string    `json:"provider"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Calls      []Call    `json:"calls"`
+	// Static holds the results of methods without a context, e.g. Metadata.
+	Static map[string][]json.RawMessage `json:"static,omitempty"`
+}
+
+// Call is one recorded method call. Arguments exclude the context and results
+// exclude the error. Streams are recorded as their content.
+type Call struct {
+	Method  string            `json:"method"`
+	Args    []json.RawMessage `json:"args"`
+	Results []json.RawMessage `json:"results,omitempty"`
+	Error   *CallError        `json:"error,omitempty"`
+}
+
+// CallError is a recorded error. Is names the well-known errors it matched, so
+// that errors.Is keeps working on replayed errors.
+type CallError struct {
+	Message string   `json:"message"`
+	Is      []string `json:"is,omitempty"`
+}
+
+// knownErrors are the errors preserved across recording and replay.
+var knownErrors = []struct {
+	name string
+	err  error
+}{
+	{"not_found", provider.ErrNotFound},
+	{"invalid_argument", provider.ErrInvalidArgument},
+	{"unsupported", provider.ErrUnsupported},
+	{"canceled", context.Canceled},
+	{"deadline_exceeded", context.DeadlineExceeded},
+	{"search_index_not_found", search.ErrIndexNotFound},
+	{"search_invalid_filter", search.ErrInvalidFilter},
+	{"auth_invalid_token", auth.ErrInvalidToken},
+}
+
+func newCallError(err error) *CallError {
+	if err == nil {
+		return nil
+	}
+	ce := &CallError{Message: err.Error()}
+	for _, k := range knownErrors {
+		if errors.Is(err, k.err) {
+			ce.Is = append(ce.Is, k.name)
+		}
+	}
+	return ce
+}
+
+// replayedError is a recorded error that matches the errors it matched when recorded.
+type replayedError struct {
+	msg string
+	is  []error
+}
+
+func (e *replayedError) Error() string   { return e.msg }
+func (e *replayedError) Unwrap() []error { return e.is }
+
+func (ce *CallError) err() error {
+	if ce == nil {
+		return nil
+	}
+	e := &replayedError{msg: ce.Message}
+	for _, name := range ce.Is {
+		for _, k := range knownErrors {
+			if k.name == name {
+				e.is = append(e.is, k.err)
+			}
+		}
+	}
+	return e
+}
+
+// LoadFixture reads a fixture written by Recorder.Save.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Recorder collects the calls of one provider instance. It is safe for
+// concurrent use; calls are stored in the order they complete.
+type Recorder struct {
+	path string // written on Close if set
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a recorder for a provider of the given category and name.
+func NewRecorder(category, providerName string) *Recorder {
+	return &Recorder{fixture: Fixture{
+		Category:   category,
+		Provider:   providerName,
+		RecordedAt: time.Now().UTC(),
+		Calls:      []Call{},
+	}}
+}
+
+// Fixture returns a copy of the calls recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.fixture
+	f.Calls = append([]Call(nil), r.fixture.Calls...)
+	if r.fixture.Static != nil {
+		f.Static = make(map[string][]json.RawMessage, len(r.fixture.Static))
+		for method, results := range r.fixture.Static {
+			f.Static[method] = results
+		}
+	}
+	return &f
+}
+
+// Save writes the recording to path as indented JSON.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Fixture(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	return nil
+}
+
+// Close saves the recording if the recorder was created by Decorator. The
+// Resolver closes decorators with their instance, so recordings are written
+// when the resolver is closed or the instance is replaced.
+func (r *Recorder) Close(ctx context.Context) error {
+	if r.path == "" {
+		return nil
+	}
+	return r.Save(r.path)
+}
+
+func (r *Recorder) record(method string, args, results []any, err error) {
+	call := Call{Method: method, Args: encodeAll(args), Results: encodeAll(results), Error: newCallError(err)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Calls = append(r.fixture.Calls, call)
+}
+
+func (r *Recorder) recordStatic(method string, results ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fixture.Static == nil {
+		r.fixture.Static = make(map[string][]json.RawMessage)
+	}
+	r.fixture.Static[method] = encodeAll(results)
+}
+
+// encodeAll encodes values at the time of the call, so later changes by the
+// caller do not leak into the recording.
+func encodeAll(values []any) []json.RawMessage {
+	encoded := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			data, _ = json.Marshal("unencodable value: " + err.Error())
+		}
+		encoded[i] = data
+	}
+	return encoded
+}
+
+// buffer reads a stream result fully, so its content can be recorded, and
+// returns a replacement stream for the caller.
+func buffer(rc io.ReadCloser, err error) (io.ReadCloser, []byte, error) {
+	if err != nil || rc == nil {
+		return rc, nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), data, nil
+}
+
+// Decorator returns a provider.Decorator that records every resolved provider.
+// Each instance is written to dir as <category>-<provider>.json when it is
+// closed; further instances of the same provider get a numeric suffix.
+// Add it first so the recording holds the calls of the provider itself,
+// including retries:
+//
+//	resolver.Use(replay.Decorator(dir), resilience.Decorator(nil), tracing.Decorator())
+func Decorator(dir string) provider.Decorator {
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	return func(category string, sel provider.Selection, instance any) (any, error) {
+		if sel.Name == Name {
+			return instance, nil
+		}
+		base := category + "-" + sel.Name
+		mu.Lock()
+		seen[base]++
+		n := seen[base]
+		mu.Unlock()
+
+		file := base + ".json"
+		if n > 1 {
+			file = fmt.Sprintf("%s-%d.json", base, n)
+		}
+		rec := NewRecorder(category, sel.Name)
+		rec.path = filepath.Join(dir, file)
+		return Record(category, instance, rec), nil
+	}
+}
+
+// MismatchError is returned by a replay provider for a call that was not recorded.
+type MismatchError struct {
+	Category string
+	Method   string
+	Reason   string
+	Diff     []string // Differences to the closest recorded call, if any
+}
+
+func (e *MismatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "replay %s.%s: %s", e.Category, e.Method, e.Reason)
+	for _, d := range e.Diff {
+		b.WriteString("\n  ")
+		b.WriteString(d)
+	}
+	return b.String()
+}
+
+// Player answers calls from a fixture. It is safe for concurrent use.
+type Player struct {
+	fixture *Fixture
+	strict  bool
+
+	mu   sync.Mutex
+	used []bool
+	args [][]any // decoded arguments of each recorded call
+}
+
+// NewPlayer returns a player for fixture. With strict, every recorded call is
+// served at most once and Close fails if calls were not replayed.
+func NewPlayer(fixture *Fixture, strict bool) *Player {
+	p := &Player{
+		fixture: fixture,
+		strict:  strict,
+		used:    make([]bool, len(fixture.Calls)),
+		args:    make([][]any, len(fixture.Calls)),
+	}
+	for i, call := range fixture.Calls {
+		p.args[i] = decodeAll(call.Args)
+	}
+	return p
+}
+
+func newPlayer(category string, config map[string]any) (*Player, error) {
+	path, _ := config["fixture"].(string)
+	strict, _ := config["strict"].(bool)
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	if fixture.Category != category {
+		return nil, fmt.Errorf("fixture %s was recorded for %s, not %s: %w", path, fixture.Category, category, provider.ErrInvalidArgument)
+	}
+	return NewPlayer(fixture, strict), nil
+}
+
+func metadata(category string) provider.Metadata {
+	return provider.Metadata{
+		Name:        Name,
+		DisplayName: "Replay",
+		Category:    category,
+		Version:     "1.0.0",
+		Description: "Serves provider calls recorded to a JSON fixture",
+		ConfigSpec: []provider.ConfigField{
+			{Key: "fixture", Type: "string", Required: true, Description: "Path of the recorded fixture"},
+			{Key: "strict", Type: "bool", Default: false, Description: "Serve each recorded call once and fail on close if calls were not replayed"},
+		},
+	}
+}
+
+// Unused returns the recorded calls that were not replayed.
+func (p *Player) Unused() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var unused []Call
+	for i, call := range p.fixture.Calls {
+		if !p.used[i] {
+			unused = append(unused, call)
+		}
+	}
+	return unused
+}
+
+// Verify returns an error listing the recorded calls that were not replayed.
+func (p *Player) Verify() error {
+	unused := p.Unused()
+	if len(unused) == 0 {
+		return nil
+	}
+	methods := make([]string, len(unused))
+	for i, call := range unused {
+		methods[i] = call.Method
+	}
+	return fmt.Errorf("replay %s: %d recorded calls were not replayed: %s",
+		p.fixture.Category, len(unused), strings.Join(methods, ", "))
+}
+
+// Close verifies the replay in strict mode and does nothing otherwise.
+func (p *Player) Close(ctx context.Context) error {
+	if !p.strict {
+		return nil
+	}
+	return p.Verify()
+}
+
+// play answers a call by decoding the results of the matching recorded call
+// into results, which point to the method's result values.
+func (p *Player) play(ctx context.Context, method string, args []any, results ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	got := decodeAll(encodeAll(args))
+
+	call, err := p.match(method, got)
+	if err != nil {
+		return err
+	}
+	for i, target := range results {
+		if i >= len(call.Results) {
+			break
+		}
+		if err := json.Unmarshal(call.Results[i], target); err != nil {
+			return fmt.Errorf("replay %s.%s: decode result %d: %w", p.fixture.Category, method, i, err)
+		}
+	}
+	return call.Error.err()
+}
+
+// playStatic answers a method without context. Missing recordings leave the
+// zero values.
+func (p *Player) playStatic(method string, results ...any) {
+	recorded := p.fixture.Static[method]
+	for i, target := range results {
+		if i < len(recorded) {
+			json.Unmarshal(recorded[i], target)
+		}
+	}
+}
+
+func (p *Player) match(method string, args []any) (*Call, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	replayed := -1
+	for i, call := range p.fixture.Calls {
+		if call.Method != method || !reflect.DeepEqual(p.args[i], args) {
+			continue
+		}
+		if !p.used[i] {
+			p.used[i] = true
+			return &p.fixture.Calls[i], nil
+		}
+		replayed = i
+	}
+	if replayed >= 0 && !p.strict {
+		return &p.fixture.Calls[replayed], nil
+	}
+
+	mismatch := &MismatchError{Category: p.fixture.Category, Method: method}
+	if replayed >= 0 {
+		mismatch.Reason = "all recorded calls with these arguments have been replayed"
+		return nil, mismatch
+	}
+
+	// Compare with the recorded call of the same method that differs least,
+	// preferring calls that were not replayed yet
+	var closest []string
+	found := false
+	for _, unusedOnly := range []bool{true, false} {
+		for i, call := range p.fixture.Calls {
+			if call.Method != method || (unusedOnly && p.used[i]) {
+				continue
+			}
+			var d []string
+			diffValues(&d, "args", p.args[i], args)
+			if !found || len(d) < len(closest) {
+				closest = d
+			}
+			found = true
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		mismatch.Reason = "no call was recorded"
+		return nil, mismatch
+	}
+	mismatch.Reason = "arguments do not match the recording"
+	mismatch.Diff = closest
+	return nil, mismatch
+}
+
+func decodeAll(raw []json.RawMessage) []any {
+	values := make([]any, len(raw))
+	for i, r := range raw {
+		json.Unmarshal(r, &values[i])
+	}
+	return values
+}
+
+// diffValues appends the differences between the decoded JSON values want and
+// got to diffs, e.g. `args[0].SKUs[1]: recorded "4711", got "4712"`.
+func diffValues(diffs *[]string, path string, want, got any) {
+	switch w := want.(type) {
+	case map[string]any:
+		if g, ok := got.(map[string]any); ok {
+			keys := make(map[string]bool, len(w)+len(g))
+			for k := range w {
+				keys[k] = true
+			}
+			for k := range g {
+				keys[k] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for k := range keys {
+				sorted = append(sorted, k)
+			}
+			sort.Strings(sorted)
+			for _, k := range sorted {
+				wv, inWant := w[k]
+				gv, inGot := g[k]
+				switch {
+				case !inWant:
+					*diffs = append(*diffs, fmt.Sprintf("%s.%s: not recorded, got %s", path, k, jsonString(gv)))
+				case !inGot:
+					*diffs = append(*diffs, fmt.Sprintf("%s.%s: recorded %s, missing", path, k, jsonString(wv)))
+				default:
+					diffValues(diffs, path+"."+k, wv, gv)
+				}
+			}
+			return
+		}
+	case []any:
+		if g, ok := got.([]any); ok {
+			for i := 0; i < len(w) || i < len(g); i++ {
+				elem := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(w):
+					*diffs = append(*diffs, fmt.Sprintf("%s: not recorded, got %s", elem, jsonString(g[i])))
+				case i >= len(g):
+					*diffs = append(*diffs, fmt.Sprintf("%s: recorded %s, missing", elem, jsonString(w[i])))
+				default:
+					diffValues(diffs, elem, w[i], g[i])
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(want, got) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: recorded %s, got %s", path, jsonString(want), jsonString(got)))
+	}
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,189 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	erpnoop "github.com/gondolia/gondolia/provider/erp/noop"
+	"github.com/gondolia/gondolia/provider/storage"
+	storagenoop "github.com/gondolia/gondolia/provider/storage/noop"
+)
+
+// recordERP records a short ERP session and returns the fixture path.
+func recordERP(t *testing.T) string {
+	t.Helper()
+	inner, err := erpnoop.NewProvider(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := NewRecorder("erp", "noop")
+	p := RecordERP(inner, rec)
+	ctx := context.Background()
+
+	if _, err := p.GetProductAvailability(ctx, []string{"4711", "4712"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.SyncCompany(ctx, ""); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Fatalf("SyncCompany() error = %v", err)
+	}
+	p.Metadata()
+
+	path := filepath.Join(t.TempDir(), "erp-noop.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func replayERP(t *testing.T, path string, strict bool) erp.ERPProvider {
+	t.Helper()
+	Register("erp")
+	factory, err := provider.Get[erp.ERPProvider]("erp", Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := factory(map[string]any{"fixture": path, "strict": strict})
+	if err != nil {
+		t.Fatalf("factory error = %v", err)
+	}
+	return p
+}
+
+func TestReplay_ServesRecordedCalls(t *testing.T) {
+	p := replayERP(t, recordERP(t), true)
+	ctx := context.Background()
+
+	stocks, err := p.GetProductAvailability(ctx, []string{"4711", "4712"})
+	if err != nil {
+		t.Fatalf("GetProductAvailability() error = %v", err)
+	}
+	if len(stocks) != 2 || stocks[1].SKU != "4712" {
+		t.Errorf("stocks = %+v, want the recorded stocks", stocks)
+	}
+	if _, err := p.SyncCompany(ctx, ""); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("SyncCompany() error = %v, want recorded ErrInvalidArgument", err)
+	}
+	if got := p.Metadata().Name; got != "noop" {
+		t.Errorf("Metadata().Name = %q, want the recorded name", got)
+	}
+	if err := provider.Close(ctx, p); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestReplay_MismatchShowsDiff(t *testing.T) {
+	p := replayERP(t, recordERP(t), false)
+
+	_, err := p.GetProductAvailability(context.Background(), []string{"4711", "4713", "4714"})
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("error = %v, want *MismatchError", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"replay erp.GetProductAvailability",
+		`args[0][1]: recorded "4712", got "4713"`,
+		`args[0][2]: not recorded, got "4714"`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error does not mention %q:\n%s", want, msg)
+		}
+	}
+
+	_, err = p.GetOrderStatus(context.Background(), "1")
+	if !errors.As(err, &mismatch) || mismatch.Reason != "no call was recorded" {
+		t.Errorf("GetOrderStatus() error = %v, want unrecorded method", err)
+	}
+}
+
+func TestReplay_StrictServesCallsOnce(t *testing.T) {
+	p := replayERP(t, recordERP(t), true)
+	ctx := context.Background()
+
+	if err := provider.Close(ctx, p); err == nil || !strings.Contains(err.Error(), "2 recorded calls were not replayed") {
+		t.Errorf("Close() error = %v, want unreplayed calls", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := p.GetProductAvailability(ctx, []string{"4711", "4712"})
+		if i == 0 && err != nil {
+			t.Fatalf("first call error = %v", err)
+		}
+		if i == 1 && err == nil {
+			t.Error("strict replay served a recorded call twice")
+		}
+	}
+}
+
+func TestReplay_RejectsFixtureOfOtherCategory(t *testing.T) {
+	Register("storage")
+	factory, err := provider.Get[storage.StorageProvider]("storage", Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := factory(map[string]any{"fixture": recordERP(t)}); !errors.Is(err, provider.ErrInvalidArgument) {
+		t.Errorf("factory error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+// contentStorage serves fixed content from Download.
+type contentStorage struct {
+	storage.StorageProvider
+	content string
+}
+
+func (s *contentStorage) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	return io.NopCloser(strings.NewReader(s.content)), &storage.FileInfo{Path: path}, nil
+}
+
+func TestDecorator_RecordsStreams(t *testing.T) {
+	inner, err := storagenoop.NewProvider(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	decorated, err := Decorator(dir)("storage", provider.Selection{Name: "s3"}, &contentStorage{StorageProvider: inner, content: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p := decorated.(storage.StorageProvider)
+
+	if _, err := p.Upload(ctx, "a.txt", strings.NewReader("uploaded"), storage.UploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	rc, _, err := p.Download(ctx, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(rc); string(data) != "hello" {
+		t.Errorf("recorded download = %q, want hello", data)
+	}
+	if err := provider.Close(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := LoadFixture(filepath.Join(dir, "storage-s3.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := ReplayStorage(NewPlayer(fixture, true))
+	if _, err := replayed.Upload(ctx, "a.txt", strings.NewReader("uploaded"), storage.UploadOptions{}); err != nil {
+		t.Errorf("Upload() error = %v", err)
+	}
+	rc, _, err = replayed.Download(ctx, "a.txt")
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if data, _ := io.ReadAll(rc); string(data) != "hello" {
+		t.Errorf("replayed download = %q, want hello", data)
+	}
+	if err := provider.Close(ctx, replayed); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+// searchRecorder records calls to search.SearchProvider.
+type searchRecorder struct {
+	next search.SearchProvider
+	rec  *Recorder
+}
+
+// RecordSearch wraps search.SearchProvider so that every call is recorded to rec.
+func RecordSearch(p search.SearchProvider, rec *Recorder) search.SearchProvider {
+	return &searchRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *searchRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *searchRecorder) IndexDocuments(ctx context.Context, index string, documents []search.Document) (r0 *search.TaskResult, err error) {
+	r0, err = p.next.IndexDocuments(ctx, index, documents)
+	p.rec.record("IndexDocuments", []any{index, documents}, []any{r0}, err)
+	return
+}
+
+func (p *searchRecorder) DeleteDocuments(ctx context.Context, index string, ids []string) (r0 *search.TaskResult, err error) {
+	r0, err = p.next.DeleteDocuments(ctx, index, ids)
+	p.rec.record("DeleteDocuments", []any{index, ids}, []any{r0}, err)
+	return
+}
+
+func (p *searchRecorder) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) (err error) {
+	err = p.next.ConfigureIndex(ctx, index, config)
+	p.rec.record("ConfigureIndex", []any{index, config}, []any{}, err)
+	return
+}
+
+func (p *searchRecorder) Search(ctx context.Context, index string, query search.SearchQuery) (r0 *search.SearchResult, err error) {
+	r0, err = p.next.Search(ctx, index, query)
+	p.rec.record("Search", []any{index, query}, []any{r0}, err)
+	return
+}
+
+func (p *searchRecorder) CreateIndex(ctx context.Context, index string, primaryKey string) (err error) {
+	err = p.next.CreateIndex(ctx, index, primaryKey)
+	p.rec.record("CreateIndex", []any{index, primaryKey}, []any{}, err)
+	return
+}
+
+func (p *searchRecorder) DeleteIndex(ctx context.Context, index string) (err error) {
+	err = p.next.DeleteIndex(ctx, index)
+	p.rec.record("DeleteIndex", []any{index}, []any{}, err)
+	return
+}
+
+func (p *searchRecorder) GetTaskStatus(ctx context.Context, taskID string) (r0 *search.TaskResult, err error) {
+	r0, err = p.next.GetTaskStatus(ctx, taskID)
+	p.rec.record("GetTaskStatus", []any{taskID}, []any{r0}, err)
+	return
+}
+
+func (p *searchRecorder) Health(ctx context.Context) (err error) {
+	err = p.next.Health(ctx)
+	p.rec.record("Health", []any{}, []any{}, err)
+	return
+}
+
+func (p *searchRecorder) Metadata() (r0 search.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// searchReplayer serves recorded calls to search.SearchProvider.
+type searchReplayer struct {
+	player *Player
+}
+
+// ReplaySearch returns a search.SearchProvider that answers every call from player.
+func ReplaySearch(player *Player) search.SearchProvider {
+	return &searchReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *searchReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *searchReplayer) IndexDocuments(ctx context.Context, index string, documents []search.Document) (r0 *search.TaskResult, err error) {
+	err = p.player.play(ctx, "IndexDocuments", []any{index, documents}, &r0)
+	return
+}
+
+func (p *searchReplayer) DeleteDocuments(ctx context.Context, index string, ids []string) (r0 *search.TaskResult, err error) {
+	err = p.player.play(ctx, "DeleteDocuments", []any{index, ids}, &r0)
+	return
+}
+
+func (p *searchReplayer) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) (err error) {
+	err = p.player.play(ctx, "ConfigureIndex", []any{index, config})
+	return
+}
+
+func (p *searchReplayer) Search(ctx context.Context, index string, query search.SearchQuery) (r0 *search.SearchResult, err error) {
+	err = p.player.play(ctx, "Search", []any{index, query}, &r0)
+	return
+}
+
+func (p *searchReplayer) CreateIndex(ctx context.Context, index string, primaryKey string) (err error) {
+	err = p.player.play(ctx, "CreateIndex", []any{index, primaryKey})
+	return
+}
+
+func (p *searchReplayer) DeleteIndex(ctx context.Context, index string) (err error) {
+	err = p.player.play(ctx, "DeleteIndex", []any{index})
+	return
+}
+
+func (p *searchReplayer) GetTaskStatus(ctx context.Context, taskID string) (r0 *search.TaskResult, err error) {
+	err = p.player.play(ctx, "GetTaskStatus", []any{taskID}, &r0)
+	return
+}
+
+func (p *searchReplayer) Health(ctx context.Context) (err error) {
+	err = p.player.play(ctx, "Health", []any{})
+	return
+}
+
+func (p *searchReplayer) Metadata() (r0 search.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,135 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// storageRecorder records calls to storage.StorageProvider.
+type storageRecorder struct {
+	next storage.StorageProvider
+	rec  *Recorder
+}
+
+// RecordStorage wraps storage.StorageProvider so that every call is recorded to rec.
+func RecordStorage(p storage.StorageProvider, rec *Recorder) storage.StorageProvider {
+	return &storageRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *storageRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *storageRecorder) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (r0 *storage.FileInfo, err error) {
+	data1, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	reader = bytes.NewReader(data1)
+	r0, err = p.next.Upload(ctx, path, reader, opts)
+	p.rec.record("Upload", []any{path, data1, opts}, []any{r0}, err)
+	return
+}
+
+func (p *storageRecorder) Download(ctx context.Context, path string) (r0 io.ReadCloser, r1 *storage.FileInfo, err error) {
+	r0, r1, err = p.next.Download(ctx, path)
+	var body0 []byte
+	r0, body0, err = buffer(r0, err)
+	p.rec.record("Download", []any{path}, []any{body0, r1}, err)
+	return
+}
+
+func (p *storageRecorder) Delete(ctx context.Context, path string) (err error) {
+	err = p.next.Delete(ctx, path)
+	p.rec.record("Delete", []any{path}, []any{}, err)
+	return
+}
+
+func (p *storageRecorder) Exists(ctx context.Context, path string) (r0 bool, err error) {
+	r0, err = p.next.Exists(ctx, path)
+	p.rec.record("Exists", []any{path}, []any{r0}, err)
+	return
+}
+
+func (p *storageRecorder) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (r0 string, err error) {
+	r0, err = p.next.GetSignedURL(ctx, path, expiry)
+	p.rec.record("GetSignedURL", []any{path, expiry}, []any{r0}, err)
+	return
+}
+
+func (p *storageRecorder) List(ctx context.Context, prefix string, opts storage.ListOptions) (r0 []storage.FileInfo, err error) {
+	r0, err = p.next.List(ctx, prefix, opts)
+	p.rec.record("List", []any{prefix, opts}, []any{r0}, err)
+	return
+}
+
+func (p *storageRecorder) Metadata() (r0 storage.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// storageReplayer serves recorded calls to storage.StorageProvider.
+type storageReplayer struct {
+	player *Player
+}
+
+// ReplayStorage returns a storage.StorageProvider that answers every call from player.
+func ReplayStorage(player *Player) storage.StorageProvider {
+	return &storageReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *storageReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *storageReplayer) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (r0 *storage.FileInfo, err error) {
+	data1, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	err = p.player.play(ctx, "Upload", []any{path, data1, opts}, &r0)
+	return
+}
+
+func (p *storageReplayer) Download(ctx context.Context, path string) (r0 io.ReadCloser, r1 *storage.FileInfo, err error) {
+	var body0 []byte
+	err = p.player.play(ctx, "Download", []any{path}, &body0, &r1)
+	if err == nil {
+		r0 = io.NopCloser(bytes.NewReader(body0))
+	}
+	return
+}
+
+func (p *storageReplayer) Delete(ctx context.Context, path string) (err error) {
+	err = p.player.play(ctx, "Delete", []any{path})
+	return
+}
+
+func (p *storageReplayer) Exists(ctx context.Context, path string) (r0 bool, err error) {
+	err = p.player.play(ctx, "Exists", []any{path}, &r0)
+	return
+}
+
+func (p *storageReplayer) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (r0 string, err error) {
+	err = p.player.play(ctx, "GetSignedURL", []any{path, expiry}, &r0)
+	return
+}
+
+func (p *storageReplayer) List(ctx context.Context, prefix string, opts storage.ListOptions) (r0 []storage.FileInfo, err error) {
+	err = p.player.play(ctx, "List", []any{prefix, opts}, &r0)
+	return
+}
+
+func (p *storageReplayer) Metadata() (r0 storage.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
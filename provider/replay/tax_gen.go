@@ -0,0 +1,62 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// taxRecorder records calls to tax.TaxProvider.
+type taxRecorder struct {
+	next tax.TaxProvider
+	rec  *Recorder
+}
+
+// RecordTax wraps tax.TaxProvider so that every call is recorded to rec.
+func RecordTax(p tax.TaxProvider, rec *Recorder) tax.TaxProvider {
+	return &taxRecorder{next: p, rec: rec}
+}
+
+// Close saves the recording, see Recorder.Close.
+func (p *taxRecorder) Close(ctx context.Context) error {
+	return p.rec.Close(ctx)
+}
+
+func (p *taxRecorder) CalculateTax(ctx context.Context, req tax.TaxRequest) (r0 *tax.TaxResult, err error) {
+	r0, err = p.next.CalculateTax(ctx, req)
+	p.rec.record("CalculateTax", []any{req}, []any{r0}, err)
+	return
+}
+
+func (p *taxRecorder) Metadata() (r0 tax.Metadata) {
+	r0 = p.next.Metadata()
+	p.rec.recordStatic("Metadata", r0)
+	return
+}
+
+// taxReplayer serves recorded calls to tax.TaxProvider.
+type taxReplayer struct {
+	player *Player
+}
+
+// ReplayTax returns a tax.TaxProvider that answers every call from player.
+func ReplayTax(player *Player) tax.TaxProvider {
+	return &taxReplayer{player: player}
+}
+
+// Close reports recorded calls that were not replayed, see Player.Close.
+func (p *taxReplayer) Close(ctx context.Context) error {
+	return p.player.Close(ctx)
+}
+
+func (p *taxReplayer) CalculateTax(ctx context.Context, req tax.TaxRequest) (r0 *tax.TaxResult, err error) {
+	err = p.player.play(ctx, "CalculateTax", []any{req}, &r0)
+	return
+}
+
+func (p *taxReplayer) Metadata() (r0 tax.Metadata) {
+	p.player.playStatic("Metadata", &r0)
+	return
+}
@@ -0,0 +1,225 @@
+// Code generated by provider/replay/gen. DO NOT EDIT.
+
+package replay
+
+import (
+	"fmt"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/crm"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/fulfillment"
+	"github.com/gondolia/gondolia/provider/notification"
+	"github.com/gondolia/gondolia/provider/payment"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// Record wraps a provider of the given category so that its calls are recorded to rec.
+// Values that do not implement the category's interface are returned unchanged.
+func Record(category string, instance any, rec *Recorder) any {
+	switch category {
+	case "auth":
+		if p, ok := instance.(auth.AuthProvider); ok {
+			return RecordAuth(p, rec)
+		}
+	case "crm":
+		if p, ok := instance.(crm.CRMProvider); ok {
+			return RecordCRM(p, rec)
+		}
+	case "erp":
+		if p, ok := instance.(erp.ERPProvider); ok {
+			return RecordERP(p, rec)
+		}
+	case "fulfillment":
+		if p, ok := instance.(fulfillment.FulfillmentProvider); ok {
+			return RecordFulfillment(p, rec)
+		}
+	case "notification":
+		if p, ok := instance.(notification.NotificationProvider); ok {
+			return RecordNotification(p, rec)
+		}
+	case "payment":
+		if p, ok := instance.(payment.PaymentProvider); ok {
+			return RecordPayment(p, rec)
+		}
+	case "pim":
+		if p, ok := instance.(pim.PIMProvider); ok {
+			return RecordPIM(p, rec)
+		}
+	case "search":
+		if p, ok := instance.(search.SearchProvider); ok {
+			return RecordSearch(p, rec)
+		}
+	case "storage":
+		if p, ok := instance.(storage.StorageProvider); ok {
+			return RecordStorage(p, rec)
+		}
+	case "tax":
+		if p, ok := instance.(tax.TaxProvider); ok {
+			return RecordTax(p, rec)
+		}
+	}
+	return instance
+}
+
+// Replay returns the replay provider of the given category, serving calls from player.
+func Replay(category string, player *Player) (any, error) {
+	switch category {
+	case "auth":
+		return ReplayAuth(player), nil
+	case "crm":
+		return ReplayCRM(player), nil
+	case "erp":
+		return ReplayERP(player), nil
+	case "fulfillment":
+		return ReplayFulfillment(player), nil
+	case "notification":
+		return ReplayNotification(player), nil
+	case "payment":
+		return ReplayPayment(player), nil
+	case "pim":
+		return ReplayPIM(player), nil
+	case "search":
+		return ReplaySearch(player), nil
+	case "storage":
+		return ReplayStorage(player), nil
+	case "tax":
+		return ReplayTax(player), nil
+	}
+	return nil, fmt.Errorf("replay: unknown provider category %s", category)
+}
+
+// categories lists the categories the replay provider can be registered in.
+var categories = []string{
+	"auth",
+	"crm",
+	"erp",
+	"fulfillment",
+	"notification",
+	"payment",
+	"pim",
+	"search",
+	"storage",
+	"tax",
+}
+
+// register registers the replay provider in category and reports whether the category is known.
+func register(category string) bool {
+	switch category {
+	case "auth":
+		provider.Register[auth.AuthProvider]("auth", Name, metadata("auth"),
+			func(config map[string]any) (auth.AuthProvider, error) {
+				player, err := newPlayer("auth", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayAuth(player), nil
+			},
+		)
+		return true
+	case "crm":
+		provider.Register[crm.CRMProvider]("crm", Name, metadata("crm"),
+			func(config map[string]any) (crm.CRMProvider, error) {
+				player, err := newPlayer("crm", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayCRM(player), nil
+			},
+		)
+		return true
+	case "erp":
+		provider.Register[erp.ERPProvider]("erp", Name, metadata("erp"),
+			func(config map[string]any) (erp.ERPProvider, error) {
+				player, err := newPlayer("erp", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayERP(player), nil
+			},
+		)
+		return true
+	case "fulfillment":
+		provider.Register[fulfillment.FulfillmentProvider]("fulfillment", Name, metadata("fulfillment"),
+			func(config map[string]any) (fulfillment.FulfillmentProvider, error) {
+				player, err := newPlayer("fulfillment", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayFulfillment(player), nil
+			},
+		)
+		return true
+	case "notification":
+		provider.Register[notification.NotificationProvider]("notification", Name, metadata("notification"),
+			func(config map[string]any) (notification.NotificationProvider, error) {
+				player, err := newPlayer("notification", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayNotification(player), nil
+			},
+		)
+		return true
+	case "payment":
+		provider.Register[payment.PaymentProvider]("payment", Name, metadata("payment"),
+			func(config map[string]any) (payment.PaymentProvider, error) {
+				player, err := newPlayer("payment", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayPayment(player), nil
+			},
+		)
+		return true
+	case "pim":
+		provider.Register[pim.PIMProvider]("pim", Name, metadata("pim"),
+			func(config map[string]any) (pim.PIMProvider, error) {
+				player, err := newPlayer("pim", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayPIM(player), nil
+			},
+		)
+		return true
+	case "search":
+		provider.Register[search.SearchProvider]("search", Name, metadata("search"),
+			func(config map[string]any) (search.SearchProvider, error) {
+				player, err := newPlayer("search", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplaySearch(player), nil
+			},
+		)
+		return true
+	case "storage":
+		provider.Register[storage.StorageProvider]("storage", Name, metadata("storage"),
+			func(config map[string]any) (storage.StorageProvider, error) {
+				player, err := newPlayer("storage", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayStorage(player), nil
+			},
+		)
+		return true
+	case "tax":
+		provider.Register[tax.TaxProvider]("tax", Name, metadata("tax"),
+			func(config map[string]any) (tax.TaxProvider, error) {
+				player, err := newPlayer("tax", config)
+				if err != nil {
+					return nil, err
+				}
+				return ReplayTax(player), nil
+			},
+		)
+		return true
+	}
+	return false
+}
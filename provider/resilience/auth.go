@@ -0,0 +1,47 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/auth"
+)
+
+// authProvider applies a resilience policy to an SSO provider.
+type authProvider struct {
+	next auth.AuthProvider
+	exec *executor
+}
+
+// WrapAuth wraps an SSO provider with policy. HandleCallback redeems a
+// single-use authorization code and is never retried.
+func WrapAuth(p auth.AuthProvider, policy Policy) auth.AuthProvider {
+	return &authProvider{next: p, exec: newExecutor("auth", policy)}
+}
+
+func (p *authProvider) GetAuthURL(ctx context.Context, state string, redirectURL string) (string, error) {
+	return call(ctx, p.exec, "GetAuthURL", func(ctx context.Context) (string, error) {
+		return p.next.GetAuthURL(ctx, state, redirectURL)
+	})
+}
+
+func (p *authProvider) HandleCallback(ctx context.Context, code string, state string) (*auth.SSOUser, error) {
+	return call(ctx, p.exec, "HandleCallback", func(ctx context.Context) (*auth.SSOUser, error) {
+		return p.next.HandleCallback(ctx, code, state)
+	})
+}
+
+func (p *authProvider) ValidateToken(ctx context.Context, token string) (*auth.SSOUser, error) {
+	return call(ctx, p.exec, "ValidateToken", func(ctx context.Context) (*auth.SSOUser, error) {
+		return p.next.ValidateToken(ctx, token)
+	})
+}
+
+func (p *authProvider) GetUserInfo(ctx context.Context, accessToken string) (*auth.SSOUser, error) {
+	return call(ctx, p.exec, "GetUserInfo", func(ctx context.Context) (*auth.SSOUser, error) {
+		return p.next.GetUserInfo(ctx, accessToken)
+	})
+}
+
+func (p *authProvider) Metadata() auth.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,101 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls pass through
+	StateOpen     State = "open"      // Calls fail fast with ErrCircuitOpen
+	StateHalfOpen State = "half_open" // A single probe call is allowed
+)
+
+// outcome classifies a finished call for the circuit breaker.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // The backend answered, even if with a business error
+	outcomeFailure                // Transient failure or timeout
+	outcomeIgnored                // The caller gave up; says nothing about the backend
+)
+
+// Breaker is a consecutive-failure circuit breaker.
+type Breaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker creates a closed circuit breaker.
+func NewBreaker(settings BreakerSettings) *Breaker {
+	return &Breaker{
+		settings: settings,
+		now:      time.Now,
+		state:    StateClosed,
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may proceed. In half-open state only one
+// probe call is let through at a time.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.settings.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call that was allowed.
+func (b *Breaker) record(o outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch o {
+	case outcomeSuccess:
+		b.state = StateClosed
+		b.failures = 0
+		b.probing = false
+	case outcomeFailure:
+		b.failures++
+		if b.state == StateHalfOpen || (b.settings.FailureThreshold > 0 && b.failures >= b.settings.FailureThreshold) {
+			b.state = StateOpen
+			b.openedAt = b.now()
+		}
+		b.probing = false
+	case outcomeIgnored:
+		b.probing = false
+	}
+}
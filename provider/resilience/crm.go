@@ -0,0 +1,40 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/crm"
+)
+
+// crmProvider applies a resilience policy to a CRM provider.
+type crmProvider struct {
+	next crm.CRMProvider
+	exec *executor
+}
+
+// WrapCRM wraps a CRM provider with policy. SyncContact is an upsert and is retried.
+func WrapCRM(p crm.CRMProvider, policy Policy) crm.CRMProvider {
+	return &crmProvider{next: p, exec: newExecutor("crm", policy)}
+}
+
+func (p *crmProvider) SyncContact(ctx context.Context, contact crm.Contact) (*crm.SyncResult, error) {
+	return call(ctx, p.exec, "SyncContact", func(ctx context.Context) (*crm.SyncResult, error) {
+		return p.next.SyncContact(ctx, contact)
+	})
+}
+
+func (p *crmProvider) GetAccount(ctx context.Context, accountID string) (*crm.Account, error) {
+	return call(ctx, p.exec, "GetAccount", func(ctx context.Context) (*crm.Account, error) {
+		return p.next.GetAccount(ctx, accountID)
+	})
+}
+
+func (p *crmProvider) ListAccounts(ctx context.Context, filter crm.AccountFilter) ([]crm.Account, error) {
+	return call(ctx, p.exec, "ListAccounts", func(ctx context.Context) ([]crm.Account, error) {
+		return p.next.ListAccounts(ctx, filter)
+	})
+}
+
+func (p *crmProvider) Metadata() crm.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,82 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// erpProvider applies a resilience policy to an ERP provider.
+type erpProvider struct {
+	next erp.ERPProvider
+	exec *executor
+}
+
+// WrapERP wraps an ERP provider with policy. CreateOrder is never retried.
+func WrapERP(p erp.ERPProvider, policy Policy) erp.ERPProvider {
+	return &erpProvider{next: p, exec: newExecutor("erp", policy)}
+}
+
+func (p *erpProvider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	return call(ctx, p.exec, "CreateOrder", func(ctx context.Context) (*erp.CreateOrderResult, error) {
+		return p.next.CreateOrder(ctx, req)
+	})
+}
+
+func (p *erpProvider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	return call(ctx, p.exec, "SimulateOrder", func(ctx context.Context) (*erp.SimulateOrderResult, error) {
+		return p.next.SimulateOrder(ctx, req)
+	})
+}
+
+func (p *erpProvider) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	return call(ctx, p.exec, "GetOrderStatus", func(ctx context.Context) (*erp.OrderStatus, error) {
+		return p.next.GetOrderStatus(ctx, orderID)
+	})
+}
+
+func (p *erpProvider) GetProductAvailability(ctx context.Context, skus []string) ([]erp.ProductStock, error) {
+	return call(ctx, p.exec, "GetProductAvailability", func(ctx context.Context) ([]erp.ProductStock, error) {
+		return p.next.GetProductAvailability(ctx, skus)
+	})
+}
+
+func (p *erpProvider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	return call(ctx, p.exec, "GetTierPrices", func(ctx context.Context) ([]erp.TierPrice, error) {
+		return p.next.GetTierPrices(ctx, req)
+	})
+}
+
+func (p *erpProvider) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	return call(ctx, p.exec, "SyncCompany", func(ctx context.Context) (*erp.CompanyData, error) {
+		return p.next.SyncCompany(ctx, erpCustomerID)
+	})
+}
+
+func (p *erpProvider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	return call(ctx, p.exec, "GetCompanyAddresses", func(ctx context.Context) ([]erp.Address, error) {
+		return p.next.GetCompanyAddresses(ctx, erpCustomerID)
+	})
+}
+
+func (p *erpProvider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	return call(ctx, p.exec, "GetOrderHistory", func(ctx context.Context) ([]erp.OrderReport, error) {
+		return p.next.GetOrderHistory(ctx, req)
+	})
+}
+
+func (p *erpProvider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) ([]erp.ShipmentReport, error) {
+	return call(ctx, p.exec, "GetShipmentHistory", func(ctx context.Context) ([]erp.ShipmentReport, error) {
+		return p.next.GetShipmentHistory(ctx, req)
+	})
+}
+
+func (p *erpProvider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	return call(ctx, p.exec, "GetInvoiceHistory", func(ctx context.Context) ([]erp.InvoiceReport, error) {
+		return p.next.GetInvoiceHistory(ctx, req)
+	})
+}
+
+func (p *erpProvider) Metadata() erp.Metadata {
+	return p.next.Metadata()
+}
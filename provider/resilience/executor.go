@@ -0,0 +1,191 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+)
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as non-transient: the call is not retried and the
+// failure does not count against the circuit breaker. Providers should use it
+// for validation errors, "not found" and other answers that a retry cannot change.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent. Errors wrapping
+// provider.ErrNotFound, provider.ErrInvalidArgument or provider.ErrUnsupported
+// are permanent as well.
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return true
+	}
+	return errors.Is(err, provider.ErrNotFound) ||
+		errors.Is(err, provider.ErrInvalidArgument) ||
+		errors.Is(err, provider.ErrUnsupported)
+}
+
+// executor applies a policy to the calls of one provider instance.
+type executor struct {
+	category string
+	policy   Policy
+	breaker  *Breaker
+}
+
+func newExecutor(category string, policy Policy) *executor {
+	return &executor{
+		category: category,
+		policy:   policy,
+		breaker:  NewBreaker(policy.Breaker),
+	}
+}
+
+// do runs fn under the policy of method. If detach is true, the per-call
+// context of the successful attempt is not cancelled; its cancel function is
+// returned instead so streamed results stay readable until they are closed.
+func (e *executor) do(ctx context.Context, method string, detach bool, fn func(context.Context) error) (context.CancelFunc, error) {
+	timeout, attempts := e.policy.forMethod(e.category, method)
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, e.policy.Backoff.delay(attempt-1)); err != nil {
+				return nil, lastErr
+			}
+		}
+
+		if err := e.breaker.allow(); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", e.category, method, err)
+		}
+
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		err := fn(callCtx)
+		if err == nil {
+			e.breaker.record(outcomeSuccess)
+			if detach {
+				return cancel, nil
+			}
+			cancel()
+			return nil, nil
+		}
+		timedOut := errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+
+		switch {
+		case ctx.Err() != nil:
+			// The caller gave up; retrying or blaming the backend makes no sense.
+			e.breaker.record(outcomeIgnored)
+			return nil, err
+		case IsPermanent(err):
+			e.breaker.record(outcomeSuccess)
+			return nil, err
+		case timedOut:
+			e.breaker.record(outcomeFailure)
+			lastErr = fmt.Errorf("%s.%s: %w after %s: %w", e.category, method, ErrTimeout, timeout, err)
+		default:
+			e.breaker.record(outcomeFailure)
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// call runs a provider method returning a value.
+func call[T any](ctx context.Context, e *executor, method string, fn func(context.Context) (T, error)) (T, error) {
+	var result T
+	_, err := e.do(ctx, method, false, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
+
+// exec runs a provider method returning only an error.
+func exec(ctx context.Context, e *executor, method string, fn func(context.Context) error) error {
+	_, err := e.do(ctx, method, false, fn)
+	return err
+}
+
+// stream runs a provider method returning a stream. The per-call timeout keeps
+// running until the stream is closed.
+func stream[T any](ctx context.Context, e *executor, method string, fn func(context.Context) (io.ReadCloser, T, error)) (io.ReadCloser, T, error) {
+	var (
+		rc    io.ReadCloser
+		extra T
+	)
+	cancel, err := e.do(ctx, method, true, func(ctx context.Context) error {
+		var err error
+		rc, extra, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		return rc, extra, err
+	}
+	return &cancelOnClose{ReadCloser: rc, cancel: cancel}, extra, nil
+}
+
+// cancelOnClose releases the call context when the stream is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// delay returns the backoff before retry n (1-based), with jitter of up to half the delay.
+func (b Backoff) delay(n int) time.Duration {
+	if b.Initial <= 0 {
+		return 0
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(b.Initial) * math.Pow(multiplier, float64(n-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	half := d / 2
+	return time.Duration(half + rand.Float64()*half)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/fulfillment"
+)
+
+// fulfillmentProvider applies a resilience policy to a fulfillment provider.
+type fulfillmentProvider struct {
+	next fulfillment.FulfillmentProvider
+	exec *executor
+}
+
+// WrapFulfillment wraps a fulfillment provider with policy. CreateShipment and
+// CancelShipment are never retried.
+func WrapFulfillment(p fulfillment.FulfillmentProvider, policy Policy) fulfillment.FulfillmentProvider {
+	return &fulfillmentProvider{next: p, exec: newExecutor("fulfillment", policy)}
+}
+
+func (p *fulfillmentProvider) CreateShipment(ctx context.Context, req fulfillment.ShipmentRequest) (*fulfillment.ShipmentResult, error) {
+	return call(ctx, p.exec, "CreateShipment", func(ctx context.Context) (*fulfillment.ShipmentResult, error) {
+		return p.next.CreateShipment(ctx, req)
+	})
+}
+
+func (p *fulfillmentProvider) GetShipmentStatus(ctx context.Context, shipmentID string) (*fulfillment.ShipmentStatus, error) {
+	return call(ctx, p.exec, "GetShipmentStatus", func(ctx context.Context) (*fulfillment.ShipmentStatus, error) {
+		return p.next.GetShipmentStatus(ctx, shipmentID)
+	})
+}
+
+func (p *fulfillmentProvider) CancelShipment(ctx context.Context, shipmentID string) error {
+	return exec(ctx, p.exec, "CancelShipment", func(ctx context.Context) error {
+		return p.next.CancelShipment(ctx, shipmentID)
+	})
+}
+
+func (p *fulfillmentProvider) GetTrackingURL(ctx context.Context, trackingNumber string) (string, error) {
+	return call(ctx, p.exec, "GetTrackingURL", func(ctx context.Context) (string, error) {
+		return p.next.GetTrackingURL(ctx, trackingNumber)
+	})
+}
+
+func (p *fulfillmentProvider) CalculateShipping(ctx context.Context, req fulfillment.ShippingCalcRequest) ([]fulfillment.ShippingOption, error) {
+	return call(ctx, p.exec, "CalculateShipping", func(ctx context.Context) ([]fulfillment.ShippingOption, error) {
+		return p.next.CalculateShipping(ctx, req)
+	})
+}
+
+func (p *fulfillmentProvider) Metadata() fulfillment.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,39 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/notification"
+)
+
+// notificationProvider applies a resilience policy to a notification provider.
+type notificationProvider struct {
+	next notification.NotificationProvider
+	exec *executor
+}
+
+// WrapNotification wraps a notification provider with policy. Sending is never
+// retried so recipients do not receive duplicates.
+func WrapNotification(p notification.NotificationProvider, policy Policy) notification.NotificationProvider {
+	return &notificationProvider{next: p, exec: newExecutor("notification", policy)}
+}
+
+func (p *notificationProvider) Send(ctx context.Context, msg notification.Message) (*notification.SendResult, error) {
+	return call(ctx, p.exec, "Send", func(ctx context.Context) (*notification.SendResult, error) {
+		return p.next.Send(ctx, msg)
+	})
+}
+
+func (p *notificationProvider) SendBatch(ctx context.Context, msgs []notification.Message) ([]notification.SendResult, error) {
+	return call(ctx, p.exec, "SendBatch", func(ctx context.Context) ([]notification.SendResult, error) {
+		return p.next.SendBatch(ctx, msgs)
+	})
+}
+
+func (p *notificationProvider) Channels() []string {
+	return p.next.Channels()
+}
+
+func (p *notificationProvider) Metadata() notification.Metadata {
+	return p.next.Metadata()
+}
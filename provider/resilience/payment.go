@@ -0,0 +1,59 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/payment"
+)
+
+// paymentProvider applies a resilience policy to a payment provider.
+type paymentProvider struct {
+	next payment.PaymentProvider
+	exec *executor
+}
+
+// WrapPayment wraps a payment provider with policy. Calls that move money or
+// change a transaction (Initialize, Authorize, Capture, Cancel, Refund) are never retried.
+func WrapPayment(p payment.PaymentProvider, policy Policy) payment.PaymentProvider {
+	return &paymentProvider{next: p, exec: newExecutor("payment", policy)}
+}
+
+func (p *paymentProvider) Initialize(ctx context.Context, req payment.InitializeRequest) (*payment.PaymentSession, error) {
+	return call(ctx, p.exec, "Initialize", func(ctx context.Context) (*payment.PaymentSession, error) {
+		return p.next.Initialize(ctx, req)
+	})
+}
+
+func (p *paymentProvider) Authorize(ctx context.Context, sessionID string) (*payment.AuthorizationResult, error) {
+	return call(ctx, p.exec, "Authorize", func(ctx context.Context) (*payment.AuthorizationResult, error) {
+		return p.next.Authorize(ctx, sessionID)
+	})
+}
+
+func (p *paymentProvider) Capture(ctx context.Context, transactionID string, amount *payment.Amount) (*payment.CaptureResult, error) {
+	return call(ctx, p.exec, "Capture", func(ctx context.Context) (*payment.CaptureResult, error) {
+		return p.next.Capture(ctx, transactionID, amount)
+	})
+}
+
+func (p *paymentProvider) Cancel(ctx context.Context, transactionID string) error {
+	return exec(ctx, p.exec, "Cancel", func(ctx context.Context) error {
+		return p.next.Cancel(ctx, transactionID)
+	})
+}
+
+func (p *paymentProvider) Refund(ctx context.Context, transactionID string, amount payment.Amount) (*payment.RefundResult, error) {
+	return call(ctx, p.exec, "Refund", func(ctx context.Context) (*payment.RefundResult, error) {
+		return p.next.Refund(ctx, transactionID, amount)
+	})
+}
+
+// HandleWebhook only verifies and parses an incoming payload, so it is passed
+// through unchanged: invalid signatures must not open the circuit breaker.
+func (p *paymentProvider) HandleWebhook(ctx context.Context, payload []byte, headers map[string]string) (*payment.WebhookEvent, error) {
+	return p.next.HandleWebhook(ctx, payload, headers)
+}
+
+func (p *paymentProvider) Metadata() payment.Metadata {
+	return p.next.Metadata()
+}
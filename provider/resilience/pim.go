@@ -0,0 +1,71 @@
+package resilience
+
+import (
+	"context"
+	"io"
+
+	"github.com/gondolia/gondolia/provider/pim"
+)
+
+// pimProvider applies a resilience policy to a PIM provider.
+type pimProvider struct {
+	next pim.PIMProvider
+	exec *executor
+}
+
+// WrapPIM wraps a PIM provider with policy. All PIM calls are read-only and retried.
+func WrapPIM(p pim.PIMProvider, policy Policy) pim.PIMProvider {
+	return &pimProvider{next: p, exec: newExecutor("pim", policy)}
+}
+
+func (p *pimProvider) FetchProducts(ctx context.Context, filter pim.ProductFilter) (*pim.ProductPage, error) {
+	return call(ctx, p.exec, "FetchProducts", func(ctx context.Context) (*pim.ProductPage, error) {
+		return p.next.FetchProducts(ctx, filter)
+	})
+}
+
+func (p *pimProvider) FetchProduct(ctx context.Context, identifier string) (*pim.Product, error) {
+	return call(ctx, p.exec, "FetchProduct", func(ctx context.Context) (*pim.Product, error) {
+		return p.next.FetchProduct(ctx, identifier)
+	})
+}
+
+func (p *pimProvider) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (*pim.ProductModelPage, error) {
+	return call(ctx, p.exec, "FetchProductModels", func(ctx context.Context) (*pim.ProductModelPage, error) {
+		return p.next.FetchProductModels(ctx, filter)
+	})
+}
+
+func (p *pimProvider) FetchProductModel(ctx context.Context, code string) (*pim.ProductModel, error) {
+	return call(ctx, p.exec, "FetchProductModel", func(ctx context.Context) (*pim.ProductModel, error) {
+		return p.next.FetchProductModel(ctx, code)
+	})
+}
+
+func (p *pimProvider) FetchFamilyVariants(ctx context.Context) ([]pim.FamilyVariant, error) {
+	return call(ctx, p.exec, "FetchFamilyVariants", func(ctx context.Context) ([]pim.FamilyVariant, error) {
+		return p.next.FetchFamilyVariants(ctx)
+	})
+}
+
+func (p *pimProvider) FetchCategories(ctx context.Context) ([]pim.Category, error) {
+	return call(ctx, p.exec, "FetchCategories", func(ctx context.Context) ([]pim.Category, error) {
+		return p.next.FetchCategories(ctx)
+	})
+}
+
+func (p *pimProvider) FetchAttributes(ctx context.Context) ([]pim.Attribute, error) {
+	return call(ctx, p.exec, "FetchAttributes", func(ctx context.Context) ([]pim.Attribute, error) {
+		return p.next.FetchAttributes(ctx)
+	})
+}
+
+func (p *pimProvider) DownloadAsset(ctx context.Context, assetCode string) (io.ReadCloser, string, error) {
+	return stream(ctx, p.exec, "DownloadAsset", func(ctx context.Context) (io.ReadCloser, string, error) {
+		return p.next.DownloadAsset(ctx, assetCode)
+	})
+}
+
+func (p *pimProvider) Metadata() pim.Metadata {
+	return p.next.Metadata()
+}
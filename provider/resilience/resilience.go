@@ -0,0 +1,351 @@
+// Package resilience wraps providers with per-method timeouts, retries with
+// backoff and a circuit breaker.
+//
+// Every call gets a deadline. Idempotent calls that fail with a transient error
+// are retried with exponential backoff and jitter. Calls that change state in
+// the remote system and cannot safely be repeated (see NonIdempotent) are
+// attempted exactly once: a timeout on such a call has an unknown outcome and
+// the caller must reconcile, e.g. CreateOrder followed by GetOrderStatus.
+//
+// Each wrapped instance has its own circuit breaker. After a number of
+// consecutive failures the breaker opens and calls fail fast with
+// ErrCircuitOpen until a probe call succeeds again.
+//
+// Policies are configured per category (Decorator) and can be overridden per
+// tenant through the "resilience" key of a provider selection:
+//
+//	"erp": {
+//	  "name": "sap",
+//	  "config": {...},
+//	  "resilience": {
+//	    "timeout": "15s",
+//	    "max_attempts": 4,
+//	    "breaker": {"failure_threshold": 10, "open_timeout": "1m"},
+//	    "methods": {"CreateOrder": {"timeout": "60s"}}
+//	  }
+//	}
+package resilience
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/crm"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/fulfillment"
+	"github.com/gondolia/gondolia/provider/notification"
+	"github.com/gondolia/gondolia/provider/payment"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+var (
+	// ErrCircuitOpen is returned without calling the provider while its circuit breaker is open.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrTimeout is returned when a single call exceeds its per-method timeout.
+	ErrTimeout = errors.New("provider call timed out")
+)
+
+// Policy configures timeouts, retries and the circuit breaker for one provider instance.
+// Zero values mean "inherit" when policies are merged.
+type Policy struct {
+	Timeout     time.Duration           // Per-call timeout
+	MaxAttempts int                     // Attempts for idempotent calls, including the first
+	Backoff     Backoff                 // Delay between attempts
+	Breaker     BreakerSettings         // Circuit breaker thresholds
+	Methods     map[string]MethodPolicy // Overrides per method name, e.g. "CreateOrder"
+}
+
+// MethodPolicy overrides the policy for a single method.
+type MethodPolicy struct {
+	Timeout     time.Duration
+	MaxAttempts int
+}
+
+// Backoff configures the exponential delay between retry attempts.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// BreakerSettings configures the circuit breaker.
+type BreakerSettings struct {
+	FailureThreshold int           // Consecutive failures that open the breaker
+	OpenTimeout      time.Duration // Time the breaker stays open before a probe call is allowed
+}
+
+// basePolicy applies to every category unless overridden.
+var basePolicy = Policy{
+	Timeout:     10 * time.Second,
+	MaxAttempts: 3,
+	Backoff: Backoff{
+		Initial:    200 * time.Millisecond,
+		Max:        5 * time.Second,
+		Multiplier: 2,
+	},
+	Breaker: BreakerSettings{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	},
+}
+
+// categoryMethods holds the built-in per-method overrides of each category.
+var categoryMethods = map[string]map[string]MethodPolicy{
+	"erp": {
+		"CreateOrder":        {Timeout: 30 * time.Second},
+		"GetOrderHistory":    {Timeout: 30 * time.Second},
+		"GetShipmentHistory": {Timeout: 30 * time.Second},
+		"GetInvoiceHistory":  {Timeout: 30 * time.Second},
+	},
+	"pim": {
+		"FetchProducts":   {Timeout: 30 * time.Second},
+		"FetchCategories": {Timeout: 30 * time.Second},
+		"FetchAttributes": {Timeout: 30 * time.Second},
+		"DownloadAsset":   {Timeout: 5 * time.Minute},
+	},
+	"search": {
+		"IndexDocuments": {Timeout: 30 * time.Second},
+		"Health":         {Timeout: 5 * time.Second, MaxAttempts: 1},
+	},
+	"storage": {
+		"Upload":   {Timeout: 5 * time.Minute},
+		"Download": {Timeout: 5 * time.Minute},
+	},
+	"notification": {
+		"SendBatch": {Timeout: 30 * time.Second},
+	},
+}
+
+// nonIdempotent lists the methods per category that are never retried,
+// regardless of configuration.
+var nonIdempotent = map[string]map[string]bool{
+	"erp":          {"CreateOrder": true},
+	"payment":      {"Initialize": true, "Authorize": true, "Capture": true, "Cancel": true, "Refund": true},
+	"notification": {"Send": true, "SendBatch": true},
+	"fulfillment":  {"CreateShipment": true, "CancelShipment": true},
+	"auth":         {"HandleCallback": true}, // Authorization codes are single-use
+	"storage":      {"Upload": true},         // The upload reader cannot be replayed
+}
+
+// NonIdempotent reports whether method of category is attempted at most once.
+func NonIdempotent(category, method string) bool {
+	return nonIdempotent[category][method]
+}
+
+// DefaultPolicy returns the built-in policy for a category.
+func DefaultPolicy(category string) Policy {
+	policy := basePolicy
+	policy.Methods = make(map[string]MethodPolicy, len(categoryMethods[category]))
+	for method, mp := range categoryMethods[category] {
+		policy.Methods[method] = mp
+	}
+	return policy
+}
+
+// Merge returns p with all non-zero settings of override applied.
+func (p Policy) Merge(override Policy) Policy {
+	result := p
+	if override.Timeout > 0 {
+		result.Timeout = override.Timeout
+	}
+	if override.MaxAttempts > 0 {
+		result.MaxAttempts = override.MaxAttempts
+	}
+	if override.Backoff.Initial > 0 {
+		result.Backoff.Initial = override.Backoff.Initial
+	}
+	if override.Backoff.Max > 0 {
+		result.Backoff.Max = override.Backoff.Max
+	}
+	if override.Backoff.Multiplier > 0 {
+		result.Backoff.Multiplier = override.Backoff.Multiplier
+	}
+	if override.Breaker.FailureThreshold > 0 {
+		result.Breaker.FailureThreshold = override.Breaker.FailureThreshold
+	}
+	if override.Breaker.OpenTimeout > 0 {
+		result.Breaker.OpenTimeout = override.Breaker.OpenTimeout
+	}
+
+	result.Methods = make(map[string]MethodPolicy, len(p.Methods)+len(override.Methods))
+	for method, mp := range p.Methods {
+		result.Methods[method] = mp
+	}
+	for method, mp := range override.Methods {
+		merged := result.Methods[method]
+		if mp.Timeout > 0 {
+			merged.Timeout = mp.Timeout
+		}
+		if mp.MaxAttempts > 0 {
+			merged.MaxAttempts = mp.MaxAttempts
+		}
+		result.Methods[method] = merged
+	}
+	return result
+}
+
+// forMethod returns the effective timeout and attempt count of a method.
+func (p Policy) forMethod(category, method string) (time.Duration, int) {
+	timeout, attempts := p.Timeout, p.MaxAttempts
+	if mp, ok := p.Methods[method]; ok {
+		if mp.Timeout > 0 {
+			timeout = mp.Timeout
+		}
+		if mp.MaxAttempts > 0 {
+			attempts = mp.MaxAttempts
+		}
+	}
+	if attempts < 1 || NonIdempotent(category, method) {
+		attempts = 1
+	}
+	return timeout, attempts
+}
+
+// policyConfig is the serialized form of a Policy as found in tenant config.
+type policyConfig struct {
+	Timeout     string `json:"timeout"`
+	MaxAttempts int    `json:"max_attempts"`
+	Backoff     struct {
+		Initial    string  `json:"initial"`
+		Max        string  `json:"max"`
+		Multiplier float64 `json:"multiplier"`
+	} `json:"backoff"`
+	Breaker struct {
+		FailureThreshold int    `json:"failure_threshold"`
+		OpenTimeout      string `json:"open_timeout"`
+	} `json:"breaker"`
+	Methods map[string]struct {
+		Timeout     string `json:"timeout"`
+		MaxAttempts int    `json:"max_attempts"`
+	} `json:"methods"`
+}
+
+// ParsePolicy parses a policy from its config map form. Durations are strings
+// such as "30s". Unset fields stay zero so the result can be merged onto a base policy.
+func ParsePolicy(raw map[string]any) (Policy, error) {
+	var policy Policy
+	if len(raw) == 0 {
+		return policy, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return policy, fmt.Errorf("invalid resilience policy: %w", err)
+	}
+	var cfg policyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return policy, fmt.Errorf("invalid resilience policy: %w", err)
+	}
+
+	var errs []error
+	parse := func(field, value string) time.Duration {
+		if value == "" {
+			return 0
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+		return d
+	}
+
+	policy.Timeout = parse("timeout", cfg.Timeout)
+	policy.MaxAttempts = cfg.MaxAttempts
+	policy.Backoff.Initial = parse("backoff.initial", cfg.Backoff.Initial)
+	policy.Backoff.Max = parse("backoff.max", cfg.Backoff.Max)
+	policy.Backoff.Multiplier = cfg.Backoff.Multiplier
+	policy.Breaker.FailureThreshold = cfg.Breaker.FailureThreshold
+	policy.Breaker.OpenTimeout = parse("breaker.open_timeout", cfg.Breaker.OpenTimeout)
+
+	if len(cfg.Methods) > 0 {
+		policy.Methods = make(map[string]MethodPolicy, len(cfg.Methods))
+		for method, mc := range cfg.Methods {
+			policy.Methods[method] = MethodPolicy{
+				Timeout:     parse("methods."+method+".timeout", mc.Timeout),
+				MaxAttempts: mc.MaxAttempts,
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return Policy{}, fmt.Errorf("invalid resilience policy: %w", errors.Join(errs...))
+	}
+	return policy, nil
+}
+
+// Decorator returns a provider.Decorator that wraps every resolved provider with
+// the policy of its category. policies overrides the built-in category defaults
+// and may be nil; the "resilience" settings of a tenant's selection override both.
+//
+// Example:
+//
+//	resolver.Use(resilience.Decorator(nil))
+func Decorator(policies map[string]Policy) provider.Decorator {
+	return func(category string, sel provider.Selection, instance any) (any, error) {
+		policy := DefaultPolicy(category)
+		if p, ok := policies[category]; ok {
+			policy = policy.Merge(p)
+		}
+		override, err := ParsePolicy(sel.Resilience)
+		if err != nil {
+			return nil, err
+		}
+		return Wrap(category, instance, policy.Merge(override)), nil
+	}
+}
+
+// Wrap wraps a provider of the given category with policy.
+// Values that do not implement the category's interface are returned unchanged.
+func Wrap(category string, instance any, policy Policy) any {
+	switch category {
+	case "erp":
+		if p, ok := instance.(erp.ERPProvider); ok {
+			return WrapERP(p, policy)
+		}
+	case "pim":
+		if p, ok := instance.(pim.PIMProvider); ok {
+			return WrapPIM(p, policy)
+		}
+	case "payment":
+		if p, ok := instance.(payment.PaymentProvider); ok {
+			return WrapPayment(p, policy)
+		}
+	case "search":
+		if p, ok := instance.(search.SearchProvider); ok {
+			return WrapSearch(p, policy)
+		}
+	case "storage":
+		if p, ok := instance.(storage.StorageProvider); ok {
+			return WrapStorage(p, policy)
+		}
+	case "notification":
+		if p, ok := instance.(notification.NotificationProvider); ok {
+			return WrapNotification(p, policy)
+		}
+	case "tax":
+		if p, ok := instance.(tax.TaxProvider); ok {
+			return WrapTax(p, policy)
+		}
+	case "fulfillment":
+		if p, ok := instance.(fulfillment.FulfillmentProvider); ok {
+			return WrapFulfillment(p, policy)
+		}
+	case "crm":
+		if p, ok := instance.(crm.CRMProvider); ok {
+			return WrapCRM(p, policy)
+		}
+	case "auth":
+		if p, ok := instance.(auth.AuthProvider); ok {
+			return WrapAuth(p, policy)
+		}
+	}
+	return instance
+}
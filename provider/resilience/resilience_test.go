@@ -0,0 +1,182 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// fakeERP fails the first failures calls of each method.
+type fakeERP struct {
+	erp.ERPProvider
+	failures int
+	calls    map[string]int
+	delay    time.Duration
+	err      error
+}
+
+func (f *fakeERP) call(ctx context.Context, method string) error {
+	f.calls[method]++
+	if f.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.delay):
+		}
+	}
+	if f.calls[method] <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *fakeERP) GetOrderStatus(ctx context.Context, orderID string) (*erp.OrderStatus, error) {
+	if err := f.call(ctx, "GetOrderStatus"); err != nil {
+		return nil, err
+	}
+	return &erp.OrderStatus{ERPOrderNumber: orderID}, nil
+}
+
+func (f *fakeERP) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	if err := f.call(ctx, "CreateOrder"); err != nil {
+		return nil, err
+	}
+	return &erp.CreateOrderResult{ERPOrderNumber: "42"}, nil
+}
+
+func newFake(failures int) *fakeERP {
+	return &fakeERP{failures: failures, calls: map[string]int{}, err: errors.New("connection reset")}
+}
+
+func testPolicy() Policy {
+	return DefaultPolicy("erp").Merge(Policy{
+		Timeout: time.Second,
+		Backoff: Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+	})
+}
+
+func TestRetriesIdempotentCalls(t *testing.T) {
+	fake := newFake(2)
+	p := WrapERP(fake, testPolicy())
+
+	status, err := p.GetOrderStatus(context.Background(), "4711")
+	if err != nil {
+		t.Fatalf("GetOrderStatus() error = %v", err)
+	}
+	if status.ERPOrderNumber != "4711" {
+		t.Errorf("ERPOrderNumber = %q, want 4711", status.ERPOrderNumber)
+	}
+	if fake.calls["GetOrderStatus"] != 3 {
+		t.Errorf("calls = %d, want 3", fake.calls["GetOrderStatus"])
+	}
+}
+
+func TestNeverRetriesNonIdempotentCalls(t *testing.T) {
+	fake := newFake(1)
+	p := WrapERP(fake, testPolicy().Merge(Policy{
+		Methods: map[string]MethodPolicy{"CreateOrder": {MaxAttempts: 5}},
+	}))
+
+	if _, err := p.CreateOrder(context.Background(), erp.CreateOrderRequest{}); err == nil {
+		t.Fatal("expected error from CreateOrder")
+	}
+	if fake.calls["CreateOrder"] != 1 {
+		t.Errorf("calls = %d, want 1", fake.calls["CreateOrder"])
+	}
+}
+
+func TestPermanentErrorsAreNotRetried(t *testing.T) {
+	fake := newFake(10)
+	fake.err = Permanent(errors.New("order not found"))
+	p := WrapERP(fake, testPolicy())
+
+	if _, err := p.GetOrderStatus(context.Background(), "4711"); !IsPermanent(err) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if fake.calls["GetOrderStatus"] != 1 {
+		t.Errorf("calls = %d, want 1", fake.calls["GetOrderStatus"])
+	}
+}
+
+func TestProviderErrorsAreNotRetried(t *testing.T) {
+	fake := newFake(10)
+	fake.err = fmt.Errorf("order 4711: %w", provider.ErrNotFound)
+	p := WrapERP(fake, testPolicy())
+
+	if _, err := p.GetOrderStatus(context.Background(), "4711"); !errors.Is(err, provider.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if fake.calls["GetOrderStatus"] != 1 {
+		t.Errorf("calls = %d, want 1", fake.calls["GetOrderStatus"])
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	fake := newFake(0)
+	fake.delay = time.Second
+	p := WrapERP(fake, testPolicy().Merge(Policy{
+		Timeout:     10 * time.Millisecond,
+		MaxAttempts: 1,
+	}))
+
+	if _, err := p.GetOrderStatus(context.Background(), "4711"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	fake := newFake(3)
+	p := WrapERP(fake, testPolicy().Merge(Policy{
+		MaxAttempts: 1,
+		Breaker:     BreakerSettings{FailureThreshold: 3, OpenTimeout: time.Minute},
+	})).(*erpProvider)
+
+	now := time.Now()
+	p.exec.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		p.GetOrderStatus(context.Background(), "4711")
+	}
+	if _, err := p.GetOrderStatus(context.Background(), "4711"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if fake.calls["GetOrderStatus"] != 3 {
+		t.Errorf("calls = %d, want 3 (open breaker must not call the provider)", fake.calls["GetOrderStatus"])
+	}
+
+	// After the open timeout a probe call is let through and closes the breaker
+	now = now.Add(time.Minute)
+	if _, err := p.GetOrderStatus(context.Background(), "4711"); err != nil {
+		t.Fatalf("probe call error = %v", err)
+	}
+	if state := p.exec.breaker.State(); state != StateClosed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+func TestDecorator_TenantOverride(t *testing.T) {
+	decorate := Decorator(map[string]Policy{"erp": {MaxAttempts: 2}})
+
+	wrapped, err := decorate("erp", provider.Selection{
+		Name:       "fake",
+		Resilience: map[string]any{"max_attempts": 4, "methods": map[string]any{"GetOrderStatus": map[string]any{"timeout": "2s"}}},
+	}, newFake(0))
+	if err != nil {
+		t.Fatalf("decorator error = %v", err)
+	}
+
+	p := wrapped.(*erpProvider)
+	timeout, attempts := p.exec.policy.forMethod("erp", "GetOrderStatus")
+	if timeout != 2*time.Second || attempts != 4 {
+		t.Errorf("GetOrderStatus policy = %s/%d, want 2s/4", timeout, attempts)
+	}
+
+	if _, err := decorate("erp", provider.Selection{Resilience: map[string]any{"timeout": "soon"}}, newFake(0)); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
@@ -0,0 +1,71 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+// searchProvider applies a resilience policy to a search provider.
+type searchProvider struct {
+	next search.SearchProvider
+	exec *executor
+}
+
+// WrapSearch wraps a search provider with policy. Indexing is keyed by document ID
+// and therefore retried like all other search calls.
+func WrapSearch(p search.SearchProvider, policy Policy) search.SearchProvider {
+	return &searchProvider{next: p, exec: newExecutor("search", policy)}
+}
+
+func (p *searchProvider) IndexDocuments(ctx context.Context, index string, documents []search.Document) (*search.TaskResult, error) {
+	return call(ctx, p.exec, "IndexDocuments", func(ctx context.Context) (*search.TaskResult, error) {
+		return p.next.IndexDocuments(ctx, index, documents)
+	})
+}
+
+func (p *searchProvider) DeleteDocuments(ctx context.Context, index string, ids []string) (*search.TaskResult, error) {
+	return call(ctx, p.exec, "DeleteDocuments", func(ctx context.Context) (*search.TaskResult, error) {
+		return p.next.DeleteDocuments(ctx, index, ids)
+	})
+}
+
+func (p *searchProvider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) error {
+	return exec(ctx, p.exec, "ConfigureIndex", func(ctx context.Context) error {
+		return p.next.ConfigureIndex(ctx, index, config)
+	})
+}
+
+func (p *searchProvider) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	return call(ctx, p.exec, "Search", func(ctx context.Context) (*search.SearchResult, error) {
+		return p.next.Search(ctx, index, query)
+	})
+}
+
+func (p *searchProvider) CreateIndex(ctx context.Context, index string, primaryKey string) error {
+	return exec(ctx, p.exec, "CreateIndex", func(ctx context.Context) error {
+		return p.next.CreateIndex(ctx, index, primaryKey)
+	})
+}
+
+func (p *searchProvider) DeleteIndex(ctx context.Context, index string) error {
+	return exec(ctx, p.exec, "DeleteIndex", func(ctx context.Context) error {
+		return p.next.DeleteIndex(ctx, index)
+	})
+}
+
+func (p *searchProvider) GetTaskStatus(ctx context.Context, taskID string) (*search.TaskResult, error) {
+	return call(ctx, p.exec, "GetTaskStatus", func(ctx context.Context) (*search.TaskResult, error) {
+		return p.next.GetTaskStatus(ctx, taskID)
+	})
+}
+
+func (p *searchProvider) Health(ctx context.Context) error {
+	return exec(ctx, p.exec, "Health", func(ctx context.Context) error {
+		return p.next.Health(ctx)
+	})
+}
+
+func (p *searchProvider) Metadata() search.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,61 @@
+package resilience
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// storageProvider applies a resilience policy to a storage provider.
+type storageProvider struct {
+	next storage.StorageProvider
+	exec *executor
+}
+
+// WrapStorage wraps a storage provider with policy. Upload consumes its reader
+// and is therefore never retried.
+func WrapStorage(p storage.StorageProvider, policy Policy) storage.StorageProvider {
+	return &storageProvider{next: p, exec: newExecutor("storage", policy)}
+}
+
+func (p *storageProvider) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (*storage.FileInfo, error) {
+	return call(ctx, p.exec, "Upload", func(ctx context.Context) (*storage.FileInfo, error) {
+		return p.next.Upload(ctx, path, reader, opts)
+	})
+}
+
+func (p *storageProvider) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	return stream(ctx, p.exec, "Download", func(ctx context.Context) (io.ReadCloser, *storage.FileInfo, error) {
+		return p.next.Download(ctx, path)
+	})
+}
+
+func (p *storageProvider) Delete(ctx context.Context, path string) error {
+	return exec(ctx, p.exec, "Delete", func(ctx context.Context) error {
+		return p.next.Delete(ctx, path)
+	})
+}
+
+func (p *storageProvider) Exists(ctx context.Context, path string) (bool, error) {
+	return call(ctx, p.exec, "Exists", func(ctx context.Context) (bool, error) {
+		return p.next.Exists(ctx, path)
+	})
+}
+
+func (p *storageProvider) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	return call(ctx, p.exec, "GetSignedURL", func(ctx context.Context) (string, error) {
+		return p.next.GetSignedURL(ctx, path, expiry)
+	})
+}
+
+func (p *storageProvider) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	return call(ctx, p.exec, "List", func(ctx context.Context) ([]storage.FileInfo, error) {
+		return p.next.List(ctx, prefix, opts)
+	})
+}
+
+func (p *storageProvider) Metadata() storage.Metadata {
+	return p.next.Metadata()
+}
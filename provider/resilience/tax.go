@@ -0,0 +1,28 @@
+package resilience
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// taxProvider applies a resilience policy to a tax provider.
+type taxProvider struct {
+	next tax.TaxProvider
+	exec *executor
+}
+
+// WrapTax wraps a tax provider with policy.
+func WrapTax(p tax.TaxProvider, policy Policy) tax.TaxProvider {
+	return &taxProvider{next: p, exec: newExecutor("tax", policy)}
+}
+
+func (p *taxProvider) CalculateTax(ctx context.Context, req tax.TaxRequest) (*tax.TaxResult, error) {
+	return call(ctx, p.exec, "CalculateTax", func(ctx context.Context) (*tax.TaxResult, error) {
+		return p.next.CalculateTax(ctx, req)
+	})
+}
+
+func (p *taxProvider) Metadata() tax.Metadata {
+	return p.next.Metadata()
+}
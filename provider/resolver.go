@@ -0,0 +1,673 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TenantConfigKey is the key in a tenant's config map that holds its provider selections.
+//
+// Example Tenant.Config:
+//
+//	{
+//	  "providers": {
+//	    "erp":    {"name": "sap", "config": {"base_url": "https://...", "client_secret": "..."}},
+//	    "search": {"name": "opensearch", "config": {"addresses": ["http://opensearch:9200"]}}
+//	  }
+//	}
+const TenantConfigKey = "providers"
+
+// DefaultRefreshInterval is how long a tenant's provider selections are cached
+// before the resolver reloads them to pick up configuration changes.
+const DefaultRefreshInterval = 30 * time.Second
+
+// Selection identifies a provider implementation and its configuration for a category.
+type Selection struct {
+	Name   string         `json:"name" yaml:"name"`
+	Config map[string]any `json:"config,omitempty" yaml:"config,omitempty"`
+
+	// Resilience overrides the timeout/retry/circuit breaker policy applied by
+	// decorators (see package provider/resilience). It is not passed to the factory.
+	Resilience map[string]any `json:"resilience,omitempty" yaml:"resilience,omitempty"`
+}
+
+// Decorator wraps a freshly built provider instance, e.g. to add timeouts or tracing.
+// It must return a value implementing the same provider interface as instance.
+type Decorator func(category string, sel Selection, instance any) (any, error)
+
+// TenantConfigLookup loads the raw config map (Tenant.Config) of a tenant.
+type TenantConfigLookup func(ctx context.Context, tenantID string) (map[string]any, error)
+
+// ParseSelections extracts the per-category provider selections from a tenant config map.
+// A missing TenantConfigKey yields an empty result.
+func ParseSelections(tenantConfig map[string]any) (map[string]Selection, error) {
+	raw, ok := tenantConfig[TenantConfigKey]
+	if !ok || raw == nil {
+		return map[string]Selection{}, nil
+	}
+
+	// Round-trip through JSON so both decoded JSONB maps and typed values are accepted.
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q tenant config: %w", TenantConfigKey, err)
+	}
+	var selections map[string]Selection
+	if err := json.Unmarshal(data, &selections); err != nil {
+		return nil, fmt.Errorf("invalid %q tenant config: %w", TenantConfigKey, err)
+	}
+	for category, sel := range selections {
+		if sel.Name == "" {
+			return nil, fmt.Errorf("invalid %q tenant config: %s provider has no name", TenantConfigKey, category)
+		}
+	}
+	return selections, nil
+}
+
+// Resolver builds provider instances per tenant from the tenant's configuration.
+//
+// Instances are created lazily on first use through the registry, cached per tenant
+// and category, and rebuilt when the tenant's selection for that category changes.
+// Categories without a tenant-specific selection fall back to a process-wide default
+// (see SetDefault), whose instance is shared by all such tenants. Tenants whose
+// selections are identical share one instance, and with it its connections.
+//
+// Instances implementing Starter are started before first use; instances
+// implementing Closer are closed once no tenant uses them any more and when the
+// resolver is closed.
+type Resolver struct {
+	lookup  TenantConfigLookup
+	refresh time.Duration
+
+	mu         sync.Mutex
+	defaults   map[string]Selection
+	decorators []Decorator
+	shared     map[string]*instance // category -> instance built from the default
+	tenants    map[string]*tenantEntry
+	pool       map[string]*instance // category and fingerprint -> instance
+
+	started     bool
+	startErr    error
+	closed      bool
+	closing     sync.WaitGroup // instances being closed in the background
+	closeErrors []error
+}
+
+type tenantEntry struct {
+	selections map[string]Selection
+	loadedAt   time.Time
+	instances  map[string]*instance // category -> instance
+}
+
+type instance struct {
+	key         string
+	name        string
+	fingerprint string
+	value       any
+	layers      []any // the factory's instance followed by each decorator's result
+	refs        int   // number of default and tenant slots holding the instance
+}
+
+// NewResolver creates a new resolver that reads tenant configs through lookup.
+func NewResolver(lookup TenantConfigLookup) *Resolver {
+	return &Resolver{
+		lookup:   lookup,
+		refresh:  DefaultRefreshInterval,
+		defaults: make(map[string]Selection),
+		shared:   make(map[string]*instance),
+		tenants:  make(map[string]*tenantEntry),
+		pool:     make(map[string]*instance),
+	}
+}
+
+// SetRefreshInterval sets how long tenant selections are cached before being reloaded.
+func (r *Resolver) SetRefreshInterval(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refresh = d
+}
+
+// SetDefault sets the selection used for tenants without their own selection in a category.
+func (r *Resolver) SetDefault(category string, sel Selection) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaults[category] = sel
+}
+
+// RemoveDefault removes the default selection of a category. Tenants without
+// their own selection can no longer resolve a provider in that category.
+func (r *Resolver) RemoveDefault(category string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.defaults, category)
+	if inst := r.shared[category]; inst != nil {
+		delete(r.shared, category)
+		r.release(inst)
+	}
+}
+
+// Use adds decorators that are applied, in order, to every instance the resolver builds.
+// Decorators must be added before the first provider is resolved.
+func (r *Resolver) Use(decorators ...Decorator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decorators = append(r.decorators, decorators...)
+}
+
+// Invalidate drops the cached selections of a tenant so the next resolve reloads them.
+// Cached instances are kept and only rebuilt if the selection actually changed.
+func (r *Resolver) Invalidate(tenantID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.tenants[tenantID]; ok {
+		entry.loadedAt = time.Time{}
+	}
+}
+
+// Resolve returns the provider of type T registered for category that is active for tenantID.
+//
+// Example:
+//
+//	erpProvider, err := provider.Resolve[erp.ERPProvider](ctx, resolver, tenantID, "erp")
+func Resolve[T any](ctx context.Context, r *Resolver, tenantID, category string) (T, error) {
+	var zero T
+
+	value, err := r.Instance(ctx, tenantID, category)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("%s provider for tenant %s has wrong type %T", category, tenantID, value)
+	}
+	return typed, nil
+}
+
+// Instance returns the provider instance active for tenantID in category without
+// asserting its interface type. Service code should use Resolve or a Source instead.
+func (r *Resolver) Instance(ctx context.Context, tenantID, category string) (any, error) {
+	sel, tenantSpecific, err := r.selection(ctx, tenantID, category)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := selectionFingerprint(sel)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s provider for tenant %s: %w", category, tenantID, err)
+	}
+
+	if cached := r.cached(tenantID, category, tenantSpecific); cached != nil && cached.fingerprint == fingerprint {
+		return cached.value, nil
+	}
+
+	inst, err := r.acquire(ctx, category, sel, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s provider for tenant %s: %w", category, tenantID, err)
+	}
+	r.store(tenantID, category, tenantSpecific, inst)
+	return inst.value, nil
+}
+
+// Start builds and starts the instances of all default selections, so that
+// connections and caches are warm before the service reports ready. It may be
+// called again after a failure; instances that started successfully are kept.
+func (r *Resolver) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defaults := make(map[string]Selection, len(r.defaults))
+	for category, sel := range r.defaults {
+		defaults[category] = sel
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for category, sel := range defaults {
+		fingerprint, err := selectionFingerprint(sel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("start %s provider: %w", category, err))
+			continue
+		}
+		if cached := r.cached("", category, false); cached != nil && cached.fingerprint == fingerprint {
+			continue
+		}
+		inst, err := r.acquire(ctx, category, sel, fingerprint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("start %s provider: %w", category, err))
+			continue
+		}
+		r.store("", category, false, inst)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.startErr = errors.Join(errs...)
+	r.started = r.startErr == nil
+	return r.startErr
+}
+
+// Ready reports whether Start has succeeded and the resolver is not closed.
+// It is meant to back a readiness probe.
+func (r *Resolver) Ready() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.closed:
+		return ErrResolverClosed
+	case r.started:
+		return nil
+	case r.startErr != nil:
+		return r.startErr
+	}
+	return ErrNotReady
+}
+
+// Close closes all instances and waits for instances that are still being
+// closed after a configuration change. Resolving providers afterwards fails
+// with ErrResolverClosed. Services call Close during graceful shutdown, after
+// the servers have stopped accepting requests.
+func (r *Resolver) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	instances := make([]*instance, 0, len(r.pool))
+	for _, inst := range r.pool {
+		instances = append(instances, inst)
+	}
+	r.pool = make(map[string]*instance)
+	r.shared = make(map[string]*instance)
+	for _, entry := range r.tenants {
+		entry.instances = make(map[string]*instance)
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, inst := range instances {
+		if err := inst.close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("close %s provider: %w", inst.name, err))
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.closing.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	r.mu.Lock()
+	errs = append(errs, r.closeErrors...)
+	r.closeErrors = nil
+	r.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// ActiveProvider describes the provider selected for a tenant in one category.
+type ActiveProvider struct {
+	Category   string         `json:"category"`
+	Name       string         `json:"name"`
+	Source     string         `json:"source"`           // "tenant" or "default"
+	Config     map[string]any `json:"config,omitempty"` // Secret values are redacted
+	Resilience map[string]any `json:"resilience,omitempty"`
+	Built      bool           `json:"built"` // Whether an instance has been created yet
+}
+
+// Active returns the providers active for a tenant in every category that has
+// a tenant selection or a default, sorted by category.
+func (r *Resolver) Active(ctx context.Context, tenantID string) ([]ActiveProvider, error) {
+	selections, err := r.tenantSelections(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	categories := make(map[string]bool)
+	for category := range r.defaults {
+		categories[category] = true
+	}
+	for category := range selections {
+		categories[category] = true
+	}
+
+	result := make([]ActiveProvider, 0, len(categories))
+	for category := range categories {
+		sel, tenantSpecific := selections[category]
+		source := "tenant"
+		if !tenantSpecific {
+			sel, source = r.defaults[category], "default"
+		}
+
+		var config map[string]any
+		if meta, ok := GetMetadata(category, sel.Name); ok {
+			config = RedactConfig(meta.ConfigSpec, sel.Config)
+		} else {
+			config = RedactConfig(nil, sel.Config)
+		}
+
+		var built bool
+		if tenantSpecific {
+			built = r.tenants[tenantID] != nil && r.tenants[tenantID].instances[category] != nil
+		} else {
+			built = r.shared[category] != nil
+		}
+
+		result = append(result, ActiveProvider{
+			Category:   category,
+			Name:       sel.Name,
+			Source:     source,
+			Config:     config,
+			Resilience: sel.Resilience,
+			Built:      built,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Category < result[j].Category })
+	return result, nil
+}
+
+// Tenants returns the IDs of all tenants the resolver has loaded selections for.
+func (r *Resolver) Tenants() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// selection returns the active selection for a tenant and category and whether
+// it comes from the tenant's own config (as opposed to the process default).
+func (r *Resolver) selection(ctx context.Context, tenantID, category string) (Selection, bool, error) {
+	selections, err := r.tenantSelections(ctx, tenantID)
+	if err != nil {
+		return Selection{}, false, err
+	}
+	if sel, ok := selections[category]; ok {
+		return sel, true, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sel, ok := r.defaults[category]; ok {
+		return sel, false, nil
+	}
+	return Selection{}, false, fmt.Errorf("no %s provider configured for tenant %s", category, tenantID)
+}
+
+// tenantSelections returns the tenant's own selections, reloading them when stale.
+// The returned map is replaced on reload and never modified, so it may be read without locking.
+func (r *Resolver) tenantSelections(ctx context.Context, tenantID string) (map[string]Selection, error) {
+	r.mu.Lock()
+	if entry := r.tenants[tenantID]; entry != nil && time.Since(entry.loadedAt) <= r.refresh {
+		selections := entry.selections
+		r.mu.Unlock()
+		return selections, nil
+	}
+	r.mu.Unlock()
+
+	var selections map[string]Selection
+	if r.lookup != nil {
+		tenantConfig, err := r.lookup(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("load provider config for tenant %s: %w", tenantID, err)
+		}
+		selections, err = ParseSelections(tenantConfig)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := r.tenants[tenantID]
+	if entry == nil {
+		entry = &tenantEntry{instances: make(map[string]*instance)}
+		r.tenants[tenantID] = entry
+	}
+	entry.selections = selections
+	entry.loadedAt = time.Now()
+
+	// Release instances of categories the tenant no longer selects itself
+	for category, inst := range entry.instances {
+		if _, ok := selections[category]; !ok {
+			delete(entry.instances, category)
+			r.release(inst)
+		}
+	}
+	return selections, nil
+}
+
+func (r *Resolver) cached(tenantID, category string, tenantSpecific bool) *instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !tenantSpecific {
+		return r.shared[category]
+	}
+	if entry := r.tenants[tenantID]; entry != nil {
+		return entry.instances[category]
+	}
+	return nil
+}
+
+// store puts an acquired instance into a default or tenant slot and releases
+// the instance the slot held before.
+func (r *Resolver) store(tenantID, category string, tenantSpecific bool, inst *instance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		r.release(inst)
+		return
+	}
+
+	var old *instance
+	if !tenantSpecific {
+		old = r.shared[category]
+		r.shared[category] = inst
+	} else {
+		entry := r.tenants[tenantID]
+		if entry == nil {
+			entry = &tenantEntry{instances: make(map[string]*instance)}
+			r.tenants[tenantID] = entry
+		}
+		old = entry.instances[category]
+		entry.instances[category] = inst
+	}
+	if old != nil {
+		r.release(old)
+	}
+}
+
+// acquire returns the pooled instance for a selection, building and starting
+// it if no tenant uses it yet. The caller must pass it to store.
+func (r *Resolver) acquire(ctx context.Context, category string, sel Selection, fingerprint string) (*instance, error) {
+	key := category + "/" + fingerprint
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrResolverClosed
+	}
+	if inst := r.pool[key]; inst != nil {
+		inst.refs++
+		r.mu.Unlock()
+		return inst, nil
+	}
+	decorators := r.decorators
+	r.mu.Unlock()
+
+	inst, err := build(ctx, category, sel, decorators)
+	if err != nil {
+		return nil, err
+	}
+	inst.key, inst.fingerprint = key, fingerprint
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		r.closeInBackground(inst)
+		return nil, ErrResolverClosed
+	}
+	if pooled := r.pool[key]; pooled != nil {
+		// Built concurrently by another caller; keep theirs
+		r.closeInBackground(inst)
+		inst = pooled
+	}
+	inst.refs++
+	r.pool[key] = inst
+	return inst, nil
+}
+
+// release drops one reference to inst and closes it once it is unused.
+// The caller must hold r.mu.
+func (r *Resolver) release(inst *instance) {
+	inst.refs--
+	if inst.refs > 0 {
+		return
+	}
+	if r.pool[inst.key] == inst {
+		delete(r.pool, inst.key)
+	}
+	r.closeInBackground(inst)
+}
+
+// closeInBackground closes inst without blocking the caller, so that calls
+// still in flight on the old instance of a tenant can complete.
+// The caller must hold r.mu.
+func (r *Resolver) closeInBackground(inst *instance) {
+	r.closing.Add(1)
+	go func() {
+		defer r.closing.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+		defer cancel()
+		if err := inst.close(ctx); err != nil {
+			r.mu.Lock()
+			r.closeErrors = append(r.closeErrors, fmt.Errorf("close %s provider: %w", inst.name, err))
+			r.mu.Unlock()
+		}
+	}()
+}
+
+// build creates, decorates and starts a provider instance for sel.
+func build(ctx context.Context, category string, sel Selection, decorators []Decorator) (*instance, error) {
+	factory, err := GetAny(category, sel.Name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := factory(sel.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	inst := &instance{name: sel.Name, value: value, layers: []any{value}}
+	for _, d := range decorators {
+		decorated, err := d(category, sel, inst.value)
+		if err != nil {
+			inst.close(ctx)
+			return nil, err
+		}
+		if !sameValue(decorated, inst.value) {
+			inst.layers = append(inst.layers, decorated)
+		}
+		inst.value = decorated
+	}
+
+	if err := inst.start(ctx); err != nil {
+		inst.close(ctx)
+		return nil, fmt.Errorf("start %s: %w", sel.Name, err)
+	}
+	return inst, nil
+}
+
+// start starts all layers that implement Starter, innermost first.
+func (inst *instance) start(ctx context.Context) error {
+	for _, layer := range inst.layers {
+		if err := Start(ctx, layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close closes all layers that implement Closer, outermost first, so that
+// decorators can flush into the instance they wrap.
+func (inst *instance) close(ctx context.Context) error {
+	var errs []error
+	for i := len(inst.layers) - 1; i >= 0; i-- {
+		if err := Close(ctx, inst.layers[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameValue reports whether a decorator returned the value it was given.
+func sameValue(a, b any) bool {
+	t := reflect.TypeOf(a)
+	return t != nil && t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// selectionFingerprint hashes a selection so config changes can be detected.
+// encoding/json sorts map keys, which makes the encoding deterministic.
+func selectionFingerprint(sel Selection) (string, error) {
+	data, err := json.Marshal(sel)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// --- Typed access ---
+
+// Source resolves a provider of type T for a tenant.
+// Services depend on a Source instead of holding a single global instance.
+type Source[T any] interface {
+	For(ctx context.Context, tenantID string) (T, error)
+}
+
+// NewSource returns a Source that resolves providers of category through r.
+func NewSource[T any](r *Resolver, category string) Source[T] {
+	return &resolverSource[T]{resolver: r, category: category}
+}
+
+// Static returns a Source that always returns p, regardless of tenant.
+// Useful in tests and for single-tenant deployments.
+func Static[T any](p T) Source[T] {
+	return staticSource[T]{value: p}
+}
+
+type resolverSource[T any] struct {
+	resolver *Resolver
+	category string
+}
+
+func (s *resolverSource[T]) For(ctx context.Context, tenantID string) (T, error) {
+	return Resolve[T](ctx, s.resolver, tenantID, s.category)
+}
+
+type staticSource[T any] struct {
+	value T
+}
+
+func (s staticSource[T]) For(ctx context.Context, tenantID string) (T, error) {
+	return s.value, nil
+}
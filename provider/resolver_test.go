@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolve_PerTenantAndRebuildOnChange(t *testing.T) {
+	builds := 0
+	Register[string]("test-resolve", "echo",
+		Metadata{Name: "echo", Category: "test-resolve", ConfigSpec: []ConfigField{
+			{Key: "value", Type: "string", Required: true},
+		}},
+		func(config map[string]any) (string, error) {
+			builds++
+			return config["value"].(string), nil
+		},
+	)
+
+	tenantConfigs := map[string]map[string]any{
+		"t1": {TenantConfigKey: map[string]any{
+			"test-resolve": map[string]any{"name": "echo", "config": map[string]any{"value": "one"}},
+		}},
+		"t2": {},
+		"t3": {},
+	}
+	r := NewResolver(func(ctx context.Context, tenantID string) (map[string]any, error) {
+		return tenantConfigs[tenantID], nil
+	})
+	r.SetDefault("test-resolve", Selection{Name: "echo", Config: map[string]any{"value": "default"}})
+	ctx := context.Background()
+
+	if got, err := Resolve[string](ctx, r, "t1", "test-resolve"); err != nil || got != "one" {
+		t.Fatalf("t1 = %q, %v; want one", got, err)
+	}
+	if got, err := Resolve[string](ctx, r, "t2", "test-resolve"); err != nil || got != "default" {
+		t.Fatalf("t2 = %q, %v; want default", got, err)
+	}
+	if _, err := Resolve[string](ctx, r, "t3", "test-resolve"); err != nil {
+		t.Fatalf("t3: %v", err)
+	}
+	if _, err := Resolve[string](ctx, r, "t1", "test-resolve"); err != nil {
+		t.Fatalf("t1: %v", err)
+	}
+	if builds != 2 {
+		t.Errorf("builds = %d, want 2 (tenant instance + shared default)", builds)
+	}
+
+	// A config change is picked up after invalidation and rebuilds the instance
+	tenantConfigs["t1"][TenantConfigKey].(map[string]any)["test-resolve"] =
+		map[string]any{"name": "echo", "config": map[string]any{"value": "two"}}
+	r.Invalidate("t1")
+
+	if got, err := Resolve[string](ctx, r, "t1", "test-resolve"); err != nil || got != "two" {
+		t.Fatalf("t1 after change = %q, %v; want two", got, err)
+	}
+	if builds != 3 {
+		t.Errorf("builds = %d, want 3", builds)
+	}
+}
+
+func TestResolve_NoProviderConfigured(t *testing.T) {
+	r := NewResolver(nil)
+	if _, err := Resolve[string](context.Background(), r, "t1", "test-unconfigured"); err == nil {
+		t.Fatal("expected error for category without selection or default")
+	}
+}
@@ -4,6 +4,7 @@ package meilisearch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -14,50 +15,70 @@ import (
 )
 
 func init() {
-	provider.Register[search.SearchProvider]("search", "meilisearch",
+	provider.RegisterTyped[search.SearchProvider, Config]("search", "meilisearch",
 		provider.Metadata{
 			Name:        "meilisearch",
 			DisplayName: "Meilisearch",
 			Category:    "search",
 			Version:     "1.0.0",
 			Description: "Meilisearch search engine integration",
-			ConfigSpec: []provider.ConfigField{
-				{
-					Key:         "host",
-					Type:        "string",
-					Required:    true,
-					Description: "Meilisearch server host URL",
-				},
-				{
-					Key:         "api_key",
-					Type:        "secret",
-					Required:    true,
-					Description: "Meilisearch master/admin API key",
-				},
-			},
+			ConfigSpec:  configSpec,
 		},
-		NewProvider,
+		New,
 	)
 }
 
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{
+		Key:         "host",
+		Type:        "string",
+		Required:    true,
+		Description: "Meilisearch server host URL",
+	},
+	{
+		Key:         "api_key",
+		Type:        "secret",
+		Required:    true,
+		Description: "Meilisearch master/admin API key",
+	},
+}
+
+// Config holds the Meilisearch configuration.
+type Config struct {
+	Host   string `config:"host"`
+	APIKey string `config:"api_key"`
+}
+
 // Provider is a Meilisearch search provider.
 type Provider struct {
 	client meilisearch.ServiceManager
 }
 
-// NewProvider creates a new Meilisearch search provider.
+// NewProvider creates a new Meilisearch search provider from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
 func NewProvider(config map[string]any) (search.SearchProvider, error) {
-	host, ok := config["host"].(string)
-	if !ok || host == "" {
-		return nil, fmt.Errorf("meilisearch: host is required")
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("meilisearch: %w", err)
 	}
+	var cfg Config
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("meilisearch: %w", err)
+	}
+	return New(cfg)
+}
 
-	apiKey, ok := config["api_key"].(string)
-	if !ok || apiKey == "" {
+// New creates a new Meilisearch search provider from a typed configuration.
+func New(cfg Config) (search.SearchProvider, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("meilisearch: host is required")
+	}
+	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("meilisearch: api_key is required")
 	}
 
-	client := meilisearch.New(host, meilisearch.WithAPIKey(apiKey))
+	client := meilisearch.New(cfg.Host, meilisearch.WithAPIKey(cfg.APIKey))
 
 	return &Provider{
 		client: client,
@@ -75,7 +96,7 @@ func (p *Provider) IndexDocuments(ctx context.Context, index string, documents [
 
 	taskInfo, err := idx.AddDocumentsWithContext(ctx, docs, nil)
 	if err != nil {
-		return nil, fmt.Errorf("meilisearch: failed to index documents: %w", err)
+		return nil, wrapError(ctx, "failed to index documents", err)
 	}
 
 	return &search.TaskResult{
@@ -89,7 +110,7 @@ func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []stri
 
 	taskInfo, err := idx.DeleteDocumentsWithContext(ctx, ids, nil)
 	if err != nil {
-		return nil, fmt.Errorf("meilisearch: failed to delete documents: %w", err)
+		return nil, wrapError(ctx, "failed to delete documents", err)
 	}
 
 	return &search.TaskResult{
@@ -99,12 +120,15 @@ func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []stri
 }
 
 func (p *Provider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	idx := p.client.Index(index)
 
 	// Configure searchable attributes
 	if len(config.SearchableAttributes) > 0 {
 		if _, err := idx.UpdateSearchableAttributesWithContext(ctx, &config.SearchableAttributes); err != nil {
-			return fmt.Errorf("meilisearch: failed to update searchable attributes: %w", err)
+			return wrapError(ctx, "failed to update searchable attributes", err)
 		}
 	}
 
@@ -115,28 +139,28 @@ func (p *Provider) ConfigureIndex(ctx context.Context, index string, config sear
 			filterableAttrs[i] = attr
 		}
 		if _, err := idx.UpdateFilterableAttributesWithContext(ctx, &filterableAttrs); err != nil {
-			return fmt.Errorf("meilisearch: failed to update filterable attributes: %w", err)
+			return wrapError(ctx, "failed to update filterable attributes", err)
 		}
 	}
 
 	// Configure sortable attributes
 	if len(config.SortableAttributes) > 0 {
 		if _, err := idx.UpdateSortableAttributesWithContext(ctx, &config.SortableAttributes); err != nil {
-			return fmt.Errorf("meilisearch: failed to update sortable attributes: %w", err)
+			return wrapError(ctx, "failed to update sortable attributes", err)
 		}
 	}
 
 	// Configure synonyms
 	if len(config.Synonyms) > 0 {
 		if _, err := idx.UpdateSynonymsWithContext(ctx, &config.Synonyms); err != nil {
-			return fmt.Errorf("meilisearch: failed to update synonyms: %w", err)
+			return wrapError(ctx, "failed to update synonyms", err)
 		}
 	}
 
 	// Configure stop words
 	if len(config.StopWords) > 0 {
 		if _, err := idx.UpdateStopWordsWithContext(ctx, &config.StopWords); err != nil {
-			return fmt.Errorf("meilisearch: failed to update stop words: %w", err)
+			return wrapError(ctx, "failed to update stop words", err)
 		}
 	}
 
@@ -154,7 +178,7 @@ func (p *Provider) ConfigureIndex(ctx context.Context, index string, config sear
 			}
 		}
 		if _, err := idx.UpdateTypoToleranceWithContext(ctx, typoTolerance); err != nil {
-			return fmt.Errorf("meilisearch: failed to update typo tolerance: %w", err)
+			return wrapError(ctx, "failed to update typo tolerance", err)
 		}
 	}
 
@@ -162,12 +186,16 @@ func (p *Provider) ConfigureIndex(ctx context.Context, index string, config sear
 }
 
 func (p *Provider) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	if err := search.ValidateQuery(query); err != nil {
+		return nil, fmt.Errorf("meilisearch: %w", err)
+	}
+
 	idx := p.client.Index(index)
 
 	searchRequest := &meilisearch.SearchRequest{
 		Query:  query.Query,
 		Offset: int64(query.Offset),
-		Limit:  int64(query.Limit),
+		Limit:  int64(query.EffectiveLimit()),
 	}
 
 	// Build filter string from filters
@@ -181,9 +209,14 @@ func (p *Provider) Search(ctx context.Context, index string, query search.Search
 		searchRequest.Facets = query.Facets
 	}
 
-	// Add sort
-	if len(query.Sort) > 0 {
-		searchRequest.Sort = query.Sort
+	// Add sort; Meilisearch requires an explicit direction
+	for _, s := range query.Sort {
+		field, desc, _ := search.ParseSort(s)
+		if desc {
+			searchRequest.Sort = append(searchRequest.Sort, field+":desc")
+		} else {
+			searchRequest.Sort = append(searchRequest.Sort, field+":asc")
+		}
 	}
 
 	// Add highlight
@@ -193,7 +226,7 @@ func (p *Provider) Search(ctx context.Context, index string, query search.Search
 
 	searchResp, err := idx.SearchWithContext(ctx, query.Query, searchRequest)
 	if err != nil {
-		return nil, fmt.Errorf("meilisearch: search failed: %w", err)
+		return nil, wrapError(ctx, "search failed", err)
 	}
 
 	// Convert hits to documents
@@ -232,13 +265,16 @@ func (p *Provider) CreateIndex(ctx context.Context, index string, primaryKey str
 		PrimaryKey: primaryKey,
 	})
 	if err != nil {
-		return fmt.Errorf("meilisearch: failed to create index: %w", err)
+		return wrapError(ctx, "failed to create index", err)
 	}
 
 	// Wait for task to complete (use 0 for default timeout)
-	_, err = p.client.WaitForTaskWithContext(ctx, task.TaskUID, 0)
+	result, err := p.client.WaitForTaskWithContext(ctx, task.TaskUID, 0)
 	if err != nil {
-		return fmt.Errorf("meilisearch: failed to wait for index creation: %w", err)
+		return wrapError(ctx, "failed to wait for index creation", err)
+	}
+	if result.Status == meilisearch.TaskStatusFailed && result.Error.Code != "index_already_exists" {
+		return fmt.Errorf("meilisearch: failed to create index: %s", result.Error.Message)
 	}
 
 	return nil
@@ -247,27 +283,35 @@ func (p *Provider) CreateIndex(ctx context.Context, index string, primaryKey str
 func (p *Provider) DeleteIndex(ctx context.Context, index string) error {
 	task, err := p.client.DeleteIndexWithContext(ctx, index)
 	if err != nil {
-		return fmt.Errorf("meilisearch: failed to delete index: %w", err)
+		return wrapError(ctx, "failed to delete index", err)
 	}
 
 	// Wait for task to complete (use 0 for default timeout)
-	_, err = p.client.WaitForTaskWithContext(ctx, task.TaskUID, 0)
+	result, err := p.client.WaitForTaskWithContext(ctx, task.TaskUID, 0)
 	if err != nil {
-		return fmt.Errorf("meilisearch: failed to wait for index deletion: %w", err)
+		return wrapError(ctx, "failed to wait for index deletion", err)
+	}
+	// Deleting a missing index is not an error
+	if result.Status == meilisearch.TaskStatusFailed && result.Error.Code != "index_not_found" {
+		return fmt.Errorf("meilisearch: failed to delete index: %s", result.Error.Message)
 	}
 
 	return nil
 }
 
 func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var uid int64
 	if _, err := fmt.Sscanf(taskID, "%d", &uid); err != nil {
-		return nil, fmt.Errorf("meilisearch: invalid task ID: %w", err)
+		return nil, fmt.Errorf("meilisearch: invalid task ID %q: %w", taskID, provider.ErrInvalidArgument)
 	}
 
 	task, err := p.client.GetTaskWithContext(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("meilisearch: failed to get task status: %w", err)
+		return nil, wrapError(ctx, "failed to get task status", err)
 	}
 
 	status := strings.ToLower(string(task.Status))
@@ -286,7 +330,7 @@ func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.Ta
 func (p *Provider) Health(ctx context.Context) error {
 	health, err := p.client.HealthWithContext(ctx)
 	if err != nil {
-		return fmt.Errorf("meilisearch: health check failed: %w", err)
+		return wrapError(ctx, "health check failed", err)
 	}
 	if health.Status != "available" {
 		return fmt.Errorf("meilisearch: server is not available (status: %s)", health.Status)
@@ -294,6 +338,18 @@ func (p *Provider) Health(ctx context.Context) error {
 	return nil
 }
 
+// Start opens a connection to the server and checks that it is available.
+func (p *Provider) Start(ctx context.Context) error {
+	return p.Health(ctx)
+}
+
+// Close releases the idle connections of the client. Requests still in flight
+// complete normally.
+func (p *Provider) Close(ctx context.Context) error {
+	p.client.Close()
+	return nil
+}
+
 func (p *Provider) Metadata() search.Metadata {
 	return search.Metadata{
 		Name:    "meilisearch",
@@ -310,18 +366,38 @@ func (p *Provider) Metadata() search.Metadata {
 	}
 }
 
-// buildMeilisearchFilter converts search.Filter to Meilisearch filter syntax
+// wrapError adds context to a client error and maps it to the errors of
+// package search. The client does not wrap context errors, so cancellation is
+// taken from ctx.
+func wrapError(ctx context.Context, msg string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("meilisearch: %s: %w", msg, ctxErr)
+	}
+
+	var apiErr *meilisearch.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.MeilisearchApiError.Code {
+		case "index_not_found":
+			return fmt.Errorf("meilisearch: %s: %w: %s", msg, search.ErrIndexNotFound, apiErr.MeilisearchApiError.Message)
+		case "task_not_found":
+			return fmt.Errorf("meilisearch: %s: %w: %s", msg, provider.ErrNotFound, apiErr.MeilisearchApiError.Message)
+		case "invalid_search_filter", "invalid_search_sort":
+			return fmt.Errorf("meilisearch: %s: %w: %s", msg, provider.ErrInvalidArgument, apiErr.MeilisearchApiError.Message)
+		}
+	}
+	return fmt.Errorf("meilisearch: %s: %w", msg, err)
+}
+
+// buildMeilisearchFilter converts search.Filter to Meilisearch filter syntax.
+// The filters must have been validated with search.ValidateQuery.
 func buildMeilisearchFilter(filters []search.Filter) string {
 	if len(filters) == 0 {
 		return ""
 	}
 
-	var parts []string
+	parts := make([]string, 0, len(filters))
 	for _, f := range filters {
-		filterStr := buildSingleFilter(f)
-		if filterStr != "" {
-			parts = append(parts, filterStr)
-		}
+		parts = append(parts, buildSingleFilter(f))
 	}
 
 	// Combine filters with AND
@@ -330,36 +406,16 @@ func buildMeilisearchFilter(filters []search.Filter) string {
 
 func buildSingleFilter(f search.Filter) string {
 	switch f.Operator {
-	case "=":
-		return fmt.Sprintf("%s = %v", f.Field, formatFilterValue(f.Value))
-	case "!=":
-		return fmt.Sprintf("%s != %v", f.Field, formatFilterValue(f.Value))
-	case ">":
-		return fmt.Sprintf("%s > %v", f.Field, f.Value)
-	case "<":
-		return fmt.Sprintf("%s < %v", f.Field, f.Value)
-	case ">=":
-		return fmt.Sprintf("%s >= %v", f.Field, f.Value)
-	case "<=":
-		return fmt.Sprintf("%s <= %v", f.Field, f.Value)
-	case "IN":
-		if values, ok := f.Value.([]any); ok {
-			var formatted []string
-			for _, v := range values {
-				formatted = append(formatted, fmt.Sprintf("%v", formatFilterValue(v)))
-			}
-			return fmt.Sprintf("%s IN [%s]", f.Field, strings.Join(formatted, ", "))
-		}
-	case "NOT IN":
-		if values, ok := f.Value.([]any); ok {
-			var formatted []string
-			for _, v := range values {
-				formatted = append(formatted, fmt.Sprintf("%v", formatFilterValue(v)))
-			}
-			return fmt.Sprintf("%s NOT IN [%s]", f.Field, strings.Join(formatted, ", "))
+	case search.OpIn, search.OpNotIn:
+		values := f.Values()
+		formatted := make([]string, len(values))
+		for i, v := range values {
+			formatted[i] = formatFilterValue(v)
 		}
+		return fmt.Sprintf("%s %s [%s]", f.Field, f.Operator, strings.Join(formatted, ", "))
+	default:
+		return fmt.Sprintf("%s %s %s", f.Field, f.Operator, formatFilterValue(f.Value))
 	}
-	return ""
 }
 
 func formatFilterValue(value any) string {
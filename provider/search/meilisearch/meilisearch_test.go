@@ -0,0 +1,50 @@
+package meilisearch
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/search/searchtest"
+)
+
+func TestBuildMeilisearchFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []search.Filter
+		want    string
+	}{
+		{"equal string", []search.Filter{{Field: "status", Operator: "=", Value: `say "hi"`}}, `status = "say \"hi\""`},
+		{"range", []search.Filter{{Field: "price", Operator: ">=", Value: 10}}, `price >= 10`},
+		{"in string slice", []search.Filter{{Field: "category_ids", Operator: "IN", Value: []string{"a", "b"}}}, `category_ids IN ["a", "b"]`},
+		{"not in int slice", []search.Filter{{Field: "size", Operator: "NOT IN", Value: []int{1, 2}}}, `size NOT IN [1, 2]`},
+		{"combined", []search.Filter{
+			{Field: "tenant_id", Operator: "=", Value: "t1"},
+			{Field: "product_type", Operator: "!=", Value: "variant"},
+		}, `tenant_id = "t1" AND product_type != "variant"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildMeilisearchFilter(tc.filters); got != tc.want {
+				t.Errorf("buildMeilisearchFilter() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestConformance runs the search conformance suite against a live server.
+// It is skipped unless MEILISEARCH_TEST_HOST is set.
+func TestConformance(t *testing.T) {
+	host := os.Getenv("MEILISEARCH_TEST_HOST")
+	if host == "" {
+		t.Skip("MEILISEARCH_TEST_HOST not set")
+	}
+
+	searchtest.Run(t, func(t *testing.T) search.SearchProvider {
+		p, err := New(Config{Host: host, APIKey: os.Getenv("MEILISEARCH_TEST_API_KEY")})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return p
+	}, searchtest.Options{})
+}
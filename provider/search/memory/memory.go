@@ -0,0 +1,417 @@
+// Package memory provides an in-memory implementation of the Search provider.
+// It implements the filter, sort and pagination semantics of package search
+// exactly and serves as reference implementation for the conformance suite
+// and as a lightweight engine for development and tests.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+func init() {
+	provider.Register[search.SearchProvider]("search", "memory",
+		provider.Metadata{
+			Name:        "memory",
+			DisplayName: "In-Memory Search Provider",
+			Category:    "search",
+			Version:     "1.0.0",
+			Description: "An in-memory search engine for development and testing; data is lost on restart",
+			ConfigSpec:  []provider.ConfigField{},
+		},
+		NewProvider,
+	)
+}
+
+// Provider is an in-memory Search provider. It is safe for concurrent use.
+type Provider struct {
+	mu      sync.RWMutex
+	indexes map[string]*store
+	tasks   map[string]search.TaskResult
+	taskSeq int
+}
+
+// store holds the documents and settings of one index.
+type store struct {
+	primaryKey string
+	config     search.IndexConfig
+	docs       map[string]search.Document
+}
+
+// NewProvider creates a new in-memory Search provider.
+func NewProvider(config map[string]any) (search.SearchProvider, error) {
+	return New(), nil
+}
+
+// New creates a new, empty in-memory Search provider.
+func New() *Provider {
+	return &Provider{
+		indexes: make(map[string]*store),
+		tasks:   make(map[string]search.TaskResult),
+	}
+}
+
+func (p *Provider) IndexDocuments(ctx context.Context, index string, documents []search.Document) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idx := p.index(index)
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		id, ok := doc[idx.primaryKey]
+		if !ok || id == nil {
+			return nil, fmt.Errorf("memory: document %d has no %q: %w", i, idx.primaryKey, provider.ErrInvalidArgument)
+		}
+		ids[i] = fmt.Sprint(id)
+	}
+	for i, doc := range documents {
+		idx.docs[ids[i]] = maps.Clone(doc)
+	}
+	return p.task(), nil
+}
+
+func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if idx, ok := p.indexes[index]; ok {
+		for _, id := range ids {
+			delete(idx.docs, id)
+		}
+	}
+	return p.task(), nil
+}
+
+func (p *Provider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.index(index).config = config
+	return nil
+}
+
+func (p *Provider) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := search.ValidateQuery(query); err != nil {
+		return nil, fmt.Errorf("memory: %w", err)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	idx, ok := p.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("memory: %s: %w", index, search.ErrIndexNotFound)
+	}
+
+	terms := strings.Fields(strings.ToLower(query.Query))
+	var matches []search.Document
+	for _, doc := range idx.docs {
+		if idx.matchQuery(doc, terms) && matchFilters(doc, query.Filters) {
+			matches = append(matches, doc)
+		}
+	}
+	sortDocuments(matches, query.Sort, idx.primaryKey)
+
+	facets := make(map[string]map[string]int)
+	for _, field := range query.Facets {
+		counts := make(map[string]int)
+		for _, doc := range matches {
+			if value, ok := doc[field]; ok {
+				for _, v := range elements(value) {
+					counts[fmt.Sprint(v)]++
+				}
+			}
+		}
+		facets[field] = counts
+	}
+
+	start := min(query.Offset, len(matches))
+	end := min(start+query.EffectiveLimit(), len(matches))
+	hits := make([]search.Document, 0, end-start)
+	for _, doc := range matches[start:end] {
+		hits = append(hits, maps.Clone(doc))
+	}
+
+	return &search.SearchResult{
+		Hits:      hits,
+		TotalHits: len(matches),
+		Facets:    facets,
+	}, nil
+}
+
+func (p *Provider) CreateIndex(ctx context.Context, index string, primaryKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.indexes[index]; !ok {
+		if primaryKey == "" {
+			primaryKey = "id"
+		}
+		p.indexes[index] = &store{primaryKey: primaryKey, docs: make(map[string]search.Document)}
+	}
+	return nil
+}
+
+func (p *Provider) DeleteIndex(ctx context.Context, index string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.indexes, index)
+	return nil
+}
+
+func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	task, ok := p.tasks[taskID]
+	if !ok {
+		return nil, fmt.Errorf("memory: task %s: %w", taskID, provider.ErrNotFound)
+	}
+	return &task, nil
+}
+
+func (p *Provider) Health(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (p *Provider) Metadata() search.Metadata {
+	return search.Metadata{
+		Name:     "memory",
+		Version:  "1.0.0",
+		Features: []string{"facets", "filtering", "sorting"},
+	}
+}
+
+// index returns the named index, creating it like engines do on first write.
+// The caller must hold p.mu.
+func (p *Provider) index(name string) *store {
+	idx, ok := p.indexes[name]
+	if !ok {
+		idx = &store{primaryKey: "id", docs: make(map[string]search.Document)}
+		p.indexes[name] = idx
+	}
+	return idx
+}
+
+// task records a completed task. The caller must hold p.mu.
+func (p *Provider) task() *search.TaskResult {
+	p.taskSeq++
+	task := search.TaskResult{TaskID: fmt.Sprintf("memory-%d", p.taskSeq), Status: "succeeded"}
+	p.tasks[task.TaskID] = task
+	return &task
+}
+
+// matchQuery reports whether every term occurs in a searchable string field.
+func (idx *store) matchQuery(doc search.Document, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+
+	var text []string
+	if len(idx.config.SearchableAttributes) > 0 {
+		for _, field := range idx.config.SearchableAttributes {
+			text = appendStrings(text, doc[field])
+		}
+	} else {
+		for _, value := range doc {
+			text = appendStrings(text, value)
+		}
+	}
+
+	for _, term := range terms {
+		found := false
+		for _, s := range text {
+			if strings.Contains(strings.ToLower(s), term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func appendStrings(dst []string, value any) []string {
+	for _, v := range elements(value) {
+		if s, ok := v.(string); ok {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+func matchFilters(doc search.Document, filters []search.Filter) bool {
+	for _, f := range filters {
+		if !matchFilter(doc, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFilter(doc search.Document, f search.Filter) bool {
+	value, ok := doc[f.Field]
+	if !ok || value == nil {
+		// Missing fields only match negated operators
+		return f.Operator == search.OpNotEqual || f.Operator == search.OpNotIn
+	}
+
+	switch f.Operator {
+	case search.OpEqual:
+		return containsAny(value, f.Value)
+	case search.OpNotEqual:
+		return !containsAny(value, f.Value)
+	case search.OpIn:
+		return containsAny(value, f.Values()...)
+	case search.OpNotIn:
+		return !containsAny(value, f.Values()...)
+	}
+
+	bound, _ := search.ToFloat(f.Value)
+	for _, v := range elements(value) {
+		n, ok := search.ToFloat(v)
+		if !ok {
+			continue
+		}
+		switch {
+		case f.Operator == search.OpGreater && n > bound,
+			f.Operator == search.OpLess && n < bound,
+			f.Operator == search.OpGreaterOrEqual && n >= bound,
+			f.Operator == search.OpLessOrEqual && n <= bound:
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether value, or any element of an array value, equals one of wants.
+func containsAny(value any, wants ...any) bool {
+	for _, v := range elements(value) {
+		for _, want := range wants {
+			if equal(v, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	if x, ok := search.ToFloat(a); ok {
+		y, ok := search.ToFloat(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return false
+}
+
+// elements returns the elements of a slice value, or the value itself.
+func elements(value any) []any {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.Kind() != reflect.Slice {
+		return []any{value}
+	}
+	result := make([]any, v.Len())
+	for i := range result {
+		result[i] = v.Index(i).Interface()
+	}
+	return result
+}
+
+// sortDocuments sorts by the given expressions, then by primary key.
+// Documents without a sort field come last.
+func sortDocuments(docs []search.Document, sortBy []string, primaryKey string) {
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for _, s := range sortBy {
+		field, desc, _ := search.ParseSort(s)
+		keys = append(keys, key{field, desc})
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, k := range keys {
+			a, aok := docs[i][k.field]
+			b, bok := docs[j][k.field]
+			switch {
+			case !aok && !bok:
+				continue
+			case !aok:
+				return false
+			case !bok:
+				return true
+			}
+			c := compare(a, b)
+			if c == 0 {
+				continue
+			}
+			if k.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return fmt.Sprint(docs[i][primaryKey]) < fmt.Sprint(docs[j][primaryKey])
+	})
+}
+
+func compare(a, b any) int {
+	x, aok := search.ToFloat(a)
+	y, bok := search.ToFloat(b)
+	if aok && bok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -22,7 +22,8 @@ func init() {
 	)
 }
 
-// Provider is a no-op Search provider.
+// Provider is a no-op Search provider. It stores nothing, but validates
+// queries like a real engine so that invalid filters surface in development.
 type Provider struct{}
 
 // NewProvider creates a new no-op Search provider.
@@ -31,6 +32,9 @@ func NewProvider(config map[string]any) (search.SearchProvider, error) {
 }
 
 func (p *Provider) IndexDocuments(ctx context.Context, index string, documents []search.Document) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return &search.TaskResult{
 		TaskID: "noop-task-001",
 		Status: "succeeded",
@@ -39,6 +43,9 @@ func (p *Provider) IndexDocuments(ctx context.Context, index string, documents [
 }
 
 func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return &search.TaskResult{
 		TaskID: "noop-task-002",
 		Status: "succeeded",
@@ -47,10 +54,19 @@ func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []stri
 }
 
 func (p *Provider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (p *Provider) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := search.ValidateQuery(query); err != nil {
+		return nil, err
+	}
 	return &search.SearchResult{
 		Hits:             []search.Document{},
 		TotalHits:        0,
@@ -60,14 +76,23 @@ func (p *Provider) Search(ctx context.Context, index string, query search.Search
 }
 
 func (p *Provider) CreateIndex(ctx context.Context, index string, primaryKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (p *Provider) DeleteIndex(ctx context.Context, index string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return &search.TaskResult{
 		TaskID: taskID,
 		Status: "succeeded",
@@ -76,7 +101,7 @@ func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.Ta
 }
 
 func (p *Provider) Health(ctx context.Context) error {
-	return nil
+	return ctx.Err()
 }
 
 func (p *Provider) Metadata() search.Metadata {
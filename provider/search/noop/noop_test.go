@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/search/searchtest"
+)
+
+func TestConformance(t *testing.T) {
+	searchtest.Run(t, func(t *testing.T) search.SearchProvider {
+		return &Provider{}
+	}, searchtest.Options{Stateless: true})
+}
@@ -18,44 +18,48 @@ import (
 )
 
 func init() {
-	provider.Register[search.SearchProvider]("search", "opensearch",
+	provider.RegisterTyped[search.SearchProvider, OpenSearchConfig]("search", "opensearch",
 		provider.Metadata{
 			Name:        "opensearch",
 			DisplayName: "OpenSearch",
 			Category:    "search",
 			Version:     "1.0.0",
 			Description: "OpenSearch search engine integration",
-			ConfigSpec: []provider.ConfigField{
-				{
-					Key:         "addresses",
-					Type:        "array",
-					Required:    true,
-					Description: "OpenSearch server addresses",
-				},
-				{
-					Key:         "username",
-					Type:        "string",
-					Required:    false,
-					Description: "OpenSearch username",
-				},
-				{
-					Key:         "password",
-					Type:        "secret",
-					Required:    false,
-					Description: "OpenSearch password",
-				},
-				{
-					Key:         "insecure_skip_verify",
-					Type:        "bool",
-					Required:    false,
-					Description: "Skip TLS certificate verification",
-				},
-			},
+			ConfigSpec:  configSpec,
 		},
-		NewProvider,
+		New,
 	)
 }
 
+// configSpec declares the configuration fields accepted by the provider.
+var configSpec = []provider.ConfigField{
+	{
+		Key:         "addresses",
+		Type:        "array",
+		Required:    true,
+		Description: "OpenSearch server addresses",
+	},
+	{
+		Key:         "username",
+		Type:        "string",
+		Required:    false,
+		Description: "OpenSearch username",
+	},
+	{
+		Key:         "password",
+		Type:        "secret",
+		Required:    false,
+		Description: "OpenSearch password",
+	},
+	{
+		Key:         "insecure_skip_verify",
+		Type:        "bool",
+		Required:    false,
+		Default:     false,
+		Description: "Skip TLS certificate verification",
+	},
+}
+
 // germanDecompoundWords is a curated word list for B2B/industrial product search.
 // The dictionary_decompounder filter uses this to split German compound words
 // (e.g., "Sicherheitshandschuhe" → "Sicherheit" + "Handschuhe").
@@ -100,43 +104,50 @@ var germanDecompoundWords = []string{
 
 // OpenSearchConfig holds the OpenSearch configuration
 type OpenSearchConfig struct {
-	Addresses          []string
-	Username           string
-	Password           string
-	InsecureSkipVerify bool
+	Addresses          []string `config:"addresses"`
+	Username           string   `config:"username"`
+	Password           string   `config:"password"`
+	InsecureSkipVerify bool     `config:"insecure_skip_verify"`
 }
 
 // Provider is an OpenSearch search provider.
 type Provider struct {
-	client *opensearchapi.Client
+	client    *opensearchapi.Client
+	transport *http.Transport
 }
 
-// NewProvider creates a new OpenSearch search provider.
+// NewProvider creates a new OpenSearch search provider from a raw configuration map.
+// Prefer resolving the provider through the registry, which validates the config first.
 func NewProvider(config map[string]any) (search.SearchProvider, error) {
-	// Parse addresses
-	var addresses []string
-	if addrAny, ok := config["addresses"]; ok {
-		switch v := addrAny.(type) {
-		case []string:
-			addresses = v
-		case []any:
-			for _, addr := range v {
-				if s, ok := addr.(string); ok {
-					addresses = append(addresses, s)
-				}
-			}
-		case string:
-			addresses = []string{v}
-		}
+	validated, err := provider.ValidateConfig(configSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("opensearch: %w", err)
+	}
+	var cfg OpenSearchConfig
+	if err := provider.Bind(validated, &cfg); err != nil {
+		return nil, fmt.Errorf("opensearch: %w", err)
 	}
+	return New(cfg)
+}
 
+// New creates a new OpenSearch search provider from a typed configuration.
+func New(config OpenSearchConfig) (search.SearchProvider, error) {
+	addresses := config.Addresses
 	if len(addresses) == 0 {
 		return nil, fmt.Errorf("opensearch: at least one address is required")
 	}
 
-	username, _ := config["username"].(string)
-	password, _ := config["password"].(string)
-	insecureSkipVerify, _ := config["insecure_skip_verify"].(bool)
+	username := config.Username
+	password := config.Password
+	insecureSkipVerify := config.InsecureSkipVerify
+
+	// Use a transport of our own so Close does not affect other clients
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
 
 	// Create OpenSearch client config
 	cfg := opensearchapi.Config{
@@ -144,37 +155,43 @@ func NewProvider(config map[string]any) (search.SearchProvider, error) {
 			Addresses: addresses,
 			Username:  username,
 			Password:  password,
+			Transport: transport,
 		},
 	}
 
-	if insecureSkipVerify {
-		cfg.Client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
-	}
-
 	client, err := opensearchapi.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("opensearch: failed to create client: %w", err)
 	}
 
 	return &Provider{
-		client: client,
+		client:    client,
+		transport: transport,
 	}, nil
 }
 
 func (p *Provider) IndexDocuments(ctx context.Context, index string, documents []search.Document) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(documents) == 0 {
+		return &search.TaskResult{TaskID: "bulk-index", Status: "succeeded"}, nil
+	}
+
 	// OpenSearch bulk indexing
 	var buf bytes.Buffer
 
-	for _, doc := range documents {
+	for i, doc := range documents {
+		id, ok := doc["id"]
+		if !ok || id == nil {
+			return nil, fmt.Errorf("opensearch: document %d has no id: %w", i, provider.ErrInvalidArgument)
+		}
+
 		// Action line
 		action := map[string]any{
 			"index": map[string]any{
 				"_index": index,
-				"_id":    doc["id"],
+				"_id":    fmt.Sprint(id),
 			},
 		}
 		if err := json.NewEncoder(&buf).Encode(action); err != nil {
@@ -187,13 +204,15 @@ func (p *Provider) IndexDocuments(ctx context.Context, index string, documents [
 		}
 	}
 
+	// Wait for a refresh so that a succeeded task is visible to Search, as with the other providers
 	req := opensearchapi.BulkReq{
-		Body: bytes.NewReader(buf.Bytes()),
+		Body:   bytes.NewReader(buf.Bytes()),
+		Params: opensearchapi.BulkParams{Refresh: "wait_for"},
 	}
 
 	resp, err := p.client.Bulk(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("opensearch: bulk indexing failed: %w", err)
+		return nil, wrapError(ctx, "bulk indexing failed", err)
 	}
 
 	if resp.Errors {
@@ -208,6 +227,13 @@ func (p *Provider) IndexDocuments(ctx context.Context, index string, documents [
 }
 
 func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return &search.TaskResult{TaskID: "bulk-delete", Status: "succeeded"}, nil
+	}
+
 	var buf bytes.Buffer
 
 	for _, id := range ids {
@@ -223,13 +249,15 @@ func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []stri
 		}
 	}
 
+	// Deleting missing documents is not reported as an error by the bulk API
 	req := opensearchapi.BulkReq{
-		Body: bytes.NewReader(buf.Bytes()),
+		Body:   bytes.NewReader(buf.Bytes()),
+		Params: opensearchapi.BulkParams{Refresh: "wait_for"},
 	}
 
 	resp, err := p.client.Bulk(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("opensearch: delete failed: %w", err)
+		return nil, wrapError(ctx, "delete failed", err)
 	}
 
 	if resp.Errors {
@@ -243,6 +271,10 @@ func (p *Provider) DeleteDocuments(ctx context.Context, index string, ids []stri
 }
 
 func (p *Provider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Create index with mappings and settings
 	mappings := map[string]any{
 		"properties": map[string]any{
@@ -383,7 +415,7 @@ func (p *Provider) ConfigureIndex(ctx context.Context, index string, config sear
 	if err != nil {
 		// Ignore "already exists" errors
 		if !strings.Contains(err.Error(), "resource_already_exists") {
-			return fmt.Errorf("opensearch: failed to configure index: %w", err)
+			return wrapError(ctx, "failed to configure index", err)
 		}
 	}
 
@@ -391,6 +423,10 @@ func (p *Provider) ConfigureIndex(ctx context.Context, index string, config sear
 }
 
 func (p *Provider) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	if err := search.ValidateQuery(query); err != nil {
+		return nil, fmt.Errorf("opensearch: %w", err)
+	}
+
 	// Build multi_match query across all language fields
 	var queryObj map[string]any
 
@@ -449,13 +485,23 @@ func (p *Provider) Search(ctx context.Context, index string, query search.Search
 		"query": map[string]any{
 			"bool": boolQuery,
 		},
-		"from": query.Offset,
-		"size": query.Limit,
+		"from":             query.Offset,
+		"size":             query.EffectiveLimit(),
+		"track_total_hits": true,
 	}
 
-	// Add sort if specified
+	// Add sort if specified ("field:desc" -> {"field": {"order": "desc"}})
 	if len(query.Sort) > 0 {
-		searchBody["sort"] = query.Sort
+		sorts := make([]map[string]any, 0, len(query.Sort))
+		for _, s := range query.Sort {
+			field, desc, _ := search.ParseSort(s)
+			order := "asc"
+			if desc {
+				order = "desc"
+			}
+			sorts = append(sorts, map[string]any{field: map[string]any{"order": order}})
+		}
+		searchBody["sort"] = sorts
 	}
 
 	// Add aggregations for faceted search
@@ -486,7 +532,7 @@ func (p *Provider) Search(ctx context.Context, index string, query search.Search
 
 	resp, err := p.client.Search(ctx, &req)
 	if err != nil {
-		return nil, fmt.Errorf("opensearch: search failed: %w", err)
+		return nil, wrapError(ctx, "search failed", err)
 	}
 
 	// Convert hits to documents
@@ -539,7 +585,7 @@ func (p *Provider) CreateIndex(ctx context.Context, index string, primaryKey str
 	if err != nil {
 		// Ignore "already exists" errors
 		if !strings.Contains(err.Error(), "resource_already_exists") {
-			return fmt.Errorf("opensearch: failed to create index: %w", err)
+			return wrapError(ctx, "failed to create index", err)
 		}
 	}
 
@@ -553,13 +599,21 @@ func (p *Provider) DeleteIndex(ctx context.Context, index string) error {
 
 	_, err := p.client.Indices.Delete(ctx, req)
 	if err != nil {
-		return fmt.Errorf("opensearch: failed to delete index: %w", err)
+		// Deleting a missing index is not an error
+		if ctx.Err() == nil && isIndexNotFound(err) {
+			return nil
+		}
+		return wrapError(ctx, "failed to delete index", err)
 	}
 
 	return nil
 }
 
 func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.TaskResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// OpenSearch operations are mostly synchronous, so tasks are completed immediately
 	return &search.TaskResult{
 		TaskID: taskID,
@@ -570,7 +624,7 @@ func (p *Provider) GetTaskStatus(ctx context.Context, taskID string) (*search.Ta
 func (p *Provider) Health(ctx context.Context) error {
 	resp, err := p.client.Cluster.Health(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("opensearch: health check failed: %w", err)
+		return wrapError(ctx, "health check failed", err)
 	}
 
 	if resp.Status != "green" && resp.Status != "yellow" {
@@ -580,6 +634,18 @@ func (p *Provider) Health(ctx context.Context) error {
 	return nil
 }
 
+// Start opens a connection to the cluster and checks that it is healthy.
+func (p *Provider) Start(ctx context.Context) error {
+	return p.Health(ctx)
+}
+
+// Close releases the idle connections of the client. Requests still in flight
+// complete normally.
+func (p *Provider) Close(ctx context.Context) error {
+	p.transport.CloseIdleConnections()
+	return nil
+}
+
 func (p *Provider) Metadata() search.Metadata {
 	return search.Metadata{
 		Name:    "opensearch",
@@ -594,26 +660,32 @@ func (p *Provider) Metadata() search.Metadata {
 	}
 }
 
-// buildOpenSearchFilter converts search.Filter to OpenSearch query DSL
+// wrapError adds context to a client error and maps it to the errors of package search.
+func wrapError(ctx context.Context, msg string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("opensearch: %s: %w", msg, ctxErr)
+	}
+	if isIndexNotFound(err) {
+		return fmt.Errorf("opensearch: %s: %w: %w", msg, search.ErrIndexNotFound, err)
+	}
+	return fmt.Errorf("opensearch: %s: %w", msg, err)
+}
+
+func isIndexNotFound(err error) bool {
+	return strings.Contains(err.Error(), "index_not_found_exception")
+}
+
+// buildOpenSearchFilter converts search.Filter to OpenSearch query DSL.
+// The filter must have been validated with search.ValidateQuery.
 func buildOpenSearchFilter(f search.Filter) map[string]any {
 	switch f.Operator {
-	case "=":
-		// Use "terms" for slice values (e.g., category hierarchy), "term" for single values
-		switch v := f.Value.(type) {
-		case []string:
-			return map[string]any{
-				"terms": map[string]any{
-					f.Field: v,
-				},
-			}
-		default:
-			return map[string]any{
-				"term": map[string]any{
-					f.Field: f.Value,
-				},
-			}
+	case search.OpEqual:
+		return map[string]any{
+			"term": map[string]any{
+				f.Field: f.Value,
+			},
 		}
-	case "!=":
+	case search.OpNotEqual:
 		return map[string]any{
 			"bool": map[string]any{
 				"must_not": map[string]any{
@@ -623,7 +695,7 @@ func buildOpenSearchFilter(f search.Filter) map[string]any {
 				},
 			},
 		}
-	case ">":
+	case search.OpGreater:
 		return map[string]any{
 			"range": map[string]any{
 				f.Field: map[string]any{
@@ -631,7 +703,7 @@ func buildOpenSearchFilter(f search.Filter) map[string]any {
 				},
 			},
 		}
-	case "<":
+	case search.OpLess:
 		return map[string]any{
 			"range": map[string]any{
 				f.Field: map[string]any{
@@ -639,7 +711,7 @@ func buildOpenSearchFilter(f search.Filter) map[string]any {
 				},
 			},
 		}
-	case ">=":
+	case search.OpGreaterOrEqual:
 		return map[string]any{
 			"range": map[string]any{
 				f.Field: map[string]any{
@@ -647,7 +719,7 @@ func buildOpenSearchFilter(f search.Filter) map[string]any {
 				},
 			},
 		}
-	case "<=":
+	case search.OpLessOrEqual:
 		return map[string]any{
 			"range": map[string]any{
 				f.Field: map[string]any{
@@ -655,25 +727,21 @@ func buildOpenSearchFilter(f search.Filter) map[string]any {
 				},
 			},
 		}
-	case "IN":
+	case search.OpIn:
 		return map[string]any{
 			"terms": map[string]any{
-				f.Field: f.Value,
+				f.Field: f.Values(),
 			},
 		}
-	case "NOT IN":
+	default: // search.OpNotIn
 		return map[string]any{
 			"bool": map[string]any{
 				"must_not": map[string]any{
 					"terms": map[string]any{
-						f.Field: f.Value,
+						f.Field: f.Values(),
 					},
 				},
 			},
 		}
-	default:
-		return map[string]any{
-			"match_all": map[string]any{},
-		}
 	}
 }
@@ -0,0 +1,58 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/search/searchtest"
+)
+
+func TestBuildOpenSearchFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter search.Filter
+		want   string
+	}{
+		{"equal", search.Filter{Field: "status", Operator: "=", Value: "active"}, `{"term":{"status":"active"}}`},
+		{"not equal", search.Filter{Field: "status", Operator: "!=", Value: "draft"}, `{"bool":{"must_not":{"term":{"status":"draft"}}}}`},
+		{"range", search.Filter{Field: "price", Operator: "<", Value: 5}, `{"range":{"price":{"lt":5}}}`},
+		{"in string slice", search.Filter{Field: "category_ids", Operator: "IN", Value: []string{"a", "b"}}, `{"terms":{"category_ids":["a","b"]}}`},
+		{"not in any slice", search.Filter{Field: "size", Operator: "NOT IN", Value: []any{1, "x"}}, `{"bool":{"must_not":{"terms":{"size":[1,"x"]}}}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(buildOpenSearchFilter(tc.filter))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("buildOpenSearchFilter() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestConformance runs the search conformance suite against a live cluster.
+// It is skipped unless OPENSEARCH_TEST_ADDRESSES (comma separated) is set.
+func TestConformance(t *testing.T) {
+	addresses := os.Getenv("OPENSEARCH_TEST_ADDRESSES")
+	if addresses == "" {
+		t.Skip("OPENSEARCH_TEST_ADDRESSES not set")
+	}
+
+	searchtest.Run(t, func(t *testing.T) search.SearchProvider {
+		p, err := New(OpenSearchConfig{
+			Addresses:          strings.Split(addresses, ","),
+			Username:           os.Getenv("OPENSEARCH_TEST_USERNAME"),
+			Password:           os.Getenv("OPENSEARCH_TEST_PASSWORD"),
+			InsecureSkipVerify: true,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return p
+	}, searchtest.Options{})
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gondolia/gondolia/provider"
+)
+
+// ValidateQuery checks a query against the semantics shared by all providers.
+// Providers call it before translating the query to their backend's syntax.
+func ValidateQuery(query SearchQuery) error {
+	if query.Offset < 0 {
+		return fmt.Errorf("offset must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	if query.Limit < 0 {
+		return fmt.Errorf("limit must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	for _, s := range query.Sort {
+		if _, _, err := ParseSort(s); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, f := range query.Filters {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EffectiveLimit returns the page size of the query, applying DefaultLimit.
+func (q SearchQuery) EffectiveLimit() int {
+	if q.Limit == 0 {
+		return DefaultLimit
+	}
+	return q.Limit
+}
+
+// Validate checks the filter's operator and value.
+func (f Filter) Validate() error {
+	if f.Field == "" {
+		return fmt.Errorf("%w: field is required", ErrInvalidFilter)
+	}
+
+	switch f.Operator {
+	case OpIn, OpNotIn:
+		if !isSlice(f.Value) {
+			return fmt.Errorf("%w: %s %s requires a slice value, got %T", ErrInvalidFilter, f.Field, f.Operator, f.Value)
+		}
+		for _, v := range f.Values() {
+			if !isScalar(v) {
+				return fmt.Errorf("%w: %s %s contains unsupported value %T", ErrInvalidFilter, f.Field, f.Operator, v)
+			}
+		}
+	case OpEqual, OpNotEqual:
+		if !isScalar(f.Value) {
+			return fmt.Errorf("%w: %s %s requires a scalar value, got %T (use IN for lists)", ErrInvalidFilter, f.Field, f.Operator, f.Value)
+		}
+	case OpGreater, OpLess, OpGreaterOrEqual, OpLessOrEqual:
+		if _, ok := ToFloat(f.Value); !ok {
+			return fmt.Errorf("%w: %s %s requires a number, got %T", ErrInvalidFilter, f.Field, f.Operator, f.Value)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, f.Operator)
+	}
+	return nil
+}
+
+// Values returns the elements of an IN or NOT IN filter value. Any slice type
+// is accepted; a scalar value is returned as a single element.
+func (f Filter) Values() []any {
+	v := reflect.ValueOf(f.Value)
+	if !isSlice(f.Value) {
+		return []any{f.Value}
+	}
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+// ParseSort parses a sort expression of the form "field", "field:asc" or "field:desc".
+func ParseSort(s string) (field string, desc bool, err error) {
+	field, dir, _ := strings.Cut(s, ":")
+	if field == "" {
+		return "", false, fmt.Errorf("invalid sort %q: field is required: %w", s, provider.ErrInvalidArgument)
+	}
+	switch dir {
+	case "", "asc":
+		return field, false, nil
+	case "desc":
+		return field, true, nil
+	}
+	return "", false, fmt.Errorf("invalid sort %q: direction must be asc or desc: %w", s, provider.ErrInvalidArgument)
+}
+
+// ToFloat converts a numeric filter or document value to float64.
+func ToFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func isSlice(value any) bool {
+	if value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func isScalar(value any) bool {
+	if _, ok := value.(string); ok {
+		return true
+	}
+	if _, ok := value.(bool); ok {
+		return true
+	}
+	_, ok := ToFloat(value)
+	return ok
+}
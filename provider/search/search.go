@@ -1,7 +1,12 @@
 // Package search defines the interface for search engine integrations.
 package search
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/gondolia/gondolia/provider"
+)
 
 // SearchProvider abstracts search engines (Meilisearch, Algolia, Elasticsearch, etc.).
 type SearchProvider interface {
@@ -43,17 +48,44 @@ type SearchProvider interface {
 type Document map[string]any
 
 // SearchQuery represents a search request.
+// A Limit of 0 means DefaultLimit; Offset and Limit must not be negative.
 type SearchQuery struct {
 	Query     string
-	Filters   []Filter
+	Filters   []Filter // Combined with AND
 	Facets    []string
-	Sort      []string
+	Sort      []string // "field:asc" or "field:desc"; a bare field sorts ascending
 	Offset    int
 	Limit     int
 	Highlight []string
 }
 
-// Filter represents a search filter.
+// DefaultLimit is the page size used when SearchQuery.Limit is 0.
+const DefaultLimit = 20
+
+// Filter operators.
+const (
+	OpEqual          = "="
+	OpNotEqual       = "!="
+	OpGreater        = ">"
+	OpLess           = "<"
+	OpGreaterOrEqual = ">="
+	OpLessOrEqual    = "<="
+	OpIn             = "IN"
+	OpNotIn          = "NOT IN"
+)
+
+// Filter represents a search filter. All providers implement the same semantics:
+//
+//   - "=" matches documents whose field equals Value. For array fields it
+//     matches if any element equals Value.
+//   - "!=" is the negation of "=" and also matches documents without the field.
+//   - ">", "<", ">=", "<=" compare numbers. Documents without the field never match.
+//   - "IN" matches if the field (or any element of an array field) equals one
+//     of the values. Value must be a slice of any element type, e.g. []string.
+//   - "NOT IN" is the negation of "IN" and also matches documents without the field.
+//
+// Every operator except IN and NOT IN takes a scalar Value. Unknown operators
+// and mismatched values are rejected with ErrInvalidFilter, never ignored.
 type Filter struct {
 	Field    string
 	Operator string // "=", "!=", ">", "<", ">=", "<=", "IN", "NOT IN"
@@ -91,6 +123,15 @@ type TaskResult struct {
 	Error  string
 }
 
+// Errors returned by search providers.
+var (
+	// ErrInvalidFilter is returned for filters that violate the semantics documented on Filter.
+	ErrInvalidFilter = fmt.Errorf("invalid search filter: %w", provider.ErrInvalidArgument)
+
+	// ErrIndexNotFound is returned when searching an index that does not exist.
+	ErrIndexNotFound = fmt.Errorf("search index %w", provider.ErrNotFound)
+)
+
 // Metadata provides information about the search provider.
 type Metadata struct {
 	Name     string
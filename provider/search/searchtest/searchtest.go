@@ -0,0 +1,386 @@
+// Package searchtest is a conformance suite for search.SearchProvider
+// implementations. It checks the behavior every caller relies on regardless of
+// the engine: filter operator semantics (see search.Filter), pagination and
+// sorting, idempotent deletes, error types and context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    searchtest.Run(t, func(t *testing.T) search.SearchProvider {
+//	        p, err := mysearch.New(mysearch.Config{...})
+//	        if err != nil {
+//	            t.Fatal(err)
+//	        }
+//	        return p
+//	    }, searchtest.Options{})
+//	}
+//
+// Every test creates its own randomly named index and deletes it afterwards,
+// so the suite can run against a shared server.
+package searchtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/providertest"
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that do not store documents, such as noop.
+	// Checks that depend on indexed data are skipped.
+	Stateless bool
+
+	// TaskTimeout bounds the wait for asynchronous tasks. Defaults to 30s.
+	TaskTimeout time.Duration
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) search.SearchProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	if opts.TaskTimeout == 0 {
+		opts.TaskTimeout = 30 * time.Second
+	}
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidQueries", s.testInvalidQueries)
+	t.Run("IdempotentDeletes", s.testIdempotentDeletes)
+	t.Run("IndexNotFound", s.stateful(s.testIndexNotFound))
+	t.Run("Pagination", s.stateful(s.testPagination))
+	t.Run("FilterOperators", s.stateful(s.testFilterOperators))
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) stateful(test func(t *testing.T)) func(t *testing.T) {
+	return func(t *testing.T) {
+		if s.opts.Stateless {
+			t.Skip("provider is stateless")
+		}
+		test(t)
+	}
+}
+
+// fixtureDoc is a document of the test data set.
+type fixtureDoc struct {
+	ID    string
+	N     int
+	Color string // Empty means the field is missing
+	Size  int
+	Tags  []string
+}
+
+func (d fixtureDoc) document() search.Document {
+	doc := search.Document{
+		"id":   d.ID,
+		"n":    d.N,
+		"size": d.Size,
+		"tags": d.Tags,
+	}
+	if d.Color != "" {
+		doc["color"] = d.Color
+	}
+	return doc
+}
+
+// fixture returns 12 documents doc-00 to doc-11. Sizes cycle through 0, 10,
+// 20, 30, colors through red, green, blue; doc-11 has no color.
+func fixture() []fixtureDoc {
+	colors := []string{"red", "green", "blue"}
+	docs := make([]fixtureDoc, 12)
+	for i := range docs {
+		d := fixtureDoc{ID: fmt.Sprintf("doc-%02d", i), N: i, Size: (i % 4) * 10, Tags: []string{}}
+		if i != 11 {
+			d.Color = colors[i%3]
+		}
+		if i%2 == 0 {
+			d.Tags = append(d.Tags, "even")
+		}
+		if i%3 == 0 {
+			d.Tags = append(d.Tags, "three")
+		}
+		docs[i] = d
+	}
+	return docs
+}
+
+// setup creates an index holding the fixture and returns it with its provider.
+func (s *suite) setup(t *testing.T) (search.SearchProvider, string) {
+	t.Helper()
+	ctx := context.Background()
+	p := s.newProvider(t)
+	index := providertest.UniqueName("conformance_")
+
+	if err := p.CreateIndex(ctx, index, "id"); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	t.Cleanup(func() { p.DeleteIndex(context.Background(), index) })
+
+	err := p.ConfigureIndex(ctx, index, search.IndexConfig{
+		FilterableAttributes: []string{"n", "color", "size", "tags"},
+		SortableAttributes:   []string{"n", "size"},
+	})
+	if err != nil {
+		t.Fatalf("ConfigureIndex() error = %v", err)
+	}
+
+	var docs []search.Document
+	for _, d := range fixture() {
+		docs = append(docs, d.document())
+	}
+	task, err := p.IndexDocuments(ctx, index, docs)
+	if err != nil {
+		t.Fatalf("IndexDocuments() error = %v", err)
+	}
+	s.wait(t, p, task)
+	return p, index
+}
+
+// wait polls an asynchronous task until it succeeded.
+func (s *suite) wait(t *testing.T, p search.SearchProvider, task *search.TaskResult) {
+	t.Helper()
+	deadline := time.Now().Add(s.opts.TaskTimeout)
+	for task.Status != "succeeded" {
+		if task.Status == "failed" {
+			t.Fatalf("task %s failed: %s", task.TaskID, task.Error)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s still %s after %s", task.TaskID, task.Status, s.opts.TaskTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		var err error
+		task, err = p.GetTaskStatus(context.Background(), task.TaskID)
+		if err != nil {
+			t.Fatalf("GetTaskStatus() error = %v", err)
+		}
+	}
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+	index := "conformance_canceled"
+
+	_, err := p.IndexDocuments(ctx, index, []search.Document{{"id": "1"}})
+	providertest.ExpectCanceled(t, "IndexDocuments", err)
+	_, err = p.DeleteDocuments(ctx, index, []string{"1"})
+	providertest.ExpectCanceled(t, "DeleteDocuments", err)
+	providertest.ExpectCanceled(t, "ConfigureIndex", p.ConfigureIndex(ctx, index, search.IndexConfig{}))
+	_, err = p.Search(ctx, index, search.SearchQuery{})
+	providertest.ExpectCanceled(t, "Search", err)
+	providertest.ExpectCanceled(t, "CreateIndex", p.CreateIndex(ctx, index, "id"))
+	providertest.ExpectCanceled(t, "DeleteIndex", p.DeleteIndex(ctx, index))
+	_, err = p.GetTaskStatus(ctx, "1")
+	providertest.ExpectCanceled(t, "GetTaskStatus", err)
+	providertest.ExpectCanceled(t, "Health", p.Health(ctx))
+}
+
+func (s *suite) testInvalidQueries(t *testing.T) {
+	p, index := s.setup(t)
+
+	filters := []struct {
+		name   string
+		filter search.Filter
+	}{
+		{"unknown operator", search.Filter{Field: "color", Operator: "LIKE", Value: "red"}},
+		{"lower case operator", search.Filter{Field: "color", Operator: "in", Value: []string{"red"}}},
+		{"empty field", search.Filter{Operator: "=", Value: "red"}},
+		{"= with slice", search.Filter{Field: "color", Operator: "=", Value: []string{"red", "blue"}}},
+		{"!= with nil", search.Filter{Field: "color", Operator: "!=", Value: nil}},
+		{"IN with scalar", search.Filter{Field: "color", Operator: "IN", Value: "red"}},
+		{"NOT IN with scalar", search.Filter{Field: "color", Operator: "NOT IN", Value: "red"}},
+		{"> with string", search.Filter{Field: "size", Operator: ">", Value: "10"}},
+	}
+	for _, tc := range filters {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Search(context.Background(), index, search.SearchQuery{Filters: []search.Filter{tc.filter}})
+			providertest.ExpectError(t, "Search", err, search.ErrInvalidFilter)
+			providertest.ExpectError(t, "Search", err, provider.ErrInvalidArgument)
+		})
+	}
+
+	queries := []struct {
+		name  string
+		query search.SearchQuery
+	}{
+		{"negative offset", search.SearchQuery{Offset: -1}},
+		{"negative limit", search.SearchQuery{Limit: -1}},
+		{"invalid sort direction", search.SearchQuery{Sort: []string{"n:up"}}},
+	}
+	for _, tc := range queries {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Search(context.Background(), index, tc.query)
+			providertest.ExpectError(t, "Search", err, provider.ErrInvalidArgument)
+		})
+	}
+}
+
+func (s *suite) testIdempotentDeletes(t *testing.T) {
+	ctx := context.Background()
+	p, index := s.setup(t)
+
+	ids := []string{"doc-00", "conformance-missing"}
+	for i := 0; i < 2; i++ {
+		task, err := p.DeleteDocuments(ctx, index, ids)
+		if err != nil {
+			t.Fatalf("DeleteDocuments() #%d error = %v", i+1, err)
+		}
+		s.wait(t, p, task)
+	}
+
+	if !s.opts.Stateless {
+		result, err := p.Search(ctx, index, search.SearchQuery{Limit: 100})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if result.TotalHits != 11 {
+			t.Errorf("TotalHits after delete = %d, want 11", result.TotalHits)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := p.DeleteIndex(ctx, index); err != nil {
+			t.Errorf("DeleteIndex() #%d error = %v", i+1, err)
+		}
+	}
+}
+
+func (s *suite) testIndexNotFound(t *testing.T) {
+	p := s.newProvider(t)
+	_, err := p.Search(context.Background(), providertest.UniqueName("conformance_missing_"), search.SearchQuery{})
+	providertest.ExpectError(t, "Search", err, search.ErrIndexNotFound)
+	providertest.ExpectError(t, "Search", err, provider.ErrNotFound)
+}
+
+func (s *suite) testPagination(t *testing.T) {
+	p, index := s.setup(t)
+	all := fixtureIDs(func(fixtureDoc) bool { return true })
+
+	tests := []struct {
+		name  string
+		query search.SearchQuery
+		want  []string
+	}{
+		{"first page", search.SearchQuery{Sort: []string{"n:asc"}, Limit: 5}, all[0:5]},
+		{"second page", search.SearchQuery{Sort: []string{"n:asc"}, Offset: 5, Limit: 5}, all[5:10]},
+		{"last page", search.SearchQuery{Sort: []string{"n:asc"}, Offset: 10, Limit: 5}, all[10:]},
+		{"beyond last page", search.SearchQuery{Sort: []string{"n:asc"}, Offset: 20, Limit: 5}, []string{}},
+		{"default limit", search.SearchQuery{Sort: []string{"n"}}, all},
+		{"descending", search.SearchQuery{Sort: []string{"n:desc"}, Limit: 3}, []string{"doc-11", "doc-10", "doc-09"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Search(context.Background(), index, tc.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if got := hitIDs(result); !slices.Equal(got, tc.want) {
+				t.Errorf("hits = %v, want %v", got, tc.want)
+			}
+			if result.TotalHits != len(all) {
+				t.Errorf("TotalHits = %d, want %d (independent of the page)", result.TotalHits, len(all))
+			}
+		})
+	}
+}
+
+func (s *suite) testFilterOperators(t *testing.T) {
+	p, index := s.setup(t)
+
+	hasTag := func(tag string) func(fixtureDoc) bool {
+		return func(d fixtureDoc) bool { return slices.Contains(d.Tags, tag) }
+	}
+
+	tests := []struct {
+		name    string
+		filters []search.Filter
+		match   func(fixtureDoc) bool
+	}{
+		{"= string", []search.Filter{{Field: "color", Operator: "=", Value: "red"}},
+			func(d fixtureDoc) bool { return d.Color == "red" }},
+		{"= number", []search.Filter{{Field: "size", Operator: "=", Value: 20}},
+			func(d fixtureDoc) bool { return d.Size == 20 }},
+		{"= array element", []search.Filter{{Field: "tags", Operator: "=", Value: "even"}},
+			hasTag("even")},
+		{"!= matches missing field", []search.Filter{{Field: "color", Operator: "!=", Value: "red"}},
+			func(d fixtureDoc) bool { return d.Color != "red" }},
+		{">", []search.Filter{{Field: "size", Operator: ">", Value: 10}},
+			func(d fixtureDoc) bool { return d.Size > 10 }},
+		{"<", []search.Filter{{Field: "size", Operator: "<", Value: 10}},
+			func(d fixtureDoc) bool { return d.Size < 10 }},
+		{">=", []search.Filter{{Field: "size", Operator: ">=", Value: 20}},
+			func(d fixtureDoc) bool { return d.Size >= 20 }},
+		{"<=", []search.Filter{{Field: "size", Operator: "<=", Value: 10.5}},
+			func(d fixtureDoc) bool { return d.Size <= 10 }},
+		{"IN []string", []search.Filter{{Field: "color", Operator: "IN", Value: []string{"red", "blue"}}},
+			func(d fixtureDoc) bool { return d.Color == "red" || d.Color == "blue" }},
+		{"IN []int", []search.Filter{{Field: "size", Operator: "IN", Value: []int{0, 30}}},
+			func(d fixtureDoc) bool { return d.Size == 0 || d.Size == 30 }},
+		{"IN array field", []search.Filter{{Field: "tags", Operator: "IN", Value: []any{"three"}}},
+			hasTag("three")},
+		{"NOT IN matches missing field", []search.Filter{{Field: "color", Operator: "NOT IN", Value: []string{"red", "green"}}},
+			func(d fixtureDoc) bool { return d.Color != "red" && d.Color != "green" }},
+		{"filters are combined with AND", []search.Filter{
+			{Field: "color", Operator: "=", Value: "green"},
+			{Field: "size", Operator: ">=", Value: 20},
+		}, func(d fixtureDoc) bool { return d.Color == "green" && d.Size >= 20 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Search(context.Background(), index, search.SearchQuery{
+				Filters: tc.filters,
+				Sort:    []string{"n:asc"},
+				Limit:   100,
+			})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			want := fixtureIDs(tc.match)
+			if got := hitIDs(result); !slices.Equal(got, want) {
+				t.Errorf("hits = %v, want %v", got, want)
+			}
+			if result.TotalHits != len(want) {
+				t.Errorf("TotalHits = %d, want %d", result.TotalHits, len(want))
+			}
+		})
+	}
+}
+
+// fixtureIDs returns the IDs of the fixture documents matching match, in order of n.
+func fixtureIDs(match func(fixtureDoc) bool) []string {
+	ids := []string{}
+	for _, d := range fixture() {
+		if match(d) {
+			ids = append(ids, d.ID)
+		}
+	}
+	return ids
+}
+
+func hitIDs(result *search.SearchResult) []string {
+	ids := []string{}
+	for _, hit := range result.Hits {
+		ids = append(ids, fmt.Sprint(hit["id"]))
+	}
+	return ids
+}
@@ -3,7 +3,9 @@ package noop
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/gondolia/gondolia/provider"
@@ -33,6 +35,12 @@ func NewProvider(config map[string]any) (storage.StorageProvider, error) {
 }
 
 func (p *Provider) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (*storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("noop: path is required: %w", provider.ErrInvalidArgument)
+	}
 	return &storage.FileInfo{
 		Path:         path,
 		Size:         0,
@@ -44,7 +52,13 @@ func (p *Provider) Upload(ctx context.Context, path string, reader io.Reader, op
 }
 
 func (p *Provider) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
-	return io.NopCloser(nil), &storage.FileInfo{
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if path == "" {
+		return nil, nil, fmt.Errorf("noop: path is required: %w", provider.ErrInvalidArgument)
+	}
+	return io.NopCloser(strings.NewReader("")), &storage.FileInfo{
 		Path:         path,
 		Size:         0,
 		ContentType:  "application/octet-stream",
@@ -55,18 +69,45 @@ func (p *Provider) Download(ctx context.Context, path string) (io.ReadCloser, *s
 }
 
 func (p *Provider) Delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("noop: path is required: %w", provider.ErrInvalidArgument)
+	}
 	return nil
 }
 
 func (p *Provider) Exists(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if path == "" {
+		return false, fmt.Errorf("noop: path is required: %w", provider.ErrInvalidArgument)
+	}
 	return false, nil
 }
 
 func (p *Provider) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("noop: path is required: %w", provider.ErrInvalidArgument)
+	}
+	if expiry <= 0 {
+		return "", fmt.Errorf("noop: expiry must be positive: %w", provider.ErrInvalidArgument)
+	}
 	return "https://example.com/noop/" + path, nil
 }
 
 func (p *Provider) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.MaxKeys < 0 {
+		return nil, fmt.Errorf("noop: MaxKeys must not be negative: %w", provider.ErrInvalidArgument)
+	}
 	return []storage.FileInfo{}, nil
 }
 
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/provider/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageProvider {
+		return &Provider{}
+	}, storagetest.Options{Stateless: true})
+}
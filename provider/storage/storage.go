@@ -12,10 +12,10 @@ type StorageProvider interface {
 	// Upload uploads a file.
 	Upload(ctx context.Context, path string, reader io.Reader, opts UploadOptions) (*FileInfo, error)
 
-	// Download downloads a file.
+	// Download downloads a file. Missing files return provider.ErrNotFound.
 	Download(ctx context.Context, path string) (io.ReadCloser, *FileInfo, error)
 
-	// Delete deletes a file.
+	// Delete deletes a file. Deleting a missing file is not an error.
 	Delete(ctx context.Context, path string) error
 
 	// Exists checks if a file exists.
@@ -24,7 +24,7 @@ type StorageProvider interface {
 	// GetSignedURL returns a temporary URL for direct access.
 	GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error)
 
-	// List lists files in a path.
+	// List lists the files whose path starts with prefix, ordered by path.
 	List(ctx context.Context, prefix string, opts ListOptions) ([]FileInfo, error)
 
 	// Metadata returns provider information.
@@ -39,9 +39,10 @@ type UploadOptions struct {
 }
 
 // ListOptions contains options for listing files.
+// A MaxKeys of 0 selects the provider's default page size.
 type ListOptions struct {
 	MaxKeys int
-	Cursor  string
+	Cursor  string // Path of the last file of the previous page; the page starts after it
 }
 
 // FileInfo represents information about a stored file.
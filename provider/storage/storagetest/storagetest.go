@@ -0,0 +1,238 @@
+// Package storagetest is a conformance suite for storage.StorageProvider
+// implementations. It checks upload and download round trips, listing with
+// cursor pagination, idempotent deletes, error types and context cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    storagetest.Run(t, func(t *testing.T) storage.StorageProvider {
+//	        return newTestProvider(t)
+//	    }, storagetest.Options{})
+//	}
+//
+// Files are written below a random "conformance/" prefix and deleted afterwards.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/providertest"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Stateless marks providers that store nothing, such as noop.
+	// Checks that read stored files back are skipped.
+	Stateless bool
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) storage.StorageProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("IdempotentDeletes", s.testIdempotentDeletes)
+	t.Run("NotFound", s.stateful(s.testNotFound))
+	t.Run("RoundTrip", s.stateful(s.testRoundTrip))
+	t.Run("Pagination", s.stateful(s.testPagination))
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) stateful(test func(t *testing.T)) func(t *testing.T) {
+	return func(t *testing.T) {
+		if s.opts.Stateless {
+			t.Skip("provider is stateless")
+		}
+		test(t)
+	}
+}
+
+// prefix returns a fresh directory for one test.
+func prefix() string {
+	return providertest.UniqueName("conformance/") + "/"
+}
+
+// upload stores content at path and deletes it when the test ends.
+func upload(t *testing.T, p storage.StorageProvider, path, content string) *storage.FileInfo {
+	t.Helper()
+	info, err := p.Upload(context.Background(), path, strings.NewReader(content), storage.UploadOptions{ContentType: "text/plain"})
+	if err != nil {
+		t.Fatalf("Upload(%s) error = %v", path, err)
+	}
+	t.Cleanup(func() { p.Delete(context.Background(), path) })
+	return info
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := providertest.CanceledContext()
+	path := prefix() + "canceled.txt"
+
+	_, err := p.Upload(ctx, path, strings.NewReader("x"), storage.UploadOptions{})
+	providertest.ExpectCanceled(t, "Upload", err)
+	rc, _, err := p.Download(ctx, path)
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectCanceled(t, "Download", err)
+	providertest.ExpectCanceled(t, "Delete", p.Delete(ctx, path))
+	_, err = p.Exists(ctx, path)
+	providertest.ExpectCanceled(t, "Exists", err)
+	_, err = p.GetSignedURL(ctx, path, time.Minute)
+	providertest.ExpectCanceled(t, "GetSignedURL", err)
+	_, err = p.List(ctx, path, storage.ListOptions{})
+	providertest.ExpectCanceled(t, "List", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	_, err := p.Upload(ctx, "", strings.NewReader("x"), storage.UploadOptions{})
+	providertest.ExpectError(t, "Upload with empty path", err, invalid)
+	rc, _, err := p.Download(ctx, "")
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectError(t, "Download with empty path", err, invalid)
+	providertest.ExpectError(t, "Delete with empty path", p.Delete(ctx, ""), invalid)
+	_, err = p.Exists(ctx, "")
+	providertest.ExpectError(t, "Exists with empty path", err, invalid)
+	_, err = p.GetSignedURL(ctx, prefix()+"file.txt", 0)
+	providertest.ExpectError(t, "GetSignedURL with zero expiry", err, invalid)
+	_, err = p.List(ctx, prefix(), storage.ListOptions{MaxKeys: -1})
+	providertest.ExpectError(t, "List with negative MaxKeys", err, invalid)
+}
+
+func (s *suite) testIdempotentDeletes(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	path := prefix() + "deleted.txt"
+
+	if !s.opts.Stateless {
+		upload(t, p, path, "content")
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.Delete(ctx, path); err != nil {
+			t.Errorf("Delete() #%d error = %v", i+1, err)
+		}
+	}
+
+	exists, err := p.Exists(ctx, path)
+	if err != nil {
+		t.Fatalf("Exists() error = %v", err)
+	}
+	if exists {
+		t.Error("file exists after Delete")
+	}
+}
+
+func (s *suite) testNotFound(t *testing.T) {
+	p := s.newProvider(t)
+
+	rc, _, err := p.Download(context.Background(), prefix()+"missing.txt")
+	if rc != nil {
+		rc.Close()
+	}
+	providertest.ExpectError(t, "Download of missing file", err, provider.ErrNotFound)
+}
+
+func (s *suite) testRoundTrip(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	path := prefix() + "hello.txt"
+	content := "hello, conformance"
+
+	info := upload(t, p, path, content)
+	if info.Path != path {
+		t.Errorf("Upload() Path = %q, want %q", info.Path, path)
+	}
+
+	exists, err := p.Exists(ctx, path)
+	if err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	rc, downloaded, err := p.Download(ctx, path)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download: %v", err)
+	}
+	if !bytes.Equal(data, []byte(content)) {
+		t.Errorf("downloaded %q, want %q", data, content)
+	}
+	if downloaded.Size != int64(len(content)) {
+		t.Errorf("FileInfo.Size = %d, want %d", downloaded.Size, len(content))
+	}
+
+	url, err := p.GetSignedURL(ctx, path, time.Minute)
+	if err != nil || url == "" {
+		t.Errorf("GetSignedURL() = %q, %v", url, err)
+	}
+}
+
+func (s *suite) testPagination(t *testing.T) {
+	p := s.newProvider(t)
+	dir := prefix()
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		path := fmt.Sprintf("%sfile-%d.txt", dir, i)
+		upload(t, p, path, "content")
+		want = append(want, path)
+	}
+	// A sibling whose name shares the prefix string but not the directory
+	upload(t, p, strings.TrimSuffix(dir, "/")+"-sibling.txt", "content")
+
+	var got []string
+	opts := storage.ListOptions{MaxKeys: 2}
+	for page := 0; page < 10; page++ {
+		files, err := p.List(context.Background(), dir, opts)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(files) > opts.MaxKeys {
+			t.Errorf("List() page %d has %d files, MaxKeys is %d", page+1, len(files), opts.MaxKeys)
+		}
+		if len(files) == 0 {
+			break
+		}
+		for _, f := range files {
+			got = append(got, f.Path)
+		}
+		opts.Cursor = files[len(files)-1].Path
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// memStorage is a minimal conforming provider used to test the suite itself.
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memStorage) check(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("path is required: %w", provider.ErrInvalidArgument)
+	}
+	return nil
+}
+
+func (m *memStorage) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (*storage.FileInfo, error) {
+	if err := m.check(ctx, path); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = data
+	return &storage.FileInfo{Path: path, Size: int64(len(data))}, nil
+}
+
+func (m *memStorage) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	if err := m.check(ctx, path); err != nil {
+		return nil, nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", path, provider.ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(data)), &storage.FileInfo{Path: path, Size: int64(len(data))}, nil
+}
+
+func (m *memStorage) Delete(ctx context.Context, path string) error {
+	if err := m.check(ctx, path); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, path)
+	return nil
+}
+
+func (m *memStorage) Exists(ctx context.Context, path string) (bool, error) {
+	if err := m.check(ctx, path); err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[path]
+	return ok, nil
+}
+
+func (m *memStorage) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	if err := m.check(ctx, path); err != nil {
+		return "", err
+	}
+	if expiry <= 0 {
+		return "", fmt.Errorf("expiry must be positive: %w", provider.ErrInvalidArgument)
+	}
+	return "mem://" + path, nil
+}
+
+func (m *memStorage) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.MaxKeys < 0 {
+		return nil, fmt.Errorf("MaxKeys must not be negative: %w", provider.ErrInvalidArgument)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var paths []string
+	for path := range m.files {
+		if strings.HasPrefix(path, prefix) && path > opts.Cursor {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	if opts.MaxKeys > 0 && len(paths) > opts.MaxKeys {
+		paths = paths[:opts.MaxKeys]
+	}
+	files := make([]storage.FileInfo, len(paths))
+	for i, path := range paths {
+		files[i] = storage.FileInfo{Path: path, Size: int64(len(m.files[path]))}
+	}
+	return files, nil
+}
+
+func (m *memStorage) Metadata() storage.Metadata {
+	return storage.Metadata{Name: "memory"}
+}
+
+func TestSuite(t *testing.T) {
+	Run(t, func(t *testing.T) storage.StorageProvider {
+		return &memStorage{files: make(map[string][]byte)}
+	}, Options{})
+}
@@ -3,6 +3,7 @@ package noop
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/tax"
@@ -31,6 +32,17 @@ func NewProvider(config map[string]any) (tax.TaxProvider, error) {
 }
 
 func (p *Provider) CalculateTax(ctx context.Context, req tax.TaxRequest) (*tax.TaxResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Country == "" {
+		return nil, fmt.Errorf("noop: country is required: %w", provider.ErrInvalidArgument)
+	}
+	for _, item := range req.Items {
+		if item.Quantity < 0 || item.UnitPrice < 0 {
+			return nil, fmt.Errorf("noop: quantity and price of %s must not be negative: %w", item.SKU, provider.ErrInvalidArgument)
+		}
+	}
 	items := make([]tax.TaxItemResult, len(req.Items))
 	var totalTax int64
 	for i, item := range req.Items {
@@ -0,0 +1,14 @@
+package noop
+
+import (
+	"testing"
+
+	"github.com/gondolia/gondolia/provider/tax"
+	"github.com/gondolia/gondolia/provider/tax/taxtest"
+)
+
+func TestConformance(t *testing.T) {
+	taxtest.Run(t, func(t *testing.T) tax.TaxProvider {
+		return &Provider{}
+	}, taxtest.Options{})
+}
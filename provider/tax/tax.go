@@ -5,7 +5,8 @@ import "context"
 
 // TaxProvider abstracts tax calculations (internal, Avalara, TaxJar, etc.).
 type TaxProvider interface {
-	// CalculateTax calculates taxes for a list of items.
+	// CalculateTax calculates taxes for a list of items. The result has one
+	// entry per item, in request order, and TotalTax is the sum of their amounts.
 	CalculateTax(ctx context.Context, req TaxRequest) (*TaxResult, error)
 
 	// Metadata returns provider information.
@@ -0,0 +1,121 @@
+// Package taxtest is a conformance suite for tax.TaxProvider implementations.
+// It checks the shape of tax results, argument validation and context
+// cancellation.
+//
+// Run it from a provider's tests, including in third-party repositories:
+//
+//	func TestConformance(t *testing.T) {
+//	    taxtest.Run(t, func(t *testing.T) tax.TaxProvider {
+//	        return newTestProvider(t)
+//	    }, taxtest.Options{Country: "DE", Currency: "EUR"})
+//	}
+package taxtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/providertest"
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// Options configures the suite.
+type Options struct {
+	// Country and Currency of the test requests. Default to "CH" and "CHF".
+	Country  string
+	Currency string
+}
+
+// Factory returns a new provider instance for one test.
+type Factory func(t *testing.T) tax.TaxProvider
+
+// Run runs the conformance suite against the providers returned by newProvider.
+func Run(t *testing.T, newProvider Factory, opts Options) {
+	if opts.Country == "" {
+		opts.Country = "CH"
+	}
+	if opts.Currency == "" {
+		opts.Currency = "CHF"
+	}
+	s := &suite{newProvider: newProvider, opts: opts}
+
+	t.Run("Metadata", s.testMetadata)
+	t.Run("ContextCancellation", s.testContextCancellation)
+	t.Run("InvalidArguments", s.testInvalidArguments)
+	t.Run("Calculation", s.testCalculation)
+}
+
+type suite struct {
+	newProvider Factory
+	opts        Options
+}
+
+func (s *suite) request(items ...tax.TaxItem) tax.TaxRequest {
+	return tax.TaxRequest{Country: s.opts.Country, Currency: s.opts.Currency, Items: items}
+}
+
+func (s *suite) testMetadata(t *testing.T) {
+	if s.newProvider(t).Metadata().Name == "" {
+		t.Error("Metadata().Name is empty")
+	}
+}
+
+func (s *suite) testContextCancellation(t *testing.T) {
+	p := s.newProvider(t)
+	_, err := p.CalculateTax(providertest.CanceledContext(), s.request(tax.TaxItem{SKU: "SKU-1", Quantity: 1, UnitPrice: 1000}))
+	providertest.ExpectCanceled(t, "CalculateTax", err)
+}
+
+func (s *suite) testInvalidArguments(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+	invalid := provider.ErrInvalidArgument
+
+	noCountry := s.request(tax.TaxItem{SKU: "SKU-1", Quantity: 1, UnitPrice: 1000})
+	noCountry.Country = ""
+	_, err := p.CalculateTax(ctx, noCountry)
+	providertest.ExpectError(t, "CalculateTax without country", err, invalid)
+	_, err = p.CalculateTax(ctx, s.request(tax.TaxItem{SKU: "SKU-1", Quantity: -1, UnitPrice: 1000}))
+	providertest.ExpectError(t, "CalculateTax with negative quantity", err, invalid)
+	_, err = p.CalculateTax(ctx, s.request(tax.TaxItem{SKU: "SKU-1", Quantity: 1, UnitPrice: -1000}))
+	providertest.ExpectError(t, "CalculateTax with negative price", err, invalid)
+}
+
+func (s *suite) testCalculation(t *testing.T) {
+	p := s.newProvider(t)
+	ctx := context.Background()
+
+	empty, err := p.CalculateTax(ctx, s.request())
+	if err != nil {
+		t.Fatalf("CalculateTax(no items) error = %v", err)
+	}
+	if len(empty.Items) != 0 || empty.TotalTax != 0 {
+		t.Errorf("CalculateTax(no items) = %+v, want no items and no tax", empty)
+	}
+
+	req := s.request(
+		tax.TaxItem{SKU: "SKU-1", Quantity: 2, UnitPrice: 1999},
+		tax.TaxItem{SKU: "SKU-2", Quantity: 1, UnitPrice: 50000},
+	)
+	result, err := p.CalculateTax(ctx, req)
+	if err != nil {
+		t.Fatalf("CalculateTax() error = %v", err)
+	}
+	if len(result.Items) != len(req.Items) {
+		t.Fatalf("CalculateTax() returned %d items for %d", len(result.Items), len(req.Items))
+	}
+	var sum int64
+	for i, item := range result.Items {
+		if item.SKU != req.Items[i].SKU {
+			t.Errorf("item %d SKU = %q, want %q (request order)", i, item.SKU, req.Items[i].SKU)
+		}
+		if item.TaxAmount < 0 || item.TaxRate < 0 {
+			t.Errorf("item %s has negative tax: %+v", item.SKU, item)
+		}
+		sum += item.TaxAmount
+	}
+	if result.TotalTax != sum {
+		t.Errorf("TotalTax = %d, want sum of items %d", result.TotalTax, sum)
+	}
+}
@@ -0,0 +1,48 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/auth"
+)
+
+// authProvider traces calls to auth.AuthProvider.
+type authProvider struct {
+	next auth.AuthProvider
+	inst *instrument
+}
+
+// WrapAuth wraps auth.AuthProvider so that every call is traced and measured.
+func WrapAuth(p auth.AuthProvider, name string) auth.AuthProvider {
+	return &authProvider{next: p, inst: newInstrument("auth", "AuthProvider", name)}
+}
+
+func (p *authProvider) GetAuthURL(ctx context.Context, state string, redirectURL string) (r0 string, err error) {
+	ctx, done := p.inst.start(ctx, "GetAuthURL")
+	defer func() { done(err) }()
+	return p.next.GetAuthURL(ctx, state, redirectURL)
+}
+
+func (p *authProvider) HandleCallback(ctx context.Context, code string, state string) (r0 *auth.SSOUser, err error) {
+	ctx, done := p.inst.start(ctx, "HandleCallback")
+	defer func() { done(err) }()
+	return p.next.HandleCallback(ctx, code, state)
+}
+
+func (p *authProvider) ValidateToken(ctx context.Context, token string) (r0 *auth.SSOUser, err error) {
+	ctx, done := p.inst.start(ctx, "ValidateToken")
+	defer func() { done(err) }()
+	return p.next.ValidateToken(ctx, token)
+}
+
+func (p *authProvider) GetUserInfo(ctx context.Context, accessToken string) (r0 *auth.SSOUser, err error) {
+	ctx, done := p.inst.start(ctx, "GetUserInfo")
+	defer func() { done(err) }()
+	return p.next.GetUserInfo(ctx, accessToken)
+}
+
+func (p *authProvider) Metadata() auth.Metadata {
+	return p.next.Metadata()
+}
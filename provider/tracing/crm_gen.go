@@ -0,0 +1,42 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/crm"
+)
+
+// crmProvider traces calls to crm.CRMProvider.
+type crmProvider struct {
+	next crm.CRMProvider
+	inst *instrument
+}
+
+// WrapCRM wraps crm.CRMProvider so that every call is traced and measured.
+func WrapCRM(p crm.CRMProvider, name string) crm.CRMProvider {
+	return &crmProvider{next: p, inst: newInstrument("crm", "CRMProvider", name)}
+}
+
+func (p *crmProvider) SyncContact(ctx context.Context, contact crm.Contact) (r0 *crm.SyncResult, err error) {
+	ctx, done := p.inst.start(ctx, "SyncContact")
+	defer func() { done(err) }()
+	return p.next.SyncContact(ctx, contact)
+}
+
+func (p *crmProvider) GetAccount(ctx context.Context, accountID string) (r0 *crm.Account, err error) {
+	ctx, done := p.inst.start(ctx, "GetAccount")
+	defer func() { done(err) }()
+	return p.next.GetAccount(ctx, accountID)
+}
+
+func (p *crmProvider) ListAccounts(ctx context.Context, filter crm.AccountFilter) (r0 []crm.Account, err error) {
+	ctx, done := p.inst.start(ctx, "ListAccounts")
+	defer func() { done(err) }()
+	return p.next.ListAccounts(ctx, filter)
+}
+
+func (p *crmProvider) Metadata() crm.Metadata {
+	return p.next.Metadata()
+}
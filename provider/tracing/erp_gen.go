@@ -0,0 +1,84 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/erp"
+)
+
+// erpProvider traces calls to erp.ERPProvider.
+type erpProvider struct {
+	next erp.ERPProvider
+	inst *instrument
+}
+
+// WrapERP wraps erp.ERPProvider so that every call is traced and measured.
+func WrapERP(p erp.ERPProvider, name string) erp.ERPProvider {
+	return &erpProvider{next: p, inst: newInstrument("erp", "ERPProvider", name)}
+}
+
+func (p *erpProvider) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (r0 *erp.CreateOrderResult, err error) {
+	ctx, done := p.inst.start(ctx, "CreateOrder")
+	defer func() { done(err) }()
+	return p.next.CreateOrder(ctx, req)
+}
+
+func (p *erpProvider) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (r0 *erp.SimulateOrderResult, err error) {
+	ctx, done := p.inst.start(ctx, "SimulateOrder")
+	defer func() { done(err) }()
+	return p.next.SimulateOrder(ctx, req)
+}
+
+func (p *erpProvider) GetOrderStatus(ctx context.Context, orderID string) (r0 *erp.OrderStatus, err error) {
+	ctx, done := p.inst.start(ctx, "GetOrderStatus")
+	defer func() { done(err) }()
+	return p.next.GetOrderStatus(ctx, orderID)
+}
+
+func (p *erpProvider) GetProductAvailability(ctx context.Context, skus []string) (r0 []erp.ProductStock, err error) {
+	ctx, done := p.inst.start(ctx, "GetProductAvailability")
+	defer func() { done(err) }()
+	return p.next.GetProductAvailability(ctx, skus)
+}
+
+func (p *erpProvider) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) (r0 []erp.TierPrice, err error) {
+	ctx, done := p.inst.start(ctx, "GetTierPrices")
+	defer func() { done(err) }()
+	return p.next.GetTierPrices(ctx, req)
+}
+
+func (p *erpProvider) SyncCompany(ctx context.Context, erpCustomerID string) (r0 *erp.CompanyData, err error) {
+	ctx, done := p.inst.start(ctx, "SyncCompany")
+	defer func() { done(err) }()
+	return p.next.SyncCompany(ctx, erpCustomerID)
+}
+
+func (p *erpProvider) GetCompanyAddresses(ctx context.Context, erpCustomerID string) (r0 []erp.Address, err error) {
+	ctx, done := p.inst.start(ctx, "GetCompanyAddresses")
+	defer func() { done(err) }()
+	return p.next.GetCompanyAddresses(ctx, erpCustomerID)
+}
+
+func (p *erpProvider) GetOrderHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.OrderReport, err error) {
+	ctx, done := p.inst.start(ctx, "GetOrderHistory")
+	defer func() { done(err) }()
+	return p.next.GetOrderHistory(ctx, req)
+}
+
+func (p *erpProvider) GetShipmentHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.ShipmentReport, err error) {
+	ctx, done := p.inst.start(ctx, "GetShipmentHistory")
+	defer func() { done(err) }()
+	return p.next.GetShipmentHistory(ctx, req)
+}
+
+func (p *erpProvider) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) (r0 []erp.InvoiceReport, err error) {
+	ctx, done := p.inst.start(ctx, "GetInvoiceHistory")
+	defer func() { done(err) }()
+	return p.next.GetInvoiceHistory(ctx, req)
+}
+
+func (p *erpProvider) Metadata() erp.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,54 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/fulfillment"
+)
+
+// fulfillmentProvider traces calls to fulfillment.FulfillmentProvider.
+type fulfillmentProvider struct {
+	next fulfillment.FulfillmentProvider
+	inst *instrument
+}
+
+// WrapFulfillment wraps fulfillment.FulfillmentProvider so that every call is traced and measured.
+func WrapFulfillment(p fulfillment.FulfillmentProvider, name string) fulfillment.FulfillmentProvider {
+	return &fulfillmentProvider{next: p, inst: newInstrument("fulfillment", "FulfillmentProvider", name)}
+}
+
+func (p *fulfillmentProvider) CreateShipment(ctx context.Context, req fulfillment.ShipmentRequest) (r0 *fulfillment.ShipmentResult, err error) {
+	ctx, done := p.inst.start(ctx, "CreateShipment")
+	defer func() { done(err) }()
+	return p.next.CreateShipment(ctx, req)
+}
+
+func (p *fulfillmentProvider) GetShipmentStatus(ctx context.Context, shipmentID string) (r0 *fulfillment.ShipmentStatus, err error) {
+	ctx, done := p.inst.start(ctx, "GetShipmentStatus")
+	defer func() { done(err) }()
+	return p.next.GetShipmentStatus(ctx, shipmentID)
+}
+
+func (p *fulfillmentProvider) CancelShipment(ctx context.Context, shipmentID string) (err error) {
+	ctx, done := p.inst.start(ctx, "CancelShipment")
+	defer func() { done(err) }()
+	return p.next.CancelShipment(ctx, shipmentID)
+}
+
+func (p *fulfillmentProvider) GetTrackingURL(ctx context.Context, trackingNumber string) (r0 string, err error) {
+	ctx, done := p.inst.start(ctx, "GetTrackingURL")
+	defer func() { done(err) }()
+	return p.next.GetTrackingURL(ctx, trackingNumber)
+}
+
+func (p *fulfillmentProvider) CalculateShipping(ctx context.Context, req fulfillment.ShippingCalcRequest) (r0 []fulfillment.ShippingOption, err error) {
+	ctx, done := p.inst.start(ctx, "CalculateShipping")
+	defer func() { done(err) }()
+	return p.next.CalculateShipping(ctx, req)
+}
+
+func (p *fulfillmentProvider) Metadata() fulfillment.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,123 @@
+// Command gen generates the tracing wrappers in package provider/tracing from
+// the provider interface definitions.
+//
+// Usage (from provider/tracing):
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gondolia/gondolia/provider/internal/codegen"
+)
+
+const header = "// Code generated by provider/tracing/gen. DO NOT EDIT.\n\n"
+
+func main() {
+	for _, t := range codegen.Targets {
+		methods, err := codegen.ParseInterface("../"+t.Category, t)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := codegen.Write(t.Category+"_gen.go", renderWrapper(t, methods)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := codegen.Write("wrap_gen.go", renderWrap()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func renderWrapper(t codegen.Target, methods []codegen.Method) []byte {
+	imports := map[string]bool{
+		codegen.ModulePath + "/" + t.Category: true,
+	}
+	for _, m := range methods {
+		for p := range m.Imports {
+			imports[p] = true
+		}
+	}
+
+	typeName := t.Category + "Provider"
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("package tracing\n\n")
+	codegen.WriteImports(&b, imports)
+
+	fmt.Fprintf(&b, "// %s traces calls to %s.%s.\n", typeName, t.Category, t.Interface)
+	fmt.Fprintf(&b, "type %s struct {\n\tnext %s.%s\n\tinst *instrument\n}\n\n", typeName, t.Category, t.Interface)
+	fmt.Fprintf(&b, "// Wrap%s wraps %s.%s so that every call is traced and measured.\n", t.Wrapper, t.Category, t.Interface)
+	fmt.Fprintf(&b, "func Wrap%s(p %s.%s, name string) %s.%s {\n", t.Wrapper, t.Category, t.Interface, t.Category, t.Interface)
+	fmt.Fprintf(&b, "\treturn &%s{next: p, inst: newInstrument(%q, %q, name)}\n}\n", typeName, t.Category, t.Interface)
+
+	for _, m := range methods {
+		b.WriteString("\n")
+		traced := m.Contextual()
+
+		var params, args []string
+		for _, p := range m.Params {
+			params = append(params, p.Name+" "+p.Type)
+			args = append(args, p.Name)
+		}
+		var results []string
+		for i, r := range m.Results {
+			name := fmt.Sprintf("r%d", i)
+			if traced && i == len(m.Results)-1 {
+				name = "err"
+			}
+			results = append(results, name+" "+r.Type)
+		}
+
+		call := fmt.Sprintf("p.next.%s(%s)", m.Name, strings.Join(args, ", "))
+		if !traced {
+			var types []string
+			for _, r := range m.Results {
+				types = append(types, r.Type)
+			}
+			fmt.Fprintf(&b, "func (p *%s) %s(%s) %s {\n", typeName, m.Name, strings.Join(params, ", "), codegen.ResultList(types))
+			if len(m.Results) > 0 {
+				fmt.Fprintf(&b, "\treturn %s\n}\n", call)
+			} else {
+				fmt.Fprintf(&b, "\t%s\n}\n", call)
+			}
+			continue
+		}
+
+		ctxName := m.Params[0].Name
+		fmt.Fprintf(&b, "func (p *%s) %s(%s) (%s) {\n", typeName, m.Name, strings.Join(params, ", "), strings.Join(results, ", "))
+		fmt.Fprintf(&b, "\t%s, done := p.inst.start(%s, %q)\n", ctxName, ctxName, m.Name)
+		b.WriteString("\tdefer func() { done(err) }()\n")
+		fmt.Fprintf(&b, "\treturn %s\n}\n", call)
+	}
+
+	return codegen.Format(b.Bytes())
+}
+
+func renderWrap() []byte {
+	imports := map[string]bool{}
+	for _, t := range codegen.Targets {
+		imports[codegen.ModulePath+"/"+t.Category] = true
+	}
+
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("package tracing\n\n")
+	codegen.WriteImports(&b, imports)
+
+	b.WriteString("// Wrap wraps a provider of the given category with tracing and metrics.\n")
+	b.WriteString("// Values that do not implement the category's interface are returned unchanged.\n")
+	b.WriteString("func Wrap(category, name string, instance any) any {\n\tswitch category {\n")
+	for _, t := range codegen.Targets {
+		fmt.Fprintf(&b, "\tcase %q:\n", t.Category)
+		fmt.Fprintf(&b, "\t\tif p, ok := instance.(%s.%s); ok {\n", t.Category, t.Interface)
+		fmt.Fprintf(&b, "\t\t\treturn Wrap%s(p, name)\n\t\t}\n", t.Wrapper)
+	}
+	b.WriteString("\t}\n\treturn instance\n}\n")
+
+	return codegen.Format(b.Bytes())
+}
@@ -0,0 +1,40 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/notification"
+)
+
+// notificationProvider traces calls to notification.NotificationProvider.
+type notificationProvider struct {
+	next notification.NotificationProvider
+	inst *instrument
+}
+
+// WrapNotification wraps notification.NotificationProvider so that every call is traced and measured.
+func WrapNotification(p notification.NotificationProvider, name string) notification.NotificationProvider {
+	return &notificationProvider{next: p, inst: newInstrument("notification", "NotificationProvider", name)}
+}
+
+func (p *notificationProvider) Send(ctx context.Context, msg notification.Message) (r0 *notification.SendResult, err error) {
+	ctx, done := p.inst.start(ctx, "Send")
+	defer func() { done(err) }()
+	return p.next.Send(ctx, msg)
+}
+
+func (p *notificationProvider) SendBatch(ctx context.Context, msgs []notification.Message) (r0 []notification.SendResult, err error) {
+	ctx, done := p.inst.start(ctx, "SendBatch")
+	defer func() { done(err) }()
+	return p.next.SendBatch(ctx, msgs)
+}
+
+func (p *notificationProvider) Channels() []string {
+	return p.next.Channels()
+}
+
+func (p *notificationProvider) Metadata() notification.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,60 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/payment"
+)
+
+// paymentProvider traces calls to payment.PaymentProvider.
+type paymentProvider struct {
+	next payment.PaymentProvider
+	inst *instrument
+}
+
+// WrapPayment wraps payment.PaymentProvider so that every call is traced and measured.
+func WrapPayment(p payment.PaymentProvider, name string) payment.PaymentProvider {
+	return &paymentProvider{next: p, inst: newInstrument("payment", "PaymentProvider", name)}
+}
+
+func (p *paymentProvider) Initialize(ctx context.Context, req payment.InitializeRequest) (r0 *payment.PaymentSession, err error) {
+	ctx, done := p.inst.start(ctx, "Initialize")
+	defer func() { done(err) }()
+	return p.next.Initialize(ctx, req)
+}
+
+func (p *paymentProvider) Authorize(ctx context.Context, sessionID string) (r0 *payment.AuthorizationResult, err error) {
+	ctx, done := p.inst.start(ctx, "Authorize")
+	defer func() { done(err) }()
+	return p.next.Authorize(ctx, sessionID)
+}
+
+func (p *paymentProvider) Capture(ctx context.Context, transactionID string, amount *payment.Amount) (r0 *payment.CaptureResult, err error) {
+	ctx, done := p.inst.start(ctx, "Capture")
+	defer func() { done(err) }()
+	return p.next.Capture(ctx, transactionID, amount)
+}
+
+func (p *paymentProvider) Cancel(ctx context.Context, transactionID string) (err error) {
+	ctx, done := p.inst.start(ctx, "Cancel")
+	defer func() { done(err) }()
+	return p.next.Cancel(ctx, transactionID)
+}
+
+func (p *paymentProvider) Refund(ctx context.Context, transactionID string, amount payment.Amount) (r0 *payment.RefundResult, err error) {
+	ctx, done := p.inst.start(ctx, "Refund")
+	defer func() { done(err) }()
+	return p.next.Refund(ctx, transactionID, amount)
+}
+
+func (p *paymentProvider) HandleWebhook(ctx context.Context, payload []byte, headers map[string]string) (r0 *payment.WebhookEvent, err error) {
+	ctx, done := p.inst.start(ctx, "HandleWebhook")
+	defer func() { done(err) }()
+	return p.next.HandleWebhook(ctx, payload, headers)
+}
+
+func (p *paymentProvider) Metadata() payment.Metadata {
+	return p.next.Metadata()
+}
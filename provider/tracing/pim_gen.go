@@ -0,0 +1,73 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/gondolia/gondolia/provider/pim"
+)
+
+// pimProvider traces calls to pim.PIMProvider.
+type pimProvider struct {
+	next pim.PIMProvider
+	inst *instrument
+}
+
+// WrapPIM wraps pim.PIMProvider so that every call is traced and measured.
+func WrapPIM(p pim.PIMProvider, name string) pim.PIMProvider {
+	return &pimProvider{next: p, inst: newInstrument("pim", "PIMProvider", name)}
+}
+
+func (p *pimProvider) FetchProducts(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductPage, err error) {
+	ctx, done := p.inst.start(ctx, "FetchProducts")
+	defer func() { done(err) }()
+	return p.next.FetchProducts(ctx, filter)
+}
+
+func (p *pimProvider) FetchProduct(ctx context.Context, identifier string) (r0 *pim.Product, err error) {
+	ctx, done := p.inst.start(ctx, "FetchProduct")
+	defer func() { done(err) }()
+	return p.next.FetchProduct(ctx, identifier)
+}
+
+func (p *pimProvider) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (r0 *pim.ProductModelPage, err error) {
+	ctx, done := p.inst.start(ctx, "FetchProductModels")
+	defer func() { done(err) }()
+	return p.next.FetchProductModels(ctx, filter)
+}
+
+func (p *pimProvider) FetchProductModel(ctx context.Context, code string) (r0 *pim.ProductModel, err error) {
+	ctx, done := p.inst.start(ctx, "FetchProductModel")
+	defer func() { done(err) }()
+	return p.next.FetchProductModel(ctx, code)
+}
+
+func (p *pimProvider) FetchFamilyVariants(ctx context.Context) (r0 []pim.FamilyVariant, err error) {
+	ctx, done := p.inst.start(ctx, "FetchFamilyVariants")
+	defer func() { done(err) }()
+	return p.next.FetchFamilyVariants(ctx)
+}
+
+func (p *pimProvider) FetchCategories(ctx context.Context) (r0 []pim.Category, err error) {
+	ctx, done := p.inst.start(ctx, "FetchCategories")
+	defer func() { done(err) }()
+	return p.next.FetchCategories(ctx)
+}
+
+func (p *pimProvider) FetchAttributes(ctx context.Context) (r0 []pim.Attribute, err error) {
+	ctx, done := p.inst.start(ctx, "FetchAttributes")
+	defer func() { done(err) }()
+	return p.next.FetchAttributes(ctx)
+}
+
+func (p *pimProvider) DownloadAsset(ctx context.Context, assetCode string) (r0 io.ReadCloser, r1 string, err error) {
+	ctx, done := p.inst.start(ctx, "DownloadAsset")
+	defer func() { done(err) }()
+	return p.next.DownloadAsset(ctx, assetCode)
+}
+
+func (p *pimProvider) Metadata() pim.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,72 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+// searchProvider traces calls to search.SearchProvider.
+type searchProvider struct {
+	next search.SearchProvider
+	inst *instrument
+}
+
+// WrapSearch wraps search.SearchProvider so that every call is traced and measured.
+func WrapSearch(p search.SearchProvider, name string) search.SearchProvider {
+	return &searchProvider{next: p, inst: newInstrument("search", "SearchProvider", name)}
+}
+
+func (p *searchProvider) IndexDocuments(ctx context.Context, index string, documents []search.Document) (r0 *search.TaskResult, err error) {
+	ctx, done := p.inst.start(ctx, "IndexDocuments")
+	defer func() { done(err) }()
+	return p.next.IndexDocuments(ctx, index, documents)
+}
+
+func (p *searchProvider) DeleteDocuments(ctx context.Context, index string, ids []string) (r0 *search.TaskResult, err error) {
+	ctx, done := p.inst.start(ctx, "DeleteDocuments")
+	defer func() { done(err) }()
+	return p.next.DeleteDocuments(ctx, index, ids)
+}
+
+func (p *searchProvider) ConfigureIndex(ctx context.Context, index string, config search.IndexConfig) (err error) {
+	ctx, done := p.inst.start(ctx, "ConfigureIndex")
+	defer func() { done(err) }()
+	return p.next.ConfigureIndex(ctx, index, config)
+}
+
+func (p *searchProvider) Search(ctx context.Context, index string, query search.SearchQuery) (r0 *search.SearchResult, err error) {
+	ctx, done := p.inst.start(ctx, "Search")
+	defer func() { done(err) }()
+	return p.next.Search(ctx, index, query)
+}
+
+func (p *searchProvider) CreateIndex(ctx context.Context, index string, primaryKey string) (err error) {
+	ctx, done := p.inst.start(ctx, "CreateIndex")
+	defer func() { done(err) }()
+	return p.next.CreateIndex(ctx, index, primaryKey)
+}
+
+func (p *searchProvider) DeleteIndex(ctx context.Context, index string) (err error) {
+	ctx, done := p.inst.start(ctx, "DeleteIndex")
+	defer func() { done(err) }()
+	return p.next.DeleteIndex(ctx, index)
+}
+
+func (p *searchProvider) GetTaskStatus(ctx context.Context, taskID string) (r0 *search.TaskResult, err error) {
+	ctx, done := p.inst.start(ctx, "GetTaskStatus")
+	defer func() { done(err) }()
+	return p.next.GetTaskStatus(ctx, taskID)
+}
+
+func (p *searchProvider) Health(ctx context.Context) (err error) {
+	ctx, done := p.inst.start(ctx, "Health")
+	defer func() { done(err) }()
+	return p.next.Health(ctx)
+}
+
+func (p *searchProvider) Metadata() search.Metadata {
+	return p.next.Metadata()
+}
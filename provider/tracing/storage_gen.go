@@ -0,0 +1,62 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/storage"
+)
+
+// storageProvider traces calls to storage.StorageProvider.
+type storageProvider struct {
+	next storage.StorageProvider
+	inst *instrument
+}
+
+// WrapStorage wraps storage.StorageProvider so that every call is traced and measured.
+func WrapStorage(p storage.StorageProvider, name string) storage.StorageProvider {
+	return &storageProvider{next: p, inst: newInstrument("storage", "StorageProvider", name)}
+}
+
+func (p *storageProvider) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (r0 *storage.FileInfo, err error) {
+	ctx, done := p.inst.start(ctx, "Upload")
+	defer func() { done(err) }()
+	return p.next.Upload(ctx, path, reader, opts)
+}
+
+func (p *storageProvider) Download(ctx context.Context, path string) (r0 io.ReadCloser, r1 *storage.FileInfo, err error) {
+	ctx, done := p.inst.start(ctx, "Download")
+	defer func() { done(err) }()
+	return p.next.Download(ctx, path)
+}
+
+func (p *storageProvider) Delete(ctx context.Context, path string) (err error) {
+	ctx, done := p.inst.start(ctx, "Delete")
+	defer func() { done(err) }()
+	return p.next.Delete(ctx, path)
+}
+
+func (p *storageProvider) Exists(ctx context.Context, path string) (r0 bool, err error) {
+	ctx, done := p.inst.start(ctx, "Exists")
+	defer func() { done(err) }()
+	return p.next.Exists(ctx, path)
+}
+
+func (p *storageProvider) GetSignedURL(ctx context.Context, path string, expiry time.Duration) (r0 string, err error) {
+	ctx, done := p.inst.start(ctx, "GetSignedURL")
+	defer func() { done(err) }()
+	return p.next.GetSignedURL(ctx, path, expiry)
+}
+
+func (p *storageProvider) List(ctx context.Context, prefix string, opts storage.ListOptions) (r0 []storage.FileInfo, err error) {
+	ctx, done := p.inst.start(ctx, "List")
+	defer func() { done(err) }()
+	return p.next.List(ctx, prefix, opts)
+}
+
+func (p *storageProvider) Metadata() storage.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,30 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// taxProvider traces calls to tax.TaxProvider.
+type taxProvider struct {
+	next tax.TaxProvider
+	inst *instrument
+}
+
+// WrapTax wraps tax.TaxProvider so that every call is traced and measured.
+func WrapTax(p tax.TaxProvider, name string) tax.TaxProvider {
+	return &taxProvider{next: p, inst: newInstrument("tax", "TaxProvider", name)}
+}
+
+func (p *taxProvider) CalculateTax(ctx context.Context, req tax.TaxRequest) (r0 *tax.TaxResult, err error) {
+	ctx, done := p.inst.start(ctx, "CalculateTax")
+	defer func() { done(err) }()
+	return p.next.CalculateTax(ctx, req)
+}
+
+func (p *taxProvider) Metadata() tax.Metadata {
+	return p.next.Metadata()
+}
@@ -0,0 +1,137 @@
+// Package tracing instruments provider calls with OpenTelemetry spans and metrics.
+//
+// Every method of a wrapped provider that takes a context starts a span named
+// after the interface and method (e.g. "SearchProvider.Search") carrying the
+// provider name, category, tenant and outcome. Errors are recorded on the span
+// via telemetry.SetError. Each call is also measured by two instruments:
+//
+//	provider.call.duration  histogram of call latency in seconds
+//	provider.call.errors    counter of failed calls
+//
+// Both carry the attributes provider.category, provider.name, provider.method
+// and provider.outcome. The tenant is taken from the context (see
+// provider.WithTenant) and only attached to spans, to keep metric cardinality low.
+//
+// The wrappers are generated from the provider interfaces; run go generate
+// after changing an interface.
+package tracing
+
+//go:generate go run ./gen
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gondolia/gondolia/pkg/telemetry"
+	"github.com/gondolia/gondolia/provider"
+)
+
+const instrumentationName = "github.com/gondolia/gondolia/provider"
+
+// Call outcomes recorded on spans and metrics.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeError    = "error"
+	OutcomeTimeout  = "timeout"
+	OutcomeCanceled = "canceled"
+)
+
+// Decorator returns a provider.Decorator that wraps every resolved provider
+// with tracing. Add it last so spans cover retries and circuit breaker rejections.
+//
+// Example:
+//
+//	resolver.Use(resilience.Decorator(nil), tracing.Decorator())
+func Decorator() provider.Decorator {
+	return func(category string, sel provider.Selection, instance any) (any, error) {
+		return Wrap(category, sel.Name, instance), nil
+	}
+}
+
+// instrument creates spans and records metrics for one provider instance.
+type instrument struct {
+	category  string
+	iface     string
+	name      string
+	tracer    trace.Tracer
+	durations metric.Float64Histogram
+	errors    metric.Int64Counter
+}
+
+func newInstrument(category, iface, name string) *instrument {
+	meter := otel.Meter(instrumentationName)
+
+	// Instrument creation only fails for invalid names; the returned no-op
+	// instruments are safe to use in that case.
+	durations, _ := meter.Float64Histogram("provider.call.duration",
+		metric.WithDescription("Duration of provider calls"),
+		metric.WithUnit("s"),
+	)
+	errs, _ := meter.Int64Counter("provider.call.errors",
+		metric.WithDescription("Number of failed provider calls"),
+	)
+
+	return &instrument{
+		category:  category,
+		iface:     iface,
+		name:      name,
+		tracer:    otel.Tracer(instrumentationName),
+		durations: durations,
+		errors:    errs,
+	}
+}
+
+// start begins a span for method. The returned function ends it and records
+// the call's outcome and latency.
+func (i *instrument) start(ctx context.Context, method string) (context.Context, func(error)) {
+	attrs := []attribute.KeyValue{
+		attribute.String("provider.category", i.category),
+		attribute.String("provider.name", i.name),
+		attribute.String("provider.method", method),
+	}
+
+	ctx, span := i.tracer.Start(ctx, i.iface+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	if tenantID := provider.TenantFromContext(ctx); tenantID != "" {
+		span.SetAttributes(attribute.String("tenant.id", tenantID))
+	}
+	start := time.Now()
+
+	return ctx, func(err error) {
+		defer span.End()
+
+		outcome := Outcome(err)
+		span.SetAttributes(attribute.String("provider.outcome", outcome))
+		if err != nil {
+			telemetry.SetError(ctx, err)
+		}
+
+		attrs = append(attrs, attribute.String("provider.outcome", outcome))
+		opt := metric.WithAttributes(attrs...)
+		i.durations.Record(ctx, time.Since(start).Seconds(), opt)
+		if err != nil {
+			i.errors.Add(ctx, 1, opt)
+		}
+	}
+}
+
+// Outcome classifies the result of a provider call.
+func Outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCanceled
+	}
+	return OutcomeError
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/search"
+)
+
+type failingSearch struct {
+	search.SearchProvider
+}
+
+func (failingSearch) Search(ctx context.Context, index string, query search.SearchQuery) (*search.SearchResult, error) {
+	return nil, errors.New("index unavailable")
+}
+
+func TestWrapSearch_RecordsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	p := Wrap("search", "opensearch", failingSearch{}).(search.SearchProvider)
+	ctx := provider.WithTenant(context.Background(), "tenant-1")
+
+	if _, err := p.Search(ctx, "products", search.SearchQuery{}); err == nil {
+		t.Fatal("expected error from Search")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "SearchProvider.Search" {
+		t.Errorf("span name = %q, want SearchProvider.Search", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want error", span.Status().Code)
+	}
+
+	want := map[attribute.Key]string{
+		"provider.category": "search",
+		"provider.name":     "opensearch",
+		"provider.method":   "Search",
+		"provider.outcome":  OutcomeError,
+		"tenant.id":         "tenant-1",
+	}
+	got := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		got[kv.Key] = kv.Value.AsString()
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("attribute %s = %q, want %q", key, got[key], value)
+		}
+	}
+}
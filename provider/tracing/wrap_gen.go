@@ -0,0 +1,64 @@
+// Code generated by provider/tracing/gen. DO NOT EDIT.
+
+package tracing
+
+import (
+	"github.com/gondolia/gondolia/provider/auth"
+	"github.com/gondolia/gondolia/provider/crm"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/fulfillment"
+	"github.com/gondolia/gondolia/provider/notification"
+	"github.com/gondolia/gondolia/provider/payment"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/provider/tax"
+)
+
+// Wrap wraps a provider of the given category with tracing and metrics.
+// Values that do not implement the category's interface are returned unchanged.
+func Wrap(category, name string, instance any) any {
+	switch category {
+	case "auth":
+		if p, ok := instance.(auth.AuthProvider); ok {
+			return WrapAuth(p, name)
+		}
+	case "crm":
+		if p, ok := instance.(crm.CRMProvider); ok {
+			return WrapCRM(p, name)
+		}
+	case "erp":
+		if p, ok := instance.(erp.ERPProvider); ok {
+			return WrapERP(p, name)
+		}
+	case "fulfillment":
+		if p, ok := instance.(fulfillment.FulfillmentProvider); ok {
+			return WrapFulfillment(p, name)
+		}
+	case "notification":
+		if p, ok := instance.(notification.NotificationProvider); ok {
+			return WrapNotification(p, name)
+		}
+	case "payment":
+		if p, ok := instance.(payment.PaymentProvider); ok {
+			return WrapPayment(p, name)
+		}
+	case "pim":
+		if p, ok := instance.(pim.PIMProvider); ok {
+			return WrapPIM(p, name)
+		}
+	case "search":
+		if p, ok := instance.(search.SearchProvider); ok {
+			return WrapSearch(p, name)
+		}
+	case "storage":
+		if p, ok := instance.(storage.StorageProvider); ok {
+			return WrapStorage(p, name)
+		}
+	case "tax":
+		if p, ok := instance.(tax.TaxProvider); ok {
+			return WrapTax(p, name)
+		}
+	}
+	return instance
+}
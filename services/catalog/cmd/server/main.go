@@ -11,14 +11,30 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
 	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/admin"
+	"github.com/gondolia/gondolia/provider/erp"
+	_ "github.com/gondolia/gondolia/provider/erp/idoc"   // Register SAP IDoc provider
+	_ "github.com/gondolia/gondolia/provider/erp/memory" // Register memory provider
+	_ "github.com/gondolia/gondolia/provider/erp/noop"   // Register noop provider
+	_ "github.com/gondolia/gondolia/provider/erp/odata"  // Register OData provider
 	"github.com/gondolia/gondolia/provider/pim"
+	_ "github.com/gondolia/gondolia/provider/pim/akeneo" // Register Akeneo PIM provider
+	_ "github.com/gondolia/gondolia/provider/pim/feed"   // Register feed PIM provider
+	_ "github.com/gondolia/gondolia/provider/pim/noop"   // Register noop PIM provider
+	"github.com/gondolia/gondolia/provider/replay"
+	"github.com/gondolia/gondolia/provider/resilience"
 	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/tracing"
 	_ "github.com/gondolia/gondolia/provider/search/opensearch" // Register opensearch provider
 	_ "github.com/gondolia/gondolia/provider/search/noop"       // Register noop provider
+	_ "github.com/gondolia/gondolia/provider/search/memory"     // Register memory provider
+	"github.com/gondolia/gondolia/provider/storage"
+	_ "github.com/gondolia/gondolia/provider/storage/noop" // Register noop storage provider
 	"github.com/gondolia/gondolia/services/catalog/internal/config"
 	"github.com/gondolia/gondolia/services/catalog/internal/domain"
 	"github.com/gondolia/gondolia/services/catalog/internal/handler"
@@ -51,22 +67,50 @@ func main() {
 	productRepo := postgres.NewProductRepository(db)
 	categoryRepo := postgres.NewCategoryRepository(db)
 	priceRepo := postgres.NewPriceRepository(db)
+	priceImportRepo := postgres.NewPriceImportRepository(db)
+	pimSyncStateRepo := postgres.NewPIMSyncStateRepository(db)
+	pimAssetRepo := postgres.NewPIMAssetRepository(db)
 	attrTransRepo := postgres.NewAttributeTranslationRepository(db)
 
-	// Initialize PIM provider
-	var pimProvider pim.PIMProvider
-	// TODO: Initialize PIM provider based on config
-	_ = pimProvider
-
-	// Initialize search provider
-	searchProvider, err := initSearchProvider(ctx, cfg, logger)
-	if err != nil {
-		logger.Fatal("Failed to initialize search provider", zap.Error(err))
+	// Initialize provider resolver. Tenants may select their own providers in
+	// Tenant.Config["providers"] or the provider config file; all others use the
+	// defaults from the provider config file or the environment.
+	lookup := tenantConfigLookup(tenantRepo)
+	providerFile := openProviderConfig(cfg.ProviderConfigFile, logger)
+	if providerFile != nil {
+		lookup = providerFile.Lookup(lookup)
+	}
+	// Fixtures recorded with PROVIDER_RECORD_DIR can be served by the "replay" provider
+	replay.Register("search", "pim", "erp")
+	resolver := provider.NewResolver(lookup)
+	if cfg.ProviderRecordDir != "" {
+		// Record provider calls as fixtures for the replay provider; innermost, so retries are recorded too
+		logger.Warn("Recording provider calls", zap.String("dir", cfg.ProviderRecordDir))
+		resolver.Use(replay.Decorator(cfg.ProviderRecordDir))
 	}
-	if searchProvider != nil {
-		logger.Info("Search provider initialized", zap.String("provider", cfg.SearchProvider))
+	resolver.Use(resilience.Decorator(nil), tracing.Decorator())
+	resolver.SetDefault("search", searchSelection(cfg, logger))
+	resolver.SetDefault("pim", pimSelection(cfg, logger))
+	resolver.SetDefault("erp", erpSelection(cfg))
+	resolver.SetDefault("storage", storageSelection(cfg))
+	if providerFile != nil {
+		providerFile.Apply(resolver)
+	}
+
+	// Warm up the default providers; readiness reports unavailable until they are started
+	warmUpCtx, stopWarmUp := context.WithCancel(ctx)
+	defer stopWarmUp()
+	go warmUpProviders(warmUpCtx, resolver, logger)
+
+	// Rebuild the affected providers when the provider config file changes
+	if providerFile != nil {
+		go providerFile.Watch(warmUpCtx, resolver, cfg.ProviderConfigReload, logProviderReload(logger))
 	}
 
+	pimProviders := provider.NewSource[pim.PIMProvider](resolver, "pim")
+	searchProviders := provider.NewSource[search.SearchProvider](resolver, "search")
+	storageProviders := provider.NewSource[storage.StorageProvider](resolver, "storage")
+
 	// Initialize parametric repositories
 	parametricPricingRepo := postgres.NewParametricPricingRepository(db)
 	axisOptionRepo := postgres.NewAxisOptionRepository(db)
@@ -84,29 +128,42 @@ func main() {
 	parametricService := service.NewParametricService(productRepo, parametricPricingRepo, axisOptionRepo, skuMappingRepo)
 	bundleService := service.NewBundleService(bundleRepo, productRepo, priceRepo, parametricService)
 
-	var syncService *service.SyncService
-	var searchService *service.SearchService
-
-	if pimProvider != nil && searchProvider != nil {
-		syncService = service.NewSyncService(productRepo, categoryRepo, pimProvider, searchProvider)
-		searchService = service.NewSearchService(searchProvider, categoryRepo)
-	} else if searchProvider != nil {
-		// Create a minimal sync service for search indexing only
-		syncService = service.NewSyncService(productRepo, categoryRepo, nil, searchProvider)
-		searchService = service.NewSearchService(searchProvider, categoryRepo)
-	}
-
-	// Bulk index all products on startup if search provider is available
-	if searchProvider != nil && cfg.SearchProvider == "opensearch" {
-		go func() {
-			logger.Info("Starting bulk product indexing...")
-			if err := bulkIndexProducts(ctx, productRepo, searchProvider, tenantRepo, logger); err != nil {
-				logger.Error("Bulk indexing failed", zap.Error(err))
-			} else {
-				logger.Info("Bulk product indexing completed")
-			}
-		}()
-	}
+	syncService := service.NewSyncService(productRepo, categoryRepo, tenantRepo, attrTransRepo, pimSyncStateRepo, pimAssetRepo,
+		pimProviders, searchProviders, storageProviders,
+		service.SyncConfig{
+			Overlap:      cfg.PIMSyncOverlap,
+			Lease:        cfg.PIMSyncLease,
+			AssetBaseURL: cfg.PIMAssetBaseURL,
+		},
+	)
+	searchService := service.NewSearchService(searchProviders, categoryRepo)
+	priceImporter := service.NewPriceImporter(productRepo, priceImportRepo, tenantRepo,
+		provider.NewSource[erp.ERPProvider](resolver, "erp"),
+		service.PriceImportConfig{
+			Interval:  cfg.ERPPriceImportInterval,
+			Cadence:   cfg.ERPPriceImportCadence,
+			BatchSize: cfg.ERPPriceImportBatchSize,
+			Lease:     time.Hour,
+		},
+	)
+
+	// Import prices from the ERP on schedule
+	importCtx, stopImport := context.WithCancel(ctx)
+	defer stopImport()
+	importDone := make(chan struct{})
+	go func() {
+		defer close(importDone)
+		priceImporter.Run(importCtx, func(err error) {
+			logger.Error("ERP price import failed", zap.Error(err))
+		})
+	}()
+
+	// Prepare the search index and bulk index all products on startup
+	go func() {
+		if err := initSearchIndex(ctx, cfg, productRepo, searchProviders, tenantRepo, logger); err != nil {
+			logger.Error("Bulk indexing failed", zap.Error(err))
+		}
+	}()
 
 	// Initialize handlers
 	productHandler := handler.NewProductHandler(productService)
@@ -114,14 +171,15 @@ func main() {
 	variantHandler.SetParametricService(parametricService)
 	categoryHandler := handler.NewCategoryHandler(categoryService, productService)
 	priceHandler := handler.NewPriceHandler(priceService)
+	priceImportHandler := handler.NewPriceImportHandler(priceImporter)
 	parametricHandler := handler.NewParametricHandler(parametricService)
 	bundleHandler := handler.NewBundleHandler(bundleService)
 	attrTransHandler := handler.NewAttributeTranslationHandler(attrTransService)
-	
-	var searchHandler *handler.SearchHandler
-	if searchService != nil && syncService != nil {
-		searchHandler = handler.NewSearchHandler(searchService, syncService)
-	}
+	searchHandler := handler.NewSearchHandler(searchService, syncService)
+	assetHandler := handler.NewAssetHandler(syncService)
+	providerAdminHandler := admin.NewHandler(resolver, func(c *gin.Context) (string, bool) {
+		return middleware.GetTenantID(c).String(), true
+	})
 
 	// Initialize HTTP server (REST API)
 	gin.SetMode(gin.ReleaseMode)
@@ -135,9 +193,12 @@ func main() {
 
 	// Health endpoints
 	router.GET("/health/live", handler.LivenessHandler)
-	router.GET("/health/ready", handler.ReadinessHandler)
+	router.GET("/health/ready", handler.ReadinessHandler(resolver.Ready))
 	router.GET("/metrics", handler.MetricsHandler)
 
+	// PIM assets; public, as image URLs carry no tenant headers
+	router.GET("/assets/*path", assetHandler.Get)
+
 	// API routes
 	api := router.Group("/api/v1")
 
@@ -204,6 +265,8 @@ func main() {
 	// Price endpoints
 	prices := api.Group("/prices")
 	{
+		prices.POST("/import", priceImportHandler.Import) // ERP price import (SKU list, dry run)
+		prices.GET("/imports", priceImportHandler.ListRuns)
 		prices.PUT("/:id", priceHandler.Update)
 		prices.DELETE("/:id", priceHandler.Delete)
 	}
@@ -218,10 +281,19 @@ func main() {
 		attrTrans.DELETE("/:id", attrTransHandler.Delete)
 	}
 
-	// Search endpoints (if available)
-	if searchHandler != nil {
-		api.GET("/search", searchHandler.Search)
-		api.POST("/sync/pim", searchHandler.SyncPIM)
+	// Search endpoints
+	api.GET("/search", searchHandler.Search)
+	api.POST("/sync/pim", searchHandler.SyncPIM)
+	api.GET("/sync/pim/state", searchHandler.GetSyncState)
+	api.DELETE("/sync/pim/state", searchHandler.ResetSyncState)
+
+	// Provider admin endpoints (catalog, active providers, health probes)
+	providerAdminHandler.RegisterRoutes(api.Group("/admin"))
+
+	// Operator endpoints (providers of all tenants); not mounted without a token
+	if cfg.OperatorToken != "" {
+		operator := router.Group("/operator", admin.RequireOperator(cfg.OperatorToken))
+		providerAdminHandler.RegisterOperatorRoutes(operator)
 	}
 
 	httpServer := &http.Server{
@@ -267,11 +339,134 @@ func main() {
 		logger.Error("HTTP server shutdown error", zap.Error(err))
 	}
 
+	// Stop the price import before closing the providers it uses; an
+	// interrupted run is failed once its lease expires
+	stopImport()
+	<-importDone
+
+	// Close providers once no requests are in flight, flushing their buffers and connections
+	stopWarmUp()
+	if err := resolver.Close(shutdownCtx); err != nil {
+		logger.Error("Provider shutdown error", zap.Error(err))
+	}
+
 	logger.Info("Servers stopped")
 }
 
-// initSearchProvider initializes the search provider based on configuration
-func initSearchProvider(ctx context.Context, cfg *config.Config, logger *zap.Logger) (search.SearchProvider, error) {
+// warmUpProviders starts the default providers so that connections are open
+// before the service reports ready, retrying until they start or ctx ends
+func warmUpProviders(ctx context.Context, resolver *provider.Resolver, logger *zap.Logger) {
+	for {
+		err := resolver.Start(ctx)
+		if err == nil {
+			logger.Info("Providers started")
+			return
+		}
+		logger.Warn("Provider warm-up failed, retrying", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// openProviderConfig loads the provider config file, if one is configured
+func openProviderConfig(path string, logger *zap.Logger) *provider.ConfigFile {
+	if path == "" {
+		return nil
+	}
+	file, err := provider.OpenConfigFile(path)
+	if err != nil {
+		logger.Fatal("Failed to load provider config", zap.Error(err))
+	}
+	logger.Info("Loaded provider config", zap.String("path", path))
+	return file
+}
+
+// logProviderReload logs hot reloads of the provider config file
+func logProviderReload(logger *zap.Logger) func(changes []string, err error) {
+	return func(changes []string, err error) {
+		if err != nil {
+			logger.Error("Provider config reload failed, keeping current config", zap.Error(err))
+			return
+		}
+		logger.Info("Provider config reloaded", zap.Strings("changes", changes))
+	}
+}
+
+// tenantConfigLookup reads provider selections from the tenant's config
+func tenantConfigLookup(tenantRepo *postgres.TenantRepository) provider.TenantConfigLookup {
+	return func(ctx context.Context, tenantID string) (map[string]any, error) {
+		id, err := uuid.Parse(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		tenant, err := tenantRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return tenant.Config, nil
+	}
+}
+
+// pimSelection returns the default PIM provider selection based on configuration
+func pimSelection(cfg *config.Config, logger *zap.Logger) provider.Selection {
+	providerType := cfg.PIMProvider
+	if providerType == "" || providerType == "mock" {
+		providerType = "noop"
+	}
+
+	var providerConfig map[string]any
+
+	switch providerType {
+	case "feed":
+		// Feeds in object storage are selected per tenant or in the provider config file
+		providerConfig = map[string]any{"path": cfg.PIMFeedPath}
+	case "akeneo":
+		providerConfig = map[string]any{
+			"base_url":      cfg.PIMURL,
+			"client_id":     cfg.PIMClientID,
+			"client_secret": cfg.PIMClientSecret,
+			"username":      cfg.PIMUsername,
+			"password":      cfg.PIMPassword,
+		}
+		if cfg.PIMChannel != "" {
+			providerConfig["channel"] = cfg.PIMChannel
+		}
+	}
+
+	if meta, ok := provider.GetMetadata("pim", providerType); ok {
+		logger.Info("Default PIM provider",
+			zap.String("provider", providerType),
+			zap.Any("config", provider.RedactConfig(meta.ConfigSpec, providerConfig)),
+		)
+	}
+
+	return provider.Selection{Name: providerType, Config: providerConfig}
+}
+
+// erpSelection returns the default ERP provider from the environment
+func erpSelection(cfg *config.Config) provider.Selection {
+	providerType := cfg.ERPProvider
+	if providerType == "" || providerType == "mock" {
+		providerType = "noop"
+	}
+	return provider.Selection{Name: providerType}
+}
+
+// storageSelection returns the default object storage from the environment
+func storageSelection(cfg *config.Config) provider.Selection {
+	providerType := cfg.StorageProvider
+	if providerType == "" || providerType == "mock" {
+		providerType = "noop"
+	}
+	return provider.Selection{Name: providerType}
+}
+
+// searchSelection returns the default search provider selection based on configuration
+func searchSelection(cfg *config.Config, logger *zap.Logger) provider.Selection {
 	providerType := cfg.SearchProvider
 	if providerType == "" || providerType == "mock" {
 		providerType = "noop"
@@ -299,15 +494,28 @@ func initSearchProvider(ctx context.Context, cfg *config.Config, logger *zap.Log
 		}
 	}
 
-	// Get provider factory from registry
-	factory, err := provider.Get[search.SearchProvider]("search", providerType)
+	if meta, ok := provider.GetMetadata("search", providerType); ok {
+		logger.Info("Default search provider",
+			zap.String("provider", providerType),
+			zap.Any("config", provider.RedactConfig(meta.ConfigSpec, providerConfig)),
+		)
+	}
+
+	return provider.Selection{Name: providerType, Config: providerConfig}
+}
+
+// initSearchIndex checks the demo tenant's search provider, configures the
+// products index and bulk indexes all products
+func initSearchIndex(ctx context.Context, cfg *config.Config, productRepo *postgres.ProductRepository, searchProviders provider.Source[search.SearchProvider], tenantRepo *postgres.TenantRepository, logger *zap.Logger) error {
+	tenant, err := tenantRepo.GetByCode(ctx, "demo")
 	if err != nil {
-		return nil, fmt.Errorf("search provider '%s' not found: %w", providerType, err)
+		return fmt.Errorf("failed to get demo tenant: %w", err)
 	}
 
-	searchProv, err := factory(providerConfig)
+	ctx = provider.WithTenant(ctx, tenant.ID.String())
+	searchProv, err := searchProviders.For(ctx, tenant.ID.String())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create search provider: %w", err)
+		return fmt.Errorf("failed to create search provider: %w", err)
 	}
 
 	// Test connection
@@ -316,15 +524,21 @@ func initSearchProvider(ctx context.Context, cfg *config.Config, logger *zap.Log
 		// Don't fail startup, just log the warning
 	}
 
-	// Configure products index if opensearch
-	if providerType == "opensearch" {
-		if err := configureProductsIndex(ctx, searchProv, logger); err != nil {
-			logger.Error("Failed to configure products index", zap.Error(err))
-			// Don't fail startup
-		}
+	if cfg.SearchProvider != "opensearch" {
+		return nil
+	}
+
+	if err := configureProductsIndex(ctx, searchProv, logger); err != nil {
+		logger.Error("Failed to configure products index", zap.Error(err))
+		// Don't fail startup
 	}
 
-	return searchProv, nil
+	logger.Info("Starting bulk product indexing...")
+	if err := bulkIndexProducts(ctx, productRepo, searchProv, tenant, logger); err != nil {
+		return err
+	}
+	logger.Info("Bulk product indexing completed")
+	return nil
 }
 
 // configureProductsIndex configures the OpenSearch products index
@@ -367,13 +581,7 @@ func configureProductsIndex(ctx context.Context, provider search.SearchProvider,
 }
 
 // bulkIndexProducts indexes all products into OpenSearch
-func bulkIndexProducts(ctx context.Context, productRepo *postgres.ProductRepository, searchProv search.SearchProvider, tenantRepo *postgres.TenantRepository, logger *zap.Logger) error {
-	// Get all tenants by trying known codes
-	tenant, err := tenantRepo.GetByCode(ctx, "demo")
-	if err != nil {
-		return fmt.Errorf("failed to get demo tenant: %w", err)
-	}
-
+func bulkIndexProducts(ctx context.Context, productRepo *postgres.ProductRepository, searchProv search.SearchProvider, tenant *domain.Tenant, logger *zap.Logger) error {
 	filter := domain.ProductFilter{
 		TenantID: tenant.ID,
 		Limit:    10000,
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -30,14 +31,47 @@ type Config struct {
 	AllowedOrigins []string
 
 	// PIM Provider
-	PIMProvider string
-	PIMURL      string
-	PIMAPIKey   string
+	PIMProvider     string
+	PIMURL          string
+	PIMAPIKey       string
+	PIMClientID     string
+	PIMClientSecret string
+	PIMUsername     string
+	PIMPassword     string
+	PIMChannel      string
+	PIMFeedPath     string
+
+	// PIM sync (incremental syncs read the products updated since the last successful run)
+	PIMSyncOverlap time.Duration
+	PIMSyncLease   time.Duration
+	// PIM assets (product images) are copied to the object storage; image URLs
+	// start with the base URL, by default the /assets route of the service
+	PIMAssetBaseURL string
+	StorageProvider string
 
 	// Search Provider
 	SearchProvider string
 	SearchURL      string
 	SearchAPIKey   string
+
+	// ERP Provider
+	ERPProvider string
+
+	// Price import (tier and contract prices from the tenant's ERP price lists)
+	ERPPriceImportInterval  time.Duration
+	ERPPriceImportCadence   time.Duration
+	ERPPriceImportBatchSize int
+
+	// Operator routes (across tenants) require this token in the
+	// X-Operator-Token header; without it they are not mounted
+	OperatorToken string
+
+	// Provider config file (YAML); its defaults override the provider settings above
+	ProviderConfigFile   string
+	ProviderConfigReload time.Duration
+
+	// Directory to record provider calls to, as fixtures for the replay provider
+	ProviderRecordDir string
 }
 
 func Load() (*Config, error) {
@@ -57,9 +91,30 @@ func Load() (*Config, error) {
 		PIMProvider:      getEnv("PIM_PROVIDER", "mock"),
 		PIMURL:           getEnv("PIM_URL", ""),
 		PIMAPIKey:        getEnv("PIM_API_KEY", ""),
+		PIMClientID:      getEnv("PIM_CLIENT_ID", ""),
+		PIMClientSecret:  getEnv("PIM_CLIENT_SECRET", ""),
+		PIMUsername:      getEnv("PIM_USERNAME", ""),
+		PIMPassword:      getEnv("PIM_PASSWORD", ""),
+		PIMChannel:       getEnv("PIM_CHANNEL", ""),
+		PIMFeedPath:      getEnv("PIM_FEED_PATH", ""),
+		PIMSyncOverlap:   getDurationEnv("PIM_SYNC_OVERLAP", 5*time.Minute),
+		PIMSyncLease:     getDurationEnv("PIM_SYNC_LEASE", 10*time.Minute),
+		PIMAssetBaseURL:  getEnv("PIM_ASSET_BASE_URL", "/assets"),
+		StorageProvider:  getEnv("STORAGE_PROVIDER", "noop"),
 		SearchProvider:   getEnv("SEARCH_PROVIDER", "mock"),
 		SearchURL:        getEnv("SEARCH_URL", ""),
 		SearchAPIKey:     getEnv("SEARCH_API_KEY", ""),
+		ERPProvider:      getEnv("ERP_PROVIDER", "noop"),
+
+		// Only tenants with price lists in Config["erp"]["price_lists"] are imported
+		ERPPriceImportInterval:  getDurationEnv("ERP_PRICE_IMPORT_INTERVAL", 15*time.Minute),
+		ERPPriceImportCadence:   getDurationEnv("ERP_PRICE_IMPORT_CADENCE", 6*time.Hour),
+		ERPPriceImportBatchSize: getIntEnv("ERP_PRICE_IMPORT_BATCH_SIZE", 100),
+
+		OperatorToken:        getEnv("OPERATOR_TOKEN", ""),
+		ProviderConfigFile:   getEnv("PROVIDER_CONFIG_FILE", ""),
+		ProviderConfigReload: getDurationEnv("PROVIDER_CONFIG_RELOAD", 10*time.Second),
+		ProviderRecordDir:    getEnv("PROVIDER_RECORD_DIR", ""),
 	}
 
 	return cfg, nil
@@ -90,6 +145,15 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
 func getSliceEnv(key string, defaultValue []string) []string {
 	if value, exists := os.LookupEnv(key); exists && value != "" {
 		var result []string
@@ -20,6 +20,14 @@ var (
 	ErrPriceInvalidRange  = errors.New("invalid price date range")
 	ErrPriceOverlap       = errors.New("price range overlaps with existing price")
 
+	// Price import errors
+	ErrPriceImportRunning = errors.New("a price import is already running for this tenant")
+	ErrNoERPPriceLists    = errors.New("no ERP price lists configured for this tenant")
+	ErrPriceImportFailed  = errors.New("price import from the ERP failed")
+
+	// PIM sync errors
+	ErrPIMSyncRunning = errors.New("a PIM sync is already running for this tenant")
+
 	// Tenant errors
 	ErrTenantNotFound  = errors.New("tenant not found")
 	ErrTenantNotActive = errors.New("tenant is not active")
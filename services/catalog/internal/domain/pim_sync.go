@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PIM sync statuses
+const (
+	PIMSyncIdle    = "idle"
+	PIMSyncRunning = "running"
+	PIMSyncFailed  = "failed"
+)
+
+// PIMSyncState is the sync progress of a tenant with a PIM provider.
+// Incremental syncs read the products updated since Watermark; a run that
+// fails keeps its cursor, and the next run of the same kind resumes there.
+type PIMSyncState struct {
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	Provider      string     `json:"provider"`
+	Watermark     *time.Time `json:"watermark,omitempty"` // nil = never synced
+	Status        string     `json:"status"`
+	RunFull       bool       `json:"run_full"`
+	RunSince      *time.Time `json:"run_since,omitempty"`
+	RunStartedAt  *time.Time `json:"run_started_at,omitempty"`
+	Cursor        string     `json:"cursor,omitempty"` // "" = first page
+	Pages         int        `json:"pages"`
+	Failed        int        `json:"failed"` // Products and models that failed on the processed pages
+	LastError     *string    `json:"last_error,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Resumable reports whether a run of the kind continues the interrupted run
+func (s *PIMSyncState) Resumable(full bool) bool {
+	return s.RunStartedAt != nil && s.Cursor != "" && s.RunFull == full
+}
+
+// PIMMapping configures how PIM attribute values become product attributes.
+// It is read from the "pim" section of the tenant config.
+type PIMMapping struct {
+	Channel  string   `json:"channel,omitempty"`  // Scope of the values to read; "" = any
+	Locales  []string `json:"locales,omitempty"`  // PIM locales of localizable values in order of preference
+	Currency string   `json:"currency,omitempty"` // Currency of price attributes; "" = the first price
+	// Attributes maps PIM attributes to product attributes; without
+	// mappings all attributes except name, description and media are mapped
+	// under their PIM code
+	Attributes []PIMAttributeMapping `json:"attributes,omitempty"`
+	// Images lists the PIM media attributes that become product images, in
+	// order; the first image is the primary one. Without images all media
+	// attributes are used. Files that are not images are skipped.
+	Images []string `json:"images,omitempty"`
+	// OverwriteTranslations replaces existing attribute translations with the
+	// PIM labels; otherwise only missing translations are created
+	OverwriteTranslations bool `json:"overwrite_translations,omitempty"`
+	// Variants syncs root product models as variant parents with the axes of
+	// their family variant, and the products of a model as its variants;
+	// otherwise all products are synced as simple products
+	Variants bool `json:"variants,omitempty"`
+}
+
+// PIMAttributeMapping maps a PIM attribute to a product attribute
+type PIMAttributeMapping struct {
+	Code    string        `json:"code"`              // PIM attribute code
+	Key     string        `json:"key,omitempty"`     // Product attribute key; "" = the PIM code
+	Type    AttributeType `json:"type,omitempty"`    // "" = derived from the PIM attribute type
+	Locales []string      `json:"locales,omitempty"` // Overrides the locales of the mapping
+	Scope   string        `json:"scope,omitempty"`   // Overrides the channel of the mapping
+}
+
+// PIMAsset is a PIM asset copied to object storage. Assets with the same
+// content share the stored file.
+type PIMAsset struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Code        string    `json:"code"`
+	Hash        string    `json:"hash"` // Hex SHA-256 of the content
+	Path        string    `json:"path"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SyncedAt    time.Time `json:"synced_at"` // Last download from the PIM
+	SeenAt      time.Time `json:"seen_at"`   // Last sync of a product using the asset
+}
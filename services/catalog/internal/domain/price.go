@@ -6,6 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// Price sources
+const (
+	PriceSourceManual = "manual" // Maintained in Gondolia
+	PriceSourceERP    = "erp"    // Imported from the ERP; overwritten by the next import
+)
+
 // Price represents a B2B contract price for a product
 type Price struct {
 	ID              uuid.UUID  `json:"id"`
@@ -17,6 +23,7 @@ type Price struct {
 	Currency        string     `json:"currency"`
 	ValidFrom       *time.Time `json:"valid_from,omitempty"`
 	ValidTo         *time.Time `json:"valid_to,omitempty"`
+	Source          string     `json:"source"` // manual or erp
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
@@ -51,6 +58,7 @@ func NewPrice(tenantID, productID uuid.UUID, price float64, currency string) *Pr
 		MinQuantity: 1,
 		Price:       price,
 		Currency:    currency,
+		Source:      PriceSourceManual,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price import triggers
+const (
+	PriceImportScheduled = "scheduled"
+	PriceImportManual    = "manual"
+)
+
+// Price import run statuses
+const (
+	PriceImportRunning   = "running"
+	PriceImportCompleted = "completed"
+	PriceImportFailed    = "failed"
+)
+
+// Price change actions of an import
+const (
+	PriceChangeCreate = "create"
+	PriceChangeUpdate = "update"
+	PriceChangeExpire = "expire"
+)
+
+// ERPPriceList maps an ERP customer, i.e. a customer group or a company in
+// the ERP, to the prices of a catalog customer group. Without a customer
+// group its prices are the base prices.
+type ERPPriceList struct {
+	ERPCustomerID   string     `json:"customer_id"`
+	CustomerGroupID *uuid.UUID `json:"customer_group_id,omitempty"`
+	Currency        string     `json:"currency,omitempty"`
+}
+
+// PriceImportRun records an import of ERP prices
+type PriceImportRun struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Trigger     string     `json:"trigger"`
+	DryRun      bool       `json:"dry_run"`
+	SKUs        []string   `json:"skus,omitempty"` // nil = all products
+	Status      string     `json:"status"`
+	Created     int        `json:"created"`
+	Updated     int        `json:"updated"`
+	Expired     int        `json:"expired"`
+	Unchanged   int        `json:"unchanged"`
+	Error       *string    `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// NewPriceImportRun creates a running import run
+func NewPriceImportRun(tenantID uuid.UUID, trigger string, skus []string, dryRun bool) *PriceImportRun {
+	return &PriceImportRun{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Trigger:   trigger,
+		DryRun:    dryRun,
+		SKUs:      skus,
+		Status:    PriceImportRunning,
+		StartedAt: time.Now(),
+	}
+}
+
+// PriceChange is a change of a catalog price by an import
+type PriceChange struct {
+	Action          string     `json:"action"`
+	PriceID         uuid.UUID  `json:"price_id"`
+	ProductID       uuid.UUID  `json:"product_id"`
+	SKU             string     `json:"sku"`
+	CustomerGroupID *uuid.UUID `json:"customer_group_id,omitempty"`
+	MinQuantity     int        `json:"min_quantity"`
+	Currency        string     `json:"currency"`
+	OldPrice        *float64   `json:"old_price,omitempty"`
+	NewPrice        *float64   `json:"new_price,omitempty"`
+	ValidFrom       *time.Time `json:"valid_from,omitempty"`
+	ValidTo         *time.Time `json:"valid_to,omitempty"`
+}
+
+// PriceImportResult is the outcome of an import; in a dry run the changes
+// are not applied
+type PriceImportResult struct {
+	Run         *PriceImportRun `json:"run"`
+	Changes     []PriceChange   `json:"changes"`
+	UnknownSKUs []string        `json:"unknown_skus,omitempty"` // Requested SKUs without a catalog product
+}
+
+// PriceImportRequest represents a request to import the prices of some or all products
+type PriceImportRequest struct {
+	SKUs   []string `json:"skus,omitempty"`
+	DryRun bool     `json:"dry_run"`
+}
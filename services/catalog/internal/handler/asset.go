@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/services/catalog/internal/service"
+)
+
+// AssetHandler serves the PIM assets stored by the sync
+type AssetHandler struct {
+	syncService *service.SyncService
+}
+
+// NewAssetHandler creates a new asset handler
+func NewAssetHandler(syncService *service.SyncService) *AssetHandler {
+	return &AssetHandler{syncService: syncService}
+}
+
+// Get handles GET /assets/*path
+// Asset paths are derived from the content hash, so responses never change.
+// The route is public, so errors are not described.
+func (h *AssetHandler) Get(c *gin.Context) {
+	body, info, err := h.syncService.OpenAsset(c.Request.Context(), strings.TrimPrefix(c.Param("path"), "/"))
+	if err != nil {
+		status, code, message := http.StatusInternalServerError, "INTERNAL_ERROR", "failed to open asset"
+		if errors.Is(err, provider.ErrNotFound) {
+			status, code, message = http.StatusNotFound, "NOT_FOUND", "asset not found"
+		}
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    code,
+				"message": message,
+			},
+		})
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	if info.Size > 0 {
+		c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", contentType)
+	_, _ = io.Copy(c.Writer, body)
+}
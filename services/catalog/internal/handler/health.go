@@ -13,12 +13,22 @@ func LivenessHandler(c *gin.Context) {
 	})
 }
 
-// ReadinessHandler handles readiness probe
-func ReadinessHandler(c *gin.Context) {
-	// TODO: Check database connection, cache, etc.
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ready",
-	})
+// ReadinessHandler handles readiness probe and reports unavailable
+// until ready, e.g. the provider warm-up, returns nil
+func ReadinessHandler(ready func() error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// TODO: Check database connection, cache, etc.
+		if err := ready(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "not ready",
+				"error":  err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ready",
+		})
+	}
 }
 
 // MetricsHandler handles metrics endpoint
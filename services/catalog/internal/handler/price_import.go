@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+	"github.com/gondolia/gondolia/services/catalog/internal/middleware"
+	"github.com/gondolia/gondolia/services/catalog/internal/service"
+)
+
+// PriceImportHandler handles the import of ERP prices
+type PriceImportHandler struct {
+	importer *service.PriceImporter
+}
+
+// NewPriceImportHandler creates a new price import handler
+func NewPriceImportHandler(importer *service.PriceImporter) *PriceImportHandler {
+	return &PriceImportHandler{importer: importer}
+}
+
+// Import handles POST /prices/import
+func (h *PriceImportHandler) Import(c *gin.Context) {
+	var req domain.PriceImportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "VALIDATION_ERROR",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	result, err := h.importer.Import(c.Request.Context(), middleware.GetTenantID(c), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		code := "IMPORT_ERROR"
+		switch {
+		case errors.Is(err, domain.ErrPriceImportRunning):
+			status = http.StatusConflict
+			code = "IMPORT_RUNNING"
+		case errors.Is(err, domain.ErrNoERPPriceLists):
+			status = http.StatusUnprocessableEntity
+			code = "NO_PRICE_LISTS"
+		case errors.Is(err, domain.ErrPriceImportFailed):
+			status = http.StatusBadGateway
+			code = "ERP_ERROR"
+		case domain.IsNotFoundError(err):
+			status = http.StatusNotFound
+			code = "NOT_FOUND"
+		}
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    code,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListRuns handles GET /prices/imports
+func (h *PriceImportHandler) ListRuns(c *gin.Context) {
+	limit := parseInt(c.Query("limit"), 20)
+	offset := parseInt(c.Query("offset"), 0)
+
+	runs, total, err := h.importer.ListRuns(c.Request.Context(), middleware.GetTenantID(c), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   runs,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
 	"github.com/gondolia/gondolia/services/catalog/internal/middleware"
 	"github.com/gondolia/gondolia/services/catalog/internal/service"
 )
@@ -81,9 +83,15 @@ func (h *SearchHandler) SyncPIM(c *gin.Context) {
 
 	result, err := h.syncService.SyncFromPIM(c.Request.Context(), tenantID, fullSync)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		code := "SYNC_ERROR"
+		if errors.Is(err, domain.ErrPIMSyncRunning) {
+			status = http.StatusConflict
+			code = "SYNC_RUNNING"
+		}
+		c.JSON(status, gin.H{
 			"error": gin.H{
-				"code":    "SYNC_ERROR",
+				"code":    code,
 				"message": err.Error(),
 			},
 		})
@@ -92,3 +100,42 @@ func (h *SearchHandler) SyncPIM(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// GetSyncState handles GET /sync/pim/state
+func (h *SearchHandler) GetSyncState(c *gin.Context) {
+	states, err := h.syncService.SyncStates(c.Request.Context(), middleware.GetTenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": states})
+}
+
+// ResetSyncState handles DELETE /sync/pim/state?provider=
+// The next incremental sync of the provider, or of all providers, reads all products.
+func (h *SearchHandler) ResetSyncState(c *gin.Context) {
+	err := h.syncService.ResetSyncState(c.Request.Context(), middleware.GetTenantID(c), c.Query("provider"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		code := "INTERNAL_ERROR"
+		if errors.Is(err, domain.ErrPIMSyncRunning) {
+			status = http.StatusConflict
+			code = "SYNC_RUNNING"
+		}
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    code,
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/services/catalog/internal/repository"
 )
 
@@ -50,6 +51,7 @@ func TenantMiddleware(tenantRepo repository.TenantRepository) gin.HandlerFunc {
 		// Set tenant in context
 		c.Set("tenant", tenant)
 		c.Set(ContextKeyTenantID, tenant.ID)
+		c.Request = c.Request.WithContext(provider.WithTenant(c.Request.Context(), tenant.ID.String()))
 
 		c.Next()
 	}
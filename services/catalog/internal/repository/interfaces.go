@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -12,6 +13,7 @@ import (
 type TenantRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error)
 	GetByCode(ctx context.Context, code string) (*domain.Tenant, error)
+	ListActive(ctx context.Context) ([]domain.Tenant, error)
 }
 
 // ProductRepository defines the interface for product data access
@@ -50,6 +52,10 @@ type CategoryRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error // Soft delete
 	HasProducts(ctx context.Context, id uuid.UUID) (bool, error)
 	GetAncestors(ctx context.Context, id uuid.UUID) ([]domain.Category, error)
+
+	// ListAll returns all categories of a tenant, including deleted ones
+	ListAll(ctx context.Context, tenantID uuid.UUID) ([]domain.Category, error)
+	Restore(ctx context.Context, id uuid.UUID) error // Undo a soft delete
 }
 
 // PriceRepository defines the interface for price data access
@@ -63,6 +69,45 @@ type PriceRepository interface {
 	CheckOverlap(ctx context.Context, price *domain.Price) (bool, error)
 }
 
+// PriceImportRepository defines the interface for ERP price imports
+type PriceImportRepository interface {
+	// ListERPPrices returns the imported prices of a customer group (nil for
+	// the base prices) that have not expired at the given time
+	ListERPPrices(ctx context.Context, tenantID uuid.UUID, customerGroupID *uuid.UUID, at time.Time) ([]domain.Price, error)
+	// SavePrices creates and updates prices in one transaction
+	SavePrices(ctx context.Context, created, updated []domain.Price) error
+
+	// StartRun records a running import; returns ErrPriceImportRunning if
+	// another import of the tenant started within the lease is still running
+	StartRun(ctx context.Context, run *domain.PriceImportRun, lease time.Duration) error
+	FinishRun(ctx context.Context, run *domain.PriceImportRun) error
+	// LastRun returns the latest import of the tenant with the trigger, or nil if there is none
+	LastRun(ctx context.Context, tenantID uuid.UUID, trigger string) (*domain.PriceImportRun, error)
+	ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]domain.PriceImportRun, int, error)
+}
+
+// PIMSyncStateRepository defines the interface for PIM sync states
+type PIMSyncStateRepository interface {
+	// Get returns the state of a tenant and provider, or nil if there is none
+	Get(ctx context.Context, tenantID uuid.UUID, provider string) (*domain.PIMSyncState, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMSyncState, error)
+	// Claim marks the state as running; returns ErrPIMSyncRunning if another
+	// run of the tenant and provider updated it within the lease
+	Claim(ctx context.Context, state *domain.PIMSyncState, lease time.Duration) error
+	Save(ctx context.Context, state *domain.PIMSyncState) error
+	// Delete removes the states of a tenant, of all providers if provider is empty
+	Delete(ctx context.Context, tenantID uuid.UUID, provider string) error
+}
+
+// PIMAssetRepository defines the interface for PIM assets in object storage
+type PIMAssetRepository interface {
+	// Get returns the asset of a tenant and code, or nil if there is none
+	Get(ctx context.Context, tenantID uuid.UUID, code string) (*domain.PIMAsset, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMAsset, error)
+	Save(ctx context.Context, asset *domain.PIMAsset) error
+	Delete(ctx context.Context, tenantID uuid.UUID, codes []string) error
+}
+
 // ParametricPricingRepository defines the interface for parametric pricing data access
 type ParametricPricingRepository interface {
 	GetByProductID(ctx context.Context, productID uuid.UUID) (*domain.ParametricPricing, error)
@@ -370,6 +370,50 @@ func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *CategoryRepository) ListAll(ctx context.Context, tenantID uuid.UUID) ([]domain.Category, error) {
+	query := `
+		SELECT id, tenant_id, code, parent_id, name, description, image, sort_order, active,
+		       pim_code, last_synced_at, created_at, updated_at, deleted_at
+		FROM categories
+		WHERE tenant_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []domain.Category
+	for rows.Next() {
+		category, err := r.scanCategoryFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *CategoryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE categories SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCategoryNotFound
+	}
+
+	return nil
+}
+
 func (r *CategoryRepository) HasProducts(ctx context.Context, id uuid.UUID) (bool, error) {
 	query := `
 		SELECT EXISTS(
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+type PIMAssetRepository struct {
+	db *DB
+}
+
+func NewPIMAssetRepository(db *DB) *PIMAssetRepository {
+	return &PIMAssetRepository{db: db}
+}
+
+const pimAssetColumns = `
+	tenant_id, code, hash, path, content_type, size, synced_at, seen_at`
+
+func scanPIMAsset(row pgx.Row) (*domain.PIMAsset, error) {
+	var a domain.PIMAsset
+	err := row.Scan(
+		&a.TenantID,
+		&a.Code,
+		&a.Hash,
+		&a.Path,
+		&a.ContentType,
+		&a.Size,
+		&a.SyncedAt,
+		&a.SeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PIMAssetRepository) Get(ctx context.Context, tenantID uuid.UUID, code string) (*domain.PIMAsset, error) {
+	query := `SELECT` + pimAssetColumns + `
+		FROM pim_assets
+		WHERE tenant_id = $1 AND code = $2`
+
+	asset, err := scanPIMAsset(r.db.Pool.QueryRow(ctx, query, tenantID, code))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return asset, err
+}
+
+func (r *PIMAssetRepository) List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMAsset, error) {
+	query := `SELECT` + pimAssetColumns + `
+		FROM pim_assets
+		WHERE tenant_id = $1
+		ORDER BY code`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []domain.PIMAsset{}
+	for rows.Next() {
+		asset, err := scanPIMAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, *asset)
+	}
+
+	return assets, rows.Err()
+}
+
+func (r *PIMAssetRepository) Save(ctx context.Context, asset *domain.PIMAsset) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO pim_assets (`+pimAssetColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, code) DO UPDATE SET
+			hash = EXCLUDED.hash,
+			path = EXCLUDED.path,
+			content_type = EXCLUDED.content_type,
+			size = EXCLUDED.size,
+			synced_at = EXCLUDED.synced_at,
+			seen_at = EXCLUDED.seen_at`,
+		asset.TenantID, asset.Code, asset.Hash, asset.Path, asset.ContentType, asset.Size, asset.SyncedAt, asset.SeenAt,
+	)
+	return err
+}
+
+func (r *PIMAssetRepository) Delete(ctx context.Context, tenantID uuid.UUID, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	_, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM pim_assets
+		WHERE tenant_id = $1 AND code = ANY($2)`,
+		tenantID, codes,
+	)
+	return err
+}
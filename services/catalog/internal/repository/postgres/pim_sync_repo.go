@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+type PIMSyncStateRepository struct {
+	db *DB
+}
+
+func NewPIMSyncStateRepository(db *DB) *PIMSyncStateRepository {
+	return &PIMSyncStateRepository{db: db}
+}
+
+const pimSyncStateColumns = `
+	tenant_id, provider, watermark, status, run_full, run_since, run_started_at,
+	COALESCE(cursor, ''), pages, failed, last_error, last_success_at, updated_at`
+
+func scanPIMSyncState(row pgx.Row) (*domain.PIMSyncState, error) {
+	var s domain.PIMSyncState
+	err := row.Scan(
+		&s.TenantID,
+		&s.Provider,
+		&s.Watermark,
+		&s.Status,
+		&s.RunFull,
+		&s.RunSince,
+		&s.RunStartedAt,
+		&s.Cursor,
+		&s.Pages,
+		&s.Failed,
+		&s.LastError,
+		&s.LastSuccessAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PIMSyncStateRepository) Get(ctx context.Context, tenantID uuid.UUID, provider string) (*domain.PIMSyncState, error) {
+	query := `SELECT` + pimSyncStateColumns + `
+		FROM pim_sync_states
+		WHERE tenant_id = $1 AND provider = $2`
+
+	state, err := scanPIMSyncState(r.db.Pool.QueryRow(ctx, query, tenantID, provider))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return state, err
+}
+
+func (r *PIMSyncStateRepository) List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMSyncState, error) {
+	query := `SELECT` + pimSyncStateColumns + `
+		FROM pim_sync_states
+		WHERE tenant_id = $1
+		ORDER BY provider`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := []domain.PIMSyncState{}
+	for rows.Next() {
+		state, err := scanPIMSyncState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, *state)
+	}
+
+	return states, rows.Err()
+}
+
+func (r *PIMSyncStateRepository) Claim(ctx context.Context, state *domain.PIMSyncState, lease time.Duration) error {
+	// Runs of crashed instances stay 'running'; they are taken over once the lease is over
+	result, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO pim_sync_states (
+			tenant_id, provider, watermark, status, run_full, run_since, run_started_at,
+			cursor, pages, failed, last_error, last_success_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12, $13)
+		ON CONFLICT (tenant_id, provider) DO UPDATE SET
+			status = EXCLUDED.status,
+			run_full = EXCLUDED.run_full,
+			run_since = EXCLUDED.run_since,
+			run_started_at = EXCLUDED.run_started_at,
+			cursor = EXCLUDED.cursor,
+			pages = EXCLUDED.pages,
+			failed = EXCLUDED.failed,
+			updated_at = EXCLUDED.updated_at
+		WHERE pim_sync_states.status <> 'running' OR pim_sync_states.updated_at < $14`,
+		state.TenantID, state.Provider, state.Watermark, state.Status, state.RunFull, state.RunSince, state.RunStartedAt,
+		state.Cursor, state.Pages, state.Failed, state.LastError, state.LastSuccessAt, state.UpdatedAt,
+		time.Now().Add(-lease),
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrPIMSyncRunning
+	}
+	return nil
+}
+
+func (r *PIMSyncStateRepository) Save(ctx context.Context, state *domain.PIMSyncState) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE pim_sync_states SET
+			watermark = $3,
+			status = $4,
+			run_full = $5,
+			run_since = $6,
+			run_started_at = $7,
+			cursor = NULLIF($8, ''),
+			pages = $9,
+			failed = $10,
+			last_error = $11,
+			last_success_at = $12,
+			updated_at = $13
+		WHERE tenant_id = $1 AND provider = $2`,
+		state.TenantID, state.Provider, state.Watermark, state.Status, state.RunFull, state.RunSince, state.RunStartedAt,
+		state.Cursor, state.Pages, state.Failed, state.LastError, state.LastSuccessAt, state.UpdatedAt,
+	)
+	return err
+}
+
+func (r *PIMSyncStateRepository) Delete(ctx context.Context, tenantID uuid.UUID, provider string) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM pim_sync_states
+		WHERE tenant_id = $1 AND ($2 = '' OR provider = $2)`,
+		tenantID, provider,
+	)
+	return err
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+type PriceImportRepository struct {
+	db *DB
+}
+
+func NewPriceImportRepository(db *DB) *PriceImportRepository {
+	return &PriceImportRepository{db: db}
+}
+
+func (r *PriceImportRepository) ListERPPrices(ctx context.Context, tenantID uuid.UUID, customerGroupID *uuid.UUID, at time.Time) ([]domain.Price, error) {
+	query := `
+		SELECT id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
+		       valid_from, valid_to, source, created_at, updated_at, deleted_at
+		FROM prices
+		WHERE tenant_id = $1
+		  AND (customer_group_id = $2 OR (customer_group_id IS NULL AND $2 IS NULL))
+		  AND source = 'erp'
+		  AND deleted_at IS NULL
+		  AND (valid_to IS NULL OR valid_to > $3)
+		ORDER BY product_id, min_quantity
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, customerGroupID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []domain.Price
+	for rows.Next() {
+		var price domain.Price
+		if err := rows.Scan(
+			&price.ID,
+			&price.TenantID,
+			&price.ProductID,
+			&price.CustomerGroupID,
+			&price.MinQuantity,
+			&price.Price,
+			&price.Currency,
+			&price.ValidFrom,
+			&price.ValidTo,
+			&price.Source,
+			&price.CreatedAt,
+			&price.UpdatedAt,
+			&price.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
+
+func (r *PriceImportRepository) SavePrices(ctx context.Context, created, updated []domain.Price) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, p := range created {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO prices (
+				id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
+				valid_from, valid_to, source, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			p.ID, p.TenantID, p.ProductID, p.CustomerGroupID, p.MinQuantity, p.Price, p.Currency,
+			p.ValidFrom, p.ValidTo, p.Source, p.CreatedAt, p.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range updated {
+		_, err := tx.Exec(ctx, `
+			UPDATE prices SET
+				price = $1,
+				valid_from = $2,
+				valid_to = $3,
+				updated_at = $4,
+				deleted_at = $5
+			WHERE id = $6 AND source = 'erp'`,
+			p.Price, p.ValidFrom, p.ValidTo, p.UpdatedAt, p.DeletedAt, p.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PriceImportRepository) StartRun(ctx context.Context, run *domain.PriceImportRun, lease time.Duration) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Runs of crashed instances keep their status; fail them once the lease is over
+	_, err = tx.Exec(ctx, `
+		UPDATE price_import_runs SET status = 'failed', error = 'abandoned', completed_at = NOW()
+		WHERE tenant_id = $1 AND status = 'running' AND started_at < $2`,
+		run.TenantID, time.Now().Add(-lease),
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO price_import_runs (id, tenant_id, trigger, dry_run, skus, status, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) WHERE status = 'running' AND NOT dry_run DO NOTHING`,
+		run.ID, run.TenantID, run.Trigger, run.DryRun, run.SKUs, run.Status, run.StartedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrPriceImportRunning
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PriceImportRepository) FinishRun(ctx context.Context, run *domain.PriceImportRun) error {
+	query := `
+		UPDATE price_import_runs SET
+			status = $1,
+			created = $2,
+			updated = $3,
+			expired = $4,
+			unchanged = $5,
+			error = $6,
+			completed_at = $7
+		WHERE id = $8
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		run.Status,
+		run.Created,
+		run.Updated,
+		run.Expired,
+		run.Unchanged,
+		run.Error,
+		run.CompletedAt,
+		run.ID,
+	)
+	return err
+}
+
+func (r *PriceImportRepository) LastRun(ctx context.Context, tenantID uuid.UUID, trigger string) (*domain.PriceImportRun, error) {
+	query := `
+		SELECT id, tenant_id, trigger, dry_run, skus, status, created, updated, expired, unchanged,
+		       error, started_at, completed_at
+		FROM price_import_runs
+		WHERE tenant_id = $1 AND trigger = $2 AND NOT dry_run
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	run, err := r.scanRun(r.db.Pool.QueryRow(ctx, query, tenantID, trigger))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+func (r *PriceImportRepository) ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]domain.PriceImportRun, int, error) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM price_import_runs WHERE tenant_id = $1`, tenantID,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, tenant_id, trigger, dry_run, skus, status, created, updated, expired, unchanged,
+		       error, started_at, completed_at
+		FROM price_import_runs
+		WHERE tenant_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var runs []domain.PriceImportRun
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, total, rows.Err()
+}
+
+func (r *PriceImportRepository) scanRun(row pgx.Row) (*domain.PriceImportRun, error) {
+	var run domain.PriceImportRun
+	err := row.Scan(
+		&run.ID,
+		&run.TenantID,
+		&run.Trigger,
+		&run.DryRun,
+		&run.SKUs,
+		&run.Status,
+		&run.Created,
+		&run.Updated,
+		&run.Expired,
+		&run.Unchanged,
+		&run.Error,
+		&run.StartedAt,
+		&run.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
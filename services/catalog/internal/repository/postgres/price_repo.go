@@ -22,7 +22,7 @@ func NewPriceRepository(db *DB) *PriceRepository {
 func (r *PriceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Price, error) {
 	query := `
 		SELECT id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
-		       valid_from, valid_to, created_at, updated_at, deleted_at
+		       valid_from, valid_to, source, created_at, updated_at, deleted_at
 		FROM prices
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -33,7 +33,7 @@ func (r *PriceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Pr
 func (r *PriceRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]domain.Price, error) {
 	query := `
 		SELECT id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
-		       valid_from, valid_to, created_at, updated_at, deleted_at
+		       valid_from, valid_to, source, created_at, updated_at, deleted_at
 		FROM prices
 		WHERE product_id = $1 AND deleted_at IS NULL
 		ORDER BY customer_group_id NULLS FIRST, min_quantity
@@ -107,7 +107,7 @@ func (r *PriceRepository) List(ctx context.Context, filter domain.PriceFilter) (
 	// Data query
 	query := fmt.Sprintf(`
 		SELECT id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
-		       valid_from, valid_to, created_at, updated_at, deleted_at
+		       valid_from, valid_to, source, created_at, updated_at, deleted_at
 		FROM prices
 		WHERE %s
 		ORDER BY product_id, customer_group_id NULLS FIRST, min_quantity
@@ -138,9 +138,9 @@ func (r *PriceRepository) Create(ctx context.Context, price *domain.Price) error
 	query := `
 		INSERT INTO prices (
 			id, tenant_id, product_id, customer_group_id, min_quantity, price, currency,
-			valid_from, valid_to, created_at, updated_at
+			valid_from, valid_to, source, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		)
 	`
 
@@ -154,6 +154,7 @@ func (r *PriceRepository) Create(ctx context.Context, price *domain.Price) error
 		price.Currency,
 		price.ValidFrom,
 		price.ValidTo,
+		price.Source,
 		price.CreatedAt,
 		price.UpdatedAt,
 	)
@@ -259,6 +260,7 @@ func (r *PriceRepository) scanPrice(row pgx.Row) (*domain.Price, error) {
 		&price.Currency,
 		&price.ValidFrom,
 		&price.ValidTo,
+		&price.Source,
 		&price.CreatedAt,
 		&price.UpdatedAt,
 		&price.DeletedAt,
@@ -287,6 +289,7 @@ func (r *PriceRepository) scanPriceFromRows(rows pgx.Rows) (*domain.Price, error
 		&price.Currency,
 		&price.ValidFrom,
 		&price.ValidTo,
+		&price.Source,
 		&price.CreatedAt,
 		&price.UpdatedAt,
 		&price.DeletedAt,
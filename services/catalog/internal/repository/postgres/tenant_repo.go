@@ -37,6 +37,32 @@ func (r *TenantRepository) GetByCode(ctx context.Context, code string) (*domain.
 	return r.scanTenant(r.db.Pool.QueryRow(ctx, query, code))
 }
 
+func (r *TenantRepository) ListActive(ctx context.Context) ([]domain.Tenant, error) {
+	query := `
+		SELECT id, code, name, config, is_active, created_at, updated_at
+		FROM tenants
+		WHERE is_active = true
+		ORDER BY code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []domain.Tenant
+	for rows.Next() {
+		tenant, err := r.scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, *tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
 func (r *TenantRepository) scanTenant(row pgx.Row) (*domain.Tenant, error) {
 	var tenant domain.Tenant
 	err := row.Scan(
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+	"github.com/gondolia/gondolia/services/catalog/internal/repository"
+)
+
+// maxAssetSize limits the size of a PIM asset copied to object storage
+const maxAssetSize = 64 << 20
+
+// assetPrefix is the storage prefix of the PIM assets of a tenant
+func assetPrefix(tenantID uuid.UUID) string {
+	return "pim/" + tenantID.String() + "/"
+}
+
+// imageSync copies the media of PIM products to object storage and turns
+// them into product images
+type imageSync struct {
+	tenantID  uuid.UUID
+	pim       pim.PIMProvider
+	storage   storage.StorageProvider
+	assetRepo repository.PIMAssetRepository
+	baseURL   string // Image URLs are the base URL followed by the storage path
+	mapper    *attributeMapper
+	now       func() time.Time
+}
+
+// images returns the images of a product: the images of its media
+// attributes followed by the images maintained in the catalog. Assets whose
+// download fails fail the product, so that it is synced again.
+func (s *imageSync) images(ctx context.Context, product pim.Product, existing []domain.ProductImage, result *SyncResult) ([]domain.ProductImage, error) {
+	altText := ""
+	if name, ok := selectValue(product.Values["name"], s.mapper.locales, s.mapper.channel); ok {
+		altText, _ = name.Data.(string)
+	}
+
+	var images []domain.ProductImage
+	seen := make(map[string]bool)
+	for _, attr := range s.mapper.images {
+		value, ok := selectValue(product.Values[attr], s.mapper.locales, s.mapper.channel)
+		code, _ := value.Data.(string)
+		if !ok || code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		asset, err := s.asset(ctx, code, product.Updated, result)
+		if err != nil {
+			result.AssetsFailed++
+			return nil, fmt.Errorf("asset %s: %w", code, err)
+		}
+		if !strings.HasPrefix(asset.ContentType, "image/") {
+			continue
+		}
+		images = append(images, domain.ProductImage{URL: s.url(asset.Path), AltText: altText})
+	}
+
+	// Keep the images maintained in the catalog; one of them may be primary
+	primary := len(images) > 0
+	for _, image := range existing {
+		if strings.HasPrefix(image.URL, s.url(assetPrefix(s.tenantID))) {
+			continue
+		}
+		if image.IsPrimary {
+			primary = false
+		}
+		images = append(images, image)
+	}
+	for i := range images {
+		images[i].SortOrder = i
+		if i == 0 && primary {
+			images[i].IsPrimary = true
+		}
+	}
+	return images, nil
+}
+
+func (s *imageSync) url(storagePath string) string {
+	return strings.TrimSuffix(s.baseURL, "/") + "/" + storagePath
+}
+
+// asset returns the stored asset of a code. The asset is downloaded again
+// only if the product changed after its last download; files are uploaded
+// once per content hash.
+func (s *imageSync) asset(ctx context.Context, code string, updated time.Time, result *SyncResult) (*domain.PIMAsset, error) {
+	now := s.now()
+	asset, err := s.assetRepo.Get(ctx, s.tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	if asset != nil && !asset.SyncedAt.Before(updated) {
+		asset.SeenAt = now
+		result.AssetsUnchanged++
+		return asset, s.assetRepo.Save(ctx, asset)
+	}
+
+	body, contentType, err := s.pim.DownloadAsset(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("larger than %d bytes: %w", maxAssetSize, provider.ErrInvalidArgument)
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(data)
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	storagePath := assetPrefix(s.tenantID) + hash + assetExtension(code, contentType)
+	if asset == nil || asset.Path != storagePath {
+		exists, err := s.storage.Exists(ctx, storagePath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			_, err := s.storage.Upload(ctx, storagePath, bytes.NewReader(data), storage.UploadOptions{
+				ContentType: contentType,
+				Metadata:    map[string]string{"pim-code": code},
+				ACL:         "public-read",
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.AssetsUploaded++
+		}
+	}
+
+	asset = &domain.PIMAsset{
+		TenantID:    s.tenantID,
+		Code:        code,
+		Hash:        hash,
+		Path:        storagePath,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SyncedAt:    now,
+		SeenAt:      now,
+	}
+	return asset, s.assetRepo.Save(ctx, asset)
+}
+
+// assetExtension returns the file extension of an asset: that of its code,
+// or one of its content type
+func assetExtension(code, contentType string) string {
+	if ext := strings.ToLower(path.Ext(code)); ext != "" && len(ext) <= 6 {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// cleanup removes the assets no product used since the start of a full
+// sync, and the stored files no asset refers to
+func (s *imageSync) cleanup(ctx context.Context, runStarted time.Time, result *SyncResult) {
+	assets, err := s.assetRepo.List(ctx, s.tenantID)
+	if err != nil {
+		result.AssetsFailed++
+		return
+	}
+	var stale []string
+	kept := make(map[string]bool)
+	for _, asset := range assets {
+		if asset.SeenAt.Before(runStarted) {
+			stale = append(stale, asset.Code)
+		} else {
+			kept[asset.Path] = true
+		}
+	}
+	if err := s.assetRepo.Delete(ctx, s.tenantID, stale); err != nil {
+		result.AssetsFailed++
+		return
+	}
+
+	opts := storage.ListOptions{MaxKeys: 1000}
+	for {
+		files, err := s.storage.List(ctx, assetPrefix(s.tenantID), opts)
+		if err != nil {
+			result.AssetsFailed++
+			return
+		}
+		if len(files) == 0 {
+			return
+		}
+		for _, file := range files {
+			if kept[file.Path] {
+				continue
+			}
+			if err := s.storage.Delete(ctx, file.Path); err != nil {
+				result.AssetsFailed++
+				continue
+			}
+			result.AssetsDeleted++
+		}
+		opts.Cursor = files[len(files)-1].Path
+	}
+}
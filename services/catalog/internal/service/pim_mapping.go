@@ -0,0 +1,349 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+// pimMapping reads the attribute mapping from the "pim" section of the tenant config
+func pimMapping(tenant *domain.Tenant) (*domain.PIMMapping, error) {
+	mapping := &domain.PIMMapping{}
+	section, ok := tenant.Config["pim"]
+	if !ok {
+		return mapping, nil
+	}
+	raw, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, mapping); err != nil {
+		return nil, fmt.Errorf("invalid PIM mapping: %w", err)
+	}
+	for _, attr := range mapping.Attributes {
+		if attr.Code == "" {
+			return nil, fmt.Errorf("invalid PIM mapping: attribute without code")
+		}
+		switch attr.Type {
+		case "", domain.AttributeTypeText, domain.AttributeTypeNumber, domain.AttributeTypeBoolean, domain.AttributeTypeDate:
+		default:
+			return nil, fmt.Errorf("invalid PIM mapping: attribute %s has unknown type %q", attr.Code, attr.Type)
+		}
+	}
+	return mapping, nil
+}
+
+// pimAttributeTypes maps PIM attribute types to product attribute types.
+// Types mapped to "" are not synced as product attributes.
+var pimAttributeTypes = map[string]domain.AttributeType{
+	"identifier":       "",
+	"media":            "",
+	"asset_collection": "",
+	"number":           domain.AttributeTypeNumber,
+	"metric":           domain.AttributeTypeNumber,
+	"price":            domain.AttributeTypeNumber,
+	"boolean":          domain.AttributeTypeBoolean,
+	"date":             domain.AttributeTypeDate,
+}
+
+// attributeTarget is a PIM attribute mapped to a product attribute
+type attributeTarget struct {
+	code    string
+	key     string
+	typ     domain.AttributeType
+	locales []string
+	scope   string
+	labels  map[string]string // PIM locale -> label
+}
+
+// attributeMapper converts PIM values to product attributes
+type attributeMapper struct {
+	targets  []attributeTarget // Sorted by key
+	keys     map[string]bool
+	locales  []string
+	channel  string
+	currency string
+	images   []string // Media attributes of the product images
+	variants bool     // Sync product models as variant parents
+}
+
+// newAttributeMapper maps the PIM attributes as configured by the mapping
+func newAttributeMapper(mapping *domain.PIMMapping, attributes []pim.Attribute) *attributeMapper {
+	definitions := make(map[string]pim.Attribute, len(attributes))
+	for _, attr := range attributes {
+		definitions[attr.Code] = attr
+	}
+
+	m := &attributeMapper{
+		keys:     make(map[string]bool),
+		locales:  mapping.Locales,
+		channel:  mapping.Channel,
+		currency: mapping.Currency,
+		images:   mapping.Images,
+		variants: mapping.Variants,
+	}
+	if len(m.images) == 0 {
+		for _, attr := range attributes {
+			if attr.Type == "media" {
+				m.images = append(m.images, attr.Code)
+			}
+		}
+	}
+	add := func(target attributeTarget) {
+		if m.keys[target.key] {
+			return
+		}
+		m.keys[target.key] = true
+		m.targets = append(m.targets, target)
+	}
+
+	if len(mapping.Attributes) == 0 {
+		for _, attr := range attributes {
+			typ, ok := pimAttributeTypes[attr.Type]
+			if !ok {
+				typ = domain.AttributeTypeText
+			}
+			if typ == "" || attr.Code == "name" || attr.Code == "description" {
+				continue
+			}
+			add(attributeTarget{code: attr.Code, key: attr.Code, typ: typ, locales: mapping.Locales, scope: mapping.Channel, labels: attr.Labels})
+		}
+	}
+	for _, am := range mapping.Attributes {
+		def := definitions[am.Code]
+		target := attributeTarget{code: am.Code, key: am.Key, typ: am.Type, locales: am.Locales, scope: am.Scope, labels: def.Labels}
+		if target.key == "" {
+			target.key = am.Code
+		}
+		if target.typ == "" {
+			target.typ = pimAttributeTypes[def.Type]
+		}
+		if target.typ == "" {
+			target.typ = domain.AttributeTypeText
+		}
+		if len(target.locales) == 0 {
+			target.locales = mapping.Locales
+		}
+		if target.scope == "" {
+			target.scope = mapping.Channel
+		}
+		add(target)
+	}
+
+	sort.Slice(m.targets, func(i, j int) bool { return m.targets[i].key < m.targets[j].key })
+	return m
+}
+
+// attributes converts the values of a PIM product. It returns the number of
+// values that could not be converted to the type of their attribute.
+func (m *attributeMapper) attributes(values map[string][]pim.AttributeValue) ([]domain.ProductAttribute, int) {
+	var attrs []domain.ProductAttribute
+	invalid := 0
+	for _, target := range m.targets {
+		value, ok := selectValue(values[target.code], target.locales, target.scope)
+		if !ok || isEmptyValue(value.Data) {
+			continue
+		}
+		converted, ok := convertValue(value.Data, target.typ, m.currency)
+		if !ok {
+			invalid++
+			continue
+		}
+		attrs = append(attrs, domain.ProductAttribute{Key: target.key, Type: target.typ, Value: converted})
+	}
+	return attrs, invalid
+}
+
+// merge replaces the mapped attributes of a product, keeping the attributes
+// maintained in the catalog
+func (m *attributeMapper) merge(existing, mapped []domain.ProductAttribute) []domain.ProductAttribute {
+	merged := make([]domain.ProductAttribute, 0, len(existing)+len(mapped))
+	for _, attr := range existing {
+		if !m.keys[attr.Key] {
+			merged = append(merged, attr)
+		}
+	}
+	return append(merged, mapped...)
+}
+
+// selectValue picks the value of the preferred locale and scope. Values of
+// other scopes are ignored; localized values are only used in one of the
+// locales if locales are given. Otherwise unlocalized and unscoped values win,
+// and ties are broken by locale and scope for a stable result.
+func selectValue(values []pim.AttributeValue, locales []string, scope string) (pim.AttributeValue, bool) {
+	var best pim.AttributeValue
+	bestRank := -1
+	for _, v := range values {
+		if scope != "" && v.Scope != "" && v.Scope != scope {
+			continue
+		}
+		rank := 0
+		if v.Locale != "" {
+			i := slices.Index(locales, v.Locale)
+			if i < 0 && len(locales) > 0 {
+				continue
+			}
+			rank = (i + 2) * 2 // A preferred locale before any locale; both after unlocalized
+		}
+		if v.Scope == "" && scope != "" || v.Scope != "" && scope == "" {
+			rank++ // Exact scope before unscoped; unscoped before any scope
+		}
+		if bestRank < 0 || rank < bestRank ||
+			rank == bestRank && (v.Locale < best.Locale || v.Locale == best.Locale && v.Scope < best.Scope) {
+			best, bestRank = v, rank
+		}
+	}
+	return best, bestRank >= 0
+}
+
+func isEmptyValue(data any) bool {
+	switch v := data.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// convertValue converts PIM data to the value of a product attribute of the type
+func convertValue(data any, typ domain.AttributeType, currency string) (any, bool) {
+	switch typ {
+	case domain.AttributeTypeNumber:
+		return numberValue(data, currency)
+	case domain.AttributeTypeBoolean:
+		switch v := data.(type) {
+		case bool:
+			return v, true
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			return b, err == nil
+		}
+		return nil, false
+	case domain.AttributeTypeDate:
+		switch v := data.(type) {
+		case time.Time:
+			return v.Format(time.DateOnly), true
+		case string:
+			v = strings.TrimSpace(v)
+			for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t.Format(time.DateOnly), true
+				}
+			}
+		}
+		return nil, false
+	default:
+		return textValue(data)
+	}
+}
+
+// numberValue reads numbers, numeric strings, metrics and price collections
+func numberValue(data any, currency string) (any, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		return numberValue(v.String(), currency)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, false
+		}
+		return numberValue(f, currency)
+	case map[string]any:
+		// Metric: {"amount": "1.5", "unit": "KILOGRAM"}
+		return numberValue(v["amount"], currency)
+	case []any:
+		// Price collection: [{"amount": "9.90", "currency": "CHF"}, ...]
+		for _, entry := range v {
+			price, _ := entry.(map[string]any)
+			if currency == "" || price["currency"] == currency {
+				return numberValue(price["amount"], currency)
+			}
+		}
+	}
+	return nil, false
+}
+
+// textValue formats scalars, metrics and option lists as text
+func textValue(data any) (any, bool) {
+	switch v := data.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int, int64, json.Number:
+		return fmt.Sprint(v), true
+	case map[string]any:
+		amount, ok := textValue(v["amount"])
+		if !ok {
+			return nil, false
+		}
+		if unit, _ := v["unit"].(string); unit != "" {
+			return fmt.Sprintf("%s %s", amount, unit), true
+		}
+		return amount, true
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			text, ok := textValue(item)
+			if !ok {
+				return nil, false
+			}
+			parts = append(parts, text.(string))
+		}
+		return strings.Join(parts, ", "), true
+	}
+	return nil, false
+}
+
+// translationLanguages picks one label per catalog language from the PIM
+// labels: "de_CH" and "de-DE" translate to "de". If several locales share a
+// language, the preferred locale wins, otherwise the first in order.
+func translationLanguages(labels map[string]string, preferred []string) map[string]string {
+	locales := make([]string, 0, len(labels))
+	for locale := range labels {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(i, j int) bool {
+		pi, pj := slices.Index(preferred, locales[i]), slices.Index(preferred, locales[j])
+		if pi != pj && (pi < 0 || pj < 0) {
+			return pi >= 0
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return locales[i] < locales[j]
+	})
+
+	translations := make(map[string]string)
+	for _, locale := range locales {
+		language, _, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+		language = strings.ToLower(language)
+		label := strings.TrimSpace(labels[locale])
+		if len(language) != 2 || label == "" {
+			continue
+		}
+		if _, ok := translations[language]; !ok {
+			translations[language] = label
+		}
+	}
+	return translations
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+// maxModelDepth bounds the chain of product models above a product
+const maxModelDepth = 4
+
+// variantSync turns PIM product models into variant parents and the
+// products of a model into its variants
+type variantSync struct {
+	pim      pim.PIMProvider
+	families map[string]pim.FamilyVariant // By code
+	models   map[string]*pim.ProductModel // Models read during the run, by code
+	parents  map[string]*variantParent    // Variant parents by root model code
+}
+
+// variantParent is the variant parent of a root product model
+type variantParent struct {
+	product *domain.Product
+	axes    []domain.VariantAxis
+}
+
+// variantLink places a synced product in the variant hierarchy
+type variantLink struct {
+	productType domain.ProductType
+	parent      *variantParent    // Parent of a variant
+	values      map[string]string // Axis values of a variant
+}
+
+// variantSync reads the family variants; nil unless the tenant syncs variants
+func (s *SyncService) variantSync(ctx context.Context, pimProvider pim.PIMProvider, mapper *attributeMapper) (*variantSync, error) {
+	if !mapper.variants {
+		return nil, nil
+	}
+	families, err := pimProvider.FetchFamilyVariants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v := &variantSync{
+		pim:      pimProvider,
+		families: make(map[string]pim.FamilyVariant, len(families)),
+		models:   make(map[string]*pim.ProductModel),
+		parents:  make(map[string]*variantParent),
+	}
+	for _, family := range families {
+		v.families[family.Code] = family
+	}
+	return v, nil
+}
+
+// model returns a product model, reading it from PIM unless the run did
+func (v *variantSync) model(ctx context.Context, code string) (*pim.ProductModel, error) {
+	if model, ok := v.models[code]; ok {
+		return model, nil
+	}
+	model, err := v.pim.FetchProductModel(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("product model %s: %w", code, err)
+	}
+	v.models[code] = model
+	return model, nil
+}
+
+// root returns the root model of a product model
+func (v *variantSync) root(ctx context.Context, code string) (*pim.ProductModel, error) {
+	model, err := v.model(ctx, code)
+	for depth := 0; err == nil && model.Parent != ""; depth++ {
+		if depth == maxModelDepth {
+			return nil, fmt.Errorf("product model %s is nested too deeply", code)
+		}
+		model, err = v.model(ctx, model.Parent)
+	}
+	return model, err
+}
+
+// axes returns the axis attributes of a root model: the axes of all levels
+// of its family variant, from the first level down
+func (v *variantSync) axes(model *pim.ProductModel) ([]string, error) {
+	family, ok := v.families[model.FamilyVariant]
+	if !ok {
+		return nil, fmt.Errorf("product model %s: unknown family variant %q", model.Code, model.FamilyVariant)
+	}
+	var axes []string
+	for _, level := range family.Levels {
+		for _, axis := range level.Axes {
+			if !slices.Contains(axes, axis) {
+				axes = append(axes, axis)
+			}
+		}
+	}
+	if len(axes) == 0 {
+		return nil, fmt.Errorf("family variant %s has no axes", family.Code)
+	}
+	if len(axes) > domain.MaxVariantAxes {
+		return nil, fmt.Errorf("family variant %s: %w", family.Code, domain.ErrTooManyAxes)
+	}
+	return axes, nil
+}
+
+// axisValues reads the option codes of the axes from the values of a variant,
+// which include the values of its product models
+func (v *variantSync) axisValues(axes []domain.VariantAxis, values map[string][]pim.AttributeValue, mapper *attributeMapper) map[string]string {
+	result := make(map[string]string, len(axes))
+	for _, axis := range axes {
+		value, ok := selectValue(values[axis.AttributeCode], mapper.locales, mapper.channel)
+		if !ok || isEmptyValue(value.Data) {
+			continue
+		}
+		if text, ok := textValue(value.Data); ok {
+			result[axis.AttributeCode] = text.(string)
+		}
+	}
+	return result
+}
+
+// syncModels syncs the root product models of the run as variant parents.
+// Sub-models are kept for resolving the root models of their products.
+func (s *SyncService) syncModels(ctx context.Context, tenantID uuid.UUID, pimProvider pim.PIMProvider, mapping *productMapping, state *domain.PIMSyncState, result *SyncResult) error {
+	filter := pim.ProductFilter{
+		Limit:        100,
+		UpdatedSince: state.RunSince,
+	}
+
+	for {
+		page, err := pimProvider.FetchProductModels(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range page.Models {
+			model := &page.Models[i]
+			mapping.variants.models[model.Code] = model
+		}
+		for i := range page.Models {
+			if page.Models[i].Parent != "" {
+				continue
+			}
+			if _, err := s.syncModel(ctx, tenantID, &page.Models[i], mapping, result); err != nil {
+				result.ModelsFailed++
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// syncModel syncs a root product model as a variant parent. The axes are
+// only replaced when they change, since that removes the axis values of the
+// variants.
+func (s *SyncService) syncModel(ctx context.Context, tenantID uuid.UUID, model *pim.ProductModel, mapping *productMapping, result *SyncResult) (*variantParent, error) {
+	codes, err := mapping.variants.axes(model)
+	if err != nil {
+		return nil, err
+	}
+
+	pimProduct := pim.Product{
+		Identifier: model.Code,
+		Family:     model.Family,
+		Categories: model.Categories,
+		Enabled:    true,
+		Values:     model.Values,
+		Created:    model.Created,
+		Updated:    model.Updated,
+	}
+	product, created, err := s.saveProduct(ctx, tenantID, pimProduct, mapping, &variantLink{productType: domain.ProductTypeVariantParent}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	axes, err := s.productRepo.GetVariantAxes(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]string, len(axes))
+	for i, axis := range axes {
+		current[i] = axis.AttributeCode
+	}
+	if !slices.Equal(current, codes) {
+		axes = make([]domain.VariantAxis, len(codes))
+		for i, code := range codes {
+			axes[i] = domain.VariantAxis{
+				ID:            uuid.New(),
+				ProductID:     product.ID,
+				AttributeCode: code,
+				Position:      i,
+				InputType:     "select",
+			}
+		}
+		if err := s.productRepo.SetVariantAxes(ctx, product.ID, axes); err != nil {
+			return nil, fmt.Errorf("failed to set variant axes: %w", err)
+		}
+	}
+
+	if created {
+		result.ModelsCreated++
+	} else {
+		result.ModelsUpdated++
+	}
+	parent := &variantParent{product: product, axes: axes}
+	mapping.variants.parents[model.Code] = parent
+	return parent, nil
+}
+
+// variantLink finds the variant parent of a PIM product and its axis
+// values. Products without a model are simple products.
+func (s *SyncService) variantLink(ctx context.Context, tenantID uuid.UUID, pimProduct pim.Product, mapping *productMapping, result *SyncResult) (*variantLink, error) {
+	if pimProduct.Parent == "" {
+		return &variantLink{productType: domain.ProductTypeSimple}, nil
+	}
+	root, err := mapping.variants.root(ctx, pimProduct.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, ok := mapping.variants.parents[root.Code]
+	if !ok {
+		product, err := s.productRepo.GetBySKU(ctx, tenantID, root.Code)
+		switch {
+		case err == domain.ErrProductNotFound:
+			// The model was not synced yet, e.g. when the run was resumed
+			if parent, err = s.syncModel(ctx, tenantID, root, mapping, result); err != nil {
+				result.ModelsFailed++
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		default:
+			axes, err := s.productRepo.GetVariantAxes(ctx, product.ID)
+			if err != nil {
+				return nil, err
+			}
+			parent = &variantParent{product: product, axes: axes}
+			mapping.variants.parents[root.Code] = parent
+		}
+	}
+	if parent.product.ProductType != domain.ProductTypeVariantParent {
+		return nil, fmt.Errorf("parent product %s is not a variant parent", parent.product.SKU)
+	}
+
+	values := mapping.variants.axisValues(parent.axes, pimProduct.Values, mapping.attributes)
+	if err := validateAxisValues(parent.axes, values); err != nil {
+		return nil, fmt.Errorf("product %s: %w", pimProduct.Identifier, err)
+	}
+	return &variantLink{productType: domain.ProductTypeVariant, parent: parent, values: values}, nil
+}
+
+// place puts a product into the variant hierarchy before it is saved. A
+// variant inherits the name, description and categories its PIM product
+// lacks from its parent, and must not repeat the axis values of another
+// variant.
+func (s *SyncService) place(ctx context.Context, product *domain.Product, link *variantLink) error {
+	if link.productType == domain.ProductTypeSimple && product.ProductType != domain.ProductTypeVariant {
+		return nil // Keep the type of products maintained in the catalog
+	}
+	product.ProductType = link.productType
+	product.ParentID = nil
+	if link.parent == nil {
+		return nil
+	}
+
+	parent := link.parent.product
+	product.ParentID = &parent.ID
+	product.Name = inheritName(product.Name, parent.Name)
+	product.Description = inheritMap(product.Description, parent.Description)
+	if len(product.CategoryIDs) == 0 {
+		product.CategoryIDs = parent.CategoryIDs
+	}
+
+	existing, err := s.productRepo.FindVariantByAxisValues(ctx, parent.ID, link.values)
+	if err == nil && existing != nil && existing.ID != product.ID {
+		return fmt.Errorf("%w: %s", domain.ErrDuplicateVariantCombination, existing.SKU)
+	}
+	return nil
+}
+
+// setAxisValues saves the axis values of a variant, and removes those of a
+// former variant
+func (s *SyncService) setAxisValues(ctx context.Context, product *domain.Product, link *variantLink, wasVariant bool) error {
+	if link.parent == nil {
+		if wasVariant {
+			return s.productRepo.SetAxisValues(ctx, product.ID, nil)
+		}
+		return nil
+	}
+
+	entries := make([]domain.AxisValueEntry, 0, len(link.values))
+	for _, axis := range link.parent.axes {
+		entries = append(entries, domain.AxisValueEntry{
+			VariantID:         product.ID,
+			AxisID:            axis.ID,
+			AxisAttributeCode: axis.AttributeCode,
+			OptionCode:        link.values[axis.AttributeCode],
+		})
+	}
+	if err := s.productRepo.SetAxisValues(ctx, product.ID, entries); err != nil {
+		return fmt.Errorf("failed to set axis values: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+	"github.com/gondolia/gondolia/services/catalog/internal/repository"
+)
+
+// PriceImportConfig configures the import of tier and contract prices from the ERP
+type PriceImportConfig struct {
+	Interval  time.Duration // How often tenants due for an import are checked; 0 disables the scheduled import
+	Cadence   time.Duration // How often the prices of each tenant are imported
+	BatchSize int           // SKUs per ERP request
+	Lease     time.Duration // How long a running import blocks other imports of the tenant
+}
+
+// PriceImporter imports the prices of the tenant's ERP price lists into
+// catalog prices. Prices the ERP no longer returns are expired; prices
+// maintained in Gondolia are left alone.
+type PriceImporter struct {
+	productRepo  repository.ProductRepository
+	importRepo   repository.PriceImportRepository
+	tenantRepo   repository.TenantRepository
+	erpProviders provider.Source[erp.ERPProvider]
+	cfg          PriceImportConfig
+	now          func() time.Time
+}
+
+// NewPriceImporter creates a new price importer
+func NewPriceImporter(
+	productRepo repository.ProductRepository,
+	importRepo repository.PriceImportRepository,
+	tenantRepo repository.TenantRepository,
+	erpProviders provider.Source[erp.ERPProvider],
+	cfg PriceImportConfig,
+) *PriceImporter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &PriceImporter{
+		productRepo:  productRepo,
+		importRepo:   importRepo,
+		tenantRepo:   tenantRepo,
+		erpProviders: erpProviders,
+		cfg:          cfg,
+		now:          time.Now,
+	}
+}
+
+// Run imports the prices of due tenants every interval until ctx ends
+func (s *PriceImporter) Run(ctx context.Context, onError func(error)) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ImportDue(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ImportDue imports the prices of all products for the tenants with ERP
+// price lists whose last scheduled import is older than the cadence, and
+// returns the number of tenants imported
+func (s *PriceImporter) ImportDue(ctx context.Context) (int, error) {
+	tenants, err := s.tenantRepo.ListActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenants for price import: %w", err)
+	}
+
+	var imported int
+	var errs []error
+	for i := range tenants {
+		tenant := &tenants[i]
+		lists, err := erpPriceLists(tenant)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.Code, err))
+			continue
+		}
+		if len(lists) == 0 {
+			continue
+		}
+
+		last, err := s.importRepo.LastRun(ctx, tenant.ID, domain.PriceImportScheduled)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.Code, err))
+			continue
+		}
+		if last != nil && s.now().Sub(last.StartedAt) < s.cfg.Cadence {
+			continue
+		}
+
+		_, err = s.importPrices(ctx, tenant, domain.PriceImportScheduled, nil, false)
+		if errors.Is(err, domain.ErrPriceImportRunning) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.Code, err))
+			continue
+		}
+		imported++
+	}
+
+	return imported, errors.Join(errs...)
+}
+
+// Import imports the prices of the given SKUs, or of all products if there
+// are none, right away. A dry run returns the changes without applying them.
+func (s *PriceImporter) Import(ctx context.Context, tenantID uuid.UUID, req domain.PriceImportRequest) (*domain.PriceImportResult, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var skus []string
+	seen := make(map[string]bool)
+	for _, sku := range req.SKUs {
+		sku = strings.TrimSpace(sku)
+		if sku != "" && !seen[sku] {
+			seen[sku] = true
+			skus = append(skus, sku)
+		}
+	}
+
+	return s.importPrices(ctx, tenant, domain.PriceImportManual, skus, req.DryRun)
+}
+
+// ListRuns returns the tenant's price imports, newest first
+func (s *PriceImporter) ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]domain.PriceImportRun, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return s.importRepo.ListRuns(ctx, tenantID, limit, offset)
+}
+
+// importPrices runs an import and records it with its counts
+func (s *PriceImporter) importPrices(ctx context.Context, tenant *domain.Tenant, trigger string, skus []string, dryRun bool) (*domain.PriceImportResult, error) {
+	lists, err := erpPriceLists(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, domain.ErrNoERPPriceLists
+	}
+
+	run := domain.NewPriceImportRun(tenant.ID, trigger, skus, dryRun)
+	run.StartedAt = s.now()
+	if err := s.importRepo.StartRun(ctx, run, s.cfg.Lease); err != nil {
+		return nil, err
+	}
+
+	result := &domain.PriceImportResult{Run: run, Changes: []domain.PriceChange{}}
+	err = s.importLists(ctx, tenant.ID, lists, skus, result)
+
+	completedAt := s.now()
+	run.CompletedAt = &completedAt
+	run.Status = domain.PriceImportCompleted
+	if err != nil {
+		message := err.Error()
+		run.Status = domain.PriceImportFailed
+		run.Error = &message
+	}
+	if finishErr := s.importRepo.FinishRun(ctx, run); finishErr != nil && err == nil {
+		err = fmt.Errorf("failed to record price import: %w", finishErr)
+	}
+
+	return result, err
+}
+
+// importLists imports the price lists one by one; each is applied on its own,
+// so a failing list leaves the lists imported before it in place
+func (s *PriceImporter) importLists(ctx context.Context, tenantID uuid.UUID, lists []domain.ERPPriceList, skus []string, result *domain.PriceImportResult) error {
+	products, err := s.products(ctx, tenantID, skus, result)
+	if err != nil {
+		return err
+	}
+
+	ctx = provider.WithTenant(ctx, tenantID.String())
+	erpProvider, err := s.erpProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPriceImportFailed, err)
+	}
+
+	for _, list := range lists {
+		tiers, err := s.fetchTiers(ctx, erpProvider, list, products)
+		if err != nil {
+			return fmt.Errorf("%w: price list %s: %v", domain.ErrPriceImportFailed, list.ERPCustomerID, err)
+		}
+
+		now := s.now()
+		existing, err := s.importRepo.ListERPPrices(ctx, tenantID, list.CustomerGroupID, now)
+		if err != nil {
+			return err
+		}
+
+		// Expire only the prices of the imported products, unless all products are imported
+		created, updated, changes, unchanged := diffPrices(tenantID, list, products, tiers, existing, skus == nil, now)
+		for _, c := range changes {
+			switch c.Action {
+			case domain.PriceChangeCreate:
+				result.Run.Created++
+			case domain.PriceChangeUpdate:
+				result.Run.Updated++
+			case domain.PriceChangeExpire:
+				result.Run.Expired++
+			}
+		}
+		result.Run.Unchanged += unchanged
+		result.Changes = append(result.Changes, changes...)
+
+		if result.Run.DryRun || len(changes) == 0 {
+			continue
+		}
+		if err := s.importRepo.SavePrices(ctx, created, updated); err != nil {
+			return fmt.Errorf("failed to save prices of price list %s: %w", list.ERPCustomerID, err)
+		}
+	}
+
+	return nil
+}
+
+// products returns the products to import by SKU; the requested SKUs
+// without a product are reported in the result
+func (s *PriceImporter) products(ctx context.Context, tenantID uuid.UUID, skus []string, result *domain.PriceImportResult) (map[string]*domain.Product, error) {
+	products := make(map[string]*domain.Product)
+
+	if skus != nil {
+		for _, sku := range skus {
+			product, err := s.productRepo.GetBySKU(ctx, tenantID, sku)
+			if errors.Is(err, domain.ErrProductNotFound) {
+				result.UnknownSKUs = append(result.UnknownSKUs, sku)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			products[sku] = product
+		}
+		return products, nil
+	}
+
+	filter := domain.ProductFilter{TenantID: tenantID, Limit: 500}
+	for {
+		page, total, err := s.productRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			products[page[i].SKU] = &page[i]
+		}
+		filter.Offset += len(page)
+		if len(page) == 0 || filter.Offset >= total {
+			return products, nil
+		}
+	}
+}
+
+// fetchTiers requests the tier prices of the products in batches
+func (s *PriceImporter) fetchTiers(ctx context.Context, erpProvider erp.ERPProvider, list domain.ERPPriceList, products map[string]*domain.Product) ([]erp.TierPrice, error) {
+	skus := make([]string, 0, len(products))
+	for sku := range products {
+		skus = append(skus, sku)
+	}
+	slices.Sort(skus)
+
+	var tiers []erp.TierPrice
+	for start := 0; start < len(skus); start += s.cfg.BatchSize {
+		batch := skus[start:min(start+s.cfg.BatchSize, len(skus))]
+		page, err := erpProvider.GetTierPrices(ctx, erp.TierPriceRequest{
+			CustomerID: list.ERPCustomerID,
+			SKUs:       batch,
+			Currency:   list.Currency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, page...)
+	}
+	return tiers, nil
+}
+
+// diffPrices compares the ERP tiers with the imported prices of a price
+// list. Prices are matched by product, minimum quantity, currency and start
+// of validity. Prices of the products the ERP no longer returns expire, and
+// with expireAll also those of products no longer in the catalog.
+func diffPrices(
+	tenantID uuid.UUID,
+	list domain.ERPPriceList,
+	products map[string]*domain.Product,
+	tiers []erp.TierPrice,
+	existing []domain.Price,
+	expireAll bool,
+	now time.Time,
+) (created, updated []domain.Price, changes []domain.PriceChange, unchanged int) {
+	skus := make(map[uuid.UUID]string, len(products))
+	for sku, product := range products {
+		skus[product.ID] = sku
+	}
+
+	byKey := make(map[string]*domain.Price, len(existing))
+	for i := range existing {
+		p := &existing[i]
+		byKey[priceKey(p.ProductID, p.MinQuantity, p.Currency, p.ValidFrom)] = p
+	}
+
+	seen := make(map[string]bool)
+	for _, tier := range tiers {
+		product, ok := products[tier.SKU]
+		if !ok {
+			continue
+		}
+		currency := strings.ToUpper(tier.Currency)
+		if currency == "" {
+			currency = strings.ToUpper(list.Currency)
+		}
+		if currency == "" {
+			continue
+		}
+		minQuantity := max(1, int(math.Ceil(tier.MinQty)))
+		validFrom, validTo := optionalTime(tier.ValidFrom), optionalTime(tier.ValidTo)
+
+		key := priceKey(product.ID, minQuantity, currency, validFrom)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		change := domain.PriceChange{
+			ProductID:       product.ID,
+			SKU:             tier.SKU,
+			CustomerGroupID: list.CustomerGroupID,
+			MinQuantity:     minQuantity,
+			Currency:        currency,
+			NewPrice:        &tier.Price,
+			ValidFrom:       validFrom,
+			ValidTo:         validTo,
+		}
+
+		if price, ok := byKey[key]; ok {
+			if price.Price == tier.Price && sameTime(price.ValidTo, validTo) {
+				unchanged++
+				continue
+			}
+			oldPrice := price.Price
+			price.Price = tier.Price
+			price.ValidTo = validTo
+			price.UpdatedAt = now
+			updated = append(updated, *price)
+
+			change.Action = domain.PriceChangeUpdate
+			change.PriceID = price.ID
+			change.OldPrice = &oldPrice
+			changes = append(changes, change)
+			continue
+		}
+
+		price := domain.NewPrice(tenantID, product.ID, tier.Price, currency)
+		price.CustomerGroupID = list.CustomerGroupID
+		price.MinQuantity = minQuantity
+		price.ValidFrom = validFrom
+		price.ValidTo = validTo
+		price.Source = domain.PriceSourceERP
+		price.CreatedAt = now
+		price.UpdatedAt = now
+		created = append(created, *price)
+
+		change.Action = domain.PriceChangeCreate
+		change.PriceID = price.ID
+		changes = append(changes, change)
+	}
+
+	for i := range existing {
+		price := &existing[i]
+		if seen[priceKey(price.ProductID, price.MinQuantity, price.Currency, price.ValidFrom)] {
+			continue
+		}
+		sku, ok := skus[price.ProductID]
+		if !ok && !expireAll {
+			continue
+		}
+
+		oldPrice := price.Price
+		if price.ValidFrom != nil && price.ValidFrom.After(now) {
+			// Not valid yet, so there is nothing to end
+			price.DeletedAt = &now
+		} else {
+			price.ValidTo = &now
+		}
+		price.UpdatedAt = now
+		updated = append(updated, *price)
+
+		changes = append(changes, domain.PriceChange{
+			Action:          domain.PriceChangeExpire,
+			PriceID:         price.ID,
+			ProductID:       price.ProductID,
+			SKU:             sku,
+			CustomerGroupID: price.CustomerGroupID,
+			MinQuantity:     price.MinQuantity,
+			Currency:        price.Currency,
+			OldPrice:        &oldPrice,
+			ValidFrom:       price.ValidFrom,
+			ValidTo:         price.ValidTo,
+		})
+	}
+
+	return created, updated, changes, unchanged
+}
+
+func priceKey(productID uuid.UUID, minQuantity int, currency string, validFrom *time.Time) string {
+	from := ""
+	if validFrom != nil {
+		from = validFrom.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s/%d/%s/%s", productID, minQuantity, currency, from)
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// erpPriceLists returns the tenant's ERP price lists from
+// Config["erp"]["price_lists"], e.g.
+// [{"customer_id": "PL-GOLD", "customer_group_id": "...", "currency": "CHF"}]
+func erpPriceLists(tenant *domain.Tenant) ([]domain.ERPPriceList, error) {
+	erpConfig, _ := tenant.Config["erp"].(map[string]any)
+	values, _ := erpConfig["price_lists"].([]any)
+
+	lists := make([]domain.ERPPriceList, 0, len(values))
+	for _, v := range values {
+		entry, _ := v.(map[string]any)
+		customerID, _ := entry["customer_id"].(string)
+		if customerID == "" {
+			continue
+		}
+		list := domain.ERPPriceList{ERPCustomerID: customerID}
+		list.Currency, _ = entry["currency"].(string)
+		if group, _ := entry["customer_group_id"].(string); group != "" {
+			id, err := uuid.Parse(group)
+			if err != nil {
+				return nil, fmt.Errorf("invalid customer group of ERP price list %s: %w", customerID, err)
+			}
+			list.CustomerGroupID = &id
+		}
+		// The prices of a customer group are diffed against one list, so a
+		// second list of the group would expire the prices of the first
+		for _, other := range lists {
+			if sameGroup(other.CustomerGroupID, list.CustomerGroupID) {
+				return nil, fmt.Errorf("ERP price lists %s and %s map to the same customer group", other.ERPCustomerID, customerID)
+			}
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+// sameGroup checks if two customer groups are the same; nil is the base prices
+func sameGroup(a, b *uuid.UUID) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
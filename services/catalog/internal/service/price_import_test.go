@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+)
+
+// fakeTierERP serves the tier prices of each ERP customer
+type fakeTierERP struct {
+	erp.ERPProvider
+	tiers    map[string][]erp.TierPrice
+	requests []erp.TierPriceRequest
+	err      error
+}
+
+func (f *fakeTierERP) GetTierPrices(ctx context.Context, req erp.TierPriceRequest) ([]erp.TierPrice, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var tiers []erp.TierPrice
+	for _, t := range f.tiers[req.CustomerID] {
+		for _, sku := range req.SKUs {
+			if t.SKU == sku {
+				tiers = append(tiers, t)
+			}
+		}
+	}
+	return tiers, nil
+}
+
+type fakeTenantRepo struct {
+	tenant *domain.Tenant
+}
+
+func (r *fakeTenantRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	if id != r.tenant.ID {
+		return nil, domain.ErrTenantNotFound
+	}
+	return r.tenant, nil
+}
+
+func (r *fakeTenantRepo) GetByCode(ctx context.Context, code string) (*domain.Tenant, error) {
+	return r.tenant, nil
+}
+
+func (r *fakeTenantRepo) ListActive(ctx context.Context) ([]domain.Tenant, error) {
+	return []domain.Tenant{*r.tenant}, nil
+}
+
+// fakePriceImportRepo keeps prices and runs in memory
+type fakePriceImportRepo struct {
+	prices map[uuid.UUID]domain.Price
+	runs   []*domain.PriceImportRun
+}
+
+func (r *fakePriceImportRepo) ListERPPrices(ctx context.Context, tenantID uuid.UUID, customerGroupID *uuid.UUID, at time.Time) ([]domain.Price, error) {
+	var prices []domain.Price
+	for _, p := range r.prices {
+		sameGroup := (p.CustomerGroupID == nil && customerGroupID == nil) ||
+			(p.CustomerGroupID != nil && customerGroupID != nil && *p.CustomerGroupID == *customerGroupID)
+		if sameGroup && p.Source == domain.PriceSourceERP && p.DeletedAt == nil && (p.ValidTo == nil || p.ValidTo.After(at)) {
+			prices = append(prices, p)
+		}
+	}
+	return prices, nil
+}
+
+func (r *fakePriceImportRepo) SavePrices(ctx context.Context, created, updated []domain.Price) error {
+	for _, p := range append(created, updated...) {
+		r.prices[p.ID] = p
+	}
+	return nil
+}
+
+func (r *fakePriceImportRepo) StartRun(ctx context.Context, run *domain.PriceImportRun, lease time.Duration) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *fakePriceImportRepo) FinishRun(ctx context.Context, run *domain.PriceImportRun) error {
+	return nil
+}
+
+func (r *fakePriceImportRepo) LastRun(ctx context.Context, tenantID uuid.UUID, trigger string) (*domain.PriceImportRun, error) {
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		if r.runs[i].Trigger == trigger && !r.runs[i].DryRun {
+			return r.runs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePriceImportRepo) ListRuns(ctx context.Context, tenantID uuid.UUID, limit, offset int) ([]domain.PriceImportRun, int, error) {
+	return nil, 0, nil
+}
+
+func setupPriceImportTest(t *testing.T, fake *fakeTierERP) (*PriceImporter, *fakePriceImportRepo, uuid.UUID) {
+	t.Helper()
+	groupID := uuid.New()
+	tenant := domain.NewTenant("demo", "Demo")
+	tenant.Config["erp"] = map[string]any{"price_lists": []any{
+		map[string]any{"customer_id": "PL-BASE", "currency": "CHF"},
+		map[string]any{"customer_id": "PL-GOLD", "customer_group_id": groupID.String(), "currency": "CHF"},
+	}}
+
+	productRepo := NewMockProductRepository()
+	for _, sku := range []string{"SKU-1", "SKU-2"} {
+		productRepo.Create(context.Background(), domain.NewProduct(tenant.ID, sku))
+	}
+	importRepo := &fakePriceImportRepo{prices: make(map[uuid.UUID]domain.Price)}
+
+	importer := NewPriceImporter(productRepo, importRepo, &fakeTenantRepo{tenant: tenant},
+		provider.Static[erp.ERPProvider](fake), PriceImportConfig{Cadence: time.Hour, BatchSize: 1})
+	return importer, importRepo, tenant.ID
+}
+
+func TestPriceImport_CreatesUpdatesAndExpiresPrices(t *testing.T) {
+	fake := &fakeTierERP{tiers: map[string][]erp.TierPrice{
+		"PL-BASE": {
+			{SKU: "SKU-1", MinQty: 1, Price: 10, Currency: "chf"},
+			{SKU: "SKU-1", MinQty: 10, Price: 9},
+			{SKU: "SKU-2", MinQty: 1, Price: 20},
+		},
+		"PL-GOLD": {{SKU: "SKU-1", MinQty: 1, Price: 8.5}},
+	}}
+	importer, importRepo, tenantID := setupPriceImportTest(t, fake)
+	ctx := context.Background()
+
+	// A dry run reports the changes without applying them
+	result, err := importer.Import(ctx, tenantID, domain.PriceImportRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Run.Created != 4 || len(result.Changes) != 4 || len(importRepo.prices) != 0 {
+		t.Errorf("dry run = %d created, %d prices saved; want 4 created, none saved", result.Run.Created, len(importRepo.prices))
+	}
+	if len(fake.requests) != 4 {
+		t.Errorf("ERP requests = %d, want one per SKU and price list", len(fake.requests))
+	}
+
+	if _, err := importer.ImportDue(ctx); err != nil {
+		t.Fatalf("ImportDue() error = %v", err)
+	}
+	if len(importRepo.prices) != 4 {
+		t.Fatalf("prices = %d, want 4", len(importRepo.prices))
+	}
+	for _, p := range importRepo.prices {
+		if p.Source != domain.PriceSourceERP || p.Currency != "CHF" {
+			t.Errorf("price = %+v, want an ERP price in CHF", p)
+		}
+	}
+
+	// Within the cadence, the scheduled import skips the tenant
+	if n, _ := importer.ImportDue(ctx); n != 0 {
+		t.Errorf("ImportDue() = %d tenants, want 0 within the cadence", n)
+	}
+
+	// Changed and removed tiers of the imported SKUs are updated and expired
+	fake.tiers["PL-BASE"] = []erp.TierPrice{
+		{SKU: "SKU-1", MinQty: 1, Price: 11},
+		{SKU: "SKU-2", MinQty: 1, Price: 20},
+	}
+	fake.tiers["PL-GOLD"] = nil
+	result, err = importer.Import(ctx, tenantID, domain.PriceImportRequest{SKUs: []string{"SKU-1", "SKU-404"}})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if run := result.Run; run.Created != 0 || run.Updated != 1 || run.Expired != 2 || run.Unchanged != 0 {
+		t.Errorf("run = %+v, want 1 updated and 2 expired", run)
+	}
+	if len(result.UnknownSKUs) != 1 || result.UnknownSKUs[0] != "SKU-404" {
+		t.Errorf("unknown SKUs = %v, want SKU-404", result.UnknownSKUs)
+	}
+
+	active, _ := importRepo.ListERPPrices(ctx, tenantID, nil, time.Now().Add(time.Second))
+	if len(active) != 2 {
+		t.Errorf("active base prices = %d, want SKU-1 and SKU-2", len(active))
+	}
+}
+
+func TestPriceImport_FailsOnERPErrors(t *testing.T) {
+	importer, importRepo, tenantID := setupPriceImportTest(t, &fakeTierERP{err: errors.New("HTTP 503")})
+
+	result, err := importer.Import(context.Background(), tenantID, domain.PriceImportRequest{})
+	if !errors.Is(err, domain.ErrPriceImportFailed) {
+		t.Fatalf("Import() error = %v, want ErrPriceImportFailed", err)
+	}
+	if result.Run.Status != domain.PriceImportFailed || result.Run.Error == nil || len(importRepo.prices) != 0 {
+		t.Errorf("run = %+v, want failed without prices", result.Run)
+	}
+
+	if imported, err := importer.ImportDue(context.Background()); imported != 0 || !errors.Is(err, domain.ErrPriceImportFailed) {
+		t.Errorf("ImportDue() = %d, %v; want 0 and ErrPriceImportFailed", imported, err)
+	}
+}
+
+func TestPriceImport_RejectsListsOfTheSameGroup(t *testing.T) {
+	importer, _, tenantID := setupPriceImportTest(t, &fakeTierERP{})
+	tenant, _ := importer.tenantRepo.GetByID(context.Background(), tenantID)
+	erpConfig := tenant.Config["erp"].(map[string]any)
+	erpConfig["price_lists"] = append(erpConfig["price_lists"].([]any),
+		map[string]any{"customer_id": "PL-BASE-EUR", "currency": "EUR"})
+
+	if _, err := importer.Import(context.Background(), tenantID, domain.PriceImportRequest{}); err == nil {
+		t.Error("Import() error = nil, want an error for two lists of the base prices")
+	}
+	if imported, err := importer.ImportDue(context.Background()); imported != 0 || err == nil {
+		t.Errorf("ImportDue() = %d, %v; want 0 and an error", imported, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/search"
 	"github.com/gondolia/gondolia/services/catalog/internal/domain"
 	"github.com/gondolia/gondolia/services/catalog/internal/repository"
@@ -12,15 +13,15 @@ import (
 
 // SearchService handles product search operations
 type SearchService struct {
-	searchProvider search.SearchProvider
-	categoryRepo   repository.CategoryRepository
+	searchProviders provider.Source[search.SearchProvider]
+	categoryRepo    repository.CategoryRepository
 }
 
 // NewSearchService creates a new search service
-func NewSearchService(searchProvider search.SearchProvider, categoryRepo repository.CategoryRepository) *SearchService {
+func NewSearchService(searchProviders provider.Source[search.SearchProvider], categoryRepo repository.CategoryRepository) *SearchService {
 	return &SearchService{
-		searchProvider: searchProvider,
-		categoryRepo:   categoryRepo,
+		searchProviders: searchProviders,
+		categoryRepo:    categoryRepo,
 	}
 }
 
@@ -87,15 +88,25 @@ func (s *SearchService) Search(ctx context.Context, tenantID uuid.UUID, query st
 				Value:    value,
 			})
 		} else {
+			// List values (e.g. a category and its descendants) match any of their elements
+			operator := search.OpEqual
+			if _, ok := value.([]string); ok {
+				operator = search.OpIn
+			}
 			searchQuery.Filters = append(searchQuery.Filters, search.Filter{
 				Field:    field,
-				Operator: "=",
+				Operator: operator,
 				Value:    value,
 			})
 		}
 	}
 
-	result, err := s.searchProvider.Search(ctx, "products", searchQuery)
+	searchProvider, err := s.searchProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := searchProvider.Search(ctx, "products", searchQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -2,143 +2,636 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/gondolia/gondolia/provider"
 	"github.com/gondolia/gondolia/provider/pim"
 	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/storage"
 	"github.com/gondolia/gondolia/services/catalog/internal/domain"
 	"github.com/gondolia/gondolia/services/catalog/internal/repository"
 )
 
+// SyncConfig configures incremental PIM syncs
+type SyncConfig struct {
+	Overlap time.Duration // Incremental syncs also re-read products updated this long before the watermark
+	Lease   time.Duration // A run that saved no progress for this long counts as abandoned
+	// AssetBaseURL prefixes the storage paths of PIM assets in image URLs;
+	// defaults to "/assets", where the service serves them
+	AssetBaseURL string
+}
+
 // SyncService handles PIM synchronization and search indexing
 type SyncService struct {
-	productRepo  repository.ProductRepository
-	categoryRepo repository.CategoryRepository
-	pimProvider  pim.PIMProvider
-	searchProvider search.SearchProvider
+	productRepo      repository.ProductRepository
+	categoryRepo     repository.CategoryRepository
+	tenantRepo       repository.TenantRepository
+	translationRepo  repository.AttributeTranslationRepository
+	stateRepo        repository.PIMSyncStateRepository
+	assetRepo        repository.PIMAssetRepository
+	pimProviders     provider.Source[pim.PIMProvider]
+	searchProviders  provider.Source[search.SearchProvider]
+	storageProviders provider.Source[storage.StorageProvider]
+	cfg              SyncConfig
+	now              func() time.Time
 }
 
 // NewSyncService creates a new sync service
 func NewSyncService(
 	productRepo repository.ProductRepository,
 	categoryRepo repository.CategoryRepository,
-	pimProvider pim.PIMProvider,
-	searchProvider search.SearchProvider,
+	tenantRepo repository.TenantRepository,
+	translationRepo repository.AttributeTranslationRepository,
+	stateRepo repository.PIMSyncStateRepository,
+	assetRepo repository.PIMAssetRepository,
+	pimProviders provider.Source[pim.PIMProvider],
+	searchProviders provider.Source[search.SearchProvider],
+	storageProviders provider.Source[storage.StorageProvider],
+	cfg SyncConfig,
 ) *SyncService {
+	if cfg.Lease <= 0 {
+		cfg.Lease = 10 * time.Minute
+	}
+	if cfg.AssetBaseURL == "" {
+		cfg.AssetBaseURL = "/assets"
+	}
 	return &SyncService{
-		productRepo:    productRepo,
-		categoryRepo:   categoryRepo,
-		pimProvider:    pimProvider,
-		searchProvider: searchProvider,
+		productRepo:      productRepo,
+		categoryRepo:     categoryRepo,
+		tenantRepo:       tenantRepo,
+		translationRepo:  translationRepo,
+		stateRepo:        stateRepo,
+		assetRepo:        assetRepo,
+		pimProviders:     pimProviders,
+		searchProviders:  searchProviders,
+		storageProviders: storageProviders,
+		cfg:              cfg,
+		now:              time.Now,
 	}
 }
 
-// SyncFromPIM synchronizes products and categories from PIM
+// SyncFromPIM synchronizes products and categories from PIM.
+//
+// An incremental sync reads the products updated since the watermark of the
+// last successful run, a full sync all products. A run that failed is resumed
+// from its last processed page by the next run of the same kind. The
+// watermark advances to the start of a run once the run has synced all
+// products without failures.
+//
+// Attribute values are mapped to product attributes as configured in the
+// "pim" section of the tenant config, and the attribute labels become
+// attribute translations. Media become product images stored in the
+// tenant's object storage; a full sync removes the files no product uses.
+//
+// If the tenant syncs variants, the root product models become variant
+// parents with the axes of their family variant before the products are
+// synced; the products of a model become variants of its root model.
 func (s *SyncService) SyncFromPIM(ctx context.Context, tenantID uuid.UUID, fullSync bool) (*SyncResult, error) {
 	result := &SyncResult{
-		StartedAt: time.Now(),
+		StartedAt: s.now(),
+		FullSync:  fullSync,
 	}
 
-	// Sync categories first
-	if err := s.syncCategories(ctx, tenantID, result); err != nil {
+	// Resolve the PIM provider configured for this tenant
+	pimProvider, err := s.pimProviders.For(ctx, tenantID.String())
+	if err != nil {
 		result.Error = err.Error()
-		result.CompletedAt = time.Now()
+		result.CompletedAt = s.now()
 		return result, err
 	}
 
-	// Sync products
-	if err := s.syncProducts(ctx, tenantID, fullSync, result); err != nil {
+	state, err := s.startRun(ctx, tenantID, pimProvider.Metadata().Name, fullSync, result)
+	if err != nil {
 		result.Error = err.Error()
-		result.CompletedAt = time.Now()
+		result.CompletedAt = s.now()
 		return result, err
 	}
 
-	result.CompletedAt = time.Now()
-	return result, nil
+	// Sync categories first
+	categories, err := s.syncCategories(ctx, tenantID, pimProvider, fullSync, result)
+	var mapper *attributeMapper
+	if err == nil {
+		mapper, err = s.syncAttributes(ctx, tenantID, pimProvider, result)
+	}
+	var images *imageSync
+	if err == nil {
+		images, err = s.imageSync(ctx, tenantID, pimProvider, mapper)
+	}
+	var variants *variantSync
+	if err == nil {
+		variants, err = s.variantSync(ctx, pimProvider, mapper)
+	}
+	mapping := &productMapping{attributes: mapper, categories: categories, images: images, variants: variants}
+	if err == nil && variants != nil && !result.Resumed {
+		// A resumed run synced the models before its first page of products
+		err = s.syncModels(ctx, tenantID, pimProvider, mapping, state, result)
+	}
+	if err == nil {
+		// Sync products
+		err = s.syncProducts(ctx, tenantID, pimProvider, mapping, state, result)
+	}
+	if err == nil && images != nil && state.RunFull && state.Failed == 0 {
+		images.cleanup(ctx, *state.RunStartedAt, result)
+	}
+
+	// Record the outcome even if the request was canceled
+	if finishErr := s.finishRun(context.WithoutCancel(ctx), state, result, err); err == nil {
+		err = finishErr
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.CompletedAt = s.now()
+	return result, err
 }
 
-// syncCategories syncs categories from PIM
-func (s *SyncService) syncCategories(ctx context.Context, tenantID uuid.UUID, result *SyncResult) error {
-	categories, err := s.pimProvider.FetchCategories(ctx)
+// startRun claims the sync state of the tenant and provider for a new run,
+// or for resuming the interrupted run of the same kind.
+func (s *SyncService) startRun(ctx context.Context, tenantID uuid.UUID, providerName string, fullSync bool, result *SyncResult) (*domain.PIMSyncState, error) {
+	state, err := s.stateRepo.Get(ctx, tenantID, providerName)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &domain.PIMSyncState{TenantID: tenantID, Provider: providerName}
+	}
+
+	now := s.now()
+	if state.Resumable(fullSync) {
+		result.Resumed = true
+	} else {
+		state.RunFull = fullSync
+		state.RunStartedAt = &now
+		state.RunSince = nil
+		state.Cursor = ""
+		state.Pages = 0
+		state.Failed = 0
+		if !fullSync && state.Watermark != nil {
+			since := state.Watermark.Add(-s.cfg.Overlap)
+			state.RunSince = &since
+		}
+	}
+	state.Status = domain.PIMSyncRunning
+	state.UpdatedAt = now
+	result.Since = state.RunSince
+
+	if err := s.stateRepo.Claim(ctx, state, s.cfg.Lease); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// finishRun records the outcome of a run. A failed run keeps its cursor; a
+// completed run advances the watermark unless products or models failed to
+// sync, including those that failed before the run was resumed.
+func (s *SyncService) finishRun(ctx context.Context, state *domain.PIMSyncState, result *SyncResult, runErr error) error {
+	now := s.now()
+	state.UpdatedAt = now
+	switch {
+	case runErr != nil:
+		msg := runErr.Error()
+		state.Status = domain.PIMSyncFailed
+		state.LastError = &msg
+	case state.Failed > 0:
+		// Keep the watermark so that the failed products are read again
+		msg := fmt.Sprintf("%d products failed to sync", result.ProductsFailed)
+		if result.ModelsFailed > 0 {
+			msg = fmt.Sprintf("%d products and %d product models failed to sync", result.ProductsFailed, result.ModelsFailed)
+		}
+		if earlier := state.Failed - result.ProductsFailed - result.ModelsFailed; earlier > 0 {
+			msg += fmt.Sprintf(", %d before the run was resumed", earlier)
+		}
+		state.Status = domain.PIMSyncFailed
+		state.LastError = &msg
+		state.Cursor = ""
+		state.Pages = 0
+		state.Failed = 0
+	default:
+		state.Watermark = state.RunStartedAt
+		state.Status = domain.PIMSyncIdle
+		state.LastError = nil
+		state.LastSuccessAt = &now
+		state.RunSince = nil
+		state.RunStartedAt = nil
+		state.Cursor = ""
+		state.Pages = 0
+		state.Failed = 0
+	}
+	return s.stateRepo.Save(ctx, state)
+}
+
+// SyncStates returns the PIM sync states of a tenant
+func (s *SyncService) SyncStates(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMSyncState, error) {
+	return s.stateRepo.List(ctx, tenantID)
+}
+
+// ResetSyncState deletes the sync state of a provider, or of all providers if
+// providerName is empty, so that the next incremental sync reads all products.
+func (s *SyncService) ResetSyncState(ctx context.Context, tenantID uuid.UUID, providerName string) error {
+	states, err := s.stateRepo.List(ctx, tenantID)
 	if err != nil {
 		return err
 	}
+	for _, state := range states {
+		if (providerName == "" || state.Provider == providerName) &&
+			state.Status == domain.PIMSyncRunning && state.UpdatedAt.After(s.now().Add(-s.cfg.Lease)) {
+			return domain.ErrPIMSyncRunning
+		}
+	}
+	return s.stateRepo.Delete(ctx, tenantID, providerName)
+}
 
-	for _, pimCat := range categories {
-		category, err := s.categoryRepo.GetByCode(ctx, tenantID, pimCat.Code)
-		
-		if err == domain.ErrCategoryNotFound {
+// productMapping converts the PIM products of a run
+type productMapping struct {
+	attributes *attributeMapper
+	categories *categoryIndex
+	images     *imageSync   // nil = the PIM has no product images
+	variants   *variantSync // nil = all products are simple products
+}
+
+// categoryIndex resolves the PIM category codes of products to categories
+type categoryIndex struct {
+	ids    map[string]uuid.UUID // Code -> ID of the categories that are not deleted
+	synced map[uuid.UUID]bool   // Categories synced from PIM, including deleted ones
+}
+
+// link replaces the synced categories of a product with the categories of
+// the codes, keeping the categories assigned in the catalog. It returns the
+// number of codes without a category.
+func (c *categoryIndex) link(existing []uuid.UUID, codes []string) ([]uuid.UUID, int) {
+	linked := make([]uuid.UUID, 0, len(existing)+len(codes))
+	for _, id := range existing {
+		if !c.synced[id] && !slices.Contains(linked, id) {
+			linked = append(linked, id)
+		}
+	}
+	unknown := 0
+	for _, code := range codes {
+		id, ok := c.ids[code]
+		if !ok {
+			unknown++
+			continue
+		}
+		if !slices.Contains(linked, id) {
+			linked = append(linked, id)
+		}
+	}
+	return linked, unknown
+}
+
+// syncCategories syncs categories from PIM.
+//
+// Parents are synced before their children; a category whose parent is
+// unknown, or that is part of a cycle, fails. A full sync deletes the synced
+// categories the PIM no longer returns and moves their remaining children up.
+func (s *SyncService) syncCategories(ctx context.Context, tenantID uuid.UUID, pimProvider pim.PIMProvider, fullSync bool, result *SyncResult) (*categoryIndex, error) {
+	pimCategories, err := pimProvider.FetchCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.categoryRepo.ListAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &categoryIndex{ids: make(map[string]uuid.UUID), synced: make(map[uuid.UUID]bool)}
+	byCode := make(map[string]*domain.Category, len(all))
+	parents := make(map[uuid.UUID]*uuid.UUID, len(all)) // Parents of the categories that are not deleted
+	for i := range all {
+		category := &all[i]
+		byCode[category.Code] = category
+		if category.PIMCode != nil {
+			index.synced[category.ID] = true
+		}
+		if category.DeletedAt == nil {
+			index.ids[category.Code] = category.ID
+			parents[category.ID] = category.ParentID
+		}
+	}
+
+	// Order the categories so that parents come before their children;
+	// categories left over are part of a cycle or below one
+	inPIM := make(map[string]bool, len(pimCategories))
+	for _, pimCat := range pimCategories {
+		inPIM[pimCat.Code] = true
+	}
+	ordered := make([]pim.Category, 0, len(pimCategories))
+	placed := make(map[string]bool, len(pimCategories))
+	for progress := true; progress; {
+		progress = false
+		for _, pimCat := range pimCategories {
+			if placed[pimCat.Code] || pimCat.Parent != "" && inPIM[pimCat.Parent] && !placed[pimCat.Parent] {
+				continue
+			}
+			placed[pimCat.Code] = true
+			ordered = append(ordered, pimCat)
+			progress = true
+		}
+	}
+	result.CategoriesFailed += len(pimCategories) - len(ordered)
+
+	for _, pimCat := range ordered {
+		var parentID *uuid.UUID
+		if pimCat.Parent != "" {
+			id, ok := index.ids[pimCat.Parent]
+			if !ok {
+				result.CategoriesFailed++
+				continue
+			}
+			parentID = &id
+		}
+
+		pimCode := pimCat.Code
+		now := s.now()
+		category, exists := byCode[pimCat.Code]
+		if !exists {
 			// Create new category
 			category = domain.NewCategory(tenantID, pimCat.Code)
+			category.ParentID = parentID
 			category.Name = pimCat.Labels
-			category.PIMCode = &pimCat.Code
-			now := time.Now()
+			category.PIMCode = &pimCode
 			category.LastSyncedAt = &now
-			
+
 			if err := s.categoryRepo.Create(ctx, category); err != nil {
 				result.CategoriesFailed++
 				continue
 			}
 			result.CategoriesCreated++
-		} else if err == nil {
-			// Update existing category
+		} else {
+			// Update existing category, moving it to the parent of the PIM
+			moved := !sameID(category.ParentID, parentID)
+			if moved && parentID != nil && isAncestor(parents, category.ID, *parentID) {
+				result.CategoriesFailed++
+				continue
+			}
+			if category.DeletedAt != nil {
+				if err := s.categoryRepo.Restore(ctx, category.ID); err != nil {
+					result.CategoriesFailed++
+					continue
+				}
+				category.DeletedAt = nil
+			}
+			category.ParentID = parentID
 			category.Name = pimCat.Labels
-			now := time.Now()
+			category.PIMCode = &pimCode
 			category.LastSyncedAt = &now
 			category.UpdatedAt = now
-			
+
 			if err := s.categoryRepo.Update(ctx, category); err != nil {
 				result.CategoriesFailed++
 				continue
 			}
 			result.CategoriesUpdated++
-		} else {
+			if moved {
+				result.CategoriesMoved++
+			}
+		}
+		index.ids[category.Code] = category.ID
+		index.synced[category.ID] = true
+		parents[category.ID] = parentID
+	}
+
+	// An empty category list is more likely a misconfigured PIM than a
+	// deleted catalog, so deletions need at least one PIM category
+	if !fullSync || len(pimCategories) == 0 {
+		return index, nil
+	}
+	deleted := make(map[uuid.UUID]bool)
+	for _, category := range all {
+		if category.PIMCode != nil && category.DeletedAt == nil && !inPIM[category.Code] {
+			deleted[category.ID] = true
+		}
+	}
+	for i := range all {
+		category := &all[i]
+		if category.DeletedAt != nil || deleted[category.ID] || category.ParentID == nil || !deleted[*category.ParentID] {
+			continue
+		}
+		// Move the remaining child to the closest ancestor that is kept
+		parentID := category.ParentID
+		for parentID != nil && deleted[*parentID] {
+			parentID = parents[*parentID]
+		}
+		category.ParentID = parentID
+		category.UpdatedAt = s.now()
+		if err := s.categoryRepo.Update(ctx, category); err != nil {
 			result.CategoriesFailed++
+			continue
 		}
+		parents[category.ID] = parentID
+		result.CategoriesMoved++
 	}
+	for _, category := range all {
+		if !deleted[category.ID] {
+			continue
+		}
+		if err := s.categoryRepo.Delete(ctx, category.ID); err != nil {
+			result.CategoriesFailed++
+			continue
+		}
+		delete(index.ids, category.Code)
+		result.CategoriesDeleted++
+	}
+	return index, nil
+}
 
-	return nil
+// isAncestor reports whether id is parentID or one of its ancestors
+func isAncestor(parents map[uuid.UUID]*uuid.UUID, id, parentID uuid.UUID) bool {
+	current := &parentID
+	for range len(parents) + 1 {
+		if current == nil {
+			return false
+		}
+		if *current == id {
+			return true
+		}
+		current = parents[*current]
+	}
+	return true // The parents already contain a cycle
 }
 
-// syncProducts syncs products from PIM
-func (s *SyncService) syncProducts(ctx context.Context, tenantID uuid.UUID, fullSync bool, result *SyncResult) error {
-	filter := pim.ProductFilter{
-		Limit: 100,
+func sameID(a, b *uuid.UUID) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// syncAttributes reads the attribute definitions from PIM and creates the
+// attribute translations of the mapped attributes from their labels
+func (s *SyncService) syncAttributes(ctx context.Context, tenantID uuid.UUID, pimProvider pim.PIMProvider, result *SyncResult) (*attributeMapper, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
 	}
+	mapping, err := pimMapping(tenant)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := pimProvider.FetchAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mapper := newAttributeMapper(mapping, attributes)
+
+	existing := make(map[string]map[string]*domain.AttributeTranslation)
+	for _, target := range mapper.targets {
+		for language, label := range translationLanguages(target.labels, target.locales) {
+			translations, ok := existing[language]
+			if !ok {
+				translations, err = s.translationRepo.GetByTenantAndLocale(ctx, tenantID, language)
+				if err != nil {
+					return nil, err
+				}
+				existing[language] = translations
+			}
 
-	if !fullSync {
-		// Incremental sync - only products updated in last 24h
-		since := time.Now().Add(-24 * time.Hour)
-		filter.UpdatedSince = &since
+			translation, ok := translations[target.key]
+			switch {
+			case !ok:
+				translation = domain.NewAttributeTranslation(tenantID, target.key, language, label)
+				if err := s.translationRepo.Create(ctx, translation); err != nil {
+					result.TranslationsFailed++
+					continue
+				}
+				result.TranslationsCreated++
+			case mapping.OverwriteTranslations && translation.DisplayName != label:
+				translation.DisplayName = label
+				translation.UpdatedAt = time.Now()
+				if err := s.translationRepo.Update(ctx, translation); err != nil {
+					result.TranslationsFailed++
+					continue
+				}
+				result.TranslationsUpdated++
+			}
+		}
 	}
+	return mapper, nil
+}
+
+// imageSync prepares copying the product media to the tenant's object
+// storage; nil if the PIM has no media attributes
+func (s *SyncService) imageSync(ctx context.Context, tenantID uuid.UUID, pimProvider pim.PIMProvider, mapper *attributeMapper) (*imageSync, error) {
+	if len(mapper.images) == 0 {
+		return nil, nil
+	}
+	store, err := s.storageProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &imageSync{
+		tenantID:  tenantID,
+		pim:       pimProvider,
+		storage:   store,
+		assetRepo: s.assetRepo,
+		baseURL:   s.cfg.AssetBaseURL,
+		mapper:    mapper,
+		now:       s.now,
+	}, nil
+}
+
+// OpenAsset opens a PIM asset stored by the sync. The tenant is taken from
+// the path, so that image URLs work without tenant headers. Assets of
+// unknown tenants are not found.
+func (s *SyncService) OpenAsset(ctx context.Context, storagePath string) (io.ReadCloser, *storage.FileInfo, error) {
+	tenant, name, ok := strings.Cut(strings.TrimPrefix(storagePath, "pim/"), "/")
+	tenantID, err := uuid.Parse(tenant)
+	if !strings.HasPrefix(storagePath, "pim/") || !ok || err != nil || name == "" || strings.Contains(name, "/") {
+		return nil, nil, provider.ErrNotFound
+	}
+	if _, err := s.tenantRepo.GetByID(ctx, tenantID); errors.Is(err, domain.ErrTenantNotFound) {
+		return nil, nil, provider.ErrNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+	store, err := s.storageProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.Download(ctx, storagePath)
+}
+
+// syncProducts syncs the products of the run from PIM, saving the cursor and
+// the failures after each page. The failures of a page that is read again
+// after an interruption are not counted twice.
+func (s *SyncService) syncProducts(ctx context.Context, tenantID uuid.UUID, pimProvider pim.PIMProvider, mapping *productMapping, state *domain.PIMSyncState, result *SyncResult) error {
+	filter := pim.ProductFilter{
+		Limit:        100,
+		UpdatedSince: state.RunSince,
+		Cursor:       state.Cursor,
+	}
+	saved := 0 // Failures of this attempt counted in the state, including those of the models
 
 	for {
-		page, err := s.pimProvider.FetchProducts(ctx, filter)
+		page, err := pimProvider.FetchProducts(ctx, filter)
+		if err != nil && result.Resumed && filter.Cursor == state.Cursor && filter.Cursor != "" && errors.Is(err, provider.ErrInvalidArgument) {
+			// The provider no longer accepts the saved cursor; restart the run
+			// from the first page, keeping its start as the next watermark
+			filter.Cursor, state.Cursor, state.Pages, state.Failed = "", "", 0, 0
+			result.Resumed = false
+			continue
+		}
 		if err != nil {
 			return err
 		}
 
 		for _, pimProduct := range page.Products {
-			if err := s.syncProduct(ctx, tenantID, pimProduct, result); err != nil {
+			if err := s.syncProduct(ctx, tenantID, pimProduct, mapping, result); err != nil {
 				result.ProductsFailed++
 				continue
 			}
 		}
+		failed := result.ProductsFailed + result.ModelsFailed
+		state.Failed += failed - saved
+		saved = failed
 
 		// Check if there are more pages
 		if page.NextCursor == "" {
 			break
 		}
 		filter.Cursor = page.NextCursor
+
+		state.Cursor = page.NextCursor
+		state.Pages++
+		state.UpdatedAt = s.now()
+		if err := s.stateRepo.Save(ctx, state); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 // syncProduct syncs a single product
-func (s *SyncService) syncProduct(ctx context.Context, tenantID uuid.UUID, pimProduct pim.Product, result *SyncResult) error {
+func (s *SyncService) syncProduct(ctx context.Context, tenantID uuid.UUID, pimProduct pim.Product, mapping *productMapping, result *SyncResult) error {
+	var link *variantLink
+	if mapping.variants != nil {
+		var err error
+		if link, err = s.variantLink(ctx, tenantID, pimProduct, mapping, result); err != nil {
+			return err
+		}
+	}
+
+	_, created, err := s.saveProduct(ctx, tenantID, pimProduct, mapping, link, result)
+	if err != nil {
+		return err
+	}
+	if created {
+		result.ProductsCreated++
+	} else {
+		result.ProductsUpdated++
+	}
+	return nil
+}
+
+// saveProduct creates or updates the product of a PIM product and reports
+// whether it was created. A link places the product in the variant
+// hierarchy; without one the product type is kept.
+func (s *SyncService) saveProduct(ctx context.Context, tenantID uuid.UUID, pimProduct pim.Product, mapping *productMapping, link *variantLink, result *SyncResult) (*domain.Product, bool, error) {
 	product, err := s.productRepo.GetBySKU(ctx, tenantID, pimProduct.Identifier)
 	
 	// Convert PIM product to domain product
@@ -161,11 +654,38 @@ func (s *SyncService) syncProduct(ctx context.Context, tenantID uuid.UUID, pimPr
 		}
 	}
 
+	attributes, invalid := mapping.attributes.attributes(pimProduct.Values)
+	result.AttributeValuesInvalid += invalid
+	var categoryIDs []uuid.UUID
+	var images []domain.ProductImage
+	if product != nil {
+		categoryIDs = product.CategoryIDs
+		images = product.Images
+	}
+	categoryIDs, unknown := mapping.categories.link(categoryIDs, pimProduct.Categories)
+	result.ProductCategoriesUnknown += unknown
+	if mapping.images != nil && (err == nil || err == domain.ErrProductNotFound) {
+		var imageErr error
+		if images, imageErr = mapping.images.images(ctx, pimProduct, images, result); imageErr != nil {
+			return nil, false, imageErr
+		}
+	}
+
+	created, wasVariant := false, false
+
 	if err == domain.ErrProductNotFound {
 		// Create new product
 		product = domain.NewProduct(tenantID, pimProduct.Identifier)
 		product.Name = name
 		product.Description = description
+		product.Attributes = attributes
+		if link != nil && link.parent != nil {
+			product.Attributes = mergeAttributes(link.parent.product.Attributes, attributes)
+		}
+		product.CategoryIDs = categoryIDs
+		if mapping.images != nil {
+			product.Images = images
+		}
 		product.PIMIdentifier = &pimProduct.Identifier
 		now := time.Now()
 		product.LastSyncedAt = &now
@@ -174,14 +694,25 @@ func (s *SyncService) syncProduct(ctx context.Context, tenantID uuid.UUID, pimPr
 			product.Status = domain.ProductStatusActive
 		}
 		
+		if link != nil {
+			if err := s.place(ctx, product, link); err != nil {
+				return nil, false, err
+			}
+		}
 		if err := s.productRepo.Create(ctx, product); err != nil {
-			return err
+			return nil, false, err
 		}
-		result.ProductsCreated++
+		created = true
 	} else if err == nil {
+		wasVariant = product.ProductType == domain.ProductTypeVariant
 		// Update existing product
 		product.Name = name
 		product.Description = description
+		product.Attributes = mapping.attributes.merge(product.Attributes, attributes)
+		product.CategoryIDs = categoryIDs
+		if mapping.images != nil {
+			product.Images = images
+		}
 		now := time.Now()
 		product.LastSyncedAt = &now
 		product.UpdatedAt = now
@@ -192,15 +723,24 @@ func (s *SyncService) syncProduct(ctx context.Context, tenantID uuid.UUID, pimPr
 			product.Status = domain.ProductStatusArchived
 		}
 		
+		if link != nil {
+			if err := s.place(ctx, product, link); err != nil {
+				return nil, false, err
+			}
+		}
 		if err := s.productRepo.Update(ctx, product); err != nil {
-			return err
+			return nil, false, err
 		}
-		result.ProductsUpdated++
 	} else {
-		return err
+		return nil, false, err
 	}
 
-	return nil
+	if link != nil {
+		if err := s.setAxisValues(ctx, product, link, wasVariant); err != nil {
+			return nil, false, err
+		}
+	}
+	return product, created, nil
 }
 
 // IndexProduct indexes a product in the search engine
@@ -216,25 +756,52 @@ func (s *SyncService) IndexProduct(ctx context.Context, product *domain.Product)
 		"updated_at":  product.UpdatedAt.Unix(),
 	}
 
-	_, err := s.searchProvider.IndexDocuments(ctx, "products", []search.Document{doc})
+	searchProvider, err := s.searchProviders.For(ctx, product.TenantID.String())
+	if err != nil {
+		return err
+	}
+
+	_, err = searchProvider.IndexDocuments(ctx, "products", []search.Document{doc})
 	return err
 }
 
 // RemoveProductFromIndex removes a product from the search index
-func (s *SyncService) RemoveProductFromIndex(ctx context.Context, productID uuid.UUID) error {
-	_, err := s.searchProvider.DeleteDocuments(ctx, "products", []string{productID.String()})
+func (s *SyncService) RemoveProductFromIndex(ctx context.Context, tenantID, productID uuid.UUID) error {
+	searchProvider, err := s.searchProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return err
+	}
+
+	_, err = searchProvider.DeleteDocuments(ctx, "products", []string{productID.String()})
 	return err
 }
 
 // SyncResult represents the result of a PIM sync operation
 type SyncResult struct {
-	StartedAt         time.Time `json:"started_at"`
-	CompletedAt       time.Time `json:"completed_at"`
-	ProductsCreated   int       `json:"products_created"`
-	ProductsUpdated   int       `json:"products_updated"`
-	ProductsFailed    int       `json:"products_failed"`
-	CategoriesCreated int       `json:"categories_created"`
-	CategoriesUpdated int       `json:"categories_updated"`
-	CategoriesFailed  int       `json:"categories_failed"`
-	Error             string    `json:"error,omitempty"`
+	StartedAt                time.Time  `json:"started_at"`
+	CompletedAt              time.Time  `json:"completed_at"`
+	ProductsCreated          int        `json:"products_created"`
+	ProductsUpdated          int        `json:"products_updated"`
+	ProductsFailed           int        `json:"products_failed"`
+	ModelsCreated            int        `json:"models_created"` // Root product models synced as variant parents
+	ModelsUpdated            int        `json:"models_updated"`
+	ModelsFailed             int        `json:"models_failed"`
+	CategoriesCreated        int        `json:"categories_created"`
+	CategoriesUpdated        int        `json:"categories_updated"`
+	CategoriesFailed         int        `json:"categories_failed"`
+	CategoriesMoved          int        `json:"categories_moved"`           // Categories with a new parent
+	CategoriesDeleted        int        `json:"categories_deleted"`         // Synced categories missing from a full sync
+	ProductCategoriesUnknown int        `json:"product_categories_unknown"` // Category codes of products without a category
+	AttributeValuesInvalid   int        `json:"attribute_values_invalid"`   // Values not convertible to the type of their attribute
+	TranslationsCreated      int        `json:"translations_created"`
+	TranslationsUpdated      int        `json:"translations_updated"`
+	TranslationsFailed       int        `json:"translations_failed"`
+	AssetsUploaded           int        `json:"assets_uploaded"`  // Files new to object storage
+	AssetsUnchanged          int        `json:"assets_unchanged"` // Assets not downloaded again
+	AssetsDeleted            int        `json:"assets_deleted"`   // Files no product uses after a full sync
+	AssetsFailed             int        `json:"assets_failed"`
+	FullSync                 bool       `json:"full_sync"`
+	Since                    *time.Time `json:"since,omitempty"` // UpdatedSince of the run; nil = all products
+	Resumed                  bool       `json:"resumed"`         // The run continued an interrupted run
+	Error                    string     `json:"error,omitempty"`
 }
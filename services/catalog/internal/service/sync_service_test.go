@@ -0,0 +1,867 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/pim"
+	"github.com/gondolia/gondolia/provider/search"
+	"github.com/gondolia/gondolia/provider/storage"
+	"github.com/gondolia/gondolia/services/catalog/internal/domain"
+	"github.com/gondolia/gondolia/services/catalog/internal/repository"
+)
+
+// fakePIM serves products in pages of two with offset cursors
+type fakePIM struct {
+	pim.PIMProvider
+	products   []pim.Product
+	filters    []pim.ProductFilter
+	failAt     string // Cursor whose page fails
+	attributes []pim.Attribute
+	categories []pim.Category
+	assets     map[string]string // Asset code -> content
+	downloads  int
+	models     []pim.ProductModel
+	families   []pim.FamilyVariant
+}
+
+func (f *fakePIM) Metadata() pim.Metadata { return pim.Metadata{Name: "fake"} }
+
+func (f *fakePIM) FetchCategories(ctx context.Context) ([]pim.Category, error) {
+	return f.categories, nil
+}
+
+func (f *fakePIM) FetchAttributes(ctx context.Context) ([]pim.Attribute, error) {
+	return f.attributes, nil
+}
+
+func (f *fakePIM) FetchProducts(ctx context.Context, filter pim.ProductFilter) (*pim.ProductPage, error) {
+	f.filters = append(f.filters, filter)
+	if filter.Cursor != "" && filter.Cursor == f.failAt {
+		return nil, errors.New("HTTP 503")
+	}
+	offset := 0
+	if filter.Cursor != "" {
+		n, ok := strings.CutPrefix(filter.Cursor, "fake:")
+		if !ok {
+			return nil, provider.ErrInvalidArgument
+		}
+		offset, _ = strconv.Atoi(n)
+	}
+
+	var matched []pim.Product
+	for _, p := range f.products {
+		if filter.UpdatedSince == nil || p.Updated.After(*filter.UpdatedSince) {
+			matched = append(matched, p)
+		}
+	}
+	page := &pim.ProductPage{Products: matched[min(offset, len(matched)):min(offset+2, len(matched))]}
+	if offset+2 < len(matched) {
+		page.NextCursor = "fake:" + strconv.Itoa(offset+2)
+	}
+	return page, nil
+}
+
+func (f *fakePIM) FetchProductModels(ctx context.Context, filter pim.ProductFilter) (*pim.ProductModelPage, error) {
+	return &pim.ProductModelPage{Models: f.models}, nil
+}
+
+func (f *fakePIM) FetchProductModel(ctx context.Context, code string) (*pim.ProductModel, error) {
+	for _, model := range f.models {
+		if model.Code == code {
+			return &model, nil
+		}
+	}
+	return nil, provider.ErrNotFound
+}
+
+func (f *fakePIM) FetchFamilyVariants(ctx context.Context) ([]pim.FamilyVariant, error) {
+	return f.families, nil
+}
+
+func (f *fakePIM) DownloadAsset(ctx context.Context, assetCode string) (io.ReadCloser, string, error) {
+	content, ok := f.assets[assetCode]
+	if !ok {
+		return nil, "", provider.ErrNotFound
+	}
+	f.downloads++
+	return io.NopCloser(strings.NewReader(content)), "", nil
+}
+
+// fakeSyncStateRepo keeps sync states in memory
+type fakeSyncStateRepo struct {
+	states map[string]domain.PIMSyncState
+}
+
+func (r *fakeSyncStateRepo) Get(ctx context.Context, tenantID uuid.UUID, provider string) (*domain.PIMSyncState, error) {
+	state, ok := r.states[tenantID.String()+"/"+provider]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *fakeSyncStateRepo) List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMSyncState, error) {
+	var states []domain.PIMSyncState
+	for _, state := range r.states {
+		if state.TenantID == tenantID {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func (r *fakeSyncStateRepo) Claim(ctx context.Context, state *domain.PIMSyncState, lease time.Duration) error {
+	if current, ok := r.states[state.TenantID.String()+"/"+state.Provider]; ok && current.Status == domain.PIMSyncRunning {
+		return domain.ErrPIMSyncRunning
+	}
+	return r.Save(ctx, state)
+}
+
+func (r *fakeSyncStateRepo) Save(ctx context.Context, state *domain.PIMSyncState) error {
+	r.states[state.TenantID.String()+"/"+state.Provider] = *state
+	return nil
+}
+
+func (r *fakeSyncStateRepo) Delete(ctx context.Context, tenantID uuid.UUID, provider string) error {
+	for key, state := range r.states {
+		if state.TenantID == tenantID && (provider == "" || state.Provider == provider) {
+			delete(r.states, key)
+		}
+	}
+	return nil
+}
+
+// fakeCategoryRepo keeps categories in memory
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+	categories map[uuid.UUID]*domain.Category
+}
+
+func (r *fakeCategoryRepo) byCode(code string) *domain.Category {
+	for _, category := range r.categories {
+		if category.Code == code {
+			return category
+		}
+	}
+	return nil
+}
+
+func (r *fakeCategoryRepo) ListAll(ctx context.Context, tenantID uuid.UUID) ([]domain.Category, error) {
+	var categories []domain.Category
+	for _, category := range r.categories {
+		if category.TenantID == tenantID {
+			categories = append(categories, *category)
+		}
+	}
+	return categories, nil
+}
+
+func (r *fakeCategoryRepo) Create(ctx context.Context, category *domain.Category) error {
+	copied := *category
+	r.categories[category.ID] = &copied
+	return nil
+}
+
+func (r *fakeCategoryRepo) Update(ctx context.Context, category *domain.Category) error {
+	if current, ok := r.categories[category.ID]; !ok || current.DeletedAt != nil {
+		return domain.ErrCategoryNotFound
+	}
+	copied := *category
+	r.categories[category.ID] = &copied
+	return nil
+}
+
+func (r *fakeCategoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	r.categories[id].DeletedAt = &now
+	return nil
+}
+
+func (r *fakeCategoryRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	r.categories[id].DeletedAt = nil
+	return nil
+}
+
+// fakeAssetRepo keeps PIM assets in memory
+type fakeAssetRepo struct {
+	assets map[string]domain.PIMAsset
+}
+
+func (r *fakeAssetRepo) Get(ctx context.Context, tenantID uuid.UUID, code string) (*domain.PIMAsset, error) {
+	asset, ok := r.assets[tenantID.String()+"/"+code]
+	if !ok {
+		return nil, nil
+	}
+	return &asset, nil
+}
+
+func (r *fakeAssetRepo) List(ctx context.Context, tenantID uuid.UUID) ([]domain.PIMAsset, error) {
+	var assets []domain.PIMAsset
+	for _, asset := range r.assets {
+		if asset.TenantID == tenantID {
+			assets = append(assets, asset)
+		}
+	}
+	return assets, nil
+}
+
+func (r *fakeAssetRepo) Save(ctx context.Context, asset *domain.PIMAsset) error {
+	r.assets[asset.TenantID.String()+"/"+asset.Code] = *asset
+	return nil
+}
+
+func (r *fakeAssetRepo) Delete(ctx context.Context, tenantID uuid.UUID, codes []string) error {
+	for _, code := range codes {
+		delete(r.assets, tenantID.String()+"/"+code)
+	}
+	return nil
+}
+
+// fakeVariantRepo keeps variant axes and axis values in memory
+type fakeVariantRepo struct {
+	*MockProductRepository
+	axes   map[uuid.UUID][]domain.VariantAxis
+	values map[uuid.UUID][]domain.AxisValueEntry
+}
+
+func (r *fakeVariantRepo) SetVariantAxes(ctx context.Context, parentID uuid.UUID, axes []domain.VariantAxis) error {
+	r.axes[parentID] = axes
+	return nil
+}
+
+func (r *fakeVariantRepo) GetVariantAxes(ctx context.Context, parentID uuid.UUID) ([]domain.VariantAxis, error) {
+	return r.axes[parentID], nil
+}
+
+func (r *fakeVariantRepo) SetAxisValues(ctx context.Context, variantID uuid.UUID, values []domain.AxisValueEntry) error {
+	r.values[variantID] = values
+	return nil
+}
+
+func (r *fakeVariantRepo) FindVariantByAxisValues(ctx context.Context, parentID uuid.UUID, axisValues map[string]string) (*domain.Product, error) {
+	for id, entries := range r.values {
+		variant := r.products[id]
+		if variant.ParentID == nil || *variant.ParentID != parentID || len(entries) != len(axisValues) {
+			continue
+		}
+		matches := true
+		for _, entry := range entries {
+			matches = matches && axisValues[entry.AxisAttributeCode] == entry.OptionCode
+		}
+		if matches {
+			return variant, nil
+		}
+	}
+	return nil, domain.ErrProductNotFound
+}
+
+// memStorage keeps files in memory
+type memStorage struct {
+	storage.StorageProvider
+	files   map[string]string
+	uploads int
+}
+
+func (s *memStorage) Upload(ctx context.Context, path string, reader io.Reader, opts storage.UploadOptions) (*storage.FileInfo, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	s.files[path] = string(content)
+	s.uploads++
+	return &storage.FileInfo{Path: path, Size: int64(len(content)), ContentType: opts.ContentType}, nil
+}
+
+func (s *memStorage) Download(ctx context.Context, path string) (io.ReadCloser, *storage.FileInfo, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return nil, nil, provider.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), &storage.FileInfo{Path: path, Size: int64(len(content))}, nil
+}
+
+func (s *memStorage) Exists(ctx context.Context, path string) (bool, error) {
+	_, ok := s.files[path]
+	return ok, nil
+}
+
+func (s *memStorage) Delete(ctx context.Context, path string) error {
+	delete(s.files, path)
+	return nil
+}
+
+func (s *memStorage) List(ctx context.Context, prefix string, opts storage.ListOptions) ([]storage.FileInfo, error) {
+	var files []storage.FileInfo
+	for path, content := range s.files {
+		if strings.HasPrefix(path, prefix) && path > opts.Cursor {
+			files = append(files, storage.FileInfo{Path: path, Size: int64(len(content))})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files[:min(len(files), opts.MaxKeys)], nil
+}
+
+// fakeTranslationRepo keeps attribute translations in memory
+type fakeTranslationRepo struct {
+	repository.AttributeTranslationRepository
+	translations map[string]*domain.AttributeTranslation // locale/key -> translation
+}
+
+func (r *fakeTranslationRepo) GetByTenantAndLocale(ctx context.Context, tenantID uuid.UUID, locale string) (map[string]*domain.AttributeTranslation, error) {
+	result := make(map[string]*domain.AttributeTranslation)
+	for _, translation := range r.translations {
+		if translation.TenantID == tenantID && translation.Locale == locale {
+			copied := *translation
+			result[translation.AttributeKey] = &copied
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeTranslationRepo) Create(ctx context.Context, translation *domain.AttributeTranslation) error {
+	r.translations[translation.Locale+"/"+translation.AttributeKey] = translation
+	return nil
+}
+
+func (r *fakeTranslationRepo) Update(ctx context.Context, translation *domain.AttributeTranslation) error {
+	r.translations[translation.Locale+"/"+translation.AttributeKey] = translation
+	return nil
+}
+
+func setupSyncTest(t *testing.T, tenant *domain.Tenant, count int) (*SyncService, *fakePIM, *fakeSyncStateRepo, *time.Time) {
+	t.Helper()
+	updated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakePIM{}
+	for i := range count {
+		fake.products = append(fake.products, pim.Product{
+			Identifier: "SKU-" + strconv.Itoa(i+1),
+			Enabled:    true,
+			Updated:    updated,
+		})
+	}
+	stateRepo := &fakeSyncStateRepo{states: make(map[string]domain.PIMSyncState)}
+
+	categoryRepo := &fakeCategoryRepo{categories: make(map[uuid.UUID]*domain.Category)}
+	translationRepo := &fakeTranslationRepo{translations: make(map[string]*domain.AttributeTranslation)}
+	assetRepo := &fakeAssetRepo{assets: make(map[string]domain.PIMAsset)}
+	store := &memStorage{files: make(map[string]string)}
+	svc := NewSyncService(NewMockProductRepository(), categoryRepo, &fakeTenantRepo{tenant: tenant}, translationRepo, stateRepo, assetRepo,
+		provider.Static[pim.PIMProvider](fake), provider.Static[search.SearchProvider](nil), provider.Static[storage.StorageProvider](store),
+		SyncConfig{Overlap: time.Minute})
+	now := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, fake, stateRepo, &now
+}
+
+func TestSyncFromPIM_AdvancesWatermarkAfterSuccess(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New()}
+	svc, fake, stateRepo, now := setupSyncTest(t, tenant, 3)
+	ctx := context.Background()
+	tenantID := tenant.ID
+	firstStart := *now
+
+	// The first incremental sync reads all products
+	result, err := svc.SyncFromPIM(ctx, tenantID, false)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if result.ProductsCreated != 3 || result.Since != nil {
+		t.Errorf("first sync = %+v, want 3 products created without UpdatedSince", result)
+	}
+	state := stateRepo.states[tenantID.String()+"/fake"]
+	if state.Status != domain.PIMSyncIdle || state.Watermark == nil || !state.Watermark.Equal(firstStart) || state.Cursor != "" {
+		t.Fatalf("state = %+v, want idle with the run start as watermark", state)
+	}
+
+	// The next sync reads the products updated since the watermark minus the overlap
+	*now = now.Add(time.Hour)
+	fake.products[1].Updated = firstStart.Add(30 * time.Minute)
+	result, err = svc.SyncFromPIM(ctx, tenantID, false)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if since := fake.filters[len(fake.filters)-1].UpdatedSince; since == nil || !since.Equal(firstStart.Add(-time.Minute)) {
+		t.Errorf("UpdatedSince = %v, want watermark minus overlap", since)
+	}
+	if result.ProductsUpdated != 1 {
+		t.Errorf("second sync = %+v, want SKU-2 updated", result)
+	}
+	if state := stateRepo.states[tenantID.String()+"/fake"]; !state.Watermark.Equal(*now) {
+		t.Errorf("watermark = %v, want %v", state.Watermark, *now)
+	}
+
+	states, _ := svc.SyncStates(ctx, tenantID)
+	if len(states) != 1 {
+		t.Fatalf("SyncStates() = %d states, want 1", len(states))
+	}
+	if err := svc.ResetSyncState(ctx, tenantID, ""); err != nil {
+		t.Fatalf("ResetSyncState() error = %v", err)
+	}
+	if result, _ = svc.SyncFromPIM(ctx, tenantID, false); result.Since != nil {
+		t.Errorf("sync after reset read since %v, want all products", result.Since)
+	}
+}
+
+func TestSyncFromPIM_ResumesInterruptedRun(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New()}
+	svc, fake, stateRepo, now := setupSyncTest(t, tenant, 5)
+	ctx := context.Background()
+	tenantID := tenant.ID
+	firstStart := *now
+
+	fake.failAt = "fake:4"
+	if _, err := svc.SyncFromPIM(ctx, tenantID, true); err == nil {
+		t.Fatal("SyncFromPIM() succeeded, want the page error")
+	}
+	state := stateRepo.states[tenantID.String()+"/fake"]
+	if state.Status != domain.PIMSyncFailed || state.Cursor != "fake:4" || state.Pages != 2 || state.Watermark != nil || state.LastError == nil {
+		t.Fatalf("state = %+v, want failed at the third page without watermark", state)
+	}
+
+	// The next full sync continues after the last processed page
+	fake.failAt = ""
+	*now = now.Add(time.Hour)
+	result, err := svc.SyncFromPIM(ctx, tenantID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if !result.Resumed || result.ProductsCreated != 1 || result.ProductsUpdated != 0 || fake.filters[len(fake.filters)-1].Cursor != "fake:4" {
+		t.Errorf("resumed sync = %+v, want SKU-5 only", result)
+	}
+	state = stateRepo.states[tenantID.String()+"/fake"]
+	if state.Status != domain.PIMSyncIdle || state.Watermark == nil || !state.Watermark.Equal(firstStart) {
+		t.Errorf("state = %+v, want idle with the start of the interrupted run as watermark", state)
+	}
+
+	// A running sync blocks other runs and resets
+	state.Status = domain.PIMSyncRunning
+	state.UpdatedAt = *now
+	stateRepo.states[tenantID.String()+"/fake"] = state
+	if _, err := svc.SyncFromPIM(ctx, tenantID, false); !errors.Is(err, domain.ErrPIMSyncRunning) {
+		t.Errorf("SyncFromPIM() error = %v, want ErrPIMSyncRunning", err)
+	}
+	if err := svc.ResetSyncState(ctx, tenantID, "fake"); !errors.Is(err, domain.ErrPIMSyncRunning) {
+		t.Errorf("ResetSyncState() error = %v, want ErrPIMSyncRunning", err)
+	}
+}
+
+func TestSyncFromPIM_MapsAttributesAndTranslations(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New(), Config: map[string]any{
+		"pim": map[string]any{
+			"channel":  "ecommerce",
+			"locales":  []any{"de_CH", "fr_CH"},
+			"currency": "CHF",
+		},
+	}}
+	svc, fake, _, _ := setupSyncTest(t, tenant, 1)
+	ctx := context.Background()
+	translations := svc.translationRepo.(*fakeTranslationRepo)
+
+	fake.attributes = []pim.Attribute{
+		{Code: "name", Type: "text", Localizable: true, Labels: map[string]string{"de_CH": "Name"}},
+		{Code: "weight", Type: "metric", Labels: map[string]string{"de_CH": "Gewicht", "de_DE": "Masse", "fr_CH": "Poids"}},
+		{Code: "color", Type: "select", Localizable: true, Scopable: true, Labels: map[string]string{"en_US": "Colour"}},
+		{Code: "price", Type: "price"},
+		{Code: "cordless", Type: "boolean"},
+		{Code: "picture", Type: "media", Labels: map[string]string{"de_CH": "Bild"}},
+	}
+	fake.products[0].Values = map[string][]pim.AttributeValue{
+		"name":   {{Locale: "de_CH", Data: "Bohrer"}},
+		"weight": {{Data: map[string]any{"amount": "1.5", "unit": "KILOGRAM"}}},
+		"color": {
+			{Locale: "en_US", Scope: "ecommerce", Data: "red"},
+			{Locale: "fr_CH", Scope: "ecommerce", Data: "rouge"},
+			{Locale: "de_CH", Scope: "print", Data: "Rot (Druck)"},
+			{Locale: "de_CH", Scope: "ecommerce", Data: "rot"},
+		},
+		"price":    {{Data: []any{map[string]any{"amount": "12.50", "currency": "EUR"}, map[string]any{"amount": "13.90", "currency": "CHF"}}}},
+		"cordless": {{Data: "yes"}},
+		"picture":  {{Data: "a/b/drill.png"}},
+	}
+	fake.assets = map[string]string{"a/b/drill.png": "\x89PNG\r\n\x1a\n drill"}
+
+	result, err := svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	product, _ := svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	want := []domain.ProductAttribute{
+		{Key: "color", Type: domain.AttributeTypeText, Value: "rot"},
+		{Key: "price", Type: domain.AttributeTypeNumber, Value: 13.9},
+		{Key: "weight", Type: domain.AttributeTypeNumber, Value: 1.5},
+	}
+	if !reflect.DeepEqual(product.Attributes, want) || result.AttributeValuesInvalid != 1 {
+		t.Errorf("attributes = %+v (%d invalid), want %+v and the invalid boolean", product.Attributes, result.AttributeValuesInvalid, want)
+	}
+
+	// Labels become translations per language, preferring the configured locales
+	if result.TranslationsCreated != 3 || len(translations.translations) != 3 ||
+		translations.translations["de/weight"].DisplayName != "Gewicht" ||
+		translations.translations["fr/weight"].DisplayName != "Poids" ||
+		translations.translations["en/color"].DisplayName != "Colour" {
+		t.Errorf("translations = %+v, want weight in de and fr, color in en", translations.translations)
+	}
+
+	// An explicit mapping replaces the mapped attributes and keeps the others;
+	// existing translations are only replaced when configured
+	product.Attributes = append(product.Attributes, domain.ProductAttribute{Key: "manual", Type: domain.AttributeTypeText, Value: "kept"})
+	tenant.Config["pim"] = map[string]any{
+		"attributes": []any{
+			map[string]any{"code": "weight", "key": "weight_kg"},
+			map[string]any{"code": "color", "locales": []any{"fr_CH"}, "scope": "ecommerce"},
+			map[string]any{"code": "cordless", "type": "boolean"},
+		},
+	}
+	fake.products[0].Values["cordless"] = []pim.AttributeValue{{Data: true}}
+	fake.attributes[1].Labels["de_CH"] = "Gewicht netto"
+	result, err = svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	product, _ = svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	want = []domain.ProductAttribute{
+		{Key: "price", Type: domain.AttributeTypeNumber, Value: 13.9},
+		{Key: "weight", Type: domain.AttributeTypeNumber, Value: 1.5},
+		{Key: "manual", Type: domain.AttributeTypeText, Value: "kept"},
+		{Key: "color", Type: domain.AttributeTypeText, Value: "rouge"},
+		{Key: "cordless", Type: domain.AttributeTypeBoolean, Value: true},
+		{Key: "weight_kg", Type: domain.AttributeTypeNumber, Value: 1.5},
+	}
+	if !reflect.DeepEqual(product.Attributes, want) {
+		t.Errorf("attributes = %+v, want %+v", product.Attributes, want)
+	}
+	if result.TranslationsCreated != 2 || translations.translations["de/weight"].DisplayName != "Gewicht" {
+		t.Errorf("translations = %+v, want weight_kg created and weight unchanged", translations.translations)
+	}
+
+	tenant.Config["pim"] = map[string]any{"overwrite_translations": true}
+	if _, err := svc.SyncFromPIM(ctx, tenant.ID, true); err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if translations.translations["de/weight"].DisplayName != "Gewicht netto" {
+		t.Errorf("de/weight = %q, want the PIM label", translations.translations["de/weight"].DisplayName)
+	}
+
+	tenant.Config["pim"] = map[string]any{"attributes": []any{map[string]any{"code": "weight", "type": "metric"}}}
+	if _, err := svc.SyncFromPIM(ctx, tenant.ID, true); err == nil {
+		t.Error("SyncFromPIM() succeeded with an unknown attribute type")
+	}
+}
+
+func TestSyncFromPIM_SyncsCategoryTree(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New()}
+	svc, fake, _, now := setupSyncTest(t, tenant, 2)
+	ctx := context.Background()
+	categories := svc.categoryRepo.(*fakeCategoryRepo)
+	parentCode := func(code string) string {
+		category := categories.byCode(code)
+		if category == nil || category.ParentID == nil {
+			return ""
+		}
+		return categories.categories[*category.ParentID].Code
+	}
+
+	// Children come before their parents and a cycle; a manual category is kept
+	manual := domain.NewCategory(tenant.ID, "sale")
+	categories.categories[manual.ID] = manual
+	fake.categories = []pim.Category{
+		{Code: "drills", Parent: "tools"},
+		{Code: "cordless", Parent: "drills"},
+		{Code: "tools", Labels: map[string]string{"de_CH": "Werkzeug"}},
+		{Code: "a", Parent: "b"},
+		{Code: "b", Parent: "a"},
+		{Code: "orphan", Parent: "missing"},
+	}
+	fake.products[0].Categories = []string{"cordless", "sale", "unknown"}
+
+	result, err := svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if result.CategoriesCreated != 3 || result.CategoriesFailed != 3 || parentCode("cordless") != "drills" || parentCode("drills") != "tools" {
+		t.Errorf("result = %+v, want tools > drills > cordless created and the cycle and orphan failed", result)
+	}
+	if synced := categories.byCode("tools").LastSyncedAt; synced == nil || !synced.Equal(*now) {
+		t.Errorf("LastSyncedAt = %v, want the sync clock %v", synced, *now)
+	}
+	product, _ := svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	if len(product.CategoryIDs) != 2 || product.CategoryIDs[0] != categories.byCode("cordless").ID || product.CategoryIDs[1] != manual.ID || result.ProductCategoriesUnknown != 1 {
+		t.Errorf("CategoryIDs = %v (%d unknown), want cordless and sale", product.CategoryIDs, result.ProductCategoriesUnknown)
+	}
+
+	// Moves are applied, links assigned in the catalog are kept, and
+	// categories missing from a full sync are deleted with their children moved up
+	extra := domain.NewCategory(tenant.ID, "workshop")
+	extra.ParentID = &categories.byCode("cordless").ID
+	categories.categories[extra.ID] = extra
+	product.CategoryIDs = append(product.CategoryIDs, extra.ID)
+	fake.categories = []pim.Category{
+		{Code: "tools"},
+		{Code: "drills"},
+		{Code: "tools", Parent: "drills"}, // Duplicate creating a cycle
+	}
+	fake.products[0].Categories = []string{"drills"}
+
+	result, err = svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if result.CategoriesDeleted != 1 || categories.byCode("cordless").DeletedAt == nil || parentCode("drills") != "" || parentCode("workshop") != "drills" {
+		t.Errorf("result = %+v, want drills moved to the root and cordless deleted with workshop moved to drills", result)
+	}
+	product, _ = svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	want := []uuid.UUID{manual.ID, extra.ID, categories.byCode("drills").ID}
+	if !reflect.DeepEqual(product.CategoryIDs, want) {
+		t.Errorf("CategoryIDs = %v, want sale, workshop and drills", product.CategoryIDs)
+	}
+
+	// A category returned again is restored
+	fake.categories = append(fake.categories, pim.Category{Code: "cordless", Parent: "drills"})
+	if _, err := svc.SyncFromPIM(ctx, tenant.ID, false); err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if categories.byCode("cordless").DeletedAt != nil || parentCode("cordless") != "drills" {
+		t.Errorf("cordless = %+v, want restored below drills", categories.byCode("cordless"))
+	}
+}
+
+func TestSyncFromPIM_StoresProductImages(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New(), Config: map[string]any{"pim": map[string]any{"locales": []any{"de_CH"}}}}
+	svc, fake, _, now := setupSyncTest(t, tenant, 2)
+	ctx := context.Background()
+	provided, _ := svc.storageProviders.For(ctx, tenant.ID.String())
+	store := provided.(*memStorage)
+	assets := svc.assetRepo.(*fakeAssetRepo)
+
+	const png = "\x89PNG\r\n\x1a\n drill"
+	fake.attributes = []pim.Attribute{
+		{Code: "name", Type: "text", Localizable: true},
+		{Code: "picture", Type: "media"},
+		{Code: "manual", Type: "media"},
+	}
+	fake.assets = map[string]string{
+		"a/drill.png":     png,
+		"b/copy.png":      png,
+		"docs/manual.pdf": "%PDF-1.4 manual",
+	}
+	fake.products[0].Values = map[string][]pim.AttributeValue{
+		"name":    {{Locale: "de_CH", Data: "Bohrer"}, {Locale: "fr_CH", Data: "Perceuse"}},
+		"picture": {{Data: "a/drill.png"}},
+		"manual":  {{Data: "docs/manual.pdf"}},
+	}
+	fake.products[1].Values = map[string][]pim.AttributeValue{"picture": {{Data: "b/copy.png"}}}
+
+	result, err := svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	// The same content is stored once; the PDF is stored but is not an image
+	if result.AssetsUploaded != 2 || store.uploads != 2 || len(assets.assets) != 3 {
+		t.Errorf("result = %+v, %d files, want the PNG and the PDF uploaded once", result, len(store.files))
+	}
+	drill := assets.assets[tenant.ID.String()+"/a/drill.png"]
+	url := "/assets/pim/" + tenant.ID.String() + "/" + drill.Hash + ".png"
+	product, _ := svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	want := []domain.ProductImage{{URL: url, AltText: "Bohrer", SortOrder: 0, IsPrimary: true}}
+	if !reflect.DeepEqual(product.Images, want) || drill.ContentType != "image/png" {
+		t.Errorf("images = %+v, want %+v", product.Images, want)
+	}
+	if copied, _ := svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-2"); len(copied.Images) != 1 || copied.Images[0].URL != url {
+		t.Errorf("SKU-2 images = %+v, want the same file", copied.Images)
+	}
+
+	// Unchanged products do not download their assets again; images added in
+	// the catalog are kept
+	product.Images = append(product.Images, domain.ProductImage{URL: "https://cdn.example.com/drill.jpg", IsPrimary: false})
+	downloads := fake.downloads
+	*now = now.Add(time.Hour)
+	result, err = svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	product, _ = svc.productRepo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	if fake.downloads != downloads || result.AssetsUnchanged != 3 || len(product.Images) != 2 || product.Images[1].SortOrder != 1 {
+		t.Errorf("result = %+v, images = %+v, want no downloads and the catalog image kept", result, product.Images)
+	}
+
+	// Changed assets replace the old files, which a full sync deletes
+	fake.assets["a/drill.png"] = "\x89PNG\r\n\x1a\n new drill"
+	fake.products[0].Updated = now.Add(time.Minute)
+	delete(fake.products[1].Values, "picture")
+	*now = now.Add(time.Hour)
+	result, err = svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if result.AssetsUploaded != 1 || result.AssetsDeleted != 1 || len(store.files) != 2 || len(assets.assets) != 2 {
+		t.Errorf("result = %+v, files = %d, want the new PNG uploaded and the old one deleted", result, len(store.files))
+	}
+	if _, ok := store.files[strings.TrimPrefix(url, "/assets/")]; ok {
+		t.Error("the old PNG was not deleted")
+	}
+
+	// Stored assets are served by path only
+	body, _, err := svc.OpenAsset(ctx, "pim/"+tenant.ID.String()+"/"+assets.assets[tenant.ID.String()+"/a/drill.png"].Hash+".png")
+	if err != nil {
+		t.Fatalf("OpenAsset() error = %v", err)
+	}
+	body.Close()
+	if _, _, err := svc.OpenAsset(ctx, "pim/../secrets"); !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("OpenAsset() error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := svc.OpenAsset(ctx, "pim/"+uuid.NewString()+"/"+drill.Hash+".png"); !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("OpenAsset() of unknown tenant error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := svc.OpenAsset(ctx, "pim/"+tenant.ID.String()+"/missing.png"); !errors.Is(err, provider.ErrNotFound) {
+		t.Errorf("OpenAsset() of missing file error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSyncFromPIM_KeepsAssetsOfProductsFailedBeforeResume(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New()}
+	svc, fake, stateRepo, now := setupSyncTest(t, tenant, 5)
+	ctx := context.Background()
+	provided, _ := svc.storageProviders.For(ctx, tenant.ID.String())
+	store := provided.(*memStorage)
+
+	fake.attributes = []pim.Attribute{{Code: "picture", Type: "media"}}
+	fake.assets = map[string]string{"a/drill.png": "\x89PNG\r\n\x1a\n drill"}
+	fake.products[0].Values = map[string][]pim.AttributeValue{"picture": {{Data: "a/drill.png"}}}
+	if _, err := svc.SyncFromPIM(ctx, tenant.ID, true); err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+
+	// SKU-1 fails on the first page, then the run is interrupted
+	fake.products[0].Values["picture"] = []pim.AttributeValue{{Data: "a/missing.png"}}
+	fake.products[0].Updated = now.Add(time.Minute)
+	fake.failAt = "fake:4"
+	*now = now.Add(time.Hour)
+	if _, err := svc.SyncFromPIM(ctx, tenant.ID, true); err == nil {
+		t.Fatal("SyncFromPIM() succeeded, want the page error")
+	}
+	if state := stateRepo.states[tenant.ID.String()+"/fake"]; state.Failed != 1 {
+		t.Errorf("state = %+v, want one failed product", state)
+	}
+
+	// The resumed run succeeds but keeps the files and the watermark of SKU-1
+	fake.failAt = ""
+	*now = now.Add(time.Hour)
+	result, err := svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	state := stateRepo.states[tenant.ID.String()+"/fake"]
+	if !result.Resumed || result.AssetsDeleted != 0 || len(store.files) != 1 {
+		t.Errorf("result = %+v, files = %d, want the file of SKU-1 kept", result, len(store.files))
+	}
+	if state.Status != domain.PIMSyncFailed || state.LastError == nil || state.Failed != 0 {
+		t.Errorf("state = %+v, want failed with the earlier failure reported", state)
+	}
+}
+
+func TestSyncFromPIM_ImportsProductModelsAsVariants(t *testing.T) {
+	tenant := &domain.Tenant{ID: uuid.New(), Config: map[string]any{"pim": map[string]any{"variants": true}}}
+	svc, fake, _, _ := setupSyncTest(t, tenant, 4)
+	ctx := context.Background()
+	repo := &fakeVariantRepo{
+		MockProductRepository: NewMockProductRepository(),
+		axes:                  make(map[uuid.UUID][]domain.VariantAxis),
+		values:                make(map[uuid.UUID][]domain.AxisValueEntry),
+	}
+	svc.productRepo = repo
+
+	fake.families = []pim.FamilyVariant{
+		{Code: "drills_color_voltage", Family: "drills", Levels: []pim.VariantLevel{{Axes: []string{"color"}}, {Axes: []string{"voltage"}}}},
+		{Code: "saws_all", Family: "saws", Levels: []pim.VariantLevel{{Axes: []string{"a", "b", "c"}}, {Axes: []string{"d", "e"}}}},
+	}
+	fake.models = []pim.ProductModel{
+		{Code: "DRILL", Family: "drills", FamilyVariant: "drills_color_voltage", Values: map[string][]pim.AttributeValue{
+			"name":        {{Locale: "de_CH", Data: "Bohrer"}},
+			"description": {{Locale: "de_CH", Data: "Akku-Bohrer"}},
+		}},
+		{Code: "DRILL-RED", Parent: "DRILL", Family: "drills", FamilyVariant: "drills_color_voltage"},
+		{Code: "SAW", Family: "saws", FamilyVariant: "saws_all"}, // More axes than a parent can have
+	}
+	variantValues := func(color string, voltage any) map[string][]pim.AttributeValue {
+		values := map[string][]pim.AttributeValue{"color": {{Data: color}}}
+		if voltage != nil {
+			values["voltage"] = []pim.AttributeValue{{Data: voltage}}
+		}
+		return values
+	}
+	for i, values := range []map[string][]pim.AttributeValue{
+		variantValues("red", int64(18)),
+		variantValues("red", int64(12)),
+		variantValues("red", int64(18)), // Same combination as SKU-1
+		variantValues("red", nil),       // No voltage
+	} {
+		fake.products[i].Parent = "DRILL-RED"
+		fake.products[i].Values = values
+	}
+
+	result, err := svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("SyncFromPIM() error = %v", err)
+	}
+	if result.ModelsCreated != 1 || result.ModelsFailed != 1 || result.ProductsCreated != 2 || result.ProductsFailed != 2 {
+		t.Errorf("result = %+v, want one parent, two variants and two failed variants", result)
+	}
+
+	parent, err := repo.GetBySKU(ctx, tenant.ID, "DRILL")
+	if err != nil || parent.ProductType != domain.ProductTypeVariantParent || parent.PIMIdentifier == nil {
+		t.Fatalf("DRILL = %+v, %v, want a variant parent", parent, err)
+	}
+	axes := repo.axes[parent.ID]
+	if len(axes) != 2 || axes[0].AttributeCode != "color" || axes[1].AttributeCode != "voltage" || axes[1].Position != 1 {
+		t.Fatalf("axes = %+v, want color and voltage", axes)
+	}
+	variant, _ := repo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	if variant.ProductType != domain.ProductTypeVariant || variant.ParentID == nil || *variant.ParentID != parent.ID {
+		t.Fatalf("SKU-1 = %+v, want a variant of DRILL", variant)
+	}
+	if variant.Name["de_CH"] != "Bohrer" || variant.Description["de_CH"] != "Akku-Bohrer" {
+		t.Errorf("SKU-1 name, description = %v, %v, want those of the parent", variant.Name, variant.Description)
+	}
+	want := []domain.AxisValueEntry{
+		{VariantID: variant.ID, AxisID: axes[0].ID, AxisAttributeCode: "color", OptionCode: "red"},
+		{VariantID: variant.ID, AxisID: axes[1].ID, AxisAttributeCode: "voltage", OptionCode: "18"},
+	}
+	if !reflect.DeepEqual(repo.values[variant.ID], want) {
+		t.Errorf("axis values = %+v, want %+v", repo.values[variant.ID], want)
+	}
+	for _, sku := range []string{"SKU-3", "SKU-4"} {
+		if _, err := repo.GetBySKU(ctx, tenant.ID, sku); err != domain.ErrProductNotFound {
+			t.Errorf("%s was created, want it to fail", sku)
+		}
+	}
+
+	// The axes are kept; a product without a model is no variant anymore
+	fake.models = fake.models[:2]
+	fake.products = fake.products[:2]
+	fake.products[0].Parent = ""
+	result, err = svc.SyncFromPIM(ctx, tenant.ID, true)
+	if err != nil {
+		t.Fatalf("second SyncFromPIM() error = %v", err)
+	}
+	if result.ModelsUpdated != 1 || result.ProductsUpdated != 2 || result.ProductsFailed != 0 {
+		t.Errorf("second result = %+v", result)
+	}
+	if got := repo.axes[parent.ID]; !reflect.DeepEqual(got, axes) {
+		t.Errorf("axes = %+v, want them unchanged", got)
+	}
+	variant, _ = repo.GetBySKU(ctx, tenant.ID, "SKU-1")
+	if variant.ProductType != domain.ProductTypeSimple || variant.ParentID != nil || len(repo.values[variant.ID]) != 0 {
+		t.Errorf("SKU-1 = %+v with axis values %v, want a simple product", variant, repo.values[variant.ID])
+	}
+}
@@ -103,7 +103,7 @@ func (s *VariantService) CreateVariant(ctx context.Context, tenantID uuid.UUID,
 	}
 
 	// Validate: all axes must have values
-	if err := s.validateAxisValues(axes, req.AxisValues); err != nil {
+	if err := validateAxisValues(axes, req.AxisValues); err != nil {
 		return nil, err
 	}
 
@@ -120,10 +120,10 @@ func (s *VariantService) CreateVariant(ctx context.Context, tenantID uuid.UUID,
 	variant.Status = domain.ProductStatusDraft
 
 	// Inherit from parent (if not explicitly set)
-	variant.Name = s.inheritName(req.Name, parent.Name)
+	variant.Name = inheritName(req.Name, parent.Name)
 	variant.Description = parent.Description // Variants inherit parent description
 	variant.CategoryIDs = parent.CategoryIDs
-	variant.Attributes = s.mergeAttributes(parent.Attributes, req.Attributes)
+	variant.Attributes = mergeAttributes(parent.Attributes, req.Attributes)
 	variant.Images = req.Images
 
 	if err := s.productRepo.Create(ctx, variant); err != nil {
@@ -318,8 +318,9 @@ func (s *VariantService) GetAvailableAxisValues(ctx context.Context, parentID uu
 	return s.productRepo.GetAvailableAxisValues(ctx, parentID, selected)
 }
 
-// validateAxisValues checks that all required axes have values
-func (s *VariantService) validateAxisValues(axes []domain.VariantAxis, values map[string]string) error {
+// validateAxisValues checks that all required axes have values. It also
+// applies to the variants synced from PIM.
+func validateAxisValues(axes []domain.VariantAxis, values map[string]string) error {
 	for _, axis := range axes {
 		if _, ok := values[axis.AttributeCode]; !ok {
 			return fmt.Errorf("missing value for required axis: %s", axis.AttributeCode)
@@ -344,7 +345,7 @@ func (s *VariantService) validateAxisValues(axes []domain.VariantAxis, values ma
 }
 
 // inheritName inherits name from parent if variant name is empty
-func (s *VariantService) inheritName(variantName, parentName map[string]string) map[string]string {
+func inheritName(variantName, parentName map[string]string) map[string]string {
 	if len(variantName) > 0 {
 		return variantName
 	}
@@ -357,29 +358,31 @@ func (s *VariantService) inheritName(variantName, parentName map[string]string)
 }
 
 // inheritMap inherits map values from parent if empty
-func (s *VariantService) inheritMap(variant, parent map[string]string) map[string]string {
+func inheritMap(variant, parent map[string]string) map[string]string {
 	if len(variant) > 0 {
 		return variant
 	}
 	return parent
 }
 
-// mergeAttributes merges parent and variant attributes (variant overrides)
-func (s *VariantService) mergeAttributes(parentAttrs, variantAttrs []domain.ProductAttribute) []domain.ProductAttribute {
+// mergeAttributes merges parent and variant attributes (variant overrides),
+// keeping the order of the parent attributes
+func mergeAttributes(parentAttrs, variantAttrs []domain.ProductAttribute) []domain.ProductAttribute {
 	// Start with parent attributes
-	merged := make(map[string]domain.ProductAttribute)
+	result := make([]domain.ProductAttribute, 0, len(parentAttrs)+len(variantAttrs))
+	index := make(map[string]int)
 	for _, attr := range parentAttrs {
-		merged[attr.Key] = attr
+		index[attr.Key] = len(result)
+		result = append(result, attr)
 	}
 
 	// Override with variant attributes
 	for _, attr := range variantAttrs {
-		merged[attr.Key] = attr
-	}
-
-	// Convert back to slice
-	result := make([]domain.ProductAttribute, 0, len(merged))
-	for _, attr := range merged {
+		if i, ok := index[attr.Key]; ok {
+			result[i] = attr
+			continue
+		}
+		index[attr.Key] = len(result)
 		result = append(result, attr)
 	}
 
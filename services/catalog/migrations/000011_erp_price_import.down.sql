@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS price_import_runs;
+
+DROP INDEX IF EXISTS idx_prices_erp;
+ALTER TABLE prices DROP CONSTRAINT IF EXISTS check_price_source;
+ALTER TABLE prices DROP COLUMN IF EXISTS source;
+
+COMMIT;
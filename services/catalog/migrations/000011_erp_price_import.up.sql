@@ -0,0 +1,39 @@
+-- 000011: Import tier and contract prices from the ERP
+-- ERP prices are marked by their source, so the import only touches its own rows
+
+BEGIN;
+
+-- 1. Price source ('manual' | 'erp')
+ALTER TABLE prices ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'manual';
+ALTER TABLE prices ADD CONSTRAINT check_price_source CHECK (source IN ('manual', 'erp'));
+
+CREATE INDEX idx_prices_erp ON prices (tenant_id, customer_group_id)
+  WHERE source = 'erp' AND deleted_at IS NULL;
+
+-- 2. Import runs with their counts
+CREATE TABLE price_import_runs (
+  id UUID PRIMARY KEY,
+  tenant_id UUID NOT NULL,
+  trigger VARCHAR(20) NOT NULL,       -- 'scheduled' | 'manual'
+  dry_run BOOLEAN NOT NULL DEFAULT false,
+  skus TEXT[],                        -- NULL = all products
+  status VARCHAR(20) NOT NULL,        -- 'running' | 'completed' | 'failed'
+  created INT NOT NULL DEFAULT 0,
+  updated INT NOT NULL DEFAULT 0,
+  expired INT NOT NULL DEFAULT 0,
+  unchanged INT NOT NULL DEFAULT 0,
+  error TEXT,
+  started_at TIMESTAMPTZ NOT NULL,
+  completed_at TIMESTAMPTZ,
+
+  CONSTRAINT check_price_import_trigger CHECK (trigger IN ('scheduled', 'manual')),
+  CONSTRAINT check_price_import_status CHECK (status IN ('running', 'completed', 'failed'))
+);
+
+CREATE INDEX idx_price_import_runs_tenant ON price_import_runs (tenant_id, started_at DESC);
+
+-- Only one import writes the prices of a tenant at a time
+CREATE UNIQUE INDEX idx_price_import_runs_running ON price_import_runs (tenant_id)
+  WHERE status = 'running' AND NOT dry_run;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS pim_sync_states;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- 000012: Persisted PIM sync state per tenant and provider
+-- Incremental syncs read the products updated since the watermark of the last
+-- successful run; interrupted runs resume from their last processed page
+
+BEGIN;
+
+CREATE TABLE pim_sync_states (
+  tenant_id UUID NOT NULL,
+  provider VARCHAR(100) NOT NULL,     -- PIM provider name, e.g. 'akeneo'
+  watermark TIMESTAMPTZ,              -- Start of the last successful run; NULL = never synced
+  status VARCHAR(20) NOT NULL,        -- 'idle' | 'running' | 'failed'
+  run_full BOOLEAN NOT NULL DEFAULT false,
+  run_since TIMESTAMPTZ,              -- UpdatedSince of the current or interrupted run
+  run_started_at TIMESTAMPTZ,
+  cursor TEXT,                        -- Cursor after the last processed page; NULL = first page
+  pages INT NOT NULL DEFAULT 0,       -- Pages processed by the current or interrupted run
+  last_error TEXT,
+  last_success_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  PRIMARY KEY (tenant_id, provider),
+  CONSTRAINT check_pim_sync_status CHECK (status IN ('idle', 'running', 'failed'))
+);
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- 000013: PIM assets copied to object storage
+-- Media of synced products are stored once per content hash; a product image
+-- links to the stored file of its PIM asset code
+
+BEGIN;
+
+CREATE TABLE pim_assets (
+  tenant_id UUID NOT NULL,
+  code VARCHAR(500) NOT NULL,         -- Asset code of the PIM, e.g. the Akeneo media file code
+  hash CHAR(64) NOT NULL,             -- SHA-256 of the content
+  path VARCHAR(500) NOT NULL,         -- Path in object storage, derived from the hash
+  content_type VARCHAR(100) NOT NULL,
+  size BIGINT NOT NULL,
+  synced_at TIMESTAMPTZ NOT NULL,     -- Last download from the PIM
+  seen_at TIMESTAMPTZ NOT NULL,       -- Last sync of a product using the asset
+
+  PRIMARY KEY (tenant_id, code)
+);
+
+CREATE INDEX idx_pim_assets_path ON pim_assets(tenant_id, path);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE pim_sync_states DROP COLUMN IF EXISTS failed;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- 000014: Failures of the current or interrupted PIM sync run
+-- A resumed run must not advance the watermark or remove assets while
+-- products of its earlier pages failed
+
+BEGIN;
+
+ALTER TABLE pim_sync_states
+  ADD COLUMN failed INT NOT NULL DEFAULT 0; -- Products and models that failed on the processed pages
+
+COMMIT;
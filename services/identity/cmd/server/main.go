@@ -11,9 +11,21 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/admin"
+	_ "github.com/gondolia/gondolia/provider/auth/noop" // Register noop SSO provider
+	"github.com/gondolia/gondolia/provider/erp"
+	_ "github.com/gondolia/gondolia/provider/erp/idoc"   // Register SAP IDoc provider
+	_ "github.com/gondolia/gondolia/provider/erp/memory" // Register memory provider
+	_ "github.com/gondolia/gondolia/provider/erp/noop"   // Register noop provider
+	_ "github.com/gondolia/gondolia/provider/erp/odata"  // Register OData provider
+	"github.com/gondolia/gondolia/provider/replay"
+	"github.com/gondolia/gondolia/provider/resilience"
+	"github.com/gondolia/gondolia/provider/tracing"
 	"github.com/gondolia/gondolia/services/identity/internal/auth"
 	"github.com/gondolia/gondolia/services/identity/internal/config"
 	"github.com/gondolia/gondolia/services/identity/internal/domain"
@@ -51,6 +63,7 @@ func main() {
 	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
 	passwordResetRepo := postgres.NewPasswordResetRepository(db)
 	authLogRepo := postgres.NewAuthLogRepository(db)
+	companySyncRepo := postgres.NewCompanySyncRepository(db)
 
 	// Initialize JWT manager
 	tokenConfig := auth.TokenConfig{
@@ -62,6 +75,39 @@ func main() {
 	}
 	jwtManager := auth.NewJWTManager(tokenConfig)
 
+	// Initialize provider resolver (per-tenant providers from Tenant.Config["providers"]
+	// and the provider config file)
+	lookup := tenantConfigLookup(tenantRepo)
+	providerFile := openProviderConfig(cfg.ProviderConfigFile, logger)
+	if providerFile != nil {
+		lookup = providerFile.Lookup(lookup)
+	}
+	// Fixtures recorded with PROVIDER_RECORD_DIR can be served by the "replay" provider
+	replay.Register("auth")
+	replay.Register("erp")
+	resolver := provider.NewResolver(lookup)
+	if cfg.ProviderRecordDir != "" {
+		// Record provider calls as fixtures for the replay provider; innermost, so retries are recorded too
+		logger.Warn("Recording provider calls", zap.String("dir", cfg.ProviderRecordDir))
+		resolver.Use(replay.Decorator(cfg.ProviderRecordDir))
+	}
+	resolver.Use(resilience.Decorator(nil), tracing.Decorator())
+	resolver.SetDefault("auth", provider.Selection{Name: "noop"})
+	resolver.SetDefault("erp", erpSelection(cfg))
+	if providerFile != nil {
+		providerFile.Apply(resolver)
+	}
+
+	// Warm up the default providers; readiness reports unavailable until they are started
+	warmUpCtx, stopWarmUp := context.WithCancel(ctx)
+	defer stopWarmUp()
+	go warmUpProviders(warmUpCtx, resolver, logger)
+
+	// Rebuild the affected providers when the provider config file changes
+	if providerFile != nil {
+		go providerFile.Watch(warmUpCtx, resolver, cfg.ProviderConfigReload, logProviderReload(logger))
+	}
+
 	// Initialize services
 	authService := service.NewAuthService(
 		userRepo,
@@ -76,12 +122,36 @@ func main() {
 	userService := service.NewUserService(userRepo, userCompanyRepo, roleRepo, authLogRepo)
 	companyService := service.NewCompanyService(companyRepo, userCompanyRepo, roleRepo, userRepo)
 	roleService := service.NewRoleService(roleRepo)
+	companySyncer := service.NewCompanySyncer(companyRepo, companySyncRepo, tenantRepo,
+		provider.NewSource[erp.ERPProvider](resolver, "erp"),
+		service.CompanySyncConfig{
+			Interval:  cfg.ERPCompanySyncInterval,
+			BatchSize: cfg.ERPCompanySyncBatchSize,
+			Cadence:   cfg.ERPCompanySyncCadence,
+			Lease:     5 * time.Minute,
+		},
+	)
+
+	// Sync company master data from the ERP in the background
+	syncCtx, stopSync := context.WithCancel(ctx)
+	defer stopSync()
+	syncDone := make(chan struct{})
+	go func() {
+		defer close(syncDone)
+		companySyncer.Run(syncCtx, func(err error) {
+			logger.Error("ERP company sync failed", zap.Error(err))
+		})
+	}()
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, userService, cfg)
 	userHandler := handler.NewUserHandler(userService)
 	companyHandler := handler.NewCompanyHandler(companyService)
 	roleHandler := handler.NewRoleHandler(roleService)
+	companySyncHandler := handler.NewCompanySyncHandler(companySyncer)
+	providerAdminHandler := admin.NewHandler(resolver, func(c *gin.Context) (string, bool) {
+		return middleware.GetTenantID(c).String(), true
+	})
 
 	// Initialize HTTP server (REST API)
 	gin.SetMode(gin.ReleaseMode)
@@ -95,7 +165,7 @@ func main() {
 
 	// Health endpoints
 	router.GET("/health/live", handler.LivenessHandler)
-	router.GET("/health/ready", handler.ReadinessHandler)
+	router.GET("/health/ready", handler.ReadinessHandler(resolver.Ready))
 	router.GET("/metrics", handler.MetricsHandler)
 
 	// API routes
@@ -152,6 +222,9 @@ func main() {
 		companies.POST("/:id/users", middleware.RequirePermission(domain.PermManageUsersAndRoles), companyHandler.AddUser)
 		companies.PUT("/:id/users/:userId", middleware.RequirePermission(domain.PermManageUsersAndRoles), companyHandler.UpdateUserRole)
 		companies.DELETE("/:id/users/:userId", middleware.RequirePermission(domain.PermManageUsersAndRoles), companyHandler.RemoveUser)
+		companies.GET("/:id/addresses", companySyncHandler.ListAddresses)
+		companies.GET("/:id/changes", middleware.RequirePermission(domain.PermManageCompany), companySyncHandler.ListChanges)
+		companies.POST("/:id/erp-sync", middleware.RequirePermission(domain.PermManageCompany), companySyncHandler.Sync)
 	}
 
 	// Role endpoints
@@ -164,6 +237,15 @@ func main() {
 		roles.DELETE("/:id", middleware.RequirePermission(domain.PermManageUsersAndRoles), roleHandler.Delete)
 	}
 
+	// Provider admin endpoints (catalog, active providers, health probes)
+	providerAdminHandler.RegisterRoutes(protected.Group("/admin", middleware.RequirePermission(domain.PermManageSettings)))
+
+	// Operator endpoints (providers of all tenants); not mounted without a token
+	if cfg.OperatorToken != "" {
+		operator := router.Group("/operator", admin.RequireOperator(cfg.OperatorToken))
+		providerAdminHandler.RegisterOperatorRoutes(operator)
+	}
+
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.HTTPPort),
 		Handler: router,
@@ -207,5 +289,83 @@ func main() {
 		logger.Error("HTTP server shutdown error", zap.Error(err))
 	}
 
+	// Stop the background sync before closing the providers it uses; claimed
+	// companies are picked up again once their lease expires
+	stopSync()
+	<-syncDone
+
+	// Close providers once no requests are in flight, flushing their buffers and connections
+	stopWarmUp()
+	if err := resolver.Close(shutdownCtx); err != nil {
+		logger.Error("Provider shutdown error", zap.Error(err))
+	}
+
 	logger.Info("Servers stopped")
 }
+
+// warmUpProviders starts the default providers so that connections are open
+// before the service reports ready, retrying until they start or ctx ends
+func warmUpProviders(ctx context.Context, resolver *provider.Resolver, logger *zap.Logger) {
+	for {
+		err := resolver.Start(ctx)
+		if err == nil {
+			logger.Info("Providers started")
+			return
+		}
+		logger.Warn("Provider warm-up failed, retrying", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// erpSelection returns the default ERP provider from the environment
+func erpSelection(cfg *config.Config) provider.Selection {
+	providerType := cfg.ERPProvider
+	if providerType == "" || providerType == "mock" {
+		providerType = "noop"
+	}
+	return provider.Selection{Name: providerType}
+}
+
+// openProviderConfig loads the provider config file, if one is configured
+func openProviderConfig(path string, logger *zap.Logger) *provider.ConfigFile {
+	if path == "" {
+		return nil
+	}
+	file, err := provider.OpenConfigFile(path)
+	if err != nil {
+		logger.Fatal("Failed to load provider config", zap.Error(err))
+	}
+	logger.Info("Loaded provider config", zap.String("path", path))
+	return file
+}
+
+// logProviderReload logs hot reloads of the provider config file
+func logProviderReload(logger *zap.Logger) func(changes []string, err error) {
+	return func(changes []string, err error) {
+		if err != nil {
+			logger.Error("Provider config reload failed, keeping current config", zap.Error(err))
+			return
+		}
+		logger.Info("Provider config reloaded", zap.Strings("changes", changes))
+	}
+}
+
+// tenantConfigLookup reads provider selections from the tenant's config
+func tenantConfigLookup(tenantRepo *postgres.TenantRepository) provider.TenantConfigLookup {
+	return func(ctx context.Context, tenantID string) (map[string]any, error) {
+		id, err := uuid.Parse(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		tenant, err := tenantRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return tenant.Config, nil
+	}
+}
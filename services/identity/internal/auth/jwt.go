@@ -42,6 +42,9 @@ type AccessTokenClaims struct {
 	RoleID      uuid.UUID `json:"role_id"`
 	RoleName    string    `json:"role_name"`
 
+	// ERP customer number of the current company (orders are placed for it)
+	SAPCompanyNumber string `json:"sap_company_number,omitempty"`
+
 	// Permissions (flattened for fast checks)
 	Permissions []string `json:"permissions"`
 
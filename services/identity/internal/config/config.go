@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -37,6 +38,25 @@ type Config struct {
 
 	// Security
 	SecureCookies bool
+
+	// ERP Provider
+	ERPProvider string
+
+	// Company sync (master data and addresses from the ERP)
+	ERPCompanySyncInterval  time.Duration
+	ERPCompanySyncBatchSize int
+	ERPCompanySyncCadence   time.Duration
+
+	// Operator routes (across tenants) require this token in the
+	// X-Operator-Token header; without it they are not mounted
+	OperatorToken string
+
+	// Provider config file (YAML); its defaults override the built-in provider defaults
+	ProviderConfigFile   string
+	ProviderConfigReload time.Duration
+
+	// Directory to record provider calls to, as fixtures for the replay provider
+	ProviderRecordDir string
 }
 
 func Load() (*Config, error) {
@@ -58,6 +78,17 @@ func Load() (*Config, error) {
 		JWTRefreshTokenExpiry: getDurationEnv("JWT_REFRESH_TOKEN_EXPIRY", 7*24*time.Hour),
 		AllowedOrigins:        getSliceEnv("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 		SecureCookies:         getBoolEnv("SECURE_COOKIES", true),
+		ERPProvider:           getEnv("ERP_PROVIDER", "noop"),
+		OperatorToken:         getEnv("OPERATOR_TOKEN", ""),
+		ProviderConfigFile:    getEnv("PROVIDER_CONFIG_FILE", ""),
+		ProviderConfigReload:  getDurationEnv("PROVIDER_CONFIG_RELOAD", 10*time.Second),
+		ProviderRecordDir:     getEnv("PROVIDER_RECORD_DIR", ""),
+
+		// The background sync is off by default, so master data maintained
+		// by hand is not overwritten until a tenant's ERP is set up
+		ERPCompanySyncInterval:  getDurationEnv("ERP_COMPANY_SYNC_INTERVAL", 0),
+		ERPCompanySyncBatchSize: getIntEnv("ERP_COMPANY_SYNC_BATCH_SIZE", 50),
+		ERPCompanySyncCadence:   getDurationEnv("ERP_COMPANY_SYNC_CADENCE", 24*time.Hour),
 	}
 
 	if cfg.JWTAccessSecret == "" {
@@ -95,6 +126,15 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
 func getSliceEnv(key string, defaultValue []string) []string {
 	if value, exists := os.LookupEnv(key); exists && value != "" {
 		var result []string
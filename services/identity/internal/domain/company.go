@@ -26,6 +26,11 @@ type Company struct {
 	Email       *string `json:"email,omitempty"`
 	Currency    string  `json:"currency"`
 
+	// Terms
+	TaxID        *string  `json:"tax_id,omitempty"`
+	PaymentTerms *string  `json:"payment_terms,omitempty"`
+	CreditLimit  *float64 `json:"credit_limit,omitempty"` // nil means unlimited
+
 	// Address
 	Street      *string `json:"street,omitempty"`
 	HouseNumber *string `json:"house_number,omitempty"`
@@ -48,6 +53,11 @@ type Company struct {
 	CustomPrimaryColor   *string `json:"custom_primary_color,omitempty"`
 	CustomSecondaryColor *string `json:"custom_secondary_color,omitempty"`
 
+	// ERP sync
+	ERPProtectedFields []string   `json:"erp_protected_fields,omitempty"` // Fields the ERP sync must not overwrite
+	ERPSyncedAt        *time.Time `json:"erp_synced_at,omitempty"`
+	ERPSyncError       *string    `json:"erp_sync_error,omitempty"`
+
 	// Status
 	IsActive  bool       `json:"is_active"`
 	CreatedAt time.Time  `json:"created_at"`
@@ -128,6 +138,11 @@ type UpdateCompanyRequest struct {
 	Description *string `json:"description,omitempty"`
 	Email       *string `json:"email,omitempty"`
 
+	// Terms
+	TaxID        *string  `json:"tax_id,omitempty"`
+	PaymentTerms *string  `json:"payment_terms,omitempty"`
+	CreditLimit  *float64 `json:"credit_limit,omitempty"`
+
 	// Address
 	Street      *string `json:"street,omitempty"`
 	HouseNumber *string `json:"house_number,omitempty"`
@@ -149,6 +164,9 @@ type UpdateCompanyRequest struct {
 	CustomPrimaryColor   *string `json:"custom_primary_color,omitempty"`
 	CustomSecondaryColor *string `json:"custom_secondary_color,omitempty"`
 
+	// ERP sync
+	ERPProtectedFields []string `json:"erp_protected_fields,omitempty"`
+
 	IsActive *bool `json:"is_active,omitempty"`
 }
 
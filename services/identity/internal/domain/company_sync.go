@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangeSourceERP is the source of company changes made by the ERP sync
+const ChangeSourceERP = "erp"
+
+// Company fields maintained by the ERP sync. The names match the JSON fields
+// of Company and are used in ERPProtectedFields and the change log.
+const (
+	CompanyFieldName             = "name"
+	CompanyFieldTaxID            = "tax_id"
+	CompanyFieldPaymentTerms     = "payment_terms"
+	CompanyFieldCurrency         = "currency"
+	CompanyFieldCreditLimit      = "credit_limit"
+	CompanyFieldAddress          = "address" // Street, house number, ZIP, city and country
+	CompanyFieldAddresses        = "addresses"
+	CompanyFieldSAPCustomerGroup = "sap_customer_group"
+	CompanyFieldSAPShippingPlant = "sap_shipping_plant"
+	CompanyFieldSAPOffice        = "sap_office"
+	CompanyFieldSAPPaymentType   = "sap_payment_type"
+	CompanyFieldSAPPriceGroup    = "sap_price_group"
+)
+
+// ERPCompanyFields lists the fields the ERP sync maintains
+var ERPCompanyFields = []string{
+	CompanyFieldName,
+	CompanyFieldTaxID,
+	CompanyFieldPaymentTerms,
+	CompanyFieldCurrency,
+	CompanyFieldCreditLimit,
+	CompanyFieldAddress,
+	CompanyFieldAddresses,
+	CompanyFieldSAPCustomerGroup,
+	CompanyFieldSAPShippingPlant,
+	CompanyFieldSAPOffice,
+	CompanyFieldSAPPaymentType,
+	CompanyFieldSAPPriceGroup,
+}
+
+// IsERPCompanyField checks if the ERP sync maintains the field
+func IsERPCompanyField(field string) bool {
+	return slices.Contains(ERPCompanyFields, field)
+}
+
+// IsERPProtected checks if the field is authoritative in Gondolia and must
+// not be overwritten by the ERP sync
+func (c *Company) IsERPProtected(field string) bool {
+	return slices.Contains(c.ERPProtectedFields, field)
+}
+
+// CompanyAddress is an address of a company as maintained in the ERP
+type CompanyAddress struct {
+	ID           uuid.UUID `json:"id"`
+	CompanyID    uuid.UUID `json:"company_id"`
+	ERPAddressID string    `json:"erp_address_id"`
+	Name         string    `json:"name,omitempty"`
+	Street       string    `json:"street,omitempty"`
+	ZIP          string    `json:"zip,omitempty"`
+	City         string    `json:"city,omitempty"`
+	Country      string    `json:"country,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CompanyChange is an entry in the change log of a company
+type CompanyChange struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	CompanyID uuid.UUID `json:"company_id"`
+	Source    string    `json:"source"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value,omitempty"`
+	NewValue  *string   `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CompanySyncResult is the outcome of syncing a company from the ERP
+type CompanySyncResult struct {
+	Company   *Company         `json:"company"`
+	Addresses []CompanyAddress `json:"addresses"`
+	Changes   []CompanyChange  `json:"changes"`
+	Skipped   []string         `json:"skipped,omitempty"` // Protected fields the ERP has other values for
+}
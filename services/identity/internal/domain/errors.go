@@ -19,6 +19,8 @@ var (
 	ErrCompanyNotFound      = errors.New("company not found")
 	ErrCompanyNotActive     = errors.New("company is not active")
 	ErrCompanyAlreadyExists = errors.New("company with this SAP number already exists")
+	ErrUnknownCompanyField  = errors.New("field is not maintained by the ERP sync")
+	ErrCompanySyncFailed    = errors.New("company sync from ERP failed")
 
 	// Role errors
 	ErrRoleNotFound       = errors.New("role not found")
@@ -66,6 +68,7 @@ func IsNotFoundError(err error) bool {
 func IsValidationError(err error) bool {
 	return errors.Is(err, ErrUserAlreadyExists) ||
 		errors.Is(err, ErrCompanyAlreadyExists) ||
+		errors.Is(err, ErrUnknownCompanyField) ||
 		errors.Is(err, ErrRoleAlreadyExists) ||
 		errors.Is(err, ErrUserAlreadyInCompany) ||
 		errors.Is(err, ErrPasswordTooWeak)
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -165,6 +166,8 @@ func (h *CompanyHandler) Update(c *gin.Context) {
 		status := http.StatusInternalServerError
 		if err == domain.ErrCompanyNotFound {
 			status = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrUnknownCompanyField) {
+			status = http.StatusBadRequest
 		}
 
 		c.JSON(status, gin.H{
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/services/identity/internal/domain"
+	"github.com/gondolia/gondolia/services/identity/internal/middleware"
+	"github.com/gondolia/gondolia/services/identity/internal/service"
+)
+
+// CompanySyncHandler handles the company master data sync from the ERP
+type CompanySyncHandler struct {
+	syncer *service.CompanySyncer
+}
+
+// NewCompanySyncHandler creates a new company sync handler
+func NewCompanySyncHandler(syncer *service.CompanySyncer) *CompanySyncHandler {
+	return &CompanySyncHandler{syncer: syncer}
+}
+
+// Sync syncs a company from the ERP right away
+func (h *CompanySyncHandler) Sync(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_ID",
+				"message": "invalid company ID",
+			},
+		})
+		return
+	}
+
+	result, err := h.syncer.Sync(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrCompanyNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrCompanySyncFailed) {
+			status = http.StatusBadGateway
+		}
+
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    "ERP_SYNC_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAddresses returns the ERP addresses of a company
+func (h *CompanySyncHandler) ListAddresses(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_ID",
+				"message": "invalid company ID",
+			},
+		})
+		return
+	}
+
+	addresses, err := h.syncer.ListAddresses(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrCompanyNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    "LIST_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": addresses})
+}
+
+// ListChanges returns the change log of a company
+func (h *CompanySyncHandler) ListChanges(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_ID",
+				"message": "invalid company ID",
+			},
+		})
+		return
+	}
+
+	var query struct {
+		Limit  int `form:"limit,default=20"`
+		Offset int `form:"offset,default=0"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	changes, total, err := h.syncer.ListChanges(c.Request.Context(), middleware.GetTenantID(c), id, query.Limit, query.Offset)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrCompanyNotFound) {
+			status = http.StatusNotFound
+		}
+
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    "LIST_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   changes,
+		"total":  total,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	})
+}
@@ -13,12 +13,22 @@ func LivenessHandler(c *gin.Context) {
 	})
 }
 
-// ReadinessHandler returns OK if the service is ready to receive traffic
-func ReadinessHandler(c *gin.Context) {
-	// TODO: Check database connection, redis, etc.
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ready",
-	})
+// ReadinessHandler returns OK if the service is ready to receive traffic and reports unavailable
+// until ready, e.g. the provider warm-up, returns nil
+func ReadinessHandler(ready func() error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// TODO: Check database connection, redis, etc.
+		if err := ready(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "not ready",
+				"error":  err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ready",
+		})
+	}
 }
 
 // MetricsHandler returns Prometheus metrics
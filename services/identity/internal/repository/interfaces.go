@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -40,6 +41,25 @@ type CompanyRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error // Soft delete
 }
 
+// CompanySyncRepository defines the interface for synchronizing company
+// master data from the ERP
+type CompanySyncRepository interface {
+	// ClaimDue leases up to limit companies whose ERP sync is due, so that
+	// concurrent workers do not sync the same company
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]uuid.UUID, error)
+
+	ListAddresses(ctx context.Context, companyID uuid.UUID) ([]domain.CompanyAddress, error)
+	ListChanges(ctx context.Context, companyID uuid.UUID, limit, offset int) ([]domain.CompanyChange, int, error)
+
+	// Save stores the ERP-maintained fields of the company, replaces its
+	// addresses and adds the changes to its change log, all in one
+	// transaction. The company is synced again at nextSyncAt.
+	Save(ctx context.Context, company *domain.Company, addresses []domain.CompanyAddress, changes []domain.CompanyChange, nextSyncAt time.Time) error
+
+	// RecordFailure records a failed sync; the company is synced again at nextSyncAt
+	RecordFailure(ctx context.Context, companyID uuid.UUID, reason string, nextSyncAt time.Time) error
+}
+
 // RoleRepository defines the interface for role data access
 type RoleRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error)
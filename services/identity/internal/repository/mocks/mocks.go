@@ -603,3 +603,84 @@ func (m *MockTenantRepository) AddTenant(tenant *domain.Tenant) {
 	defer m.mu.Unlock()
 	m.tenants[tenant.ID] = tenant
 }
+
+// MockCompanySyncRepository is a mock implementation of CompanySyncRepository
+type MockCompanySyncRepository struct {
+	mu         sync.RWMutex
+	due        []uuid.UUID
+	addresses  map[uuid.UUID][]domain.CompanyAddress
+	changes    map[uuid.UUID][]domain.CompanyChange
+	errors     map[uuid.UUID]string
+	nextSyncAt map[uuid.UUID]time.Time
+}
+
+func NewMockCompanySyncRepository() *MockCompanySyncRepository {
+	return &MockCompanySyncRepository{
+		addresses:  make(map[uuid.UUID][]domain.CompanyAddress),
+		changes:    make(map[uuid.UUID][]domain.CompanyChange),
+		errors:     make(map[uuid.UUID]string),
+		nextSyncAt: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (m *MockCompanySyncRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	due := m.due
+	m.due = nil
+	return due, nil
+}
+
+func (m *MockCompanySyncRepository) ListAddresses(ctx context.Context, companyID uuid.UUID) ([]domain.CompanyAddress, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]domain.CompanyAddress{}, m.addresses[companyID]...), nil
+}
+
+func (m *MockCompanySyncRepository) ListChanges(ctx context.Context, companyID uuid.UUID, limit, offset int) ([]domain.CompanyChange, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	changes := m.changes[companyID]
+	return changes, len(changes), nil
+}
+
+func (m *MockCompanySyncRepository) Save(ctx context.Context, company *domain.Company, addresses []domain.CompanyAddress, changes []domain.CompanyChange, nextSyncAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if addresses != nil {
+		m.addresses[company.ID] = addresses
+	}
+	m.changes[company.ID] = append(m.changes[company.ID], changes...)
+	delete(m.errors, company.ID)
+	m.nextSyncAt[company.ID] = nextSyncAt
+	return nil
+}
+
+func (m *MockCompanySyncRepository) RecordFailure(ctx context.Context, companyID uuid.UUID, reason string, nextSyncAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[companyID] = reason
+	m.nextSyncAt[companyID] = nextSyncAt
+	return nil
+}
+
+// AddDue marks companies as due for a sync
+func (m *MockCompanySyncRepository) AddDue(ids ...uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.due = append(m.due, ids...)
+}
+
+// SyncError returns the recorded error of the last failed sync
+func (m *MockCompanySyncRepository) SyncError(companyID uuid.UUID) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.errors[companyID]
+}
+
+// NextSyncAt returns when the company is synced next
+func (m *MockCompanySyncRepository) NextSyncAt(companyID uuid.UUID) time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.nextSyncAt[companyID]
+}
@@ -26,10 +26,10 @@ func (r *CompanyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	query := `
 		SELECT id, tenant_id, sap_company_number, sap_customer_group, sap_shipping_plant,
 		       sap_office, sap_payment_type, sap_price_group, name, description, email,
-		       currency, street, house_number, zip, city, country, phone, fax, url,
-		       config, desired_delivery_days, default_shipping_note, disable_order_feature,
-		       custom_primary_color, custom_secondary_color, is_active,
-		       created_at, updated_at, deleted_at
+		       currency, tax_id, payment_terms, credit_limit, street, house_number, zip, city, country,
+		       phone, fax, url, config, desired_delivery_days, default_shipping_note, disable_order_feature,
+		       custom_primary_color, custom_secondary_color, erp_protected_fields, erp_synced_at, erp_sync_error,
+		       is_active, created_at, updated_at, deleted_at
 		FROM companies
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -41,10 +41,10 @@ func (r *CompanyRepository) GetBySAPNumber(ctx context.Context, tenantID uuid.UU
 	query := `
 		SELECT id, tenant_id, sap_company_number, sap_customer_group, sap_shipping_plant,
 		       sap_office, sap_payment_type, sap_price_group, name, description, email,
-		       currency, street, house_number, zip, city, country, phone, fax, url,
-		       config, desired_delivery_days, default_shipping_note, disable_order_feature,
-		       custom_primary_color, custom_secondary_color, is_active,
-		       created_at, updated_at, deleted_at
+		       currency, tax_id, payment_terms, credit_limit, street, house_number, zip, city, country,
+		       phone, fax, url, config, desired_delivery_days, default_shipping_note, disable_order_feature,
+		       custom_primary_color, custom_secondary_color, erp_protected_fields, erp_synced_at, erp_sync_error,
+		       is_active, created_at, updated_at, deleted_at
 		FROM companies
 		WHERE tenant_id = $1 AND sap_company_number = $2 AND deleted_at IS NULL
 	`
@@ -94,10 +94,10 @@ func (r *CompanyRepository) List(ctx context.Context, filter domain.CompanyFilte
 	query := fmt.Sprintf(`
 		SELECT id, tenant_id, sap_company_number, sap_customer_group, sap_shipping_plant,
 		       sap_office, sap_payment_type, sap_price_group, name, description, email,
-		       currency, street, house_number, zip, city, country, phone, fax, url,
-		       config, desired_delivery_days, default_shipping_note, disable_order_feature,
-		       custom_primary_color, custom_secondary_color, is_active,
-		       created_at, updated_at, deleted_at
+		       currency, tax_id, payment_terms, credit_limit, street, house_number, zip, city, country,
+		       phone, fax, url, config, desired_delivery_days, default_shipping_note, disable_order_feature,
+		       custom_primary_color, custom_secondary_color, erp_protected_fields, erp_synced_at, erp_sync_error,
+		       is_active, created_at, updated_at, deleted_at
 		FROM companies
 		WHERE %s
 		ORDER BY name
@@ -129,12 +129,12 @@ func (r *CompanyRepository) Create(ctx context.Context, company *domain.Company)
 		INSERT INTO companies (
 			id, tenant_id, sap_company_number, sap_customer_group, sap_shipping_plant,
 			sap_office, sap_payment_type, sap_price_group, name, description, email,
-			currency, street, house_number, zip, city, country, phone, fax, url,
-			config, desired_delivery_days, default_shipping_note, disable_order_feature,
-			custom_primary_color, custom_secondary_color, is_active, created_at, updated_at
+			currency, tax_id, payment_terms, credit_limit, street, house_number, zip, city, country,
+			phone, fax, url, config, desired_delivery_days, default_shipping_note, disable_order_feature,
+			custom_primary_color, custom_secondary_color, erp_protected_fields, is_active, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
-			$18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29
+			$18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33
 		)
 	`
 
@@ -147,11 +147,12 @@ func (r *CompanyRepository) Create(ctx context.Context, company *domain.Company)
 		company.ID, company.TenantID, company.SAPCompanyNumber, company.SAPCustomerGroup,
 		company.SAPShippingPlant, company.SAPOffice, company.SAPPaymentType, company.SAPPriceGroup,
 		company.Name, company.Description, company.Email, company.Currency,
+		company.TaxID, company.PaymentTerms, company.CreditLimit,
 		company.Street, company.HouseNumber, company.ZIP, company.City, company.Country,
 		company.Phone, company.Fax, company.URL, configJSON,
 		pq.Array(company.DesiredDeliveryDays), company.DefaultShippingNote, company.DisableOrderFeature,
-		company.CustomPrimaryColor, company.CustomSecondaryColor, company.IsActive,
-		company.CreatedAt, company.UpdatedAt,
+		company.CustomPrimaryColor, company.CustomSecondaryColor, pq.Array(company.ERPProtectedFields),
+		company.IsActive, company.CreatedAt, company.UpdatedAt,
 	)
 	return err
 }
@@ -165,6 +166,7 @@ func (r *CompanyRepository) Update(ctx context.Context, company *domain.Company)
 			city = $14, country = $15, phone = $16, fax = $17, url = $18, config = $19,
 			desired_delivery_days = $20, default_shipping_note = $21, disable_order_feature = $22,
 			custom_primary_color = $23, custom_secondary_color = $24, is_active = $25,
+			tax_id = $26, payment_terms = $27, credit_limit = $28, erp_protected_fields = $29,
 			updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -181,6 +183,7 @@ func (r *CompanyRepository) Update(ctx context.Context, company *domain.Company)
 		company.City, company.Country, company.Phone, company.Fax, company.URL, configJSON,
 		pq.Array(company.DesiredDeliveryDays), company.DefaultShippingNote, company.DisableOrderFeature,
 		company.CustomPrimaryColor, company.CustomSecondaryColor, company.IsActive,
+		company.TaxID, company.PaymentTerms, company.CreditLimit, pq.Array(company.ERPProtectedFields),
 	)
 	if err != nil {
 		return err
@@ -215,10 +218,12 @@ func (r *CompanyRepository) scanCompany(row pgx.Row) (*domain.Company, error) {
 	err := row.Scan(
 		&c.ID, &c.TenantID, &c.SAPCompanyNumber, &c.SAPCustomerGroup, &c.SAPShippingPlant,
 		&c.SAPOffice, &c.SAPPaymentType, &c.SAPPriceGroup, &c.Name, &c.Description, &c.Email,
-		&c.Currency, &c.Street, &c.HouseNumber, &c.ZIP, &c.City, &c.Country,
+		&c.Currency, &c.TaxID, &c.PaymentTerms, &c.CreditLimit,
+		&c.Street, &c.HouseNumber, &c.ZIP, &c.City, &c.Country,
 		&c.Phone, &c.Fax, &c.URL, &configJSON, pq.Array(&c.DesiredDeliveryDays),
 		&c.DefaultShippingNote, &c.DisableOrderFeature,
-		&c.CustomPrimaryColor, &c.CustomSecondaryColor, &c.IsActive,
+		&c.CustomPrimaryColor, &c.CustomSecondaryColor,
+		pq.Array(&c.ERPProtectedFields), &c.ERPSyncedAt, &c.ERPSyncError, &c.IsActive,
 		&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
 	)
 	if err != nil {
@@ -244,10 +249,12 @@ func (r *CompanyRepository) scanCompanyFromRows(rows pgx.Rows) (*domain.Company,
 	err := rows.Scan(
 		&c.ID, &c.TenantID, &c.SAPCompanyNumber, &c.SAPCustomerGroup, &c.SAPShippingPlant,
 		&c.SAPOffice, &c.SAPPaymentType, &c.SAPPriceGroup, &c.Name, &c.Description, &c.Email,
-		&c.Currency, &c.Street, &c.HouseNumber, &c.ZIP, &c.City, &c.Country,
+		&c.Currency, &c.TaxID, &c.PaymentTerms, &c.CreditLimit,
+		&c.Street, &c.HouseNumber, &c.ZIP, &c.City, &c.Country,
 		&c.Phone, &c.Fax, &c.URL, &configJSON, pq.Array(&c.DesiredDeliveryDays),
 		&c.DefaultShippingNote, &c.DisableOrderFeature,
-		&c.CustomPrimaryColor, &c.CustomSecondaryColor, &c.IsActive,
+		&c.CustomPrimaryColor, &c.CustomSecondaryColor,
+		pq.Array(&c.ERPProtectedFields), &c.ERPSyncedAt, &c.ERPSyncError, &c.IsActive,
 		&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
 	)
 	if err != nil {
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/identity/internal/domain"
+)
+
+type CompanySyncRepository struct {
+	db *DB
+}
+
+func NewCompanySyncRepository(db *DB) *CompanySyncRepository {
+	return &CompanySyncRepository{db: db}
+}
+
+func (r *CompanySyncRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]uuid.UUID, error) {
+	// The lease postpones the next sync, so the company is not claimed again
+	// until Save or RecordFailure schedules it or the lease expires
+	query := `
+		UPDATE companies
+		SET erp_next_sync_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM companies
+			WHERE deleted_at IS NULL
+			  AND (erp_next_sync_at IS NULL OR erp_next_sync_at <= NOW())
+			ORDER BY erp_next_sync_at NULLS FIRST
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *CompanySyncRepository) ListAddresses(ctx context.Context, companyID uuid.UUID) ([]domain.CompanyAddress, error) {
+	query := `
+		SELECT id, company_id, erp_address_id, COALESCE(name, ''), COALESCE(street, ''), COALESCE(zip, ''),
+		       COALESCE(city, ''), COALESCE(country, ''), COALESCE(region, ''), created_at, updated_at
+		FROM company_addresses
+		WHERE company_id = $1
+		ORDER BY erp_address_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []domain.CompanyAddress{}
+	for rows.Next() {
+		var a domain.CompanyAddress
+		err := rows.Scan(
+			&a.ID, &a.CompanyID, &a.ERPAddressID, &a.Name, &a.Street, &a.ZIP,
+			&a.City, &a.Country, &a.Region, &a.CreatedAt, &a.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, a)
+	}
+
+	return addresses, rows.Err()
+}
+
+func (r *CompanySyncRepository) ListChanges(ctx context.Context, companyID uuid.UUID, limit, offset int) ([]domain.CompanyChange, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM company_changes WHERE company_id = $1`
+	if err := r.db.Pool.QueryRow(ctx, countQuery, companyID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, tenant_id, company_id, source, field, old_value, new_value, created_at
+		FROM company_changes
+		WHERE company_id = $1
+		ORDER BY created_at DESC, field
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, companyID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	changes := []domain.CompanyChange{}
+	for rows.Next() {
+		var c domain.CompanyChange
+		err := rows.Scan(&c.ID, &c.TenantID, &c.CompanyID, &c.Source, &c.Field, &c.OldValue, &c.NewValue, &c.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, total, rows.Err()
+}
+
+func (r *CompanySyncRepository) Save(ctx context.Context, company *domain.Company, addresses []domain.CompanyAddress, changes []domain.CompanyChange, nextSyncAt time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Only the ERP-maintained columns, so concurrent edits of other fields are kept
+	query := `
+		UPDATE companies SET
+			name = $2, currency = $3, tax_id = $4, payment_terms = $5, credit_limit = $6,
+			street = $7, house_number = $8, zip = $9, city = $10, country = $11,
+			sap_customer_group = $12, sap_shipping_plant = $13, sap_office = $14,
+			sap_payment_type = $15, sap_price_group = $16,
+			erp_synced_at = $17, erp_sync_error = NULL, erp_next_sync_at = $18,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.Exec(ctx, query,
+		company.ID, company.Name, company.Currency, company.TaxID, company.PaymentTerms, company.CreditLimit,
+		company.Street, company.HouseNumber, company.ZIP, company.City, company.Country,
+		company.SAPCustomerGroup, company.SAPShippingPlant, company.SAPOffice,
+		company.SAPPaymentType, company.SAPPriceGroup,
+		company.ERPSyncedAt, nextSyncAt,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrCompanyNotFound
+	}
+
+	if err := replaceAddresses(ctx, tx, company.ID, addresses); err != nil {
+		return fmt.Errorf("failed to save addresses: %w", err)
+	}
+
+	for _, c := range changes {
+		query := `
+			INSERT INTO company_changes (id, tenant_id, company_id, source, field, old_value, new_value, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		if _, err := tx.Exec(ctx, query, c.ID, c.TenantID, c.CompanyID, c.Source, c.Field, c.OldValue, c.NewValue, c.CreatedAt); err != nil {
+			return fmt.Errorf("failed to add change: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// replaceAddresses upserts the addresses by ERP address ID and removes the
+// ones the ERP no longer has. A nil slice keeps the current addresses.
+func replaceAddresses(ctx context.Context, tx pgx.Tx, companyID uuid.UUID, addresses []domain.CompanyAddress) error {
+	if addresses == nil {
+		return nil
+	}
+
+	erpIDs := make([]string, len(addresses))
+	for i, a := range addresses {
+		erpIDs[i] = a.ERPAddressID
+
+		query := `
+			INSERT INTO company_addresses (id, company_id, erp_address_id, name, street, zip, city, country, region, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (company_id, erp_address_id) DO UPDATE SET
+				name = EXCLUDED.name, street = EXCLUDED.street, zip = EXCLUDED.zip, city = EXCLUDED.city,
+				country = EXCLUDED.country, region = EXCLUDED.region, updated_at = EXCLUDED.updated_at
+		`
+		_, err := tx.Exec(ctx, query,
+			a.ID, companyID, a.ERPAddressID, a.Name, a.Street, a.ZIP, a.City, a.Country, a.Region, a.CreatedAt, a.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(ctx, `DELETE FROM company_addresses WHERE company_id = $1 AND NOT (erp_address_id = ANY($2))`, companyID, erpIDs)
+	return err
+}
+
+func (r *CompanySyncRepository) RecordFailure(ctx context.Context, companyID uuid.UUID, reason string, nextSyncAt time.Time) error {
+	query := `UPDATE companies SET erp_sync_error = $2, erp_next_sync_at = $3 WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Pool.Exec(ctx, query, companyID, reason, nextSyncAt)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCompanyNotFound
+	}
+
+	return nil
+}
@@ -405,17 +405,18 @@ func (s *AuthService) generateTokens(ctx context.Context, user *domain.User, com
 
 	// Generate access token
 	accessClaims := auth.AccessTokenClaims{
-		UserID:        user.ID,
-		TenantID:      user.TenantID,
-		Email:         user.Email,
-		Name:          user.Name(),
-		CompanyID:     company.ID,
-		CompanyName:   company.Name,
-		RoleID:        roleID,
-		RoleName:      roleName,
-		Permissions:   permissions,
-		IsSalesMaster: user.IsSalesMaster,
-		SSOOnly:       user.SSOOnly,
+		UserID:           user.ID,
+		TenantID:         user.TenantID,
+		Email:            user.Email,
+		Name:             user.Name(),
+		CompanyID:        company.ID,
+		CompanyName:      company.Name,
+		SAPCompanyNumber: company.SAPCompanyNumber,
+		RoleID:           roleID,
+		RoleName:         roleName,
+		Permissions:      permissions,
+		IsSalesMaster:    user.IsSalesMaster,
+		SSOOnly:          user.SSOOnly,
 	}
 
 	accessToken, err := s.jwtManager.GenerateAccessToken(accessClaims)
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 
@@ -102,6 +103,15 @@ func (s *CompanyService) Update(ctx context.Context, id uuid.UUID, req domain.Up
 	if req.Email != nil {
 		company.Email = req.Email
 	}
+	if req.TaxID != nil {
+		company.TaxID = req.TaxID
+	}
+	if req.PaymentTerms != nil {
+		company.PaymentTerms = req.PaymentTerms
+	}
+	if req.CreditLimit != nil {
+		company.CreditLimit = req.CreditLimit
+	}
 	if req.Street != nil {
 		company.Street = req.Street
 	}
@@ -141,6 +151,14 @@ func (s *CompanyService) Update(ctx context.Context, id uuid.UUID, req domain.Up
 	if req.CustomSecondaryColor != nil {
 		company.CustomSecondaryColor = req.CustomSecondaryColor
 	}
+	if req.ERPProtectedFields != nil {
+		for _, field := range req.ERPProtectedFields {
+			if !domain.IsERPCompanyField(field) {
+				return nil, fmt.Errorf("%w: %s", domain.ErrUnknownCompanyField, field)
+			}
+		}
+		company.ERPProtectedFields = req.ERPProtectedFields
+	}
 	if req.IsActive != nil {
 		company.IsActive = *req.IsActive
 	}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/identity/internal/domain"
+	"github.com/gondolia/gondolia/services/identity/internal/repository"
+)
+
+// CompanySyncConfig configures the company master data sync from the ERP
+type CompanySyncConfig struct {
+	Interval  time.Duration // How often companies due for a sync are processed; 0 disables the background sync
+	BatchSize int           // Companies claimed per run
+	Cadence   time.Duration // How often each company is synced
+	Lease     time.Duration // How long a claimed company is locked for other workers
+}
+
+// CompanySyncer synchronizes company master data and addresses from the
+// tenant's ERP, keeping fields that are authoritative in Gondolia
+type CompanySyncer struct {
+	companyRepo  repository.CompanyRepository
+	syncRepo     repository.CompanySyncRepository
+	tenantRepo   repository.TenantRepository
+	erpProviders provider.Source[erp.ERPProvider]
+	cfg          CompanySyncConfig
+	now          func() time.Time
+}
+
+// NewCompanySyncer creates a new company syncer
+func NewCompanySyncer(
+	companyRepo repository.CompanyRepository,
+	syncRepo repository.CompanySyncRepository,
+	tenantRepo repository.TenantRepository,
+	erpProviders provider.Source[erp.ERPProvider],
+	cfg CompanySyncConfig,
+) *CompanySyncer {
+	return &CompanySyncer{
+		companyRepo:  companyRepo,
+		syncRepo:     syncRepo,
+		tenantRepo:   tenantRepo,
+		erpProviders: erpProviders,
+		cfg:          cfg,
+		now:          time.Now,
+	}
+}
+
+// Run syncs due companies every interval until ctx ends. Errors other than
+// failed ERP requests, which are recorded on the company, are passed to onError.
+func (s *CompanySyncer) Run(ctx context.Context, onError func(error)) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SyncDue(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncDue syncs the companies that are due and returns the number of
+// companies processed
+func (s *CompanySyncer) SyncDue(ctx context.Context) (int, error) {
+	ids, err := s.syncRepo.ClaimDue(ctx, s.cfg.BatchSize, s.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim companies for erp sync: %w", err)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		company, err := s.companyRepo.GetByID(ctx, id)
+		if err == nil {
+			_, err = s.sync(ctx, company)
+		}
+		if err != nil && !errors.Is(err, domain.ErrCompanySyncFailed) {
+			errs = append(errs, fmt.Errorf("company %s: %w", id, err))
+		}
+	}
+
+	return len(ids), errors.Join(errs...)
+}
+
+// Sync syncs a company of the tenant from the ERP right away
+func (s *CompanySyncer) Sync(ctx context.Context, tenantID, companyID uuid.UUID) (*domain.CompanySyncResult, error) {
+	company, err := s.getCompany(ctx, tenantID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, company)
+}
+
+// ListAddresses returns the ERP addresses of a company of the tenant
+func (s *CompanySyncer) ListAddresses(ctx context.Context, tenantID, companyID uuid.UUID) ([]domain.CompanyAddress, error) {
+	if _, err := s.getCompany(ctx, tenantID, companyID); err != nil {
+		return nil, err
+	}
+	return s.syncRepo.ListAddresses(ctx, companyID)
+}
+
+// ListChanges returns the change log of a company of the tenant, newest first
+func (s *CompanySyncer) ListChanges(ctx context.Context, tenantID, companyID uuid.UUID, limit, offset int) ([]domain.CompanyChange, int, error) {
+	if _, err := s.getCompany(ctx, tenantID, companyID); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return s.syncRepo.ListChanges(ctx, companyID, limit, offset)
+}
+
+// getCompany returns the company if it belongs to the tenant
+func (s *CompanySyncer) getCompany(ctx context.Context, tenantID, companyID uuid.UUID) (*domain.Company, error) {
+	company, err := s.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if company.TenantID != tenantID {
+		return nil, domain.ErrCompanyNotFound
+	}
+	return company, nil
+}
+
+// sync fetches the company from the ERP and saves the changes. Failed ERP
+// requests are recorded on the company and returned wrapping
+// ErrCompanySyncFailed.
+func (s *CompanySyncer) sync(ctx context.Context, company *domain.Company) (*domain.CompanySyncResult, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, company.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	current, err := s.syncRepo.ListAddresses(ctx, company.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses: %w", err)
+	}
+
+	data, erpAddresses, err := s.fetch(ctx, company)
+	now := s.now()
+	if err != nil {
+		if recordErr := s.syncRepo.RecordFailure(ctx, company.ID, err.Error(), now.Add(s.cfg.Cadence)); recordErr != nil {
+			return nil, recordErr
+		}
+		return nil, fmt.Errorf("%w: %w", domain.ErrCompanySyncFailed, err)
+	}
+
+	update := newCompanyUpdate(company, tenant, now)
+	update.apply(data, attributeFields(tenant))
+	addresses := update.applyAddresses(current, erpAddresses)
+	company.ERPSyncedAt = &now
+	company.ERPSyncError = nil
+
+	if err := s.syncRepo.Save(ctx, company, addresses, update.changes, now.Add(s.cfg.Cadence)); err != nil {
+		return nil, err
+	}
+
+	if addresses == nil {
+		addresses = current
+	}
+	return &domain.CompanySyncResult{
+		Company:   company,
+		Addresses: addresses,
+		Changes:   update.changes,
+		Skipped:   update.skipped,
+	}, nil
+}
+
+// fetch asks the tenant's ERP for the company's master data and addresses
+func (s *CompanySyncer) fetch(ctx context.Context, company *domain.Company) (*erp.CompanyData, []erp.Address, error) {
+	tenantID := company.TenantID.String()
+	ctx = provider.WithTenant(ctx, tenantID)
+
+	erpProvider, err := s.erpProviders.For(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := erpProvider.SyncCompany(ctx, company.SAPCompanyNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addresses, err := erpProvider.GetCompanyAddresses(ctx, company.SAPCompanyNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, addresses, nil
+}
+
+// erpConfig returns the tenant's ERP settings from Tenant.Config["erp"]
+func erpConfig(tenant *domain.Tenant) map[string]any {
+	cfg, _ := tenant.Config["erp"].(map[string]any)
+	return cfg
+}
+
+// protectedFields returns the fields the tenant keeps in Gondolia for all
+// companies, from Config["erp"]["protected_fields"], e.g. ["name"]
+func protectedFields(tenant *domain.Tenant) []string {
+	values, _ := erpConfig(tenant)["protected_fields"].([]any)
+	fields := make([]string, 0, len(values))
+	for _, v := range values {
+		if field, ok := v.(string); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// attributeFields maps ERP company attributes to the SAP fields of a
+// company, from Config["erp"]["company_attributes"], e.g. {"KONDA": "sap_price_group"}
+func attributeFields(tenant *domain.Tenant) map[string]string {
+	values, _ := erpConfig(tenant)["company_attributes"].(map[string]any)
+	fields := make(map[string]string, len(values))
+	for attribute, v := range values {
+		if field, ok := v.(string); ok {
+			fields[attribute] = field
+		}
+	}
+	return fields
+}
+
+// companyUpdate applies ERP data to a company, collecting the change log
+// and the protected fields it left alone
+type companyUpdate struct {
+	company   *domain.Company
+	protected []string
+	changes   []domain.CompanyChange
+	skipped   []string
+	now       time.Time
+}
+
+func newCompanyUpdate(company *domain.Company, tenant *domain.Tenant, now time.Time) *companyUpdate {
+	return &companyUpdate{
+		company:   company,
+		protected: append(protectedFields(tenant), company.ERPProtectedFields...),
+		now:       now,
+	}
+}
+
+// apply updates the company's master data. Values the ERP does not maintain
+// (empty strings, a zero credit limit) are ignored.
+func (u *companyUpdate) apply(data *erp.CompanyData, attributes map[string]string) {
+	c := u.company
+	u.setString(domain.CompanyFieldName, c.Name, data.Name, func(v string) { c.Name = v })
+	u.setString(domain.CompanyFieldTaxID, deref(c.TaxID), data.TaxID, func(v string) { c.TaxID = &v })
+	u.setString(domain.CompanyFieldPaymentTerms, deref(c.PaymentTerms), data.PaymentTerms, func(v string) { c.PaymentTerms = &v })
+	u.setString(domain.CompanyFieldCurrency, c.Currency, strings.ToUpper(data.Currency), func(v string) { c.Currency = v })
+
+	if data.CreditLimit > 0 {
+		limit := data.CreditLimit
+		u.set(domain.CompanyFieldCreditLimit, formatLimit(c.CreditLimit), formatLimit(&limit), func() { c.CreditLimit = &limit })
+	}
+
+	targets := map[string]**string{
+		domain.CompanyFieldSAPCustomerGroup: &c.SAPCustomerGroup,
+		domain.CompanyFieldSAPShippingPlant: &c.SAPShippingPlant,
+		domain.CompanyFieldSAPOffice:        &c.SAPOffice,
+		domain.CompanyFieldSAPPaymentType:   &c.SAPPaymentType,
+		domain.CompanyFieldSAPPriceGroup:    &c.SAPPriceGroup,
+	}
+	for attribute, field := range attributes {
+		if target, ok := targets[field]; ok {
+			u.setString(field, deref(*target), data.Attributes[attribute], func(v string) { *target = &v })
+		}
+	}
+}
+
+// applyAddresses updates the company's main address from the ERP address
+// with the company's SAP number as ID, or the first one, and returns the
+// addresses to save. Returns nil if the addresses are protected.
+func (u *companyUpdate) applyAddresses(current []domain.CompanyAddress, erpAddresses []erp.Address) []domain.CompanyAddress {
+	c := u.company
+	if len(erpAddresses) > 0 {
+		main := erpAddresses[0]
+		for _, a := range erpAddresses {
+			if a.ID == c.SAPCompanyNumber {
+				main = a
+			}
+		}
+		street, houseNumber := splitStreet(main.Street)
+		country := strings.ToUpper(main.Country)
+		if country == "" {
+			country = c.Country
+		}
+		old := formatAddress(deref(c.Street), deref(c.HouseNumber), deref(c.ZIP), deref(c.City), c.Country)
+		value := formatAddress(street, houseNumber, main.PostalCode, main.City, country)
+		u.set(domain.CompanyFieldAddress, old, value, func() {
+			c.Street = optional(street)
+			c.HouseNumber = optional(houseNumber)
+			c.ZIP = optional(main.PostalCode)
+			c.City = optional(main.City)
+			c.Country = country
+		})
+	}
+
+	existing := make(map[string]domain.CompanyAddress, len(current))
+	for _, a := range current {
+		existing[a.ERPAddressID] = a
+	}
+
+	addresses := make([]domain.CompanyAddress, 0, len(erpAddresses))
+	var changed []func()
+	for _, e := range erpAddresses {
+		a, ok := existing[e.ID]
+		if !ok {
+			a = domain.CompanyAddress{ID: uuid.New(), CompanyID: c.ID, ERPAddressID: e.ID, CreatedAt: u.now}
+		}
+		delete(existing, e.ID)
+
+		old := ""
+		if ok {
+			old = formatCompanyAddress(a)
+		}
+		a.Name, a.Street, a.ZIP, a.City, a.Country, a.Region = e.Name, e.Street, e.PostalCode, e.City, strings.ToUpper(e.Country), e.Region
+		if value := formatCompanyAddress(a); value != old {
+			a.UpdatedAt = u.now
+			changed = append(changed, func() { u.log(domain.CompanyFieldAddresses, old, value) })
+		}
+		addresses = append(addresses, a)
+	}
+	for _, a := range existing {
+		changed = append(changed, func() { u.log(domain.CompanyFieldAddresses, formatCompanyAddress(a), "") })
+	}
+
+	if len(changed) == 0 {
+		return addresses
+	}
+	if u.isProtected(domain.CompanyFieldAddresses) {
+		u.skipped = append(u.skipped, domain.CompanyFieldAddresses)
+		return nil
+	}
+	for _, log := range changed {
+		log()
+	}
+	return addresses
+}
+
+// setString sets a field the ERP has a value for
+func (u *companyUpdate) setString(field, old, value string, apply func(string)) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	u.set(field, old, value, func() { apply(value) })
+}
+
+// set applies a changed value unless the field is protected
+func (u *companyUpdate) set(field, old, value string, apply func()) {
+	if value == old {
+		return
+	}
+	if u.isProtected(field) {
+		u.skipped = append(u.skipped, field)
+		return
+	}
+	apply()
+	u.log(field, old, value)
+}
+
+func (u *companyUpdate) isProtected(field string) bool {
+	return slices.Contains(u.protected, field)
+}
+
+// log adds a change to the change log
+func (u *companyUpdate) log(field, old, value string) {
+	u.changes = append(u.changes, domain.CompanyChange{
+		ID:        uuid.New(),
+		TenantID:  u.company.TenantID,
+		CompanyID: u.company.ID,
+		Source:    domain.ChangeSourceERP,
+		Field:     field,
+		OldValue:  optional(old),
+		NewValue:  optional(value),
+		CreatedAt: u.now,
+	})
+}
+
+// splitStreet splits a trailing house number off a street, e.g.
+// "Bahnhofstrasse 1a" into "Bahnhofstrasse" and "1a"
+func splitStreet(street string) (string, string) {
+	street = strings.TrimSpace(street)
+	i := strings.LastIndex(street, " ")
+	if i < 0 || street[i+1] < '0' || street[i+1] > '9' {
+		return street, ""
+	}
+	return street[:i], street[i+1:]
+}
+
+func formatAddress(street, houseNumber, zip, city, country string) string {
+	return strings.TrimSpace(street+" "+houseNumber) + ", " + strings.TrimSpace(zip+" "+city) + ", " + country
+}
+
+func formatCompanyAddress(a domain.CompanyAddress) string {
+	return a.ERPAddressID + ": " + strings.Join([]string{a.Name, a.Street, strings.TrimSpace(a.ZIP + " " + a.City), a.Country, a.Region}, ", ")
+}
+
+// formatLimit formats a credit limit; "" means unlimited
+func formatLimit(limit *float64) string {
+	if limit == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*limit, 'f', 2, 64)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
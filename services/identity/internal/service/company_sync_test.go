@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/identity/internal/domain"
+	"github.com/gondolia/gondolia/services/identity/internal/repository/mocks"
+)
+
+// fakeCompanyERP serves the company master data of a single customer
+type fakeCompanyERP struct {
+	erp.ERPProvider
+	data      *erp.CompanyData
+	addresses []erp.Address
+	err       error
+}
+
+func (f *fakeCompanyERP) SyncCompany(ctx context.Context, erpCustomerID string) (*erp.CompanyData, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	data := *f.data
+	return &data, nil
+}
+
+func (f *fakeCompanyERP) GetCompanyAddresses(ctx context.Context, erpCustomerID string) ([]erp.Address, error) {
+	return f.addresses, nil
+}
+
+func setupCompanySyncTest(tenant *domain.Tenant, company *domain.Company, fake *fakeCompanyERP) (*CompanySyncer, *mocks.MockCompanySyncRepository) {
+	companyRepo := mocks.NewMockCompanyRepository()
+	companyRepo.AddCompany(company)
+	tenantRepo := mocks.NewMockTenantRepository()
+	tenantRepo.AddTenant(tenant)
+	syncRepo := mocks.NewMockCompanySyncRepository()
+
+	syncer := NewCompanySyncer(companyRepo, syncRepo, tenantRepo, provider.Static[erp.ERPProvider](fake),
+		CompanySyncConfig{BatchSize: 10, Cadence: 24 * time.Hour})
+	return syncer, syncRepo
+}
+
+func TestCompanySync_UpdatesMasterDataAndLogsChanges(t *testing.T) {
+	tenant := &domain.Tenant{ID: testTenantID, Config: map[string]any{
+		"erp": map[string]any{"company_attributes": map[string]any{"KONDA": "sap_price_group"}},
+	}}
+	company := domain.NewCompany(testTenantID, "1000042", "Muster AG")
+	fake := &fakeCompanyERP{
+		data: &erp.CompanyData{
+			ERPCustomerID: "1000042",
+			Name:          "Muster AG Zürich",
+			TaxID:         "CHE-123.456.789",
+			PaymentTerms:  "Z030",
+			Currency:      "chf",
+			CreditLimit:   50000,
+			Attributes:    map[string]string{"KONDA": "02"},
+		},
+		addresses: []erp.Address{
+			{ID: "WE-1", Name: "Lager", Street: "Industriestrasse 5", PostalCode: "8500", City: "Frauenfeld", Country: "ch"},
+			{ID: "1000042", Name: "Muster AG", Street: "Bahnhofstrasse 1a", PostalCode: "8001", City: "Zürich", Country: "ch"},
+		},
+	}
+	syncer, syncRepo := setupCompanySyncTest(tenant, company, fake)
+	ctx := context.Background()
+
+	result, err := syncer.Sync(ctx, testTenantID, company.ID)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if company.Name != "Muster AG Zürich" || *company.TaxID != "CHE-123.456.789" || *company.PaymentTerms != "Z030" ||
+		company.Currency != "CHF" || *company.CreditLimit != 50000 || *company.SAPPriceGroup != "02" {
+		t.Errorf("company = %+v, want ERP master data", company)
+	}
+	if *company.Street != "Bahnhofstrasse" || *company.HouseNumber != "1a" || *company.ZIP != "8001" || *company.City != "Zürich" {
+		t.Errorf("address = %s, want the address with the customer number", company.FullAddress())
+	}
+	if company.ERPSyncedAt == nil || len(result.Addresses) != 2 {
+		t.Errorf("result = %+v, want synced with 2 addresses", result)
+	}
+
+	// name, tax ID, payment terms, credit limit, price group, address and two addresses; currency is unchanged
+	if len(result.Changes) != 8 {
+		t.Errorf("changes = %d, want 8", len(result.Changes))
+	}
+	for _, c := range result.Changes {
+		if c.Source != domain.ChangeSourceERP || c.CompanyID != company.ID {
+			t.Errorf("change = %+v, want from the ERP", c)
+		}
+	}
+
+	// A second sync without ERP changes logs nothing
+	result, err = syncer.Sync(ctx, testTenantID, company.ID)
+	if err != nil || len(result.Changes) != 0 {
+		t.Fatalf("second Sync() = %d changes, %v; want none", len(result.Changes), err)
+	}
+
+	// Removed addresses are logged
+	fake.addresses = fake.addresses[1:]
+	result, _ = syncer.Sync(ctx, testTenantID, company.ID)
+	if len(result.Changes) != 1 || result.Changes[0].Field != domain.CompanyFieldAddresses || result.Changes[0].NewValue != nil {
+		t.Errorf("changes = %+v, want the removed address", result.Changes)
+	}
+	if addresses, _ := syncRepo.ListAddresses(ctx, company.ID); len(addresses) != 1 {
+		t.Errorf("addresses = %d, want 1", len(addresses))
+	}
+}
+
+func TestCompanySync_KeepsProtectedFields(t *testing.T) {
+	tenant := &domain.Tenant{ID: testTenantID, Config: map[string]any{
+		"erp": map[string]any{"protected_fields": []any{"name"}},
+	}}
+	company := domain.NewCompany(testTenantID, "1000042", "Muster AG")
+	company.ERPProtectedFields = []string{domain.CompanyFieldAddresses}
+	limit := 20000.0
+	company.CreditLimit = &limit // Maintained by hand; the ERP has none
+	fake := &fakeCompanyERP{
+		data:      &erp.CompanyData{Name: "MUSTER AG", PaymentTerms: "Z030"},
+		addresses: []erp.Address{{ID: "1000042", Street: "Bahnhofstrasse 1", PostalCode: "8001", City: "Zürich", Country: "CH"}},
+	}
+	syncer, syncRepo := setupCompanySyncTest(tenant, company, fake)
+
+	result, err := syncer.Sync(context.Background(), testTenantID, company.ID)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if company.Name != "Muster AG" || *company.PaymentTerms != "Z030" || *company.City != "Zürich" {
+		t.Errorf("company = %+v, want the name kept and the rest synced", company)
+	}
+	if company.CreditLimit == nil || *company.CreditLimit != 20000 {
+		t.Errorf("credit limit = %v, want the manual limit kept", company.CreditLimit)
+	}
+	if len(result.Skipped) != 2 || result.Skipped[0] != domain.CompanyFieldName || result.Skipped[1] != domain.CompanyFieldAddresses {
+		t.Errorf("skipped = %v, want name and addresses", result.Skipped)
+	}
+	if addresses, _ := syncRepo.ListAddresses(context.Background(), company.ID); len(addresses) != 0 {
+		t.Errorf("addresses = %d, want protected addresses kept", len(addresses))
+	}
+}
+
+func TestCompanySync_RecordsFailures(t *testing.T) {
+	company := domain.NewCompany(testTenantID, "1000042", "Muster AG")
+	fake := &fakeCompanyERP{err: errors.New("HTTP 503")}
+	syncer, syncRepo := setupCompanySyncTest(&domain.Tenant{ID: testTenantID}, company, fake)
+	now := time.Now()
+	syncer.now = func() time.Time { return now }
+
+	if _, err := syncer.Sync(context.Background(), uuid.New(), company.ID); !errors.Is(err, domain.ErrCompanyNotFound) {
+		t.Errorf("Sync() of another tenant error = %v, want ErrCompanyNotFound", err)
+	}
+	if _, err := syncer.Sync(context.Background(), testTenantID, company.ID); !errors.Is(err, domain.ErrCompanySyncFailed) {
+		t.Errorf("Sync() error = %v, want ErrCompanySyncFailed", err)
+	}
+
+	// The background sync records failures instead of reporting them
+	syncRepo.AddDue(company.ID)
+	if n, err := syncer.SyncDue(context.Background()); n != 1 || err != nil {
+		t.Fatalf("SyncDue() = %d, %v; want 1 company without error", n, err)
+	}
+	if syncRepo.SyncError(company.ID) != "HTTP 503" || !syncRepo.NextSyncAt(company.ID).Equal(now.Add(24*time.Hour)) {
+		t.Errorf("sync state = %q at %v, want the error and a retry after the cadence", syncRepo.SyncError(company.ID), syncRepo.NextSyncAt(company.ID))
+	}
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS company_changes;
+DROP TABLE IF EXISTS company_addresses;
+
+DROP INDEX IF EXISTS idx_companies_erp_sync;
+
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS tax_id,
+    DROP COLUMN IF EXISTS payment_terms,
+    DROP COLUMN IF EXISTS credit_limit,
+    DROP COLUMN IF EXISTS erp_protected_fields,
+    DROP COLUMN IF EXISTS erp_synced_at,
+    DROP COLUMN IF EXISTS erp_sync_error,
+    DROP COLUMN IF EXISTS erp_next_sync_at;
@@ -0,0 +1,51 @@
+-- ERP master data and sync state on companies
+ALTER TABLE companies
+    ADD COLUMN tax_id                VARCHAR(50),
+    ADD COLUMN payment_terms         VARCHAR(50),
+    ADD COLUMN credit_limit          NUMERIC(15, 2),
+    ADD COLUMN erp_protected_fields  TEXT[],
+    ADD COLUMN erp_synced_at         TIMESTAMPTZ,
+    ADD COLUMN erp_sync_error        TEXT,
+    ADD COLUMN erp_next_sync_at      TIMESTAMPTZ;
+
+CREATE INDEX idx_companies_erp_sync ON companies(erp_next_sync_at) WHERE deleted_at IS NULL;
+
+-- Addresses of a company as maintained in the ERP
+CREATE TABLE company_addresses (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id      UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+
+    erp_address_id  VARCHAR(50) NOT NULL,
+    name            VARCHAR(255),
+    street          VARCHAR(255),
+    zip             VARCHAR(20),
+    city            VARCHAR(100),
+    country         VARCHAR(2),
+    region          VARCHAR(100),
+
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ DEFAULT NOW(),
+
+    CONSTRAINT uq_company_addresses_erp UNIQUE (company_id, erp_address_id)
+);
+
+CREATE INDEX idx_company_addresses_company ON company_addresses(company_id);
+
+-- Changes made to companies by the ERP sync
+CREATE TABLE company_changes (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    company_id      UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+
+    source          VARCHAR(50) NOT NULL,
+    field           VARCHAR(100) NOT NULL,
+    old_value       TEXT,
+    new_value       TEXT,
+
+    created_at      TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_company_changes_company ON company_changes(company_id, created_at);
+
+COMMENT ON COLUMN companies.erp_protected_fields IS 'Fields maintained in Gondolia that the ERP sync must not overwrite';
+COMMENT ON TABLE company_changes IS 'Change log of company master data synchronized from the ERP';
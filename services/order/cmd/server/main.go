@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/admin"
+	"github.com/gondolia/gondolia/provider/erp"
+	_ "github.com/gondolia/gondolia/provider/erp/idoc"   // Register SAP IDoc provider
+	_ "github.com/gondolia/gondolia/provider/erp/memory" // Register memory provider
+	_ "github.com/gondolia/gondolia/provider/erp/noop"   // Register noop provider
+	_ "github.com/gondolia/gondolia/provider/erp/odata"  // Register OData provider
+	"github.com/gondolia/gondolia/provider/replay"
+	"github.com/gondolia/gondolia/provider/resilience"
+	"github.com/gondolia/gondolia/provider/tracing"
+	"github.com/gondolia/gondolia/services/order/internal/config"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/handler"
+	"github.com/gondolia/gondolia/services/order/internal/middleware"
+	"github.com/gondolia/gondolia/services/order/internal/repository/postgres"
+	"github.com/gondolia/gondolia/services/order/internal/service"
+)
+
+func main() {
+	// Initialize logger
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	// Initialize database
+	ctx := context.Background()
+	db, err := postgres.NewDB(ctx, cfg.DatabaseURL())
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer db.Close()
+
+	// Initialize repositories
+	tenantRepo := postgres.NewTenantRepository(db)
+	orderRepo := postgres.NewOrderRepository(db)
+	transmissionRepo := postgres.NewTransmissionRepository(db)
+	simulationRepo := postgres.NewSimulationRepository(db)
+	statusSyncRepo := postgres.NewStatusSyncRepository(db)
+
+	// Initialize provider resolver. Tenants may select their own ERP in
+	// Tenant.Config["providers"] or the provider config file; all others use the
+	// default from the provider config file or the environment.
+	lookup := tenantConfigLookup(tenantRepo)
+	providerFile := openProviderConfig(cfg.ProviderConfigFile, logger)
+	if providerFile != nil {
+		lookup = providerFile.Lookup(lookup)
+	}
+	// Fixtures recorded with PROVIDER_RECORD_DIR can be served by the "replay" provider
+	replay.Register("erp")
+	resolver := provider.NewResolver(lookup)
+	if cfg.ProviderRecordDir != "" {
+		// Record provider calls as fixtures for the replay provider; innermost, so retries are recorded too
+		logger.Warn("Recording provider calls", zap.String("dir", cfg.ProviderRecordDir))
+		resolver.Use(replay.Decorator(cfg.ProviderRecordDir))
+	}
+	resolver.Use(resilience.Decorator(nil), tracing.Decorator())
+	resolver.SetDefault("erp", erpSelection(cfg))
+	if providerFile != nil {
+		providerFile.Apply(resolver)
+	}
+
+	// Warm up the default providers; readiness reports unavailable until they are started
+	warmUpCtx, stopWarmUp := context.WithCancel(ctx)
+	defer stopWarmUp()
+	go warmUpProviders(warmUpCtx, resolver, logger)
+
+	// Rebuild the affected providers when the provider config file changes
+	if providerFile != nil {
+		go providerFile.Watch(warmUpCtx, resolver, cfg.ProviderConfigReload, logProviderReload(logger))
+	}
+
+	erpProviders := provider.NewSource[erp.ERPProvider](resolver, "erp")
+
+	// Initialize services
+	orderService := service.NewOrderService(orderRepo, tenantRepo, cfg.CartServiceURL)
+	orderService.SetSimulation(simulationRepo, erpProviders, service.SimulationConfig{
+		TTL:      cfg.SimulationTTL,
+		Required: cfg.CheckoutRequireSimulation,
+	})
+	erpDispatcher := service.NewERPDispatcher(orderRepo, transmissionRepo, tenantRepo, erpProviders, service.ERPDispatcherConfig{
+		Interval:    cfg.ERPDispatchInterval,
+		BatchSize:   cfg.ERPDispatchBatchSize,
+		MaxAttempts: cfg.ERPDispatchMaxAttempts,
+		Backoff:     cfg.ERPDispatchBackoff,
+		MaxBackoff:  cfg.ERPDispatchMaxBackoff,
+		Lease:       cfg.ERPDispatchLease,
+	})
+
+	erpStatusSyncer := service.NewERPStatusSyncer(orderRepo, statusSyncRepo, tenantRepo, erpProviders, service.ERPStatusSyncConfig{
+		Interval:   cfg.ERPStatusSyncInterval,
+		BatchSize:  cfg.ERPStatusSyncBatchSize,
+		Cadence:    cfg.ERPStatusSyncCadence,
+		MaxBackoff: cfg.ERPStatusSyncMaxBackoff,
+		Jitter:     cfg.ERPStatusSyncJitter,
+		Lease:      cfg.ERPStatusSyncLease,
+	})
+
+	documentService := service.NewDocumentService(erpProviders, cfg.DocumentCacheTTL)
+
+	// Transmit orders from the ERP outbox in the background
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	dispatchDone := make(chan struct{})
+	go func() {
+		defer close(dispatchDone)
+		erpDispatcher.Run(dispatchCtx, func(err error) {
+			logger.Error("ERP dispatch failed", zap.Error(err))
+		})
+	}()
+
+	// Sync the status of open orders from the ERP in the background
+	statusSyncDone := make(chan struct{})
+	go func() {
+		defer close(statusSyncDone)
+		erpStatusSyncer.Run(dispatchCtx, func(err error) {
+			logger.Error("ERP status sync failed", zap.Error(err))
+		})
+	}()
+
+	// Initialize handlers
+	orderHandler := handler.NewOrderHandler(orderService)
+	transmissionHandler := handler.NewERPTransmissionHandler(erpDispatcher)
+	documentHandler := handler.NewDocumentHandler(documentService)
+	providerAdminHandler := admin.NewHandler(resolver, func(c *gin.Context) (string, bool) {
+		return middleware.GetTenantID(c).String(), true
+	})
+
+	// Initialize HTTP server (REST API)
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	// CORS middleware - must be before all routes
+	router.Use(middleware.CORSMiddleware(middleware.CORSConfig{
+		AllowedOrigins: cfg.AllowedOrigins,
+	}))
+
+	// Health endpoints
+	router.GET("/health/live", handler.LivenessHandler)
+	router.GET("/health/ready", handler.ReadinessHandler(resolver.Ready))
+	router.GET("/metrics", handler.MetricsHandler)
+
+	// API routes
+	api := router.Group("/api/v1")
+
+	// Apply tenant and auth middleware to all API routes
+	api.Use(middleware.TenantMiddleware(tenantRepo))
+	api.Use(middleware.AuthMiddleware(cfg.JWTAccessSecret))
+
+	// Order endpoints
+	orders := api.Group("/orders")
+	{
+		orders.POST("/simulate", orderHandler.Simulate)
+		orders.POST("/checkout", orderHandler.Checkout)
+		orders.GET("", orderHandler.List)
+		orders.GET("/:id", orderHandler.Get)
+		orders.PATCH("/:id/cancel", orderHandler.Cancel)
+	}
+
+	// Document center (the company's ERP orders, invoices, shipments and credits)
+	documentHandler.RegisterRoutes(api)
+
+	// Admin endpoints (active providers, health probes)
+	providerAdminHandler.RegisterRoutes(api.Group("/admin", middleware.RequirePermission(domain.PermManageSettings)))
+
+	// Operator endpoints (providers of all tenants, ERP outbox of the tenant
+	// in X-Tenant-ID); not mounted without a token
+	if cfg.OperatorToken != "" {
+		operator := router.Group("/operator", admin.RequireOperator(cfg.OperatorToken))
+		providerAdminHandler.RegisterOperatorRoutes(operator)
+		transmissionHandler.RegisterRoutes(operator.Group("", middleware.TenantMiddleware(tenantRepo)))
+	}
+
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.HTTPPort),
+		Handler: router,
+	}
+
+	// Initialize gRPC server
+	grpcServer := grpc.NewServer()
+	// Register gRPC services here
+
+	// Start servers
+	go func() {
+		logger.Info("Starting HTTP server", zap.String("port", cfg.HTTPPort))
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("HTTP server error", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+		if err != nil {
+			logger.Fatal("Failed to listen for gRPC", zap.Error(err))
+		}
+		logger.Info("Starting gRPC server", zap.String("port", cfg.GRPCPort))
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Fatal("gRPC server error", zap.Error(err))
+		}
+	}()
+
+	// Graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down servers...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	grpcServer.GracefulStop()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", zap.Error(err))
+	}
+
+	// Stop the background workers before closing the providers they use;
+	// claimed transmissions and orders are picked up again once their lease expires
+	stopDispatch()
+	<-dispatchDone
+	<-statusSyncDone
+
+	// Close providers once no requests are in flight, flushing their buffers and connections
+	stopWarmUp()
+	if err := resolver.Close(shutdownCtx); err != nil {
+		logger.Error("Provider shutdown error", zap.Error(err))
+	}
+
+	logger.Info("Servers stopped")
+}
+
+// warmUpProviders starts the default providers so that connections are open
+// before the service reports ready, retrying until they start or ctx ends
+func warmUpProviders(ctx context.Context, resolver *provider.Resolver, logger *zap.Logger) {
+	for {
+		err := resolver.Start(ctx)
+		if err == nil {
+			logger.Info("Providers started")
+			return
+		}
+		logger.Warn("Provider warm-up failed, retrying", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// openProviderConfig loads the provider config file, if one is configured
+func openProviderConfig(path string, logger *zap.Logger) *provider.ConfigFile {
+	if path == "" {
+		return nil
+	}
+	file, err := provider.OpenConfigFile(path)
+	if err != nil {
+		logger.Fatal("Failed to load provider config", zap.Error(err))
+	}
+	logger.Info("Loaded provider config", zap.String("path", path))
+	return file
+}
+
+// logProviderReload logs hot reloads of the provider config file
+func logProviderReload(logger *zap.Logger) func(changes []string, err error) {
+	return func(changes []string, err error) {
+		if err != nil {
+			logger.Error("Provider config reload failed, keeping current config", zap.Error(err))
+			return
+		}
+		logger.Info("Provider config reloaded", zap.Strings("changes", changes))
+	}
+}
+
+// tenantConfigLookup reads provider selections from the tenant's config
+func tenantConfigLookup(tenantRepo *postgres.TenantRepository) provider.TenantConfigLookup {
+	return func(ctx context.Context, tenantID string) (map[string]any, error) {
+		id, err := uuid.Parse(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		tenant, err := tenantRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return tenant.Config, nil
+	}
+}
+
+// erpSelection returns the default ERP provider selection based on configuration
+func erpSelection(cfg *config.Config) provider.Selection {
+	providerType := cfg.ERPProvider
+	if providerType == "" || providerType == "mock" {
+		providerType = "noop"
+	}
+	return provider.Selection{Name: providerType}
+}
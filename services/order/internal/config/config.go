@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -30,6 +32,46 @@ type Config struct {
 
 	// JWT
 	JWTAccessSecret string
+
+	// ERP Provider
+	ERPProvider string
+
+	// ERP dispatcher (transmits orders from the outbox)
+	ERPDispatchInterval    time.Duration
+	ERPDispatchBatchSize   int
+	ERPDispatchMaxAttempts int
+	ERPDispatchBackoff     time.Duration
+	ERPDispatchMaxBackoff  time.Duration
+	// A claimed transmission is locked for this long, so it must exceed the
+	// worst-case ERP call including the provider's retries
+	ERPDispatchLease time.Duration
+
+	// ERP status sync (polls the ERP for the status of open orders)
+	ERPStatusSyncInterval   time.Duration
+	ERPStatusSyncBatchSize  int
+	ERPStatusSyncCadence    time.Duration
+	ERPStatusSyncMaxBackoff time.Duration
+	ERPStatusSyncJitter     time.Duration
+	// Like ERPDispatchLease, for a claimed order
+	ERPStatusSyncLease time.Duration
+
+	// Checkout simulation (ERP prices, availability and delivery schedule)
+	SimulationTTL             time.Duration
+	CheckoutRequireSimulation bool
+
+	// Document center (ERP order, invoice and shipment history per company)
+	DocumentCacheTTL time.Duration
+
+	// Operator routes (across tenants) require this token in the
+	// X-Operator-Token header; without it they are not mounted
+	OperatorToken string
+
+	// Provider config file (YAML); its defaults override the provider settings above
+	ProviderConfigFile   string
+	ProviderConfigReload time.Duration
+
+	// Directory to record provider calls to as fixtures for the "replay" provider
+	ProviderRecordDir string
 }
 
 func Load() (*Config, error) {
@@ -47,6 +89,32 @@ func Load() (*Config, error) {
 		AllowedOrigins:   getSliceEnv("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 		CartServiceURL:   getEnv("CART_SERVICE_URL", "http://cart:8082"),
 		JWTAccessSecret:  getEnv("JWT_ACCESS_SECRET", "dev-access-secret-change-me"),
+
+		ERPProvider: getEnv("ERP_PROVIDER", "noop"),
+
+		ERPDispatchInterval:    getDurationEnv("ERP_DISPATCH_INTERVAL", 5*time.Second),
+		ERPDispatchBatchSize:   getIntEnv("ERP_DISPATCH_BATCH_SIZE", 20),
+		ERPDispatchMaxAttempts: getIntEnv("ERP_DISPATCH_MAX_ATTEMPTS", 10),
+		ERPDispatchBackoff:     getDurationEnv("ERP_DISPATCH_BACKOFF", 30*time.Second),
+		ERPDispatchMaxBackoff:  getDurationEnv("ERP_DISPATCH_MAX_BACKOFF", time.Hour),
+		ERPDispatchLease:       getDurationEnv("ERP_DISPATCH_LEASE", 5*time.Minute),
+
+		ERPStatusSyncInterval:   getDurationEnv("ERP_STATUS_SYNC_INTERVAL", time.Minute),
+		ERPStatusSyncBatchSize:  getIntEnv("ERP_STATUS_SYNC_BATCH_SIZE", 50),
+		ERPStatusSyncCadence:    getDurationEnv("ERP_STATUS_SYNC_CADENCE", 15*time.Minute),
+		ERPStatusSyncMaxBackoff: getDurationEnv("ERP_STATUS_SYNC_MAX_BACKOFF", 6*time.Hour),
+		ERPStatusSyncJitter:     getDurationEnv("ERP_STATUS_SYNC_JITTER", time.Minute),
+		ERPStatusSyncLease:      getDurationEnv("ERP_STATUS_SYNC_LEASE", 5*time.Minute),
+
+		SimulationTTL:             getDurationEnv("SIMULATION_TTL", 15*time.Minute),
+		CheckoutRequireSimulation: getBoolEnv("CHECKOUT_REQUIRE_SIMULATION", false),
+
+		DocumentCacheTTL: getDurationEnv("DOCUMENT_CACHE_TTL", time.Minute),
+
+		OperatorToken:        getEnv("OPERATOR_TOKEN", ""),
+		ProviderConfigFile:   getEnv("PROVIDER_CONFIG_FILE", ""),
+		ProviderConfigReload: getDurationEnv("PROVIDER_CONFIG_RELOAD", 10*time.Second),
+		ProviderRecordDir:    getEnv("PROVIDER_RECORD_DIR", ""),
 	}
 
 	return cfg, nil
@@ -68,6 +136,31 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		return value == "true" || value == "1"
+	}
+	return defaultValue
+}
+
 func getSliceEnv(key string, defaultValue []string) []string {
 	if value, exists := os.LookupEnv(key); exists && value != "" {
 		var result []string
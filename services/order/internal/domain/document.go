@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// Permissions of the user's role in the current company (JWT claim
+// "permissions") that grant access to the company's ERP documents
+const (
+	PermSeeOrders    = "company.order-data.see-orders"
+	PermSeeInvoices  = "company.order-data.see-invoices"
+	PermSeeShipments = "company.order-data.see-shipments"
+	PermSeeCredits   = "company.order-data.see-credits"
+)
+
+// DocumentFilter filters the ERP documents of a company
+type DocumentFilter struct {
+	DateFrom time.Time
+	DateTo   time.Time
+	Status   string
+	Limit    int
+	Offset   int
+}
+
+// OrderDocument is an order in the company's ERP order history
+type OrderDocument struct {
+	ERPOrderNumber string    `json:"erp_order_number"`
+	OrderDate      time.Time `json:"order_date"`
+	CustomerPO     string    `json:"customer_po,omitempty"`
+	TotalAmount    float64   `json:"total_amount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+}
+
+// InvoiceDocument is an invoice or credit note in the company's ERP
+// invoice history. Credit notes have a negative amount.
+type InvoiceDocument struct {
+	InvoiceNumber string    `json:"invoice_number"`
+	OrderNumber   string    `json:"order_number,omitempty"`
+	InvoiceDate   time.Time `json:"invoice_date"`
+	DueDate       time.Time `json:"due_date"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"`
+}
+
+// IsCredit checks if the document is a credit note
+func (d *InvoiceDocument) IsCredit() bool {
+	return d.Amount < 0
+}
+
+// ShipmentDocument is a shipment in the company's ERP shipment history
+type ShipmentDocument struct {
+	ShipmentID     string    `json:"shipment_id"`
+	OrderNumber    string    `json:"order_number,omitempty"`
+	ShipDate       time.Time `json:"ship_date"`
+	TrackingNumber string    `json:"tracking_number,omitempty"`
+	Carrier        string    `json:"carrier,omitempty"`
+}
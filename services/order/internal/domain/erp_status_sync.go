@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActorERP is the actor of status changes reported by the ERP
+const ActorERP = "erp"
+
+// ERPStatusSync is the state of polling the ERP for the status of an open
+// order, i.e. a confirmed, processing or shipped order with an ERP order
+// number
+type ERPStatusSync struct {
+	OrderID        uuid.UUID
+	TenantID       uuid.UUID
+	OrderNumber    string
+	ERPOrderNumber string
+	ERPStatus      string // Status last reported by the ERP
+	Failures       int    // Consecutive failed polls
+	LastError      string
+	NextSyncAt     time.Time
+	SyncedAt       *time.Time // Last successful poll
+}
+
+// StatusMapping maps ERP order statuses to order statuses. Statuses mapped
+// to "" are ignored.
+type StatusMapping map[string]OrderStatus
+
+// DefaultStatusMapping maps the statuses reported by the bundled ERP
+// providers. Tenants extend or override it in Config["erp"]["status_mapping"].
+var DefaultStatusMapping = StatusMapping{
+	"confirmed":  OrderStatusConfirmed,
+	"processing": OrderStatusProcessing,
+	"shipped":    OrderStatusShipped,
+	"delivered":  OrderStatusDelivered,
+	"cancelled":  OrderStatusCancelled,
+	"rejected":   OrderStatusCancelled,
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransmissionStatus represents the status of an order's transmission to the ERP
+type TransmissionStatus string
+
+const (
+	TransmissionStatusPending TransmissionStatus = "pending" // Waiting for (another) attempt
+	TransmissionStatusSent    TransmissionStatus = "sent"    // Accepted by the ERP
+	TransmissionStatusFailed  TransmissionStatus = "failed"  // Rejected or out of attempts; needs an operator
+	TransmissionStatusSkipped TransmissionStatus = "skipped" // Skipped by an operator or because the order was cancelled
+)
+
+// ERPTransmission is an outbox entry that transmits an order to the ERP.
+// It is written in the same transaction as the order and processed by the
+// ERP dispatcher.
+type ERPTransmission struct {
+	ID             uuid.UUID          `json:"id"`
+	TenantID       uuid.UUID          `json:"tenant_id"`
+	OrderID        uuid.UUID          `json:"order_id"`
+	OrderNumber    string             `json:"order_number"`
+	Status         TransmissionStatus `json:"status"`
+	Attempts       int                `json:"attempts"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at"`
+	LastError      string             `json:"last_error,omitempty"`
+	Messages       []ERPMessage       `json:"messages,omitempty"`
+	ERPOrderNumber string             `json:"erp_order_number,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	SentAt         *time.Time         `json:"sent_at,omitempty"`
+	LockedUntil    *time.Time         `json:"locked_until,omitempty"` // Lease of the dispatcher transmitting it
+	ClaimToken     *uuid.UUID         `json:"-"`                      // Set when claimed by this dispatcher
+}
+
+// ERPMessage is a message returned by the ERP, e.g. a warning on an accepted
+// order or the reason an order was rejected
+type ERPMessage struct {
+	Type    string `json:"type"` // "success", "warning", "error", "info"
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// TransmissionFilter represents filter options for listing transmissions
+type TransmissionFilter struct {
+	TenantID uuid.UUID
+	Status   *TransmissionStatus
+	Stuck    bool // Failed, or pending after at least one failed attempt
+	Limit    int
+	Offset   int
+}
+
+// NewERPTransmission creates a pending transmission for an order
+func NewERPTransmission(order *Order) *ERPTransmission {
+	return &ERPTransmission{
+		ID:            uuid.New(),
+		TenantID:      order.TenantID,
+		OrderID:       order.ID,
+		OrderNumber:   order.OrderNumber,
+		Status:        TransmissionStatusPending,
+		NextAttemptAt: order.CreatedAt,
+		CreatedAt:     order.CreatedAt,
+		UpdatedAt:     order.CreatedAt,
+	}
+}
+
+// IsInFlight checks if a dispatcher holds the lease of the transmission
+func (t *ERPTransmission) IsInFlight(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
+
+// IsStuck checks if the transmission needs an operator's attention
+func (t *ERPTransmission) IsStuck() bool {
+	return t.Status == TransmissionStatusFailed ||
+		(t.Status == TransmissionStatusPending && t.Attempts > 0)
+}
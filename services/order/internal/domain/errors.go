@@ -16,6 +16,23 @@ var (
 	ErrCartEmpty               = errors.New("cart is empty")
 	ErrCartValidationFailed    = errors.New("cart validation failed")
 
+	// ERP transmission errors
+	ErrTransmissionNotFound      = errors.New("erp transmission not found")
+	ErrTransmissionAlreadySent   = errors.New("erp transmission was already sent")
+	ErrTransmissionInFlight      = errors.New("erp transmission is being dispatched, try again later")
+	ErrTransmissionLeaseLost     = errors.New("erp transmission lease expired before the outcome was saved")
+
+	// Checkout simulation errors
+	ErrSimulationNotFound    = errors.New("checkout simulation not found")
+	ErrSimulationExpired     = errors.New("checkout simulation expired, please simulate again")
+	ErrSimulationMismatch    = errors.New("order does not match the checkout simulation")
+	ErrSimulationRequired    = errors.New("checkout requires a simulation")
+	ErrSimulationUnsupported = errors.New("the ERP does not support checkout simulation")
+
+	// Document center errors
+	ErrNoERPCustomer        = errors.New("current company has no ERP customer number")
+	ErrShipmentStatusFilter = errors.New("shipments cannot be filtered by status")
+
 	// Tenant errors
 	ErrTenantNotFound  = errors.New("tenant not found")
 	ErrTenantNotActive = errors.New("tenant is not active")
@@ -25,10 +42,27 @@ var (
 	ErrForbidden    = errors.New("forbidden")
 )
 
+// ERPRejectedError is returned when the ERP rejects a request; Messages
+// holds the ERP's reasons
+type ERPRejectedError struct {
+	Err      error
+	Messages []ERPMessage
+}
+
+func (e *ERPRejectedError) Error() string {
+	return "rejected by the ERP: " + e.Err.Error()
+}
+
+func (e *ERPRejectedError) Unwrap() error {
+	return e.Err
+}
+
 // IsNotFoundError checks if error is a not found error
 func IsNotFoundError(err error) bool {
 	return errors.Is(err, ErrOrderNotFound) ||
 		errors.Is(err, ErrCartNotFound) ||
+		errors.Is(err, ErrTransmissionNotFound) ||
+		errors.Is(err, ErrSimulationNotFound) ||
 		errors.Is(err, ErrTenantNotFound)
 }
 
@@ -39,5 +73,10 @@ func IsValidationError(err error) bool {
 		errors.Is(err, ErrOrderCannotBeCancelled) ||
 		errors.Is(err, ErrOrderInvalidTransition) ||
 		errors.Is(err, ErrCartEmpty) ||
+		errors.Is(err, ErrTransmissionAlreadySent) ||
+		errors.Is(err, ErrTransmissionInFlight) ||
+		errors.Is(err, ErrSimulationExpired) ||
+		errors.Is(err, ErrSimulationMismatch) ||
+		errors.Is(err, ErrSimulationRequired) ||
 		errors.Is(err, ErrCartValidationFailed)
 }
@@ -32,6 +32,10 @@ type Order struct {
 	ShippingAddress map[string]any    `json:"shipping_address"`
 	BillingAddress  map[string]any    `json:"billing_address"`
 	Notes           string            `json:"notes,omitempty"`
+	CompanyID       *uuid.UUID        `json:"company_id,omitempty"`
+	ERPCustomerID   string            `json:"erp_customer_id,omitempty"`   // Sold-to party the order is placed for
+	ERPOrderNumber  string            `json:"erp_order_number,omitempty"`  // Set once the ERP accepted the order
+	SimulationID    *uuid.UUID        `json:"simulation_id,omitempty"`     // Checkout simulation the order was placed with
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 	ItemCount       int               `json:"item_count,omitempty"`       // Number of items (for list views)
@@ -54,6 +58,11 @@ type OrderItem struct {
 	Currency      string         `json:"currency"`
 	Configuration map[string]any `json:"configuration,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
+
+	// Confirmation by the ERP
+	ERPItemNumber     string   `json:"erp_item_number,omitempty"`
+	ConfirmedQuantity *float64 `json:"confirmed_quantity,omitempty"`
+	ConfirmedPrice    *float64 `json:"confirmed_price,omitempty"`
 }
 
 // OrderStatusLog represents a status change in the order history
@@ -63,7 +72,9 @@ type OrderStatusLog struct {
 	FromStatus *OrderStatus `json:"from_status,omitempty"`
 	ToStatus   OrderStatus `json:"to_status"`
 	ChangedBy  *uuid.UUID  `json:"changed_by,omitempty"`
+	Actor      string      `json:"actor,omitempty"` // System that changed the status, e.g. ActorERP
 	Note       string      `json:"note,omitempty"`
+	Messages   []ERPMessage `json:"messages,omitempty"` // ERP messages, e.g. why a transmission failed
 	CreatedAt  time.Time   `json:"created_at"`
 }
 
@@ -72,6 +83,11 @@ type CheckoutRequest struct {
 	ShippingAddress map[string]any `json:"shipping_address" binding:"required"`
 	BillingAddress  map[string]any `json:"billing_address" binding:"required"`
 	Notes           string         `json:"notes,omitempty"`
+	SimulationID    *uuid.UUID     `json:"simulation_id,omitempty"` // From POST /orders/simulate
+
+	// Company the order is placed for, from the access token
+	CompanyID     *uuid.UUID `json:"-"`
+	ERPCustomerID string     `json:"-"`
 }
 
 // OrderFilter represents filter options for listing orders
@@ -126,3 +142,38 @@ func IsValidStatusTransition(from, to OrderStatus) bool {
 	}
 	return false
 }
+
+// StatusPath returns the statuses an order passes through on its way from one
+// status to another along valid transitions, or nil if to cannot be reached
+func StatusPath(from, to OrderStatus) []OrderStatus {
+	statuses := []OrderStatus{
+		OrderStatusPending,
+		OrderStatusConfirmed,
+		OrderStatusProcessing,
+		OrderStatusShipped,
+		OrderStatusDelivered,
+		OrderStatusCancelled,
+	}
+
+	// Breadth-first search for the shortest path
+	previous := map[OrderStatus]OrderStatus{from: from}
+	queue := []OrderStatus{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to && current != from {
+			var path []OrderStatus
+			for status := to; status != from; status = previous[status] {
+				path = append([]OrderStatus{status}, path...)
+			}
+			return path
+		}
+		for _, next := range statuses {
+			if _, seen := previous[next]; !seen && IsValidStatusTransition(current, next) {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
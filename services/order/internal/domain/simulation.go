@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderSimulation is a checkout preview with the ERP's prices, availability
+// and delivery schedule for the current cart. Checkout can require that the
+// order matches an unexpired simulation.
+type OrderSimulation struct {
+	ID            uuid.UUID          `json:"id"`
+	TenantID      uuid.UUID          `json:"tenant_id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	ERPCustomerID string             `json:"erp_customer_id,omitempty"`
+	Currency      string             `json:"currency"`
+	Items         []SimulationItem   `json:"items"`
+	Totals        SimulationTotals   `json:"totals"`
+	Schedule      []DeliverySchedule `json:"schedule,omitempty"`
+	Messages      []ERPMessage       `json:"messages,omitempty"`
+	CatalogTotal  float64            `json:"catalog_total"` // Total at the cart's catalog prices
+	PriceChanged  bool               `json:"price_changed"` // Any ERP price differs from the catalog price
+	AllAvailable  bool               `json:"all_available"`
+	CreatedAt     time.Time          `json:"created_at"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+}
+
+// SimulationItem is a cart item as priced and scheduled by the ERP
+type SimulationItem struct {
+	SKU              string  `json:"sku"`
+	ProductName      string  `json:"product_name"`
+	Quantity         int     `json:"quantity"`
+	Unit             string  `json:"unit,omitempty"`
+	CatalogUnitPrice float64 `json:"catalog_unit_price"`
+	UnitPrice        float64 `json:"unit_price"`       // ERP price
+	TotalPrice       float64 `json:"total_price"`      // ERP price
+	PriceDifference  float64 `json:"price_difference"` // UnitPrice - CatalogUnitPrice
+	PriceChanged     bool    `json:"price_changed"`
+	Available        bool    `json:"available"`
+	LeadTimeDays     int     `json:"lead_time_days"`
+}
+
+// SimulationTotals are the ERP's order totals
+type SimulationTotals struct {
+	Subtotal float64 `json:"subtotal"`
+	Tax      float64 `json:"tax"`
+	Shipping float64 `json:"shipping"`
+	Total    float64 `json:"total"`
+	Currency string  `json:"currency"`
+}
+
+// DeliverySchedule is a planned (partial) delivery
+type DeliverySchedule struct {
+	Date     time.Time `json:"date"`
+	Quantity float64   `json:"quantity"`
+	SKUs     []string  `json:"skus,omitempty"`
+}
+
+// SimulateRequest represents a request to simulate the checkout of the cart
+type SimulateRequest struct {
+	ShippingAddress map[string]any `json:"shipping_address,omitempty"`
+	DesiredDate     *time.Time     `json:"desired_date,omitempty"`
+
+	// Company the order is placed for, from the access token
+	ERPCustomerID string `json:"-"`
+}
+
+// SetPrice stores the ERP's price and its difference to the catalog price
+func (i *SimulationItem) SetPrice(unitPrice, totalPrice float64) {
+	i.UnitPrice = unitPrice
+	i.TotalPrice = totalPrice
+	i.PriceDifference = math.Round((unitPrice-i.CatalogUnitPrice)*100) / 100
+	i.PriceChanged = i.PriceDifference != 0
+}
+
+// IsExpired checks if the simulation's prices are no longer binding
+func (s *OrderSimulation) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Matches checks if the order items are the simulated SKUs and quantities at
+// the catalog prices the simulation compared against
+func (s *OrderSimulation) Matches(items []OrderItem) bool {
+	if len(items) != len(s.Items) {
+		return false
+	}
+	type line struct {
+		sku   string
+		price int64 // Catalog unit price in cents
+	}
+	quantities := make(map[line]int, len(items))
+	for _, item := range s.Items {
+		quantities[line{item.SKU, cents(item.CatalogUnitPrice)}] += item.Quantity
+	}
+	for _, item := range items {
+		quantities[line{item.SKU, cents(item.UnitPrice)}] -= item.Quantity
+	}
+	for _, q := range quantities {
+		if q != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Check verifies that the order may be placed based on the simulation
+func (s *OrderSimulation) Check(order *Order, items []OrderItem, now time.Time) error {
+	if s.TenantID != order.TenantID || s.UserID != order.UserID {
+		return ErrSimulationNotFound
+	}
+	if s.IsExpired(now) {
+		return ErrSimulationExpired
+	}
+	if s.ERPCustomerID != order.ERPCustomerID {
+		return fmt.Errorf("%w: the cart was simulated for another customer", ErrSimulationMismatch)
+	}
+	if !s.Matches(items) {
+		return fmt.Errorf("%w: the cart changed since it was simulated", ErrSimulationMismatch)
+	}
+	return nil
+}
+
+// Apply sets the simulated ERP prices and totals on an order that matches
+// the simulation, since they are binding for the order
+func (s *OrderSimulation) Apply(order *Order, items []OrderItem) {
+	used := make([]bool, len(s.Items))
+	for i := range items {
+		for j, simulated := range s.Items {
+			if used[j] || simulated.SKU != items[i].SKU || simulated.Quantity != items[i].Quantity {
+				continue
+			}
+			used[j] = true
+			items[i].UnitPrice = simulated.UnitPrice
+			items[i].TotalPrice = simulated.TotalPrice
+			break
+		}
+	}
+	order.Subtotal = s.Totals.Subtotal
+	order.TaxAmount = s.Totals.Tax
+	order.Total = s.Totals.Total
+	order.Currency = s.Totals.Currency
+}
+
+// cents rounds an amount to cents for comparing prices
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// PermManageSettings is the permission of the user's role in the current
+// company (JWT claim "permissions") to see the tenant's provider settings
+const PermManageSettings = "company.manage-settings"
+
 // Tenant represents a tenant in the multi-tenant system
 type Tenant struct {
 	ID        uuid.UUID      `json:"id"`
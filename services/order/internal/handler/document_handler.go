@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/middleware"
+	"github.com/gondolia/gondolia/services/order/internal/service"
+)
+
+const (
+	documentPageLimit   = 50   // Default page size
+	documentMaxLimit    = 200  // Largest page
+	documentExportLimit = 1000 // Default and largest CSV export
+)
+
+// DocumentHandler handles the document center of the current company: its
+// orders, invoices, shipments and credit notes in the ERP
+type DocumentHandler struct {
+	documentService *service.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler
+func NewDocumentHandler(documentService *service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		documentService: documentService,
+	}
+}
+
+// RegisterRoutes registers the document endpoints, each gated by its permission
+func (h *DocumentHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/documents/orders", middleware.RequirePermission(domain.PermSeeOrders), h.ListOrders)
+	rg.GET("/documents/invoices", middleware.RequirePermission(domain.PermSeeInvoices), h.ListInvoices)
+	rg.GET("/documents/shipments", middleware.RequirePermission(domain.PermSeeShipments), h.ListShipments)
+	rg.GET("/documents/credits", middleware.RequirePermission(domain.PermSeeCredits), h.ListCredits)
+}
+
+// ListOrders handles GET /documents/orders
+func (h *DocumentHandler) ListOrders(c *gin.Context) {
+	filter, csvExport, ok := parseDocumentFilter(c)
+	if !ok {
+		return
+	}
+
+	orders, err := h.documentService.ListOrders(c.Request.Context(), middleware.GetTenantID(c), middleware.GetERPCustomerID(c), filter)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	if csvExport {
+		rows := make([][]string, len(orders))
+		for i, o := range orders {
+			rows[i] = []string{o.ERPOrderNumber, formatDate(o.OrderDate), o.CustomerPO, formatAmount(o.TotalAmount), o.Currency, o.Status}
+		}
+		respondCSV(c, "orders", []string{"erp_order_number", "order_date", "customer_po", "total_amount", "currency", "status"}, rows)
+		return
+	}
+	respondDocuments(c, orders, filter)
+}
+
+// ListInvoices handles GET /documents/invoices
+func (h *DocumentHandler) ListInvoices(c *gin.Context) {
+	h.listInvoices(c, false)
+}
+
+// ListCredits handles GET /documents/credits
+func (h *DocumentHandler) ListCredits(c *gin.Context) {
+	h.listInvoices(c, true)
+}
+
+func (h *DocumentHandler) listInvoices(c *gin.Context, credits bool) {
+	filter, csvExport, ok := parseDocumentFilter(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := h.documentService.ListInvoices(c.Request.Context(), middleware.GetTenantID(c), middleware.GetERPCustomerID(c), filter, credits)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	if csvExport {
+		name := "invoices"
+		if credits {
+			name = "credits"
+		}
+		rows := make([][]string, len(invoices))
+		for i, inv := range invoices {
+			rows[i] = []string{inv.InvoiceNumber, inv.OrderNumber, formatDate(inv.InvoiceDate), formatDate(inv.DueDate), formatAmount(inv.Amount), inv.Currency, inv.Status}
+		}
+		respondCSV(c, name, []string{"invoice_number", "order_number", "invoice_date", "due_date", "amount", "currency", "status"}, rows)
+		return
+	}
+	respondDocuments(c, invoices, filter)
+}
+
+// ListShipments handles GET /documents/shipments
+func (h *DocumentHandler) ListShipments(c *gin.Context) {
+	filter, csvExport, ok := parseDocumentFilter(c)
+	if !ok {
+		return
+	}
+
+	shipments, err := h.documentService.ListShipments(c.Request.Context(), middleware.GetTenantID(c), middleware.GetERPCustomerID(c), filter)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	if csvExport {
+		rows := make([][]string, len(shipments))
+		for i, s := range shipments {
+			rows[i] = []string{s.ShipmentID, s.OrderNumber, formatDate(s.ShipDate), s.TrackingNumber, s.Carrier}
+		}
+		respondCSV(c, "shipments", []string{"shipment_id", "order_number", "ship_date", "tracking_number", "carrier"}, rows)
+		return
+	}
+	respondDocuments(c, shipments, filter)
+}
+
+// parseDocumentFilter parses date_from, date_to (YYYY-MM-DD), status, limit,
+// offset and format=csv. Responds with an error if they are invalid.
+func parseDocumentFilter(c *gin.Context) (domain.DocumentFilter, bool, bool) {
+	var filter domain.DocumentFilter
+	csvExport := c.Query("format") == "csv"
+
+	for param, date := range map[string]*time.Time{"date_from": &filter.DateFrom, "date_to": &filter.DateTo} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": gin.H{
+						"code":    "INVALID_REQUEST",
+						"message": fmt.Sprintf("%s must be a date like 2024-05-02", param),
+					},
+				})
+				return filter, false, false
+			}
+			*date = parsed
+		}
+	}
+	filter.Status = c.Query("status")
+
+	defaultLimit, maxLimit := documentPageLimit, documentMaxLimit
+	if csvExport {
+		defaultLimit, maxLimit = documentExportLimit, documentExportLimit
+	}
+	filter.Limit = min(parseInt(c.Query("limit"), defaultLimit), maxLimit)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	if offset := parseInt(c.Query("offset"), 0); offset >= 0 {
+		filter.Offset = offset
+	}
+
+	return filter, csvExport, true
+}
+
+func respondDocuments(c *gin.Context, data any, filter domain.DocumentFilter) {
+	c.JSON(http.StatusOK, gin.H{
+		"data":   data,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// respondCSV sends the rows as a CSV download
+func respondCSV(c *gin.Context, name string, header []string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	w.WriteAll(rows)
+}
+
+func respondDocumentError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrNoERPCustomer) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": gin.H{
+				"code":    "NO_ERP_CUSTOMER",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrShipmentStatusFilter) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusBadGateway, gin.H{
+		"error": gin.H{
+			"code":    "ERP_ERROR",
+			"message": err.Error(),
+		},
+	})
+}
+
+// formatDate formats a date for CSV exports; zero dates are left empty
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/middleware"
+	"github.com/gondolia/gondolia/services/order/internal/service"
+)
+
+// ERPTransmissionHandler handles the operator endpoints of the ERP outbox
+type ERPTransmissionHandler struct {
+	dispatcher *service.ERPDispatcher
+}
+
+// NewERPTransmissionHandler creates a new ERP transmission handler
+func NewERPTransmissionHandler(dispatcher *service.ERPDispatcher) *ERPTransmissionHandler {
+	return &ERPTransmissionHandler{
+		dispatcher: dispatcher,
+	}
+}
+
+// RegisterRoutes registers the ERP transmission endpoints on rg, which must
+// only be reachable by operators and must resolve the tenant
+func (h *ERPTransmissionHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/erp/transmissions", h.List)
+	rg.POST("/erp/transmissions/:id/retry", h.Retry)
+	rg.POST("/erp/transmissions/:id/skip", h.Skip)
+}
+
+// List handles GET /erp/transmissions. Without a status filter it lists the
+// stuck transmissions: failed ones and those being retried.
+func (h *ERPTransmissionHandler) List(c *gin.Context) {
+	filter := domain.TransmissionFilter{
+		TenantID: middleware.GetTenantID(c),
+		Limit:    50,
+		Offset:   0,
+	}
+
+	switch status := c.Query("status"); status {
+	case "", "stuck":
+		filter.Stuck = true
+	case string(domain.TransmissionStatusPending), string(domain.TransmissionStatusSent),
+		string(domain.TransmissionStatusFailed), string(domain.TransmissionStatusSkipped):
+		s := domain.TransmissionStatus(status)
+		filter.Status = &s
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": "status must be one of stuck, pending, sent, failed, skipped, all",
+			},
+		})
+		return
+	}
+
+	// Pagination
+	if limit := parseInt(c.Query("limit"), 50); limit > 0 {
+		filter.Limit = limit
+	}
+	if offset := parseInt(c.Query("offset"), 0); offset >= 0 {
+		filter.Offset = offset
+	}
+
+	transmissions, total, err := h.dispatcher.ListTransmissions(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   transmissions,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// Retry handles POST /erp/transmissions/:id/retry
+func (h *ERPTransmissionHandler) Retry(c *gin.Context) {
+	transmissionID, ok := parseTransmissionID(c)
+	if !ok {
+		return
+	}
+
+	transmission, err := h.dispatcher.Retry(c.Request.Context(), middleware.GetTenantID(c), transmissionID)
+	if err != nil {
+		respondTransmissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": transmission,
+	})
+}
+
+// skipRequest is the optional body of a skip request
+type skipRequest struct {
+	ERPOrderNumber string `json:"erp_order_number"` // Set if the order was entered in the ERP manually
+	Note           string `json:"note"`
+}
+
+// Skip handles POST /erp/transmissions/:id/skip
+func (h *ERPTransmissionHandler) Skip(c *gin.Context) {
+	transmissionID, ok := parseTransmissionID(c)
+	if !ok {
+		return
+	}
+
+	var req skipRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_REQUEST",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	transmission, err := h.dispatcher.Skip(c.Request.Context(), middleware.GetTenantID(c), transmissionID,
+		middleware.GetUserID(c), req.ERPOrderNumber, req.Note)
+	if err != nil {
+		respondTransmissionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": transmission,
+	})
+}
+
+// parseTransmissionID parses the :id parameter, responding with an error if it is invalid
+func parseTransmissionID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": gin.H{
+				"code":    "INVALID_ID",
+				"message": "invalid transmission ID",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondTransmissionError maps a dispatcher error to a response
+func respondTransmissionError(c *gin.Context, err error) {
+	if domain.IsNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": gin.H{
+				"code":    "NOT_FOUND",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	if domain.IsValidationError(err) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": gin.H{
+			"code":    "INTERNAL_ERROR",
+			"message": err.Error(),
+		},
+	})
+}
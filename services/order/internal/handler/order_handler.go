@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +50,8 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 		})
 		return
 	}
+	req.CompanyID = middleware.GetCompanyID(c)
+	req.ERPCustomerID = middleware.GetERPCustomerID(c)
 
 	order, err := h.orderService.Checkout(c.Request.Context(), tenantID, *userID, sessionID, &req)
 	if err != nil {
@@ -84,6 +87,90 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 	})
 }
 
+// Simulate handles POST /orders/simulate
+func (h *OrderHandler) Simulate(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	userID := middleware.GetUserID(c)
+	sessionID := c.GetHeader("X-Session-ID")
+
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "user authentication required",
+			},
+		})
+		return
+	}
+
+	var req domain.SimulateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "INVALID_REQUEST",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+	}
+	req.ERPCustomerID = middleware.GetERPCustomerID(c)
+
+	simulation, err := h.orderService.Simulate(c.Request.Context(), tenantID, *userID, sessionID, &req)
+	if err != nil {
+		var rejected *domain.ERPRejectedError
+		if errors.As(err, &rejected) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": gin.H{
+					"code":     "ERP_REJECTED",
+					"message":  err.Error(),
+					"messages": rejected.Messages,
+				},
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrSimulationUnsupported) {
+			c.JSON(http.StatusNotImplemented, gin.H{
+				"error": gin.H{
+					"code":    "NOT_SUPPORTED",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		if domain.IsNotFoundError(err) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": gin.H{
+					"code":    "NOT_FOUND",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		if domain.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": gin.H{
+					"code":    "VALIDATION_ERROR",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": simulation,
+	})
+}
+
 // List handles GET /orders
 func (h *OrderHandler) List(c *gin.Context) {
 	tenantID := middleware.GetTenantID(c)
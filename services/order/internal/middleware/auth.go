@@ -9,7 +9,8 @@ import (
 	"github.com/google/uuid"
 )
 
-// AuthMiddleware validates JWT token and extracts user_id
+// AuthMiddleware validates JWT token and extracts user_id, the current company
+// and the permissions of the user's role in it
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Try to get JWT token from Authorization header
@@ -39,7 +40,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Extract user_id from claims
+		// Extract user_id, the current company and permissions from claims
 		if claims, ok := token.Claims.(jwt.MapClaims); ok {
 			if userIDStr, ok := claims["user_id"].(string); ok {
 				userID, err := uuid.Parse(userIDStr)
@@ -47,6 +48,24 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 					c.Set(ContextKeyUserID, userID)
 				}
 			}
+			if companyIDStr, ok := claims["company_id"].(string); ok {
+				companyID, err := uuid.Parse(companyIDStr)
+				if err == nil && companyID != uuid.Nil {
+					c.Set(ContextKeyCompanyID, companyID)
+				}
+			}
+			if customerID, ok := claims["sap_company_number"].(string); ok && customerID != "" {
+				c.Set(ContextKeyERPCustomerID, customerID)
+			}
+			if values, ok := claims["permissions"].([]any); ok {
+				permissions := make([]string, 0, len(values))
+				for _, v := range values {
+					if p, ok := v.(string); ok {
+						permissions = append(permissions, p)
+					}
+				}
+				c.Set(ContextKeyPermissions, permissions)
+			}
 		}
 
 		c.Next()
@@ -1,14 +1,19 @@
 package middleware
 
 import (
+	"slices"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // Context keys
 const (
-	ContextKeyTenantID = "tenant_id"
-	ContextKeyUserID   = "user_id"
+	ContextKeyTenantID      = "tenant_id"
+	ContextKeyUserID        = "user_id"
+	ContextKeyCompanyID     = "company_id"
+	ContextKeyERPCustomerID = "erp_customer_id"
+	ContextKeyPermissions   = "permissions"
 )
 
 // GetTenantID returns the tenant ID from context
@@ -27,6 +32,28 @@ func GetUserID(c *gin.Context) *uuid.UUID {
 	return &userID
 }
 
+// GetCompanyID returns the current company ID from context
+func GetCompanyID(c *gin.Context) *uuid.UUID {
+	id, exists := c.Get(ContextKeyCompanyID)
+	if !exists {
+		return nil
+	}
+	companyID := id.(uuid.UUID)
+	return &companyID
+}
+
+// GetERPCustomerID returns the ERP customer number of the current company
+func GetERPCustomerID(c *gin.Context) string {
+	return c.GetString(ContextKeyERPCustomerID)
+}
+
+// HasPermission checks if the user's role in the current company grants the permission
+func HasPermission(c *gin.Context, permission string) bool {
+	value, _ := c.Get(ContextKeyPermissions)
+	permissions, _ := value.([]string)
+	return slices.Contains(permissions, permission)
+}
+
 // GetClientIP returns the client IP address
 func GetClientIP(c *gin.Context) string {
 	// Check X-Forwarded-For first (for proxied requests)
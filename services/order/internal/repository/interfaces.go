@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -20,6 +21,9 @@ type OrderRepository interface {
 	GetByOrderNumber(ctx context.Context, tenantID uuid.UUID, orderNumber string) (*domain.Order, error)
 	List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, int, error)
 	Create(ctx context.Context, order *domain.Order) error
+	// CreateWithTransmission saves the order with its items and status history
+	// and the outbox entry transmitting it to the ERP in one transaction
+	CreateWithTransmission(ctx context.Context, order *domain.Order, transmission *domain.ERPTransmission) error
 	Update(ctx context.Context, order *domain.Order) error
 
 	// Order items
@@ -33,3 +37,44 @@ type OrderRepository interface {
 	// Order number generation
 	GenerateOrderNumber(ctx context.Context, tenantID uuid.UUID) (string, error)
 }
+
+// TransmissionRepository defines the interface for the ERP outbox
+type TransmissionRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ERPTransmission, error)
+	List(ctx context.Context, filter domain.TransmissionFilter) ([]domain.ERPTransmission, int, error)
+
+	// ClaimDue leases up to limit pending transmissions that are due, so that
+	// concurrent dispatchers do not transmit the same order twice. Each
+	// claimed transmission carries a new ClaimToken.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPTransmission, error)
+
+	// Update saves the transmission and releases its lease. If order is set,
+	// its status, ERP order number and item confirmations are saved as well;
+	// if log is set, it is added to the order's status history. All in one
+	// transaction.
+	//
+	// A claimed transmission is only saved while its ClaimToken still holds
+	// the lease (ErrTransmissionLeaseLost); any other only while no
+	// dispatcher holds a lease (ErrTransmissionInFlight).
+	Update(ctx context.Context, transmission *domain.ERPTransmission, order *domain.Order, log *domain.OrderStatusLog) error
+}
+
+// StatusSyncRepository defines the interface for polling the ERP for the
+// status of open orders
+type StatusSyncRepository interface {
+	// ClaimDue leases up to limit open orders whose status sync is due, so
+	// that concurrent workers do not poll the same order
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPStatusSync, error)
+
+	// Update saves the sync state. If logs are given, the order moves to the
+	// status of the last log and the logs are added to its status history,
+	// provided the order is still in the status the first log changes from.
+	// All in one transaction.
+	Update(ctx context.Context, sync *domain.ERPStatusSync, logs []domain.OrderStatusLog) error
+}
+
+// SimulationRepository defines the interface for checkout simulations
+type SimulationRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.OrderSimulation, error)
+	Create(ctx context.Context, simulation *domain.OrderSimulation) error
+}
@@ -24,7 +24,8 @@ func NewOrderRepository(db *DB) *OrderRepository {
 func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
 	query := `
 		SELECT id, tenant_id, user_id, order_number, status, subtotal, tax_amount, total,
-		       currency, shipping_address, billing_address, notes, created_at, updated_at
+		       currency, shipping_address, billing_address, notes, created_at, updated_at,
+		       company_id, COALESCE(erp_customer_id, ''), COALESCE(erp_order_number, ''), simulation_id
 		FROM orders
 		WHERE id = $1
 	`
@@ -54,7 +55,8 @@ func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Or
 func (r *OrderRepository) GetByOrderNumber(ctx context.Context, tenantID uuid.UUID, orderNumber string) (*domain.Order, error) {
 	query := `
 		SELECT id, tenant_id, user_id, order_number, status, subtotal, tax_amount, total,
-		       currency, shipping_address, billing_address, notes, created_at, updated_at
+		       currency, shipping_address, billing_address, notes, created_at, updated_at,
+		       company_id, COALESCE(erp_customer_id, ''), COALESCE(erp_order_number, ''), simulation_id
 		FROM orders
 		WHERE tenant_id = $1 AND order_number = $2
 	`
@@ -115,6 +117,7 @@ func (r *OrderRepository) List(ctx context.Context, filter domain.OrderFilter) (
 	query := fmt.Sprintf(`
 		SELECT o.id, o.tenant_id, o.user_id, o.order_number, o.status, o.subtotal, o.tax_amount, o.total,
 		       o.currency, o.shipping_address, o.billing_address, o.notes, o.created_at, o.updated_at,
+		       o.company_id, COALESCE(o.erp_customer_id, ''), COALESCE(o.erp_order_number, ''), o.simulation_id,
 		       (SELECT COUNT(*) FROM order_items WHERE order_id = o.id) as item_count
 		FROM orders o
 		WHERE %s
@@ -143,6 +146,35 @@ func (r *OrderRepository) List(ctx context.Context, filter domain.OrderFilter) (
 }
 
 func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error {
+	return insertOrder(ctx, r.db.Pool, order)
+}
+
+func (r *OrderRepository) CreateWithTransmission(ctx context.Context, order *domain.Order, transmission *domain.ERPTransmission) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	if err := insertItems(ctx, tx, order.Items); err != nil {
+		return fmt.Errorf("failed to create order items: %w", err)
+	}
+	for i := range order.StatusHistory {
+		if err := insertStatusLog(ctx, tx, &order.StatusHistory[i]); err != nil {
+			return fmt.Errorf("failed to add status log: %w", err)
+		}
+	}
+	if err := insertTransmission(ctx, tx, transmission); err != nil {
+		return fmt.Errorf("failed to create erp transmission: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertOrder(ctx context.Context, q querier, order *domain.Order) error {
 	shippingAddrJSON, err := json.Marshal(order.ShippingAddress)
 	if err != nil {
 		return fmt.Errorf("failed to marshal shipping address: %w", err)
@@ -155,11 +187,12 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 
 	query := `
 		INSERT INTO orders (id, tenant_id, user_id, order_number, status, subtotal, tax_amount, total,
-		                    currency, shipping_address, billing_address, notes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		                    currency, shipping_address, billing_address, notes, created_at, updated_at,
+		                    company_id, erp_customer_id, erp_order_number, simulation_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), NULLIF($17, ''), $18)
 	`
 
-	_, err = r.db.Pool.Exec(ctx, query,
+	_, err = q.Exec(ctx, query,
 		order.ID,
 		order.TenantID,
 		order.UserID,
@@ -174,6 +207,10 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 		order.Notes,
 		order.CreatedAt,
 		order.UpdatedAt,
+		order.CompanyID,
+		order.ERPCustomerID,
+		order.ERPOrderNumber,
+		order.SimulationID,
 	)
 
 	return err
@@ -214,6 +251,10 @@ func (r *OrderRepository) Update(ctx context.Context, order *domain.Order) error
 }
 
 func (r *OrderRepository) CreateItems(ctx context.Context, items []domain.OrderItem) error {
+	return insertItems(ctx, r.db.Pool, items)
+}
+
+func insertItems(ctx context.Context, q querier, items []domain.OrderItem) error {
 	if len(items) == 0 {
 		return nil
 	}
@@ -259,14 +300,15 @@ func (r *OrderRepository) CreateItems(ctx context.Context, items []domain.OrderI
 		VALUES %s
 	`, strings.Join(valueStrings, ","))
 
-	_, err := r.db.Pool.Exec(ctx, query, valueArgs...)
+	_, err := q.Exec(ctx, query, valueArgs...)
 	return err
 }
 
 func (r *OrderRepository) GetItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]domain.OrderItem, error) {
 	query := `
 		SELECT id, order_id, product_id, variant_id, product_type, product_name, sku,
-		       quantity, unit_price, total_price, currency, configuration, created_at
+		       quantity, unit_price, total_price, currency, configuration, created_at,
+		       COALESCE(erp_item_number, ''), confirmed_quantity, confirmed_price
 		FROM order_items
 		WHERE order_id = $1
 		ORDER BY created_at
@@ -291,18 +333,29 @@ func (r *OrderRepository) GetItemsByOrderID(ctx context.Context, orderID uuid.UU
 }
 
 func (r *OrderRepository) AddStatusLog(ctx context.Context, log *domain.OrderStatusLog) error {
+	return insertStatusLog(ctx, r.db.Pool, log)
+}
+
+func insertStatusLog(ctx context.Context, q querier, log *domain.OrderStatusLog) error {
+	messagesJSON, err := marshalMessages(log.Messages)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO order_status_history (id, order_id, from_status, to_status, changed_by, note, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO order_status_history (id, order_id, from_status, to_status, changed_by, actor, note, messages, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9)
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err = q.Exec(ctx, query,
 		log.ID,
 		log.OrderID,
 		log.FromStatus,
 		log.ToStatus,
 		log.ChangedBy,
+		log.Actor,
 		log.Note,
+		messagesJSON,
 		log.CreatedAt,
 	)
 
@@ -311,7 +364,7 @@ func (r *OrderRepository) AddStatusLog(ctx context.Context, log *domain.OrderSta
 
 func (r *OrderRepository) GetStatusHistory(ctx context.Context, orderID uuid.UUID) ([]domain.OrderStatusLog, error) {
 	query := `
-		SELECT id, order_id, from_status, to_status, changed_by, note, created_at
+		SELECT id, order_id, from_status, to_status, changed_by, COALESCE(actor, ''), note, messages, created_at
 		FROM order_status_history
 		WHERE order_id = $1
 		ORDER BY created_at
@@ -375,6 +428,10 @@ func (r *OrderRepository) scanOrder(row pgx.Row) (*domain.Order, error) {
 		&order.Notes,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.CompanyID,
+		&order.ERPCustomerID,
+		&order.ERPOrderNumber,
+		&order.SimulationID,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -413,6 +470,10 @@ func (r *OrderRepository) scanOrderFromRows(rows pgx.Rows) (*domain.Order, error
 		&order.Notes,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.CompanyID,
+		&order.ERPCustomerID,
+		&order.ERPOrderNumber,
+		&order.SimulationID,
 	)
 	if err != nil {
 		return nil, err
@@ -448,6 +509,10 @@ func (r *OrderRepository) scanOrderFromRowsWithCount(rows pgx.Rows) (*domain.Ord
 		&order.Notes,
 		&order.CreatedAt,
 		&order.UpdatedAt,
+		&order.CompanyID,
+		&order.ERPCustomerID,
+		&order.ERPOrderNumber,
+		&order.SimulationID,
 		&order.ItemCount,
 	)
 	if err != nil {
@@ -483,6 +548,9 @@ func (r *OrderRepository) scanOrderItem(rows pgx.Rows) (*domain.OrderItem, error
 		&item.Currency,
 		&configJSON,
 		&item.CreatedAt,
+		&item.ERPItemNumber,
+		&item.ConfirmedQuantity,
+		&item.ConfirmedPrice,
 	)
 	if err != nil {
 		return nil, err
@@ -500,6 +568,7 @@ func (r *OrderRepository) scanOrderItem(rows pgx.Rows) (*domain.OrderItem, error
 func (r *OrderRepository) scanStatusLog(rows pgx.Rows) (*domain.OrderStatusLog, error) {
 	var log domain.OrderStatusLog
 	var fromStatus *string
+	var messagesJSON []byte
 
 	err := rows.Scan(
 		&log.ID,
@@ -507,13 +576,21 @@ func (r *OrderRepository) scanStatusLog(rows pgx.Rows) (*domain.OrderStatusLog,
 		&fromStatus,
 		&log.ToStatus,
 		&log.ChangedBy,
+		&log.Actor,
 		&log.Note,
+		&messagesJSON,
 		&log.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(messagesJSON) > 0 {
+		if err := json.Unmarshal(messagesJSON, &log.Messages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+		}
+	}
+
 	if fromStatus != nil {
 		status := domain.OrderStatus(*fromStatus)
 		log.FromStatus = &status
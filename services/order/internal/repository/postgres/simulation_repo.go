@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+type SimulationRepository struct {
+	db *DB
+}
+
+func NewSimulationRepository(db *DB) *SimulationRepository {
+	return &SimulationRepository{db: db}
+}
+
+func (r *SimulationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.OrderSimulation, error) {
+	query := `
+		SELECT data
+		FROM order_simulations
+		WHERE id = $1
+	`
+
+	var data []byte
+	if err := r.db.Pool.QueryRow(ctx, query, id).Scan(&data); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrSimulationNotFound
+		}
+		return nil, err
+	}
+
+	var simulation domain.OrderSimulation
+	if err := json.Unmarshal(data, &simulation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal simulation: %w", err)
+	}
+
+	return &simulation, nil
+}
+
+func (r *SimulationRepository) Create(ctx context.Context, simulation *domain.OrderSimulation) error {
+	data, err := json.Marshal(simulation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal simulation: %w", err)
+	}
+
+	query := `
+		INSERT INTO order_simulations (id, tenant_id, user_id, data, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		simulation.ID,
+		simulation.TenantID,
+		simulation.UserID,
+		data,
+		simulation.CreatedAt,
+		simulation.ExpiresAt,
+	)
+
+	return err
+}
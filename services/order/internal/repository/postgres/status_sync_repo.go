@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+// statusSyncColumns selects the ERP status sync state of an order
+const statusSyncColumns = `
+	id, tenant_id, order_number, erp_order_number, COALESCE(erp_status, ''),
+	erp_status_failures, COALESCE(erp_status_error, ''), erp_status_next_sync_at, erp_status_synced_at`
+
+type StatusSyncRepository struct {
+	db *DB
+}
+
+func NewStatusSyncRepository(db *DB) *StatusSyncRepository {
+	return &StatusSyncRepository{db: db}
+}
+
+func (r *StatusSyncRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPStatusSync, error) {
+	// The lease postpones the next sync, so the order is not claimed again
+	// until Update schedules it or the lease expires
+	query := `
+		UPDATE orders
+		SET erp_status_next_sync_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM orders
+			WHERE status IN ('confirmed', 'processing', 'shipped') AND erp_order_number IS NOT NULL
+			  AND (erp_status_next_sync_at IS NULL OR erp_status_next_sync_at <= NOW())
+			ORDER BY erp_status_next_sync_at NULLS FIRST
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING` + statusSyncColumns
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var syncs []domain.ERPStatusSync
+	for rows.Next() {
+		s, err := r.scanStatusSync(rows)
+		if err != nil {
+			return nil, err
+		}
+		syncs = append(syncs, *s)
+	}
+
+	return syncs, rows.Err()
+}
+
+func (r *StatusSyncRepository) Update(ctx context.Context, sync *domain.ERPStatusSync, logs []domain.OrderStatusLog) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE orders
+		SET erp_status = NULLIF($2, ''), erp_status_failures = $3, erp_status_error = NULLIF($4, ''),
+		    erp_status_next_sync_at = $5, erp_status_synced_at = $6
+		WHERE id = $1
+	`
+
+	tag, err := tx.Exec(ctx, query,
+		sync.OrderID,
+		sync.ERPStatus,
+		sync.Failures,
+		sync.LastError,
+		sync.NextSyncAt,
+		sync.SyncedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOrderNotFound
+	}
+
+	if len(logs) > 0 {
+		if err := updateOrderStatus(ctx, tx, sync, logs); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// updateOrderStatus moves the order along the status logs and adds them to
+// its history. If the order's status changed since it was loaded, e.g. it was
+// cancelled by the customer, nothing is changed and the next sync starts from
+// the new status.
+func updateOrderStatus(ctx context.Context, q querier, sync *domain.ERPStatusSync, logs []domain.OrderStatusLog) error {
+	first, last := logs[0], logs[len(logs)-1]
+	if first.FromStatus == nil {
+		return fmt.Errorf("status log of order %s has no previous status", sync.OrderNumber)
+	}
+
+	query := `
+		UPDATE orders
+		SET status = $3, updated_at = $4
+		WHERE id = $1 AND status = $2
+	`
+
+	tag, err := q.Exec(ctx, query, sync.OrderID, *first.FromStatus, last.ToStatus, last.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	for i := range logs {
+		if err := insertStatusLog(ctx, q, &logs[i]); err != nil {
+			return fmt.Errorf("failed to add status log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *StatusSyncRepository) scanStatusSync(row pgx.Row) (*domain.ERPStatusSync, error) {
+	var s domain.ERPStatusSync
+	var nextSyncAt *time.Time
+
+	err := row.Scan(
+		&s.OrderID,
+		&s.TenantID,
+		&s.OrderNumber,
+		&s.ERPOrderNumber,
+		&s.ERPStatus,
+		&s.Failures,
+		&s.LastError,
+		&nextSyncAt,
+		&s.SyncedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	if nextSyncAt != nil {
+		s.NextSyncAt = *nextSyncAt
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+// transmissionColumns selects an erp_outbox row t joined with its order o
+const transmissionColumns = `
+	t.id, t.tenant_id, t.order_id, o.order_number, t.status, t.attempts, t.next_attempt_at,
+	COALESCE(t.last_error, ''), t.messages, COALESCE(t.erp_order_number, ''),
+	t.created_at, t.updated_at, t.sent_at, t.locked_until, t.claim_token`
+
+type TransmissionRepository struct {
+	db *DB
+}
+
+func NewTransmissionRepository(db *DB) *TransmissionRepository {
+	return &TransmissionRepository{db: db}
+}
+
+func (r *TransmissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ERPTransmission, error) {
+	query := `SELECT` + transmissionColumns + `
+		FROM erp_outbox t
+		JOIN orders o ON o.id = t.order_id
+		WHERE t.id = $1
+	`
+
+	return r.scanTransmission(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+func (r *TransmissionRepository) List(ctx context.Context, filter domain.TransmissionFilter) ([]domain.ERPTransmission, int, error) {
+	var conditions []string
+	var args []any
+	argNum := 1
+
+	conditions = append(conditions, fmt.Sprintf("t.tenant_id = $%d", argNum))
+	args = append(args, filter.TenantID)
+	argNum++
+
+	if filter.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("t.status = $%d", argNum))
+		args = append(args, *filter.Status)
+		argNum++
+	}
+
+	if filter.Stuck {
+		conditions = append(conditions, "(t.status = 'failed' OR (t.status = 'pending' AND t.attempts > 0))")
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	// Count query
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM erp_outbox t WHERE %s", whereClause)
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Data query, oldest first
+	query := fmt.Sprintf(`SELECT %s
+		FROM erp_outbox t
+		JOIN orders o ON o.id = t.order_id
+		WHERE %s
+		ORDER BY t.created_at
+		LIMIT $%d OFFSET $%d
+	`, transmissionColumns, whereClause, argNum, argNum+1)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transmissions []domain.ERPTransmission
+	for rows.Next() {
+		t, err := r.scanTransmission(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transmissions = append(transmissions, *t)
+	}
+
+	return transmissions, total, rows.Err()
+}
+
+func (r *TransmissionRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPTransmission, error) {
+	// Rows locked by another dispatcher are skipped; the lease keeps them
+	// claimed after this statement commits until Update releases them
+	query := `
+		UPDATE erp_outbox t
+		SET locked_until = NOW() + $2 * INTERVAL '1 millisecond', claim_token = gen_random_uuid()
+		FROM orders o
+		WHERE o.id = t.order_id AND t.id IN (
+			SELECT id FROM erp_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND (locked_until IS NULL OR locked_until < NOW())
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING` + transmissionColumns
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transmissions []domain.ERPTransmission
+	for rows.Next() {
+		t, err := r.scanTransmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		transmissions = append(transmissions, *t)
+	}
+
+	return transmissions, rows.Err()
+}
+
+func (r *TransmissionRepository) Update(ctx context.Context, transmission *domain.ERPTransmission, order *domain.Order, log *domain.OrderStatusLog) error {
+	messagesJSON, err := marshalMessages(transmission.Messages)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE erp_outbox
+		SET status = $2, attempts = $3, next_attempt_at = $4, locked_until = NULL, claim_token = NULL,
+		    last_error = NULLIF($5, ''), messages = $6, erp_order_number = NULLIF($7, ''),
+		    updated_at = $8, sent_at = $9
+		WHERE id = $1 AND CASE
+			WHEN $10::uuid IS NULL THEN locked_until IS NULL OR locked_until <= NOW()
+			ELSE claim_token = $10 AND locked_until > NOW()
+		END
+	`
+
+	tag, err := tx.Exec(ctx, query,
+		transmission.ID,
+		transmission.Status,
+		transmission.Attempts,
+		transmission.NextAttemptAt,
+		transmission.LastError,
+		messagesJSON,
+		transmission.ERPOrderNumber,
+		transmission.UpdatedAt,
+		transmission.SentAt,
+		transmission.ClaimToken,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		// The transmission was loaded or claimed before, so it exists
+		if transmission.ClaimToken != nil {
+			return domain.ErrTransmissionLeaseLost
+		}
+		return domain.ErrTransmissionInFlight
+	}
+
+	if order != nil {
+		if err := updateOrderConfirmation(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+
+	if log != nil {
+		if err := insertStatusLog(ctx, tx, log); err != nil {
+			return fmt.Errorf("failed to add status log: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertTransmission(ctx context.Context, q querier, transmission *domain.ERPTransmission) error {
+	query := `
+		INSERT INTO erp_outbox (id, tenant_id, order_id, status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := q.Exec(ctx, query,
+		transmission.ID,
+		transmission.TenantID,
+		transmission.OrderID,
+		transmission.Status,
+		transmission.Attempts,
+		transmission.NextAttemptAt,
+		transmission.CreatedAt,
+		transmission.UpdatedAt,
+	)
+
+	return err
+}
+
+// updateOrderConfirmation saves the order's status and ERP confirmation.
+// Only pending orders change status, so an order cancelled meanwhile stays cancelled.
+func updateOrderConfirmation(ctx context.Context, q querier, order *domain.Order) error {
+	query := `
+		UPDATE orders
+		SET status = CASE WHEN status = 'pending' THEN $2 ELSE status END,
+		    erp_order_number = NULLIF($3, ''), updated_at = $4
+		WHERE id = $1
+	`
+
+	if _, err := q.Exec(ctx, query, order.ID, order.Status, order.ERPOrderNumber, order.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	itemQuery := `
+		UPDATE order_items
+		SET erp_item_number = NULLIF($2, ''), confirmed_quantity = $3, confirmed_price = $4
+		WHERE id = $1
+	`
+
+	for _, item := range order.Items {
+		if _, err := q.Exec(ctx, itemQuery, item.ID, item.ERPItemNumber, item.ConfirmedQuantity, item.ConfirmedPrice); err != nil {
+			return fmt.Errorf("failed to update order item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// marshalMessages encodes ERP messages as JSONB, or NULL if there are none
+func marshalMessages(messages []domain.ERPMessage) ([]byte, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	return data, nil
+}
+
+func (r *TransmissionRepository) scanTransmission(row pgx.Row) (*domain.ERPTransmission, error) {
+	var t domain.ERPTransmission
+	var messagesJSON []byte
+
+	err := row.Scan(
+		&t.ID,
+		&t.TenantID,
+		&t.OrderID,
+		&t.OrderNumber,
+		&t.Status,
+		&t.Attempts,
+		&t.NextAttemptAt,
+		&t.LastError,
+		&messagesJSON,
+		&t.ERPOrderNumber,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.SentAt,
+		&t.LockedUntil,
+		&t.ClaimToken,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, domain.ErrTransmissionNotFound
+		}
+		return nil, err
+	}
+
+	if len(messagesJSON) > 0 {
+		if err := json.Unmarshal(messagesJSON, &t.Messages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+		}
+	}
+
+	return &t, nil
+}
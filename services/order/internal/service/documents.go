@@ -0,0 +1,239 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+const (
+	documentFetchSize = 500   // Documents per ERP request
+	maxDocuments      = 10000 // Documents read per date range
+)
+
+// DocumentService serves a company's order, invoice and shipment history
+// from the ERP. The ERP cannot filter by status or tell invoices from credit
+// notes, so the whole date range is read, cached briefly per company, and
+// filtered and paged here.
+type DocumentService struct {
+	erpProviders provider.Source[erp.ERPProvider]
+	cache        *documentCache
+}
+
+// NewDocumentService creates a new document service; a cacheTTL of 0
+// disables the cache
+func NewDocumentService(erpProviders provider.Source[erp.ERPProvider], cacheTTL time.Duration) *DocumentService {
+	return &DocumentService{
+		erpProviders: erpProviders,
+		cache:        newDocumentCache(cacheTTL, time.Now),
+	}
+}
+
+// ListOrders returns the company's ERP orders, newest first
+func (s *DocumentService) ListOrders(ctx context.Context, tenantID uuid.UUID, erpCustomerID string, filter domain.DocumentFilter) ([]domain.OrderDocument, error) {
+	reports, err := fetchDocuments(ctx, s, "orders", tenantID, erpCustomerID, filter, erp.ERPProvider.GetOrderHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]domain.OrderDocument, 0, len(reports))
+	for _, r := range reports {
+		if matchesStatus(filter, r.Status) {
+			documents = append(documents, domain.OrderDocument{
+				ERPOrderNumber: r.ERPOrderNumber,
+				OrderDate:      r.OrderDate,
+				CustomerPO:     r.CustomerPO,
+				TotalAmount:    r.TotalAmount,
+				Currency:       r.Currency,
+				Status:         r.Status,
+			})
+		}
+	}
+	newestFirst(documents, func(d domain.OrderDocument) (time.Time, string) { return d.OrderDate, d.ERPOrderNumber })
+	return page(documents, filter), nil
+}
+
+// ListInvoices returns the company's ERP invoices, or its credit notes if
+// credits is set, newest first
+func (s *DocumentService) ListInvoices(ctx context.Context, tenantID uuid.UUID, erpCustomerID string, filter domain.DocumentFilter, credits bool) ([]domain.InvoiceDocument, error) {
+	reports, err := fetchDocuments(ctx, s, "invoices", tenantID, erpCustomerID, filter, erp.ERPProvider.GetInvoiceHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]domain.InvoiceDocument, 0, len(reports))
+	for _, r := range reports {
+		document := domain.InvoiceDocument{
+			InvoiceNumber: r.InvoiceNumber,
+			OrderNumber:   r.OrderNumber,
+			InvoiceDate:   r.InvoiceDate,
+			DueDate:       r.DueDate,
+			Amount:        r.Amount,
+			Currency:      r.Currency,
+			Status:        r.Status,
+		}
+		if document.IsCredit() == credits && matchesStatus(filter, r.Status) {
+			documents = append(documents, document)
+		}
+	}
+	newestFirst(documents, func(d domain.InvoiceDocument) (time.Time, string) { return d.InvoiceDate, d.InvoiceNumber })
+	return page(documents, filter), nil
+}
+
+// ListShipments returns the company's ERP shipments, newest first.
+// Shipments have no status to filter by.
+func (s *DocumentService) ListShipments(ctx context.Context, tenantID uuid.UUID, erpCustomerID string, filter domain.DocumentFilter) ([]domain.ShipmentDocument, error) {
+	if filter.Status != "" {
+		return nil, domain.ErrShipmentStatusFilter
+	}
+	reports, err := fetchDocuments(ctx, s, "shipments", tenantID, erpCustomerID, filter, erp.ERPProvider.GetShipmentHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]domain.ShipmentDocument, 0, len(reports))
+	for _, r := range reports {
+		documents = append(documents, domain.ShipmentDocument{
+			ShipmentID:     r.ShipmentID,
+			OrderNumber:    r.OrderNumber,
+			ShipDate:       r.ShipDate,
+			TrackingNumber: r.TrackingNumber,
+			Carrier:        r.Carrier,
+		})
+	}
+	newestFirst(documents, func(d domain.ShipmentDocument) (time.Time, string) { return d.ShipDate, d.ShipmentID })
+	return page(documents, filter), nil
+}
+
+// fetchDocuments returns the company's ERP history in the filter's date
+// range, from the cache if it was fetched recently. The ERP is read in pages
+// of documentFetchSize, up to maxDocuments.
+func fetchDocuments[T any](
+	ctx context.Context,
+	s *DocumentService,
+	kind string,
+	tenantID uuid.UUID,
+	erpCustomerID string,
+	filter domain.DocumentFilter,
+	fetch func(erp.ERPProvider, context.Context, erp.ReportFilter) ([]T, error),
+) ([]T, error) {
+	if erpCustomerID == "" {
+		return nil, domain.ErrNoERPCustomer
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", tenantID, erpCustomerID, kind,
+		filter.DateFrom.Format(time.DateOnly), filter.DateTo.Format(time.DateOnly))
+	if cached, ok := s.cache.get(key); ok {
+		return cached.([]T), nil
+	}
+
+	ctx = provider.WithTenant(ctx, tenantID.String())
+	erpProvider, err := s.erpProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []T
+	for len(reports) < maxDocuments {
+		batch, err := fetch(erpProvider, ctx, erp.ReportFilter{
+			CustomerID: erpCustomerID,
+			DateFrom:   filter.DateFrom,
+			DateTo:     filter.DateTo,
+			Limit:      documentFetchSize,
+			Offset:     len(reports),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s from ERP: %w", kind, err)
+		}
+		reports = append(reports, batch...)
+		if len(batch) < documentFetchSize {
+			break
+		}
+	}
+
+	s.cache.set(key, reports)
+	return reports, nil
+}
+
+// newestFirst sorts documents by date, newest first, and by number within a
+// date, so that pages do not depend on the ERP's order
+func newestFirst[T any](documents []T, key func(T) (time.Time, string)) {
+	slices.SortFunc(documents, func(a, b T) int {
+		aDate, aNumber := key(a)
+		bDate, bNumber := key(b)
+		if c := bDate.Compare(aDate); c != 0 {
+			return c
+		}
+		return cmp.Compare(bNumber, aNumber)
+	})
+}
+
+// page returns the documents in the filter's page
+func page[T any](documents []T, filter domain.DocumentFilter) []T {
+	if filter.Offset >= len(documents) {
+		return []T{}
+	}
+	documents = documents[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(documents) {
+		documents = documents[:filter.Limit]
+	}
+	return documents
+}
+
+// matchesStatus checks a document's status against the filter, ignoring case
+func matchesStatus(filter domain.DocumentFilter, status string) bool {
+	return filter.Status == "" || strings.EqualFold(filter.Status, status)
+}
+
+// documentCache caches ERP responses for a fixed time
+type documentCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+func newDocumentCache(ttl time.Duration, now func() time.Time) *documentCache {
+	return &documentCache{ttl: ttl, now: now, entries: make(map[string]cacheEntry)}
+}
+
+func (c *documentCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set caches the value and drops expired entries
+func (c *documentCache) set(key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+// fakeHistoryERP serves a fixed invoice history and counts the calls
+type fakeHistoryERP struct {
+	erp.ERPProvider
+	invoices []erp.InvoiceReport
+	orders   []erp.OrderReport
+	filters  []erp.ReportFilter
+}
+
+func (f *fakeHistoryERP) GetOrderHistory(ctx context.Context, req erp.ReportFilter) ([]erp.OrderReport, error) {
+	f.filters = append(f.filters, req)
+	orders := f.orders[min(req.Offset, len(f.orders)):]
+	return orders[:min(req.Limit, len(orders))], nil
+}
+
+func (f *fakeHistoryERP) GetInvoiceHistory(ctx context.Context, req erp.ReportFilter) ([]erp.InvoiceReport, error) {
+	f.filters = append(f.filters, req)
+	return f.invoices, nil
+}
+
+func TestListInvoices_SplitsCreditsAndCaches(t *testing.T) {
+	// The ERP returns the invoices out of order
+	fake := &fakeHistoryERP{invoices: []erp.InvoiceReport{
+		{InvoiceNumber: "90000003", InvoiceDate: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), Amount: 430.5, Currency: "CHF", Status: "CLEARED"},
+		{InvoiceNumber: "90000001", InvoiceDate: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), Amount: 1250, Currency: "CHF", Status: "OPEN"},
+		{InvoiceNumber: "90000002", InvoiceDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Amount: -80, Currency: "CHF", Status: "CLEARED"},
+	}}
+	documents := NewDocumentService(provider.Static[erp.ERPProvider](fake), time.Minute)
+	now := time.Now()
+	documents.cache.now = func() time.Time { return now }
+	tenantID := uuid.New()
+	filter := domain.DocumentFilter{DateFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Limit: 50}
+
+	invoices, err := documents.ListInvoices(context.Background(), tenantID, "1000042", filter, false)
+	if err != nil {
+		t.Fatalf("ListInvoices() error = %v", err)
+	}
+	if len(invoices) != 2 || invoices[0].InvoiceNumber != "90000001" || invoices[1].InvoiceNumber != "90000003" {
+		t.Errorf("invoices = %+v, want 90000001 and 90000003", invoices)
+	}
+	if f := fake.filters[0]; f.CustomerID != "1000042" || !f.DateFrom.Equal(filter.DateFrom) || f.Limit != documentFetchSize {
+		t.Errorf("ERP filter = %+v, want the company and the filter", f)
+	}
+
+	// Credits and the status filter are served from the cache
+	credits, _ := documents.ListInvoices(context.Background(), tenantID, "1000042", filter, true)
+	if len(credits) != 1 || credits[0].InvoiceNumber != "90000002" {
+		t.Errorf("credits = %+v, want 90000002", credits)
+	}
+	filter.Status = "cleared"
+	cleared, _ := documents.ListInvoices(context.Background(), tenantID, "1000042", filter, false)
+	if len(cleared) != 1 || cleared[0].InvoiceNumber != "90000003" {
+		t.Errorf("cleared invoices = %+v, want 90000003", cleared)
+	}
+	if len(fake.filters) != 1 {
+		t.Errorf("ERP calls = %d, want 1", len(fake.filters))
+	}
+
+	// Other companies and expired entries hit the ERP again
+	documents.ListInvoices(context.Background(), tenantID, "1000043", filter, false)
+	now = now.Add(time.Minute)
+	documents.ListInvoices(context.Background(), tenantID, "1000042", filter, false)
+	if len(fake.filters) != 3 {
+		t.Errorf("ERP calls = %d, want 3", len(fake.filters))
+	}
+
+	if _, err := documents.ListInvoices(context.Background(), tenantID, "", filter, false); !errors.Is(err, domain.ErrNoERPCustomer) {
+		t.Errorf("ListInvoices() without customer error = %v, want ErrNoERPCustomer", err)
+	}
+}
+
+func TestListOrders_FiltersBeforePaging(t *testing.T) {
+	fake := &fakeHistoryERP{}
+	for i := range 600 {
+		status := "OPEN"
+		if i%2 == 1 {
+			status = "COMPLETED"
+		}
+		fake.orders = append(fake.orders, erp.OrderReport{ERPOrderNumber: fmt.Sprintf("%d", 1000+i), Status: status})
+	}
+	documents := NewDocumentService(provider.Static[erp.ERPProvider](fake), time.Minute)
+	tenantID := uuid.New()
+
+	// Orders of the same date are sorted by number, newest first
+	orders, err := documents.ListOrders(context.Background(), tenantID, "1000042", domain.DocumentFilter{Status: "open", Limit: 50, Offset: 280})
+	if err != nil {
+		t.Fatalf("ListOrders() error = %v", err)
+	}
+	if len(orders) != 20 || orders[0].ERPOrderNumber != "1038" || orders[19].ERPOrderNumber != "1000" {
+		t.Errorf("orders = %+v, want the oldest 20 open orders from 1038", orders)
+	}
+	if len(fake.filters) != 2 || fake.filters[1].Offset != documentFetchSize {
+		t.Errorf("ERP filters = %+v, want the whole history in two pages", fake.filters)
+	}
+
+	if _, err := documents.ListShipments(context.Background(), tenantID, "1000042", domain.DocumentFilter{Status: "open"}); !errors.Is(err, domain.ErrShipmentStatusFilter) {
+		t.Errorf("ListShipments() with status error = %v, want ErrShipmentStatusFilter", err)
+	}
+}
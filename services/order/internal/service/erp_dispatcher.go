@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/provider/resilience"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/repository"
+)
+
+// ERPDispatcherConfig configures the ERP dispatcher
+type ERPDispatcherConfig struct {
+	Interval    time.Duration // How often due transmissions are polled
+	BatchSize   int           // Transmissions dispatched per poll, each claimed on its own
+	MaxAttempts int           // Failed attempts before a transmission needs an operator
+	Backoff     time.Duration // Delay after the first failed attempt, doubled per attempt
+	MaxBackoff  time.Duration
+	Lease       time.Duration // How long a claimed transmission is locked for other dispatchers; must exceed the worst-case ERP call including retries
+}
+
+// ERPDispatcher transmits orders from the ERP outbox to the tenant's ERP,
+// retrying failed transmissions with exponential backoff
+type ERPDispatcher struct {
+	orderRepo        repository.OrderRepository
+	transmissionRepo repository.TransmissionRepository
+	tenantRepo       repository.TenantRepository
+	erpProviders     provider.Source[erp.ERPProvider]
+	cfg              ERPDispatcherConfig
+	now              func() time.Time
+}
+
+// NewERPDispatcher creates a new ERP dispatcher
+func NewERPDispatcher(
+	orderRepo repository.OrderRepository,
+	transmissionRepo repository.TransmissionRepository,
+	tenantRepo repository.TenantRepository,
+	erpProviders provider.Source[erp.ERPProvider],
+	cfg ERPDispatcherConfig,
+) *ERPDispatcher {
+	return &ERPDispatcher{
+		orderRepo:        orderRepo,
+		transmissionRepo: transmissionRepo,
+		tenantRepo:       tenantRepo,
+		erpProviders:     erpProviders,
+		cfg:              cfg,
+		now:              time.Now,
+	}
+}
+
+// Run dispatches due transmissions every interval until ctx ends. Errors
+// other than failed transmissions, which are recorded on the order, are
+// passed to onError.
+func (d *ERPDispatcher) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue transmits the orders whose transmission is due and returns
+// the number of transmissions processed. Transmissions are claimed one at a
+// time, so that the lease only has to cover a single ERP call.
+func (d *ERPDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	var processed int
+	var errs []error
+	for processed < d.cfg.BatchSize {
+		transmissions, err := d.transmissionRepo.ClaimDue(ctx, 1, d.cfg.Lease)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to claim erp transmissions: %w", err))
+			break
+		}
+		if len(transmissions) == 0 {
+			break
+		}
+
+		processed++
+		if err := d.dispatch(ctx, &transmissions[0]); err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", transmissions[0].OrderNumber, err))
+		}
+	}
+
+	return processed, errors.Join(errs...)
+}
+
+// ListTransmissions lists the tenant's transmissions
+func (d *ERPDispatcher) ListTransmissions(ctx context.Context, filter domain.TransmissionFilter) ([]domain.ERPTransmission, int, error) {
+	return d.transmissionRepo.List(ctx, filter)
+}
+
+// Retry schedules a stuck transmission for immediate transmission with a
+// fresh set of attempts. Transmissions being dispatched cannot be retried.
+func (d *ERPDispatcher) Retry(ctx context.Context, tenantID, transmissionID uuid.UUID) (*domain.ERPTransmission, error) {
+	t, err := d.getTransmission(ctx, tenantID, transmissionID)
+	if err != nil {
+		return nil, err
+	}
+	now := d.now()
+	if err := checkOperable(t, now); err != nil {
+		return nil, err
+	}
+
+	t.Status = domain.TransmissionStatusPending
+	t.Attempts = 0
+	t.NextAttemptAt = now
+	t.UpdatedAt = now
+
+	if err := d.transmissionRepo.Update(ctx, t, nil, nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Skip gives up on transmitting an order, e.g. because it was entered in the
+// ERP manually. A pending order is confirmed with the given ERP order number.
+// Transmissions being dispatched cannot be skipped.
+func (d *ERPDispatcher) Skip(ctx context.Context, tenantID, transmissionID uuid.UUID, userID *uuid.UUID, erpOrderNumber, note string) (*domain.ERPTransmission, error) {
+	t, err := d.getTransmission(ctx, tenantID, transmissionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOperable(t, d.now()); err != nil {
+		return nil, err
+	}
+
+	order, err := d.orderRepo.GetByID(ctx, t.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := d.now()
+	t.Status = domain.TransmissionStatusSkipped
+	t.ERPOrderNumber = erpOrderNumber
+	t.UpdatedAt = now
+
+	fromStatus := order.Status
+	if order.Status == domain.OrderStatusPending {
+		order.Status = domain.OrderStatusConfirmed
+	}
+	if erpOrderNumber != "" {
+		order.ERPOrderNumber = erpOrderNumber
+	}
+	order.UpdatedAt = now
+
+	logNote := "ERP transmission skipped by operator"
+	if note != "" {
+		logNote += ": " + note
+	}
+	statusLog := &domain.OrderStatusLog{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: &fromStatus,
+		ToStatus:   order.Status,
+		ChangedBy:  userID,
+		Note:       logNote,
+		CreatedAt:  now,
+	}
+
+	if err := d.transmissionRepo.Update(ctx, t, order, statusLog); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// getTransmission loads a transmission of the tenant
+func (d *ERPDispatcher) getTransmission(ctx context.Context, tenantID, transmissionID uuid.UUID) (*domain.ERPTransmission, error) {
+	t, err := d.transmissionRepo.GetByID(ctx, transmissionID)
+	if err != nil {
+		return nil, err
+	}
+	if t.TenantID != tenantID {
+		return nil, domain.ErrTransmissionNotFound
+	}
+	return t, nil
+}
+
+// checkOperable checks if an operator may retry or skip the transmission.
+// Update checks the lease again, for dispatchers claiming it meanwhile.
+func checkOperable(t *domain.ERPTransmission, now time.Time) error {
+	if t.Status == domain.TransmissionStatusSent {
+		return domain.ErrTransmissionAlreadySent
+	}
+	if t.IsInFlight(now) {
+		return domain.ErrTransmissionInFlight
+	}
+	return nil
+}
+
+// dispatch transmits one order and records the outcome. Failed
+// transmissions are recorded, only errors saving the outcome are returned.
+func (d *ERPDispatcher) dispatch(ctx context.Context, t *domain.ERPTransmission) error {
+	order, err := d.orderRepo.GetByID(ctx, t.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status == domain.OrderStatusCancelled {
+		t.Status = domain.TransmissionStatusSkipped
+		t.LastError = "order was cancelled before it was transmitted"
+		t.UpdatedAt = d.now()
+		return d.transmissionRepo.Update(ctx, t, nil, nil)
+	}
+
+	result, err := d.createOrder(ctx, order)
+	switch {
+	case err != nil:
+		return d.recordFailure(ctx, t, order, err.Error(), erp.ErrorMessages(err), resilience.IsPermanent(err))
+	case result.ERPOrderNumber == "" && erp.HasErrors(result.Messages):
+		// Some ERPs reject orders with error messages instead of an error
+		return d.recordFailure(ctx, t, order, "order rejected by the ERP", result.Messages, true)
+	}
+	return d.recordSuccess(ctx, t, order, result)
+}
+
+// createOrder sends the order to the tenant's ERP
+func (d *ERPDispatcher) createOrder(ctx context.Context, order *domain.Order) (*erp.CreateOrderResult, error) {
+	tenantID := order.TenantID.String()
+	ctx = provider.WithTenant(ctx, tenantID)
+
+	tenant, err := d.tenantRepo.GetByID(ctx, order.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	erpProvider, err := d.erpProviders.For(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return erpProvider.CreateOrder(ctx, newCreateOrderRequest(order, tenant))
+}
+
+// recordSuccess confirms the order with the ERP's order number, quantities and prices
+func (d *ERPDispatcher) recordSuccess(ctx context.Context, t *domain.ERPTransmission, order *domain.Order, result *erp.CreateOrderResult) error {
+	now := d.now()
+	messages := toERPMessages(result.Messages)
+
+	t.Status = domain.TransmissionStatusSent
+	t.Attempts++
+	t.LastError = ""
+	t.Messages = messages
+	t.ERPOrderNumber = result.ERPOrderNumber
+	t.UpdatedAt = now
+	t.SentAt = &now
+
+	fromStatus := order.Status
+	if order.Status == domain.OrderStatusPending {
+		order.Status = domain.OrderStatusConfirmed
+	}
+	order.ERPOrderNumber = result.ERPOrderNumber
+	order.UpdatedAt = now
+	applyConfirmations(order.Items, result.Items)
+
+	statusLog := &domain.OrderStatusLog{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: &fromStatus,
+		ToStatus:   order.Status,
+		Actor:      domain.ActorERP,
+		Note:       fmt.Sprintf("Order transmitted to ERP as %s", result.ERPOrderNumber),
+		Messages:   messages,
+		CreatedAt:  now,
+	}
+
+	return d.transmissionRepo.Update(ctx, t, order, statusLog)
+}
+
+// recordFailure records a failed attempt in the order's status history and
+// schedules the next attempt, unless the failure is permanent or the
+// attempts are used up
+func (d *ERPDispatcher) recordFailure(ctx context.Context, t *domain.ERPTransmission, order *domain.Order, reason string, messages []erp.Message, permanent bool) error {
+	now := d.now()
+
+	t.Attempts++
+	t.LastError = reason
+	t.Messages = toERPMessages(messages)
+	t.UpdatedAt = now
+
+	var note string
+	if permanent || t.Attempts >= d.cfg.MaxAttempts {
+		t.Status = domain.TransmissionStatusFailed
+		note = fmt.Sprintf("ERP transmission failed after %d attempt(s), waiting for an operator: %s", t.Attempts, reason)
+	} else {
+		t.NextAttemptAt = now.Add(d.backoff(t.Attempts))
+		note = fmt.Sprintf("ERP transmission attempt %d failed, retrying: %s", t.Attempts, reason)
+	}
+
+	statusLog := &domain.OrderStatusLog{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: &order.Status,
+		ToStatus:   order.Status,
+		Actor:      domain.ActorERP,
+		Note:       note,
+		Messages:   t.Messages,
+		CreatedAt:  now,
+	}
+
+	return d.transmissionRepo.Update(ctx, t, nil, statusLog)
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (d *ERPDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.Backoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if d.cfg.MaxBackoff > 0 && delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/repository"
+)
+
+// fakeERP answers CreateOrder with the queued results
+type fakeERP struct {
+	erp.ERPProvider
+	requests []erp.CreateOrderRequest
+	results  []func() (*erp.CreateOrderResult, error)
+	simulate func(req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error)
+	status   func(erpOrderNumber string) (*erp.OrderStatus, error)
+}
+
+func (f *fakeERP) GetOrderStatus(ctx context.Context, erpOrderNumber string) (*erp.OrderStatus, error) {
+	return f.status(erpOrderNumber)
+}
+
+func (f *fakeERP) SimulateOrder(ctx context.Context, req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+	return f.simulate(req)
+}
+
+func (f *fakeERP) CreateOrder(ctx context.Context, req erp.CreateOrderRequest) (*erp.CreateOrderResult, error) {
+	f.requests = append(f.requests, req)
+	next := f.results[0]
+	f.results = f.results[1:]
+	return next()
+}
+
+// currentERP is the instance returned by the "dispatcher-test" provider
+var currentERP *fakeERP
+
+func init() {
+	provider.Register[erp.ERPProvider]("erp", "dispatcher-test",
+		provider.Metadata{Name: "dispatcher-test"},
+		func(config map[string]any) (erp.ERPProvider, error) {
+			return currentERP, nil
+		},
+	)
+}
+
+// rejectedError is a provider error carrying ERP messages
+type rejectedError struct {
+	err      error
+	messages []erp.Message
+}
+
+func (e *rejectedError) Error() string              { return e.err.Error() }
+func (e *rejectedError) Unwrap() error              { return e.err }
+func (e *rejectedError) ERPMessages() []erp.Message { return e.messages }
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders map[uuid.UUID]*domain.Order
+}
+
+func (r *fakeOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	copied := *order
+	copied.Items = append([]domain.OrderItem(nil), order.Items...)
+	return &copied, nil
+}
+
+type fakeTenantRepo struct {
+	repository.TenantRepository
+	tenant *domain.Tenant
+}
+
+func (r *fakeTenantRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	return r.tenant, nil
+}
+
+type fakeTransmissionRepo struct {
+	transmissions map[uuid.UUID]domain.ERPTransmission
+	orders        *fakeOrderRepo
+	history       []domain.OrderStatusLog
+	now           func() time.Time
+}
+
+func (r *fakeTransmissionRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.ERPTransmission, error) {
+	t, ok := r.transmissions[id]
+	if !ok {
+		return nil, domain.ErrTransmissionNotFound
+	}
+	return &t, nil
+}
+
+func (r *fakeTransmissionRepo) List(ctx context.Context, filter domain.TransmissionFilter) ([]domain.ERPTransmission, int, error) {
+	var result []domain.ERPTransmission
+	for _, t := range r.transmissions {
+		if !filter.Stuck || t.IsStuck() {
+			result = append(result, t)
+		}
+	}
+	return result, len(result), nil
+}
+
+func (r *fakeTransmissionRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPTransmission, error) {
+	var due []domain.ERPTransmission
+	for id, t := range r.transmissions {
+		if len(due) < limit && t.Status == domain.TransmissionStatusPending && !t.NextAttemptAt.After(r.now()) && !t.IsInFlight(r.now()) {
+			lockedUntil, token := r.now().Add(lease), uuid.New()
+			t.LockedUntil, t.ClaimToken = &lockedUntil, &token
+			r.transmissions[id] = t
+			due = append(due, t)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeTransmissionRepo) Update(ctx context.Context, t *domain.ERPTransmission, order *domain.Order, log *domain.OrderStatusLog) error {
+	current := r.transmissions[t.ID]
+	switch {
+	case t.ClaimToken != nil && (current.ClaimToken == nil || *current.ClaimToken != *t.ClaimToken || !current.IsInFlight(r.now())):
+		return domain.ErrTransmissionLeaseLost
+	case t.ClaimToken == nil && current.IsInFlight(r.now()):
+		return domain.ErrTransmissionInFlight
+	}
+	saved := *t
+	saved.LockedUntil, saved.ClaimToken = nil, nil
+	r.transmissions[t.ID] = saved
+	if order != nil {
+		r.orders.orders[order.ID] = order
+	}
+	if log != nil {
+		r.history = append(r.history, *log)
+	}
+	return nil
+}
+
+type dispatcherFixture struct {
+	dispatcher    *ERPDispatcher
+	transmissions *fakeTransmissionRepo
+	orders        *fakeOrderRepo
+	order         *domain.Order
+	transmission  *domain.ERPTransmission
+	clock         time.Time
+}
+
+func newDispatcherFixture(t *testing.T, results ...func() (*erp.CreateOrderResult, error)) *dispatcherFixture {
+	t.Helper()
+	currentERP = &fakeERP{results: results}
+
+	tenant := domain.NewTenant("demo", "Demo")
+	tenant.Config["erp"] = map[string]any{"sales_org": "1000", "customer_id": "ONE-TIME"}
+
+	order := domain.NewOrder(tenant.ID, uuid.New(), "ORD-20240502-0001")
+	order.Status = domain.OrderStatusPending
+	order.ERPCustomerID = "1000042"
+	order.Currency = "CHF"
+	order.ShippingAddress = map[string]any{"company": "Muster AG", "street": "Bahnhofstrasse 1", "postalCode": "8001", "city": "Zürich", "country": "ch"}
+	order.Items = []domain.OrderItem{
+		{ID: uuid.New(), OrderID: order.ID, SKU: "4711", Quantity: 10},
+		{ID: uuid.New(), OrderID: order.ID, SKU: "4712", Quantity: 2},
+	}
+
+	f := &dispatcherFixture{
+		orders: &fakeOrderRepo{orders: map[uuid.UUID]*domain.Order{order.ID: order}},
+		order:  order,
+		clock:  order.CreatedAt,
+	}
+	f.transmission = domain.NewERPTransmission(order)
+	f.transmissions = &fakeTransmissionRepo{
+		transmissions: map[uuid.UUID]domain.ERPTransmission{f.transmission.ID: *f.transmission},
+		orders:        f.orders,
+		now:           func() time.Time { return f.clock },
+	}
+
+	resolver := provider.NewResolver(nil)
+	resolver.SetDefault("erp", provider.Selection{Name: "dispatcher-test"})
+	f.dispatcher = NewERPDispatcher(f.orders, f.transmissions, &fakeTenantRepo{tenant: tenant},
+		provider.NewSource[erp.ERPProvider](resolver, "erp"),
+		ERPDispatcherConfig{BatchSize: 10, MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour, Lease: 5 * time.Minute},
+	)
+	f.dispatcher.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *dispatcherFixture) dispatch(t *testing.T) domain.ERPTransmission {
+	t.Helper()
+	if _, err := f.dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue() error = %v", err)
+	}
+	return f.transmissions.transmissions[f.transmission.ID]
+}
+
+func TestDispatchDue_RetriesAndConfirmsOrder(t *testing.T) {
+	f := newDispatcherFixture(t,
+		func() (*erp.CreateOrderResult, error) {
+			return nil, &rejectedError{err: errors.New("HTTP 503"), messages: []erp.Message{{Type: "error", Code: "503", Message: "ERP unavailable"}}}
+		},
+		func() (*erp.CreateOrderResult, error) {
+			return &erp.CreateOrderResult{
+				ERPOrderNumber: "0000012301",
+				Items: []erp.OrderItemResult{
+					{SKU: "4711", ItemNumber: "000010", ConfirmedQty: 10, ConfirmedPrice: 12.5},
+					{SKU: "4712", ItemNumber: "000020", ConfirmedQty: 1, ConfirmedPrice: 7.2},
+				},
+				Messages: []erp.Message{{Type: "warning", Message: "Partial confirmation"}},
+			}, nil
+		},
+	)
+
+	got := f.dispatch(t)
+	if got.Status != domain.TransmissionStatusPending || got.Attempts != 1 || !got.NextAttemptAt.Equal(f.clock.Add(time.Minute)) {
+		t.Fatalf("after transient failure = %+v, want pending, 1 attempt, retry after backoff", got)
+	}
+	if len(f.transmissions.history) != 1 || len(f.transmissions.history[0].Messages) != 1 || f.transmissions.history[0].Messages[0].Message != "ERP unavailable" ||
+		f.transmissions.history[0].Actor != domain.ActorERP {
+		t.Fatalf("history = %+v, want the failure by the ERP with its ERP messages", f.transmissions.history)
+	}
+
+	// Not due before the backoff has passed
+	if n, _ := f.dispatcher.DispatchDue(context.Background()); n != 0 {
+		t.Fatalf("DispatchDue() before backoff processed %d transmissions", n)
+	}
+
+	f.clock = f.clock.Add(time.Minute)
+	got = f.dispatch(t)
+	if got.Status != domain.TransmissionStatusSent || got.ERPOrderNumber != "0000012301" || got.SentAt == nil {
+		t.Fatalf("after success = %+v, want sent with ERP order number", got)
+	}
+
+	order := f.orders.orders[f.order.ID]
+	if order.Status != domain.OrderStatusConfirmed || order.ERPOrderNumber != "0000012301" {
+		t.Errorf("order = %s/%q, want confirmed with ERP order number", order.Status, order.ERPOrderNumber)
+	}
+	item := order.Items[1]
+	if item.ERPItemNumber != "000020" || item.ConfirmedQuantity == nil || *item.ConfirmedQuantity != 1 || *item.ConfirmedPrice != 7.2 {
+		t.Errorf("item = %+v, want ERP confirmation", item)
+	}
+
+	req := currentERP.requests[1]
+	if req.Customer.SoldToParty != "1000042" || req.TenantConfig.SalesOrg != "1000" || req.Order.ExternalID != "ORD-20240502-0001" {
+		t.Errorf("request = %+v, want customer, sales area and order number", req)
+	}
+	if req.ShipTo.Name != "Muster AG" || req.ShipTo.PostalCode != "8001" || req.ShipTo.Country != "CH" {
+		t.Errorf("ShipTo = %+v, want mapped checkout address", req.ShipTo)
+	}
+}
+
+func TestDispatchDue_RejectionWaitsForOperator(t *testing.T) {
+	f := newDispatcherFixture(t,
+		func() (*erp.CreateOrderResult, error) {
+			return nil, fmt.Errorf("customer blocked: %w", provider.ErrInvalidArgument)
+		},
+		func() (*erp.CreateOrderResult, error) {
+			return nil, fmt.Errorf("customer blocked: %w", provider.ErrInvalidArgument)
+		},
+	)
+	ctx := context.Background()
+
+	if got := f.dispatch(t); got.Status != domain.TransmissionStatusFailed || got.Attempts != 1 {
+		t.Fatalf("after rejection = %+v, want failed without retries", got)
+	}
+	stuck, _, _ := f.dispatcher.ListTransmissions(ctx, domain.TransmissionFilter{TenantID: f.order.TenantID, Stuck: true})
+	if len(stuck) != 1 {
+		t.Fatalf("stuck transmissions = %d, want 1", len(stuck))
+	}
+
+	retried, err := f.dispatcher.Retry(ctx, f.order.TenantID, f.transmission.ID)
+	if err != nil || retried.Status != domain.TransmissionStatusPending || retried.Attempts != 0 {
+		t.Fatalf("Retry() = %+v, %v; want pending with fresh attempts", retried, err)
+	}
+	if got := f.dispatch(t); got.Status != domain.TransmissionStatusFailed {
+		t.Fatalf("after second rejection = %+v, want failed", got)
+	}
+
+	if _, err := f.dispatcher.Skip(ctx, uuid.New(), f.transmission.ID, nil, "", ""); !errors.Is(err, domain.ErrTransmissionNotFound) {
+		t.Errorf("Skip() of another tenant error = %v, want ErrTransmissionNotFound", err)
+	}
+	skipped, err := f.dispatcher.Skip(ctx, f.order.TenantID, f.transmission.ID, nil, "0000099999", "entered manually")
+	if err != nil || skipped.Status != domain.TransmissionStatusSkipped {
+		t.Fatalf("Skip() = %+v, %v; want skipped", skipped, err)
+	}
+	if order := f.orders.orders[f.order.ID]; order.Status != domain.OrderStatusConfirmed || order.ERPOrderNumber != "0000099999" {
+		t.Errorf("order = %s/%q, want confirmed with the manual ERP order number", order.Status, order.ERPOrderNumber)
+	}
+	if _, err := f.dispatcher.Retry(ctx, f.order.TenantID, f.transmission.ID); err != nil {
+		t.Errorf("Retry() of skipped transmission error = %v", err)
+	}
+}
+
+func TestDispatchDue_FencesLeases(t *testing.T) {
+	var f *dispatcherFixture
+	f = newDispatcherFixture(t,
+		func() (*erp.CreateOrderResult, error) {
+			// The ERP call outlasts the lease and another dispatcher claims the order
+			f.clock = f.clock.Add(6 * time.Minute)
+			f.transmissions.ClaimDue(context.Background(), 1, 5*time.Minute)
+			return &erp.CreateOrderResult{ERPOrderNumber: "0000012301"}, nil
+		},
+	)
+	ctx := context.Background()
+
+	if _, err := f.dispatcher.DispatchDue(ctx); !errors.Is(err, domain.ErrTransmissionLeaseLost) {
+		t.Fatalf("DispatchDue() error = %v, want ErrTransmissionLeaseLost", err)
+	}
+	got := f.transmissions.transmissions[f.transmission.ID]
+	if got.Status != domain.TransmissionStatusPending || !got.IsInFlight(f.clock) || len(f.transmissions.history) != 0 {
+		t.Errorf("transmission = %+v, want it left to the dispatcher holding the lease", got)
+	}
+
+	// Operators cannot retry or skip a transmission being dispatched
+	if _, err := f.dispatcher.Retry(ctx, f.order.TenantID, f.transmission.ID); !errors.Is(err, domain.ErrTransmissionInFlight) {
+		t.Errorf("Retry() error = %v, want ErrTransmissionInFlight", err)
+	}
+	if _, err := f.dispatcher.Skip(ctx, f.order.TenantID, f.transmission.ID, nil, "", ""); !errors.Is(err, domain.ErrTransmissionInFlight) {
+		t.Errorf("Skip() error = %v, want ErrTransmissionInFlight", err)
+	}
+	if order := f.orders.orders[f.order.ID]; order.Status != domain.OrderStatusPending {
+		t.Errorf("order status = %s, want pending", order.Status)
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+// newCreateOrderRequest maps an order to an ERP order
+func newCreateOrderRequest(order *domain.Order, tenant *domain.Tenant) erp.CreateOrderRequest {
+	items := make([]erp.OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = erp.OrderItem{
+			SKU:      item.SKU,
+			Quantity: float64(item.Quantity),
+		}
+	}
+
+	return erp.CreateOrderRequest{
+		TenantConfig: erpTenantConfig(tenant, order.Currency),
+		Order: erp.Order{
+			ExternalID: order.OrderNumber,
+			Items:      items,
+			Notes:      order.Notes,
+		},
+		Customer: erpCustomer(tenant, order.ERPCustomerID),
+		ShipTo:   addressFromMap(order.ShippingAddress),
+		BillTo:   addressFromMap(order.BillingAddress),
+	}
+}
+
+// erpConfig returns the tenant's ERP settings from Tenant.Config["erp"]
+func erpConfig(tenant *domain.Tenant) map[string]any {
+	cfg, _ := tenant.Config["erp"].(map[string]any)
+	return cfg
+}
+
+// erpTenantConfig returns the tenant's sales area
+func erpTenantConfig(tenant *domain.Tenant, currency string) erp.TenantConfig {
+	cfg := erpConfig(tenant)
+	return erp.TenantConfig{
+		SalesOrg:    stringValue(cfg, "sales_org"),
+		DistChannel: stringValue(cfg, "dist_channel"),
+		Division:    stringValue(cfg, "division"),
+		Currency:    currency,
+		Language:    stringValue(cfg, "language"),
+	}
+}
+
+// erpCustomer returns the sold-to party. Buyers without an ERP customer fall
+// back to the tenant's "customer_id", e.g. a one-time customer account.
+func erpCustomer(tenant *domain.Tenant, customerID string) erp.Customer {
+	if customerID == "" {
+		customerID = stringValue(erpConfig(tenant), "customer_id")
+	}
+	return erp.Customer{
+		ERPCustomerID: customerID,
+		SoldToParty:   customerID,
+	}
+}
+
+// addressFromMap maps a checkout address to an ERP address
+func addressFromMap(addr map[string]any) erp.Address {
+	name := stringValue(addr, "company")
+	if name == "" {
+		name = strings.TrimSpace(stringValue(addr, "firstName", "first_name") + " " + stringValue(addr, "lastName", "last_name"))
+	}
+	return erp.Address{
+		Name:       name,
+		Street:     stringValue(addr, "street"),
+		PostalCode: stringValue(addr, "postalCode", "postal_code", "zip"),
+		City:       stringValue(addr, "city"),
+		Country:    strings.ToUpper(stringValue(addr, "country")),
+		Region:     stringValue(addr, "region"),
+	}
+}
+
+// applyConfirmations stores the ERP's confirmation on the matching order items
+func applyConfirmations(items []domain.OrderItem, results []erp.OrderItemResult) {
+	used := make([]bool, len(results))
+	for i := range items {
+		for j, result := range results {
+			if used[j] || result.SKU != items[i].SKU {
+				continue
+			}
+			used[j] = true
+			quantity, price := result.ConfirmedQty, result.ConfirmedPrice
+			items[i].ERPItemNumber = result.ItemNumber
+			items[i].ConfirmedQuantity = &quantity
+			items[i].ConfirmedPrice = &price
+			break
+		}
+	}
+}
+
+// statusMapping returns the default ERP status mapping extended by the
+// tenant's Config["erp"]["status_mapping"], e.g. {"B": "processing"}
+func statusMapping(tenant *domain.Tenant) domain.StatusMapping {
+	mapping := maps.Clone(domain.DefaultStatusMapping)
+	custom, _ := erpConfig(tenant)["status_mapping"].(map[string]any)
+	for erpStatus, status := range custom {
+		if s, ok := status.(string); ok {
+			mapping[erpStatus] = domain.OrderStatus(s)
+		}
+	}
+	return mapping
+}
+
+// shipmentStatus derives the order status from the ERP's shipped
+// quantities: processing once anything shipped, shipped once every item
+// shipped its (confirmed) quantity. Returns "" if nothing shipped yet.
+func shipmentStatus(items []domain.OrderItem, statuses []erp.OrderItemStatus) domain.OrderStatus {
+	skus := make(map[string]string, len(items)) // ERP item number -> SKU
+	ordered := make(map[string]float64, len(items))
+	for _, item := range items {
+		if item.ERPItemNumber != "" {
+			skus[item.ERPItemNumber] = item.SKU
+		}
+		quantity := float64(item.Quantity)
+		if item.ConfirmedQuantity != nil {
+			quantity = *item.ConfirmedQuantity
+		}
+		ordered[item.SKU] += quantity
+	}
+
+	shipped := make(map[string]float64, len(statuses))
+	var total float64
+	for _, s := range statuses {
+		sku := s.SKU
+		if sku == "" {
+			sku = skus[s.ItemNumber]
+		}
+		shipped[sku] += s.ShippedQty
+		total += s.ShippedQty
+	}
+	if total <= 0 {
+		return ""
+	}
+
+	for sku, quantity := range ordered {
+		if shipped[sku] < quantity {
+			return domain.OrderStatusProcessing
+		}
+	}
+	return domain.OrderStatusShipped
+}
+
+// toERPMessages converts provider messages to domain messages
+func toERPMessages(messages []erp.Message) []domain.ERPMessage {
+	if len(messages) == 0 {
+		return nil
+	}
+	result := make([]domain.ERPMessage, len(messages))
+	for i, m := range messages {
+		result[i] = domain.ERPMessage{Type: m.Type, Code: m.Code, Message: m.Message}
+	}
+	return result
+}
+
+// stringValue returns the first non-empty string value of the keys in m
+func stringValue(m map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
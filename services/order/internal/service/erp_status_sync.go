@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/repository"
+)
+
+// ERPStatusSyncConfig configures the ERP status sync
+type ERPStatusSyncConfig struct {
+	Interval   time.Duration // How often orders due for a sync are polled
+	BatchSize  int           // Orders synced per poll, each claimed on its own
+	Cadence    time.Duration // How often the ERP is asked for an open order's status
+	MaxBackoff time.Duration // Cadence is doubled per failed sync up to MaxBackoff
+	Jitter     time.Duration // Random delay added to each sync to spread the load on the ERP
+	Lease      time.Duration // How long a claimed order is locked for other workers; must exceed the worst-case ERP call including retries
+}
+
+// ERPStatusSyncer polls the ERP for the status of open orders and moves the
+// orders along, mapping the ERP's statuses through the tenant's status mapping
+type ERPStatusSyncer struct {
+	orderRepo    repository.OrderRepository
+	syncRepo     repository.StatusSyncRepository
+	tenantRepo   repository.TenantRepository
+	erpProviders provider.Source[erp.ERPProvider]
+	cfg          ERPStatusSyncConfig
+	now          func() time.Time
+	jitter       func(max time.Duration) time.Duration
+}
+
+// NewERPStatusSyncer creates a new ERP status syncer
+func NewERPStatusSyncer(
+	orderRepo repository.OrderRepository,
+	syncRepo repository.StatusSyncRepository,
+	tenantRepo repository.TenantRepository,
+	erpProviders provider.Source[erp.ERPProvider],
+	cfg ERPStatusSyncConfig,
+) *ERPStatusSyncer {
+	return &ERPStatusSyncer{
+		orderRepo:    orderRepo,
+		syncRepo:     syncRepo,
+		tenantRepo:   tenantRepo,
+		erpProviders: erpProviders,
+		cfg:          cfg,
+		now:          time.Now,
+		jitter:       rand.N[time.Duration],
+	}
+}
+
+// Run syncs due orders every interval until ctx ends. Errors other than
+// failed ERP requests, which are retried with backoff, are passed to onError.
+func (s *ERPStatusSyncer) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.SyncDue(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncDue syncs the status of up to BatchSize open orders that are due and
+// returns the number of orders processed. Orders are claimed one at a time,
+// so the lease only has to cover a single ERP call.
+func (s *ERPStatusSyncer) SyncDue(ctx context.Context) (int, error) {
+	var processed int
+	var errs []error
+	for processed < s.cfg.BatchSize {
+		syncs, err := s.syncRepo.ClaimDue(ctx, 1, s.cfg.Lease)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to claim orders for erp status sync: %w", err))
+			break
+		}
+		if len(syncs) == 0 {
+			break
+		}
+
+		processed++
+		if err := s.sync(ctx, &syncs[0]); err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", syncs[0].OrderNumber, err))
+		}
+	}
+
+	return processed, errors.Join(errs...)
+}
+
+// sync polls the ERP for one order and records the outcome. Failed polls
+// are recorded, only errors saving the outcome are returned.
+func (s *ERPStatusSyncer) sync(ctx context.Context, state *domain.ERPStatusSync) error {
+	order, err := s.orderRepo.GetByID(ctx, state.OrderID)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, order.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	status, err := s.getOrderStatus(ctx, order)
+	if err != nil {
+		return s.recordFailure(ctx, state, err.Error())
+	}
+
+	now := s.now()
+	state.ERPStatus = status.Status
+	state.Failures = 0
+	state.LastError = ""
+	state.SyncedAt = &now
+	state.NextSyncAt = s.nextSync(now, 0)
+
+	target := targetStatus(statusMapping(tenant), status, order.Items)
+	if target == "" || target == order.Status {
+		return s.syncRepo.Update(ctx, state, nil)
+	}
+
+	path := domain.StatusPath(order.Status, target)
+	if path == nil {
+		state.LastError = fmt.Sprintf("ERP status %q (%s) cannot be applied to a %s order", status.Status, target, order.Status)
+		return s.syncRepo.Update(ctx, state, nil)
+	}
+
+	// One log per step, so the history shows every transition
+	logs := make([]domain.OrderStatusLog, len(path))
+	from := order.Status
+	for i, to := range path {
+		fromStatus := from
+		logs[i] = domain.OrderStatusLog{
+			ID:         uuid.New(),
+			OrderID:    order.ID,
+			FromStatus: &fromStatus,
+			ToStatus:   to,
+			Actor:      domain.ActorERP,
+			Note:       fmt.Sprintf("Status synchronized from ERP status %q", status.Status),
+			CreatedAt:  now,
+		}
+		from = to
+	}
+
+	return s.syncRepo.Update(ctx, state, logs)
+}
+
+// getOrderStatus asks the tenant's ERP for the order's status
+func (s *ERPStatusSyncer) getOrderStatus(ctx context.Context, order *domain.Order) (*erp.OrderStatus, error) {
+	tenantID := order.TenantID.String()
+	ctx = provider.WithTenant(ctx, tenantID)
+
+	erpProvider, err := s.erpProviders.For(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return erpProvider.GetOrderStatus(ctx, order.ERPOrderNumber)
+}
+
+// recordFailure schedules the next sync with backoff
+func (s *ERPStatusSyncer) recordFailure(ctx context.Context, state *domain.ERPStatusSync, reason string) error {
+	state.Failures++
+	state.LastError = reason
+	state.NextSyncAt = s.nextSync(s.now(), state.Failures)
+	return s.syncRepo.Update(ctx, state, nil)
+}
+
+// nextSync returns when to sync next after the given number of failed syncs
+func (s *ERPStatusSyncer) nextSync(now time.Time, failures int) time.Time {
+	delay := s.cfg.Cadence
+	for i := 0; i < failures && delay < s.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if s.cfg.MaxBackoff > 0 && delay > s.cfg.MaxBackoff {
+		delay = s.cfg.MaxBackoff
+	}
+	if s.cfg.Jitter > 0 {
+		delay += s.jitter(s.cfg.Jitter)
+	}
+	return now.Add(delay)
+}
+
+// targetStatus maps the ERP's order status to an order status. Shipped
+// quantities move the order on even if the ERP's status lags behind.
+// Returns "" if the ERP status is not mapped and nothing shipped.
+func targetStatus(mapping domain.StatusMapping, status *erp.OrderStatus, items []domain.OrderItem) domain.OrderStatus {
+	target := mapping[status.Status]
+	if target == domain.OrderStatusCancelled {
+		return target
+	}
+
+	shipped := shipmentStatus(items, status.Items)
+	if shipped == "" {
+		return target
+	}
+	if target == "" || domain.StatusPath(target, shipped) != nil {
+		return shipped
+	}
+	return target
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+type fakeStatusSyncRepo struct {
+	syncs   map[uuid.UUID]domain.ERPStatusSync
+	orders  *fakeOrderRepo
+	history []domain.OrderStatusLog
+	locked  map[uuid.UUID]time.Time
+	claims  []int // limit of each ClaimDue call
+	now     func() time.Time
+}
+
+func (r *fakeStatusSyncRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.ERPStatusSync, error) {
+	r.claims = append(r.claims, limit)
+	if r.locked == nil {
+		r.locked = make(map[uuid.UUID]time.Time)
+	}
+	var due []domain.ERPStatusSync
+	for id, s := range r.syncs {
+		if len(due) == limit {
+			break
+		}
+		if !s.NextSyncAt.After(r.now()) && !r.locked[id].After(r.now()) {
+			r.locked[id] = r.now().Add(lease)
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeStatusSyncRepo) Update(ctx context.Context, sync *domain.ERPStatusSync, logs []domain.OrderStatusLog) error {
+	delete(r.locked, sync.OrderID)
+	r.syncs[sync.OrderID] = *sync
+	if len(logs) > 0 {
+		order := r.orders.orders[sync.OrderID]
+		if order.Status != *logs[0].FromStatus {
+			return nil
+		}
+		order.Status = logs[len(logs)-1].ToStatus
+		r.history = append(r.history, logs...)
+	}
+	return nil
+}
+
+type statusSyncFixture struct {
+	syncer *ERPStatusSyncer
+	syncs  *fakeStatusSyncRepo
+	order  *domain.Order
+	clock  time.Time
+}
+
+func newStatusSyncFixture(t *testing.T, tenant *domain.Tenant, status func(string) (*erp.OrderStatus, error)) *statusSyncFixture {
+	t.Helper()
+	currentERP = &fakeERP{status: status}
+
+	order := domain.NewOrder(tenant.ID, uuid.New(), "ORD-20240502-0001")
+	order.ERPOrderNumber = "0000012301"
+	confirmed := 1.0
+	order.Items = []domain.OrderItem{
+		{ID: uuid.New(), OrderID: order.ID, SKU: "4711", Quantity: 10, ERPItemNumber: "000010"},
+		{ID: uuid.New(), OrderID: order.ID, SKU: "4712", Quantity: 2, ERPItemNumber: "000020", ConfirmedQuantity: &confirmed},
+	}
+
+	f := &statusSyncFixture{order: order, clock: order.CreatedAt}
+	orders := &fakeOrderRepo{orders: map[uuid.UUID]*domain.Order{order.ID: order}}
+	f.syncs = &fakeStatusSyncRepo{
+		syncs: map[uuid.UUID]domain.ERPStatusSync{order.ID: {
+			OrderID: order.ID, TenantID: tenant.ID, OrderNumber: order.OrderNumber, ERPOrderNumber: order.ERPOrderNumber,
+		}},
+		orders: orders,
+		now:    func() time.Time { return f.clock },
+	}
+
+	resolver := provider.NewResolver(nil)
+	resolver.SetDefault("erp", provider.Selection{Name: "dispatcher-test"})
+	f.syncer = NewERPStatusSyncer(orders, f.syncs, &fakeTenantRepo{tenant: tenant},
+		provider.NewSource[erp.ERPProvider](resolver, "erp"),
+		ERPStatusSyncConfig{BatchSize: 10, Cadence: 15 * time.Minute, MaxBackoff: time.Hour, Jitter: time.Minute, Lease: 5 * time.Minute},
+	)
+	f.syncer.now = func() time.Time { return f.clock }
+	f.syncer.jitter = func(max time.Duration) time.Duration { return max / 2 }
+	return f
+}
+
+func (f *statusSyncFixture) sync(t *testing.T) domain.ERPStatusSync {
+	t.Helper()
+	if _, err := f.syncer.SyncDue(context.Background()); err != nil {
+		t.Fatalf("SyncDue() error = %v", err)
+	}
+	return f.syncs.syncs[f.order.ID]
+}
+
+func TestSyncDue_MapsShippedQuantities(t *testing.T) {
+	tenant := domain.NewTenant("demo", "Demo")
+	tenant.Config["erp"] = map[string]any{"status_mapping": map[string]any{"B": "processing", "C": "delivered"}}
+
+	var shipped float64
+	erpStatus := "B"
+	f := newStatusSyncFixture(t, tenant, func(erpOrderNumber string) (*erp.OrderStatus, error) {
+		return &erp.OrderStatus{ERPOrderNumber: erpOrderNumber, Status: erpStatus, Items: []erp.OrderItemStatus{
+			{ItemNumber: "000010", ShippedQty: 10},
+			{ItemNumber: "000020", ShippedQty: shipped},
+		}}, nil
+	})
+
+	got := f.sync(t)
+	if f.order.Status != domain.OrderStatusProcessing || got.ERPStatus != "B" || got.SyncedAt == nil {
+		t.Fatalf("after partial shipment = %s/%+v, want processing", f.order.Status, got)
+	}
+	if !got.NextSyncAt.Equal(f.clock.Add(15*time.Minute + 30*time.Second)) {
+		t.Errorf("NextSyncAt = %v, want cadence plus jitter", got.NextSyncAt)
+	}
+
+	// The confirmed quantity of the second item is 1, not the ordered 2
+	shipped = 1
+	f.clock = got.NextSyncAt
+	f.sync(t)
+	if f.order.Status != domain.OrderStatusShipped {
+		t.Fatalf("after full shipment status = %s, want shipped", f.order.Status)
+	}
+
+	erpStatus = "C"
+	f.clock = f.clock.Add(time.Hour)
+	f.sync(t)
+	if f.order.Status != domain.OrderStatusDelivered {
+		t.Fatalf("after delivery status = %s, want delivered", f.order.Status)
+	}
+
+	history := f.syncs.history
+	if len(history) != 3 || history[0].ToStatus != domain.OrderStatusProcessing || history[2].ToStatus != domain.OrderStatusDelivered {
+		t.Fatalf("history = %+v, want processing, shipped, delivered", history)
+	}
+	for _, log := range history {
+		if log.Actor != domain.ActorERP || log.ChangedBy != nil {
+			t.Errorf("log = %+v, want ERP as actor", log)
+		}
+	}
+}
+
+func TestSyncDue_ClaimsOrdersOneAtATime(t *testing.T) {
+	f := newStatusSyncFixture(t, domain.NewTenant("demo", "Demo"), func(erpOrderNumber string) (*erp.OrderStatus, error) {
+		return &erp.OrderStatus{ERPOrderNumber: erpOrderNumber, Status: "open"}, nil
+	})
+	for range 2 {
+		order := domain.NewOrder(f.order.TenantID, uuid.New(), "ORD-20240502-0002")
+		order.ERPOrderNumber = "0000012302"
+		f.syncs.orders.orders[order.ID] = order
+		f.syncs.syncs[order.ID] = domain.ERPStatusSync{
+			OrderID: order.ID, TenantID: order.TenantID, OrderNumber: order.OrderNumber, ERPOrderNumber: order.ERPOrderNumber,
+		}
+	}
+
+	f.syncer.cfg.BatchSize = 2
+	n, err := f.syncer.SyncDue(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("SyncDue() = %d, %v, want 2 orders", n, err)
+	}
+	n, err = f.syncer.SyncDue(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("second SyncDue() = %d, %v, want the remaining order", n, err)
+	}
+	for _, limit := range f.syncs.claims {
+		if limit != 1 {
+			t.Fatalf("claims = %v, want one order per claim", f.syncs.claims)
+		}
+	}
+	if len(f.syncs.locked) != 0 {
+		t.Errorf("%d orders still locked after their sync", len(f.syncs.locked))
+	}
+}
+
+func TestSyncDue_SkipsInvalidTransitionsAndBacksOff(t *testing.T) {
+	var failing bool
+	erpStatus := "delivered"
+	f := newStatusSyncFixture(t, domain.NewTenant("demo", "Demo"), func(erpOrderNumber string) (*erp.OrderStatus, error) {
+		if failing {
+			return nil, errors.New("HTTP 503")
+		}
+		return &erp.OrderStatus{ERPOrderNumber: erpOrderNumber, Status: erpStatus}, nil
+	})
+
+	// Delivered is reached through processing and shipped, one log per step
+	f.sync(t)
+	if f.order.Status != domain.OrderStatusDelivered || len(f.syncs.history) != 3 {
+		t.Fatalf("status = %s with %d logs, want delivered with 3 logs", f.order.Status, len(f.syncs.history))
+	}
+
+	f.order.Status = domain.OrderStatusShipped
+	f.syncs.history = nil
+	erpStatus = "cancelled"
+	f.clock = f.clock.Add(time.Hour)
+	got := f.sync(t)
+	if f.order.Status != domain.OrderStatusShipped || len(f.syncs.history) != 0 || got.LastError == "" {
+		t.Fatalf("after invalid transition = %s/%+v, want unchanged with error", f.order.Status, got)
+	}
+
+	failing = true
+	for _, want := range []time.Duration{30 * time.Minute, time.Hour, time.Hour} {
+		f.clock = got.NextSyncAt
+		got = f.sync(t)
+		if !got.NextSyncAt.Equal(f.clock.Add(want + 30*time.Second)) {
+			t.Errorf("after %d failures NextSyncAt = %v, want %v plus jitter", got.Failures, got.NextSyncAt.Sub(f.clock), want)
+		}
+	}
+	if got.Failures != 3 || got.LastError != "HTTP 503" {
+		t.Errorf("sync = %+v, want 3 failures with the ERP error", got)
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
 	"github.com/gondolia/gondolia/services/order/internal/domain"
 	"github.com/gondolia/gondolia/services/order/internal/repository"
 )
@@ -40,6 +42,11 @@ type OrderService struct {
 	tenantRepo     repository.TenantRepository
 	cartServiceURL string
 	httpClient     *http.Client
+
+	// ERP checkout simulation, see SetSimulation
+	simulationRepo repository.SimulationRepository
+	erpProviders   provider.Source[erp.ERPProvider]
+	simulationCfg  SimulationConfig
 }
 
 func NewOrderService(orderRepo repository.OrderRepository, tenantRepo repository.TenantRepository, cartServiceURL string) *OrderService {
@@ -81,6 +88,10 @@ func (s *OrderService) Checkout(ctx context.Context, tenantID, userID uuid.UUID,
 	order.ShippingAddress = req.ShippingAddress
 	order.BillingAddress = req.BillingAddress
 	order.Notes = req.Notes
+	order.CompanyID = req.CompanyID
+	order.ERPCustomerID = req.ERPCustomerID
+	// The order stays pending until the ERP dispatcher transmitted it
+	order.Status = domain.OrderStatusPending
 
 	// Calculate totals from cart items
 	var subtotal float64
@@ -117,17 +128,12 @@ func (s *OrderService) Checkout(ctx context.Context, tenantID, userID uuid.UUID,
 		order.Currency = cart.Items[0].Currency
 	}
 
-	// 5. Save order
-	if err := s.orderRepo.Create(ctx, order); err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
-	}
-
-	// 6. Save order items
-	if err := s.orderRepo.CreateItems(ctx, orderItems); err != nil {
-		return nil, fmt.Errorf("failed to create order items: %w", err)
+	// 5. Verify the order against the ERP checkout simulation
+	if err := s.checkSimulation(ctx, order, orderItems, req.SimulationID); err != nil {
+		return nil, err
 	}
 
-	// 7. Add status history log
+	// 6. Add status history log
 	statusLog := &domain.OrderStatusLog{
 		ID:        uuid.New(),
 		OrderID:   order.ID,
@@ -136,8 +142,12 @@ func (s *OrderService) Checkout(ctx context.Context, tenantID, userID uuid.UUID,
 		Note:      "Order created from checkout",
 		CreatedAt: time.Now(),
 	}
-	if err := s.orderRepo.AddStatusLog(ctx, statusLog); err != nil {
-		return nil, fmt.Errorf("failed to add status log: %w", err)
+	order.Items = orderItems
+	order.StatusHistory = []domain.OrderStatusLog{*statusLog}
+
+	// 7. Save order, items, status history and the ERP outbox entry in one transaction
+	if err := s.orderRepo.CreateWithTransmission(ctx, order, domain.NewERPTransmission(order)); err != nil {
+		return nil, err
 	}
 
 	// 8. Mark cart as completed
@@ -146,10 +156,6 @@ func (s *OrderService) Checkout(ctx context.Context, tenantID, userID uuid.UUID,
 		fmt.Printf("Warning: failed to mark cart as completed: %v\n", err)
 	}
 
-	// Load full order with items and history
-	order.Items = orderItems
-	order.StatusHistory = []domain.OrderStatusLog{*statusLog}
-
 	return order, nil
 }
 
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+	"github.com/gondolia/gondolia/services/order/internal/repository"
+)
+
+// SimulationConfig configures the ERP checkout simulation
+type SimulationConfig struct {
+	TTL      time.Duration // How long simulated prices are binding
+	Required bool          // Default for tenants without Config["erp"]["require_simulation"]
+}
+
+// SetSimulation enables the ERP checkout simulation
+func (s *OrderService) SetSimulation(simulationRepo repository.SimulationRepository, erpProviders provider.Source[erp.ERPProvider], cfg SimulationConfig) {
+	s.simulationRepo = simulationRepo
+	s.erpProviders = erpProviders
+	s.simulationCfg = cfg
+}
+
+// Simulate previews the checkout of the cart with the ERP's prices,
+// availability and delivery schedule. The simulation can be passed to
+// Checkout until it expires.
+func (s *OrderService) Simulate(ctx context.Context, tenantID, userID uuid.UUID, sessionID string, req *domain.SimulateRequest) (*domain.OrderSimulation, error) {
+	if s.erpProviders == nil {
+		return nil, domain.ErrSimulationUnsupported
+	}
+
+	cart, err := s.getCart(ctx, tenantID, userID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, domain.ErrCartEmpty
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	ctx = provider.WithTenant(ctx, tenantID.String())
+	erpProvider, err := s.erpProviders.For(ctx, tenantID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	currency := cart.Items[0].Currency
+	result, err := erpProvider.SimulateOrder(ctx, newSimulateOrderRequest(cart, tenant, currency, req))
+	if err != nil {
+		switch {
+		case errors.Is(err, provider.ErrUnsupported):
+			return nil, domain.ErrSimulationUnsupported
+		case errors.Is(err, provider.ErrInvalidArgument):
+			return nil, &domain.ERPRejectedError{Err: err, Messages: toERPMessages(erp.ErrorMessages(err))}
+		}
+		return nil, fmt.Errorf("erp simulation failed: %w", err)
+	}
+
+	now := time.Now()
+	simulation := newSimulation(cart, result, currency, now)
+	simulation.TenantID = tenantID
+	simulation.UserID = userID
+	simulation.ERPCustomerID = req.ERPCustomerID
+	simulation.ExpiresAt = now.Add(s.simulationCfg.TTL)
+
+	if err := s.simulationRepo.Create(ctx, simulation); err != nil {
+		return nil, fmt.Errorf("failed to save simulation: %w", err)
+	}
+
+	return simulation, nil
+}
+
+// checkSimulation verifies that the order matches the given unexpired
+// simulation and applies its prices, or that the tenant does not require one
+func (s *OrderService) checkSimulation(ctx context.Context, order *domain.Order, items []domain.OrderItem, simulationID *uuid.UUID) error {
+	if simulationID == nil {
+		required, err := s.simulationRequired(ctx, order.TenantID)
+		if err != nil {
+			return err
+		}
+		if required {
+			return domain.ErrSimulationRequired
+		}
+		return nil
+	}
+
+	if s.simulationRepo == nil {
+		return domain.ErrSimulationNotFound
+	}
+
+	simulation, err := s.simulationRepo.GetByID(ctx, *simulationID)
+	if err != nil {
+		return err
+	}
+	if err := simulation.Check(order, items, time.Now()); err != nil {
+		return err
+	}
+
+	simulation.Apply(order, items)
+	order.SimulationID = simulationID
+	return nil
+}
+
+// simulationRequired checks if the tenant requires a simulation at checkout
+func (s *OrderService) simulationRequired(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if required, ok := erpConfig(tenant)["require_simulation"].(bool); ok {
+		return required, nil
+	}
+	return s.simulationCfg.Required, nil
+}
+
+// newSimulateOrderRequest maps the cart to an ERP simulation
+func newSimulateOrderRequest(cart *CartResponse, tenant *domain.Tenant, currency string, req *domain.SimulateRequest) erp.SimulateOrderRequest {
+	items := make([]erp.SimulateItem, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = erp.SimulateItem{
+			SKU:      item.SKU,
+			Quantity: float64(item.Quantity),
+		}
+	}
+
+	return erp.SimulateOrderRequest{
+		TenantConfig: erpTenantConfig(tenant, currency),
+		Items:        items,
+		Customer:     erpCustomer(tenant, req.ERPCustomerID),
+		ShipTo:       addressFromMap(req.ShippingAddress),
+		DesiredDate:  req.DesiredDate,
+	}
+}
+
+// newSimulation builds the checkout preview, comparing the ERP's prices
+// with the cart's catalog prices
+func newSimulation(cart *CartResponse, result *erp.SimulateOrderResult, currency string, now time.Time) *domain.OrderSimulation {
+	simulation := &domain.OrderSimulation{
+		ID:           uuid.New(),
+		Currency:     currency,
+		Items:        make([]domain.SimulationItem, len(cart.Items)),
+		Messages:     toERPMessages(result.Messages),
+		AllAvailable: true,
+		CreatedAt:    now,
+		Totals: domain.SimulationTotals{
+			Subtotal: result.Totals.Subtotal,
+			Tax:      result.Totals.Tax,
+			Shipping: result.Totals.Shipping,
+			Total:    result.Totals.Total,
+			Currency: result.Totals.Currency,
+		},
+	}
+	if simulation.Totals.Currency == "" {
+		simulation.Totals.Currency = currency
+	}
+
+	used := make([]bool, len(result.Items))
+	for i, cartItem := range cart.Items {
+		item := domain.SimulationItem{
+			SKU:              cartItem.SKU,
+			ProductName:      cartItem.ProductName,
+			Quantity:         cartItem.Quantity,
+			CatalogUnitPrice: cartItem.UnitPrice,
+		}
+		simulation.CatalogTotal += cartItem.UnitPrice * float64(cartItem.Quantity)
+
+		simulated := -1
+		for j, r := range result.Items {
+			if !used[j] && r.SKU == cartItem.SKU {
+				simulated = j
+				break
+			}
+		}
+		if simulated >= 0 {
+			used[simulated] = true
+			r := result.Items[simulated]
+			item.Unit = r.Unit
+			item.Available = r.Available
+			item.LeadTimeDays = r.LeadTimeDays
+			item.SetPrice(r.UnitPrice, r.TotalPrice)
+		} else {
+			// Not priced by the ERP; keep the catalog price
+			item.SetPrice(cartItem.UnitPrice, cartItem.UnitPrice*float64(cartItem.Quantity))
+		}
+
+		simulation.PriceChanged = simulation.PriceChanged || item.PriceChanged
+		simulation.AllAvailable = simulation.AllAvailable && item.Available
+		simulation.Items[i] = item
+	}
+
+	for _, d := range result.Schedule {
+		simulation.Schedule = append(simulation.Schedule, domain.DeliverySchedule{
+			Date:     d.Date,
+			Quantity: d.Quantity,
+			SKUs:     d.Items,
+		})
+	}
+
+	return simulation
+}
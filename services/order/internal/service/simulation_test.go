@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gondolia/gondolia/provider"
+	"github.com/gondolia/gondolia/provider/erp"
+	"github.com/gondolia/gondolia/services/order/internal/domain"
+)
+
+type fakeSimulationRepo struct {
+	simulations map[uuid.UUID]*domain.OrderSimulation
+}
+
+func (r *fakeSimulationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.OrderSimulation, error) {
+	simulation, ok := r.simulations[id]
+	if !ok {
+		return nil, domain.ErrSimulationNotFound
+	}
+	return simulation, nil
+}
+
+func (r *fakeSimulationRepo) Create(ctx context.Context, simulation *domain.OrderSimulation) error {
+	r.simulations[simulation.ID] = simulation
+	return nil
+}
+
+func (r *fakeOrderRepo) GenerateOrderNumber(ctx context.Context, tenantID uuid.UUID) (string, error) {
+	return "ORD-20240502-0001", nil
+}
+
+func (r *fakeOrderRepo) CreateWithTransmission(ctx context.Context, order *domain.Order, transmission *domain.ERPTransmission) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+// newCartServer serves the cart items to checkout
+func newCartServer(t *testing.T, items *[]CartItem) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/cart" {
+			json.NewEncoder(w).Encode(CartResponse{ID: uuid.New(), Items: *items})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSimulate_PreviewAndCheckout(t *testing.T) {
+	items := []CartItem{
+		{ProductID: uuid.New(), SKU: "4711", ProductName: "Schraube M8", Quantity: 10, UnitPrice: 12.5, Currency: "CHF"},
+		{ProductID: uuid.New(), SKU: "4712", ProductName: "Mutter M8", Quantity: 2, UnitPrice: 3, Currency: "CHF"},
+	}
+	cart := newCartServer(t, &items)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Truncate(time.Second)
+	currentERP = &fakeERP{simulate: func(req erp.SimulateOrderRequest) (*erp.SimulateOrderResult, error) {
+		if req.Customer.SoldToParty != "1000042" || len(req.Items) != 2 {
+			t.Errorf("SimulateOrder(%+v), want the cart for customer 1000042", req)
+		}
+		return &erp.SimulateOrderResult{
+			Items: []erp.SimulatedItem{
+				{SKU: "4711", Quantity: 10, Unit: "PCE", UnitPrice: 11.9, TotalPrice: 119, Available: true, LeadTimeDays: 1},
+				{SKU: "4712", Quantity: 2, Unit: "PCE", UnitPrice: 3, TotalPrice: 6, Available: false, LeadTimeDays: 14},
+			},
+			Totals:   erp.Totals{Subtotal: 125, Total: 125, Currency: "CHF"},
+			Schedule: []erp.DeliverySchedule{{Date: tomorrow, Quantity: 10, Items: []string{"4711"}}},
+			Messages: []erp.Message{{Type: "warning", Message: "4712 is not in stock"}},
+		}, nil
+	}}
+
+	tenant := domain.NewTenant("demo", "Demo")
+	tenant.Config["erp"] = map[string]any{"require_simulation": true}
+	orders := &fakeOrderRepo{orders: map[uuid.UUID]*domain.Order{}}
+	simulations := &fakeSimulationRepo{simulations: map[uuid.UUID]*domain.OrderSimulation{}}
+
+	resolver := provider.NewResolver(nil)
+	resolver.SetDefault("erp", provider.Selection{Name: "dispatcher-test"})
+	s := NewOrderService(orders, &fakeTenantRepo{tenant: tenant}, cart.URL)
+	s.SetSimulation(simulations, provider.NewSource[erp.ERPProvider](resolver, "erp"), SimulationConfig{TTL: 15 * time.Minute})
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	simulation, err := s.Simulate(ctx, tenant.ID, userID, "", &domain.SimulateRequest{ERPCustomerID: "1000042"})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	screw := simulation.Items[0]
+	if !screw.PriceChanged || screw.PriceDifference != -0.6 || screw.UnitPrice != 11.9 || screw.CatalogUnitPrice != 12.5 {
+		t.Errorf("Items[0] = %+v, want ERP price 11.9 highlighted against catalog price 12.5", screw)
+	}
+	if simulation.Items[1].PriceChanged || simulation.AllAvailable || simulation.Items[1].LeadTimeDays != 14 {
+		t.Errorf("Items[1] = %+v, want unchanged price, unavailable with lead time", simulation.Items[1])
+	}
+	if !simulation.PriceChanged || simulation.CatalogTotal != 131 || simulation.Totals.Total != 125 {
+		t.Errorf("simulation = %+v, want price changes, catalog total 131 and ERP total 125", simulation)
+	}
+	if len(simulation.Schedule) != 1 || !simulation.Schedule[0].Date.Equal(tomorrow) || len(simulation.Messages) != 1 {
+		t.Errorf("schedule = %+v, messages = %+v; want one delivery and one message", simulation.Schedule, simulation.Messages)
+	}
+
+	checkout := func(simulationID *uuid.UUID) (*domain.Order, error) {
+		return s.Checkout(ctx, tenant.ID, userID, "", &domain.CheckoutRequest{SimulationID: simulationID, ERPCustomerID: "1000042"})
+	}
+
+	if _, err := checkout(nil); !errors.Is(err, domain.ErrSimulationRequired) {
+		t.Errorf("Checkout() without simulation error = %v, want ErrSimulationRequired", err)
+	}
+
+	items[0].Quantity = 20
+	if _, err := checkout(&simulation.ID); !errors.Is(err, domain.ErrSimulationMismatch) {
+		t.Errorf("Checkout() after cart change error = %v, want ErrSimulationMismatch", err)
+	}
+	items[0].Quantity = 10
+
+	items[1].UnitPrice = 2.5
+	if _, err := checkout(&simulation.ID); !errors.Is(err, domain.ErrSimulationMismatch) {
+		t.Errorf("Checkout() after catalog price change error = %v, want ErrSimulationMismatch", err)
+	}
+	items[1].UnitPrice = 3
+
+	if _, err := s.Checkout(ctx, tenant.ID, userID, "", &domain.CheckoutRequest{SimulationID: &simulation.ID, ERPCustomerID: "1000043"}); !errors.Is(err, domain.ErrSimulationMismatch) {
+		t.Errorf("Checkout() for another customer error = %v, want ErrSimulationMismatch", err)
+	}
+
+	if _, err := s.Checkout(ctx, tenant.ID, uuid.New(), "", &domain.CheckoutRequest{SimulationID: &simulation.ID}); !errors.Is(err, domain.ErrSimulationNotFound) {
+		t.Errorf("Checkout() by another user error = %v, want ErrSimulationNotFound", err)
+	}
+
+	order, err := checkout(&simulation.ID)
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if order.SimulationID == nil || *order.SimulationID != simulation.ID {
+		t.Errorf("SimulationID = %v, want %s", order.SimulationID, simulation.ID)
+	}
+	if order.Items[0].UnitPrice != 11.9 || order.Items[0].TotalPrice != 119 || order.Subtotal != 125 || order.Total != 125 {
+		t.Errorf("order = %+v, want the simulated ERP prices and totals", order)
+	}
+
+	simulation.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := checkout(&simulation.ID); !errors.Is(err, domain.ErrSimulationExpired) {
+		t.Errorf("Checkout() with expired simulation error = %v, want ErrSimulationExpired", err)
+	}
+}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS erp_outbox;
+
+ALTER TABLE order_status_history
+    DROP COLUMN IF EXISTS messages;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS erp_item_number,
+    DROP COLUMN IF EXISTS confirmed_quantity,
+    DROP COLUMN IF EXISTS confirmed_price;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS company_id,
+    DROP COLUMN IF EXISTS erp_customer_id,
+    DROP COLUMN IF EXISTS erp_order_number;
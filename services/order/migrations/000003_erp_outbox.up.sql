@@ -0,0 +1,44 @@
+-- ERP fields on orders
+ALTER TABLE orders
+    ADD COLUMN company_id UUID,
+    ADD COLUMN erp_customer_id VARCHAR(50),
+    ADD COLUMN erp_order_number VARCHAR(50);
+
+ALTER TABLE order_items
+    ADD COLUMN erp_item_number VARCHAR(20),
+    ADD COLUMN confirmed_quantity DECIMAL(12, 3),
+    ADD COLUMN confirmed_price DECIMAL(12, 2);
+
+ALTER TABLE order_status_history
+    ADD COLUMN messages JSONB;
+
+-- Create erp_outbox table
+CREATE TABLE erp_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    messages JSONB,
+    erp_order_number VARCHAR(50),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+
+    CONSTRAINT check_erp_outbox_status CHECK (
+        status IN ('pending', 'sent', 'failed', 'skipped')
+    )
+);
+
+-- Create indexes for erp_outbox
+CREATE UNIQUE INDEX idx_erp_outbox_order ON erp_outbox(order_id);
+CREATE INDEX idx_erp_outbox_due ON erp_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_erp_outbox_tenant_status ON erp_outbox(tenant_id, status);
+
+-- Comments
+COMMENT ON TABLE erp_outbox IS 'Transactional outbox transmitting orders to the ERP';
+COMMENT ON COLUMN erp_outbox.locked_until IS 'Lease of the dispatcher currently transmitting the order';
+COMMENT ON COLUMN order_status_history.messages IS 'ERP messages, e.g. why a transmission failed';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_orders_simulation;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS simulation_id;
+
+DROP TABLE IF EXISTS order_simulations;
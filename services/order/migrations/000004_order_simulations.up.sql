@@ -0,0 +1,22 @@
+-- Create order_simulations table
+CREATE TABLE order_simulations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Create indexes for order_simulations
+CREATE INDEX idx_order_simulations_expires_at ON order_simulations(expires_at);
+
+-- Each simulation can be used for one order only
+ALTER TABLE orders
+    ADD COLUMN simulation_id UUID;
+
+CREATE UNIQUE INDEX idx_orders_simulation ON orders(simulation_id) WHERE simulation_id IS NOT NULL;
+
+-- Comments
+COMMENT ON TABLE order_simulations IS 'Checkout previews with ERP prices, availability and delivery schedule';
+COMMENT ON COLUMN order_simulations.data IS 'JSONB storing the simulated items, totals, schedule and messages';
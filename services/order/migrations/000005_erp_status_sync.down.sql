@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_orders_erp_status_sync;
+
+ALTER TABLE order_status_history
+    DROP COLUMN IF EXISTS actor;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS erp_status,
+    DROP COLUMN IF EXISTS erp_status_failures,
+    DROP COLUMN IF EXISTS erp_status_error,
+    DROP COLUMN IF EXISTS erp_status_next_sync_at,
+    DROP COLUMN IF EXISTS erp_status_synced_at;
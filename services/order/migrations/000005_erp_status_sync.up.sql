@@ -0,0 +1,20 @@
+-- ERP status sync state on orders
+ALTER TABLE orders
+    ADD COLUMN erp_status VARCHAR(50),
+    ADD COLUMN erp_status_failures INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN erp_status_error TEXT,
+    ADD COLUMN erp_status_next_sync_at TIMESTAMPTZ,
+    ADD COLUMN erp_status_synced_at TIMESTAMPTZ;
+
+-- Who changed the status if not a user, e.g. 'erp'
+ALTER TABLE order_status_history
+    ADD COLUMN actor VARCHAR(50);
+
+-- Create index for open orders to poll
+CREATE INDEX idx_orders_erp_status_sync ON orders(erp_status_next_sync_at)
+    WHERE status IN ('confirmed', 'processing', 'shipped') AND erp_order_number IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN orders.erp_status IS 'Order status last reported by the ERP';
+COMMENT ON COLUMN orders.erp_status_next_sync_at IS 'When the ERP is next polled for the order status';
+COMMENT ON COLUMN order_status_history.actor IS 'System that changed the status, e.g. erp';
@@ -0,0 +1,2 @@
+ALTER TABLE erp_outbox
+    DROP COLUMN IF EXISTS claim_token;
@@ -0,0 +1,6 @@
+-- Fence the dispatcher holding the lease of a transmission
+ALTER TABLE erp_outbox
+    ADD COLUMN claim_token UUID;
+
+-- Comments
+COMMENT ON COLUMN erp_outbox.claim_token IS 'Claim of the dispatcher holding the lease; its update must carry it';